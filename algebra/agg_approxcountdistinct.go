@@ -0,0 +1,165 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function APPROX_COUNT_DISTINCT(expr).
+It returns an estimate of the number of distinct values of expr
+that are not NULL or MISSING, using a HyperLogLog sketch instead of
+the set of values kept by COUNT(DISTINCT expr).
+*/
+type ApproxCountDistinct struct {
+	AggregateBase
+}
+
+/*
+The function NewApproxCountDistinct calls NewAggregateBase to
+create an aggregate function named approx_count_distinct with
+one expression as input.
+*/
+func NewApproxCountDistinct(operands expression.Expressions, flags uint32, filter expression.Expression,
+	wTerm *WindowTerm) Aggregate {
+	rv := &ApproxCountDistinct{
+		*NewAggregateBase("approx_count_distinct", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *ApproxCountDistinct) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *ApproxCountDistinct) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *ApproxCountDistinct) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewApproxCountDistinct with the input operand
+cast to a Function as the FunctionConstructor.
+*/
+func (this *ApproxCountDistinct) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewApproxCountDistinct(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *ApproxCountDistinct) Copy() expression.Expression {
+	rv := &ApproxCountDistinct{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the APPROX_COUNT_DISTINCT function, then the default value
+returned is a zero value.
+*/
+func (this *ApproxCountDistinct) Default(item value.Value, context Context) (value.Value, error) {
+	return value.ZERO_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. NULL and MISSING
+values are not counted. Every other value is added to the sketch.
+*/
+func (this *ApproxCountDistinct) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	item, e := this.Operands()[0].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type() <= value.NULL {
+		return cumulative, nil
+	}
+
+	av, sketch := addSketch(cumulative, func() interface{} { return newHLLSketch() })
+	hll, ok := sketch.(*hllSketch)
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s sketch %v of type %T.", this.Name(), sketch, sketch)
+	}
+
+	hll.Add(item)
+	return av, nil
+}
+
+/*
+Aggregates intermediate results by merging their sketches.
+*/
+func (this *ApproxCountDistinct) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	psketch := getSketch(part)
+	if psketch == nil {
+		return cumulative, nil
+	}
+
+	csketch := getSketch(cumulative)
+	if csketch == nil {
+		return part, nil
+	}
+
+	phll, pok := psketch.(*hllSketch)
+	chll, cok := csketch.(*hllSketch)
+	if !pok || !cok {
+		return nil, fmt.Errorf("Invalid %s sketches %T and %T.", this.Name(), psketch, csketch)
+	}
+
+	chll.Merge(phll)
+	return cumulative, nil
+}
+
+/*
+Compute the Final. Return the estimated number of distinct values,
+or zero if there were none.
+*/
+func (this *ApproxCountDistinct) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	sketch := getSketch(cumulative)
+	if sketch == nil {
+		return value.ZERO_VALUE, nil
+	}
+
+	hll, ok := sketch.(*hllSketch)
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s sketch %v of type %T.", this.Name(), sketch, sketch)
+	}
+	return value.NewValue(hll.Estimate()), nil
+}
@@ -0,0 +1,120 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function APPROX_MEDIAN(expr). It is
+APPROX_PERCENTILE(expr, 0.5): an estimate of the median of the number
+values in the group that, unlike MEDIAN, does not keep all of them.
+*/
+type ApproxMedian struct {
+	AggregateBase
+}
+
+/*
+The function NewApproxMedian calls NewAggregateBase to
+create an aggregate function named approx_median with
+one expression as input.
+*/
+func NewApproxMedian(operands expression.Expressions, flags uint32, filter expression.Expression,
+	wTerm *WindowTerm) Aggregate {
+	rv := &ApproxMedian{
+		*NewAggregateBase("approx_median", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *ApproxMedian) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *ApproxMedian) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *ApproxMedian) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewApproxMedian with the input operand
+cast to a Function as the FunctionConstructor.
+*/
+func (this *ApproxMedian) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewApproxMedian(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *ApproxMedian) Copy() expression.Expression {
+	rv := &ApproxMedian{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the APPROX_MEDIAN function, then the default value
+returned is a null.
+*/
+func (this *ApproxMedian) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than
+numbers are ignored, numbers are added to the t-digest.
+*/
+func (this *ApproxMedian) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	return digestAdd(this.Name(), this.Operands()[0], item, cumulative, context)
+}
+
+/*
+Aggregates intermediate results by merging their t-digests.
+*/
+func (this *ApproxMedian) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateDigests(this.Name(), part, cumulative)
+}
+
+/*
+Compute the Final. Return NULL if no values of type NUMBER exist,
+otherwise the estimated median.
+*/
+func (this *ApproxMedian) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	return digestQuantile(this.Name(), cumulative, 0.5)
+}
@@ -0,0 +1,213 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function APPROX_PERCENTILE(expr, fraction).
+It returns an estimate of the value below which the given fraction of
+the number values in the group fall, computed from a t-digest.
+Type ApproxPercentile is a struct that inherits from AggregateBase.
+*/
+type ApproxPercentile struct {
+	AggregateBase
+}
+
+/*
+The function NewApproxPercentile calls NewAggregateBase to
+create an aggregate function named approx_percentile with
+two expressions as input.
+*/
+func NewApproxPercentile(operands expression.Expressions, flags uint32, filter expression.Expression,
+	wTerm *WindowTerm) Aggregate {
+	rv := &ApproxPercentile{
+		*NewAggregateBase("approx_percentile", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *ApproxPercentile) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *ApproxPercentile) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *ApproxPercentile) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewApproxPercentile with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *ApproxPercentile) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewApproxPercentile(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *ApproxPercentile) Copy() expression.Expression {
+	rv := &ApproxPercentile{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 2.
+*/
+func (this *ApproxPercentile) MinArgs() int { return 2 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *ApproxPercentile) MaxArgs() int { return 2 }
+
+/*
+If no input to the APPROX_PERCENTILE function, then the default value
+returned is a null.
+*/
+func (this *ApproxPercentile) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than
+numbers are ignored, numbers are added to the t-digest.
+*/
+func (this *ApproxPercentile) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	return digestAdd(this.Name(), this.Operands()[0], item, cumulative, context)
+}
+
+/*
+Aggregates intermediate results by merging their t-digests.
+*/
+func (this *ApproxPercentile) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateDigests(this.Name(), part, cumulative)
+}
+
+/*
+Compute the Final. Return NULL if no values of type NUMBER exist,
+otherwise the estimated percentile. The fraction must be a number
+between 0 and 1.
+*/
+func (this *ApproxPercentile) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	fraction, e := this.Operands()[1].Evaluate(value.NULL_VALUE, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if fraction.Type() != value.NUMBER {
+		return value.NULL_VALUE, nil
+	}
+
+	q := value.AsNumberValue(fraction).Float64()
+	if q < 0.0 || q > 1.0 {
+		return nil, fmt.Errorf("%s fraction must be between 0 and 1: %v.", this.Name(), q)
+	}
+
+	return digestQuantile(this.Name(), cumulative, q)
+}
+
+/*
+Add the number value of expr to the t-digest in cumulative.
+*/
+func digestAdd(name string, expr expression.Expression, item, cumulative value.Value,
+	context Context) (value.Value, error) {
+	item, e := expr.Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type() != value.NUMBER {
+		return cumulative, nil
+	}
+
+	av, sketch := addSketch(cumulative, func() interface{} { return newTDigest() })
+	digest, ok := sketch.(*tdigest)
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s sketch %v of type %T.", name, sketch, sketch)
+	}
+
+	digest.Add(value.AsNumberValue(item).Float64())
+	return av, nil
+}
+
+/*
+Merge the t-digest of part into the one of cumulative.
+*/
+func cumulateDigests(name string, part, cumulative value.Value) (value.Value, error) {
+	psketch := getSketch(part)
+	if psketch == nil {
+		return cumulative, nil
+	}
+
+	csketch := getSketch(cumulative)
+	if csketch == nil {
+		return part, nil
+	}
+
+	pdigest, pok := psketch.(*tdigest)
+	cdigest, cok := csketch.(*tdigest)
+	if !pok || !cok {
+		return nil, fmt.Errorf("Invalid %s sketches %T and %T.", name, psketch, csketch)
+	}
+
+	cdigest.Merge(pdigest)
+	return cumulative, nil
+}
+
+func digestQuantile(name string, cumulative value.Value, q float64) (value.Value, error) {
+	sketch := getSketch(cumulative)
+	if sketch == nil {
+		return value.NULL_VALUE, nil
+	}
+
+	digest, ok := sketch.(*tdigest)
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s sketch %v of type %T.", name, sketch, sketch)
+	}
+
+	if digest.Count() == 0 {
+		return value.NULL_VALUE, nil
+	}
+	return value.NewValue(digest.Quantile(q)), nil
+}
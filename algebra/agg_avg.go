@@ -117,7 +117,7 @@ func (this *Avg) CumulateInitial(item, cumulative value.Value, context Context)
 	if this.Distinct() {
 		return setAdd(item, cumulative, true), nil
 	} else {
-		part := value.NewValue(map[string]interface{}{"sum": item, "count": value.ONE_VALUE})
+		part := value.NewValue(map[string]interface{}{"sum": expression.ArithNumber(item, context), "count": value.ONE_VALUE})
 		return this.cumulatePart(part, cumulative, context)
 	}
 }
@@ -144,7 +144,7 @@ func (this *Avg) ComputeFinal(cumulative value.Value, context Context) (value.Va
 	}
 
 	count := float64(0)
-	sum := value.ZERO_NUMBER
+	sum := expression.ArithNumber(value.ZERO_VALUE, context)
 
 	if this.Distinct() {
 		av := cumulative.(value.AnnotatedValue)
@@ -154,7 +154,7 @@ func (this *Avg) ComputeFinal(cumulative value.Value, context Context) (value.Va
 		for _, v := range set.Values() {
 			switch {
 			case v.Type() == value.NUMBER:
-				sum = sum.Add(value.AsNumberValue(v))
+				sum = sum.Add(expression.ArithNumber(v, context))
 			default:
 				return nil, fmt.Errorf("Invalid partial AVG %v of type %T.", v.Actual(), v.Actual())
 			}
@@ -173,7 +173,9 @@ func (this *Avg) ComputeFinal(cumulative value.Value, context Context) (value.Va
 		count = countv.Actual().(float64)
 	}
 
-	if count > 0.0 {
+	if count > 0.0 && expression.UseDecimal(context) {
+		return value.DecimalDiv(sum, value.AsNumberValue(value.NewValue(count))), nil
+	} else if count > 0.0 {
 		return value.NewValue(sum.Actual().(float64) / count), nil
 	} else {
 		return value.NULL_VALUE, nil
@@ -205,7 +207,7 @@ func (this *Avg) CumulateRemove(item, cumulative value.Value, context Context) (
 		csum, sok := cumulative.Field("sum")
 		ccount, cok := cumulative.Field("count")
 		if sok && cok && csum.Type() == value.NUMBER && ccount.Type() == value.NUMBER {
-			cumulative.SetField("sum", value.AsNumberValue(csum).Sub(value.AsNumberValue(item)))
+			cumulative.SetField("sum", value.AsNumberValue(csum).Sub(expression.ArithNumber(item, context)))
 			cumulative.SetField("count", value.AsNumberValue(ccount).Sub(value.AsNumberValue(value.ONE_VALUE)))
 			return cumulative, nil
 		}
@@ -0,0 +1,137 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function BIT_OR(expr). It returns the
+bitwise OR of the integer values in the group.
+Type BitOr is a struct that inherits from AggregateBase.
+*/
+type BitOr struct {
+	AggregateBase
+}
+
+/*
+The function NewBitOr calls NewAggregateBase to
+create an aggregate function named BIT_OR with
+one expression as input.
+*/
+func NewBitOr(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &BitOr{
+		*NewAggregateBase("bit_or", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *BitOr) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *BitOr) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *BitOr) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewBitOr with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *BitOr) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewBitOr(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *BitOr) Copy() expression.Expression {
+	rv := &BitOr{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the BIT_OR function, then the default value
+returned is a null.
+*/
+func (this *BitOr) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than integers
+are ignored.
+*/
+func (this *BitOr) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bitsPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *BitOr) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *BitOr) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bitsPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no integer values,
+otherwise the bits that are set in any of them.
+*/
+func (this *BitOr) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	return bitsResult(this.Name(), cumulative, func(ones, count int64) bool { return ones > 0 })
+}
@@ -0,0 +1,137 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function BIT_XOR(expr). It returns the
+bitwise exclusive OR of the integer values in the group.
+Type BitXor is a struct that inherits from AggregateBase.
+*/
+type BitXor struct {
+	AggregateBase
+}
+
+/*
+The function NewBitXor calls NewAggregateBase to
+create an aggregate function named BIT_XOR with
+one expression as input.
+*/
+func NewBitXor(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &BitXor{
+		*NewAggregateBase("bit_xor", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *BitXor) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *BitXor) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *BitXor) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewBitXor with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *BitXor) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewBitXor(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *BitXor) Copy() expression.Expression {
+	rv := &BitXor{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the BIT_XOR function, then the default value
+returned is a null.
+*/
+func (this *BitXor) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than integers
+are ignored.
+*/
+func (this *BitXor) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bitsPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *BitXor) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *BitXor) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bitsPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no integer values,
+otherwise the bits that are set in an odd number of them.
+*/
+func (this *BitXor) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	return bitsResult(this.Name(), cumulative, func(ones, count int64) bool { return ones&1 != 0 })
+}
@@ -0,0 +1,144 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function BOOL_AND(expr), also named
+EVERY(expr). It returns true if all the boolean values in the group
+are true.
+Type BoolAnd is a struct that inherits from AggregateBase.
+*/
+type BoolAnd struct {
+	AggregateBase
+}
+
+/*
+The function NewBoolAnd calls NewAggregateBase to
+create an aggregate function named BOOL_AND with
+one expression as input.
+*/
+func NewBoolAnd(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &BoolAnd{
+		*NewAggregateBase("bool_and", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *BoolAnd) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type BOOLEAN.
+*/
+func (this *BoolAnd) Type() value.Type { return value.BOOLEAN }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *BoolAnd) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewBoolAnd with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *BoolAnd) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewBoolAnd(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *BoolAnd) Copy() expression.Expression {
+	rv := &BoolAnd{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the BOOL_AND function, then the default value
+returned is a null.
+*/
+func (this *BoolAnd) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than booleans
+are ignored.
+*/
+func (this *BoolAnd) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := booleanPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *BoolAnd) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *BoolAnd) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := booleanPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no boolean values.
+*/
+func (this *BoolAnd) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	count, trues, e := booleanCounts(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	} else if count == 0.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(trues == count), nil
+}
@@ -0,0 +1,143 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function BOOL_OR(expr). It returns true
+if any of the boolean values in the group is true.
+Type BoolOr is a struct that inherits from AggregateBase.
+*/
+type BoolOr struct {
+	AggregateBase
+}
+
+/*
+The function NewBoolOr calls NewAggregateBase to
+create an aggregate function named BOOL_OR with
+one expression as input.
+*/
+func NewBoolOr(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &BoolOr{
+		*NewAggregateBase("bool_or", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *BoolOr) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type BOOLEAN.
+*/
+func (this *BoolOr) Type() value.Type { return value.BOOLEAN }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *BoolOr) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewBoolOr with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *BoolOr) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewBoolOr(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *BoolOr) Copy() expression.Expression {
+	rv := &BoolOr{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the BOOL_OR function, then the default value
+returned is a null.
+*/
+func (this *BoolOr) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than booleans
+are ignored.
+*/
+func (this *BoolOr) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := booleanPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *BoolOr) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *BoolOr) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := booleanPart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no boolean values.
+*/
+func (this *BoolOr) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	count, trues, e := booleanCounts(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	} else if count == 0.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(trues > 0.0), nil
+}
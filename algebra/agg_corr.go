@@ -0,0 +1,156 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"math"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function CORR(y, x). It returns the
+correlation coefficient of the pairs of number values in the group.
+Type Corr is a struct that inherits from AggregateBase.
+*/
+type Corr struct {
+	AggregateBase
+}
+
+/*
+The function NewCorr calls NewAggregateBase to
+create an aggregate function named CORR with
+two expressions as input.
+*/
+func NewCorr(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &Corr{
+		*NewAggregateBase("corr", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *Corr) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *Corr) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *Corr) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewCorr with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *Corr) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewCorr(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *Corr) Copy() expression.Expression {
+	rv := &Corr{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 2.
+*/
+func (this *Corr) MinArgs() int { return 2 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *Corr) MaxArgs() int { return 2 }
+
+/*
+If no input to the CORR function, then the default value
+returned is a null.
+*/
+func (this *Corr) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Pairs where either value is not
+a number are ignored.
+*/
+func (this *Corr) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *Corr) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *Corr) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no pairs, or if either
+variable has no variance.
+*/
+func (this *Corr) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	n, _, _, sxx, syy, sxy, e := bivariateSums(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	} else if n == 0.0 || sxx <= 0.0 || syy <= 0.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(sxy / math.Sqrt(sxx*syy)), nil
+}
@@ -0,0 +1,153 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function COVAR_SAMP(y, x). It returns the
+sample covariance of the pairs of number values in the group.
+Type CovarSamp is a struct that inherits from AggregateBase.
+*/
+type CovarSamp struct {
+	AggregateBase
+}
+
+/*
+The function NewCovarSamp calls NewAggregateBase to
+create an aggregate function named COVAR_SAMP with
+two expressions as input.
+*/
+func NewCovarSamp(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &CovarSamp{
+		*NewAggregateBase("covar_samp", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *CovarSamp) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *CovarSamp) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *CovarSamp) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewCovarSamp with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *CovarSamp) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewCovarSamp(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *CovarSamp) Copy() expression.Expression {
+	rv := &CovarSamp{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 2.
+*/
+func (this *CovarSamp) MinArgs() int { return 2 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *CovarSamp) MaxArgs() int { return 2 }
+
+/*
+If no input to the COVAR_SAMP function, then the default value
+returned is a null.
+*/
+func (this *CovarSamp) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Pairs where either value is not
+a number are ignored.
+*/
+func (this *CovarSamp) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *CovarSamp) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *CovarSamp) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are fewer than two pairs.
+*/
+func (this *CovarSamp) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	n, _, _, _, _, sxy, e := bivariateSums(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	} else if n < 2.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(sxy / (n - 1.0)), nil
+}
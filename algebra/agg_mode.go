@@ -0,0 +1,154 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the ordered-set aggregate function
+MODE() WITHIN GROUP (ORDER BY expr). It returns the most frequent
+value in the group. If several values are equally frequent, the
+first of them in sort order is returned.
+Type Mode is a struct that inherits from AggregateBase.
+*/
+type Mode struct {
+	AggregateBase
+}
+
+/*
+The function NewMode calls NewAggregateBase to
+create an aggregate function named mode with
+no arguments as input.
+*/
+func NewMode(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &Mode{
+		*NewAggregateBase("mode", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *Mode) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type JSON.
+*/
+func (this *Mode) Type() value.Type { return value.JSON }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *Mode) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewMode with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *Mode) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewMode(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *Mode) Copy() expression.Expression {
+	rv := &Mode{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.SetWithinGroup(CopyOrder(this.WithinGroup()))
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+MODE takes no arguments.
+*/
+func (this *Mode) MinArgs() int { return 0 }
+
+/*
+MODE takes no arguments.
+*/
+func (this *Mode) MaxArgs() int { return 0 }
+
+/*
+If no input to the MODE function, then the default value
+returned is a null.
+*/
+func (this *Mode) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating the WITHIN GROUP sort term.
+NULL and MISSING values are ignored.
+*/
+func (this *Mode) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	return sortKeyAdd(this, item, cumulative, context, false)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *Mode) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateOrdered(part, cumulative)
+}
+
+/*
+Compute the Final. Sort the values, so that equal values are
+next to each other, and return the one with the longest run.
+*/
+func (this *Mode) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	if cumulative == value.NULL_VALUE {
+		return cumulative, nil
+	}
+
+	vals, e := sortedKeys(this, cumulative, context)
+	if e != nil || len(vals) == 0 {
+		return value.NULL_VALUE, e
+	}
+
+	rv := vals[0]
+	best := 0
+	for i := 0; i < len(vals); {
+		j := i + 1
+		for j < len(vals) && vals[j].Collate(vals[i]) == 0 {
+			j++
+		}
+		if j-i > best {
+			rv = vals[i]
+			best = j - i
+		}
+		i = j
+	}
+	return rv, nil
+}
@@ -0,0 +1,228 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Helpers for the bivariate statistics, boolean and bitwise aggregates.
+Their intermediate values are objects of running counts and sums, so
+partial results can be added up in CumulateIntermediate and input
+values can be subtracted again in CumulateRemove, as for AVG.
+*/
+
+/*
+Evaluate the (y, x) pair of a bivariate aggregate. Returns nil if
+either is not a number, otherwise the counts and sums of the pair.
+*/
+func bivariatePart(agg Aggregate, item value.Value, context Context) (value.Value, error) {
+	y, e := agg.Operands()[0].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	x, e := agg.Operands()[1].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if y.Type() != value.NUMBER || x.Type() != value.NUMBER {
+		return nil, nil
+	}
+
+	fy := value.AsNumberValue(y).Float64()
+	fx := value.AsNumberValue(x).Float64()
+	return value.NewValue(map[string]interface{}{
+		"count": 1.0,
+		"sx":    fx,
+		"sy":    fy,
+		"sxx":   fx * fx,
+		"syy":   fy * fy,
+		"sxy":   fx * fy,
+	}), nil
+}
+
+/*
+Return the number of pairs, and the sums of squares and products of
+the deviations from the means: Sxx, Syy and Sxy.
+*/
+func bivariateSums(name string, cumulative value.Value) (n, sx, sy, sxx, syy, sxy float64, e error) {
+	if cumulative.Type() != value.OBJECT {
+		return
+	}
+
+	fields := [6]*float64{&n, &sx, &sy, &sxx, &syy, &sxy}
+	for i, f := range [6]string{"count", "sx", "sy", "sxx", "syy", "sxy"} {
+		v, _ := cumulative.Field(f)
+		if v.Type() != value.NUMBER {
+			e = fmt.Errorf("Missing or invalid %s in %s: %v.", f, name, v.Actual())
+			return
+		}
+		*fields[i] = value.AsNumberValue(v).Float64()
+	}
+
+	if n > 0.0 {
+		sxx -= sx * sx / n
+		syy -= sy * sy / n
+		sxy -= sx * sy / n
+	}
+	return
+}
+
+/*
+Evaluate the operand of a boolean aggregate. Returns nil for values
+other than booleans, otherwise the count of true or false values.
+*/
+func booleanPart(agg Aggregate, item value.Value, context Context) (value.Value, error) {
+	item, e := agg.Operands()[0].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type() != value.BOOLEAN {
+		return nil, nil
+	}
+
+	return value.NewValue(map[string]interface{}{
+		"count":                          1.0,
+		strconv.FormatBool(item.Truth()): 1.0,
+	}), nil
+}
+
+/*
+Return the number of boolean values and how many of them are true.
+*/
+func booleanCounts(name string, cumulative value.Value) (count, trues float64, e error) {
+	return countField(name, cumulative, "count", "true")
+}
+
+/*
+Evaluate the operand of a bitwise aggregate. Returns nil for values
+other than integers, otherwise the count of values and of each bit
+that is set.
+*/
+func bitsPart(agg Aggregate, item value.Value, context Context) (value.Value, error) {
+	item, e := agg.Operands()[0].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type() != value.NUMBER {
+		return nil, nil
+	}
+
+	i, ok := value.IsIntValue(item)
+	if !ok {
+		return nil, nil
+	}
+
+	part := map[string]interface{}{"count": 1.0}
+	for b, bits := uint(0), uint64(i); bits != 0; b, bits = b+1, bits>>1 {
+		if bits&1 != 0 {
+			part[strconv.Itoa(int(b))] = 1.0
+		}
+	}
+	return value.NewValue(part), nil
+}
+
+/*
+Combine the counts of each bit into the result. A bit is set in the
+result if set returns true for the number of values with the bit set
+out of all the values.
+*/
+func bitsResult(name string, cumulative value.Value, set func(ones, count int64) bool) (value.Value, error) {
+	if cumulative.Type() != value.OBJECT {
+		return value.NULL_VALUE, nil
+	}
+
+	count, _, e := countField(name, cumulative, "count", "")
+	if e != nil {
+		return nil, e
+	} else if count == 0.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	var rv uint64
+	for b := uint(0); b < 64; b++ {
+		ones, _, e := countField(name, cumulative, strconv.Itoa(int(b)), "")
+		if e != nil {
+			return nil, e
+		}
+		if set(int64(ones), int64(count)) {
+			rv |= 1 << b
+		}
+	}
+	return value.NewValue(int64(rv)), nil
+}
+
+/*
+Return the counts in fields f1 and f2 of the cumulative value.
+Missing counts are zero.
+*/
+func countField(name string, cumulative value.Value, f1, f2 string) (c1, c2 float64, e error) {
+	if cumulative.Type() != value.OBJECT {
+		return
+	}
+
+	for _, f := range []struct {
+		name string
+		c    *float64
+	}{{f1, &c1}, {f2, &c2}} {
+		if f.name == "" {
+			continue
+		}
+		v, ok := cumulative.Field(f.name)
+		if !ok {
+			continue
+		} else if v.Type() != value.NUMBER {
+			return 0.0, 0.0, fmt.Errorf("Invalid %s in %s: %v.", f.name, name, v.Actual())
+		}
+		*f.c = value.AsNumberValue(v).Float64()
+	}
+	return
+}
+
+/*
+Add (sign 1) or subtract (sign -1) the counts and sums of part
+to or from the cumulative value. Part is nil or not an object when
+there was no input to count, e.g. the default value of a group.
+*/
+func cumulateMoments(name string, part, cumulative value.Value, sign float64) (value.Value, error) {
+	if part == nil || part.Type() != value.OBJECT {
+		return cumulative, nil
+	} else if cumulative.Type() != value.OBJECT {
+		if sign < 0.0 {
+			return nil, fmt.Errorf("Invalid %s.CumulateRemove() for %v value.", name, cumulative.Actual())
+		}
+		return part, nil
+	}
+
+	for f, pv := range part.Fields() {
+		pval := value.NewValue(pv)
+		if pval.Type() != value.NUMBER {
+			return nil, fmt.Errorf("Invalid partial %s %v of type %T.", name, pv, pv)
+		}
+		p := value.AsNumberValue(pval).Float64()
+
+		c := 0.0
+		if cv, ok := cumulative.Field(f); ok {
+			if cv.Type() != value.NUMBER {
+				return nil, fmt.Errorf("Invalid %s %v of type %T.", name, cv.Actual(), cv.Actual())
+			}
+			c = value.AsNumberValue(cv).Float64()
+		}
+		cumulative.SetField(f, c+sign*p)
+	}
+	return cumulative, nil
+}
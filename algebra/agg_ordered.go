@@ -0,0 +1,131 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"sort"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Helpers for the aggregates with a WITHIN GROUP (ORDER BY ...)
+clause. The input values are collected in the "list" attachment,
+exactly like ARRAY_AGG, and sorted when the final value is computed.
+*/
+
+/*
+Add the value of the single WITHIN GROUP sort term to the list.
+NULL and MISSING values are ignored, as are values other than
+numbers if numeric is set.
+*/
+func sortKeyAdd(agg Aggregate, item, cumulative value.Value, context Context, numeric bool) (value.Value, error) {
+	item, e := agg.WithinGroup().Terms()[0].Expression().Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if item.Type() <= value.NULL || (numeric && item.Type() != value.NUMBER) {
+		return cumulative, nil
+	}
+
+	return listAdd(item, cumulative), nil
+}
+
+/*
+Return the collected values sorted on the single WITHIN GROUP sort
+term. The list is left as it is, as window aggregates may compute
+the final value more than once.
+*/
+func sortedKeys(agg Aggregate, cumulative value.Value, context Context) (value.Values, error) {
+	list, e := getList(cumulative)
+	if e != nil {
+		return nil, e
+	}
+
+	vals := make(value.Values, list.Len())
+	copy(vals, list.Values())
+
+	descending := agg.WithinGroup().Terms()[0].Descending(context)
+	sort.SliceStable(vals, func(i, j int) bool {
+		c := vals[i].Collate(vals[j])
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	return vals, nil
+}
+
+/*
+Sort entries of the form [value, key1, key2, ...] on their keys,
+following the WITHIN GROUP sort terms, and return the values.
+*/
+func sortedEntries(order *Order, entries value.Values, context Context) value.Values {
+	terms := order.Terms()
+	descending := make([]bool, len(terms))
+	nullsLast := make([]bool, len(terms))
+	for i, term := range terms {
+		descending[i] = term.Descending(context)
+		nullsLast[i] = term.NullsLast(context)
+	}
+
+	keys := make([][]interface{}, len(entries))
+	for i, entry := range entries {
+		keys[i], _ = entry.Actual().([]interface{})
+	}
+
+	perm := make([]int, len(entries))
+	for i := range perm {
+		perm[i] = i
+	}
+
+	sort.SliceStable(perm, func(i, j int) bool {
+		k1 := keys[perm[i]]
+		k2 := keys[perm[j]]
+		for t := range terms {
+			if t+1 >= len(k1) || t+1 >= len(k2) {
+				break
+			}
+
+			ev1 := value.NewValue(k1[t+1])
+			ev2 := value.NewValue(k2[t+1])
+
+			var c int
+			if (descending[t] && nullsLast[t]) || (!descending[t] && !nullsLast[t]) ||
+				((ev1.Type() <= value.NULL && ev2.Type() <= value.NULL) ||
+					(ev1.Type() > value.NULL && ev2.Type() > value.NULL)) {
+				c = ev1.Collate(ev2)
+			} else if ev1.Type() <= value.NULL && ev2.Type() > value.NULL {
+				c = 1
+			} else {
+				c = -1
+			}
+
+			if c == 0 {
+				continue
+			} else if descending[t] {
+				return c > 0
+			} else {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	rv := make(value.Values, len(entries))
+	for i, p := range perm {
+		if len(keys[p]) > 0 {
+			rv[i] = value.NewValue(keys[p][0])
+		} else {
+			rv[i] = entries[p]
+		}
+	}
+	return rv
+}
@@ -0,0 +1,183 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the ordered-set aggregate function
+PERCENTILE_CONT(fraction) WITHIN GROUP (ORDER BY expr). It returns
+the value at the given fraction of the sorted number values in the
+group, interpolating linearly between the two nearest values.
+Type PercentileCont is a struct that inherits from AggregateBase.
+*/
+type PercentileCont struct {
+	AggregateBase
+}
+
+/*
+The function NewPercentileCont calls NewAggregateBase to
+create an aggregate function named percentile_cont with
+the fraction as input.
+*/
+func NewPercentileCont(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &PercentileCont{
+		*NewAggregateBase("percentile_cont", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *PercentileCont) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *PercentileCont) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *PercentileCont) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewPercentileCont with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *PercentileCont) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewPercentileCont(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *PercentileCont) Copy() expression.Expression {
+	rv := &PercentileCont{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.SetWithinGroup(CopyOrder(this.WithinGroup()))
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the PERCENTILE_CONT function, then the default value
+returned is a null.
+*/
+func (this *PercentileCont) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating the WITHIN GROUP sort term.
+Values other than numbers are ignored.
+*/
+func (this *PercentileCont) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	return sortKeyAdd(this, item, cumulative, context, true)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *PercentileCont) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateOrdered(part, cumulative)
+}
+
+/*
+Compute the Final. Return NULL if no values of type NUMBER exist.
+Otherwise sort the values and interpolate between the two values
+around row fraction * (count - 1).
+*/
+func (this *PercentileCont) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	if cumulative == value.NULL_VALUE {
+		return cumulative, nil
+	}
+
+	q, null, e := orderedFraction(this, context)
+	if e != nil || null {
+		return value.NULL_VALUE, e
+	}
+
+	vals, e := sortedKeys(this, cumulative, context)
+	if e != nil || len(vals) == 0 {
+		return value.NULL_VALUE, e
+	}
+
+	row := q * float64(len(vals)-1)
+	lo := math.Floor(row)
+	hi := math.Ceil(row)
+	v1 := value.AsNumberValue(vals[int(lo)]).Float64()
+	if lo == hi {
+		return vals[int(lo)], nil
+	}
+
+	v2 := value.AsNumberValue(vals[int(hi)]).Float64()
+	return value.NewValue(v1 + (row-lo)*(v2-v1)), nil
+}
+
+/*
+Evaluate the fraction argument of PERCENTILE_CONT and PERCENTILE_DISC.
+Returns true if the fraction is not a number.
+*/
+func orderedFraction(agg Aggregate, context Context) (float64, bool, error) {
+	fraction, e := agg.Operands()[0].Evaluate(value.NULL_VALUE, context)
+	if e != nil {
+		return 0.0, true, e
+	}
+
+	if fraction.Type() != value.NUMBER {
+		return 0.0, true, nil
+	}
+
+	q := value.AsNumberValue(fraction).Float64()
+	if q < 0.0 || q > 1.0 {
+		return 0.0, true, fmt.Errorf("%s fraction must be between 0 and 1: %v.", agg.Name(), q)
+	}
+	return q, false, nil
+}
+
+/*
+Aggregate the collected values of intermediate results. Groups
+without any input still have the default value, which has no list.
+*/
+func cumulateOrdered(part, cumulative value.Value) (value.Value, error) {
+	if _, ok := part.(value.AnnotatedValue); !ok {
+		return cumulative, nil
+	} else if _, ok := cumulative.(value.AnnotatedValue); !ok {
+		return part, nil
+	}
+	return cumulateLists(part, cumulative)
+}
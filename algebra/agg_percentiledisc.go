@@ -0,0 +1,142 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"math"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the ordered-set aggregate function
+PERCENTILE_DISC(fraction) WITHIN GROUP (ORDER BY expr). It returns
+the first of the sorted values in the group whose cumulative
+distribution is at least the given fraction.
+Type PercentileDisc is a struct that inherits from AggregateBase.
+*/
+type PercentileDisc struct {
+	AggregateBase
+}
+
+/*
+The function NewPercentileDisc calls NewAggregateBase to
+create an aggregate function named percentile_disc with
+the fraction as input.
+*/
+func NewPercentileDisc(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &PercentileDisc{
+		*NewAggregateBase("percentile_disc", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *PercentileDisc) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type JSON.
+*/
+func (this *PercentileDisc) Type() value.Type { return value.JSON }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *PercentileDisc) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewPercentileDisc with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *PercentileDisc) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewPercentileDisc(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *PercentileDisc) Copy() expression.Expression {
+	rv := &PercentileDisc{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.SetWithinGroup(CopyOrder(this.WithinGroup()))
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+If no input to the PERCENTILE_DISC function, then the default value
+returned is a null.
+*/
+func (this *PercentileDisc) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating the WITHIN GROUP sort term.
+NULL and MISSING values are ignored.
+*/
+func (this *PercentileDisc) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	return sortKeyAdd(this, item, cumulative, context, false)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *PercentileDisc) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateOrdered(part, cumulative)
+}
+
+/*
+Compute the Final. Return NULL if there are no values, otherwise the
+value at position ceil(fraction * count) in sort order.
+*/
+func (this *PercentileDisc) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	if cumulative == value.NULL_VALUE {
+		return cumulative, nil
+	}
+
+	q, null, e := orderedFraction(this, context)
+	if e != nil || null {
+		return value.NULL_VALUE, e
+	}
+
+	vals, e := sortedKeys(this, cumulative, context)
+	if e != nil || len(vals) == 0 {
+		return value.NULL_VALUE, e
+	}
+
+	pos := int(math.Ceil(q*float64(len(vals)))) - 1
+	if pos < 0 {
+		pos = 0
+	}
+	return vals[pos], nil
+}
@@ -66,6 +66,11 @@ const (
 	AGGREGATE_WINDOW_FROMLAST
 	AGGREGATE_WINDOW_2ND_POSINT
 	AGGREGATE_WINDOW_2ND_DYNAMIC
+	AGGREGATE_2ND_FRACTION
+	AGGREGATE_1ST_FRACTION
+	AGGREGATE_2ND_CONSTANT
+	AGGREGATE_ALLOWS_WITHIN_GROUP
+	AGGREGATE_REQUIRES_WITHIN_GROUP
 )
 
 /*
@@ -79,6 +84,10 @@ const (
 	AGGREGATE_ALLOWS_FL              = AGGREGATE_ALLOWS_WINDOW | AGGREGATE_ALLOWS_WINDOW_FRAME | AGGREGATE_WINDOW_RESPECTNULLS | AGGREGATE_WINDOW_IGNORENULLS
 	AGGREGATE_ALLOWS_NTH             = AGGREGATE_ALLOWS_FL | AGGREGATE_WINDOW_FROMFIRST | AGGREGATE_WINDOW_FROMLAST | AGGREGATE_WINDOW_2ND_POSINT | AGGREGATE_WINDOW_2ND_DYNAMIC
 	AGGREGATE_ALLOWS_LAGLEAD         = AGGREGATE_ALLOWS_WINDOW | AGGREGATE_WINDOW_ORDER | AGGREGATE_WINDOW_RESPECTNULLS | AGGREGATE_WINDOW_IGNORENULLS | AGGREGATE_WINDOW_2ND_POSINT
+	AGGREGATE_ALLOWS_APPROX          = AGGREGATE_ALLOWS_ALL &^ AGGREGATE_ALLOWS_DISTINCT
+	AGGREGATE_ALLOWS_ORDERED         = AGGREGATE_ALLOWS_APPROX | AGGREGATE_ALLOWS_WITHIN_GROUP
+	AGGREGATE_ORDERED_SET            = AGGREGATE_ALLOWS_ORDERED | AGGREGATE_REQUIRES_WITHIN_GROUP
+	AGGREGATE_ALLOWS_MOMENTS         = AGGREGATE_ALLOWS_APPROX | AGGREGATE_ALLOWS_INCREMENTAL
 )
 
 /*
@@ -145,4 +154,31 @@ var _AGGREGATES = map[string]*AggregateRegistry{
 	"nth_value":       &AggregateRegistry{property: AGGREGATE_ALLOWS_NTH, agg: &NthValue{}},
 	"lag":             &AggregateRegistry{property: AGGREGATE_ALLOWS_LAGLEAD, agg: &Lag{}},
 	"lead":            &AggregateRegistry{property: AGGREGATE_ALLOWS_LAGLEAD, agg: &Lead{}},
+
+	// approximate aggregates, with mergeable sketches as intermediate values
+	"approx_count_distinct": &AggregateRegistry{property: AGGREGATE_ALLOWS_APPROX, agg: &ApproxCountDistinct{}},
+	"approx_median":         &AggregateRegistry{property: AGGREGATE_ALLOWS_APPROX, agg: &ApproxMedian{}},
+	"approx_percentile":     &AggregateRegistry{property: AGGREGATE_ALLOWS_APPROX | AGGREGATE_2ND_FRACTION, agg: &ApproxPercentile{}},
+
+	// ordered-set aggregates, which take their input order from WITHIN GROUP (ORDER BY ...)
+	"listagg":         &AggregateRegistry{property: AGGREGATE_ALLOWS_ORDERED | AGGREGATE_2ND_CONSTANT, agg: &StringAgg{}},
+	"mode":            &AggregateRegistry{property: AGGREGATE_ORDERED_SET, agg: &Mode{}},
+	"percentile_cont": &AggregateRegistry{property: AGGREGATE_ORDERED_SET | AGGREGATE_1ST_FRACTION, agg: &PercentileCont{}},
+	"percentile_disc": &AggregateRegistry{property: AGGREGATE_ORDERED_SET | AGGREGATE_1ST_FRACTION, agg: &PercentileDisc{}},
+	"string_agg":      &AggregateRegistry{property: AGGREGATE_ALLOWS_ORDERED | AGGREGATE_2ND_CONSTANT, agg: &StringAgg{}},
+
+	// bivariate statistics, boolean and bitwise aggregates, with running counts and sums as intermediate values
+	"bit_and":        &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BitAnd{}},
+	"bit_or":         &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BitOr{}},
+	"bit_xor":        &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BitXor{}},
+	"bool_and":       &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BoolAnd{}},
+	"bool_or":        &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BoolOr{}},
+	"corr":           &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &Corr{}},
+	"covar_pop":      &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &CovarPop{}},
+	"covar_samp":     &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &CovarSamp{}},
+	"every":          &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &BoolAnd{}},
+	"regr_count":     &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &RegrCount{}},
+	"regr_intercept": &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &RegrIntercept{}},
+	"regr_r2":        &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &RegrR2{}},
+	"regr_slope":     &AggregateRegistry{property: AGGREGATE_ALLOWS_MOMENTS, agg: &RegrSlope{}},
 }
@@ -0,0 +1,151 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function REGR_COUNT(y, x). It returns the
+number of pairs in the group where both values are numbers.
+Type RegrCount is a struct that inherits from AggregateBase.
+*/
+type RegrCount struct {
+	AggregateBase
+}
+
+/*
+The function NewRegrCount calls NewAggregateBase to
+create an aggregate function named REGR_COUNT with
+two expressions as input.
+*/
+func NewRegrCount(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &RegrCount{
+		*NewAggregateBase("regr_count", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *RegrCount) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *RegrCount) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *RegrCount) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewRegrCount with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *RegrCount) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewRegrCount(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *RegrCount) Copy() expression.Expression {
+	rv := &RegrCount{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 2.
+*/
+func (this *RegrCount) MinArgs() int { return 2 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *RegrCount) MaxArgs() int { return 2 }
+
+/*
+If no input to the REGR_COUNT function, then the default value
+returned is a zero.
+*/
+func (this *RegrCount) Default(item value.Value, context Context) (value.Value, error) {
+	return value.ZERO_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Pairs where either value is not
+a number are ignored.
+*/
+func (this *RegrCount) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *RegrCount) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *RegrCount) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return the number of pairs.
+*/
+func (this *RegrCount) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	n, _, _, _, _, _, e := bivariateSums(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	}
+
+	return value.NewValue(n), nil
+}
@@ -0,0 +1,157 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function REGR_R2(y, x). It returns the
+coefficient of determination of the least-squares regression line of
+y on x, fitted to the pairs of number values in the group.
+Type RegrR2 is a struct that inherits from AggregateBase.
+*/
+type RegrR2 struct {
+	AggregateBase
+}
+
+/*
+The function NewRegrR2 calls NewAggregateBase to
+create an aggregate function named REGR_R2 with
+two expressions as input.
+*/
+func NewRegrR2(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &RegrR2{
+		*NewAggregateBase("regr_r2", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *RegrR2) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type NUMBER.
+*/
+func (this *RegrR2) Type() value.Type { return value.NUMBER }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *RegrR2) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewRegrR2 with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *RegrR2) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewRegrR2(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *RegrR2) Copy() expression.Expression {
+	rv := &RegrR2{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 2.
+*/
+func (this *RegrR2) MinArgs() int { return 2 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *RegrR2) MaxArgs() int { return 2 }
+
+/*
+If no input to the REGR_R2 function, then the default value
+returned is a null.
+*/
+func (this *RegrR2) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Pairs where either value is not
+a number are ignored.
+*/
+func (this *RegrR2) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *RegrR2) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateMoments(this.Name(), part, cumulative, 1.0)
+}
+
+/*
+Used for Incremental Aggregation.
+Remove the input data by evaluating operands.
+*/
+func (this *RegrR2) CumulateRemove(item, cumulative value.Value, context Context) (value.Value, error) {
+	part, e := bivariatePart(this, item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	return cumulateMoments(this.Name(), part, cumulative, -1.0)
+}
+
+/*
+Compute the Final. Return NULL if there are no pairs, or if x has
+no variance, and 1 if y has no variance.
+*/
+func (this *RegrR2) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	n, _, _, sxx, syy, sxy, e := bivariateSums(this.Name(), cumulative)
+	if e != nil {
+		return nil, e
+	} else if n == 0.0 || sxx <= 0.0 {
+		return value.NULL_VALUE, nil
+	} else if syy <= 0.0 {
+		return value.ONE_VALUE, nil
+	}
+
+	return value.NewValue(sxy * sxy / (sxx * syy)), nil
+}
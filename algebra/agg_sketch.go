@@ -0,0 +1,325 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Sketches used by the approximate aggregates. Both are kept as the
+"sketch" attachment of the cumulative value and can be merged, so
+that partial aggregates from InitialGroup and IntermediateGroup
+combine into the same result as a single pass over the input.
+*/
+
+/*
+HyperLogLog with 2^_HLL_PRECISION registers, giving a standard
+error of about 0.8%. Small cardinalities are kept in a sparse map
+of registers, which is converted to the dense form once it holds
+more than _HLL_SPARSE_MAX entries.
+*/
+const (
+	_HLL_PRECISION  = 14
+	_HLL_REGISTERS  = 1 << _HLL_PRECISION
+	_HLL_SPARSE_MAX = _HLL_REGISTERS / 16
+)
+
+type hllSketch struct {
+	sparse map[uint32]uint8
+	dense  []uint8
+}
+
+func newHLLSketch() *hllSketch {
+	return &hllSketch{sparse: make(map[uint32]uint8, 16)}
+}
+
+func (this *hllSketch) Add(item value.Value) {
+	h := hashValue(item)
+	idx := uint32(h >> (64 - _HLL_PRECISION))
+	rank := uint8(bits.LeadingZeros64(h<<_HLL_PRECISION|1<<(_HLL_PRECISION-1)) + 1)
+	this.set(idx, rank)
+}
+
+func (this *hllSketch) set(idx uint32, rank uint8) {
+	if this.dense != nil {
+		if rank > this.dense[idx] {
+			this.dense[idx] = rank
+		}
+		return
+	}
+
+	if rank > this.sparse[idx] {
+		this.sparse[idx] = rank
+		if len(this.sparse) > _HLL_SPARSE_MAX {
+			this.dense = make([]uint8, _HLL_REGISTERS)
+			for i, r := range this.sparse {
+				this.dense[i] = r
+			}
+			this.sparse = nil
+		}
+	}
+}
+
+/*
+Merge other into the receiver. Both sketches have the same
+precision, so merging is the register-wise maximum.
+*/
+func (this *hllSketch) Merge(other *hllSketch) {
+	if other.dense != nil {
+		for i, r := range other.dense {
+			if r != 0 {
+				this.set(uint32(i), r)
+			}
+		}
+		return
+	}
+
+	for i, r := range other.sparse {
+		this.set(i, r)
+	}
+}
+
+func (this *hllSketch) Estimate() int64 {
+	m := float64(_HLL_REGISTERS)
+
+	if this.dense == nil {
+		return linearCount(m, m-float64(len(this.sparse)))
+	}
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range this.dense {
+		sum += 1.0 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := (0.7213 / (1.0 + 1.079/m)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		return linearCount(m, float64(zeros))
+	}
+	return int64(math.Floor(estimate + 0.5))
+}
+
+func linearCount(m, zeros float64) int64 {
+	return int64(math.Floor(m*math.Log(m/zeros) + 0.5))
+}
+
+/*
+Numbers hash by value, so that 1 and 1.0 are the same, everything
+else by its JSON encoding.
+*/
+func hashValue(item value.Value) uint64 {
+	if item.Type() == value.NUMBER {
+		f := value.AsNumberValue(item).Float64()
+		if f == 0 {
+			f = 0 // normalize -0
+		}
+		return mix64(math.Float64bits(f))
+	}
+
+	h := fnv.New64a()
+	bytes, _ := item.MarshalJSON()
+	h.Write([]byte{byte(item.Type())})
+	h.Write(bytes)
+	return mix64(h.Sum64())
+}
+
+/*
+MurmurHash3 64 bit finalizer. FNV does not spread its input well
+enough over the high bits HyperLogLog uses for the register index.
+*/
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+/*
+Merging t-digest with compression _TDIGEST_COMPRESSION. Incoming
+values and merged centroids are buffered and folded into the sorted
+centroid list when the buffer fills up or a quantile is asked for.
+Centroids near the tails are kept small, so extreme quantiles are
+more accurate than the median.
+*/
+const (
+	_TDIGEST_COMPRESSION = 100.0
+	_TDIGEST_BUFFER      = 5 * int(_TDIGEST_COMPRESSION)
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+type tdigest struct {
+	centroids []centroid
+	buffer    []centroid
+	total     float64
+	min       float64
+	max       float64
+}
+
+func newTDigest() *tdigest {
+	return &tdigest{
+		min: math.Inf(1),
+		max: math.Inf(-1),
+	}
+}
+
+func (this *tdigest) Add(x float64) {
+	this.add(centroid{x, 1.0})
+}
+
+func (this *tdigest) add(c centroid) {
+	this.buffer = append(this.buffer, c)
+	this.total += c.weight
+	if c.mean < this.min {
+		this.min = c.mean
+	}
+	if c.mean > this.max {
+		this.max = c.mean
+	}
+	if len(this.buffer) >= _TDIGEST_BUFFER {
+		this.compress()
+	}
+}
+
+func (this *tdigest) Merge(other *tdigest) {
+	for _, c := range other.centroids {
+		this.add(c)
+	}
+	for _, c := range other.buffer {
+		this.add(c)
+	}
+	this.min = math.Min(this.min, other.min)
+	this.max = math.Max(this.max, other.max)
+}
+
+func (this *tdigest) Count() float64 {
+	return this.total
+}
+
+/*
+Two neighbouring centroids are merged as long as together they
+span no more than one unit of the scale function k(q).
+*/
+func (this *tdigest) compress() {
+	if len(this.buffer) == 0 {
+		return
+	}
+
+	all := append(this.centroids, this.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(this.centroids)+1)
+	cur := all[0]
+	soFar := 0.0
+	for _, c := range all[1:] {
+		if tdigestScale((soFar+cur.weight+c.weight)/this.total)-tdigestScale(soFar/this.total) <= 1.0 {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+		} else {
+			soFar += cur.weight
+			merged = append(merged, cur)
+			cur = c
+		}
+	}
+
+	this.centroids = append(merged, cur)
+	this.buffer = nil
+}
+
+func tdigestScale(q float64) float64 {
+	if q > 1.0 {
+		q = 1.0
+	}
+	return _TDIGEST_COMPRESSION / (2.0 * math.Pi) * math.Asin(2.0*q-1.0)
+}
+
+/*
+Interpolate between the centers of the centroids around the target
+rank. Below the first and above the last center, interpolate towards
+the smallest and largest value seen.
+*/
+func (this *tdigest) Quantile(q float64) float64 {
+	this.compress()
+
+	n := len(this.centroids)
+	if n == 0 {
+		return math.NaN()
+	} else if q <= 0.0 {
+		return this.min
+	} else if q >= 1.0 {
+		return this.max
+	}
+
+	target := q * this.total
+	first := this.centroids[0]
+	if target < first.weight/2.0 {
+		return this.min + (first.mean-this.min)*target/(first.weight/2.0)
+	}
+
+	center := first.weight / 2.0
+	for i := 1; i < n; i++ {
+		prev := this.centroids[i-1]
+		c := this.centroids[i]
+		next := center + (prev.weight+c.weight)/2.0
+		if target <= next {
+			return prev.mean + (c.mean-prev.mean)*(target-center)/(next-center)
+		}
+		center = next
+	}
+
+	last := this.centroids[n-1]
+	rest := this.total - center
+	if rest <= 0.0 {
+		return this.max
+	}
+	return last.mean + (this.max-last.mean)*(target-center)/rest
+}
+
+/*
+Return the sketch of the cumulative value, creating it with
+newSketch if there isn't one yet.
+*/
+func addSketch(cumulative value.Value, newSketch func() interface{}) (value.AnnotatedValue, interface{}) {
+	av, ok := cumulative.(value.AnnotatedValue)
+	if !ok {
+		av = value.NewAnnotatedValue(cumulative)
+	}
+
+	sketch := av.GetAttachment("sketch")
+	if sketch == nil {
+		sketch = newSketch()
+		av.SetAttachment("sketch", sketch)
+	}
+	return av, sketch
+}
+
+/*
+Retrieve the sketch of the cumulative value. Groups without any
+input still have the default value, which has no sketch.
+*/
+func getSketch(item value.Value) interface{} {
+	if av, ok := item.(value.AnnotatedValue); ok {
+		return av.GetAttachment("sketch")
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+This represents the Aggregate function STRING_AGG(expr [, separator])
+[WITHIN GROUP (ORDER BY ...)], also named LISTAGG. It returns the
+string values in the group concatenated, separated by separator,
+in the order given by the WITHIN GROUP clause.
+Type StringAgg is a struct that inherits from AggregateBase.
+*/
+type StringAgg struct {
+	AggregateBase
+}
+
+/*
+The function NewStringAgg calls NewAggregateBase to
+create an aggregate function named string_agg with
+one or two expressions as input.
+*/
+func NewStringAgg(operands expression.Expressions, flags uint32, filter expression.Expression, wTerm *WindowTerm) Aggregate {
+	rv := &StringAgg{
+		*NewAggregateBase("string_agg", operands, flags, filter, wTerm),
+	}
+
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+It calls the VisitFunction method by passing in the receiver to
+and returns the interface. It is a visitor pattern.
+*/
+func (this *StringAgg) Accept(visitor expression.Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+/*
+It returns a value of type STRING.
+*/
+func (this *StringAgg) Type() value.Type { return value.STRING }
+
+/*
+Calls the evaluate method for aggregate functions and passes in the
+receiver, current item and current context.
+*/
+func (this *StringAgg) Evaluate(item value.Value, context expression.Context) (result value.Value, e error) {
+	return this.evaluate(this, item, context)
+}
+
+/*
+The constructor returns a NewStringAgg with the input operands
+cast to a Function as the FunctionConstructor.
+*/
+func (this *StringAgg) Constructor() expression.FunctionConstructor {
+	return func(operands ...expression.Expression) expression.Function {
+		return NewStringAgg(operands, uint32(0), nil, nil)
+	}
+}
+
+/*
+Copy of the aggregate function
+*/
+
+func (this *StringAgg) Copy() expression.Expression {
+	rv := &StringAgg{
+		*NewAggregateBase(this.Name(), expression.CopyExpressions(this.Operands()),
+			this.Flags(), expression.Copy(this.Filter()), CopyWindowTerm(this.WindowTerm())),
+	}
+
+	rv.SetWithinGroup(CopyOrder(this.WithinGroup()))
+	rv.BaseCopy(this)
+	rv.SetExpr(rv)
+	return rv
+}
+
+/*
+Minimum input arguments required is 1.
+*/
+func (this *StringAgg) MinArgs() int { return 1 }
+
+/*
+Maximum number of input arguments allowed is 2.
+*/
+func (this *StringAgg) MaxArgs() int { return 2 }
+
+/*
+If no input to the STRING_AGG function, then the default value
+returned is a null.
+*/
+func (this *StringAgg) Default(item value.Value, context Context) (value.Value, error) {
+	return value.NULL_VALUE, nil
+}
+
+/*
+Aggregates input data by evaluating operands. Values other than
+strings are ignored. With a WITHIN GROUP clause, the value is kept
+together with the values of the sort terms.
+*/
+func (this *StringAgg) CumulateInitial(item, cumulative value.Value, context Context) (value.Value, error) {
+	// apply filter if any
+	if ok, e := this.evaluateFilter(item, context); e != nil || !ok {
+		return cumulative, e
+	}
+
+	val, e := this.Operands()[0].Evaluate(item, context)
+	if e != nil {
+		return nil, e
+	}
+
+	if val.Type() != value.STRING {
+		return cumulative, nil
+	}
+
+	order := this.WithinGroup()
+	if order == nil {
+		return listAdd(val, cumulative), nil
+	}
+
+	entry := make([]interface{}, 0, len(order.Terms())+1)
+	entry = append(entry, val)
+	for _, term := range order.Terms() {
+		key, e := term.Expression().Evaluate(item, context)
+		if e != nil {
+			return nil, e
+		}
+		entry = append(entry, key)
+	}
+	return listAdd(value.NewValue(entry), cumulative), nil
+}
+
+/*
+Aggregates intermediate results and return them.
+*/
+func (this *StringAgg) CumulateIntermediate(part, cumulative value.Value, context Context) (value.Value, error) {
+	return cumulateOrdered(part, cumulative)
+}
+
+/*
+Compute the Final. Return NULL if no values of type STRING exist,
+otherwise the sorted values joined by the separator.
+*/
+func (this *StringAgg) ComputeFinal(cumulative value.Value, context Context) (value.Value, error) {
+	if cumulative == value.NULL_VALUE {
+		return cumulative, nil
+	}
+
+	list, e := getList(cumulative)
+	if e != nil {
+		return nil, e
+	}
+
+	if list.Len() == 0 {
+		return value.NULL_VALUE, nil
+	}
+
+	separator := ""
+	if len(this.Operands()) > 1 {
+		sep, e := this.Operands()[1].Evaluate(value.NULL_VALUE, context)
+		if e != nil {
+			return nil, e
+		}
+
+		switch sep.Type() {
+		case value.STRING:
+			separator = sep.Actual().(string)
+		case value.NULL, value.MISSING:
+		default:
+			return nil, fmt.Errorf("%s separator must be a string: %v.", this.Name(), sep)
+		}
+	}
+
+	vals := list.Values()
+	if order := this.WithinGroup(); order != nil {
+		vals = sortedEntries(order, vals, context)
+	}
+
+	var buf bytes.Buffer
+	for i, val := range vals {
+		if i > 0 {
+			buf.WriteString(separator)
+		}
+		buf.WriteString(val.Actual().(string))
+	}
+	return value.NewValue(buf.String()), nil
+}
@@ -117,7 +117,7 @@ func (this *Sum) CumulateInitial(item, cumulative value.Value, context Context)
 	if this.Distinct() {
 		return setAdd(item, cumulative, true), nil
 	} else {
-		return this.cumulatePart(item, cumulative, context)
+		return this.cumulatePart(expression.ArithNumber(item, context), cumulative, context)
 	}
 }
 
@@ -156,7 +156,7 @@ func (this *Sum) CumulateRemove(item, cumulative value.Value, context Context) (
 	if item.Type() != value.NUMBER {
 		return cumulative, nil
 	} else if cumulative.Type() == value.NUMBER {
-		return value.AsNumberValue(cumulative).Sub(value.AsNumberValue(item)), nil
+		return value.AsNumberValue(cumulative).Sub(expression.ArithNumber(item, context)), nil
 	}
 
 	return nil, fmt.Errorf("Invalid %v.CumulateRemove() for %v value.", this.Name(), cumulative.Actual())
@@ -199,11 +199,11 @@ func (this *Sum) computeDistinctFinal(cumulative value.Value, context Context) (
 		return value.NULL_VALUE, nil
 	}
 
-	sum := value.ZERO_NUMBER
+	sum := expression.ArithNumber(value.ZERO_VALUE, context)
 	for _, v := range set.Values() {
 		switch {
 		case v.Type() == value.NUMBER:
-			sum = sum.Add(value.AsNumberValue(v))
+			sum = sum.Add(expression.ArithNumber(v, context))
 		default:
 			return nil, fmt.Errorf("Invalid partial SUM %v of type %T.", v.Actual(), v.Actual())
 		}
@@ -50,6 +50,16 @@ type Aggregate interface {
 	*/
 	SetAggregateModifiers(flags uint32, filter expression.Expression, wTerm *WindowTerm)
 
+	/*
+	   Set the ORDER BY of the WITHIN GROUP clause.
+	*/
+	SetWithinGroup(order *Order)
+
+	/*
+	   Return the ORDER BY of the WITHIN GROUP clause.
+	*/
+	WithinGroup() *Order
+
 	/*
 	   Return WindowTerm.
 	*/
@@ -119,15 +129,17 @@ It inherits from expressions FunctionBase, and has
      flags          which represents the modifers/flags
                          DISTINCT, INCREMENTAL, RESPECT|IGNORE NULLS, FROM FIRST|LAST
      filter         include those objects that filter condition is true in aggregation
+     withinGroup    order of the input values for ordered-set aggregates
      windowTerm     which represents the Window information
 */
 
 type AggregateBase struct {
 	expression.FunctionBase
-	text       string
-	flags      uint32
-	filter     expression.Expression
-	windowTerm *WindowTerm
+	text        string
+	flags       uint32
+	filter      expression.Expression
+	withinGroup *Order
+	windowTerm  *WindowTerm
 }
 
 /*
@@ -179,6 +191,13 @@ func (this *AggregateBase) SetAggregateModifiers(flags uint32, filter expression
 	}
 }
 
+/*
+Sets the ORDER BY of the WITHIN GROUP clause
+*/
+func (this *AggregateBase) SetWithinGroup(order *Order) {
+	this.withinGroup = order
+}
+
 /*
 Helper functions
 */
@@ -190,6 +209,7 @@ func (this *AggregateBase) Flags() uint32                 { return this.flags }
 func (this *AggregateBase) MinArgs() int                  { return 1 }
 func (this *AggregateBase) MaxArgs() int                  { return 1 }
 func (this *AggregateBase) Filter() expression.Expression { return this.filter }
+func (this *AggregateBase) WithinGroup() *Order           { return this.withinGroup }
 
 /*
 If Incremental aggregation is possible or not
@@ -245,6 +265,12 @@ func (this *AggregateBase) String() string {
 
 	buf.WriteString(")")
 
+	if this.withinGroup != nil {
+		buf.WriteString(" WITHIN GROUP (")
+		buf.WriteString(this.withinGroup.String()[1:])
+		buf.WriteString(")")
+	}
+
 	if this.Filter() != nil {
 		buf.WriteString(" FILTER (WHERE ")
 		buf.WriteString(stringer.Visit(this.Filter()))
@@ -347,8 +373,12 @@ func EqualAggregateModifiers(agg1, agg2 Aggregate) bool {
 	wTerm1 := agg1.WindowTerm()
 	wTerm2 := agg2.WindowTerm()
 
+	wGroup1 := agg1.WithinGroup()
+	wGroup2 := agg2.WithinGroup()
+
 	return agg1.Flags() == agg2.Flags() &&
 		expression.Equivalent(agg1.Filter(), agg2.Filter()) &&
+		((wGroup1 == wGroup2) || (wGroup1 != nil && wGroup2 != nil && wGroup1.String() == wGroup2.String())) &&
 		expression.Equivalents(agg1.Operands(), agg2.Operands()) &&
 		((wTerm1 == wTerm2) || (wTerm1 != nil && wTerm2 != nil && wTerm1.String() == wTerm2.String()))
 }
@@ -376,6 +406,10 @@ func (this *AggregateBase) Children() expression.Expressions {
 		rv = append(rv, this.Filter())
 	}
 
+	if this.withinGroup != nil {
+		rv = append(rv, this.withinGroup.Expressions()...)
+	}
+
 	wTerm := this.WindowTerm()
 	if wTerm != nil {
 		exprs := wTerm.Expressions()
@@ -415,6 +449,12 @@ func (this *AggregateBase) MapChildren(mapper expression.Mapper) error {
 		this.filter = expr
 	}
 
+	if this.withinGroup != nil {
+		if err := this.withinGroup.MapExpressions(mapper); err != nil {
+			return err
+		}
+	}
+
 	wTerm := this.WindowTerm()
 	if wTerm != nil {
 		return wTerm.MapExpressions(mapper)
@@ -27,12 +27,14 @@ and returning represents the returning clause.
 type Delete struct {
 	statementBase
 
-	keyspace  *KeyspaceRef          `json:"keyspace"`
-	keys      expression.Expression `json:"keys"`
-	indexes   IndexRefs             `json:"indexes"`
-	where     expression.Expression `json:"where"`
-	limit     expression.Expression `json:"limit"`
-	returning *Projection           `json:"returning"`
+	keyspace   *KeyspaceRef          `json:"keyspace"`
+	keys       expression.Expression `json:"keys"`
+	indexes    IndexRefs             `json:"indexes"`
+	where      expression.Expression `json:"where"`
+	limit      expression.Expression `json:"limit"`
+	returning  *Projection           `json:"returning"`
+	images     bool
+	optimHints *OptimHints `json:"optimizer_hints"`
 }
 
 /*
@@ -206,7 +208,7 @@ func (this *Delete) Formalize() (err error) {
 	}
 
 	if this.returning != nil {
-		_, err = this.returning.Formalize(f)
+		this.images, err = formalizeReturning(this.returning, f)
 	}
 
 	return
@@ -257,3 +259,22 @@ delete statement.
 func (this *Delete) Returning() *Projection {
 	return this.returning
 }
+
+/*
+Returns whether the RETURNING clause references the OLD or
+NEW image of the documents.
+*/
+func (this *Delete) ReturningImages() bool {
+	return this.images
+}
+
+/*
+Returns the optimizer hints of the statement.
+*/
+func (this *Delete) OptimHints() *OptimHints {
+	return this.optimHints
+}
+
+func (this *Delete) SetOptimHints(optimHints *OptimHints) {
+	this.optimHints = optimHints
+}
@@ -77,6 +77,9 @@ type SimpleFromTerm interface {
 	SetJoinHint(joinHint JoinHint)
 	PreferHash() bool
 	PreferNL() bool
+	PreferMerge() bool
+	AvoidHash() bool
+	AvoidNL() bool
 	UnsetJoinProps() uint32
 	SetJoinProps(joinProps uint32)
 }
@@ -240,6 +240,27 @@ func (this *ExpressionTerm) PreferNL() bool {
 	return this.joinHint == USE_NL
 }
 
+/*
+Join hint prefers merge join
+*/
+func (this *ExpressionTerm) PreferMerge() bool {
+	return this.joinHint == USE_MERGE
+}
+
+/*
+Join hint avoids hash join
+*/
+func (this *ExpressionTerm) AvoidHash() bool {
+	return this.joinHint == NO_USE_HASH
+}
+
+/*
+Join hint avoids nested loop join
+*/
+func (this *ExpressionTerm) AvoidNL() bool {
+	return this.joinHint == NO_USE_NL
+}
+
 /*
 Returns the property.
 */
@@ -27,6 +27,7 @@ const (
 	TERM_INDEX_JOIN_NEST             // right-hand side of index join/nest
 	TERM_IN_CORR_SUBQ                // inside a correlated subquery
 	TERM_COMMA_JOIN                  // right-hand side of comma-separated join
+	TERM_UNDER_MERGE                 // right-hand side of Merge Join
 )
 
 const TERM_JOIN_PROPS = (TERM_ANSI_JOIN | TERM_ANSI_NEST | TERM_PRIMARY_JOIN)
@@ -332,7 +333,7 @@ func (this *KeyspaceTerm) JoinKeys() expression.Expression {
 }
 
 /*
-Returns the join hint (USE HASH or USE NL).
+Returns the join hint (USE HASH, USE NL or USE MERGE, or NO_USE_HASH or NO_USE_NL).
 */
 func (this *KeyspaceTerm) JoinHint() JoinHint {
 	return this.joinHint
@@ -352,6 +353,27 @@ func (this *KeyspaceTerm) PreferNL() bool {
 	return this.joinHint == USE_NL
 }
 
+/*
+Join hint prefers merge join
+*/
+func (this *KeyspaceTerm) PreferMerge() bool {
+	return this.joinHint == USE_MERGE
+}
+
+/*
+Join hint avoids hash join
+*/
+func (this *KeyspaceTerm) AvoidHash() bool {
+	return this.joinHint == NO_USE_HASH
+}
+
+/*
+Join hint avoids nested loop join
+*/
+func (this *KeyspaceTerm) AvoidNL() bool {
+	return this.joinHint == NO_USE_NL
+}
+
 /*
 Returns the property.
 */
@@ -412,6 +434,13 @@ func (this *KeyspaceTerm) IsUnderHash() bool {
 	return (this.property & TERM_UNDER_HASH) != 0
 }
 
+/*
+Returns whether this keyspace is being considered for Merge Join
+*/
+func (this *KeyspaceTerm) IsUnderMerge() bool {
+	return (this.property & TERM_UNDER_MERGE) != 0
+}
+
 /*
 Returns whether it's right-hand side of index join/nest
 */
@@ -505,6 +534,20 @@ func (this *KeyspaceTerm) UnsetUnderHash() {
 	this.property &^= TERM_UNDER_HASH
 }
 
+/*
+Set UNDER MERGE property
+*/
+func (this *KeyspaceTerm) SetUnderMerge() {
+	this.property |= TERM_UNDER_MERGE
+}
+
+/*
+Unset UNDER MERGE property
+*/
+func (this *KeyspaceTerm) UnsetUnderMerge() {
+	this.property &^= TERM_UNDER_MERGE
+}
+
 /*
 Set INDEX JOIN/NEST property
 */
@@ -139,6 +139,27 @@ func (this *SubqueryTerm) PreferNL() bool {
 	return this.joinHint == USE_NL
 }
 
+/*
+Join hint prefers merge join
+*/
+func (this *SubqueryTerm) PreferMerge() bool {
+	return this.joinHint == USE_MERGE
+}
+
+/*
+Join hint avoids hash join
+*/
+func (this *SubqueryTerm) AvoidHash() bool {
+	return this.joinHint == NO_USE_HASH
+}
+
+/*
+Join hint avoids nested loop join
+*/
+func (this *SubqueryTerm) AvoidNL() bool {
+	return this.joinHint == NO_USE_NL
+}
+
 /*
 Returns the property.
 */
@@ -0,0 +1,114 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Create group statement. The options are "description"
+and "roles", the roles the members of the group have, each as
+role or role[target].
+*/
+type CreateGroup struct {
+	statementBase
+
+	group string      `json:"group"`
+	with  value.Value `json:"with"`
+}
+
+/*
+The function NewCreateGroup returns a pointer to the
+CreateGroup struct with the input argument values as fields.
+*/
+func NewCreateGroup(group string, with value.Value) *CreateGroup {
+	rv := &CreateGroup{
+		group: group,
+		with:  with,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitCreateGroup method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *CreateGroup) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateGroup(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateGroup) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateGroup) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *CreateGroup) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateGroup) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *CreateGroup) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add("", auth.PRIV_SECURITY_WRITE, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+func (this *CreateGroup) Group() string {
+	return this.group
+}
+
+func (this *CreateGroup) With() value.Value {
+	return this.with
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *CreateGroup) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "createGroup"}
+	r["group"] = this.group
+	if this.with != nil {
+		r["with"] = this.with
+	}
+	return json.Marshal(r)
+}
+
+func (this *CreateGroup) Type() string {
+	return "CREATE_GROUP"
+}
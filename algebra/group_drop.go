@@ -0,0 +1,103 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Drop group statement.
+*/
+type DropGroup struct {
+	statementBase
+
+	group string `json:"group"`
+}
+
+/*
+The function NewDropGroup returns a pointer to the
+DropGroup struct with the input argument values as fields.
+*/
+func NewDropGroup(group string) *DropGroup {
+	rv := &DropGroup{
+		group: group,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitDropGroup method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *DropGroup) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropGroup(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *DropGroup) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropGroup) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *DropGroup) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropGroup) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *DropGroup) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add("", auth.PRIV_SECURITY_WRITE, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+func (this *DropGroup) Group() string {
+	return this.group
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *DropGroup) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "dropGroup"}
+	r["group"] = this.group
+	return json.Marshal(r)
+}
+
+func (this *DropGroup) Type() string {
+	return "DROP_GROUP"
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/couchbase/query/datastore"
@@ -28,6 +29,14 @@ const (
 	HINT_NL
 	HINT_HASH
 	HINT_ORDERED
+	HINT_MERGE
+	HINT_LEADING
+	HINT_NO_INDEX
+	HINT_NO_HASH
+	HINT_NO_NL
+	HINT_PARALLEL
+	HINT_INDEX_COMBINE
+	HINT_NO_INDEX_COMBINE
 )
 
 type HintState int32
@@ -41,24 +50,40 @@ const (
 )
 
 const (
-	INVALID_HINT                = "Invalid hint name"
-	MISSING_ARG                 = "Missing argument for "
-	EXTRA_ARG                   = "Argument not expected: "
-	INVALID_SLASH               = "Invalid '/' found in "
-	EXTRA_SLASH                 = "Extra '/' found in "
-	INVALID_HASH_OPTION         = "Invalid hash option (BUILD or PROBE only):  "
-	INVALID_KEYSPACE            = "Invalid keyspace specified: "
-	DUPLICATED_JOIN_HINT        = "Duplciated join hint specified for keyspace: "
-	DUPLICATED_INDEX_HINT       = "Duplicated INDEX hint specified for keyspace: "
-	DUPLICATED_INDEX_FTS_HINT   = "Duplicated INDEX_FTS hint specified for keyspace: "
-	NON_KEYSPACE_INDEX_HINT     = "INDEX hint specified on non-keyspace: "
-	NON_KEYSPACE_INDEX_FTS_HINT = "INDEX_FTS hint specified on non-keyspace: "
-	HASH_JOIN_NOT_AVAILABLE     = "Hash Join/Nest is not supported"
-	INDEX_HINT_NOT_FOLLOWED     = "INDEX hint cannot be followed"
-	INDEX_FTS_HINT_NOT_FOLLOWED = "INDEX_FTS hint cannot be followed"
-	USE_NL_HINT_NOT_FOLLOWED    = "USE_NL hint cannot be followed"
-	USE_HASH_HINT_NOT_FOLLOWED  = "USE_HASH hint cannot be followed"
-	ORDERED_HINT_NOT_FOLLOWED   = "ORDERED hint cannot be followed"
+	INVALID_HINT                       = "Invalid hint name"
+	MISSING_ARG                        = "Missing argument for "
+	EXTRA_ARG                          = "Argument not expected: "
+	INVALID_SLASH                      = "Invalid '/' found in "
+	EXTRA_SLASH                        = "Extra '/' found in "
+	INVALID_HASH_OPTION                = "Invalid hash option (BUILD or PROBE only):  "
+	INVALID_PARALLELISM                = "Invalid parallelism (positive integer only): "
+	INVALID_KEYSPACE                   = "Invalid keyspace specified: "
+	DUPLICATED_JOIN_HINT               = "Duplciated join hint specified for keyspace: "
+	DUPLICATED_INDEX_HINT              = "Duplicated INDEX hint specified for keyspace: "
+	DUPLICATED_INDEX_FTS_HINT          = "Duplicated INDEX_FTS hint specified for keyspace: "
+	DUPLICATED_COMBINE_HINT            = "Duplicated INDEX_COMBINE/NO_INDEX_COMBINE hint specified for keyspace: "
+	DUPLICATED_LEADING_HINT            = "Duplicated LEADING hint specified"
+	DUPLICATED_LEADING_KEYSPACE        = "Duplicated keyspace specified in LEADING hint: "
+	DUPLICATED_PARALLEL_HINT           = "Duplicated PARALLEL hint specified"
+	NON_KEYSPACE_INDEX_HINT            = "INDEX hint specified on non-keyspace: "
+	NON_KEYSPACE_INDEX_FTS_HINT        = "INDEX_FTS hint specified on non-keyspace: "
+	NON_KEYSPACE_NO_INDEX_HINT         = "NO_INDEX hint specified on non-keyspace: "
+	NON_KEYSPACE_COMBINE_HINT          = "INDEX_COMBINE/NO_INDEX_COMBINE hint specified on non-keyspace: "
+	NO_INDEX_HINT_CONFLICT             = "NO_INDEX hint excludes an index of the INDEX hint for keyspace: "
+	HASH_JOIN_NOT_AVAILABLE            = "Hash Join/Nest is not supported"
+	INDEX_HINT_NOT_FOLLOWED            = "INDEX hint cannot be followed"
+	INDEX_FTS_HINT_NOT_FOLLOWED        = "INDEX_FTS hint cannot be followed"
+	NO_INDEX_HINT_NOT_FOLLOWED         = "NO_INDEX hint cannot be followed"
+	USE_NL_HINT_NOT_FOLLOWED           = "USE_NL hint cannot be followed"
+	USE_HASH_HINT_NOT_FOLLOWED         = "USE_HASH hint cannot be followed"
+	USE_MERGE_HINT_NOT_FOLLOWED        = "USE_MERGE hint cannot be followed"
+	NO_USE_NL_HINT_NOT_FOLLOWED        = "NO_USE_NL hint cannot be followed"
+	NO_USE_HASH_HINT_NOT_FOLLOWED      = "NO_USE_HASH hint cannot be followed"
+	ORDERED_HINT_NOT_FOLLOWED          = "ORDERED hint cannot be followed"
+	LEADING_HINT_NOT_FOLLOWED          = "LEADING hint cannot be followed"
+	PARALLEL_HINT_NOT_FOLLOWED         = "PARALLEL hint cannot be followed"
+	INDEX_COMBINE_HINT_NOT_FOLLOWED    = "INDEX_COMBINE hint cannot be followed"
+	NO_INDEX_COMBINE_HINT_NOT_FOLLOWED = "NO_INDEX_COMBINE hint cannot be followed"
 )
 
 type OptimHint interface {
@@ -147,9 +172,33 @@ func addJSONHint(r map[string]interface{}, hint OptimHint) {
 	case *HintHash:
 		name = "use_hash"
 		obj = hint.formatJSON()
+	case *HintMerge:
+		name = "use_merge"
+		obj = hint.formatJSON()
 	case *HintOrdered:
 		name = "ordered"
 		obj = hint.formatJSON()
+	case *HintLeading:
+		name = "leading"
+		obj = hint.formatJSON()
+	case *HintNoIndex:
+		name = "no_index"
+		obj = hint.formatJSON()
+	case *HintNoNL:
+		name = "no_use_nl"
+		obj = hint.formatJSON()
+	case *HintNoHash:
+		name = "no_use_hash"
+		obj = hint.formatJSON()
+	case *HintParallel:
+		name = "parallel"
+		obj = hint.formatJSON()
+	case *HintIndexCombine:
+		name = "index_combine"
+		obj = hint.formatJSON()
+	case *HintNoIndexCombine:
+		name = "no_index_combine"
+		obj = hint.formatJSON()
 	case *HintInvalid:
 		name = "invalid_hints"
 		obj = hint.formatJSON()
@@ -265,6 +314,23 @@ func NewOptimHint(hint_name string, hint_args []string) []OptimHint {
 			hint := NewHashHint(keyspace, option)
 			hints = append(hints, hint)
 		}
+	case "use_merge":
+		// USE_MERGE hint must include at least 1 keyspace
+		if len(hint_args) == 0 {
+			invalid = true
+			err = MISSING_ARG + hint_name
+			break
+		}
+		hints = make([]OptimHint, 0, len(hint_args))
+		for _, arg := range hint_args {
+			if strings.Contains(arg, "/") {
+				invalid = true
+				err = INVALID_SLASH + arg
+				break
+			}
+			hint := NewMergeHint(arg)
+			hints = append(hints, hint)
+		}
 	case "ordered":
 		if hint_args != nil {
 			invalid = true
@@ -272,6 +338,86 @@ func NewOptimHint(hint_name string, hint_args []string) []OptimHint {
 			break
 		}
 		hints = []OptimHint{NewOrderedHint()}
+	case "leading":
+		// LEADING hint must include at least 1 keyspace
+		if len(hint_args) == 0 {
+			invalid = true
+			err = MISSING_ARG + hint_name
+			break
+		}
+		for _, arg := range hint_args {
+			if strings.Contains(arg, "/") {
+				invalid = true
+				err = INVALID_SLASH + arg
+				break
+			}
+		}
+		if !invalid {
+			hints = []OptimHint{NewLeadingHint(hint_args)}
+		}
+	case "no_index":
+		if len(hint_args) == 0 {
+			invalid = true
+			err = MISSING_ARG + hint_name
+			break
+		}
+		// first arg is keyspace (alias), no index excludes all secondary indexes
+		indexes := make(IndexRefs, 0, len(hint_args)-1)
+		for i := 1; i < len(hint_args); i++ {
+			if strings.Contains(hint_args[i], "/") {
+				invalid = true
+				err = INVALID_SLASH + hint_args[i]
+				break
+			}
+			indexes = append(indexes, NewIndexRef(hint_args[i], datastore.DEFAULT))
+		}
+		if !invalid {
+			hints = []OptimHint{NewNoIndexHint(hint_args[0], indexes)}
+		}
+	case "no_use_nl", "no_use_hash", "index_combine", "no_index_combine":
+		// must include at least 1 keyspace
+		if len(hint_args) == 0 {
+			invalid = true
+			err = MISSING_ARG + hint_name
+			break
+		}
+		hints = make([]OptimHint, 0, len(hint_args))
+		for _, arg := range hint_args {
+			if strings.Contains(arg, "/") {
+				invalid = true
+				err = INVALID_SLASH + arg
+				break
+			}
+			var hint OptimHint
+			switch lowerName {
+			case "no_use_nl":
+				hint = NewNoNLHint(arg)
+			case "no_use_hash":
+				hint = NewNoHashHint(arg)
+			case "index_combine":
+				hint = NewIndexCombineHint(arg)
+			case "no_index_combine":
+				hint = NewNoIndexCombineHint(arg)
+			}
+			hints = append(hints, hint)
+		}
+	case "parallel":
+		if len(hint_args) == 0 {
+			invalid = true
+			err = MISSING_ARG + hint_name
+			break
+		} else if len(hint_args) > 1 {
+			invalid = true
+			err = EXTRA_ARG + strings.Join(hint_args[1:], " ")
+			break
+		}
+		parallelism, e := strconv.Atoi(hint_args[0])
+		if e != nil || parallelism <= 0 {
+			invalid = true
+			err = INVALID_PARALLELISM + hint_args[0]
+			break
+		}
+		hints = []OptimHint{NewParallelHint(parallelism)}
 	default:
 		invalid = true
 		err = INVALID_HINT
@@ -717,6 +863,94 @@ func (this *HintHash) formatJSON() map[string]interface{} {
 	return r
 }
 
+type HintMerge struct {
+	keyspace string
+	derived  bool
+	state    HintState
+	err      string
+}
+
+func NewMergeHint(keyspace string) *HintMerge {
+	return &HintMerge{
+		keyspace: keyspace,
+	}
+}
+
+func NewDerivedMergeHint(keyspace string) *HintMerge {
+	return &HintMerge{
+		keyspace: keyspace,
+		derived:  true,
+	}
+}
+
+func (this *HintMerge) Type() HintType {
+	return HINT_MERGE
+}
+
+func (this *HintMerge) Copy() OptimHint {
+	return &HintMerge{
+		keyspace: this.keyspace,
+		derived:  this.derived,
+		state:    this.state,
+		err:      this.err,
+	}
+}
+
+func (this *HintMerge) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *HintMerge) Derived() bool {
+	return this.derived
+}
+
+func (this *HintMerge) State() HintState {
+	return this.state
+}
+
+func (this *HintMerge) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintMerge) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = USE_MERGE_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintMerge) Error() string {
+	return this.err
+}
+
+func (this *HintMerge) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintMerge) sortString() string {
+	return fmt.Sprintf("%d%d%t%s%s", this.Type(), this.state, this.derived, this.keyspace, this.err)
+}
+
+func (this *HintMerge) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"use_merge": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	return formatHint("USE_MERGE", []string{this.keyspace})
+}
+
+func (this *HintMerge) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["keyspace"] = this.keyspace
+	return r
+}
+
 type HintOrdered struct {
 	state HintState
 	err   string
@@ -785,129 +1019,719 @@ func (this *HintOrdered) formatJSON() map[string]interface{} {
 	return r
 }
 
-type HintInvalid struct {
-	input    string
-	inputObj map[string]interface{}
-	err      string
-}
-
-func NewInvalidHint(input string) *HintInvalid {
-	return &HintInvalid{
-		input: input,
-	}
+type HintLeading struct {
+	keyspaces []string
+	state     HintState
+	err       string
 }
 
-func NewInvalidJSONHint(r map[string]interface{}) *HintInvalid {
-	return &HintInvalid{
-		inputObj: r,
+func NewLeadingHint(keyspaces []string) *HintLeading {
+	return &HintLeading{
+		keyspaces: keyspaces,
 	}
 }
 
-func (this *HintInvalid) Type() HintType {
-	return HINT_INVALID
+func (this *HintLeading) Type() HintType {
+	return HINT_LEADING
 }
 
-func (this *HintInvalid) Copy() OptimHint {
-	rv := &HintInvalid{
-		input: this.input,
+func (this *HintLeading) Copy() OptimHint {
+	rv := &HintLeading{
+		state: this.state,
 		err:   this.err,
 	}
-	if len(this.inputObj) > 0 {
-		inputObj := make(map[string]interface{}, len(this.inputObj))
-		for k, v := range this.inputObj {
-			inputObj[k] = v
-		}
-		rv.inputObj = inputObj
+	if len(this.keyspaces) > 0 {
+		rv.keyspaces = make([]string, len(this.keyspaces))
+		copy(rv.keyspaces, this.keyspaces)
 	}
 	return rv
 }
 
-func (this *HintInvalid) Input() string {
-	return this.input
-}
-
-func (this *HintInvalid) InputObj() map[string]interface{} {
-	return this.inputObj
+// the keyspaces (aliases) to be joined first, in order
+func (this *HintLeading) Keyspaces() []string {
+	return this.keyspaces
 }
 
-func (this *HintInvalid) Derived() bool {
+func (this *HintLeading) Derived() bool {
 	return false
 }
 
-// invalid hint only has HINT_STATE_INVALID
-func (this *HintInvalid) State() HintState {
-	return HINT_STATE_INVALID
+func (this *HintLeading) State() HintState {
+	return this.state
 }
 
-func (this *HintInvalid) SetFollowed() {
-	// no-op
+func (this *HintLeading) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
 }
 
-func (this *HintInvalid) SetNotFollowed() {
-	// no-op
+func (this *HintLeading) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = LEADING_HINT_NOT_FOLLOWED
+	}
 }
 
-func (this *HintInvalid) Error() string {
+func (this *HintLeading) Error() string {
 	return this.err
 }
 
-func (this *HintInvalid) SetError(err string) {
+func (this *HintLeading) SetError(err string) {
 	this.err = err
+	this.state = HINT_STATE_ERROR
 }
 
-func (this *HintInvalid) sortString() string {
-	return fmt.Sprintf("%d%d%s%s", this.Type(), this.State(), this.input, this.err)
+func (this *HintLeading) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.state, strings.Join(this.keyspaces, " "), this.err)
 }
 
-func (this *HintInvalid) FormatHint(jsonStyle bool) string {
-	if jsonStyle && len(this.inputObj) != 0 {
-		bytes, _ := json.Marshal(this.inputObj)
+func (this *HintLeading) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"leading": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
 		return string(bytes)
 	}
-	return this.input
+	return formatHint("LEADING", this.keyspaces)
 }
 
-func (this *HintInvalid) formatJSON() map[string]interface{} {
-	return this.inputObj
+func (this *HintLeading) formatJSON() map[string]interface{} {
+	keyspaces := make([]interface{}, 0, len(this.keyspaces))
+	for _, ks := range this.keyspaces {
+		keyspaces = append(keyspaces, ks)
+	}
+	r := make(map[string]interface{}, 1)
+	r["keyspaces"] = keyspaces
+	return r
 }
 
-func formatHint(hint_name string, hint_args []string) string {
-	s := hint_name
-	if hint_args != nil {
-		s += "(" + strings.Join(hint_args, " ") + ")"
+type HintNoIndex struct {
+	keyspace string
+	indexes  IndexRefs
+	state    HintState
+	err      string
+}
+
+func NewNoIndexHint(keyspace string, indexes IndexRefs) *HintNoIndex {
+	return &HintNoIndex{
+		keyspace: keyspace,
+		indexes:  indexes,
 	}
-	return s
 }
 
-func invalidHint(hint_name string, hint_args []string, err string) []OptimHint {
-	return genInvalidHint(formatHint(hint_name, hint_args), err)
+func (this *HintNoIndex) Type() HintType {
+	return HINT_NO_INDEX
 }
 
-func genInvalidHint(input, err string) []OptimHint {
-	hint := NewInvalidHint(input)
-	hint.SetError(err)
-	return []OptimHint{hint}
+func (this *HintNoIndex) Copy() OptimHint {
+	rv := &HintNoIndex{
+		keyspace: this.keyspace,
+		state:    this.state,
+		err:      this.err,
+	}
+	if len(this.indexes) > 0 {
+		rv.indexes = make(IndexRefs, 0, len(this.indexes))
+		for _, idx := range this.indexes {
+			rv.indexes = append(rv.indexes, idx)
+		}
+	}
+	return rv
 }
 
-func genInvalidJSONHint(r map[string]interface{}, err string) []OptimHint {
-	hint := NewInvalidJSONHint(r)
-	hint.SetError(err)
-	return []OptimHint{hint}
+func (this *HintNoIndex) Keyspace() string {
+	return this.keyspace
 }
 
-func InvalidOptimHints(input, err string) *OptimHints {
-	return &OptimHints{
-		hints:     genInvalidHint(input, err),
-		jsonStyle: false,
-	}
+// the excluded indexes, none excludes all secondary indexes
+func (this *HintNoIndex) Indexes() IndexRefs {
+	return this.indexes
 }
 
-// JSON style hints
+func (this *HintNoIndex) Derived() bool {
+	return false
+}
 
-func ParseObjectHints(object expression.Expression) []OptimHint {
-	if object == nil {
-		return nil
-	}
+func (this *HintNoIndex) State() HintState {
+	return this.state
+}
+
+func (this *HintNoIndex) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintNoIndex) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = NO_INDEX_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintNoIndex) Error() string {
+	return this.err
+}
+
+func (this *HintNoIndex) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintNoIndex) sortString() string {
+	return fmt.Sprintf("%d%d%s%d%s", this.Type(), this.state, this.keyspace, len(this.indexes), this.err)
+}
+
+func (this *HintNoIndex) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"no_index": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	args := make([]string, 0, len(this.indexes)+1)
+	args = append(args, this.keyspace)
+	for _, idx := range this.indexes {
+		args = append(args, idx.Name())
+	}
+	return formatHint("NO_INDEX", args)
+}
+
+func (this *HintNoIndex) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 2)
+	r["keyspace"] = this.keyspace
+	if len(this.indexes) > 0 {
+		indexes := make([]interface{}, 0, len(this.indexes))
+		for _, idx := range this.indexes {
+			indexes = append(indexes, idx.Name())
+		}
+		r["indexes"] = indexes
+	}
+	return r
+}
+
+// NO_USE_NL: join the keyspace with any join method but nested-loop
+type HintNoNL struct {
+	keyspace string
+	state    HintState
+	err      string
+}
+
+func NewNoNLHint(keyspace string) *HintNoNL {
+	return &HintNoNL{
+		keyspace: keyspace,
+	}
+}
+
+func (this *HintNoNL) Type() HintType {
+	return HINT_NO_NL
+}
+
+func (this *HintNoNL) Copy() OptimHint {
+	return &HintNoNL{
+		keyspace: this.keyspace,
+		state:    this.state,
+		err:      this.err,
+	}
+}
+
+func (this *HintNoNL) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *HintNoNL) Derived() bool {
+	return false
+}
+
+func (this *HintNoNL) State() HintState {
+	return this.state
+}
+
+func (this *HintNoNL) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintNoNL) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = NO_USE_NL_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintNoNL) Error() string {
+	return this.err
+}
+
+func (this *HintNoNL) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintNoNL) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.state, this.keyspace, this.err)
+}
+
+func (this *HintNoNL) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"no_use_nl": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	return formatHint("NO_USE_NL", []string{this.keyspace})
+}
+
+func (this *HintNoNL) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["keyspace"] = this.keyspace
+	return r
+}
+
+// NO_USE_HASH: join the keyspace with any join method but hash join
+type HintNoHash struct {
+	keyspace string
+	state    HintState
+	err      string
+}
+
+func NewNoHashHint(keyspace string) *HintNoHash {
+	return &HintNoHash{
+		keyspace: keyspace,
+	}
+}
+
+func (this *HintNoHash) Type() HintType {
+	return HINT_NO_HASH
+}
+
+func (this *HintNoHash) Copy() OptimHint {
+	return &HintNoHash{
+		keyspace: this.keyspace,
+		state:    this.state,
+		err:      this.err,
+	}
+}
+
+func (this *HintNoHash) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *HintNoHash) Derived() bool {
+	return false
+}
+
+func (this *HintNoHash) State() HintState {
+	return this.state
+}
+
+func (this *HintNoHash) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintNoHash) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = NO_USE_HASH_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintNoHash) Error() string {
+	return this.err
+}
+
+func (this *HintNoHash) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintNoHash) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.state, this.keyspace, this.err)
+}
+
+func (this *HintNoHash) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"no_use_hash": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	return formatHint("NO_USE_HASH", []string{this.keyspace})
+}
+
+func (this *HintNoHash) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["keyspace"] = this.keyspace
+	return r
+}
+
+// PARALLEL(n): run the query block with at most n copies of each parallel operator
+type HintParallel struct {
+	parallelism int
+	state       HintState
+	err         string
+}
+
+func NewParallelHint(parallelism int) *HintParallel {
+	return &HintParallel{
+		parallelism: parallelism,
+	}
+}
+
+func (this *HintParallel) Type() HintType {
+	return HINT_PARALLEL
+}
+
+func (this *HintParallel) Copy() OptimHint {
+	return &HintParallel{
+		parallelism: this.parallelism,
+		state:       this.state,
+		err:         this.err,
+	}
+}
+
+func (this *HintParallel) Parallelism() int {
+	return this.parallelism
+}
+
+func (this *HintParallel) Derived() bool {
+	return false
+}
+
+func (this *HintParallel) State() HintState {
+	return this.state
+}
+
+func (this *HintParallel) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintParallel) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = PARALLEL_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintParallel) Error() string {
+	return this.err
+}
+
+func (this *HintParallel) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintParallel) sortString() string {
+	return fmt.Sprintf("%d%d%d%s", this.Type(), this.state, this.parallelism, this.err)
+}
+
+func (this *HintParallel) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		bytes, _ := json.Marshal(this.formatJSON())
+		return string(bytes)
+	}
+	return formatHint("PARALLEL", []string{strconv.Itoa(this.parallelism)})
+}
+
+func (this *HintParallel) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["parallel"] = this.parallelism
+	return r
+}
+
+// INDEX_COMBINE: scan the keyspace with an IntersectScan or UnionScan of several indexes
+type HintIndexCombine struct {
+	keyspace string
+	state    HintState
+	err      string
+}
+
+func NewIndexCombineHint(keyspace string) *HintIndexCombine {
+	return &HintIndexCombine{
+		keyspace: keyspace,
+	}
+}
+
+func (this *HintIndexCombine) Type() HintType {
+	return HINT_INDEX_COMBINE
+}
+
+func (this *HintIndexCombine) Copy() OptimHint {
+	return &HintIndexCombine{
+		keyspace: this.keyspace,
+		state:    this.state,
+		err:      this.err,
+	}
+}
+
+func (this *HintIndexCombine) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *HintIndexCombine) Derived() bool {
+	return false
+}
+
+func (this *HintIndexCombine) State() HintState {
+	return this.state
+}
+
+func (this *HintIndexCombine) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintIndexCombine) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = INDEX_COMBINE_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintIndexCombine) Error() string {
+	return this.err
+}
+
+func (this *HintIndexCombine) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintIndexCombine) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.state, this.keyspace, this.err)
+}
+
+func (this *HintIndexCombine) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"index_combine": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	return formatHint("INDEX_COMBINE", []string{this.keyspace})
+}
+
+func (this *HintIndexCombine) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["keyspace"] = this.keyspace
+	return r
+}
+
+// NO_INDEX_COMBINE: scan the keyspace with a single index
+type HintNoIndexCombine struct {
+	keyspace string
+	state    HintState
+	err      string
+}
+
+func NewNoIndexCombineHint(keyspace string) *HintNoIndexCombine {
+	return &HintNoIndexCombine{
+		keyspace: keyspace,
+	}
+}
+
+func (this *HintNoIndexCombine) Type() HintType {
+	return HINT_NO_INDEX_COMBINE
+}
+
+func (this *HintNoIndexCombine) Copy() OptimHint {
+	return &HintNoIndexCombine{
+		keyspace: this.keyspace,
+		state:    this.state,
+		err:      this.err,
+	}
+}
+
+func (this *HintNoIndexCombine) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *HintNoIndexCombine) Derived() bool {
+	return false
+}
+
+func (this *HintNoIndexCombine) State() HintState {
+	return this.state
+}
+
+func (this *HintNoIndexCombine) SetFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_FOLLOWED
+	}
+}
+
+func (this *HintNoIndexCombine) SetNotFollowed() {
+	if this.state == HINT_STATE_UNKNOWN {
+		this.state = HINT_STATE_NOT_FOLLOWED
+		this.err = NO_INDEX_COMBINE_HINT_NOT_FOLLOWED
+	}
+}
+
+func (this *HintNoIndexCombine) Error() string {
+	return this.err
+}
+
+func (this *HintNoIndexCombine) SetError(err string) {
+	this.err = err
+	this.state = HINT_STATE_ERROR
+}
+
+func (this *HintNoIndexCombine) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.state, this.keyspace, this.err)
+}
+
+func (this *HintNoIndexCombine) FormatHint(jsonStyle bool) string {
+	if jsonStyle {
+		hint := map[string]interface{}{
+			"no_index_combine": this.formatJSON(),
+		}
+		bytes, _ := json.Marshal(hint)
+		return string(bytes)
+	}
+	return formatHint("NO_INDEX_COMBINE", []string{this.keyspace})
+}
+
+func (this *HintNoIndexCombine) formatJSON() map[string]interface{} {
+	r := make(map[string]interface{}, 1)
+	r["keyspace"] = this.keyspace
+	return r
+}
+
+type HintInvalid struct {
+	input    string
+	inputObj map[string]interface{}
+	err      string
+}
+
+func NewInvalidHint(input string) *HintInvalid {
+	return &HintInvalid{
+		input: input,
+	}
+}
+
+func NewInvalidJSONHint(r map[string]interface{}) *HintInvalid {
+	return &HintInvalid{
+		inputObj: r,
+	}
+}
+
+func (this *HintInvalid) Type() HintType {
+	return HINT_INVALID
+}
+
+func (this *HintInvalid) Copy() OptimHint {
+	rv := &HintInvalid{
+		input: this.input,
+		err:   this.err,
+	}
+	if len(this.inputObj) > 0 {
+		inputObj := make(map[string]interface{}, len(this.inputObj))
+		for k, v := range this.inputObj {
+			inputObj[k] = v
+		}
+		rv.inputObj = inputObj
+	}
+	return rv
+}
+
+func (this *HintInvalid) Input() string {
+	return this.input
+}
+
+func (this *HintInvalid) InputObj() map[string]interface{} {
+	return this.inputObj
+}
+
+func (this *HintInvalid) Derived() bool {
+	return false
+}
+
+// invalid hint only has HINT_STATE_INVALID
+func (this *HintInvalid) State() HintState {
+	return HINT_STATE_INVALID
+}
+
+func (this *HintInvalid) SetFollowed() {
+	// no-op
+}
+
+func (this *HintInvalid) SetNotFollowed() {
+	// no-op
+}
+
+func (this *HintInvalid) Error() string {
+	return this.err
+}
+
+func (this *HintInvalid) SetError(err string) {
+	this.err = err
+}
+
+func (this *HintInvalid) sortString() string {
+	return fmt.Sprintf("%d%d%s%s", this.Type(), this.State(), this.input, this.err)
+}
+
+func (this *HintInvalid) FormatHint(jsonStyle bool) string {
+	if jsonStyle && len(this.inputObj) != 0 {
+		bytes, _ := json.Marshal(this.inputObj)
+		return string(bytes)
+	}
+	return this.input
+}
+
+func (this *HintInvalid) formatJSON() map[string]interface{} {
+	return this.inputObj
+}
+
+func formatHint(hint_name string, hint_args []string) string {
+	s := hint_name
+	if hint_args != nil {
+		s += "(" + strings.Join(hint_args, " ") + ")"
+	}
+	return s
+}
+
+func invalidHint(hint_name string, hint_args []string, err string) []OptimHint {
+	return genInvalidHint(formatHint(hint_name, hint_args), err)
+}
+
+func genInvalidHint(input, err string) []OptimHint {
+	hint := NewInvalidHint(input)
+	hint.SetError(err)
+	return []OptimHint{hint}
+}
+
+func genInvalidJSONHint(r map[string]interface{}, err string) []OptimHint {
+	hint := NewInvalidJSONHint(r)
+	hint.SetError(err)
+	return []OptimHint{hint}
+}
+
+func InvalidOptimHints(input, err string) *OptimHints {
+	return &OptimHints{
+		hints:     genInvalidHint(input, err),
+		jsonStyle: false,
+	}
+}
+
+// JSON style hints
+
+func ParseObjectHints(object expression.Expression) []OptimHint {
+	if object == nil {
+		return nil
+	}
 
 	val := object.Value()
 	if val == nil || val.Type() != value.OBJECT {
@@ -931,8 +1755,24 @@ func ParseObjectHints(object expression.Expression) []OptimHint {
 			hints, invalid = newNLHints(vval)
 		case "use_hash":
 			hints, invalid = newHashHints(vval)
+		case "use_merge":
+			hints, invalid = newMergeHints(vval)
 		case "ordered":
 			hints, invalid = newOrderedHint(vval)
+		case "leading":
+			hints, invalid = newLeadingHints(vval)
+		case "no_index":
+			hints, invalid = newNoIndexHints(vval)
+		case "no_use_nl":
+			hints, invalid = newNoNLHints(vval)
+		case "no_use_hash":
+			hints, invalid = newNoHashHints(vval)
+		case "parallel":
+			hints, invalid = newParallelHint(vval)
+		case "index_combine":
+			hints, invalid = newIndexCombineHints(vval)
+		case "no_index_combine":
+			hints, invalid = newNoIndexCombineHints(vval)
 		default:
 			invalid = true
 		}
@@ -1120,6 +1960,136 @@ func procHashHints(fields map[string]interface{}) (OptimHint, bool) {
 	return NewHashHint(keyspace, option), false
 }
 
+func newMergeHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procMergeHints)
+}
+
+func procMergeHints(fields map[string]interface{}) (OptimHint, bool) {
+	invalid := false
+	var keyspace string
+	for k, v := range fields {
+		key := strings.ToLower(k)
+		if key == "keyspace" || key == "alias" {
+			keyspace = value.NewValue(v).ToString()
+			if keyspace == "" {
+				invalid = true
+			}
+		} else {
+			invalid = true
+			break
+		}
+	}
+	if invalid || keyspace == "" {
+		return nil, true
+	}
+
+	return NewMergeHint(keyspace), false
+}
+
+func newLeadingHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procLeadingHints)
+}
+
+func procLeadingHints(fields map[string]interface{}) (OptimHint, bool) {
+	var keyspaces []string
+	for k, v := range fields {
+		key := strings.ToLower(k)
+		if key != "keyspaces" && key != "aliases" {
+			return nil, true
+		}
+		kss, ok := value.NewValue(v).Actual().([]interface{})
+		if !ok {
+			return nil, true
+		}
+		for _, ks := range kss {
+			keyspace := value.NewValue(ks).ToString()
+			if keyspace == "" {
+				return nil, true
+			}
+			keyspaces = append(keyspaces, keyspace)
+		}
+	}
+	if len(keyspaces) == 0 {
+		return nil, true
+	}
+
+	return NewLeadingHint(keyspaces), false
+}
+
+func newNoIndexHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procNoIndexHints)
+}
+
+func procNoIndexHints(fields map[string]interface{}) (OptimHint, bool) {
+	hint, invalid := procIndexHints(fields)
+	if invalid {
+		return nil, true
+	}
+	index := hint.(*HintIndex)
+	return NewNoIndexHint(index.Keyspace(), index.Indexes()), false
+}
+
+func newNoNLHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procNoNLHints)
+}
+
+func procNoNLHints(fields map[string]interface{}) (OptimHint, bool) {
+	keyspace, invalid := procKeyspaceHint(fields)
+	if invalid {
+		return nil, true
+	}
+	return NewNoNLHint(keyspace), false
+}
+
+func newNoHashHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procNoHashHints)
+}
+
+func procNoHashHints(fields map[string]interface{}) (OptimHint, bool) {
+	keyspace, invalid := procKeyspaceHint(fields)
+	if invalid {
+		return nil, true
+	}
+	return NewNoHashHint(keyspace), false
+}
+
+func newIndexCombineHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procIndexCombineHints)
+}
+
+func procIndexCombineHints(fields map[string]interface{}) (OptimHint, bool) {
+	keyspace, invalid := procKeyspaceHint(fields)
+	if invalid {
+		return nil, true
+	}
+	return NewIndexCombineHint(keyspace), false
+}
+
+func newNoIndexCombineHints(val value.Value) ([]OptimHint, bool) {
+	return newHints(val, procNoIndexCombineHints)
+}
+
+func procNoIndexCombineHints(fields map[string]interface{}) (OptimHint, bool) {
+	keyspace, invalid := procKeyspaceHint(fields)
+	if invalid {
+		return nil, true
+	}
+	return NewNoIndexCombineHint(keyspace), false
+}
+
+// hints whose only field is the keyspace (alias)
+func procKeyspaceHint(fields map[string]interface{}) (string, bool) {
+	var keyspace string
+	for k, v := range fields {
+		key := strings.ToLower(k)
+		if key != "keyspace" && key != "alias" {
+			return "", true
+		}
+		keyspace = value.NewValue(v).ToString()
+	}
+	return keyspace, keyspace == ""
+}
+
 func newHints(val value.Value, procFunc func(fields map[string]interface{}) (OptimHint, bool)) ([]OptimHint, bool) {
 
 	hints := make([]OptimHint, 0, 1)
@@ -1155,6 +2125,15 @@ func newOrderedHint(val value.Value) ([]OptimHint, bool) {
 	return nil, true
 }
 
+func newParallelHint(val value.Value) ([]OptimHint, bool) {
+	if val != nil && val.Type() == value.NUMBER {
+		if parallelism, ok := value.IsIntValue(val); ok && parallelism > 0 {
+			return []OptimHint{NewParallelHint(int(parallelism))}, false
+		}
+	}
+	return nil, true
+}
+
 // when marshalling we put the optimizer hints in groups:
 // hints_followed, hints_not_followed, invalid_hints
 func (this *OptimHints) MarshalJSON() ([]byte, error) {
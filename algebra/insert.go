@@ -10,6 +10,7 @@ package algebra
 
 import (
 	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/value"
@@ -36,6 +37,7 @@ type Insert struct {
 	values    Pairs                 `json:"values"`
 	query     *Select               `json:"select"`
 	returning *Projection           `json:"returning"`
+	validate  bool
 }
 
 /*
@@ -196,9 +198,18 @@ func (this *Insert) Privileges() (*auth.Privileges, errors.Error) {
 	privs := auth.NewPrivileges()
 	props := this.keyspace.PrivilegeProps()
 	fullKeyspace := this.keyspace.FullName()
-	privs.Add(fullKeyspace, auth.PRIV_QUERY_INSERT, props)
+	isSystem := this.keyspace.IsSystem()
+	if isSystem {
+		datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_INSERT, privs)
+	} else {
+		privs.Add(fullKeyspace, auth.PRIV_QUERY_INSERT, props)
+	}
 	if this.returning != nil {
-		privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		if isSystem {
+			datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_SELECT, privs)
+		} else {
+			privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		}
 	}
 
 	if this.query != nil {
@@ -305,3 +316,15 @@ insert statement.
 func (this *Insert) Returning() *Projection {
 	return this.returning
 }
+
+/*
+Returns whether the new documents are validated against the
+keyspace schema.
+*/
+func (this *Insert) Validate() bool {
+	return this.validate
+}
+
+func (this *Insert) SetValidate(validate bool) {
+	this.validate = validate
+}
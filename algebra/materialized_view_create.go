@@ -0,0 +1,312 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Create materialized view ddl statement. The view is
+named after its target collection, which holds the query results.
+*/
+type CreateMaterializedView struct {
+	statementBase
+
+	keyspace     *KeyspaceRef `json:"keyspace"`
+	query        *Select      `json:"query"`
+	text         string       `json:"text"`
+	with         value.Value  `json:"with"`
+	failIfExists bool         `json:"failIfExists"`
+}
+
+/*
+The function NewCreateMaterializedView returns a pointer to the
+CreateMaterializedView struct with the input argument values as fields.
+*/
+func NewCreateMaterializedView(keyspace *KeyspaceRef, query *Select, text string,
+	with value.Value, failIfExists bool) *CreateMaterializedView {
+	rv := &CreateMaterializedView{
+		keyspace:     keyspace,
+		query:        query,
+		text:         text,
+		with:         with,
+		failIfExists: failIfExists,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitCreateMaterializedView method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *CreateMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateMaterializedView(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateMaterializedView) Signature() value.Value {
+	return nil
+}
+
+/*
+Formalize the view query.
+*/
+func (this *CreateMaterializedView) Formalize() error {
+	return this.query.Formalize()
+}
+
+/*
+This method maps the expressions of the view query.
+*/
+func (this *CreateMaterializedView) MapExpressions(mapper expression.Mapper) error {
+	return this.query.MapExpressions(mapper)
+}
+
+/*
+Return the expressions of the view query.
+*/
+func (this *CreateMaterializedView) Expressions() expression.Expressions {
+	return this.query.Expressions()
+}
+
+/*
+Returns all required privileges: those of the view query, and those
+needed to populate the target collection.
+*/
+func (this *CreateMaterializedView) Privileges() (*auth.Privileges, errors.Error) {
+	privs, err := this.query.Privileges()
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := this.keyspace.FullName()
+	props := this.keyspace.PrivilegeProps()
+	privs.Add(fullName, auth.PRIV_QUERY_INSERT, props)
+	privs.Add(fullName, auth.PRIV_QUERY_UPDATE, props)
+	privs.Add(fullName, auth.PRIV_QUERY_DELETE, props)
+	return privs, nil
+}
+
+/*
+Returns the keyspace reference of the target collection.
+*/
+func (this *CreateMaterializedView) Keyspace() *KeyspaceRef {
+	return this.keyspace
+}
+
+func (this *CreateMaterializedView) Query() *Select {
+	return this.query
+}
+
+/*
+Returns the view query as written.
+*/
+func (this *CreateMaterializedView) Text() string {
+	return this.text
+}
+
+func (this *CreateMaterializedView) With() value.Value {
+	return this.with
+}
+
+func (this *CreateMaterializedView) FailIfExists() bool {
+	return this.failIfExists
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *CreateMaterializedView) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "createMaterializedView"}
+	r["keyspaceRef"] = this.keyspace
+	r["query"] = this.text
+	if this.with != nil {
+		r["with"] = this.with
+	}
+	r["failIfExists"] = this.failIfExists
+	return json.Marshal(r)
+}
+
+func (this *CreateMaterializedView) Type() string {
+	return "CREATE_MATERIALIZED_VIEW"
+}
+
+/*
+Build the view definition from the statement and its options. The
+view query is only broken down into its expressions if it is a simple
+aggregation. Keyspaces without a namespace are placed in the given one.
+*/
+func (this *CreateMaterializedView) Definition(namespace string) (*datastore.MaterializedView, errors.Error) {
+	this.keyspace.SetDefaultNamespace(namespace)
+	name := this.keyspace.FullName()
+	view := &datastore.MaterializedView{
+		Name:   name,
+		Target: this.keyspace.Path().ProtectedString(),
+		Text:   this.text,
+	}
+
+	if this.Params() > 0 {
+		return nil, errors.NewMaterializedViewDefinitionError(name, "the query cannot have parameters")
+	}
+
+	if this.with != nil {
+		if this.with.Type() != value.OBJECT {
+			return nil, errors.NewMaterializedViewDefinitionError(name, "WITH options must be an object")
+		}
+		for option, _ := range this.with.Fields() {
+			val, _ := this.with.Field(option)
+			switch option {
+			case "refresh_interval":
+				s, ok := val.Actual().(string)
+				if !ok {
+					return nil, errors.NewMaterializedViewDefinitionError(name, "refresh_interval must be a duration string")
+				}
+				interval, err := time.ParseDuration(s)
+				if err != nil || interval <= 0 {
+					return nil, errors.NewMaterializedViewDefinitionError(name,
+						fmt.Sprintf("invalid refresh_interval %v", s))
+				}
+				view.Interval = interval
+			case "query_rewrite":
+				b, ok := val.Actual().(bool)
+				if !ok {
+					return nil, errors.NewMaterializedViewDefinitionError(name, "query_rewrite must be a boolean")
+				}
+				view.QueryRewrite = b
+			default:
+				return nil, errors.NewMaterializedViewDefinitionError(name, fmt.Sprintf("unknown option %v", option))
+			}
+		}
+	}
+
+	this.simpleAggregation(view, namespace)
+	if view.Source == view.Target {
+		return nil, errors.NewMaterializedViewDefinitionError(name, "the query cannot read the target collection")
+	}
+	if view.QueryRewrite && !view.Incremental() {
+		return nil, errors.NewMaterializedViewDefinitionError(name,
+			"query_rewrite is only available for simple aggregations")
+	}
+	return view, nil
+}
+
+/*
+A simple aggregation reads a single keyspace, has no ORDER BY, LIMIT,
+OFFSET, WITH, LET, LETTING, HAVING, windows or subqueries, and
+projects group keys and COUNT, SUM, MIN and MAX aggregates without
+modifiers.
+*/
+func (this *CreateMaterializedView) simpleAggregation(view *datastore.MaterializedView, namespace string) {
+	query := this.query
+	if query.Order() != nil || query.Limit() != nil || query.Offset() != nil {
+		return
+	}
+
+	sub, ok := query.Subresult().(*Subselect)
+	if !ok || sub.With() != nil || sub.Let() != nil || sub.Window() != nil {
+		return
+	}
+
+	simple, ok := sub.From().(SimpleFromTerm)
+	if !ok {
+		return
+	}
+	from := GetKeyspaceTerm(simple)
+	if from == nil || from.Path() == nil || from.Keys() != nil || from.Indexes() != nil {
+		return
+	}
+
+	var keys expression.Expressions
+	if group := sub.Group(); group != nil {
+		if group.Letting() != nil || group.Having() != nil {
+			return
+		}
+		keys = group.By()
+	}
+
+	projection := sub.Projection()
+	if projection.Raw() || projection.Distinct() {
+		return
+	}
+
+	subqueries, err := expression.ListSubqueries(sub.Expressions(), false)
+	if err != nil || len(subqueries) > 0 {
+		return
+	}
+
+	fields := make([]datastore.MaterializedField, 0, len(projection.Terms()))
+	for _, term := range projection.Terms() {
+		if term.Star() {
+			return
+		}
+
+		expr := term.Expression()
+		aggregate := ""
+		switch expr.(type) {
+		case *Count:
+			aggregate = "count"
+		case *Sum:
+			aggregate = "sum"
+		case *Min:
+			aggregate = "min"
+		case *Max:
+			aggregate = "max"
+		}
+
+		if aggregate != "" {
+			agg := expr.(Aggregate)
+			if agg.Distinct() || agg.Filter() != nil || agg.WindowTerm() != nil {
+				return
+			}
+		} else if !isGroupKey(expr, keys) {
+			return
+		}
+
+		fields = append(fields, datastore.MaterializedField{
+			Name:      term.Alias(),
+			Expr:      expr.String(),
+			Aggregate: aggregate,
+		})
+	}
+
+	view.Keys = make([]string, len(keys))
+	for i, key := range keys {
+		view.Keys[i] = key.String()
+	}
+	if sub.Where() != nil {
+		view.Where = sub.Where().String()
+	}
+	view.Fields = fields
+	from.SetDefaultNamespace(namespace)
+	view.Alias = from.Alias()
+	view.Source = from.Path().ProtectedString()
+}
+
+func isGroupKey(expr expression.Expression, keys expression.Expressions) bool {
+	for _, key := range keys {
+		if expr.EquivalentTo(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Drop materialized view ddl statement. Dropping a view
+removes its definition and stops its scheduled refreshes, but leaves
+the target collection and its contents in place.
+*/
+type DropMaterializedView struct {
+	statementBase
+
+	keyspace        *KeyspaceRef `json:"keyspace"`
+	failIfNotExists bool         `json:"failIfNotExists"`
+}
+
+/*
+The function NewDropMaterializedView returns a pointer to the
+DropMaterializedView struct with the input argument values as fields.
+*/
+func NewDropMaterializedView(keyspace *KeyspaceRef, failIfNotExists bool) *DropMaterializedView {
+	rv := &DropMaterializedView{
+		keyspace:        keyspace,
+		failIfNotExists: failIfNotExists,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitDropMaterializedView method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *DropMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropMaterializedView(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *DropMaterializedView) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropMaterializedView) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *DropMaterializedView) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropMaterializedView) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *DropMaterializedView) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add(this.keyspace.FullName(), auth.PRIV_QUERY_DELETE, this.keyspace.PrivilegeProps())
+	return privs, nil
+}
+
+/*
+Returns the keyspace reference of the target collection.
+*/
+func (this *DropMaterializedView) Keyspace() *KeyspaceRef {
+	return this.keyspace
+}
+
+func (this *DropMaterializedView) FailIfNotExists() bool {
+	return this.failIfNotExists
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *DropMaterializedView) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "dropMaterializedView"}
+	r["keyspaceRef"] = this.keyspace
+	r["failIfNotExists"] = this.failIfNotExists
+	return json.Marshal(r)
+}
+
+func (this *DropMaterializedView) Type() string {
+	return "DROP_MATERIALIZED_VIEW"
+}
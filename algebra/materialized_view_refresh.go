@@ -0,0 +1,127 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Refresh modes. Without one, views that can be refreshed incrementally
+are, and others are refreshed in full.
+*/
+const (
+	MV_REFRESH_FULL        = "FULL"
+	MV_REFRESH_INCREMENTAL = "INCREMENTAL"
+)
+
+/*
+Represents the Refresh materialized view statement.
+*/
+type RefreshMaterializedView struct {
+	statementBase
+
+	keyspace *KeyspaceRef `json:"keyspace"`
+	mode     string       `json:"mode"`
+}
+
+/*
+The function NewRefreshMaterializedView returns a pointer to the
+RefreshMaterializedView struct with the input argument values as fields.
+*/
+func NewRefreshMaterializedView(keyspace *KeyspaceRef, mode string) *RefreshMaterializedView {
+	rv := &RefreshMaterializedView{
+		keyspace: keyspace,
+		mode:     mode,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitRefreshMaterializedView method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *RefreshMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitRefreshMaterializedView(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *RefreshMaterializedView) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *RefreshMaterializedView) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *RefreshMaterializedView) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *RefreshMaterializedView) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *RefreshMaterializedView) Privileges() (*auth.Privileges, errors.Error) {
+	// the view query is only known at execution time, and the statements
+	// the refresh runs are authorized then
+	return auth.NewPrivileges(), nil
+}
+
+/*
+Returns the keyspace reference of the target collection.
+*/
+func (this *RefreshMaterializedView) Keyspace() *KeyspaceRef {
+	return this.keyspace
+}
+
+/*
+Returns the refresh mode, or an empty string if none was given.
+*/
+func (this *RefreshMaterializedView) Mode() string {
+	return this.mode
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *RefreshMaterializedView) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "refreshMaterializedView"}
+	r["keyspaceRef"] = this.keyspace
+	if this.mode != "" {
+		r["mode"] = this.mode
+	}
+	return json.Marshal(r)
+}
+
+func (this *RefreshMaterializedView) Type() string {
+	return "REFRESH_MATERIALIZED_VIEW"
+}
@@ -30,14 +30,17 @@ clause.
 type Merge struct {
 	statementBase
 
-	keyspace  *KeyspaceRef          `json:"keyspace"`
-	indexes   IndexRefs             `json:"indexes"`
-	source    *MergeSource          `json:"source"`
-	on        expression.Expression `json:"on"`
-	isOnKey   bool                  `json:"is_on_key"`
-	actions   *MergeActions         `json:"actions"`
-	limit     expression.Expression `json:"limit"`
-	returning *Projection           `json:"returning"`
+	keyspace   *KeyspaceRef          `json:"keyspace"`
+	indexes    IndexRefs             `json:"indexes"`
+	source     *MergeSource          `json:"source"`
+	on         expression.Expression `json:"on"`
+	isOnKey    bool                  `json:"is_on_key"`
+	actions    *MergeActions         `json:"actions"`
+	limit      expression.Expression `json:"limit"`
+	returning  *Projection           `json:"returning"`
+	images     bool
+	optimHints *OptimHints `json:"optimizer_hints"`
+	validate   bool
 }
 
 /*
@@ -258,7 +261,7 @@ func (this *Merge) Formalize() (err error) {
 	}
 
 	if this.returning != nil {
-		_, err = this.returning.Formalize(kf)
+		this.images, err = formalizeReturning(this.returning, kf)
 	}
 
 	return
@@ -323,6 +326,37 @@ func (this *Merge) Returning() *Projection {
 	return this.returning
 }
 
+/*
+Returns whether the RETURNING clause references the OLD or
+NEW image of the documents.
+*/
+func (this *Merge) ReturningImages() bool {
+	return this.images
+}
+
+/*
+Returns whether the new documents are validated against the
+keyspace schema.
+*/
+func (this *Merge) Validate() bool {
+	return this.validate
+}
+
+func (this *Merge) SetValidate(validate bool) {
+	this.validate = validate
+}
+
+/*
+Returns the optimizer hints of the statement.
+*/
+func (this *Merge) OptimHints() *OptimHints {
+	return this.optimHints
+}
+
+func (this *Merge) SetOptimHints(optimHints *OptimHints) {
+	this.optimHints = optimHints
+}
+
 func (this *Merge) Type() string {
 	return "MERGE"
 }
@@ -46,6 +46,16 @@ func (this *Order) Copy() *Order {
 	}
 }
 
+/*
+Copy Order
+*/
+func CopyOrder(order *Order) *Order {
+	if order == nil {
+		return nil
+	}
+	return order.Copy()
+}
+
 /*
 Map expressions for the terms by calling MapExpressions.
 */
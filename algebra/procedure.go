@@ -0,0 +1,322 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"github.com/couchbase/query/expression"
+)
+
+/*
+The body of a stored procedure is a block of procedural statements,
+which are run by the procedural language runner.
+Variables are referenced as identifiers in expressions, and as named
+parameters in the N1QL statements that the procedure executes.
+*/
+type ProcStatement interface {
+	/*
+	   Apply mapper to all the expressions of the statement, and of any
+	   statements it contains.
+	*/
+	MapExpressions(mapper expression.Mapper) error
+}
+
+type ProcStatements []ProcStatement
+
+func (this ProcStatements) MapExpressions(mapper expression.Mapper) error {
+	for _, s := range this {
+		err := s.MapExpressions(mapper)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+BEGIN ... [ EXCEPTION WHEN OTHERS [ AS error ] THEN ... ] END
+*/
+type ProcBlock struct {
+	stmts    ProcStatements
+	handler  ProcStatements
+	errorVar string
+}
+
+func NewProcBlock(stmts, handler ProcStatements, errorVar string) *ProcBlock {
+	return &ProcBlock{
+		stmts:    stmts,
+		handler:  handler,
+		errorVar: errorVar,
+	}
+}
+
+func (this *ProcBlock) Statements() ProcStatements {
+	return this.stmts
+}
+
+/*
+The statements run when the block fails, nil if the block has no
+exception handler.
+*/
+func (this *ProcBlock) Handler() ProcStatements {
+	return this.handler
+}
+
+func (this *ProcBlock) ErrorVar() string {
+	return this.errorVar
+}
+
+func (this *ProcBlock) MapExpressions(mapper expression.Mapper) error {
+	err := this.stmts.MapExpressions(mapper)
+	if err == nil {
+		err = this.handler.MapExpressions(mapper)
+	}
+	return err
+}
+
+/*
+DECLARE var [ = expr ]
+*/
+type ProcDeclare struct {
+	variable string
+	expr     expression.Expression
+}
+
+func NewProcDeclare(variable string, expr expression.Expression) *ProcDeclare {
+	return &ProcDeclare{
+		variable: variable,
+		expr:     expr,
+	}
+}
+
+func (this *ProcDeclare) Variable() string {
+	return this.variable
+}
+
+func (this *ProcDeclare) Expression() expression.Expression {
+	return this.expr
+}
+
+func (this *ProcDeclare) MapExpressions(mapper expression.Mapper) (err error) {
+	if this.expr != nil {
+		this.expr, err = mapper.Map(this.expr)
+	}
+	return
+}
+
+/*
+SET var = expr
+*/
+type ProcSet struct {
+	variable string
+	expr     expression.Expression
+}
+
+func NewProcSet(variable string, expr expression.Expression) *ProcSet {
+	return &ProcSet{
+		variable: variable,
+		expr:     expr,
+	}
+}
+
+func (this *ProcSet) Variable() string {
+	return this.variable
+}
+
+func (this *ProcSet) Expression() expression.Expression {
+	return this.expr
+}
+
+func (this *ProcSet) MapExpressions(mapper expression.Mapper) (err error) {
+	this.expr, err = mapper.Map(this.expr)
+	return
+}
+
+/*
+IF cond THEN ... [ ELSEIF cond THEN ... ] [ ELSE ... ] END IF
+ELSEIF branches are nested in the ELSE branch.
+*/
+type ProcIf struct {
+	cond  expression.Expression
+	then  ProcStatements
+	elseS ProcStatements
+}
+
+func NewProcIf(cond expression.Expression, then, elseS ProcStatements) *ProcIf {
+	return &ProcIf{
+		cond:  cond,
+		then:  then,
+		elseS: elseS,
+	}
+}
+
+func (this *ProcIf) Condition() expression.Expression {
+	return this.cond
+}
+
+func (this *ProcIf) Then() ProcStatements {
+	return this.then
+}
+
+func (this *ProcIf) Else() ProcStatements {
+	return this.elseS
+}
+
+func (this *ProcIf) MapExpressions(mapper expression.Mapper) (err error) {
+	this.cond, err = mapper.Map(this.cond)
+	if err == nil {
+		err = this.then.MapExpressions(mapper)
+	}
+	if err == nil {
+		err = this.elseS.MapExpressions(mapper)
+	}
+	return
+}
+
+/*
+WHILE cond DO ... END WHILE
+*/
+type ProcWhile struct {
+	cond expression.Expression
+	body ProcStatements
+}
+
+func NewProcWhile(cond expression.Expression, body ProcStatements) *ProcWhile {
+	return &ProcWhile{
+		cond: cond,
+		body: body,
+	}
+}
+
+func (this *ProcWhile) Condition() expression.Expression {
+	return this.cond
+}
+
+func (this *ProcWhile) Body() ProcStatements {
+	return this.body
+}
+
+func (this *ProcWhile) MapExpressions(mapper expression.Mapper) (err error) {
+	this.cond, err = mapper.Map(this.cond)
+	if err == nil {
+		err = this.body.MapExpressions(mapper)
+	}
+	return
+}
+
+/*
+FOR var IN expr DO ... END FOR
+The loop runs over the elements of an array, such as the results of a
+subquery.
+*/
+type ProcFor struct {
+	variable string
+	expr     expression.Expression
+	body     ProcStatements
+}
+
+func NewProcFor(variable string, expr expression.Expression, body ProcStatements) *ProcFor {
+	return &ProcFor{
+		variable: variable,
+		expr:     expr,
+		body:     body,
+	}
+}
+
+func (this *ProcFor) Variable() string {
+	return this.variable
+}
+
+func (this *ProcFor) Expression() expression.Expression {
+	return this.expr
+}
+
+func (this *ProcFor) Body() ProcStatements {
+	return this.body
+}
+
+func (this *ProcFor) MapExpressions(mapper expression.Mapper) (err error) {
+	this.expr, err = mapper.Map(this.expr)
+	if err == nil {
+		err = this.body.MapExpressions(mapper)
+	}
+	return
+}
+
+/*
+BREAK and CONTINUE
+*/
+type ProcLoopControl struct {
+	cont bool
+}
+
+func NewProcLoopControl(cont bool) *ProcLoopControl {
+	return &ProcLoopControl{
+		cont: cont,
+	}
+}
+
+func (this *ProcLoopControl) Continue() bool {
+	return this.cont
+}
+
+func (this *ProcLoopControl) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+RETURN [ expr ]
+*/
+type ProcReturn struct {
+	expr expression.Expression
+}
+
+func NewProcReturn(expr expression.Expression) *ProcReturn {
+	return &ProcReturn{
+		expr: expr,
+	}
+}
+
+func (this *ProcReturn) Expression() expression.Expression {
+	return this.expr
+}
+
+func (this *ProcReturn) MapExpressions(mapper expression.Mapper) (err error) {
+	if this.expr != nil {
+		this.expr, err = mapper.Map(this.expr)
+	}
+	return
+}
+
+/*
+A N1QL statement, kept as text and executed with the procedure
+variables as named parameters.
+*/
+type ProcExecute struct {
+	text     string
+	stmtType string
+}
+
+func NewProcExecute(text string, stmt Statement) *ProcExecute {
+	return &ProcExecute{
+		text:     text,
+		stmtType: stmt.Type(),
+	}
+}
+
+func (this *ProcExecute) Text() string {
+	return this.text
+}
+
+func (this *ProcExecute) StatementType() string {
+	return this.stmtType
+}
+
+func (this *ProcExecute) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/expression"
+)
+
+/*
+Aliases for the before and after image of each document in the
+RETURNING clause of UPDATE, DELETE and MERGE.
+*/
+const (
+	RETURNING_OLD = "OLD"
+	RETURNING_NEW = "NEW"
+)
+
+/*
+Formalize a RETURNING clause that can reference OLD and NEW, unless
+the keyspace is itself aliased as either. Returns whether the images
+are referenced, so that the mutation knows to attach them.
+*/
+func formalizeReturning(returning *Projection, in *expression.Formalizer) (bool, error) {
+	f := in.Copy()
+	images := make([]string, 0, 2)
+	for _, image := range []string{RETURNING_OLD, RETURNING_NEW} {
+		if image != f.Keyspace() {
+			f.SetAllowedAlias(image, false)
+			images = append(images, image)
+		}
+	}
+
+	_, err := returning.Formalize(f)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, image := range images {
+		if _, ok := f.Identifiers().Field(image); ok {
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	// the images are not part of the result of an unprefixed star
+	for _, term := range returning.terms {
+		if term.star && term.expr == expression.SELF {
+			return false, fmt.Errorf("RETURNING * cannot be combined with %s or %s.", RETURNING_OLD, RETURNING_NEW)
+		}
+	}
+	return true, nil
+}
@@ -347,6 +347,13 @@ func (this *Subselect) From() FromTerm {
 	return this.from
 }
 
+/*
+Set the From clause, e.g. when the planner reorders its joins.
+*/
+func (this *Subselect) SetFrom(from FromTerm) {
+	this.from = from
+}
+
 /*
 Returns the let field that represents the Let
 clause in the subselect statement.
@@ -0,0 +1,190 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/functions"
+	"github.com/couchbase/query/value"
+)
+
+// the documents a trigger function can be passed
+const (
+	TRIGGER_NEW = "NEW"
+	TRIGGER_OLD = "OLD"
+)
+
+/*
+Represents the Create trigger ddl statement. The function arguments
+can refer to the NEW and OLD documents of the row being mutated.
+*/
+type CreateTrigger struct {
+	statementBase
+
+	name         string                 `json:"name"`
+	keyspace     *KeyspaceRef           `json:"keyspace"`
+	timing       string                 `json:"timing"`
+	event        string                 `json:"event"`
+	function     functions.FunctionName `json:"function"`
+	args         expression.Expressions `json:"args"`
+	failIfExists bool                   `json:"failIfExists"`
+}
+
+/*
+The function NewCreateTrigger returns a pointer to the
+CreateTrigger struct with the input argument values as fields.
+*/
+func NewCreateTrigger(name string, keyspace *KeyspaceRef, timing, event string,
+	function functions.FunctionName, args expression.Expressions, failIfExists bool) *CreateTrigger {
+	rv := &CreateTrigger{
+		name:         name,
+		keyspace:     keyspace,
+		timing:       timing,
+		event:        event,
+		function:     function,
+		args:         args,
+		failIfExists: failIfExists,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitCreateTrigger method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *CreateTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateTrigger(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateTrigger) Signature() value.Value {
+	return nil
+}
+
+/*
+Formalize the function arguments, which can only refer to NEW and OLD.
+*/
+func (this *CreateTrigger) Formalize() error {
+	f := expression.NewFormalizer("", nil)
+	f.SetAllowedAlias(TRIGGER_NEW, false)
+	f.SetAllowedAlias(TRIGGER_OLD, false)
+	return this.MapExpressions(f)
+}
+
+/*
+This method maps the function arguments.
+*/
+func (this *CreateTrigger) MapExpressions(mapper expression.Mapper) error {
+	if len(this.args) > 0 {
+		return this.args.MapExpressions(mapper)
+	}
+	return nil
+}
+
+/*
+Returns the function arguments.
+*/
+func (this *CreateTrigger) Expressions() expression.Expressions {
+	return this.args
+}
+
+/*
+Returns all required privileges. Triggers are managed like the
+collections of a scope; the function itself is authorized whenever
+the trigger fires, with the credentials of the statement firing it.
+*/
+func (this *CreateTrigger) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add(triggerScope(this.keyspace), auth.PRIV_QUERY_SCOPE_ADMIN, auth.PRIV_PROPS_NONE)
+
+	for _, expr := range this.args {
+		privs.AddAll(expr.Privileges())
+	}
+	return privs, nil
+}
+
+func triggerScope(keyspace *KeyspaceRef) string {
+	path := keyspace.Path()
+	if path == nil {
+		return keyspace.FullName()
+	}
+	if scope := path.ScopePath(); scope != nil {
+		return scope.FullName()
+	}
+	return path.BucketPath().FullName()
+}
+
+func (this *CreateTrigger) Name() string {
+	return this.name
+}
+
+/*
+Returns the keyspace reference of the keyspace the trigger is defined on.
+*/
+func (this *CreateTrigger) Keyspace() *KeyspaceRef {
+	return this.keyspace
+}
+
+/*
+Returns BEFORE or AFTER.
+*/
+func (this *CreateTrigger) Timing() string {
+	return this.timing
+}
+
+/*
+Returns INSERT, UPDATE or DELETE.
+*/
+func (this *CreateTrigger) Event() string {
+	return this.event
+}
+
+func (this *CreateTrigger) Function() functions.FunctionName {
+	return this.function
+}
+
+func (this *CreateTrigger) Args() expression.Expressions {
+	return this.args
+}
+
+func (this *CreateTrigger) FailIfExists() bool {
+	return this.failIfExists
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *CreateTrigger) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "createTrigger"}
+	r["name"] = this.name
+	r["keyspaceRef"] = this.keyspace
+	r["timing"] = this.timing
+	r["event"] = this.event
+	r["function"] = this.function.Key()
+	args := make([]string, len(this.args))
+	for i, arg := range this.args {
+		args[i] = arg.String()
+	}
+	r["args"] = args
+	r["failIfExists"] = this.failIfExists
+	return json.Marshal(r)
+}
+
+func (this *CreateTrigger) Type() string {
+	return "CREATE_TRIGGER"
+}
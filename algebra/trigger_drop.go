@@ -0,0 +1,121 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Drop trigger ddl statement. Triggers are named within
+the keyspace they are defined on.
+*/
+type DropTrigger struct {
+	statementBase
+
+	name            string       `json:"name"`
+	keyspace        *KeyspaceRef `json:"keyspace"`
+	failIfNotExists bool         `json:"failIfNotExists"`
+}
+
+/*
+The function NewDropTrigger returns a pointer to the
+DropTrigger struct with the input argument values as fields.
+*/
+func NewDropTrigger(name string, keyspace *KeyspaceRef, failIfNotExists bool) *DropTrigger {
+	rv := &DropTrigger{
+		name:            name,
+		keyspace:        keyspace,
+		failIfNotExists: failIfNotExists,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitDropTrigger method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *DropTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropTrigger(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *DropTrigger) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropTrigger) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *DropTrigger) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropTrigger) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *DropTrigger) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add(triggerScope(this.keyspace), auth.PRIV_QUERY_SCOPE_ADMIN, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+func (this *DropTrigger) Name() string {
+	return this.name
+}
+
+/*
+Returns the keyspace reference of the keyspace the trigger is defined on.
+*/
+func (this *DropTrigger) Keyspace() *KeyspaceRef {
+	return this.keyspace
+}
+
+func (this *DropTrigger) FailIfNotExists() bool {
+	return this.failIfNotExists
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *DropTrigger) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "dropTrigger"}
+	r["name"] = this.name
+	r["keyspaceRef"] = this.keyspace
+	r["failIfNotExists"] = this.failIfNotExists
+	return json.Marshal(r)
+}
+
+func (this *DropTrigger) Type() string {
+	return "DROP_TRIGGER"
+}
@@ -10,6 +10,7 @@ package algebra
 
 import (
 	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/value"
@@ -21,14 +22,17 @@ Represents the UPDATE statement.
 type Update struct {
 	statementBase
 
-	keyspace  *KeyspaceRef          `json:"keyspace"`
-	keys      expression.Expression `json:"keys"`
-	indexes   IndexRefs             `json:"indexes"`
-	set       *Set                  `json:"set"`
-	unset     *Unset                `json:"unset"`
-	where     expression.Expression `json:"where"`
-	limit     expression.Expression `json:"limit"`
-	returning *Projection           `json:"returning"`
+	keyspace   *KeyspaceRef          `json:"keyspace"`
+	keys       expression.Expression `json:"keys"`
+	indexes    IndexRefs             `json:"indexes"`
+	set        *Set                  `json:"set"`
+	unset      *Unset                `json:"unset"`
+	where      expression.Expression `json:"where"`
+	limit      expression.Expression `json:"limit"`
+	returning  *Projection           `json:"returning"`
+	images     bool
+	optimHints *OptimHints `json:"optimizer_hints"`
+	validate   bool
 }
 
 func NewUpdate(keyspace *KeyspaceRef, keys expression.Expression, indexes IndexRefs,
@@ -186,9 +190,18 @@ func (this *Update) Privileges() (*auth.Privileges, errors.Error) {
 	privs := auth.NewPrivileges()
 	fullKeyspace := this.keyspace.FullName()
 	props := this.keyspace.PrivilegeProps()
-	privs.Add(fullKeyspace, auth.PRIV_QUERY_UPDATE, props)
+	isSystem := this.keyspace.IsSystem()
+	if isSystem {
+		datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_UPDATE, privs)
+	} else {
+		privs.Add(fullKeyspace, auth.PRIV_QUERY_UPDATE, props)
+	}
 	if this.returning != nil {
-		privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		if isSystem {
+			datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_SELECT, privs)
+		} else {
+			privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		}
 	}
 
 	exprs := this.Expressions()
@@ -253,7 +266,7 @@ func (this *Update) Formalize() (err error) {
 	}
 
 	if this.returning != nil {
-		_, err = this.returning.Formalize(f)
+		this.images, err = formalizeReturning(this.returning, f)
 	}
 
 	return
@@ -320,3 +333,34 @@ UPDATE statement.
 func (this *Update) Returning() *Projection {
 	return this.returning
 }
+
+/*
+Returns whether the RETURNING clause references the OLD or
+NEW image of the documents.
+*/
+func (this *Update) ReturningImages() bool {
+	return this.images
+}
+
+/*
+Returns whether the new documents are validated against the
+keyspace schema.
+*/
+func (this *Update) Validate() bool {
+	return this.validate
+}
+
+func (this *Update) SetValidate(validate bool) {
+	this.validate = validate
+}
+
+/*
+Returns the optimizer hints of the statement.
+*/
+func (this *Update) OptimHints() *OptimHints {
+	return this.optimHints
+}
+
+func (this *Update) SetOptimHints(optimHints *OptimHints) {
+	this.optimHints = optimHints
+}
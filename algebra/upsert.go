@@ -10,6 +10,7 @@ package algebra
 
 import (
 	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/value"
@@ -36,6 +37,7 @@ type Upsert struct {
 	values    Pairs                 `json:"values"`
 	query     *Select               `json:"select"`
 	returning *Projection           `json:"returning"`
+	validate  bool
 }
 
 /*
@@ -189,10 +191,20 @@ func (this *Upsert) Privileges() (*auth.Privileges, errors.Error) {
 	privs := auth.NewPrivileges()
 	props := this.keyspace.PrivilegeProps()
 	fullKeyspace := this.keyspace.FullName()
-	privs.Add(fullKeyspace, auth.PRIV_QUERY_INSERT, props)
-	privs.Add(fullKeyspace, auth.PRIV_QUERY_UPDATE, props)
+	isSystem := this.keyspace.IsSystem()
+	if isSystem {
+		datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_INSERT, privs)
+		datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_UPDATE, privs)
+	} else {
+		privs.Add(fullKeyspace, auth.PRIV_QUERY_INSERT, props)
+		privs.Add(fullKeyspace, auth.PRIV_QUERY_UPDATE, props)
+	}
 	if this.returning != nil {
-		privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		if isSystem {
+			datastore.GetSystemstore().PrivilegesFromPath(fullKeyspace, this.keyspace.Keyspace(), auth.PRIV_QUERY_SELECT, privs)
+		} else {
+			privs.Add(fullKeyspace, auth.PRIV_QUERY_SELECT, props)
+		}
 	}
 
 	if this.query != nil {
@@ -304,6 +316,18 @@ func (this *Upsert) Returning() *Projection {
 	return this.returning
 }
 
+/*
+Returns whether the new documents are validated against the
+keyspace schema.
+*/
+func (this *Upsert) Validate() bool {
+	return this.validate
+}
+
+func (this *Upsert) SetValidate(validate bool) {
+	this.validate = validate
+}
+
 func (this *Upsert) Type() string {
 	return "UPSERT"
 }
@@ -20,6 +20,9 @@ const (
 	USE_HASH_PROBE
 	USE_HASH_EITHER
 	USE_NL
+	USE_MERGE
+	NO_USE_HASH
+	NO_USE_NL
 )
 
 var EMPTY_USE = NewUse(nil, nil, JOIN_HINT_NONE)
@@ -0,0 +1,129 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Alter user statement. It changes the password, the
+options, or both; the options take the same form as for CREATE USER,
+and those given replace the current ones.
+*/
+type AlterUser struct {
+	statementBase
+
+	user     string      `json:"user"`
+	password string      `json:"password"`
+	with     value.Value `json:"with"`
+	redacted string      `json:"redacted"`
+}
+
+/*
+The function NewAlterUser returns a pointer to the
+AlterUser struct with the input argument values as fields.
+*/
+func NewAlterUser(user, password string, with value.Value, redacted string) *AlterUser {
+	rv := &AlterUser{
+		user:     user,
+		password: password,
+		with:     with,
+		redacted: redacted,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitAlterUser method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *AlterUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitAlterUser(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *AlterUser) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *AlterUser) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *AlterUser) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *AlterUser) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *AlterUser) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add("", auth.PRIV_SECURITY_WRITE, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+func (this *AlterUser) User() string {
+	return this.user
+}
+
+/*
+Returns the new password, or an empty string if it is not changed.
+*/
+func (this *AlterUser) Password() string {
+	return this.password
+}
+
+func (this *AlterUser) With() value.Value {
+	return this.with
+}
+
+func (this *AlterUser) RedactedText() string {
+	return this.redacted
+}
+
+/*
+Marshals input receiver into byte array. The password is left out.
+*/
+func (this *AlterUser) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "alterUser"}
+	r["user"] = this.user
+	if this.with != nil {
+		r["with"] = this.with
+	}
+	return json.Marshal(r)
+}
+
+func (this *AlterUser) Type() string {
+	return "ALTER_USER"
+}
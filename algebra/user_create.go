@@ -0,0 +1,156 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Statements holding a password keep a copy of the statement text with
+the password masked, which is what requests show in its place.
+*/
+type Redacted interface {
+	RedactedText() string
+}
+
+/*
+Returns the masked text of a statement holding a password, looking
+through EXPLAIN, ADVISE and PREPARE.
+*/
+func RedactedText(stmt Statement) (string, bool) {
+	switch s := stmt.(type) {
+	case *Explain:
+		return RedactedText(s.Statement())
+	case *Advise:
+		return RedactedText(s.Statement())
+	case *Prepare:
+		return RedactedText(s.Statement())
+	case Redacted:
+		text := s.RedactedText()
+		return text, text != ""
+	}
+	return "", false
+}
+
+/*
+Represents the Create user statement. The options are "name", the
+full name of the user, and "groups", the groups it belongs to.
+*/
+type CreateUser struct {
+	statementBase
+
+	user     string      `json:"user"`
+	password string      `json:"password"`
+	with     value.Value `json:"with"`
+	redacted string      `json:"redacted"`
+}
+
+/*
+The function NewCreateUser returns a pointer to the
+CreateUser struct with the input argument values as fields.
+*/
+func NewCreateUser(user, password string, with value.Value, redacted string) *CreateUser {
+	rv := &CreateUser{
+		user:     user,
+		password: password,
+		with:     with,
+		redacted: redacted,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitCreateUser method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *CreateUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateUser(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateUser) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateUser) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *CreateUser) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *CreateUser) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges. Users are security data, like the
+roles granted to them.
+*/
+func (this *CreateUser) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add("", auth.PRIV_SECURITY_WRITE, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+/*
+Returns the user, in either user_id or domain:user_id form.
+*/
+func (this *CreateUser) User() string {
+	return this.user
+}
+
+func (this *CreateUser) Password() string {
+	return this.password
+}
+
+func (this *CreateUser) With() value.Value {
+	return this.with
+}
+
+func (this *CreateUser) RedactedText() string {
+	return this.redacted
+}
+
+/*
+Marshals input receiver into byte array. The password is left out.
+*/
+func (this *CreateUser) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "createUser"}
+	r["user"] = this.user
+	if this.with != nil {
+		r["with"] = this.with
+	}
+	return json.Marshal(r)
+}
+
+func (this *CreateUser) Type() string {
+	return "CREATE_USER"
+}
@@ -0,0 +1,103 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package algebra
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/value"
+)
+
+/*
+Represents the Drop user statement.
+*/
+type DropUser struct {
+	statementBase
+
+	user string `json:"user"`
+}
+
+/*
+The function NewDropUser returns a pointer to the
+DropUser struct with the input argument values as fields.
+*/
+func NewDropUser(user string) *DropUser {
+	rv := &DropUser{
+		user: user,
+	}
+
+	rv.stmt = rv
+	return rv
+}
+
+/*
+It calls the VisitDropUser method by passing
+in the receiver and returns the interface. It is a
+visitor pattern.
+*/
+func (this *DropUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropUser(this)
+}
+
+/*
+Returns nil.
+*/
+func (this *DropUser) Signature() value.Value {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropUser) Formalize() error {
+	return nil
+}
+
+/*
+This method maps all the constituent clauses, but here none have expressions
+*/
+func (this *DropUser) MapExpressions(mapper expression.Mapper) error {
+	return nil
+}
+
+/*
+Returns nil.
+*/
+func (this *DropUser) Expressions() expression.Expressions {
+	return nil
+}
+
+/*
+Returns all required privileges.
+*/
+func (this *DropUser) Privileges() (*auth.Privileges, errors.Error) {
+	privs := auth.NewPrivileges()
+	privs.Add("", auth.PRIV_SECURITY_WRITE, auth.PRIV_PROPS_NONE)
+	return privs, nil
+}
+
+func (this *DropUser) User() string {
+	return this.user
+}
+
+/*
+Marshals input receiver into byte array.
+*/
+func (this *DropUser) MarshalJSON() ([]byte, error) {
+	r := map[string]interface{}{"type": "dropUser"}
+	r["user"] = this.user
+	return json.Marshal(r)
+}
+
+func (this *DropUser) Type() string {
+	return "DROP_USER"
+}
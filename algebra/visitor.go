@@ -43,12 +43,34 @@ type Visitor interface {
 	VisitDropCollection(stmt *DropCollection) (interface{}, error)
 	VisitFlushCollection(stmt *FlushCollection) (interface{}, error)
 
+	/*
+	   Visitor for MATERIALIZED VIEW statements.
+	*/
+	VisitCreateMaterializedView(stmt *CreateMaterializedView) (interface{}, error)
+	VisitRefreshMaterializedView(stmt *RefreshMaterializedView) (interface{}, error)
+	VisitDropMaterializedView(stmt *DropMaterializedView) (interface{}, error)
+
+	/*
+	   Visitor for TRIGGER statements.
+	*/
+	VisitCreateTrigger(stmt *CreateTrigger) (interface{}, error)
+	VisitDropTrigger(stmt *DropTrigger) (interface{}, error)
+
 	/*
 	   Visitor for ROLES statements.
 	*/
 	VisitGrantRole(stmt *GrantRole) (interface{}, error)
 	VisitRevokeRole(stmt *RevokeRole) (interface{}, error)
 
+	/*
+	   Visitor for USER and GROUP statements.
+	*/
+	VisitCreateUser(stmt *CreateUser) (interface{}, error)
+	VisitAlterUser(stmt *AlterUser) (interface{}, error)
+	VisitDropUser(stmt *DropUser) (interface{}, error)
+	VisitCreateGroup(stmt *CreateGroup) (interface{}, error)
+	VisitDropGroup(stmt *DropGroup) (interface{}, error)
+
 	/*
 	   Visitor for EXPLAIN statements.
 	*/
@@ -437,6 +437,11 @@ var _EVENT_TYPE_MAP = map[string]uint32{
 	"ROLLBACK_SAVEPOINT":        28723,
 	"SET_TRANSACTION_ISOLATION": 28724,
 	"SAVEPOINT":                 28725,
+	"CREATE_USER":               28730,
+	"ALTER_USER":                28731,
+	"DROP_USER":                 28732,
+	"CREATE_GROUP":              28733,
+	"DROP_GROUP":                28734,
 }
 
 func Submit(event Auditable) {
@@ -509,6 +514,7 @@ const (
 	API_ADMIN_INDEXES_TRANSACTIONS       = 28727
 	API_ADMIN_FUNCTIONS_BACKUP           = 28728
 	API_ADMIN_SHUTDOWN                   = 28729
+	API_ADMIN_PLAN_BASELINES             = 28735
 )
 
 func SubmitApiRequest(event *ApiAuditFields) {
@@ -693,6 +699,10 @@ var AUTH_ERRORS = map[errors.ErrorCode]bool{
 	errors.E_SYSTEM_UNABLE_TO_UPDATE:            true,
 	errors.E_SYSTEM_FILTERED_ROWS_WARNING:       true,
 	errors.E_USER_NOT_FOUND:                     true,
+	errors.E_USER_EXISTS:                        true,
+	errors.E_GROUP_NOT_FOUND:                    true,
+	errors.E_GROUP_EXISTS:                       true,
+	errors.E_USER_OPTION:                        true,
 	errors.E_ROLE_REQUIRES_KEYSPACE:             true,
 	errors.E_ROLE_TAKES_NO_KEYSPACE:             true,
 	errors.E_ROLE_NOT_FOUND:                     true,
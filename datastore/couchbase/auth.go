@@ -85,6 +85,8 @@ func privilegeString(namespace, target, obj string, requested auth.Privilege) (s
 		permission = "cluster.n1ql.meta!backup"
 	case auth.PRIV_BACKUP_BUCKET:
 		permission = join3Strings("cluster.bucket[", target, "].n1ql.meta!backup")
+	case auth.PRIV_CLUSTER_ADMIN:
+		permission = "cluster.settings!write"
 	default:
 		return "", fmt.Errorf("Invalid Privileges")
 	}
@@ -65,6 +65,9 @@ func messageForDeniedPrivilege(pair auth.PrivilegePair) string {
 	case auth.PRIV_BACKUP_BUCKET:
 		privilege = "backup bucket metadata"
 		role = fmt.Sprintf("data_backup on %s", keyspace)
+	case auth.PRIV_CLUSTER_ADMIN:
+		privilege = "queries changing cluster settings"
+		role = "cluster_admin"
 	case auth.PRIV_QUERY_MANAGE_FUNCTIONS:
 		privilege = "manage global functions"
 		role = "query_manage_global_functions"
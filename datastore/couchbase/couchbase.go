@@ -477,35 +477,107 @@ func (s *store) GetUserInfoAll() ([]datastore.User, errors.Error) {
 		resultUsers[i].Name = u.Name
 		resultUsers[i].Id = u.Id
 		resultUsers[i].Domain = u.Domain
-		roles := make([]datastore.Role, len(u.Roles))
-		for j, r := range u.Roles {
-			roles[j].Name = r.Role
-			if r.CollectionName != "" && r.CollectionName != "*" {
-				roles[j].Target = r.BucketName + ":" + r.ScopeName + ":" + r.CollectionName
-			} else if r.ScopeName != "" && r.ScopeName != "*" {
-				roles[j].Target = r.BucketName + ":" + r.ScopeName
-			} else if r.BucketName != "" {
-				roles[j].Target = r.BucketName
+		resultUsers[i].Groups = u.Groups
+
+		// roles the user only has through its groups are not its own
+		roles := make([]datastore.Role, 0, len(u.Roles))
+		for _, r := range u.Roles {
+			if !userRole(r) {
+				continue
 			}
+			roles = append(roles, roleFromCb(r))
 		}
 		resultUsers[i].Roles = roles
 	}
 	return resultUsers, nil
 }
 
+func userRole(r cb.Role) bool {
+	if len(r.Origins) == 0 {
+		return true
+	}
+	for _, o := range r.Origins {
+		if o.Type == "user" {
+			return true
+		}
+	}
+	return false
+}
+
+func roleFromCb(r cb.Role) datastore.Role {
+	role := datastore.Role{Name: r.Role}
+	if r.CollectionName != "" && r.CollectionName != "*" {
+		role.Target = r.BucketName + ":" + r.ScopeName + ":" + r.CollectionName
+	} else if r.ScopeName != "" && r.ScopeName != "*" {
+		role.Target = r.BucketName + ":" + r.ScopeName
+	} else if r.BucketName != "" {
+		role.Target = r.BucketName
+	}
+	return role
+}
+
+func rolesToCb(roles []datastore.Role) []cb.Role {
+	rv := make([]cb.Role, len(roles))
+	for i, r := range roles {
+		rv[i].Role = r.Name
+		if len(r.Target) > 0 {
+			rv[i].BucketName = r.Target
+		}
+	}
+	return rv
+}
+
 func (s *store) PutUserInfo(u *datastore.User) errors.Error {
 	var outputUser cb.User
 	outputUser.Name = u.Name
 	outputUser.Id = u.Id
-	outputUser.Roles = make([]cb.Role, len(u.Roles))
+	outputUser.Roles = rolesToCb(u.Roles)
 	outputUser.Domain = u.Domain
-	for i, r := range u.Roles {
-		outputUser.Roles[i].Role = r.Name
-		if len(r.Target) > 0 {
-			outputUser.Roles[i].BucketName = r.Target
+	outputUser.Groups = u.Groups
+	outputUser.Password = u.Password
+	err := s.client.PutUserInfo(&outputUser)
+	if err != nil {
+		return errors.NewSystemUnableToUpdateError(err)
+	}
+	return nil
+}
+
+func (s *store) DeleteUserInfo(u *datastore.User) errors.Error {
+	err := s.client.DeleteUserInfo(&cb.User{Id: u.Id, Domain: u.Domain})
+	if err != nil {
+		return errors.NewSystemUnableToUpdateError(err)
+	}
+	return nil
+}
+
+func (s *store) GetGroupInfoAll() ([]datastore.Group, errors.Error) {
+	sourceGroups, err := s.client.GetGroupInfoAll()
+	if err != nil {
+		return nil, errors.NewSystemUnableToRetrieveError(err)
+	}
+	resultGroups := make([]datastore.Group, len(sourceGroups))
+	for i, g := range sourceGroups {
+		resultGroups[i].Id = g.Id
+		resultGroups[i].Description = g.Description
+		roles := make([]datastore.Role, len(g.Roles))
+		for j, r := range g.Roles {
+			roles[j] = roleFromCb(r)
 		}
+		resultGroups[i].Roles = roles
 	}
-	err := s.client.PutUserInfo(&outputUser)
+	return resultGroups, nil
+}
+
+func (s *store) PutGroupInfo(g *datastore.Group) errors.Error {
+	err := s.client.PutGroupInfo(&cb.Group{Id: g.Id, Description: g.Description, Roles: rolesToCb(g.Roles)})
+	if err != nil {
+		return errors.NewSystemUnableToUpdateError(err)
+	}
+	return nil
+}
+
+func (s *store) DeleteGroupInfo(g *datastore.Group) errors.Error {
+	err := s.client.DeleteGroupInfo(&cb.Group{Id: g.Id})
 	if err != nil {
 		return errors.NewSystemUnableToUpdateError(err)
 	}
@@ -52,6 +52,10 @@ type Datastore interface {
 	UserInfo() (value.Value, errors.Error)                                                 // The users, and their roles. JSON data.
 	GetUserInfoAll() ([]User, errors.Error)                                                // Get information about all the users.
 	PutUserInfo(u *User) errors.Error                                                      // Set information for a specific user.
+	DeleteUserInfo(u *User) errors.Error                                                   // Remove a specific user.
+	GetGroupInfoAll() ([]Group, errors.Error)                                              // Get information about all the groups.
+	PutGroupInfo(g *Group) errors.Error                                                    // Set information for a specific group.
+	DeleteGroupInfo(g *Group) errors.Error                                                 // Remove a specific group.
 	GetRolesAll() ([]Role, errors.Error)                                                   // Get all roles that exist in the system.
 
 	AuditInfo() (*AuditInfo, errors.Error)
@@ -424,11 +428,20 @@ func IndexerQualifiedKeyspacePath(indexer Indexer) string {
 // Very similar structures exist in primitives/couchbase, but to keep open the
 // possibility of connecting to other back ends, the query engine
 // uses its own representation.
+// Password is only ever set to be changed, and is never returned.
 type User struct {
-	Name   string
-	Id     string
-	Domain string
-	Roles  []Role
+	Name     string
+	Id       string
+	Domain   string
+	Roles    []Role
+	Groups   []string
+	Password string
+}
+
+type Group struct {
+	Id          string
+	Description string
+	Roles       []Role
 }
 
 type Role struct {
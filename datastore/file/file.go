@@ -7,10 +7,8 @@
 //  the file licenses/APL2.txt.
 
 /*
-
 Package file provides a file-based implementation of the datastore
 package.
-
 */
 package file
 
@@ -42,7 +40,8 @@ type store struct {
 	namespaceNames []string
 	inferencer     datastore.Inferencer // what we use to infer schemas
 
-	users map[string]*datastore.User
+	users  map[string]*datastore.User
+	groups map[string]*datastore.Group
 }
 
 func (s *store) Id() string {
@@ -150,16 +149,44 @@ func (s *store) UserInfo() (value.Value, errors.Error) {
 func (s *store) GetUserInfoAll() ([]datastore.User, errors.Error) {
 	ret := make([]datastore.User, 0, len(s.users))
 	for _, v := range s.users {
-		ret = append(ret, *v)
+		u := *v
+		u.Password = ""
+		ret = append(ret, u)
 	}
 	return ret, nil
 }
 
 func (s *store) PutUserInfo(u *datastore.User) errors.Error {
+	if old, ok := s.users[u.Id]; ok && u.Password == "" {
+		u.Password = old.Password
+	}
 	s.users[u.Id] = u
 	return nil
 }
 
+func (s *store) DeleteUserInfo(u *datastore.User) errors.Error {
+	delete(s.users, u.Id)
+	return nil
+}
+
+func (s *store) GetGroupInfoAll() ([]datastore.Group, errors.Error) {
+	ret := make([]datastore.Group, 0, len(s.groups))
+	for _, v := range s.groups {
+		ret = append(ret, *v)
+	}
+	return ret, nil
+}
+
+func (s *store) PutGroupInfo(g *datastore.Group) errors.Error {
+	s.groups[g.Id] = g
+	return nil
+}
+
+func (s *store) DeleteGroupInfo(g *datastore.Group) errors.Error {
+	delete(s.groups, g.Id)
+	return nil
+}
+
 func (s *store) GetRolesAll() ([]datastore.Role, errors.Error) {
 	return []datastore.Role{
 		datastore.Role{Name: "cluster_admin"},
@@ -207,7 +234,8 @@ func NewDatastore(path string) (s datastore.Datastore, e errors.Error) {
 		return nil, errors.NewFileDatastoreError(er, "")
 	}
 
-	fs := &store{path: path, users: make(map[string]*datastore.User, 4)}
+	fs := &store{path: path, users: make(map[string]*datastore.User, 4),
+		groups: make(map[string]*datastore.Group, 4)}
 
 	e = fs.loadNamespaces()
 	if e != nil {
@@ -0,0 +1,89 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+/*
+KeyStore holds the encryption keys that ENCRYPT and DECRYPT refer to
+by name. Statements only ever carry key names, so key material does
+not appear in statement text, logs, system:completed_requests or
+plans.
+
+The default store is empty. cbq-engine loads the keys from the file
+given by -keystore, and deployments that use an external key manager
+replace the store with SetKeyStore.
+*/
+type KeyStore interface {
+	Key(name string) ([]byte, bool)
+}
+
+var _KEYSTORE KeyStore = newKeyStore(nil)
+
+func SetKeyStore(keyStore KeyStore) {
+	_KEYSTORE = keyStore
+}
+
+func GetKeyStore() KeyStore {
+	return _KEYSTORE
+}
+
+/*
+Returns a KeyStore holding the keys in the given file, a JSON object
+mapping key names to base64 encoded AES keys of 16, 24 or 32 bytes.
+Errors name the offending key, never its contents.
+*/
+func NewFileKeyStore(path string) (KeyStore, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded map[string]string
+	if err = json.Unmarshal(bytes, &encoded); err != nil {
+		return nil, fmt.Errorf("Invalid key file %s: expected an object of base64 encoded keys", path)
+	}
+
+	keys := make(map[string][]byte, len(encoded))
+	for name, s := range encoded {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid key %s in key file %s: key is not base64 encoded", name, path)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("Invalid key %s in key file %s: key must be 16, 24 or 32 bytes", name, path)
+		}
+		keys[name] = key
+	}
+
+	return newKeyStore(keys), nil
+}
+
+type keyStore struct {
+	keys map[string][]byte
+}
+
+func newKeyStore(keys map[string][]byte) *keyStore {
+	if keys == nil {
+		keys = make(map[string][]byte)
+	}
+	return &keyStore{keys: keys}
+}
+
+func (this *keyStore) Key(name string) ([]byte, bool) {
+	key, ok := this.keys[name]
+	return key, ok
+}
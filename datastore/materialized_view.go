@@ -0,0 +1,500 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/metadata"
+	"github.com/couchbase/query/util"
+	"github.com/couchbase/query/value"
+)
+
+/*
+A materialized view stores the results of a query in a target
+collection, and is named after it: Name is the full name of the
+collection, and Target and Source are keyspace paths as used in
+statements. The query text is kept as written, and is what a full
+refresh runs, in the query context the view was created in.
+
+Simple aggregations - a single keyspace, an optional WHERE clause,
+GROUP BY, and a projection made of group keys and COUNT, SUM, MIN and
+MAX - are also broken down into formalized expressions, which allow
+incremental refreshes and query rewriting; Source is empty for any
+other query.
+*/
+type MaterializedView struct {
+	Name         string
+	Target       string
+	QueryContext string
+	Text         string
+	Source       string
+	Alias        string
+	Where        string
+	Keys         []string
+	Fields       []MaterializedField
+	Interval     time.Duration
+	QueryRewrite bool
+
+	sync.Mutex
+	refreshing  sync.Mutex
+	watermark   value.Value
+	sourceCount int64
+	lastRefresh time.Time
+	lease       string
+}
+
+/*
+A projection term of a simple aggregation. Aggregate is one of count,
+sum, min and max, or empty for group keys.
+*/
+type MaterializedField struct {
+	Name      string `json:"name"`
+	Expr      string `json:"expr"`
+	Aggregate string `json:"aggregate,omitempty"`
+}
+
+func (this *MaterializedView) Incremental() bool {
+	return this.Source != ""
+}
+
+/*
+Refreshes of a view are run one at a time.
+*/
+func (this *MaterializedView) BeginRefresh() {
+	this.refreshing.Lock()
+}
+
+func (this *MaterializedView) EndRefresh() {
+	this.refreshing.Unlock()
+}
+
+/*
+The highest source document CAS included in the view, and the time of
+the last refresh, which is zero until the view has been populated.
+*/
+func (this *MaterializedView) Refreshed() (value.Value, time.Time) {
+	this.Lock()
+	defer this.Unlock()
+	return this.watermark, this.lastRefresh
+}
+
+/*
+The number of source documents aggregated by the view, which tells
+incremental refreshes whether documents have been changed or removed
+since.
+*/
+func (this *MaterializedView) SourceCount() int64 {
+	this.Lock()
+	defer this.Unlock()
+	return this.sourceCount
+}
+
+func (this *MaterializedView) SetRefreshed(watermark value.Value, sourceCount int64, lastRefresh time.Time) {
+	this.Lock()
+	defer this.Unlock()
+	this.watermark = watermark
+	this.sourceCount = sourceCount
+	this.lastRefresh = lastRefresh
+}
+
+// same definition, whatever the refresh state
+func (this *MaterializedView) sameAs(other *MaterializedView) bool {
+	return this.Name == other.Name && this.Target == other.Target && this.QueryContext == other.QueryContext &&
+		this.Text == other.Text && this.Interval == other.Interval && this.QueryRewrite == other.QueryRewrite
+}
+
+type materializedViewEntry struct {
+	Name         string              `json:"name"`
+	Target       string              `json:"target"`
+	QueryContext string              `json:"query_context,omitempty"`
+	Text         string              `json:"text"`
+	Source       string              `json:"source,omitempty"`
+	Alias        string              `json:"alias,omitempty"`
+	Where        string              `json:"where,omitempty"`
+	Keys         []string            `json:"keys,omitempty"`
+	Fields       []MaterializedField `json:"fields,omitempty"`
+	Interval     time.Duration       `json:"interval,omitempty"`
+	QueryRewrite bool                `json:"query_rewrite,omitempty"`
+	Watermark    json.RawMessage     `json:"watermark,omitempty"`
+	SourceCount  int64               `json:"source_count,omitempty"`
+	LastRefresh  time.Time           `json:"last_refresh"`
+	Lease        *refreshLease       `json:"lease,omitempty"`
+}
+
+// held by the node refreshing the view, identified by the token
+type refreshLease struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+func encodeMaterializedView(view *MaterializedView) ([]byte, error) {
+	entry, err := newMaterializedViewEntry(view)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entry)
+}
+
+// the watermark is kept as JSON text, as CAS values exceed float precision
+func newMaterializedViewEntry(view *MaterializedView) (*materializedViewEntry, error) {
+	watermark, sourceCount, lastRefresh := func() (value.Value, int64, time.Time) {
+		view.Lock()
+		defer view.Unlock()
+		return view.watermark, view.sourceCount, view.lastRefresh
+	}()
+	entry := &materializedViewEntry{
+		Name:         view.Name,
+		Target:       view.Target,
+		QueryContext: view.QueryContext,
+		Text:         view.Text,
+		Source:       view.Source,
+		Alias:        view.Alias,
+		Where:        view.Where,
+		Keys:         view.Keys,
+		Fields:       view.Fields,
+		Interval:     view.Interval,
+		QueryRewrite: view.QueryRewrite,
+		SourceCount:  sourceCount,
+		LastRefresh:  lastRefresh,
+	}
+	if watermark != nil {
+		bytes, err := watermark.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		entry.Watermark = bytes
+	}
+	return entry, nil
+}
+
+func decodeMaterializedView(bytes []byte) (*MaterializedView, error) {
+	var entry materializedViewEntry
+	err := json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry.view(), nil
+}
+
+func (this *materializedViewEntry) view() *MaterializedView {
+	rv := &MaterializedView{
+		Name:         this.Name,
+		Target:       this.Target,
+		QueryContext: this.QueryContext,
+		Text:         this.Text,
+		Source:       this.Source,
+		Alias:        this.Alias,
+		Where:        this.Where,
+		Keys:         this.Keys,
+		Fields:       this.Fields,
+		Interval:     this.Interval,
+		QueryRewrite: this.QueryRewrite,
+		sourceCount:  this.SourceCount,
+		lastRefresh:  this.LastRefresh,
+	}
+	if len(this.Watermark) > 0 {
+		rv.watermark = value.NewValue([]byte(this.Watermark))
+	}
+	return rv
+}
+
+/*
+MaterializedViewStore holds the materialized view definitions, keyed
+by the full path of the target collection, and is listed through
+system:materialized_views.
+
+AddView returns false if the view exists, and DropView returns nil if
+it doesn't. SaveRefresh records the refresh state of a view, so that
+every node rewrites queries against it and refreshes it from where
+the last refresh left off. Errors are failures to store the change.
+
+Refreshes of a stored view are exclusive across the cluster: a refresh
+claims the view before it starts, renews its claim before every change
+to the target collection, and releases it when done, saving the new
+refresh state if asked to. Each returns false if another node holds
+the claim, in which case the refresh must leave the view alone.
+*/
+type MaterializedViewStore interface {
+	View(name string) (*MaterializedView, bool)
+	AddView(view *MaterializedView) (bool, error)
+	DropView(name string) (*MaterializedView, error)
+	SaveRefresh(view *MaterializedView) error
+	ClaimRefresh(view *MaterializedView) (bool, error)
+	RenewRefresh(view *MaterializedView) (bool, error)
+	ReleaseRefresh(view *MaterializedView, save bool) (bool, error)
+	Names() []string
+}
+
+/*
+The target collections are shared by the cluster, so the server keeps
+the view definitions in metakv as well; this store, which lasts as
+long as the process, serves everything else.
+*/
+var _MVSTORE MaterializedViewStore = newMaterializedViewStore()
+
+func SetMaterializedViewStore(store MaterializedViewStore) {
+	_MVSTORE = store
+}
+
+func GetMaterializedViewStore() MaterializedViewStore {
+	return _MVSTORE
+}
+
+/*
+The store tells the observer of views as they are added and dropped,
+whichever node made the change, so that every node can schedule their
+refreshes; views replaced by a new definition are dropped and added.
+*/
+var _MVOBSERVER = func(view *MaterializedView, dropped bool) {}
+
+func SetMaterializedViewObserver(observer func(view *MaterializedView, dropped bool)) {
+	_MVOBSERVER = observer
+}
+
+const _MATERIALIZED_VIEWS_PATH = "/query/materialized_views/"
+
+type materializedViewStore struct {
+	sync.RWMutex
+	views  map[string]*MaterializedView
+	mirror *metadata.Mirror
+}
+
+func newMaterializedViewStore() *materializedViewStore {
+	return &materializedViewStore{views: make(map[string]*MaterializedView)}
+}
+
+func NewMetakvMaterializedViewStore() MaterializedViewStore {
+	rv := newMaterializedViewStore()
+	rv.mirror = metadata.NewMirror(_MATERIALIZED_VIEWS_PATH, rv.applyEntry)
+	rv.mirror.Start()
+	return rv
+}
+
+func (this *materializedViewStore) View(name string) (*MaterializedView, bool) {
+	this.RLock()
+	defer this.RUnlock()
+	view, ok := this.views[name]
+	return view, ok
+}
+
+func (this *materializedViewStore) AddView(view *MaterializedView) (bool, error) {
+	this.Lock()
+	_, ok := this.views[view.Name]
+	if !ok {
+		this.views[view.Name] = view
+	}
+	this.Unlock()
+	if ok {
+		return false, nil
+	}
+	_MVOBSERVER(view, false)
+	return true, this.SaveRefresh(view)
+}
+
+func (this *materializedViewStore) DropView(name string) (*MaterializedView, error) {
+	this.Lock()
+	view := this.views[name]
+	delete(this.views, name)
+	this.Unlock()
+	if view == nil {
+		return nil, nil
+	}
+	_MVOBSERVER(view, true)
+	if this.mirror != nil {
+		return view, this.mirror.Delete(name)
+	}
+	return view, nil
+}
+
+func (this *materializedViewStore) SaveRefresh(view *MaterializedView) error {
+	if this.mirror == nil {
+		return nil
+	}
+	bytes, err := encodeMaterializedView(view)
+	if err != nil {
+		return err
+	}
+	return this.mirror.Set(view.Name, bytes)
+}
+
+/*
+Claims are leases, stored with the view, which lapse if not renewed in
+time, so that views outlive the nodes that refresh them. Leases are
+only ever taken over once they have lapsed, and every change to one is
+conditional on the revision it was read at, so that a node renewing or
+releasing its lease knows whether another node has taken the view over
+in the meantime.
+
+Claiming a view also brings its refresh state up to date, so that a
+refresh starts from where the last one left off, on whichever node.
+Views not stored yet, which is to say views being created, are only
+known to the node creating them, and need no lease.
+*/
+const _REFRESH_LEASE = 10 * time.Minute
+
+func (this *materializedViewStore) ClaimRefresh(view *MaterializedView) (bool, error) {
+	if this.mirror == nil {
+		return true, nil
+	}
+	entry, rev, err := this.storedEntry(view.Name)
+	if err != nil || entry == nil {
+		return err == nil, err
+	}
+	now := time.Now()
+	if entry.Lease != nil && entry.Lease.Expiry.After(now) {
+		return false, nil
+	}
+	token, err := util.UUIDV4()
+	if err != nil {
+		return false, err
+	}
+	entry.Lease = &refreshLease{Token: token, Expiry: now.Add(_REFRESH_LEASE)}
+	ok, err := this.setEntry(view.Name, entry, rev)
+	if !ok {
+		return false, err
+	}
+
+	stored := entry.view()
+	view.Lock()
+	view.lease = token
+	if stored.lastRefresh.After(view.lastRefresh) {
+		view.watermark = stored.watermark
+		view.sourceCount = stored.sourceCount
+		view.lastRefresh = stored.lastRefresh
+	}
+	view.Unlock()
+	return true, nil
+}
+
+func (this *materializedViewStore) RenewRefresh(view *MaterializedView) (bool, error) {
+	token := view.leaseToken()
+	if this.mirror == nil || token == "" {
+		return true, nil
+	}
+	entry, rev, err := this.storedEntry(view.Name)
+	if err != nil || !entry.leasedTo(token) {
+		return false, err
+	}
+	entry.Lease.Expiry = time.Now().Add(_REFRESH_LEASE)
+	return this.setEntry(view.Name, entry, rev)
+}
+
+func (this *materializedViewStore) ReleaseRefresh(view *MaterializedView, save bool) (bool, error) {
+	token := view.leaseToken()
+	view.Lock()
+	view.lease = ""
+	view.Unlock()
+	if token == "" {
+		if save {
+			return true, this.SaveRefresh(view)
+		}
+		return true, nil
+	}
+
+	entry, rev, err := this.storedEntry(view.Name)
+	if err != nil || !entry.leasedTo(token) {
+		return false, err
+	}
+	if save {
+		entry, err = newMaterializedViewEntry(view)
+		if err != nil {
+			return false, err
+		}
+	}
+	entry.Lease = nil
+	return this.setEntry(view.Name, entry, rev)
+}
+
+// the entry as stored, with its revision, or nil if the view has been dropped
+func (this *materializedViewStore) storedEntry(name string) (*materializedViewEntry, interface{}, error) {
+	bytes, rev, err := this.mirror.Get(name)
+	if err != nil || bytes == nil {
+		return nil, nil, err
+	}
+	var entry materializedViewEntry
+	err = json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &entry, rev, nil
+}
+
+func (this *materializedViewStore) setEntry(name string, entry *materializedViewEntry, rev interface{}) (bool, error) {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return false, err
+	}
+	return this.mirror.SetIf(name, bytes, rev)
+}
+
+func (this *materializedViewEntry) leasedTo(token string) bool {
+	return this != nil && this.Lease != nil && this.Lease.Token == token
+}
+
+func (this *MaterializedView) leaseToken() string {
+	this.Lock()
+	defer this.Unlock()
+	return this.lease
+}
+
+/*
+Apply a change reported by metakv. A view whose definition is
+unchanged only takes the refresh state, so that the view stays the
+same object, which scheduled refreshes rely on.
+*/
+func (this *materializedViewStore) applyEntry(name string, bytes []byte) {
+	if bytes == nil {
+		this.Lock()
+		old := this.views[name]
+		delete(this.views, name)
+		this.Unlock()
+		if old != nil {
+			_MVOBSERVER(old, true)
+		}
+		return
+	}
+	view, err := decodeMaterializedView(bytes)
+	if err != nil {
+		logging.Errorf("Ignoring materialized view %v: %v", name, err)
+		return
+	}
+
+	this.Lock()
+	old, ok := this.views[name]
+	if ok && old.sameAs(view) {
+		this.Unlock()
+		_, lastRefresh := old.Refreshed()
+		if view.lastRefresh.After(lastRefresh) {
+			old.SetRefreshed(view.watermark, view.sourceCount, view.lastRefresh)
+		}
+		return
+	}
+	this.views[name] = view
+	this.Unlock()
+	if ok {
+		_MVOBSERVER(old, true)
+	}
+	_MVOBSERVER(view, false)
+}
+
+func (this *materializedViewStore) Names() []string {
+	this.RLock()
+	defer this.RUnlock()
+	rv := make([]string, 0, len(this.views))
+	for name, _ := range this.views {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
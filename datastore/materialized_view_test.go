@@ -0,0 +1,109 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/query/value"
+)
+
+func TestMaterializedViewEntries(t *testing.T) {
+	view := &MaterializedView{Name: "default:orders.s.by_region", Target: "default:orders.s.by_region",
+		Text: "SELECT o.region, COUNT(1) AS n FROM orders o GROUP BY o.region", Source: "default:orders",
+		Alias: "o", Keys: []string{"o.region"}, Fields: []MaterializedField{{Name: "n", Expr: "COUNT(1)", Aggregate: "COUNT"}}}
+	refreshed := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// CAS values do not fit in a float64
+	view.SetRefreshed(value.NewValue(int64(1623456789012345678)), 42, refreshed)
+
+	bytes, err := encodeMaterializedView(view)
+	if err != nil {
+		t.Fatalf("Unexpected encoding error: %v", err)
+	}
+	decoded, err := decodeMaterializedView(bytes)
+	if err != nil {
+		t.Fatalf("Unexpected decoding error: %v", err)
+	}
+	if !decoded.sameAs(view) || decoded.SourceCount() != 42 {
+		t.Errorf("Unexpected decoded view %+v", decoded)
+	}
+	watermark, last := decoded.Refreshed()
+	if watermark.String() != "1623456789012345678" || !last.Equal(refreshed) {
+		t.Errorf("Unexpected refresh state %v, %v", watermark, last)
+	}
+
+	store := newMaterializedViewStore()
+	if ok, err := store.AddView(view); !ok || err != nil {
+		t.Fatalf("Unexpected failure adding view: %v", err)
+	}
+	if ok, _ := store.AddView(decoded); ok {
+		t.Errorf("Expected duplicate view to fail")
+	}
+
+	// a later refresh elsewhere updates the view in place, an earlier one is ignored
+	decoded.SetRefreshed(value.NewValue(int64(1623456789012345999)), 50, refreshed.Add(time.Minute))
+	bytes, _ = encodeMaterializedView(decoded)
+	store.applyEntry(view.Name, bytes)
+	decoded.SetRefreshed(value.NewValue(int64(1)), 1, refreshed)
+	stale, _ := encodeMaterializedView(decoded)
+	store.applyEntry(view.Name, stale)
+	if current, _ := store.View(view.Name); current != view || view.SourceCount() != 50 {
+		t.Errorf("Unexpected view after refresh %+v", current)
+	}
+
+	// a changed definition replaces the view
+	decoded.QueryRewrite = true
+	bytes, _ = encodeMaterializedView(decoded)
+	store.applyEntry(view.Name, bytes)
+	if current, _ := store.View(view.Name); current == view || !current.QueryRewrite {
+		t.Errorf("Expected view to be replaced")
+	}
+
+	store.applyEntry(view.Name, nil)
+	if dropped, err := store.DropView(view.Name); dropped != nil || err != nil {
+		t.Errorf("Unexpected drop of missing view: %v, %v", dropped, err)
+	}
+}
+
+func TestMaterializedViewObserver(t *testing.T) {
+	var events []string
+	SetMaterializedViewObserver(func(view *MaterializedView, dropped bool) {
+		if dropped {
+			events = append(events, "drop "+view.Text)
+		} else {
+			events = append(events, "add "+view.Text)
+		}
+	})
+	defer SetMaterializedViewObserver(func(view *MaterializedView, dropped bool) {})
+
+	view := &MaterializedView{Name: "default:orders.s.v", Target: "default:orders.s.v", Text: "q1", Interval: time.Hour}
+	store := newMaterializedViewStore()
+	store.AddView(view)
+
+	// refreshes elsewhere are no news, new definitions and drops are
+	refreshed := &MaterializedView{Name: view.Name, Target: view.Target, Text: "q1", Interval: time.Hour}
+	refreshed.SetRefreshed(nil, 1, time.Now())
+	bytes, _ := encodeMaterializedView(refreshed)
+	store.applyEntry(view.Name, bytes)
+	refreshed.Text = "q2"
+	bytes, _ = encodeMaterializedView(refreshed)
+	store.applyEntry(view.Name, bytes)
+	store.applyEntry(view.Name, nil)
+	store.applyEntry(view.Name, nil)
+	store.AddView(view)
+	store.DropView(view.Name)
+
+	expected := "add q1, drop q1, add q2, drop q2, add q1, drop q1"
+	if got := strings.Join(events, ", "); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -148,6 +148,22 @@ func (s *store) PutUserInfo(u *datastore.User) errors.Error {
 	return errors.NewOtherNotImplementedError(nil, "PutUserInfo")
 }
 
+func (s *store) DeleteUserInfo(u *datastore.User) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "DeleteUserInfo")
+}
+
+func (s *store) GetGroupInfoAll() ([]datastore.Group, errors.Error) {
+	return nil, errors.NewOtherNotImplementedError(nil, "GetGroupInfoAll")
+}
+
+func (s *store) PutGroupInfo(g *datastore.Group) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "PutGroupInfo")
+}
+
+func (s *store) DeleteGroupInfo(g *datastore.Group) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "DeleteGroupInfo")
+}
+
 func (s *store) GetRolesAll() ([]datastore.Role, errors.Error) {
 	return nil, errors.NewOtherNotImplementedError(nil, "GetRolesAll")
 }
@@ -0,0 +1,126 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/metadata"
+	"github.com/couchbase/query/value"
+)
+
+/*
+SchemaStore holds the JSON schemas that INSERT, UPSERT, UPDATE and
+MERGE ... VALIDATE check new documents against, keyed by the
+qualified keyspace name, e.g. default:bucket.scope.collection. The
+schemas are managed through system:schemas.
+
+DropSchema returns false if the keyspace has no schema. Errors are
+failures to store the change.
+*/
+type SchemaStore interface {
+	Schema(keyspace string) (value.Value, bool)
+	SetSchema(keyspace string, schema value.Value) error
+	DropSchema(keyspace string) (bool, error)
+	Keyspaces() []string
+}
+
+// the server validates against schemas kept in metakv, so that every node agrees
+var _SCHEMASTORE SchemaStore = newSchemaStore()
+
+func SetSchemaStore(schemaStore SchemaStore) {
+	_SCHEMASTORE = schemaStore
+}
+
+func GetSchemaStore() SchemaStore {
+	return _SCHEMASTORE
+}
+
+const _SCHEMAS_PATH = "/query/schemas/"
+
+type schemaStore struct {
+	sync.RWMutex
+	schemas map[string]value.Value
+	mirror  *metadata.Mirror
+}
+
+func newSchemaStore() *schemaStore {
+	return &schemaStore{schemas: make(map[string]value.Value)}
+}
+
+func NewMetakvSchemaStore() SchemaStore {
+	rv := newSchemaStore()
+	rv.mirror = metadata.NewMirror(_SCHEMAS_PATH, rv.applyEntry)
+	rv.mirror.Start()
+	return rv
+}
+
+func (this *schemaStore) Schema(keyspace string) (value.Value, bool) {
+	this.RLock()
+	defer this.RUnlock()
+	schema, ok := this.schemas[keyspace]
+	return schema, ok
+}
+
+func (this *schemaStore) SetSchema(keyspace string, schema value.Value) error {
+	var bytes []byte
+	if this.mirror != nil {
+		var err error
+		bytes, err = schema.MarshalJSON()
+		if err != nil {
+			return err
+		}
+	}
+	this.Lock()
+	this.schemas[keyspace] = schema
+	this.Unlock()
+	if this.mirror != nil {
+		return this.mirror.Set(keyspace, bytes)
+	}
+	return nil
+}
+
+func (this *schemaStore) DropSchema(keyspace string) (bool, error) {
+	this.Lock()
+	_, ok := this.schemas[keyspace]
+	delete(this.schemas, keyspace)
+	this.Unlock()
+	if ok && this.mirror != nil {
+		return true, this.mirror.Delete(keyspace)
+	}
+	return ok, nil
+}
+
+func (this *schemaStore) applyEntry(keyspace string, bytes []byte) {
+	this.Lock()
+	defer this.Unlock()
+	if bytes == nil {
+		delete(this.schemas, keyspace)
+		return
+	}
+	schema := value.NewValue(bytes)
+	if schema.Type() == value.BINARY {
+		logging.Errorf("Ignoring schema for %v: not JSON", keyspace)
+		return
+	}
+	this.schemas[keyspace] = schema
+}
+
+func (this *schemaStore) Keyspaces() []string {
+	this.RLock()
+	defer this.RUnlock()
+	rv := make([]string, 0, len(this.schemas))
+	for keyspace, _ := range this.schemas {
+		rv = append(rv, keyspace)
+	}
+	sort.Strings(rv)
+	return rv
+}
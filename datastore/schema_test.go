@@ -0,0 +1,42 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/value"
+)
+
+func TestSchemaEntries(t *testing.T) {
+	store := newSchemaStore()
+	if err := store.SetSchema("default:users", value.NewValue(map[string]interface{}{"type": "object"})); err != nil {
+		t.Fatalf("Unexpected failure setting schema: %v", err)
+	}
+
+	// schemas set elsewhere, or our own coming back
+	store.applyEntry("default:orders", []byte(`{"required": ["id"]}`))
+	store.applyEntry("default:users", []byte(`{"type": "array"}`))
+	store.applyEntry("default:broken", []byte(`{"type": `))
+	if keyspaces := store.Keyspaces(); len(keyspaces) != 2 || keyspaces[0] != "default:orders" {
+		t.Errorf("Unexpected keyspaces %v", keyspaces)
+	}
+	schema, _ := store.Schema("default:users")
+	if kind, _ := schema.Field("type"); kind.Actual() != "array" {
+		t.Errorf("Unexpected schema %v", schema)
+	}
+
+	store.applyEntry("default:orders", nil)
+	if ok, err := store.DropSchema("default:orders"); ok || err != nil {
+		t.Errorf("Unexpected drop of missing schema: %v, %v", ok, err)
+	}
+	if ok, _ := store.DropSchema("default:users"); !ok {
+		t.Errorf("Expected to drop schema for default:users")
+	}
+}
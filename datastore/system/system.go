@@ -48,6 +48,11 @@ const KEYSPACE_NAME_NODES = "nodes"
 const KEYSPACE_NAME_APPLICABLE_ROLES = "applicable_roles"
 const KEYSPACE_NAME_TASKS_CACHE = "tasks_cache"
 const KEYSPACE_NAME_TRANSACTIONS = "transactions"
+const KEYSPACE_NAME_SCHEMAS = "schemas"
+const KEYSPACE_NAME_RESOURCE_GROUPS = "resource_groups"
+const KEYSPACE_NAME_MATERIALIZED_VIEWS = "materialized_views"
+const KEYSPACE_NAME_TRIGGERS = "triggers"
+const KEYSPACE_NAME_PLAN_BASELINES = "plan_baselines"
 
 // TODO, sync with fetch timeout
 const scanTimeout = 30 * time.Second
@@ -63,12 +68,26 @@ func (s *store) PrivilegesFromPath(fullname string, keyspace string, privilege a
 		switch keyspace {
 
 		// currently these keyspaces require system read for delete
-		case KEYSPACE_NAME_ACTIVE, KEYSPACE_NAME_REQUESTS, KEYSPACE_NAME_PREPAREDS, KEYSPACE_NAME_FUNCTIONS_CACHE, KEYSPACE_NAME_DICTIONARY_CACHE:
+		case KEYSPACE_NAME_ACTIVE, KEYSPACE_NAME_REQUESTS, KEYSPACE_NAME_PREPAREDS, KEYSPACE_NAME_FUNCTIONS_CACHE, KEYSPACE_NAME_DICTIONARY_CACHE,
+			KEYSPACE_NAME_SCHEMAS:
 			privs.Add("", auth.PRIV_SYSTEM_READ, auth.PRIV_PROPS_NONE)
 
+		// resource groups and plan baselines apply to the whole cluster
+		case KEYSPACE_NAME_RESOURCE_GROUPS, KEYSPACE_NAME_PLAN_BASELINES:
+			privs.Add("", auth.PRIV_CLUSTER_ADMIN, auth.PRIV_PROPS_NONE)
+
 			// for all other keyspaces, we rely on the implementation do deny access
 		}
 
+	// schemas, resource groups and plan baselines are the only keyspaces that can be written to
+	case auth.PRIV_QUERY_INSERT, auth.PRIV_QUERY_UPDATE:
+		switch keyspace {
+		case KEYSPACE_NAME_SCHEMAS:
+			privs.Add("", auth.PRIV_SYSTEM_READ, auth.PRIV_PROPS_NONE)
+		case KEYSPACE_NAME_RESOURCE_GROUPS, KEYSPACE_NAME_PLAN_BASELINES:
+			privs.Add("", auth.PRIV_CLUSTER_ADMIN, auth.PRIV_PROPS_NONE)
+		}
+
 	// for SELECT previous code specified a target, even though it's not needed
 	// we still specify a target for backward compatibility and to avoid test failures
 	case auth.PRIV_QUERY_SELECT:
@@ -210,6 +229,22 @@ func (s *store) PutUserInfo(u *datastore.User) errors.Error {
 	return errors.NewOtherNotImplementedError(nil, "PutUserInfo")
 }
 
+func (s *store) DeleteUserInfo(u *datastore.User) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "DeleteUserInfo")
+}
+
+func (s *store) GetGroupInfoAll() ([]datastore.Group, errors.Error) {
+	return nil, errors.NewOtherNotImplementedError(nil, "GetGroupInfoAll")
+}
+
+func (s *store) PutGroupInfo(g *datastore.Group) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "PutGroupInfo")
+}
+
+func (s *store) DeleteGroupInfo(g *datastore.Group) errors.Error {
+	return errors.NewOtherNotImplementedError(nil, "DeleteGroupInfo")
+}
+
 func (s *store) GetRolesAll() ([]datastore.Role, errors.Error) {
 	return nil, errors.NewOtherNotImplementedError(nil, "GetRolesAll")
 }
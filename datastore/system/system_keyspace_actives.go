@@ -123,8 +123,8 @@ func (b *activeRequestsKeyspace) Fetch(keys []string, keysMap map[string]value.A
 				if cId != "" {
 					item.SetField("clientContextID", cId)
 				}
-				if request.Statement() != "" {
-					item.SetField("statement", request.Statement())
+				if request.RedactedStatement() != "" {
+					item.SetField("statement", request.RedactedStatement())
 				}
 				if request.Type() != "" {
 					item.SetField("statementType", request.Type())
@@ -0,0 +1,174 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package system
+
+import (
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/value"
+)
+
+/*
+system:materialized_views lists the materialized view definitions and
+their refresh state. Views are created and dropped with CREATE and DROP
+MATERIALIZED VIEW, so the keyspace is read only.
+*/
+type materializedViewsKeyspace struct {
+	keyspaceBase
+	indexer datastore.Indexer
+}
+
+func (b *materializedViewsKeyspace) Release(close bool) {
+}
+
+func (b *materializedViewsKeyspace) NamespaceId() string {
+	return b.namespace.Id()
+}
+
+func (b *materializedViewsKeyspace) Id() string {
+	return b.Name()
+}
+
+func (b *materializedViewsKeyspace) Name() string {
+	return b.name
+}
+
+func (b *materializedViewsKeyspace) Count(context datastore.QueryContext) (int64, errors.Error) {
+	return int64(len(datastore.GetMaterializedViewStore().Names())), nil
+}
+
+func (b *materializedViewsKeyspace) Size(context datastore.QueryContext) (int64, errors.Error) {
+	return -1, nil
+}
+
+func (b *materializedViewsKeyspace) Indexer(name datastore.IndexType) (datastore.Indexer, errors.Error) {
+	return b.indexer, nil
+}
+
+func (b *materializedViewsKeyspace) Indexers() ([]datastore.Indexer, errors.Error) {
+	return []datastore.Indexer{b.indexer}, nil
+}
+
+func (b *materializedViewsKeyspace) Fetch(keys []string, keysMap map[string]value.AnnotatedValue,
+	context datastore.QueryContext, subPaths []string) (errs errors.Errors) {
+
+	for _, key := range keys {
+		view, ok := datastore.GetMaterializedViewStore().View(key)
+		if !ok {
+			continue
+		}
+
+		doc := map[string]interface{}{
+			"name":          view.Name,
+			"definition":    view.Text,
+			"incremental":   view.Incremental(),
+			"query_rewrite": view.QueryRewrite,
+		}
+		if view.Incremental() {
+			doc["source"] = view.Source
+		}
+		if view.Interval > 0 {
+			doc["refresh_interval"] = view.Interval.String()
+		}
+		watermark, lastRefresh := view.Refreshed()
+		if !lastRefresh.IsZero() {
+			doc["last_refresh"] = lastRefresh.Format(expression.DEFAULT_FORMAT)
+		}
+		if watermark != nil {
+			doc["watermark"] = watermark
+		}
+
+		item := value.NewAnnotatedValue(doc)
+		item.NewMeta()["keyspace"] = b.fullName
+		item.SetId(key)
+		keysMap[key] = item
+	}
+	return
+}
+
+func newMaterializedViewsKeyspace(p *namespace) (*materializedViewsKeyspace, errors.Error) {
+	b := new(materializedViewsKeyspace)
+	setKeyspaceBase(&b.keyspaceBase, p, KEYSPACE_NAME_MATERIALIZED_VIEWS)
+
+	primary := &materializedViewsIndex{name: "#primary", keyspace: b}
+	b.indexer = newSystemIndexer(b, primary)
+	setIndexBase(&primary.indexBase, b.indexer)
+
+	return b, nil
+}
+
+type materializedViewsIndex struct {
+	indexBase
+	name     string
+	keyspace *materializedViewsKeyspace
+}
+
+func (pi *materializedViewsIndex) KeyspaceId() string {
+	return pi.keyspace.Id()
+}
+
+func (pi *materializedViewsIndex) Id() string {
+	return pi.Name()
+}
+
+func (pi *materializedViewsIndex) Name() string {
+	return pi.name
+}
+
+func (pi *materializedViewsIndex) Type() datastore.IndexType {
+	return datastore.SYSTEM
+}
+
+func (pi *materializedViewsIndex) SeekKey() expression.Expressions {
+	return nil
+}
+
+func (pi *materializedViewsIndex) RangeKey() expression.Expressions {
+	return nil
+}
+
+func (pi *materializedViewsIndex) Condition() expression.Expression {
+	return nil
+}
+
+func (pi *materializedViewsIndex) IsPrimary() bool {
+	return true
+}
+
+func (pi *materializedViewsIndex) State() (state datastore.IndexState, msg string, err errors.Error) {
+	return datastore.ONLINE, "", nil
+}
+
+func (pi *materializedViewsIndex) Statistics(requestId string, span *datastore.Span) (
+	datastore.Statistics, errors.Error) {
+	return nil, nil
+}
+
+func (pi *materializedViewsIndex) Drop(requestId string) errors.Error {
+	return errors.NewSystemIdxNoDropError(nil, pi.Name())
+}
+
+func (pi *materializedViewsIndex) Scan(requestId string, span *datastore.Span, distinct bool, limit int64,
+	cons datastore.ScanConsistency, vector timestamp.Vector, conn *datastore.IndexConnection) {
+	pi.ScanEntries(requestId, limit, cons, vector, conn)
+}
+
+func (pi *materializedViewsIndex) ScanEntries(requestId string, limit int64, cons datastore.ScanConsistency,
+	vector timestamp.Vector, conn *datastore.IndexConnection) {
+	defer conn.Sender().Close()
+
+	for _, name := range datastore.GetMaterializedViewStore().Names() {
+		entry := datastore.IndexEntry{PrimaryKey: name}
+		if !sendSystemKey(conn, &entry) {
+			return
+		}
+	}
+}
@@ -0,0 +1,286 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package system
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/prepareds"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/value"
+)
+
+/*
+system:plan_baselines lists the plan baselines, keyed by name.
+
+Inserting a document captures a baseline for its statement, or for
+that of a prepared statement, and upserting an existing baseline
+evolves it. Updating the state accepts or rejects a baseline, and
+deleting it drops it.
+*/
+type planBaselinesKeyspace struct {
+	keyspaceBase
+	indexer datastore.Indexer
+}
+
+func (b *planBaselinesKeyspace) Release(close bool) {
+}
+
+func (b *planBaselinesKeyspace) NamespaceId() string {
+	return b.namespace.Id()
+}
+
+func (b *planBaselinesKeyspace) Id() string {
+	return b.Name()
+}
+
+func (b *planBaselinesKeyspace) Name() string {
+	return b.name
+}
+
+func (b *planBaselinesKeyspace) Count(context datastore.QueryContext) (int64, errors.Error) {
+	return int64(len(plan.GetBaselineStore().Names())), nil
+}
+
+func (b *planBaselinesKeyspace) Size(context datastore.QueryContext) (int64, errors.Error) {
+	return -1, nil
+}
+
+func (b *planBaselinesKeyspace) Indexer(name datastore.IndexType) (datastore.Indexer, errors.Error) {
+	return b.indexer, nil
+}
+
+func (b *planBaselinesKeyspace) Indexers() ([]datastore.Indexer, errors.Error) {
+	return []datastore.Indexer{b.indexer}, nil
+}
+
+func (b *planBaselinesKeyspace) Fetch(keys []string, keysMap map[string]value.AnnotatedValue,
+	context datastore.QueryContext, subPaths []string) (errs errors.Errors) {
+
+	for _, key := range keys {
+		baseline, ok := plan.GetBaselineStore().Baseline(key)
+		if !ok {
+			continue
+		}
+
+		doc := map[string]interface{}{
+			"name":      baseline.Name(),
+			"statement": baseline.Text(),
+			"namespace": baseline.Namespace(),
+			"state":     baseline.State(),
+			"captured":  baseline.Captured().Format(expression.DEFAULT_FORMAT),
+			"uses":      baseline.Uses(),
+		}
+		if baseline.QueryContext() != "" {
+			doc["query_context"] = baseline.QueryContext()
+		}
+		var op interface{}
+		if json.Unmarshal(baseline.Plan(), &op) == nil {
+			doc["plan"] = op
+		}
+
+		item := value.NewAnnotatedValue(doc)
+		item.NewMeta()["keyspace"] = b.fullName
+		item.SetId(key)
+		keysMap[key] = item
+	}
+	return
+}
+
+func (b *planBaselinesKeyspace) Insert(inserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	var errs errors.Errors
+
+	rv := make(value.Pairs, 0, len(inserts))
+	for _, pair := range inserts {
+		if _, ok := plan.GetBaselineStore().Baseline(pair.Name); ok {
+			errs = append(errs, errors.NewSystemDatastoreError(nil, "Duplicate key "+pair.Name))
+			continue
+		}
+		err := b.capture(pair, "", "", "", context)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rv = append(rv, pair)
+	}
+	return rv, errs
+}
+
+func (b *planBaselinesKeyspace) Upsert(upserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	var errs errors.Errors
+
+	rv := make(value.Pairs, 0, len(upserts))
+	for _, pair := range upserts {
+
+		// an existing baseline is evolved, unless the statement is given anew
+		var statement, queryContext, namespace string
+		if baseline, ok := plan.GetBaselineStore().Baseline(pair.Name); ok {
+			statement = baseline.Text()
+			queryContext = baseline.QueryContext()
+			namespace = baseline.Namespace()
+		}
+		err := b.capture(pair, statement, queryContext, namespace, context)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rv = append(rv, pair)
+	}
+	return rv, errs
+}
+
+func (b *planBaselinesKeyspace) capture(pair value.Pair, statement, queryContext, namespace string,
+	context datastore.QueryContext) errors.Error {
+	if pair.Value.Type() != value.OBJECT {
+		return errors.NewSystemDatastoreError(nil, "Baseline must be an object "+pair.Name)
+	}
+
+	var prepared string
+	for field, v := range pair.Value.Fields() {
+		val := value.NewValue(v)
+		if val.Type() != value.STRING {
+			if field == "statement" || field == "prepared" || field == "query_context" || field == "namespace" {
+				return errors.NewSystemDatastoreError(nil, field+" must be a string "+pair.Name)
+			}
+			continue
+		}
+		switch field {
+		case "statement":
+			statement = val.Actual().(string)
+		case "prepared":
+			prepared = val.Actual().(string)
+		case "query_context":
+			queryContext = val.Actual().(string)
+		case "namespace":
+			namespace = val.Actual().(string)
+		}
+	}
+	if statement == "" && prepared == "" {
+		return errors.NewSystemDatastoreError(nil, "Baseline needs a statement or a prepared name "+pair.Name)
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	_, err := prepareds.CaptureBaseline(pair.Name, statement, prepared, queryContext, namespace, context.Credentials())
+	return err
+}
+
+func (b *planBaselinesKeyspace) Update(updates value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	var errs errors.Errors
+
+	rv := make(value.Pairs, 0, len(updates))
+	for _, pair := range updates {
+		state, ok := pair.Value.Field("state")
+		if !ok || state.Type() != value.STRING {
+			errs = append(errs, errors.NewSystemDatastoreError(nil, "Baseline state must be a string "+pair.Name))
+			continue
+		}
+		err := prepareds.SetBaselineState(pair.Name, state.Actual().(string))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rv = append(rv, pair)
+	}
+	return rv, errs
+}
+
+func (b *planBaselinesKeyspace) Delete(deletes value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	rv := make(value.Pairs, 0, len(deletes))
+	for _, pair := range deletes {
+		if prepareds.DropBaseline(pair.Name) == nil {
+			rv = append(rv, pair)
+		}
+	}
+	return rv, nil
+}
+
+func newPlanBaselinesKeyspace(p *namespace) (*planBaselinesKeyspace, errors.Error) {
+	b := new(planBaselinesKeyspace)
+	setKeyspaceBase(&b.keyspaceBase, p, KEYSPACE_NAME_PLAN_BASELINES)
+
+	primary := &planBaselinesIndex{name: "#primary", keyspace: b}
+	b.indexer = newSystemIndexer(b, primary)
+	setIndexBase(&primary.indexBase, b.indexer)
+
+	return b, nil
+}
+
+type planBaselinesIndex struct {
+	indexBase
+	name     string
+	keyspace *planBaselinesKeyspace
+}
+
+func (pi *planBaselinesIndex) KeyspaceId() string {
+	return pi.keyspace.Id()
+}
+
+func (pi *planBaselinesIndex) Id() string {
+	return pi.Name()
+}
+
+func (pi *planBaselinesIndex) Name() string {
+	return pi.name
+}
+
+func (pi *planBaselinesIndex) Type() datastore.IndexType {
+	return datastore.SYSTEM
+}
+
+func (pi *planBaselinesIndex) SeekKey() expression.Expressions {
+	return nil
+}
+
+func (pi *planBaselinesIndex) RangeKey() expression.Expressions {
+	return nil
+}
+
+func (pi *planBaselinesIndex) Condition() expression.Expression {
+	return nil
+}
+
+func (pi *planBaselinesIndex) IsPrimary() bool {
+	return true
+}
+
+func (pi *planBaselinesIndex) State() (state datastore.IndexState, msg string, err errors.Error) {
+	return datastore.ONLINE, "", nil
+}
+
+func (pi *planBaselinesIndex) Statistics(requestId string, span *datastore.Span) (
+	datastore.Statistics, errors.Error) {
+	return nil, nil
+}
+
+func (pi *planBaselinesIndex) Drop(requestId string) errors.Error {
+	return errors.NewSystemIdxNoDropError(nil, pi.Name())
+}
+
+func (pi *planBaselinesIndex) Scan(requestId string, span *datastore.Span, distinct bool, limit int64,
+	cons datastore.ScanConsistency, vector timestamp.Vector, conn *datastore.IndexConnection) {
+	pi.ScanEntries(requestId, limit, cons, vector, conn)
+}
+
+func (pi *planBaselinesIndex) ScanEntries(requestId string, limit int64, cons datastore.ScanConsistency,
+	vector timestamp.Vector, conn *datastore.IndexConnection) {
+	defer conn.Sender().Close()
+
+	for _, name := range plan.GetBaselineStore().Names() {
+		entry := datastore.IndexEntry{PrimaryKey: name}
+		if !sendSystemKey(conn, &entry) {
+			return
+		}
+	}
+}
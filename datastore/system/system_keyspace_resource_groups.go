@@ -0,0 +1,207 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package system
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/server"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/value"
+)
+
+/*
+system:resource_groups lists the workload management resource groups of
+this node, keyed by group name, along with the requests currently running
+and queued in each. Groups can be created, changed and dropped through DML
+as well as through the resource-groups admin setting; the statistics fields
+are ignored on write.
+*/
+type resourceGroupsKeyspace struct {
+	keyspaceBase
+	indexer datastore.Indexer
+}
+
+func (b *resourceGroupsKeyspace) Release(close bool) {
+}
+
+func (b *resourceGroupsKeyspace) NamespaceId() string {
+	return b.namespace.Id()
+}
+
+func (b *resourceGroupsKeyspace) Id() string {
+	return b.Name()
+}
+
+func (b *resourceGroupsKeyspace) Name() string {
+	return b.name
+}
+
+func (b *resourceGroupsKeyspace) Count(context datastore.QueryContext) (int64, errors.Error) {
+	return int64(server.ResourceGroupsCount()), nil
+}
+
+func (b *resourceGroupsKeyspace) Size(context datastore.QueryContext) (int64, errors.Error) {
+	return -1, nil
+}
+
+func (b *resourceGroupsKeyspace) Indexer(name datastore.IndexType) (datastore.Indexer, errors.Error) {
+	return b.indexer, nil
+}
+
+func (b *resourceGroupsKeyspace) Indexers() ([]datastore.Indexer, errors.Error) {
+	return []datastore.Indexer{b.indexer}, nil
+}
+
+func (b *resourceGroupsKeyspace) Fetch(keys []string, keysMap map[string]value.AnnotatedValue,
+	context datastore.QueryContext, subPaths []string) (errs errors.Errors) {
+
+	for _, key := range keys {
+		group, ok := server.ResourceGroupGet(key)
+		if !ok {
+			continue
+		}
+
+		item := value.NewAnnotatedValue(group)
+		item.NewMeta()["keyspace"] = b.fullName
+		item.SetId(key)
+		keysMap[key] = item
+	}
+	return
+}
+
+func (b *resourceGroupsKeyspace) Insert(inserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(inserts, func(exists bool) bool { return !exists }, "Duplicate key ")
+}
+
+func (b *resourceGroupsKeyspace) Update(updates value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(updates, func(exists bool) bool { return exists }, "Key not found ")
+}
+
+func (b *resourceGroupsKeyspace) Upsert(upserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(upserts, func(exists bool) bool { return true }, "")
+}
+
+func (b *resourceGroupsKeyspace) store(pairs value.Pairs, allowed func(bool) bool, msg string) (value.Pairs, errors.Errors) {
+	var errs errors.Errors
+
+	rv := make(value.Pairs, 0, len(pairs))
+	for _, pair := range pairs {
+		_, exists := server.ResourceGroupGet(pair.Name)
+		if !allowed(exists) {
+			errs = append(errs, errors.NewSystemDatastoreError(nil, msg+pair.Name))
+			continue
+		}
+
+		// documents may hold values built by the statement, the setting
+		// takes the same plain JSON as the admin endpoint
+		var def interface{}
+		bytes, _ := pair.Value.MarshalJSON()
+		json.Unmarshal(bytes, &def)
+		err := server.ResourceGroupSet(pair.Name, def)
+		if err != nil {
+			errs = append(errs, errors.NewSystemDatastoreError(err, pair.Name))
+			continue
+		}
+		rv = append(rv, pair)
+	}
+	return rv, errs
+}
+
+func (b *resourceGroupsKeyspace) Delete(deletes value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	rv := make(value.Pairs, 0, len(deletes))
+	for _, pair := range deletes {
+		if server.ResourceGroupDelete(pair.Name) {
+			rv = append(rv, pair)
+		}
+	}
+	return rv, nil
+}
+
+func newResourceGroupsKeyspace(p *namespace) (*resourceGroupsKeyspace, errors.Error) {
+	b := new(resourceGroupsKeyspace)
+	setKeyspaceBase(&b.keyspaceBase, p, KEYSPACE_NAME_RESOURCE_GROUPS)
+
+	primary := &resourceGroupsIndex{name: "#primary", keyspace: b}
+	b.indexer = newSystemIndexer(b, primary)
+	setIndexBase(&primary.indexBase, b.indexer)
+
+	return b, nil
+}
+
+type resourceGroupsIndex struct {
+	indexBase
+	name     string
+	keyspace *resourceGroupsKeyspace
+}
+
+func (pi *resourceGroupsIndex) KeyspaceId() string {
+	return pi.keyspace.Id()
+}
+
+func (pi *resourceGroupsIndex) Id() string {
+	return pi.Name()
+}
+
+func (pi *resourceGroupsIndex) Name() string {
+	return pi.name
+}
+
+func (pi *resourceGroupsIndex) Type() datastore.IndexType {
+	return datastore.SYSTEM
+}
+
+func (pi *resourceGroupsIndex) SeekKey() expression.Expressions {
+	return nil
+}
+
+func (pi *resourceGroupsIndex) RangeKey() expression.Expressions {
+	return nil
+}
+
+func (pi *resourceGroupsIndex) Condition() expression.Expression {
+	return nil
+}
+
+func (pi *resourceGroupsIndex) IsPrimary() bool {
+	return true
+}
+
+func (pi *resourceGroupsIndex) State() (state datastore.IndexState, msg string, err errors.Error) {
+	return datastore.ONLINE, "", nil
+}
+
+func (pi *resourceGroupsIndex) Statistics(requestId string, span *datastore.Span) (
+	datastore.Statistics, errors.Error) {
+	return nil, nil
+}
+
+func (pi *resourceGroupsIndex) Drop(requestId string) errors.Error {
+	return errors.NewSystemIdxNoDropError(nil, pi.Name())
+}
+
+func (pi *resourceGroupsIndex) Scan(requestId string, span *datastore.Span, distinct bool, limit int64,
+	cons datastore.ScanConsistency, vector timestamp.Vector, conn *datastore.IndexConnection) {
+	pi.ScanEntries(requestId, limit, cons, vector, conn)
+}
+
+func (pi *resourceGroupsIndex) ScanEntries(requestId string, limit int64, cons datastore.ScanConsistency,
+	vector timestamp.Vector, conn *datastore.IndexConnection) {
+	defer conn.Sender().Close()
+
+	for _, name := range server.ResourceGroupsNames() {
+		entry := datastore.IndexEntry{PrimaryKey: name}
+		if !sendSystemKey(conn, &entry) {
+			return
+		}
+	}
+}
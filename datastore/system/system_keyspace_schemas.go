@@ -0,0 +1,210 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package system
+
+import (
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/value"
+)
+
+/*
+system:schemas holds the JSON schemas used by DML statements with the
+VALIDATE option. The document key is the full path of the keyspace,
+e.g. default:bucket.scope.collection, and the document is the schema.
+*/
+type schemasKeyspace struct {
+	keyspaceBase
+	indexer datastore.Indexer
+}
+
+func (b *schemasKeyspace) Release(close bool) {
+}
+
+func (b *schemasKeyspace) NamespaceId() string {
+	return b.namespace.Id()
+}
+
+func (b *schemasKeyspace) Id() string {
+	return b.Name()
+}
+
+func (b *schemasKeyspace) Name() string {
+	return b.name
+}
+
+func (b *schemasKeyspace) Count(context datastore.QueryContext) (int64, errors.Error) {
+	return int64(len(datastore.GetSchemaStore().Keyspaces())), nil
+}
+
+func (b *schemasKeyspace) Size(context datastore.QueryContext) (int64, errors.Error) {
+	return -1, nil
+}
+
+func (b *schemasKeyspace) Indexer(name datastore.IndexType) (datastore.Indexer, errors.Error) {
+	return b.indexer, nil
+}
+
+func (b *schemasKeyspace) Indexers() ([]datastore.Indexer, errors.Error) {
+	return []datastore.Indexer{b.indexer}, nil
+}
+
+func (b *schemasKeyspace) Fetch(keys []string, keysMap map[string]value.AnnotatedValue,
+	context datastore.QueryContext, subPaths []string) (errs errors.Errors) {
+
+	for _, key := range keys {
+		schema, ok := datastore.GetSchemaStore().Schema(key)
+		if !ok {
+			continue
+		}
+
+		item := value.NewAnnotatedValue(schema.Copy())
+		item.NewMeta()["keyspace"] = b.fullName
+		item.SetId(key)
+		keysMap[key] = item
+	}
+	return
+}
+
+func (b *schemasKeyspace) Insert(inserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(inserts, func(exists bool) bool { return !exists }, "Duplicate key ")
+}
+
+func (b *schemasKeyspace) Update(updates value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(updates, func(exists bool) bool { return exists }, "Key not found ")
+}
+
+func (b *schemasKeyspace) Upsert(upserts value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	return b.store(upserts, func(exists bool) bool { return true }, "")
+}
+
+func (b *schemasKeyspace) store(pairs value.Pairs, allowed func(bool) bool, msg string) (value.Pairs, errors.Errors) {
+	var errs errors.Errors
+
+	store := datastore.GetSchemaStore()
+	rv := make(value.Pairs, 0, len(pairs))
+	for _, pair := range pairs {
+		_, exists := store.Schema(pair.Name)
+		if !allowed(exists) {
+			errs = append(errs, errors.NewSystemDatastoreError(nil, msg+pair.Name))
+			continue
+		}
+
+		// only store schemas that compile
+		_, err := expression.NewJSONSchema(pair.Value)
+		if err != nil {
+			errs = append(errs, errors.NewSystemDatastoreError(err, pair.Name))
+			continue
+		}
+
+		err = store.SetSchema(pair.Name, value.NewValue(pair.Value.Actual()).Copy())
+		if err != nil {
+			errs = append(errs, errors.NewSystemDatastoreError(err, pair.Name))
+			continue
+		}
+		rv = append(rv, pair)
+	}
+	return rv, errs
+}
+
+func (b *schemasKeyspace) Delete(deletes value.Pairs, context datastore.QueryContext) (value.Pairs, errors.Errors) {
+	store := datastore.GetSchemaStore()
+	var errs errors.Errors
+	rv := make(value.Pairs, 0, len(deletes))
+	for _, pair := range deletes {
+		ok, err := store.DropSchema(pair.Name)
+		if err != nil {
+			errs = append(errs, errors.NewSystemDatastoreError(err, pair.Name))
+		} else if ok {
+			rv = append(rv, pair)
+		}
+	}
+	return rv, errs
+}
+
+func newSchemasKeyspace(p *namespace) (*schemasKeyspace, errors.Error) {
+	b := new(schemasKeyspace)
+	setKeyspaceBase(&b.keyspaceBase, p, KEYSPACE_NAME_SCHEMAS)
+
+	primary := &schemasIndex{name: "#primary", keyspace: b}
+	b.indexer = newSystemIndexer(b, primary)
+	setIndexBase(&primary.indexBase, b.indexer)
+
+	return b, nil
+}
+
+type schemasIndex struct {
+	indexBase
+	name     string
+	keyspace *schemasKeyspace
+}
+
+func (pi *schemasIndex) KeyspaceId() string {
+	return pi.keyspace.Id()
+}
+
+func (pi *schemasIndex) Id() string {
+	return pi.Name()
+}
+
+func (pi *schemasIndex) Name() string {
+	return pi.name
+}
+
+func (pi *schemasIndex) Type() datastore.IndexType {
+	return datastore.SYSTEM
+}
+
+func (pi *schemasIndex) SeekKey() expression.Expressions {
+	return nil
+}
+
+func (pi *schemasIndex) RangeKey() expression.Expressions {
+	return nil
+}
+
+func (pi *schemasIndex) Condition() expression.Expression {
+	return nil
+}
+
+func (pi *schemasIndex) IsPrimary() bool {
+	return true
+}
+
+func (pi *schemasIndex) State() (state datastore.IndexState, msg string, err errors.Error) {
+	return datastore.ONLINE, "", nil
+}
+
+func (pi *schemasIndex) Statistics(requestId string, span *datastore.Span) (
+	datastore.Statistics, errors.Error) {
+	return nil, nil
+}
+
+func (pi *schemasIndex) Drop(requestId string) errors.Error {
+	return errors.NewSystemIdxNoDropError(nil, pi.Name())
+}
+
+func (pi *schemasIndex) Scan(requestId string, span *datastore.Span, distinct bool, limit int64,
+	cons datastore.ScanConsistency, vector timestamp.Vector, conn *datastore.IndexConnection) {
+	pi.ScanEntries(requestId, limit, cons, vector, conn)
+}
+
+func (pi *schemasIndex) ScanEntries(requestId string, limit int64, cons datastore.ScanConsistency,
+	vector timestamp.Vector, conn *datastore.IndexConnection) {
+	defer conn.Sender().Close()
+
+	for _, keyspace := range datastore.GetSchemaStore().Keyspaces() {
+		entry := datastore.IndexEntry{PrimaryKey: keyspace}
+		if !sendSystemKey(conn, &entry) {
+			return
+		}
+	}
+}
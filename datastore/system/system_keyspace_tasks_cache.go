@@ -100,6 +100,9 @@ func (b *tasksCacheKeyspace) Fetch(keys []string, keysMap map[string]value.Annot
 					"submitTime": entry.PostTime.String(),
 					"delay":      entry.Delay.String(),
 				}
+				if entry.Interval > 0 {
+					itemMap["interval"] = entry.Interval.String()
+				}
 				if entry.Results != nil {
 					itemMap["results"] = entry.Results
 				}
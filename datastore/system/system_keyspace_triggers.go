@@ -0,0 +1,182 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package system
+
+import (
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/value"
+)
+
+/*
+system:triggers lists the triggers of every keyspace, keyed by the
+keyspace and trigger names. Triggers are created and dropped with
+CREATE and DROP TRIGGER, so the keyspace is read only.
+*/
+type triggersKeyspace struct {
+	keyspaceBase
+	indexer datastore.Indexer
+}
+
+func (b *triggersKeyspace) Release(close bool) {
+}
+
+func (b *triggersKeyspace) NamespaceId() string {
+	return b.namespace.Id()
+}
+
+func (b *triggersKeyspace) Id() string {
+	return b.Name()
+}
+
+func (b *triggersKeyspace) Name() string {
+	return b.name
+}
+
+func (b *triggersKeyspace) Count(context datastore.QueryContext) (int64, errors.Error) {
+	return int64(len(triggers())), nil
+}
+
+func (b *triggersKeyspace) Size(context datastore.QueryContext) (int64, errors.Error) {
+	return -1, nil
+}
+
+func (b *triggersKeyspace) Indexer(name datastore.IndexType) (datastore.Indexer, errors.Error) {
+	return b.indexer, nil
+}
+
+func (b *triggersKeyspace) Indexers() ([]datastore.Indexer, errors.Error) {
+	return []datastore.Indexer{b.indexer}, nil
+}
+
+func (b *triggersKeyspace) Fetch(keys []string, keysMap map[string]value.AnnotatedValue,
+	context datastore.QueryContext, subPaths []string) (errs errors.Errors) {
+
+	var all map[string]*datastore.Trigger
+	for _, key := range keys {
+		if all == nil {
+			all = triggers()
+		}
+		trigger, ok := all[key]
+		if !ok {
+			continue
+		}
+
+		args := make([]interface{}, len(trigger.Args))
+		for i, arg := range trigger.Args {
+			args[i] = arg.String()
+		}
+		doc := map[string]interface{}{
+			"name":     trigger.Name,
+			"keyspace": trigger.Keyspace,
+			"timing":   trigger.Timing,
+			"event":    trigger.Event,
+			"function": trigger.Function.Key(),
+			"args":     args,
+		}
+
+		item := value.NewAnnotatedValue(doc)
+		item.NewMeta()["keyspace"] = b.fullName
+		item.SetId(key)
+		keysMap[key] = item
+	}
+	return
+}
+
+func newTriggersKeyspace(p *namespace) (*triggersKeyspace, errors.Error) {
+	b := new(triggersKeyspace)
+	setKeyspaceBase(&b.keyspaceBase, p, KEYSPACE_NAME_TRIGGERS)
+
+	primary := &triggersIndex{name: "#primary", keyspace: b}
+	b.indexer = newSystemIndexer(b, primary)
+	setIndexBase(&primary.indexBase, b.indexer)
+
+	return b, nil
+}
+
+type triggersIndex struct {
+	indexBase
+	name     string
+	keyspace *triggersKeyspace
+}
+
+func (pi *triggersIndex) KeyspaceId() string {
+	return pi.keyspace.Id()
+}
+
+func (pi *triggersIndex) Id() string {
+	return pi.Name()
+}
+
+func (pi *triggersIndex) Name() string {
+	return pi.name
+}
+
+func (pi *triggersIndex) Type() datastore.IndexType {
+	return datastore.SYSTEM
+}
+
+func (pi *triggersIndex) SeekKey() expression.Expressions {
+	return nil
+}
+
+func (pi *triggersIndex) RangeKey() expression.Expressions {
+	return nil
+}
+
+func (pi *triggersIndex) Condition() expression.Expression {
+	return nil
+}
+
+func (pi *triggersIndex) IsPrimary() bool {
+	return true
+}
+
+func (pi *triggersIndex) State() (state datastore.IndexState, msg string, err errors.Error) {
+	return datastore.ONLINE, "", nil
+}
+
+func (pi *triggersIndex) Statistics(requestId string, span *datastore.Span) (
+	datastore.Statistics, errors.Error) {
+	return nil, nil
+}
+
+func (pi *triggersIndex) Drop(requestId string) errors.Error {
+	return errors.NewSystemIdxNoDropError(nil, pi.Name())
+}
+
+func (pi *triggersIndex) Scan(requestId string, span *datastore.Span, distinct bool, limit int64,
+	cons datastore.ScanConsistency, vector timestamp.Vector, conn *datastore.IndexConnection) {
+	pi.ScanEntries(requestId, limit, cons, vector, conn)
+}
+
+func (pi *triggersIndex) ScanEntries(requestId string, limit int64, cons datastore.ScanConsistency,
+	vector timestamp.Vector, conn *datastore.IndexConnection) {
+	defer conn.Sender().Close()
+
+	for key, _ := range triggers() {
+		entry := datastore.IndexEntry{PrimaryKey: key}
+		if !sendSystemKey(conn, &entry) {
+			return
+		}
+	}
+}
+
+func triggers() map[string]*datastore.Trigger {
+	store := datastore.GetTriggerStore()
+	rv := make(map[string]*datastore.Trigger)
+	for _, keyspace := range store.Keyspaces() {
+		for _, trigger := range store.Triggers(keyspace) {
+			rv[keyspace+"."+trigger.Name] = trigger
+		}
+	}
+	return rv
+}
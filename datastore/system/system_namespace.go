@@ -185,6 +185,36 @@ func (p *namespace) loadKeyspaces() (e errors.Error) {
 	}
 	p.keyspaces[funcs.Name()] = funcs
 
+	schemas, e := newSchemasKeyspace(p)
+	if e != nil {
+		return e
+	}
+	p.keyspaces[schemas.Name()] = schemas
+
+	resourceGroups, e := newResourceGroupsKeyspace(p)
+	if e != nil {
+		return e
+	}
+	p.keyspaces[resourceGroups.Name()] = resourceGroups
+
+	materializedViews, e := newMaterializedViewsKeyspace(p)
+	if e != nil {
+		return e
+	}
+	p.keyspaces[materializedViews.Name()] = materializedViews
+
+	triggers, e := newTriggersKeyspace(p)
+	if e != nil {
+		return e
+	}
+	p.keyspaces[triggers.Name()] = triggers
+
+	planBaselines, e := newPlanBaselinesKeyspace(p)
+	if e != nil {
+		return e
+	}
+	p.keyspaces[planBaselines.Name()] = planBaselines
+
 	dictCache, e := newDictionaryCacheKeyspace(p, KEYSPACE_NAME_DICTIONARY_CACHE)
 	if e != nil {
 		return e
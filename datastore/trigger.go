@@ -0,0 +1,210 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/functions"
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/metadata"
+)
+
+const (
+	TRIGGER_BEFORE = "BEFORE"
+	TRIGGER_AFTER  = "AFTER"
+)
+
+const (
+	TRIGGER_INSERT = "INSERT"
+	TRIGGER_UPDATE = "UPDATE"
+	TRIGGER_DELETE = "DELETE"
+)
+
+/*
+A trigger executes a function for each document mutated by a statement
+on a keyspace, before or after the mutation. Keyspace is the qualified
+name of the keyspace, and Args are formalized against the NEW and OLD
+documents.
+*/
+type Trigger struct {
+	Name     string
+	Keyspace string
+	Timing   string
+	Event    string
+	Function functions.FunctionName
+	Args     expression.Expressions
+}
+
+/*
+TriggerStore holds the triggers defined on each keyspace, and is
+listed through system:triggers. Triggers are returned in name order,
+which is the order they are fired in.
+
+AddTrigger returns false if the keyspace already has a trigger of that
+name, and DropTrigger returns nil if it has none. Errors are failures
+to store the change.
+*/
+type TriggerStore interface {
+	Triggers(keyspace string) []*Trigger
+	AddTrigger(trigger *Trigger) (bool, error)
+	DropTrigger(keyspace, name string) (*Trigger, error)
+	Keyspaces() []string
+}
+
+/*
+Triggers fire on whichever node runs the statement, so the server
+replaces this store, which only lasts as long as the process, with
+one kept in metakv.
+*/
+var _TRIGGERSTORE TriggerStore = newTriggerStore()
+
+func SetTriggerStore(store TriggerStore) {
+	_TRIGGERSTORE = store
+}
+
+func GetTriggerStore() TriggerStore {
+	return _TRIGGERSTORE
+}
+
+const _TRIGGERS_PATH = "/query/triggers/"
+
+type triggerStore struct {
+	sync.RWMutex
+	triggers map[string][]*Trigger
+	mirror   *metadata.Mirror
+	encode   func(*Trigger) ([]byte, error)
+	decode   func([]byte) (*Trigger, error)
+}
+
+func newTriggerStore() *triggerStore {
+	return &triggerStore{triggers: make(map[string][]*Trigger)}
+}
+
+/*
+A store shared by the query nodes through metakv. Function names and
+arguments are encoded by the planner, as for CREATE TRIGGER plans.
+*/
+func NewMetakvTriggerStore(encode func(*Trigger) ([]byte, error),
+	decode func([]byte) (*Trigger, error)) TriggerStore {
+	rv := newTriggerStore()
+	rv.encode = encode
+	rv.decode = decode
+	rv.mirror = metadata.NewMirror(_TRIGGERS_PATH, rv.applyEntry)
+	rv.mirror.Start()
+	return rv
+}
+
+// keyspace names have no slashes
+func triggerKey(keyspace, name string) string {
+	return keyspace + "/" + name
+}
+
+// the slices are replaced, never changed, so callers can hold on to them
+func (this *triggerStore) Triggers(keyspace string) []*Trigger {
+	this.RLock()
+	defer this.RUnlock()
+	return this.triggers[keyspace]
+}
+
+func (this *triggerStore) AddTrigger(trigger *Trigger) (bool, error) {
+	var bytes []byte
+	if this.mirror != nil {
+		var err error
+		bytes, err = this.encode(trigger)
+		if err != nil {
+			return false, err
+		}
+	}
+	if !this.add(trigger, false) {
+		return false, nil
+	}
+	if this.mirror != nil {
+		return true, this.mirror.Set(triggerKey(trigger.Keyspace, trigger.Name), bytes)
+	}
+	return true, nil
+}
+
+func (this *triggerStore) add(trigger *Trigger, replace bool) bool {
+	this.Lock()
+	defer this.Unlock()
+	triggers := this.triggers[trigger.Keyspace]
+	rv := make([]*Trigger, 0, len(triggers)+1)
+	for _, t := range triggers {
+		if t.Name == trigger.Name {
+			if !replace {
+				return false
+			}
+			continue
+		}
+		rv = append(rv, t)
+	}
+	rv = append(rv, trigger)
+	sort.Slice(rv, func(i, j int) bool { return rv[i].Name < rv[j].Name })
+	this.triggers[trigger.Keyspace] = rv
+	return true
+}
+
+func (this *triggerStore) DropTrigger(keyspace, name string) (*Trigger, error) {
+	trigger := this.drop(keyspace, name)
+	if trigger != nil && this.mirror != nil {
+		return trigger, this.mirror.Delete(triggerKey(keyspace, name))
+	}
+	return trigger, nil
+}
+
+func (this *triggerStore) drop(keyspace, name string) *Trigger {
+	this.Lock()
+	defer this.Unlock()
+	triggers := this.triggers[keyspace]
+	for i, t := range triggers {
+		if t.Name == name {
+			if len(triggers) == 1 {
+				delete(this.triggers, keyspace)
+			} else {
+				rv := make([]*Trigger, 0, len(triggers)-1)
+				rv = append(rv, triggers[:i]...)
+				this.triggers[keyspace] = append(rv, triggers[i+1:]...)
+			}
+			return t
+		}
+	}
+	return nil
+}
+
+// a change reported by metakv, possibly one this node made
+func (this *triggerStore) applyEntry(key string, bytes []byte) {
+	if bytes == nil {
+		i := strings.IndexByte(key, '/')
+		if i > 0 {
+			this.drop(key[:i], key[i+1:])
+		}
+		return
+	}
+	trigger, err := this.decode(bytes)
+	if err != nil {
+		logging.Errorf("Ignoring trigger %v: %v", key, err)
+		return
+	}
+	this.add(trigger, true)
+}
+
+func (this *triggerStore) Keyspaces() []string {
+	this.RLock()
+	defer this.RUnlock()
+	rv := make([]string, 0, len(this.triggers))
+	for keyspace, _ := range this.triggers {
+		rv = append(rv, keyspace)
+	}
+	sort.Strings(rv)
+	return rv
+}
@@ -0,0 +1,50 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package datastore
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTriggerEntries(t *testing.T) {
+	store := newTriggerStore()
+	store.decode = func(bytes []byte) (*Trigger, error) {
+		var rv Trigger
+		err := json.Unmarshal(bytes, &rv)
+		return &rv, err
+	}
+
+	t2 := &Trigger{Name: "t2", Keyspace: "default:orders", Timing: TRIGGER_BEFORE, Event: TRIGGER_INSERT}
+	if ok, err := store.AddTrigger(t2); !ok || err != nil {
+		t.Fatalf("Unexpected failure adding trigger: %v", err)
+	}
+	if ok, _ := store.AddTrigger(t2); ok {
+		t.Errorf("Expected duplicate trigger to fail")
+	}
+
+	// triggers created elsewhere, or our own coming back, replace by name
+	store.applyEntry("default:orders/t1", []byte(`{"Name": "t1", "Keyspace": "default:orders", "Event": "UPDATE"}`))
+	store.applyEntry("default:orders/t2", []byte(`{"Name": "t2", "Keyspace": "default:orders", "Event": "DELETE"}`))
+	triggers := store.Triggers("default:orders")
+	if len(triggers) != 2 || triggers[0].Name != "t1" || triggers[1].Event != TRIGGER_DELETE {
+		t.Errorf("Unexpected triggers %v", triggers)
+	}
+
+	store.applyEntry("default:orders/t1", nil)
+	if trigger, err := store.DropTrigger("default:orders", "t1"); trigger != nil || err != nil {
+		t.Errorf("Unexpected drop of missing trigger: %v, %v", trigger, err)
+	}
+	if trigger, _ := store.DropTrigger("default:orders", "t2"); trigger == nil {
+		t.Errorf("Expected to drop trigger t2")
+	}
+	if keyspaces := store.Keyspaces(); len(keyspaces) != 0 {
+		t.Errorf("Unexpected keyspaces %v", keyspaces)
+	}
+}
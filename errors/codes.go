@@ -38,6 +38,11 @@ const (
 	E_SHELL_NO_SUCH_ALIAS                     ErrorCode = 141
 	E_SHELL_BATCH_MODE                        ErrorCode = 142
 	E_SHELL_STRING_WRITE                      ErrorCode = 143
+	E_SHELL_INVALID_FORMAT                    ErrorCode = 144
+	E_SHELL_UNMATCHED_CONDITIONAL             ErrorCode = 145
+	E_SHELL_UNTERMINATED_CONDITIONAL          ErrorCode = 146
+	E_SHELL_NOT_SCALAR                        ErrorCode = 147
+	E_SHELL_IMPORT                            ErrorCode = 148
 	E_SHELL_OPERATION_TIMEOUT                 ErrorCode = 170
 	E_SHELL_ROWS_SCAN                         ErrorCode = 171
 	E_SHELL_JSON_MARSHAL                      ErrorCode = 172
@@ -132,6 +137,7 @@ const (
 	E_CREATE_INDEX_ATTRIBUTE                  ErrorCode = 3282
 	E_FLATTEN_KEYS                            ErrorCode = 3283
 	E_ALL_DISTINCT_NOT_ALLOWED                ErrorCode = 3284
+	E_PREPARE_PASSWORD                        ErrorCode = 3285
 	E_PLAN                                    ErrorCode = 4000
 	E_REPREPARE                               ErrorCode = 4001
 	E_NO_TERM_NAME                            ErrorCode = 4010
@@ -148,6 +154,8 @@ const (
 	E_ENCODING_NAME_MISMATCH                  ErrorCode = 4090
 	E_ENCODING_CONTEXT_MISMATCH               ErrorCode = 4091
 	E_PREDEFINED_PREPARED_NAME                ErrorCode = 4092
+	E_NO_SUCH_BASELINE                        ErrorCode = 4093
+	E_BASELINE                                ErrorCode = 4094
 	E_NO_INDEX_JOIN                           ErrorCode = 4100
 	E_USE_KEYS_USE_INDEXES                    ErrorCode = 4110
 	E_NO_PRIMARY_INDEX                        ErrorCode = 4120
@@ -192,10 +200,28 @@ const (
 	E_UPDATE_ALIAS_MISSING                    ErrorCode = 5100
 	E_UPDATE_ALIAS_METADATA                   ErrorCode = 5110
 	E_UPDATE_MISSING_CLONE                    ErrorCode = 5120
+	E_SCHEMA_VALIDATION                       ErrorCode = 5130
+	E_SCHEMA_NOT_FOUND                        ErrorCode = 5131
+	E_ENCRYPTION_KEY_NOT_FOUND                ErrorCode = 5140
+	E_DECRYPTION                              ErrorCode = 5141
+	E_MATERIALIZED_VIEW_NOT_FOUND             ErrorCode = 5150
+	E_DUPLICATE_MATERIALIZED_VIEW             ErrorCode = 5151
+	E_MATERIALIZED_VIEW_REFRESH               ErrorCode = 5152
+	E_MATERIALIZED_VIEW_DEFINITION            ErrorCode = 5153
+	E_MATERIALIZED_VIEW_STORE                 ErrorCode = 5154
+	E_TRIGGER_NOT_FOUND                       ErrorCode = 5160
+	E_DUPLICATE_TRIGGER                       ErrorCode = 5161
+	E_TRIGGER_REJECTED                        ErrorCode = 5162
+	E_TRIGGER_EXECUTION                       ErrorCode = 5163
+	E_TRIGGER_STORE                           ErrorCode = 5164
 	E_UNNEST_INVALID_POSITION                 ErrorCode = 5180
 	E_SCAN_VECTOR_TOO_MANY_SCANNED_BUCKETS    ErrorCode = 5190
 	_RETIRED_5200                                       = 5200
 	E_USER_NOT_FOUND                          ErrorCode = 5210
+	E_USER_EXISTS                             ErrorCode = 5211
+	E_GROUP_NOT_FOUND                         ErrorCode = 5212
+	E_GROUP_EXISTS                            ErrorCode = 5213
+	E_USER_OPTION                             ErrorCode = 5214
 	E_ROLE_REQUIRES_KEYSPACE                  ErrorCode = 5220
 	E_ROLE_TAKES_NO_KEYSPACE                  ErrorCode = 5230
 	E_NO_SUCH_KEYSPACE                        ErrorCode = 5240
@@ -264,6 +290,7 @@ const (
 	E_METAKV_INDEX                            ErrorCode = 10111
 	E_TOO_MANY_NESTED_FUNCTIONS               ErrorCode = 10112
 	E_INNER_FUNCTION_EXECUTION                ErrorCode = 10113
+	E_PROCEDURE_DEFINITION                    ErrorCode = 10114
 	E_DATASTORE_INVALID_BUCKET_PARTS          ErrorCode = 10200
 	E_QUERY_CONTEXT                           ErrorCode = 10201
 	E_BUCKET_NO_DEFAULT_COLLECTION            ErrorCode = 10202
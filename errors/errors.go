@@ -165,6 +165,12 @@ func processValue(v interface{}) interface{} {
 	switch vt := v.(type) {
 	case map[string]interface{}:
 		return processMap(vt)
+	case []interface{}:
+		rv := make([]interface{}, len(vt))
+		for i, v := range vt {
+			rv[i] = processValue(v)
+		}
+		return rv
 	case interface{ Object() map[string]interface{} }:
 		return vt.Object()
 	case interface{ Error() string }:
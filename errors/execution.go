@@ -147,6 +147,81 @@ func NewUpdateMissingClone() Error {
 		InternalMsg: "Missing UPDATE clone.", InternalCaller: CallerN(1)}
 }
 
+func NewSchemaValidationError(key string, errs []interface{}) Error {
+	c := map[string]interface{}{"key": key, "errors": errs}
+	return &err{level: EXCEPTION, ICode: E_SCHEMA_VALIDATION, IKey: "execution.schema_validation",
+		InternalMsg: fmt.Sprintf("Document %s does not match the keyspace schema.", key), cause: c,
+		InternalCaller: CallerN(1)}
+}
+
+func NewSchemaNotFoundError(keyspace string) Error {
+	return &err{level: EXCEPTION, ICode: E_SCHEMA_NOT_FOUND, IKey: "execution.schema_not_found",
+		InternalMsg: fmt.Sprintf("No schema found in system:schemas for %s.", keyspace), InternalCaller: CallerN(1)}
+}
+
+func NewEncryptionKeyNotFoundError(name string) Error {
+	return &err{level: EXCEPTION, ICode: E_ENCRYPTION_KEY_NOT_FOUND, IKey: "execution.encryption_key_not_found",
+		InternalMsg: fmt.Sprintf("Encryption key %s not found in the keystore.", name), InternalCaller: CallerN(1)}
+}
+
+func NewDecryptionError(name string) Error {
+	return &err{level: EXCEPTION, ICode: E_DECRYPTION, IKey: "execution.decryption_error",
+		InternalMsg:    fmt.Sprintf("Value cannot be decrypted with key %s: it was not encrypted with this key or has been altered.", name),
+		InternalCaller: CallerN(1)}
+}
+
+func NewMaterializedViewNotFoundError(name string) Error {
+	return &err{level: EXCEPTION, ICode: E_MATERIALIZED_VIEW_NOT_FOUND, IKey: "execution.materialized_view_not_found",
+		InternalMsg: fmt.Sprintf("Materialized view %s not found.", name), InternalCaller: CallerN(1)}
+}
+
+func NewDuplicateMaterializedViewError(name string) Error {
+	return &err{level: EXCEPTION, ICode: E_DUPLICATE_MATERIALIZED_VIEW, IKey: "execution.duplicate_materialized_view",
+		InternalMsg: fmt.Sprintf("Materialized view %s already exists.", name), InternalCaller: CallerN(1)}
+}
+
+func NewMaterializedViewRefreshError(name string, e error) Error {
+	return &err{level: EXCEPTION, ICode: E_MATERIALIZED_VIEW_REFRESH, IKey: "execution.materialized_view_refresh",
+		InternalMsg: fmt.Sprintf("Error refreshing materialized view %s", name), ICause: e, InternalCaller: CallerN(1)}
+}
+
+func NewMaterializedViewDefinitionError(name, reason string) Error {
+	return &err{level: EXCEPTION, ICode: E_MATERIALIZED_VIEW_DEFINITION, IKey: "execution.materialized_view_definition",
+		InternalMsg: fmt.Sprintf("Invalid materialized view %s: %s", name, reason), InternalCaller: CallerN(1)}
+}
+
+func NewMaterializedViewStoreError(name string, e error) Error {
+	return &err{level: EXCEPTION, ICode: E_MATERIALIZED_VIEW_STORE, IKey: "execution.materialized_view_store",
+		InternalMsg: fmt.Sprintf("Error storing materialized view %s", name), ICause: e, InternalCaller: CallerN(1)}
+}
+
+func NewTriggerNotFoundError(name, keyspace string) Error {
+	return &err{level: EXCEPTION, ICode: E_TRIGGER_NOT_FOUND, IKey: "execution.trigger_not_found",
+		InternalMsg: fmt.Sprintf("Trigger %s on %s not found.", name, keyspace), InternalCaller: CallerN(1)}
+}
+
+func NewDuplicateTriggerError(name, keyspace string) Error {
+	return &err{level: EXCEPTION, ICode: E_DUPLICATE_TRIGGER, IKey: "execution.duplicate_trigger",
+		InternalMsg: fmt.Sprintf("Trigger %s on %s already exists.", name, keyspace), InternalCaller: CallerN(1)}
+}
+
+func NewTriggerRejectedError(name, key string) Error {
+	return &err{level: EXCEPTION, ICode: E_TRIGGER_REJECTED, IKey: "execution.trigger_rejected",
+		InternalMsg: fmt.Sprintf("Trigger %s rejected document %s.", name, key), InternalCaller: CallerN(1)}
+}
+
+func NewTriggerExecutionError(name, key string, e error) Error {
+	return &err{level: EXCEPTION, ICode: E_TRIGGER_EXECUTION, IKey: "execution.trigger_execution",
+		InternalMsg: fmt.Sprintf("Error executing trigger %s for document %s", name, key), ICause: e,
+		InternalCaller: CallerN(1)}
+}
+
+func NewTriggerStoreError(name, keyspace string, e error) Error {
+	return &err{level: EXCEPTION, ICode: E_TRIGGER_STORE, IKey: "execution.trigger_store",
+		InternalMsg: fmt.Sprintf("Error storing trigger %s on %s", name, keyspace), ICause: e,
+		InternalCaller: CallerN(1)}
+}
+
 func NewUnnestInvalidPosition(pos interface{}) Error {
 	return &err{level: EXCEPTION, ICode: E_UNNEST_INVALID_POSITION, IKey: "execution.unnest_invalid_position",
 		InternalMsg: fmt.Sprintf("Invalid UNNEST position of type %T.", pos), InternalCaller: CallerN(1)}
@@ -165,6 +240,26 @@ func NewUserNotFoundError(u string) Error {
 		InternalMsg: fmt.Sprintf("Unable to find user %s.", u), InternalCaller: CallerN(1)}
 }
 
+func NewUserExistsError(u string) Error {
+	return &err{level: EXCEPTION, ICode: E_USER_EXISTS, IKey: "execution.user_exists",
+		InternalMsg: fmt.Sprintf("User %s already exists.", u), InternalCaller: CallerN(1)}
+}
+
+func NewGroupNotFoundError(g string) Error {
+	return &err{level: EXCEPTION, ICode: E_GROUP_NOT_FOUND, IKey: "execution.group_not_found",
+		InternalMsg: fmt.Sprintf("Unable to find group %s.", g), InternalCaller: CallerN(1)}
+}
+
+func NewGroupExistsError(g string) Error {
+	return &err{level: EXCEPTION, ICode: E_GROUP_EXISTS, IKey: "execution.group_exists",
+		InternalMsg: fmt.Sprintf("Group %s already exists.", g), InternalCaller: CallerN(1)}
+}
+
+func NewUserOptionError(what, name, msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_USER_OPTION, IKey: "execution.user_option",
+		InternalMsg: fmt.Sprintf("Invalid option for %s %s - %s", what, name, msg), InternalCaller: CallerN(1)}
+}
+
 func NewRoleRequiresKeyspaceError(role string) Error {
 	return &err{level: EXCEPTION, ICode: E_ROLE_REQUIRES_KEYSPACE, IKey: "execution.role_requires_keyspace",
 		InternalMsg: fmt.Sprintf("Role %s requires a keyspace.", role), InternalCaller: CallerN(1)}
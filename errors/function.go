@@ -115,6 +115,11 @@ func NewFunctionExecutionNestedError(levels int, name string) Error {
 		InternalCaller: CallerN(1)}
 }
 
+func NewProcedureDefinitionError(reason string) Error {
+	return &err{level: EXCEPTION, ICode: E_PROCEDURE_DEFINITION, IKey: "function.procedure.error",
+		InternalMsg: fmt.Sprintf("Invalid procedure: %v", reason), InternalCaller: CallerN(1)}
+}
+
 func NewAdvisorSessionNotFoundError(s string) Error {
 	c := make(map[string]interface{})
 	c["unknown_session"] = s
@@ -113,6 +113,16 @@ func NewPredefinedPreparedNameError(msg string) Error {
 		InternalMsg: fmt.Sprintf("Prepared name %s is predefined (reserved). ", msg), InternalCaller: CallerN(1)}
 }
 
+func NewNoSuchBaselineError(name string) Error {
+	return &err{level: EXCEPTION, ICode: E_NO_SUCH_BASELINE, IKey: "plan.baseline.no_such_name",
+		InternalMsg: fmt.Sprintf("No such plan baseline: %s", name), InternalCaller: CallerN(1)}
+}
+
+func NewBaselineError(e error, name, msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_BASELINE, IKey: "plan.baseline.error", ICause: e,
+		InternalMsg: fmt.Sprintf("Plan baseline %s - %s", name, msg), InternalCaller: CallerN(1)}
+}
+
 func NewNoIndexJoinError(alias, op string) Error {
 	return &err{level: EXCEPTION, ICode: E_NO_INDEX_JOIN, IKey: fmt.Sprintf("plan.index_%s.no_index", op),
 		InternalMsg: fmt.Sprintf("No index available for join term %s", alias), InternalCaller: CallerN(1)}
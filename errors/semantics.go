@@ -164,6 +164,12 @@ func NewAllDistinctNotAllowed(msg, at string) Error {
 		InternalCaller: CallerN(1)}
 }
 
+func NewPreparePasswordError(stmtType string) Error {
+	return &err{level: EXCEPTION, ICode: E_PREPARE_PASSWORD, IKey: "semantics_prepare_password",
+		InternalMsg:    fmt.Sprintf("%s statements hold a password and cannot be prepared.", stmtType),
+		InternalCaller: CallerN(1)}
+}
+
 /* ---- BEGIN MOVED error numbers ----
    The following error numbers (in the 4000 range) originally reside in plan.go (before the introduction of the semantics package)
    although they are semantic errors. They are moved from plan.go to semantics.go but their original error numbers are kept.
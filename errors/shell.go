@@ -42,6 +42,12 @@ const (
 	NO_SUCH_ALIAS_MSG   = "Alias does not exist "
 	BATCH_MODE_MSG      = "Error when running in batch mode for Analytics. Incorrect input value"
 	STRING_WRITE_MSG    = "Cannot write to string buffer. "
+	INVALID_FORMAT_MSG  = "Invalid output format. Supported formats are json, jsonl, table, csv and tsv. "
+
+	UNMATCHED_CONDITIONAL_MSG    = "\\ELSE or \\ENDIF without a matching \\IF."
+	UNTERMINATED_CONDITIONAL_MSG = "\\IF without a matching \\ENDIF."
+	NOT_SCALAR_MSG               = "Statement must return a single result to be assigned to a variable. "
+	IMPORT_MSG                   = "Some documents could not be imported. "
 
 	OPERATION_TIMEOUT_MSG       = "Operation timed out. Check query service url "
 	ROWS_SCAN_MSG               = ""
@@ -173,6 +179,31 @@ func NewShellErrorStringWrite(msg string) Error {
 
 }
 
+func NewShellErrorInvalidFormat(msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_SHELL_INVALID_FORMAT, IKey: "shell.invalid.format", InternalMsg: INVALID_FORMAT_MSG + msg, InternalCaller: CallerN(1)}
+
+}
+
+func NewShellErrorUnmatchedConditional(msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_SHELL_UNMATCHED_CONDITIONAL, IKey: "shell.unmatched.conditional", InternalMsg: UNMATCHED_CONDITIONAL_MSG + msg, InternalCaller: CallerN(1)}
+
+}
+
+func NewShellErrorUnterminatedConditional(msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_SHELL_UNTERMINATED_CONDITIONAL, IKey: "shell.unterminated.conditional", InternalMsg: UNTERMINATED_CONDITIONAL_MSG + msg, InternalCaller: CallerN(1)}
+
+}
+
+func NewShellErrorNotScalar(msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_SHELL_NOT_SCALAR, IKey: "shell.not.scalar", InternalMsg: NOT_SCALAR_MSG + msg, InternalCaller: CallerN(1)}
+
+}
+
+func NewShellErrorImport(msg string) Error {
+	return &err{level: EXCEPTION, ICode: E_SHELL_IMPORT, IKey: "shell.import", InternalMsg: IMPORT_MSG + msg, InternalCaller: CallerN(1)}
+
+}
+
 //Generic Errors
 
 func NewShellErrorOperationTimeout(msg string) Error {
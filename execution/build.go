@@ -262,6 +262,16 @@ func (this *builder) VisitHashJoin(plan *plan.HashJoin) (interface{}, error) {
 	return checkOp(NewHashJoin(plan, this.context, c.(Operator), this.aliasMap), this.context)
 }
 
+func (this *builder) VisitMergeJoin(plan *plan.MergeJoin) (interface{}, error) {
+	child := plan.Child()
+	c, e := child.Accept(this)
+	if e != nil {
+		return nil, e
+	}
+
+	return checkOp(NewMergeJoin(plan, this.context, c.(Operator), this.aliasMap), this.context)
+}
+
 func (this *builder) VisitNest(plan *plan.Nest) (interface{}, error) {
 	this.setAliasMap(plan.Term())
 	return checkOp(NewNest(plan, this.context), this.context)
@@ -292,6 +302,16 @@ func (this *builder) VisitHashNest(plan *plan.HashNest) (interface{}, error) {
 	return checkOp(NewHashNest(plan, this.context, c.(Operator), this.aliasMap), this.context)
 }
 
+func (this *builder) VisitMergeNest(plan *plan.MergeNest) (interface{}, error) {
+	child := plan.Child()
+	c, e := child.Accept(this)
+	if e != nil {
+		return nil, e
+	}
+
+	return checkOp(NewMergeNest(plan, this.context, c.(Operator), this.aliasMap), this.context)
+}
+
 func (this *builder) VisitUnnest(plan *plan.Unnest) (interface{}, error) {
 	return checkOp(NewUnnest(plan, this.context), this.context)
 }
@@ -642,6 +662,31 @@ func (this *builder) VisitRevokeRole(plan *plan.RevokeRole) (interface{}, error)
 	return checkOp(NewRevokeRole(plan, this.context), this.context)
 }
 
+// CreateUser
+func (this *builder) VisitCreateUser(plan *plan.CreateUser) (interface{}, error) {
+	return checkOp(NewCreateUser(plan, this.context), this.context)
+}
+
+// AlterUser
+func (this *builder) VisitAlterUser(plan *plan.AlterUser) (interface{}, error) {
+	return checkOp(NewAlterUser(plan, this.context), this.context)
+}
+
+// DropUser
+func (this *builder) VisitDropUser(plan *plan.DropUser) (interface{}, error) {
+	return checkOp(NewDropUser(plan, this.context), this.context)
+}
+
+// CreateGroup
+func (this *builder) VisitCreateGroup(plan *plan.CreateGroup) (interface{}, error) {
+	return checkOp(NewCreateGroup(plan, this.context), this.context)
+}
+
+// DropGroup
+func (this *builder) VisitDropGroup(plan *plan.DropGroup) (interface{}, error) {
+	return checkOp(NewDropGroup(plan, this.context), this.context)
+}
+
 // CreateIndex
 func (this *builder) VisitCreateIndex(plan *plan.CreateIndex) (interface{}, error) {
 	return checkOp(NewCreateIndex(plan, this.context), this.context)
@@ -723,6 +768,31 @@ func (this *builder) VisitExecuteFunction(plan *plan.ExecuteFunction) (interface
 	return checkOp(NewExecuteFunction(plan, this.context), this.context)
 }
 
+// CreateMaterializedView
+func (this *builder) VisitCreateMaterializedView(plan *plan.CreateMaterializedView) (interface{}, error) {
+	return checkOp(NewCreateMaterializedView(plan, this.context), this.context)
+}
+
+// DropMaterializedView
+func (this *builder) VisitDropMaterializedView(plan *plan.DropMaterializedView) (interface{}, error) {
+	return checkOp(NewDropMaterializedView(plan, this.context), this.context)
+}
+
+// RefreshMaterializedView
+func (this *builder) VisitRefreshMaterializedView(plan *plan.RefreshMaterializedView) (interface{}, error) {
+	return checkOp(NewRefreshMaterializedView(plan, this.context), this.context)
+}
+
+// CreateTrigger
+func (this *builder) VisitCreateTrigger(plan *plan.CreateTrigger) (interface{}, error) {
+	return checkOp(NewCreateTrigger(plan, this.context), this.context)
+}
+
+// DropTrigger
+func (this *builder) VisitDropTrigger(plan *plan.DropTrigger) (interface{}, error) {
+	return checkOp(NewDropTrigger(plan, this.context), this.context)
+}
+
 // IndexFtsSearch
 func (this *builder) VisitIndexFtsSearch(plan *plan.IndexFtsSearch) (interface{}, error) {
 	this.setScannedIndexes(plan.Term())
@@ -48,10 +48,12 @@ const (
 	INDEX_JOIN
 	NL_JOIN
 	HASH_JOIN
+	MERGE_JOIN
 	NEST
 	INDEX_NEST
 	NL_NEST
 	HASH_NEST
+	MERGE_NEST
 	COUNT
 	INDEX_COUNT
 	FILTER
@@ -92,10 +94,12 @@ var _PHASE_NAMES = []string{
 	INDEX_JOIN:   "indexJoin",
 	NL_JOIN:      "nestedLoopJoin",
 	HASH_JOIN:    "hashJoin",
+	MERGE_JOIN:   "mergeJoin",
 	NEST:         "nest",
 	INDEX_NEST:   "indexNest",
 	NL_NEST:      "nestedLoopNest",
 	HASH_NEST:    "hashNest",
+	MERGE_NEST:   "mergeNest",
 	COUNT:        "count",
 	INDEX_COUNT:  "indexCount",
 	SORT:         "sort",
@@ -165,6 +169,7 @@ type Context struct {
 	optimizer           planner.Optimizer
 	readonly            bool
 	maxParallelism      int
+	serverLoad          int
 	scanCap             int64
 	pipelineCap         int64
 	pipelineBatch       int
@@ -201,6 +206,7 @@ type Context struct {
 	numAtrs             int
 	kvTimeout           time.Duration
 	preserveExpiry      bool
+	useDecimal          bool
 	flags               uint32
 	recursionCount      int32
 	result              func(context *Context, item value.AnnotatedValue) bool
@@ -266,6 +272,7 @@ func (this *Context) Copy() *Context {
 		namespace:           this.namespace,
 		readonly:            this.readonly,
 		maxParallelism:      this.maxParallelism,
+		serverLoad:          this.serverLoad,
 		scanCap:             this.scanCap,
 		pipelineCap:         this.pipelineCap,
 		pipelineBatch:       this.pipelineBatch,
@@ -292,6 +299,7 @@ func (this *Context) Copy() *Context {
 		atrCollection:       this.atrCollection,
 		numAtrs:             this.numAtrs,
 		preserveExpiry:      this.preserveExpiry,
+		useDecimal:          this.useDecimal,
 		flags:               this.flags,
 		reqTimeout:          this.reqTimeout,
 		whitelist:           this.whitelist,
@@ -350,6 +358,10 @@ func (this *Context) GetWhitelist() map[string]interface{} {
 	return this.whitelist
 }
 
+func (this *Context) EncryptionKey(name string) ([]byte, bool) {
+	return datastore.GetKeyStore().Key(name)
+}
+
 func (this *Context) Optimizer() planner.Optimizer {
 	return this.optimizer
 }
@@ -374,6 +386,31 @@ func (this *Context) MaxParallelism() int {
 	return this.maxParallelism
 }
 
+// the load of the server when the request was admitted, as a percentage of the servicers
+func (this *Context) SetServerLoad(load int) {
+	this.serverLoad = load
+}
+
+func (this *Context) ServerLoad() int {
+	return this.serverLoad
+}
+
+/*
+The degree of parallelism for operators that can adapt to the server load:
+all of n while at least half of the servicers are idle, then in proportion
+to the idle servicers, but never less than one.
+*/
+func (this *Context) AdaptiveParallelism(n int) int {
+	idle := 100 - this.serverLoad
+	if idle < 50 {
+		n = n * idle / 50
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
 func (this *Context) Now() time.Time {
 	return this.now
 }
@@ -704,6 +741,17 @@ func (this *Context) PreserveExpiry() bool {
 	return this.preserveExpiry
 }
 
+func (this *Context) SetUseDecimal(useDecimal bool) {
+	this.useDecimal = useDecimal
+}
+
+/*
+Exact decimal arithmetic, see expression.DecimalContext.
+*/
+func (this *Context) UseDecimal() bool {
+	return this.useDecimal
+}
+
 func (this *Context) ResetTxContext() {
 	if this.txContext != nil {
 		this.txContext = nil
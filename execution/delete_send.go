@@ -32,12 +32,14 @@ type SendDelete struct {
 	plan     *plan.SendDelete
 	keyspace datastore.Keyspace
 	limit    int64
+	triggers *rowTriggers
 }
 
 func NewSendDelete(plan *plan.SendDelete, context *Context) *SendDelete {
 	rv := _SENDDELETE_OP_POOL.Get().(*SendDelete)
 	rv.plan = plan
 	rv.limit = -1
+	rv.triggers = nil
 
 	newBase(&rv.base, context)
 	rv.execPhase = DELETE
@@ -53,6 +55,7 @@ func (this *SendDelete) Copy() Operator {
 	rv := _SENDDELETE_OP_POOL.Get().(*SendDelete)
 	rv.plan = this.plan
 	rv.limit = this.limit
+	rv.triggers = this.triggers
 	this.base.copy(&rv.base)
 	return rv
 }
@@ -80,6 +83,7 @@ func (this *SendDelete) beforeItems(context *Context, parent value.Value) bool {
 	if this.keyspace == nil {
 		return false
 	}
+	this.triggers = getTriggers(this.keyspace, datastore.TRIGGER_DELETE)
 
 	if this.plan.Limit() == nil {
 		return true
@@ -121,7 +125,9 @@ func (this *SendDelete) flushBatch(context *Context) bool {
 		pairs = make([]value.Pair, 0, len(this.batch))
 	}
 
-	for i, item := range this.batch {
+	var rejected map[int]bool
+	i := 0
+	for n, item := range this.batch {
 		dv, ok := item.Field(this.plan.Alias())
 		if !ok {
 			context.Error(errors.NewDeleteAliasMissingError(this.plan.Alias()))
@@ -139,11 +145,25 @@ func (this *SendDelete) flushBatch(context *Context) bool {
 			return false
 		}
 
+		_, ok = this.triggers.fireBefore(key, nil, av, context)
+		if !ok {
+			if rejected == nil {
+				rejected = make(map[int]bool)
+			}
+			rejected[n] = true
+			continue
+		}
+
 		pairs = pairs[0 : i+1]
 		pair := &pairs[i]
 		pair.Name = key
 		pair.Value = av
+		if this.plan.Images() {
+			setReturningImages(item, this.plan.Alias(), av, nil)
+		}
+		i++
 	}
+	pairs = pairs[0:i]
 
 	this.switchPhase(_SERVTIME)
 
@@ -153,6 +173,10 @@ func (this *SendDelete) flushBatch(context *Context) bool {
 	// Update mutation count with number of deleted docs:
 	context.AddMutationCount(uint64(len(dpairs)))
 
+	for _, dp := range dpairs {
+		this.triggers.fireAfter(dp.Name, nil, dp.Value, context)
+	}
+
 	mutationOk := true
 	if len(errs) > 0 {
 		context.Errors(errs)
@@ -161,7 +185,10 @@ func (this *SendDelete) flushBatch(context *Context) bool {
 		}
 	}
 
-	for _, item := range this.batch {
+	for n, item := range this.batch {
+		if rejected[n] {
+			continue
+		}
 		if !this.sendItem(item) {
 			return false
 		}
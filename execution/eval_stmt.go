@@ -35,6 +35,11 @@ type internalOutput struct {
 	err           errors.Error
 }
 
+// for contexts the server sets up for statements of its own
+func NewInternalOutput() Output {
+	return &internalOutput{}
+}
+
 func (this *internalOutput) SetUp() {
 }
 
@@ -192,6 +197,7 @@ func (this *Context) PrepareStatement(statement string, namedArgs map[string]val
 	planner.NewPrepareContext(&prepContext, this.requestId, this.queryContext, namedArgs,
 		positionalArgs, this.indexApiVersion, this.featureControls, this.useFts, this.useCBO, this.optimizer,
 		this.deltaKeyspaces, this)
+	prepContext.SetBaselineText(statement)
 
 	if autoPrepare {
 		name = prepareds.GetAutoPrepareName(statement, &prepContext)
@@ -0,0 +1,155 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type CreateGroup struct {
+	base
+	plan *plan.CreateGroup
+}
+
+func NewCreateGroup(plan *plan.CreateGroup, context *Context) *CreateGroup {
+	rv := &CreateGroup{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *CreateGroup) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateGroup(this)
+}
+
+func (this *CreateGroup) Copy() Operator {
+	rv := &CreateGroup{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *CreateGroup) PlanOp() plan.Operator {
+	return this.plan
+}
+
+// Retrieve the current set of groups, as a map indexed by group id.
+func getGroupMap(ds datastore.Datastore) (map[string]*datastore.Group, errors.Error) {
+	currentGroups, err := ds.GetGroupInfoAll()
+	if err != nil {
+		return nil, err
+	}
+	groupMap := make(map[string]*datastore.Group, len(currentGroups))
+	for i, g := range currentGroups {
+		groupMap[g.Id] = &currentGroups[i]
+	}
+	return groupMap, nil
+}
+
+// Set the options given in the WITH clause of CREATE GROUP.
+func setGroupOptions(group *datastore.Group, with value.Value, context *Context) errors.Error {
+	if with == nil {
+		return nil
+	}
+
+	if with.Type() != value.OBJECT {
+		return errors.NewUserOptionError("group", group.Id, "WITH must be an object")
+	}
+	for k, v := range with.Fields() {
+		val := value.NewValue(v)
+		switch k {
+		case "description":
+			if val.Type() != value.STRING {
+				return errors.NewUserOptionError("group", group.Id, "description must be a string")
+			}
+			group.Description = val.Actual().(string)
+		case "roles":
+			names, ok := stringList(val)
+			if !ok {
+				return errors.NewUserOptionError("group", group.Id, "roles must be an array of strings")
+			}
+
+			// roles are given as role or role[target]
+			roles := make([]datastore.Role, len(names))
+			for i, n := range names {
+				if j := strings.Index(n, "["); j > 0 && strings.HasSuffix(n, "]") {
+					roles[i].Target = n[j+1 : len(n)-1]
+					n = n[:j]
+				}
+				roles[i].Name = auth.NormalizeRoleNames([]string{n})[0]
+			}
+			validRoles, err := context.datastore.GetRolesAll()
+			if err != nil {
+				return err
+			}
+			err = validateRoles(roles, validRoles)
+			if err != nil {
+				return err
+			}
+			group.Roles = roles
+		default:
+			return errors.NewUserOptionError("group", group.Id, "unknown option "+k)
+		}
+	}
+	return nil
+}
+
+func (this *CreateGroup) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		node := this.plan.Node()
+		groupMap, err := getGroupMap(context.datastore)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+		if groupMap[node.Group()] != nil {
+			context.Fatal(errors.NewGroupExistsError(node.Group()))
+			return
+		}
+
+		group := &datastore.Group{Id: node.Group()}
+		err = setGroupOptions(group, node.With(), context)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+
+		err = context.datastore.PutGroupInfo(group)
+		if err != nil {
+			context.Fatal(err)
+		}
+	})
+}
+
+func (this *CreateGroup) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
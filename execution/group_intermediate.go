@@ -11,17 +11,22 @@ package execution
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/util"
 	"github.com/couchbase/query/value"
 )
 
 // Grouping of groups. Recursable.
 type IntermediateGroup struct {
 	base
-	plan   *plan.IntermediateGroup
-	groups map[string]value.AnnotatedValue
+	plan       *plan.IntermediateGroup
+	groups     map[string]value.AnnotatedValue
+	shared     *groupMerge
+	registered bool
 }
 
 func NewIntermediateGroup(plan *plan.IntermediateGroup, context *Context) *IntermediateGroup {
@@ -29,8 +34,14 @@ func NewIntermediateGroup(plan *plan.IntermediateGroup, context *Context) *Inter
 		plan:   plan,
 		groups: make(map[string]value.AnnotatedValue),
 	}
+	if plan.Partitions() > 1 {
+		rv.shared = newGroupMerge(plan.Partitions())
+	}
 
 	newBase(&rv.base, context)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	rv.output = rv
 	return rv
 }
@@ -43,8 +54,12 @@ func (this *IntermediateGroup) Copy() Operator {
 	rv := &IntermediateGroup{
 		plan:   this.plan,
 		groups: make(map[string]value.AnnotatedValue),
+		shared: this.shared,
 	}
 	this.base.copy(&rv.base)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	return rv
 }
 
@@ -53,9 +68,20 @@ func (this *IntermediateGroup) PlanOp() plan.Operator {
 }
 
 func (this *IntermediateGroup) RunOnce(context *Context, parent value.Value) {
+
+	// the other copies wait for this one, even if it panics
+	defer this.leave()
 	this.runConsumer(this, context, parent)
 }
 
+func (this *IntermediateGroup) beforeItems(context *Context, parent value.Value) bool {
+	if this.shared != nil {
+		this.shared.register()
+		this.registered = true
+	}
+	return true
+}
+
 func (this *IntermediateGroup) processItem(item value.AnnotatedValue, context *Context) bool {
 	// Generate the group key
 	var gk string
@@ -69,13 +95,25 @@ func (this *IntermediateGroup) processItem(item value.AnnotatedValue, context *C
 		}
 	}
 
+	if this.shared != nil {
+		partition := this.shared.partition(gk)
+		partition.Lock()
+		defer partition.Unlock()
+		return this.cumulate(partition.groups, gk, item, context)
+	}
+	return this.cumulate(this.groups, gk, item, context)
+}
+
+func (this *IntermediateGroup) cumulate(groups map[string]value.AnnotatedValue, gk string,
+	item value.AnnotatedValue, context *Context) bool {
+
 	// Get or seed the group value
-	gv := this.groups[gk]
+	gv := groups[gk]
 	if gv == nil {
 
 		// avoid recycling of seeding values
 		gv = item
-		this.groups[gk] = gv
+		groups[gk] = gv
 		return true
 	}
 
@@ -116,6 +154,26 @@ func (this *IntermediateGroup) processItem(item value.AnnotatedValue, context *C
 }
 
 func (this *IntermediateGroup) afterItems(context *Context) {
+	if this.shared != nil {
+		if !this.registered {
+			return
+		}
+		this.leave()
+
+		// once all copies have merged their input, complete disjoint partitions
+		if !this.shared.wait(this.stopCh()) {
+			return
+		}
+		for partition := this.shared.claim(); partition != nil; partition = this.shared.claim() {
+			for _, av := range partition.groups {
+				if !this.sendItem(av) {
+					return
+				}
+			}
+		}
+		return
+	}
+
 	for _, av := range this.groups {
 		if !this.sendItem(av) {
 			return
@@ -123,6 +181,13 @@ func (this *IntermediateGroup) afterItems(context *Context) {
 	}
 }
 
+func (this *IntermediateGroup) leave() {
+	if this.registered {
+		this.registered = false
+		this.shared.leave()
+	}
+}
+
 func (this *IntermediateGroup) MarshalJSON() ([]byte, error) {
 	r := this.plan.MarshalBase(func(r map[string]interface{}) {
 		this.marshalTimes(r)
@@ -130,8 +195,107 @@ func (this *IntermediateGroup) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// send a stop/pause
+func (this *IntermediateGroup) SendAction(action opAction) {
+	this.chanSendAction(action)
+}
+
 func (this *IntermediateGroup) reopen(context *Context) bool {
 	rv := this.baseReopen(context)
 	this.groups = make(map[string]value.AnnotatedValue)
+	if this.shared != nil {
+		this.shared.reset()
+		this.registered = false
+	}
 	return rv
 }
+
+/*
+The groups shared by all the copies of a partitioned IntermediateGroup
+in a Parallel.
+Each copy merges the partial groups it receives into the partition of
+the group key. When a copy runs out of input it waits for the others,
+since the copies share their input, and then all the copies take turns
+at claiming partitions and sending their groups on.
+Copies that stop or fail leave all the same, so that the others do not
+wait for them.
+*/
+type groupMerge struct {
+	sync.Mutex
+	active     int
+	merged     chan struct{}
+	closed     bool
+	next       int32
+	partitions []groupPartition
+}
+
+type groupPartition struct {
+	sync.Mutex
+	groups map[string]value.AnnotatedValue
+}
+
+func newGroupMerge(partitions int) *groupMerge {
+	rv := &groupMerge{
+		partitions: make([]groupPartition, partitions),
+		merged:     make(chan struct{}),
+	}
+	for i := range rv.partitions {
+		rv.partitions[i].groups = make(map[string]value.AnnotatedValue)
+	}
+	return rv
+}
+
+func (this *groupMerge) register() {
+	this.Lock()
+	this.active++
+	this.Unlock()
+}
+
+// a copy has merged all its input
+func (this *groupMerge) leave() {
+	this.Lock()
+	this.active--
+	if this.active == 0 && !this.closed {
+		this.closed = true
+		close(this.merged)
+	}
+	this.Unlock()
+}
+
+// wait for the other copies to leave, returns false if stopped first
+func (this *groupMerge) wait(stop stopChannel) bool {
+	this.Lock()
+	merged := this.merged
+	this.Unlock()
+	select {
+	case <-merged:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (this *groupMerge) partition(gk string) *groupPartition {
+	return &this.partitions[util.SeaHashSum64([]byte(gk))%uint64(len(this.partitions))]
+}
+
+// the next partition to complete, if any are left
+func (this *groupMerge) claim() *groupPartition {
+	next := int(atomic.AddInt32(&this.next, 1)) - 1
+	if next >= len(this.partitions) {
+		return nil
+	}
+	return &this.partitions[next]
+}
+
+func (this *groupMerge) reset() {
+	this.Lock()
+	this.active = 0
+	this.merged = make(chan struct{})
+	this.closed = false
+	this.next = 0
+	for i := range this.partitions {
+		this.partitions[i].groups = make(map[string]value.AnnotatedValue)
+	}
+	this.Unlock()
+}
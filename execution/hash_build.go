@@ -0,0 +1,230 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/util"
+	"github.com/couchbase/query/value"
+)
+
+const (
+	_HASH_BUILD_PENDING = iota
+	_HASH_BUILD_RUNNING
+	_HASH_BUILD_DONE
+	_HASH_BUILD_FAILED
+)
+
+/*
+A partitioned hash table shared by all the copies of a hash join or nest
+in a Parallel.
+The first copy to start builds the table, while the others wait for it,
+then all copies probe the table concurrently. The last copy to finish
+drops it.
+A copy that starts after the table has been dropped has nothing to probe,
+since the copies share their input, and the others only finish once the
+input is exhausted.
+A build that panics counts as failed, and copies stopped while waiting
+give up on the table.
+*/
+type hashBuild struct {
+	sync.Mutex
+	state   int
+	built   chan struct{}
+	users   int
+	hashTab *util.PartitionedHashTable
+}
+
+func newHashBuild() *hashBuild {
+	return &hashBuild{built: make(chan struct{})}
+}
+
+// get the hash table, building it if needed
+// on success, the caller must release the hash table when done
+func (this *hashBuild) acquire(build func() (*util.PartitionedHashTable, bool), stop stopChannel,
+	context *Context) (*util.PartitionedHashTable, bool) {
+
+	this.Lock()
+	switch this.state {
+	case _HASH_BUILD_PENDING:
+		this.state = _HASH_BUILD_RUNNING
+		this.Unlock()
+		this.build(build, context)
+		this.Lock()
+	case _HASH_BUILD_RUNNING:
+		built := this.built
+		this.Unlock()
+		select {
+		case <-built:
+		case <-stop:
+			return nil, false
+		}
+		this.Lock()
+	}
+
+	hashTab := this.hashTab
+	ok := hashTab != nil
+	if ok {
+		this.users++
+	}
+	this.Unlock()
+	return hashTab, ok
+}
+
+func (this *hashBuild) build(build func() (*util.PartitionedHashTable, bool), context *Context) {
+	var hashTab *util.PartitionedHashTable
+	ok := false
+
+	// release the waiting copies whatever happens
+	defer func() {
+		if !ok {
+			dropHashBuild(hashTab, context)
+			hashTab = nil
+		}
+		this.Lock()
+		this.hashTab = hashTab
+		if ok {
+			this.state = _HASH_BUILD_DONE
+		} else {
+			this.state = _HASH_BUILD_FAILED
+		}
+		close(this.built)
+		this.Unlock()
+	}()
+	hashTab, ok = build()
+}
+
+// stop using the hash table, and drop it if no other copy is using it
+func (this *hashBuild) release(context *Context) {
+	this.Lock()
+	this.users--
+	if this.users > 0 || this.hashTab == nil {
+		this.Unlock()
+		return
+	}
+	hashTab := this.hashTab
+	this.hashTab = nil
+	this.Unlock()
+	dropHashBuild(hashTab, context)
+}
+
+func dropHashBuild(hashTab *util.PartitionedHashTable, context *Context) {
+	if hashTab != nil {
+		if context.UseRequestQuota() {
+			context.ReleaseValueSize(hashTab.Size())
+		}
+		hashTab.Drop()
+	}
+}
+
+func (this *hashBuild) reset() {
+	this.Lock()
+	this.state = _HASH_BUILD_PENDING
+	this.built = make(chan struct{})
+	this.users = 0
+	this.hashTab = nil
+	this.Unlock()
+}
+
+/*
+Build a partitioned hash table from the items of buildOp, with as many
+workers evaluating the build expressions and inserting into the table.
+*/
+func buildPartitionedHashTab(base *base, buildOp Operator, hashTab *util.PartitionedHashTable,
+	buildExprs expression.Expressions, workers int, context *Context) bool {
+
+	var wg sync.WaitGroup
+	var failed int32
+
+	items := make(chan value.AnnotatedValue, workers*2)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// keep draining after a failure, so that the reader never blocks
+			ok := true
+			buildVals := make(value.Values, len(buildExprs))
+			for item := range items {
+				if ok && atomic.LoadInt32(&failed) == 0 {
+					ok = putHashItem(hashTab, item, buildExprs, buildVals, context)
+					if !ok {
+						atomic.StoreInt32(&failed, 1)
+					}
+				}
+			}
+		}()
+	}
+
+	stopped := false
+	n := 1
+
+loop:
+	for atomic.LoadInt32(&failed) == 0 {
+		build_item, child, cont := base.getItemChildrenOp(buildOp)
+		if cont {
+			if build_item != nil {
+				items <- build_item
+			} else if child >= 0 {
+				n--
+			} else {
+				break loop
+			}
+		} else {
+			stopped = true
+			break loop
+		}
+	}
+	close(items)
+	wg.Wait()
+
+	if n > 0 {
+		notifyChildren(buildOp)
+		base.childrenWaitNoStop(buildOp)
+	}
+
+	return !stopped && atomic.LoadInt32(&failed) == 0
+}
+
+func putHashItem(hashTab *util.PartitionedHashTable, item value.AnnotatedValue,
+	buildExprs expression.Expressions, buildVals value.Values, context *Context) (ok bool) {
+	defer context.Recover(nil) // Recover from any panic
+
+	var err error
+	for i, be := range buildExprs {
+		buildVals[i], err = be.Evaluate(item, context)
+		if err != nil {
+			context.Error(errors.NewEvaluationError(err, "Hash Table Build Expression"))
+			return false
+		}
+	}
+
+	var buildVal value.Value
+	var size uint64
+
+	if len(buildVals) == 1 {
+		buildVal = buildVals[0]
+	} else {
+		buildVal = value.NewValue(buildVals)
+	}
+	if context.UseRequestQuota() {
+		size = item.Size()
+	}
+
+	err = hashTab.Put(buildVal, item, value.MarshalValue, value.EqualValue, size)
+	if err != nil {
+		context.Error(errors.NewHashTablePutError(err))
+		return false
+	}
+	return true
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/util"
 	"github.com/couchbase/query/value"
@@ -32,12 +33,16 @@ type SendInsert struct {
 	plan     *plan.SendInsert
 	keyspace datastore.Keyspace
 	limit    int64
+	schema   *expression.JSONSchema
+	triggers *rowTriggers
 }
 
 func NewSendInsert(plan *plan.SendInsert, context *Context) *SendInsert {
 	rv := _SENDINSERT_OP_POOL.Get().(*SendInsert)
 	rv.plan = plan
 	rv.limit = -1
+	rv.schema = nil
+	rv.triggers = nil
 	newBase(&rv.base, context)
 	rv.execPhase = INSERT
 	rv.output = rv
@@ -52,6 +57,8 @@ func (this *SendInsert) Copy() Operator {
 	rv := _SENDINSERT_OP_POOL.Get().(*SendInsert)
 	rv.plan = this.plan
 	rv.limit = this.limit
+	rv.schema = this.schema
+	rv.triggers = this.triggers
 	this.base.copy(&rv.base)
 	return rv
 }
@@ -79,6 +86,14 @@ func (this *SendInsert) beforeItems(context *Context, parent value.Value) bool {
 	if this.keyspace == nil {
 		return false
 	}
+	this.triggers = getTriggers(this.keyspace, datastore.TRIGGER_INSERT)
+
+	if this.plan.Validate() {
+		this.schema = getSchema(this.keyspace, context)
+		if this.schema == nil {
+			return false
+		}
+	}
 
 	if this.plan.Limit() == nil {
 		return true
@@ -188,6 +203,17 @@ func (this *SendInsert) flushBatch(context *Context) bool {
 			continue
 		}
 
+		replacement, ok := this.triggers.fireBefore(dpair.Name, val, nil, context)
+		if !ok {
+			continue
+		} else if replacement != nil {
+			val = replacement
+		}
+
+		if !validateDocument(this.schema, dpair.Name, val, context) {
+			continue
+		}
+
 		dpair.Options = adjustExpiration(options)
 		expiration, _ := getExpiration(dpair.Options)
 		dpair.Value = this.setDocumentKey(dpair.Name, value.NewAnnotatedValue(val), expiration, context)
@@ -215,12 +241,22 @@ func (this *SendInsert) flushBatch(context *Context) bool {
 		}
 	}
 
+	// a MERGE only sends the documents it inserts here
+	action := this.batch[0].GetAttachment("merge_action")
+
 	// Capture the inserted keys in case there is a RETURNING clause
 	for _, dp := range dpairs {
+		this.triggers.fireAfter(dp.Name, dp.Value, nil, context)
 		dv := value.NewAnnotatedValue(dp.Value)
 		av := value.NewAnnotatedValue(make(map[string]interface{}, 1))
 		av.ShareAnnotations(dv)
 		av.SetField(this.plan.Alias(), dv)
+		if this.plan.Images() {
+			setReturningImages(av, this.plan.Alias(), nil, dv)
+		}
+		if action != nil {
+			av.SetAttachment("merge_action", action)
+		}
 		if !this.sendItem(av) {
 			return false
 		}
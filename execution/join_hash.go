@@ -27,6 +27,8 @@ type HashJoin struct {
 	hashTab   *util.HashTable
 	buildVals value.Values
 	probeVals value.Values
+	shared    *hashBuild
+	partTab   *util.PartitionedHashTable
 }
 
 func NewHashJoin(plan *plan.HashJoin, context *Context, child Operator, aliasMap map[string]string) *HashJoin {
@@ -36,7 +38,14 @@ func NewHashJoin(plan *plan.HashJoin, context *Context, child Operator, aliasMap
 		aliasMap: aliasMap,
 	}
 
+	if plan.Partitions() > 1 {
+		rv.shared = newHashBuild()
+	}
+
 	newBase(&rv.base, context)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	rv.trackChildren(1)
 	rv.execPhase = HASH_JOIN
 	rv.output = rv
@@ -52,8 +61,12 @@ func (this *HashJoin) Copy() Operator {
 		plan:     this.plan,
 		child:    this.child.Copy(),
 		aliasMap: this.aliasMap,
+		shared:   this.shared,
 	}
 	this.base.copy(&rv.base)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	return rv
 }
 
@@ -62,6 +75,9 @@ func (this *HashJoin) PlanOp() plan.Operator {
 }
 
 func (this *HashJoin) RunOnce(context *Context, parent value.Value) {
+
+	// the last copy drops the shared hash table, even if one panics
+	defer this.dropHashTable(context)
 	this.runConsumer(this, context, parent)
 }
 
@@ -89,11 +105,28 @@ func (this *HashJoin) beforeItems(context *Context, parent value.Value) bool {
 		this.ansiFlags |= ANSI_ONCLAUSE_TRUE
 	}
 
+	this.probeVals = make(value.Values, len(this.plan.ProbeExprs()))
+
+	if this.shared != nil {
+		ok := false
+		this.partTab, ok = this.shared.acquire(func() (*util.PartitionedHashTable, bool) {
+			return this.buildPartitioned(context, parent)
+		}, this.stopCh(), context)
+		if !ok {
+			return false
+		}
+
+		if this.partTab.Count() == 0 && !this.plan.Outer() {
+			this.dropHashTable(context)
+			return false
+		}
+		return true
+	}
+
 	// build hash table
 	this.hashTab = util.NewHashTable(util.HASH_TABLE_FOR_HASH_JOIN)
 
 	this.buildVals = make(value.Values, len(this.plan.BuildExprs()))
-	this.probeVals = make(value.Values, len(this.plan.ProbeExprs()))
 
 	this.child.SetOutput(this.child)
 	this.child.SetInput(nil)
@@ -117,6 +150,23 @@ func (this *HashJoin) beforeItems(context *Context, parent value.Value) bool {
 	return true
 }
 
+// the first copy to start builds the shared hash table
+func (this *HashJoin) buildPartitioned(context *Context, parent value.Value) (*util.PartitionedHashTable, bool) {
+	hashTab := util.NewPartitionedHashTable(util.HASH_TABLE_FOR_HASH_JOIN, this.plan.Partitions())
+	workers := context.AdaptiveParallelism(util.MinInt(this.plan.Partitions(), context.MaxParallelism()))
+
+	this.child.SetOutput(this.child)
+	this.child.SetInput(nil)
+	this.child.SetParent(this)
+	this.child.SetStop(nil)
+
+	this.fork(this.child, context, parent)
+
+	ok := buildPartitionedHashTab(&(this.base), this.child, hashTab,
+		this.plan.BuildExprs(), workers, context)
+	return hashTab, ok
+}
+
 func buildHashTab(base *base, buildOp Operator, hashTab *util.HashTable,
 	buildExprs expression.Expressions, buildVals value.Values, context *Context) bool {
 	var err error
@@ -206,7 +256,16 @@ func (this *HashJoin) processItem(item value.AnnotatedValue, context *Context) b
 	if probeVal == nil {
 		return false
 	}
-	outVal, err = this.hashTab.Get(probeVal, value.MarshalValue, value.EqualValue)
+	var outVals []interface{}
+	if this.partTab != nil {
+		outVals, err = this.partTab.Lookup(probeVal, value.MarshalValue, value.EqualValue)
+		if len(outVals) > 0 {
+			outVal = outVals[0]
+			outVals = outVals[1:]
+		}
+	} else {
+		outVal, err = this.hashTab.Get(probeVal, value.MarshalValue, value.EqualValue)
+	}
 	if err != nil {
 		context.Error(errors.NewHashTableGetError(err))
 		return false
@@ -230,7 +289,15 @@ func (this *HashJoin) processItem(item value.AnnotatedValue, context *Context) b
 			return false
 		}
 
-		outVal, err = this.hashTab.GetNext()
+		if this.partTab != nil {
+			outVal = nil
+			if len(outVals) > 0 {
+				outVal = outVals[0]
+				outVals = outVals[1:]
+			}
+		} else {
+			outVal, err = this.hashTab.GetNext()
+		}
 		if err != nil {
 			context.Error(errors.NewHashTableGetError(err))
 			return false
@@ -258,6 +325,10 @@ func (this *HashJoin) afterItems(context *Context) {
 }
 
 func (this *HashJoin) dropHashTable(context *Context) {
+	if this.partTab != nil {
+		this.partTab = nil
+		this.shared.release(context)
+	}
 	if this.hashTab != nil {
 		if context.UseRequestQuota() {
 			context.ReleaseValueSize(this.hashTab.Size())
@@ -304,13 +375,20 @@ func (this *HashJoin) MarshalJSON() ([]byte, error) {
 }
 
 func (this *HashJoin) SendAction(action opAction) {
-	this.baseSendAction(action)
+	this.chanSendAction(action)
 	child := this.child
 	if child != nil {
 		child.SendAction(action)
 	}
 }
 
+func (this *HashJoin) reopen(context *Context) bool {
+	if this.shared != nil {
+		this.shared.reset()
+	}
+	return this.baseReopen(context)
+}
+
 func (this *HashJoin) Done() {
 	this.baseDone()
 	if this.child != nil {
@@ -0,0 +1,364 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type MergeJoin struct {
+	base
+	plan      *plan.MergeJoin
+	child     Operator
+	aliasMap  map[string]string
+	ansiFlags uint32
+	cursor    mergeCursor
+}
+
+func NewMergeJoin(plan *plan.MergeJoin, context *Context, child Operator, aliasMap map[string]string) *MergeJoin {
+	rv := &MergeJoin{
+		plan:     plan,
+		child:    child,
+		aliasMap: aliasMap,
+	}
+
+	newBase(&rv.base, context)
+	rv.trackChildren(1)
+	rv.execPhase = MERGE_JOIN
+	rv.output = rv
+	return rv
+}
+
+func (this *MergeJoin) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitMergeJoin(this)
+}
+
+func (this *MergeJoin) Copy() Operator {
+	rv := &MergeJoin{
+		plan:     this.plan,
+		child:    this.child.Copy(),
+		aliasMap: this.aliasMap,
+	}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *MergeJoin) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *MergeJoin) RunOnce(context *Context, parent value.Value) {
+	this.runConsumer(this, context, parent)
+}
+
+func (this *MergeJoin) beforeItems(context *Context, parent value.Value) bool {
+	if !context.assert(this.child != nil, "MERGE JOIN has no child") {
+		return false
+	}
+
+	// check for constant TRUE or FALSE onclause
+	onclause := this.plan.Onclause()
+	if onclause != nil {
+		cpred := onclause.Value()
+		if cpred != nil {
+			if cpred.Truth() {
+				this.ansiFlags |= ANSI_ONCLAUSE_TRUE
+			} else {
+				this.ansiFlags |= ANSI_ONCLAUSE_FALSE
+			}
+		} else {
+			onclause.EnableInlistHash(context)
+			SetSearchInfo(this.aliasMap, parent, context, onclause)
+		}
+	} else {
+		this.ansiFlags |= ANSI_ONCLAUSE_TRUE
+	}
+
+	this.child.SetOutput(this.child)
+	this.child.SetInput(nil)
+	this.child.SetParent(this)
+	this.child.SetStop(nil)
+
+	this.cursor.init(this.child, this.plan.RightKey(), this.plan.Descending())
+	this.fork(this.child, context, parent)
+
+	// position on the first right-hand side item; if there is none and this
+	// is not an outer join, no need to activate the left-hand side.
+	if !this.cursor.fetch(&this.base, "Merge Join", context) {
+		return false
+	}
+	if this.cursor.empty() && !this.plan.Outer() {
+		return false
+	}
+
+	return true
+}
+
+func (this *MergeJoin) processItem(item value.AnnotatedValue, context *Context) bool {
+	defer this.switchPhase(_EXECTIME)
+
+	leftKey, err := this.plan.LeftKey().Evaluate(item, context)
+	if err != nil {
+		context.Error(errors.NewEvaluationError(err, "Merge Join Left Key"))
+		return false
+	}
+
+	right_items, ok := this.cursor.match(&this.base, leftKey, "Merge Join", context)
+	if !ok {
+		return false
+	}
+
+	matched := false
+	for _, right_item := range right_items {
+		var match bool
+		var joined value.AnnotatedValue
+		match, ok, joined = processAnsiExec(item, right_item, this.plan.Onclause(),
+			this.plan.RightAliases(), this.ansiFlags, context, "join")
+		if match && ok {
+			matched = true
+			ok = this.checkSendItem(joined, func() uint64 {
+				return joined.Size()
+			}, true, this.plan.Filter(), context)
+		} else if joined != nil {
+			joined.Recycle()
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	if this.plan.Outer() && !matched {
+		return this.checkSendItem(item, func() uint64 {
+			return 0
+		}, false, this.plan.Filter(), context)
+	} else if context.UseRequestQuota() {
+		context.ReleaseValueSize(item.Size())
+	}
+
+	return true
+}
+
+func (this *MergeJoin) afterItems(context *Context) {
+	this.cursor.close(&this.base, context)
+	onclause := this.plan.Onclause()
+	if onclause != nil {
+		onclause.ResetMemory(context)
+	}
+}
+
+func (this *MergeJoin) checkSendItem(av value.AnnotatedValue, quotaFunc func() uint64, recycle bool, filter expression.Expression, context *Context) bool {
+	if filter != nil {
+		result, err := filter.Evaluate(av, context)
+		if err != nil {
+			context.Error(errors.NewEvaluationError(err, "merge join filter"))
+			if recycle {
+				av.Recycle()
+			}
+			return false
+		}
+		if !result.Truth() {
+			if recycle {
+				av.Recycle()
+			}
+			return true
+		}
+	}
+	if context.UseRequestQuota() && context.TrackValueSize(quotaFunc()) {
+		context.Error(errors.NewMemoryQuotaExceededError())
+		if recycle {
+			av.Recycle()
+		}
+		return false
+
+	}
+	return this.sendItem(av)
+}
+
+func (this *MergeJoin) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+		r["~child"] = this.child
+	})
+	return json.Marshal(r)
+}
+
+func (this *MergeJoin) SendAction(action opAction) {
+	this.baseSendAction(action)
+	child := this.child
+	if child != nil {
+		child.SendAction(action)
+	}
+}
+
+func (this *MergeJoin) Done() {
+	this.baseDone()
+	if this.child != nil {
+		child := this.child
+		this.child = nil
+		child.Done()
+	}
+}
+
+/*
+mergeCursor walks the ordered right-hand side of a merge join or nest.
+It keeps one item of lookahead and the run of items sharing the key
+last matched, so that consecutive left-hand side items with the same
+key see the same run. Items with a NULL or MISSING key never match and
+are skipped.
+*/
+type mergeCursor struct {
+	child      Operator
+	rightKey   expression.Expression
+	descending bool
+	next       value.AnnotatedValue
+	nextKey    value.Value
+	group      value.AnnotatedValues
+	groupKey   value.Value
+	done       bool
+	notified   bool
+}
+
+func (this *mergeCursor) init(child Operator, rightKey expression.Expression, descending bool) {
+	*this = mergeCursor{
+		child:      child,
+		rightKey:   rightKey,
+		descending: descending,
+	}
+}
+
+// no right-hand side items left to match
+func (this *mergeCursor) empty() bool {
+	return this.next == nil && this.groupKey == nil && this.done
+}
+
+// compare in the direction of the ordering of both sides
+func (this *mergeCursor) compare(left, right value.Value) int {
+	cmp := left.Collate(right)
+	if this.descending {
+		cmp = -cmp
+	}
+	return cmp
+}
+
+// returns the right-hand side items with the given key
+func (this *mergeCursor) match(base *base, key value.Value, op string, context *Context) (value.AnnotatedValues, bool) {
+	if key.Type() <= value.NULL {
+		return nil, true
+	}
+
+	if this.groupKey != nil {
+		cmp := this.compare(key, this.groupKey)
+		if cmp == 0 {
+			return this.group, true
+		} else if cmp < 0 {
+			return nil, true
+		}
+		this.releaseGroup(context)
+	}
+
+	// skip right-hand side items preceding the key
+	for {
+		if this.next == nil {
+			if this.done {
+				return nil, true
+			}
+			if !this.fetch(base, op, context) {
+				return nil, false
+			}
+			continue
+		}
+
+		cmp := this.compare(key, this.nextKey)
+		if cmp < 0 {
+			return nil, true
+		} else if cmp == 0 {
+			break
+		}
+		releaseMergeItem(this.next, context)
+		this.next = nil
+	}
+
+	// gather the run of right-hand side items with the key
+	this.groupKey = this.nextKey
+	for this.next != nil && this.compare(this.groupKey, this.nextKey) == 0 {
+		this.group = append(this.group, this.next)
+		this.next = nil
+		if !this.fetch(base, op, context) {
+			return nil, false
+		}
+	}
+
+	return this.group, true
+}
+
+// read the next right-hand side item with a valued key, if any
+func (this *mergeCursor) fetch(base *base, op string, context *Context) bool {
+	for !this.done {
+		item, child, cont := base.getItemChildrenOp(this.child)
+		if !cont {
+			return false
+		}
+		if item != nil {
+			key, err := this.rightKey.Evaluate(item, context)
+			if err != nil {
+				context.Error(errors.NewEvaluationError(err, op+" Right Key"))
+				return false
+			}
+			if key.Type() <= value.NULL {
+				releaseMergeItem(item, context)
+				continue
+			}
+			this.next = item
+			this.nextKey = key
+			return true
+		} else if child >= 0 {
+			this.notified = true
+		} else {
+			this.done = true
+		}
+	}
+	return true
+}
+
+func (this *mergeCursor) releaseGroup(context *Context) {
+	for i, item := range this.group {
+		releaseMergeItem(item, context)
+		this.group[i] = nil
+	}
+	this.group = this.group[:0]
+	this.groupKey = nil
+}
+
+// stop the right-hand side if the left-hand side finished first
+func (this *mergeCursor) close(base *base, context *Context) {
+	if this.child == nil {
+		return
+	}
+	this.releaseGroup(context)
+	if this.next != nil {
+		releaseMergeItem(this.next, context)
+		this.next = nil
+	}
+	if !this.notified {
+		notifyChildren(this.child)
+		base.childrenWaitNoStop(this.child)
+	}
+	this.child = nil
+}
+
+func releaseMergeItem(item value.AnnotatedValue, context *Context) {
+	if context.UseRequestQuota() {
+		context.ReleaseValueSize(item.Size())
+	}
+}
@@ -0,0 +1,127 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+// {alias: {"k": keys[i], "i": i}}, leaving k out for MISSING
+func testOrderedScan(alias string, keys ...interface{}) plan.Operator {
+	docs := make([]interface{}, len(keys))
+	for i, k := range keys {
+		doc := map[string]interface{}{"i": i}
+		if k != value.MISSING_VALUE {
+			doc["k"] = k
+		}
+		docs[i] = doc
+	}
+	return plan.NewExpressionScan(expression.NewConstant(docs), alias, false, nil, -1, -1, -1, -1)
+}
+
+func reverseKeys(keys []interface{}) []interface{} {
+	rv := make([]interface{}, len(keys))
+	for i, k := range keys {
+		rv[len(keys)-1-i] = k
+	}
+	return rv
+}
+
+var _MERGE_LEFT_KEYS = []interface{}{value.MISSING_VALUE, nil, 1, 2, 2, 3, 5}
+var _MERGE_RIGHT_KEYS = []interface{}{nil, 2, 2, 3, 4, 5, 5, 6}
+
+func TestMergeJoin(t *testing.T) {
+	onclause := expression.NewEq(testField("x", "k"), testField("y", "k"))
+
+	var tests = []struct {
+		outer      bool
+		descending bool
+		expected   int
+	}{
+		// 2 twice by 2, 3 by 3 and 5 by 5 twice
+		{false, false, 7},
+		{false, true, 7},
+		// and the unmatched MISSING, NULL and 1
+		{true, false, 10},
+		{true, true, 10},
+	}
+
+	for _, test := range tests {
+		left, right := _MERGE_LEFT_KEYS, _MERGE_RIGHT_KEYS
+		if test.descending {
+			left, right = reverseKeys(left), reverseKeys(right)
+		}
+		join := plan.NewMergeJoin(algebra.NewAnsiJoin(nil, test.outer, nil, onclause),
+			testOrderedScan("y", right...), testField("x", "k"), testField("y", "k"),
+			test.descending, []string{"y"}, nil, -1, -1, -1, -1)
+
+		results := runParallelTest(t, testOrderedScan("x", left...), join)
+		if len(results) != test.expected {
+			t.Errorf("Outer %v, descending %v: expected %d results, got %v",
+				test.outer, test.descending, test.expected, results)
+			continue
+		}
+
+		last := -1
+		for _, r := range results {
+			row := value.NewValue(r)
+			x, _ := row.Field("x")
+			i, _ := x.Field("i")
+			xk, _ := x.Field("k")
+			y, ok := row.Field("y")
+			if ok {
+				yk, _ := y.Field("k")
+				if !xk.Equals(yk).Truth() {
+					t.Errorf("Unexpected join result %v", r)
+				}
+			} else if !test.outer {
+				t.Errorf("Unexpected unmatched result %v", r)
+			}
+
+			// the order of the left-hand side is kept
+			if n := int(value.AsNumberValue(i).Int64()); n < last {
+				t.Errorf("Left-hand side out of order in %v", results)
+				break
+			} else {
+				last = n
+			}
+		}
+	}
+}
+
+func TestMergeNest(t *testing.T) {
+	onclause := expression.NewEq(testField("x", "k"), testField("y", "k"))
+	nest := plan.NewMergeNest(algebra.NewAnsiNest(nil, true, nil, onclause),
+		testOrderedScan("y", _MERGE_RIGHT_KEYS...), testField("x", "k"), testField("y", "k"),
+		false, "y", nil, -1, -1, -1, -1)
+
+	results := runParallelTest(t, testOrderedScan("x", _MERGE_LEFT_KEYS...), nest)
+	if len(results) != len(_MERGE_LEFT_KEYS) {
+		t.Fatalf("Expected %d results, got %v", len(_MERGE_LEFT_KEYS), results)
+	}
+
+	expected := map[int]int{3: 2, 4: 2, 5: 1, 6: 2}
+	for _, r := range results {
+		row := value.NewValue(r)
+		i, _ := row.Field("x")
+		i, _ = i.Field("i")
+		n := 0
+		if y, ok := row.Field("y"); ok && y.Type() == value.ARRAY {
+			n = len(y.Actual().([]interface{}))
+		}
+		if n != expected[int(value.AsNumberValue(i).Int64())] {
+			t.Errorf("Unexpected nest result %v", r)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	goerrors "errors"
+	"strings"
+	"time"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/scheduler"
+	"github.com/couchbase/query/value"
+)
+
+// scheduled refreshes are tasks of this class, named after the view
+const (
+	_MV_CLASS   = "materialized_view"
+	_MV_REFRESH = "refresh"
+)
+
+/*
+Refresh a materialized view. Views that are simple aggregations are
+refreshed incrementally unless a full refresh is requested: only source
+documents with a CAS above the view watermark are aggregated, and the
+results are merged into the existing view documents. Merging is only
+right for documents that have been added, so an incremental refresh
+that finds documents changed or removed rebuilds the view instead.
+Full refreshes empty the target collection before populating it, and
+are not atomic.
+*/
+func refreshMaterializedView(view *datastore.MaterializedView, mode string, context *Context) errors.Error {
+	incremental := view.Incremental()
+	switch mode {
+	case algebra.MV_REFRESH_FULL:
+		incremental = false
+	case algebra.MV_REFRESH_INCREMENTAL:
+		if !incremental {
+			return errors.NewMaterializedViewRefreshError(view.Name,
+				goerrors.New("only simple aggregations can be refreshed incrementally"))
+		}
+	}
+
+	view.BeginRefresh()
+	defer view.EndRefresh()
+
+	// views being created are stored once populated, and dropped ones not at all
+	store := datastore.GetMaterializedViewStore()
+	current, stored := store.View(view.Name)
+	stored = stored && current == view
+	if stored {
+		claimed, err := store.ClaimRefresh(view)
+		if err != nil {
+			return errors.NewMaterializedViewStoreError(view.Name, err)
+		} else if !claimed {
+			return errors.NewMaterializedViewRefreshError(view.Name, errRefreshClaimed)
+		}
+	}
+
+	// the claim on the view must still hold when the target is changed
+	claim := func() error {
+		if !stored {
+			return nil
+		}
+		claimed, err := store.RenewRefresh(view)
+		if err == nil && !claimed {
+			err = errRefreshTakenOver
+		}
+		return err
+	}
+
+	watermark, _ := view.Refreshed()
+	if watermark == nil {
+		incremental = false
+	}
+
+	ctx := context.NewQueryContext(view.QueryContext, false).(*Context)
+	refreshed := time.Now()
+	var sourceCount int64
+	var err error
+	if !view.Incremental() {
+		err = fullRefresh(view, claim, ctx)
+	} else {
+		watermark, sourceCount, err = aggregationRefresh(view, watermark, view.SourceCount(), incremental, claim, ctx)
+	}
+	if err != nil {
+		if stored {
+			store.ReleaseRefresh(view, false)
+		}
+		return errors.NewMaterializedViewRefreshError(view.Name, err)
+	}
+	view.SetRefreshed(watermark, sourceCount, refreshed)
+
+	if stored {
+		released, err := store.ReleaseRefresh(view, true)
+		if err == nil && !released {
+			err = errRefreshTakenOver
+		}
+		if err != nil {
+			return errors.NewMaterializedViewStoreError(view.Name, err)
+		}
+	}
+	return nil
+}
+
+var errRefreshClaimed = goerrors.New("the view is being refreshed by another query node")
+var errRefreshTakenOver = goerrors.New("the view has been taken over by another query node")
+
+func fullRefresh(view *datastore.MaterializedView, claim func() error, context *Context) error {
+	err := claim()
+	if err != nil {
+		return err
+	}
+	_, _, err = context.EvaluateStatement("DELETE FROM "+view.Target, nil, nil, false, false)
+	if err != nil {
+		return err
+	}
+	_, _, err = context.EvaluateStatement("INSERT INTO "+view.Target+" (KEY UUID(), VALUE _v) SELECT _v FROM ("+
+		view.Text+") AS _v", nil, nil, false, false)
+	return err
+}
+
+/*
+Aggregate the source documents, or just those above the watermark for
+incremental refreshes, and store the results keyed by their group keys.
+Returns the new watermark and the number of source documents the view
+aggregates.
+
+If the source now holds as many documents as the view aggregated plus
+those above the watermark, every document above the watermark is new,
+and none has been removed: an updated document would be counted in
+both, and a removed one in neither. Otherwise the view is rebuilt.
+Documents changing while the counts are taken can only cause a rebuild,
+as only documents up to the highest CAS counted are aggregated.
+
+Merging aggregates twice would count documents twice, so the target is
+only changed under the claim of the refresh.
+*/
+func aggregationRefresh(view *datastore.MaterializedView, watermark value.Value, sourceCount int64,
+	incremental bool, claim func() error, context *Context) (value.Value, int64, error) {
+
+	alias := "`" + view.Alias + "`"
+	cas := "META(" + alias + ").cas"
+	from := " FROM " + view.Source + " AS " + alias
+
+	var high value.Value
+	if incremental {
+		query := "SELECT COUNT(1) AS total, SUM(CASE WHEN " + cas + " > $watermark THEN 1 ELSE 0 END) AS fresh, MAX(" +
+			cas + ") AS high" + from
+		if view.Where != "" {
+			query += " WHERE " + view.Where
+		}
+		rows, _, err := context.EvaluateStatement(query, map[string]value.Value{"watermark": watermark},
+			nil, false, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		var total, fresh int64
+		if counts := rows.Actual().([]interface{}); len(counts) > 0 {
+			row := value.NewValue(counts[0])
+			total = countField(row, "total")
+			fresh = countField(row, "fresh")
+			high, _ = row.Field("high")
+		}
+		if total != sourceCount+fresh {
+			incremental = false
+		} else if fresh == 0 {
+			return watermark, sourceCount, nil
+		}
+	}
+
+	if !incremental {
+		err := claim()
+		if err != nil {
+			return nil, 0, err
+		}
+		_, _, err = context.EvaluateStatement("DELETE FROM "+view.Target, nil, nil, false, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		watermark = nil
+		sourceCount = 0
+	}
+
+	fields := make([]string, len(view.Fields))
+	for i, f := range view.Fields {
+		fields[i] = "\"" + f.Name + "\": " + f.Expr
+	}
+	query := "SELECT ENCODE_JSON([" + strings.Join(view.Keys, ", ") + "]) AS _k, {" + strings.Join(fields, ", ") +
+		"} AS _v, MAX(" + cas + ") AS _cas, COUNT(1) AS _n" + from
+
+	var conds []string
+	var args map[string]value.Value
+	if view.Where != "" {
+		conds = append(conds, view.Where)
+	}
+	if incremental {
+		conds = append(conds, cas+" > $watermark", cas+" <= $high")
+		args = map[string]value.Value{"watermark": watermark, "high": high}
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(view.Keys) > 0 {
+		query += " GROUP BY " + strings.Join(view.Keys, ", ")
+	}
+
+	rows, _, err := context.EvaluateStatement(query, args, nil, false, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := rows.Actual().([]interface{})
+	for _, r := range results {
+		row := value.NewValue(r)
+		sourceCount += countField(row, "_n")
+		cas, _ := row.Field("_cas")
+		if cas.Type() == value.NUMBER && (watermark == nil || cas.Collate(watermark) > 0) {
+			watermark = cas
+		}
+	}
+	if len(results) == 0 {
+		return watermark, sourceCount, nil
+	}
+
+	if incremental {
+		results, err = mergeAggregates(view, results, context)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err = claim()
+	if err != nil {
+		return nil, 0, err
+	}
+	_, _, err = context.EvaluateStatement("UPSERT INTO "+view.Target+" (KEY _k, VALUE _v) SELECT d._k AS _k, d._v AS _v FROM $rows AS d",
+		map[string]value.Value{"rows": value.NewValue(results)}, nil, false, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	return watermark, sourceCount, nil
+}
+
+// counts are NULL over no documents
+func countField(row value.Value, field string) int64 {
+	v, _ := row.Field(field)
+	if v.Type() != value.NUMBER {
+		return 0
+	}
+	return value.AsNumberValue(v).Int64()
+}
+
+/*
+Merge the aggregates of newly added documents into the view documents
+they update.
+*/
+func mergeAggregates(view *datastore.MaterializedView, rows []interface{}, context *Context) ([]interface{}, error) {
+	keys := make([]interface{}, len(rows))
+	for i, r := range rows {
+		keys[i], _ = value.NewValue(r).Field("_k")
+	}
+
+	existing, _, err := context.EvaluateStatement("SELECT META(t).id AS _k, t AS _v FROM "+view.Target+" AS t USE KEYS $keys",
+		map[string]value.Value{"keys": value.NewValue(keys)}, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]value.Value, len(rows))
+	for _, e := range existing.Actual().([]interface{}) {
+		e := value.NewValue(e)
+		k, _ := e.Field("_k")
+		v, _ := e.Field("_v")
+		if k.Type() == value.STRING && v.Type() == value.OBJECT {
+			docs[k.ToString()] = v
+		}
+	}
+
+	rv := make([]interface{}, len(rows))
+	for i, r := range rows {
+		row := value.NewValue(r)
+		k, _ := row.Field("_k")
+		v, _ := row.Field("_v")
+		old, ok := docs[k.ToString()]
+		if ok {
+			merged := value.NewValue(map[string]interface{}{})
+			for _, f := range view.Fields {
+				n, _ := v.Field(f.Name)
+				o, _ := old.Field(f.Name)
+				merged.SetField(f.Name, mergeAggregate(f.Aggregate, o, n))
+			}
+			v = merged
+		}
+		rv[i] = map[string]interface{}{"_k": k, "_v": v}
+	}
+	return rv, nil
+}
+
+// NULL and MISSING don't contribute, as for the aggregates themselves
+func mergeAggregate(aggregate string, old, next value.Value) value.Value {
+	if old.Type() <= value.NULL {
+		return next
+	} else if next.Type() <= value.NULL {
+		return old
+	}
+
+	switch aggregate {
+	case "count", "sum":
+		if old.Type() == value.NUMBER && next.Type() == value.NUMBER {
+			return value.AsNumberValue(old).Add(value.AsNumberValue(next))
+		}
+	case "min":
+		if old.Collate(next) < 0 {
+			return old
+		}
+	case "max":
+		if old.Collate(next) > 0 {
+			return old
+		}
+	}
+	return next
+}
+
+/*
+Refresh a view every interval, for as long as it exists. Every node
+schedules the views it learns of from the store, so that views keep
+being refreshed whichever nodes come and go, and scheduled refreshes
+leave alone views another node has refreshed in the last half interval.
+*/
+func scheduleMaterializedView(view *datastore.MaterializedView, context *Context) errors.Error {
+	err := scheduler.ScheduleRepeatingTask(view.Name, _MV_CLASS, _MV_REFRESH, view.Interval,
+		func(context scheduler.Context, parms interface{}) (interface{}, []errors.Error) {
+			view := parms.(*datastore.MaterializedView)
+			current, ok := datastore.GetMaterializedViewStore().View(view.Name)
+			if !ok || current != view {
+				return nil, nil
+			}
+			if _, lastRefresh := view.Refreshed(); time.Since(lastRefresh) < view.Interval/2 {
+				return nil, nil
+			}
+			err := refreshMaterializedView(view, "", context.(*Context))
+
+			// another node got there first
+			if err != nil && err.GetICause() == errRefreshClaimed {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, []errors.Error{err}
+			}
+			return nil, nil
+		}, nil, view, context)
+
+	// already scheduled as the store told us of the view
+	if err != nil && err.Code() == errors.E_DUPLICATE_TASK {
+		return nil
+	}
+	return err
+}
+
+func unscheduleMaterializedView(view *datastore.MaterializedView) errors.Error {
+	id, err := scheduler.TaskId(view.Name, _MV_CLASS, _MV_REFRESH)
+	if err != nil {
+		return err
+	}
+	err = scheduler.DeleteTask(id)
+	if err != nil && err.Code() == errors.E_TASK_NOT_FOUND {
+		return nil
+	}
+	return err
+}
+
+/*
+Views this node learns of from the store, rather than creates, are
+refreshed in a context the server sets up, with the credentials of the
+query service.
+*/
+var mvContext func() *Context
+
+func SetMaterializedViewContext(newContext func() *Context) {
+	mvContext = newContext
+}
+
+func init() {
+	datastore.SetMaterializedViewObserver(observeMaterializedView)
+}
+
+func observeMaterializedView(view *datastore.MaterializedView, dropped bool) {
+	var err errors.Error
+	if view.Interval == 0 {
+		return
+	} else if dropped {
+		err = unscheduleMaterializedView(view)
+	} else if mvContext != nil {
+		err = scheduleMaterializedView(view, mvContext())
+	}
+	if err != nil {
+		logging.Errorf("Unable to change the refresh schedule of materialized view %v: %v", view.Name, err)
+	}
+}
@@ -0,0 +1,121 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type CreateMaterializedView struct {
+	base
+	plan *plan.CreateMaterializedView
+}
+
+func NewCreateMaterializedView(plan *plan.CreateMaterializedView, context *Context) *CreateMaterializedView {
+	rv := &CreateMaterializedView{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *CreateMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateMaterializedView(this)
+}
+
+func (this *CreateMaterializedView) Copy() Operator {
+	rv := &CreateMaterializedView{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *CreateMaterializedView) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *CreateMaterializedView) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		// the definition is shared by prepared executions, so the view gets its own
+		def := this.plan.View()
+		view := &datastore.MaterializedView{
+			Name:         def.Name,
+			Target:       def.Target,
+			QueryContext: context.QueryContext(),
+			Text:         def.Text,
+			Source:       def.Source,
+			Alias:        def.Alias,
+			Where:        def.Where,
+			Keys:         def.Keys,
+			Fields:       def.Fields,
+			Interval:     def.Interval,
+			QueryRewrite: def.QueryRewrite,
+		}
+
+		store := datastore.GetMaterializedViewStore()
+		if _, ok := store.View(view.Name); ok {
+			if this.plan.FailIfExists() {
+				context.Error(errors.NewDuplicateMaterializedViewError(view.Name))
+			}
+			return
+		}
+
+		// the view only exists once it has been populated, so that
+		// queries are never rewritten to use an empty target
+		err := refreshMaterializedView(view, "", context)
+		if err != nil {
+			context.Error(err)
+			return
+		}
+
+		this.switchPhase(_SERVTIME)
+		added, serr := store.AddView(view)
+		this.switchPhase(_EXECTIME)
+		if serr != nil {
+			context.Error(errors.NewMaterializedViewStoreError(view.Name, serr))
+			return
+		} else if !added {
+			if this.plan.FailIfExists() {
+				context.Error(errors.NewDuplicateMaterializedViewError(view.Name))
+			}
+			return
+		}
+
+		if view.Interval > 0 {
+			err = scheduleMaterializedView(view, context)
+			if err != nil {
+				context.Error(err)
+			}
+		}
+	})
+}
+
+func (this *CreateMaterializedView) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
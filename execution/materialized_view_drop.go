@@ -0,0 +1,79 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type DropMaterializedView struct {
+	base
+	plan *plan.DropMaterializedView
+}
+
+func NewDropMaterializedView(plan *plan.DropMaterializedView, context *Context) *DropMaterializedView {
+	rv := &DropMaterializedView{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *DropMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropMaterializedView(this)
+}
+
+func (this *DropMaterializedView) Copy() Operator {
+	rv := &DropMaterializedView{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *DropMaterializedView) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *DropMaterializedView) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		this.switchPhase(_SERVTIME)
+		view, serr := datastore.GetMaterializedViewStore().DropView(this.plan.Name())
+		this.switchPhase(_EXECTIME)
+		if serr != nil {
+			context.Error(errors.NewMaterializedViewStoreError(this.plan.Name(), serr))
+			return
+		} else if view == nil && this.plan.FailIfNotExists() {
+			context.Error(errors.NewMaterializedViewNotFoundError(this.plan.Name()))
+		}
+	})
+}
+
+func (this *DropMaterializedView) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
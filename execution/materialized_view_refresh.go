@@ -0,0 +1,82 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type RefreshMaterializedView struct {
+	base
+	plan *plan.RefreshMaterializedView
+}
+
+func NewRefreshMaterializedView(plan *plan.RefreshMaterializedView, context *Context) *RefreshMaterializedView {
+	rv := &RefreshMaterializedView{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *RefreshMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitRefreshMaterializedView(this)
+}
+
+func (this *RefreshMaterializedView) Copy() Operator {
+	rv := &RefreshMaterializedView{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *RefreshMaterializedView) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *RefreshMaterializedView) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		view, ok := datastore.GetMaterializedViewStore().View(this.plan.Name())
+		if !ok {
+			context.Error(errors.NewMaterializedViewNotFoundError(this.plan.Name()))
+			return
+		}
+
+		this.switchPhase(_SERVTIME)
+		err := refreshMaterializedView(view, this.plan.Mode(), context)
+		this.switchPhase(_EXECTIME)
+		if err != nil {
+			context.Error(err)
+		}
+	})
+}
+
+func (this *RefreshMaterializedView) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
@@ -251,6 +251,7 @@ func (this *Merge) processAction(item value.AnnotatedValue, context *Context,
 				}
 				this.matched[key] = true
 				check = false
+				item1.SetAttachment("merge_action", "UPDATE")
 				ok = this.sendItemOp(update.Input(), item1)
 			} else if delete == nil {
 				item.Recycle()
@@ -270,6 +271,7 @@ func (this *Merge) processAction(item value.AnnotatedValue, context *Context,
 						return false
 					}
 					this.matched[key] = true
+					item.SetAttachment("merge_action", "DELETE")
 					ok = this.sendItemOp(delete.Input(), item)
 				} else {
 					item.Recycle()
@@ -310,6 +312,7 @@ func (this *Merge) processAction(item value.AnnotatedValue, context *Context,
 					context.Error(errors.NewMergeMultiInsertError(key))
 					return false
 				}
+				item.SetAttachment("merge_action", "INSERT")
 				ok = this.sendItemOp(insert.Input(), item)
 				this.inserted[key] = true
 			} else {
@@ -26,6 +26,8 @@ type HashNest struct {
 	hashTab   *util.HashTable
 	buildVals value.Values
 	probeVals value.Values
+	shared    *hashBuild
+	partTab   *util.PartitionedHashTable
 }
 
 func NewHashNest(plan *plan.HashNest, context *Context, child Operator, aliasMap map[string]string) *HashNest {
@@ -35,7 +37,14 @@ func NewHashNest(plan *plan.HashNest, context *Context, child Operator, aliasMap
 		aliasMap: aliasMap,
 	}
 
+	if plan.Partitions() > 1 {
+		rv.shared = newHashBuild()
+	}
+
 	newBase(&rv.base, context)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	rv.trackChildren(1)
 	rv.execPhase = HASH_NEST
 	rv.output = rv
@@ -51,8 +60,12 @@ func (this *HashNest) Copy() Operator {
 		plan:     this.plan,
 		child:    this.child.Copy(),
 		aliasMap: this.aliasMap,
+		shared:   this.shared,
 	}
 	this.base.copy(&rv.base)
+	if rv.shared != nil {
+		rv.newStopChannel()
+	}
 	return rv
 }
 
@@ -61,6 +74,9 @@ func (this *HashNest) PlanOp() plan.Operator {
 }
 
 func (this *HashNest) RunOnce(context *Context, parent value.Value) {
+
+	// the last copy drops the shared hash table, even if one panics
+	defer this.dropHashTable(context)
 	this.runConsumer(this, context, parent)
 }
 
@@ -85,11 +101,20 @@ func (this *HashNest) beforeItems(context *Context, parent value.Value) bool {
 		SetSearchInfo(this.aliasMap, parent, context, this.plan.Onclause())
 	}
 
+	this.probeVals = make(value.Values, len(this.plan.ProbeExprs()))
+
+	if this.shared != nil {
+		ok := false
+		this.partTab, ok = this.shared.acquire(func() (*util.PartitionedHashTable, bool) {
+			return this.buildPartitioned(context, parent)
+		}, this.stopCh(), context)
+		return ok
+	}
+
 	// build hash table
 	this.hashTab = util.NewHashTable(util.HASH_TABLE_FOR_HASH_JOIN)
 
 	this.buildVals = make(value.Values, len(this.plan.BuildExprs()))
-	this.probeVals = make(value.Values, len(this.plan.ProbeExprs()))
 
 	this.child.SetOutput(this.child)
 	this.child.SetInput(nil)
@@ -102,6 +127,23 @@ func (this *HashNest) beforeItems(context *Context, parent value.Value) bool {
 		this.plan.BuildExprs(), this.buildVals, context)
 }
 
+// the first copy to start builds the shared hash table
+func (this *HashNest) buildPartitioned(context *Context, parent value.Value) (*util.PartitionedHashTable, bool) {
+	hashTab := util.NewPartitionedHashTable(util.HASH_TABLE_FOR_HASH_JOIN, this.plan.Partitions())
+	workers := context.AdaptiveParallelism(util.MinInt(this.plan.Partitions(), context.MaxParallelism()))
+
+	this.child.SetOutput(this.child)
+	this.child.SetInput(nil)
+	this.child.SetParent(this)
+	this.child.SetStop(nil)
+
+	this.fork(this.child, context, parent)
+
+	ok := buildPartitionedHashTab(&(this.base), this.child, hashTab,
+		this.plan.BuildExprs(), workers, context)
+	return hashTab, ok
+}
+
 func (this *HashNest) processItem(item value.AnnotatedValue, context *Context) bool {
 	defer this.switchPhase(_EXECTIME)
 
@@ -114,7 +156,16 @@ func (this *HashNest) processItem(item value.AnnotatedValue, context *Context) b
 	if probeVal == nil {
 		return false
 	}
-	outVal, err = this.hashTab.Get(probeVal, value.MarshalValue, value.EqualValue)
+	var outVals []interface{}
+	if this.partTab != nil {
+		outVals, err = this.partTab.Lookup(probeVal, value.MarshalValue, value.EqualValue)
+		if len(outVals) > 0 {
+			outVal = outVals[0]
+			outVals = outVals[1:]
+		}
+	} else {
+		outVal, err = this.hashTab.Get(probeVal, value.MarshalValue, value.EqualValue)
+	}
 	if err != nil {
 		context.Error(errors.NewHashTableGetError(err))
 		return false
@@ -133,7 +184,15 @@ func (this *HashNest) processItem(item value.AnnotatedValue, context *Context) b
 			return false
 		}
 
-		outVal, err = this.hashTab.GetNext()
+		if this.partTab != nil {
+			outVal = nil
+			if len(outVals) > 0 {
+				outVal = outVals[0]
+				outVals = outVals[1:]
+			}
+		} else {
+			outVal, err = this.hashTab.GetNext()
+		}
 		if err != nil {
 			context.Error(errors.NewHashTableGetError(err))
 			return false
@@ -181,6 +240,10 @@ func (this *HashNest) afterItems(context *Context) {
 }
 
 func (this *HashNest) dropHashTable(context *Context) {
+	if this.partTab != nil {
+		this.partTab = nil
+		this.shared.release(context)
+	}
 	if this.hashTab != nil {
 		if context.UseRequestQuota() {
 			context.ReleaseValueSize(this.hashTab.Size())
@@ -199,13 +262,20 @@ func (this *HashNest) MarshalJSON() ([]byte, error) {
 }
 
 func (this *HashNest) SendAction(action opAction) {
-	this.baseSendAction(action)
+	this.chanSendAction(action)
 	child := this.child
 	if child != nil {
 		child.SendAction(action)
 	}
 }
 
+func (this *HashNest) reopen(context *Context) bool {
+	if this.shared != nil {
+		this.shared.reset()
+	}
+	return this.baseReopen(context)
+}
+
 func (this *HashNest) Done() {
 	this.baseDone()
 	if this.child != nil {
@@ -0,0 +1,193 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type MergeNest struct {
+	base
+	plan      *plan.MergeNest
+	child     Operator
+	aliasMap  map[string]string
+	ansiFlags uint32
+	cursor    mergeCursor
+}
+
+func NewMergeNest(plan *plan.MergeNest, context *Context, child Operator, aliasMap map[string]string) *MergeNest {
+	rv := &MergeNest{
+		plan:     plan,
+		child:    child,
+		aliasMap: aliasMap,
+	}
+
+	newBase(&rv.base, context)
+	rv.trackChildren(1)
+	rv.execPhase = MERGE_NEST
+	rv.output = rv
+	return rv
+}
+
+func (this *MergeNest) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitMergeNest(this)
+}
+
+func (this *MergeNest) Copy() Operator {
+	rv := &MergeNest{
+		plan:     this.plan,
+		child:    this.child.Copy(),
+		aliasMap: this.aliasMap,
+	}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *MergeNest) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *MergeNest) RunOnce(context *Context, parent value.Value) {
+	this.runConsumer(this, context, parent)
+}
+
+func (this *MergeNest) beforeItems(context *Context, parent value.Value) bool {
+	if !context.assert(this.child != nil, "MERGE NEST has no child") {
+		return false
+	}
+	if !context.assert(this.plan.Onclause() != nil, "MERGE NEST does not have onclause") {
+		return false
+	}
+
+	// check for constant TRUE or FALSE onclause
+	cpred := this.plan.Onclause().Value()
+	if cpred != nil {
+		if cpred.Truth() {
+			this.ansiFlags |= ANSI_ONCLAUSE_TRUE
+		} else {
+			this.ansiFlags |= ANSI_ONCLAUSE_FALSE
+		}
+	} else {
+		this.plan.Onclause().EnableInlistHash(context)
+		SetSearchInfo(this.aliasMap, parent, context, this.plan.Onclause())
+	}
+
+	this.child.SetOutput(this.child)
+	this.child.SetInput(nil)
+	this.child.SetParent(this)
+	this.child.SetStop(nil)
+
+	this.cursor.init(this.child, this.plan.RightKey(), this.plan.Descending())
+	this.fork(this.child, context, parent)
+
+	if !this.cursor.fetch(&this.base, "Merge Nest", context) {
+		return false
+	}
+	if this.cursor.empty() && !this.plan.Outer() {
+		return false
+	}
+
+	return true
+}
+
+func (this *MergeNest) processItem(item value.AnnotatedValue, context *Context) bool {
+	defer this.switchPhase(_EXECTIME)
+
+	leftKey, err := this.plan.LeftKey().Evaluate(item, context)
+	if err != nil {
+		context.Error(errors.NewEvaluationError(err, "Merge Nest Left Key"))
+		return false
+	}
+
+	candidates, ok := this.cursor.match(&this.base, leftKey, "Merge Nest", context)
+	if !ok {
+		return false
+	}
+
+	var right_items value.AnnotatedValues
+	aliases := []string{this.plan.RightAlias()}
+	for _, right_item := range candidates {
+		var match bool
+		match, ok, _ = processAnsiExec(item, right_item, this.plan.Onclause(),
+			aliases, this.ansiFlags, context, "nest")
+		if !ok {
+			return false
+		}
+		if match {
+			right_items = append(right_items, right_item)
+		}
+	}
+
+	var joined value.AnnotatedValue
+	joined, ok = processAnsiNest(item, right_items, this.plan.RightAlias(), this.plan.Outer(), context)
+	if !ok {
+		return false
+	}
+	if joined != nil {
+		if this.plan.Filter() != nil {
+			result, err := this.plan.Filter().Evaluate(joined, context)
+			if err != nil {
+				context.Error(errors.NewEvaluationError(err, "merge nest filter"))
+				return false
+			}
+			if !result.Truth() {
+				return true
+			}
+		}
+		if context.UseRequestQuota() {
+			iSz := item.Size()
+			jSz := joined.Size()
+			if jSz > iSz {
+				if context.TrackValueSize(jSz - iSz) {
+					context.Error(errors.NewMemoryQuotaExceededError())
+					return false
+				}
+			} else {
+				context.ReleaseValueSize(iSz - jSz)
+			}
+		}
+		return this.sendItem(joined)
+	}
+
+	return true
+}
+
+func (this *MergeNest) afterItems(context *Context) {
+	this.cursor.close(&this.base, context)
+	this.plan.Onclause().ResetMemory(context)
+}
+
+func (this *MergeNest) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+		r["~child"] = this.child
+	})
+	return json.Marshal(r)
+}
+
+func (this *MergeNest) SendAction(action opAction) {
+	this.baseSendAction(action)
+	child := this.child
+	if child != nil {
+		child.SendAction(action)
+	}
+}
+
+func (this *MergeNest) Done() {
+	this.baseDone()
+	if this.child != nil {
+		child := this.child
+		this.child = nil
+		child.Done()
+	}
+}
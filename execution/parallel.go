@@ -77,6 +77,9 @@ func (this *Parallel) RunOnce(context *Context, parent value.Value) {
 		defer context.Recover(&this.base) // Recover from any panic
 		active := this.active()
 		n := util.MinInt(this.plan.MaxParallelism(), context.MaxParallelism())
+		if this.plan.Adaptive() {
+			n = context.AdaptiveParallelism(n)
+		}
 		this.SetKeepAlive(n, context)
 		this.switchPhase(_EXECTIME)
 		defer this.switchPhase(_NOTIME)
@@ -0,0 +1,200 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/timestamp"
+	"github.com/couchbase/query/util"
+	"github.com/couchbase/query/value"
+)
+
+const _TEST_PARTITIONS = 4
+
+type noScanVectors struct{}
+
+func (this noScanVectors) ScanVector(namespace, keyspace string) timestamp.Vector {
+	return nil
+}
+
+func (this noScanVectors) Type() int32 {
+	return timestamp.NO_VECTORS
+}
+
+func newParallelTestContext() *Context {
+	return NewContext("parallel-test", nil, nil, "", true, _TEST_PARTITIONS, 0, 0, 0, nil, nil, nil,
+		datastore.NOT_SET, noScanVectors{}, &internalOutput{}, nil, 0, 0, "", false, false, nil, 0, 0)
+}
+
+// {alias: {"k": i % keys, "i": i}} for i up to n
+func testScan(alias string, n, keys int) plan.Operator {
+	docs := make([]interface{}, n)
+	for i := range docs {
+		docs[i] = map[string]interface{}{"k": i % keys, "i": i}
+	}
+	return plan.NewExpressionScan(expression.NewConstant(docs), alias, false, nil, -1, -1, -1, -1)
+}
+
+func testField(alias, field string) expression.Expression {
+	return expression.NewField(expression.NewIdentifier(alias), expression.NewFieldName(field, false))
+}
+
+func runParallelTest(t *testing.T, ops ...plan.Operator) []interface{} {
+	context := newParallelTestContext()
+	prepared := plan.NewPrepared(plan.NewSequence(ops...), nil, nil)
+	rv, _, err := context.ExecutePrepared(prepared, false, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected execution error: %v", err)
+	}
+	return rv.Actual().([]interface{})
+}
+
+// SELECT x.k, COUNT(1) AS n FROM ... AS x GROUP BY x.k
+func TestParallelGroup(t *testing.T) {
+	keys := expression.Expressions{testField("x", "k")}
+	count := algebra.NewCount(expression.Expressions{expression.NewConstant(1)}, 0, nil, nil)
+	aggs := algebra.Aggregates{count}
+	intermediate := plan.NewIntermediateGroup(keys, aggs, -1, -1, -1, -1)
+	intermediate.SetPartitions(_TEST_PARTITIONS)
+	projection := algebra.NewProjection(false, algebra.ResultTerms{
+		algebra.NewResultTerm(keys[0], false, "k"),
+		algebra.NewResultTerm(count, false, "n"),
+	})
+
+	results := runParallelTest(t,
+		testScan("x", 1000, 7),
+		plan.NewAdaptiveParallel(plan.NewSequence(plan.NewInitialGroup(keys, aggs, -1, -1, -1, -1), intermediate),
+			_TEST_PARTITIONS),
+		plan.NewFinalGroup(keys, aggs, -1, -1, -1, -1),
+		plan.NewInitialProject(projection, -1, -1, -1, -1),
+		plan.NewFinalProject())
+
+	if len(results) != 7 {
+		t.Fatalf("Expected 7 groups, got %v", results)
+	}
+	total := int64(0)
+	for _, r := range results {
+		row := value.NewValue(r)
+		k, _ := row.Field("k")
+		n, _ := row.Field("n")
+		expected := int64(142)
+		if value.AsNumberValue(k).Int64() < 1000%7 {
+			expected++
+		}
+		if value.AsNumberValue(n).Int64() != expected {
+			t.Errorf("Unexpected count for group %v", r)
+		}
+		total += value.AsNumberValue(n).Int64()
+	}
+	if total != 1000 {
+		t.Errorf("Expected 1000 documents counted, got %v", total)
+	}
+}
+
+// SELECT x.i, y.i FROM ... AS x JOIN ... AS y USE HASH(BUILD) ON x.k = y.k
+func TestParallelHashJoin(t *testing.T) {
+	onclause := expression.NewEq(testField("x", "k"), testField("y", "k"))
+	join := plan.NewHashJoin(algebra.NewAnsiJoin(nil, false, nil, onclause), testScan("y", 20, 10),
+		expression.Expressions{testField("y", "k")}, expression.Expressions{testField("x", "k")},
+		[]string{"y"}, nil, -1, -1, -1, -1)
+	join.SetPartitions(_TEST_PARTITIONS)
+
+	results := runParallelTest(t,
+		testScan("x", 1000, 100),
+		plan.NewAdaptiveParallel(plan.NewSequence(join), _TEST_PARTITIONS))
+
+	// only x.k below 10 match, twice each
+	if len(results) != 200 {
+		t.Fatalf("Expected 200 joined documents, got %v", len(results))
+	}
+	for _, r := range results {
+		row := value.NewValue(r)
+		xk, _ := row.Field("x")
+		yk, _ := row.Field("y")
+		xk, _ = xk.Field("k")
+		yk, _ = yk.Field("k")
+		if !xk.Equals(yk).Truth() {
+			t.Errorf("Unexpected join result %v", r)
+		}
+	}
+}
+
+func TestGroupMergeStop(t *testing.T) {
+	merge := newGroupMerge(_TEST_PARTITIONS)
+	merge.register()
+	merge.register()
+	merge.leave()
+
+	// a stopped copy gives up on the others
+	stop := make(stopChannel, 1)
+	stop <- 0
+	if merge.wait(stop) {
+		t.Errorf("Expected wait to stop")
+	}
+
+	merge.leave()
+	if !merge.wait(make(stopChannel, 1)) {
+		t.Errorf("Expected all copies to have left")
+	}
+
+	// late copies have nothing left to wait for
+	merge.register()
+	merge.leave()
+	if !merge.wait(nil) {
+		t.Errorf("Expected late copy not to wait")
+	}
+}
+
+func TestHashBuildFailure(t *testing.T) {
+	context := newParallelTestContext()
+	shared := newHashBuild()
+	building := make(chan bool)
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		shared.acquire(func() (*util.PartitionedHashTable, bool) {
+			building <- true
+			<-building
+			panic("build failed")
+		}, nil, context)
+	}()
+	<-building
+
+	// copies waiting on the build can be stopped
+	stop := make(stopChannel, 1)
+	stop <- 0
+	if _, ok := shared.acquire(nil, stop, context); ok {
+		t.Errorf("Expected stopped copy to fail")
+	}
+
+	// and are released if the build panics
+	acquired := make(chan bool)
+	go func() {
+		_, ok := shared.acquire(nil, nil, context)
+		acquired <- ok
+	}()
+	building <- true
+	if r := <-panicked; r == nil {
+		t.Errorf("Expected build to panic")
+	}
+	select {
+	case ok := <-acquired:
+		if ok {
+			t.Errorf("Expected failed build not to be acquired")
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("Copy still waiting on the failed build")
+	}
+}
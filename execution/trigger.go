@@ -0,0 +1,146 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/functions"
+	"github.com/couchbase/query/value"
+)
+
+// triggers firing statements that fire triggers
+const _MAX_TRIGGER_NESTING = 16
+
+/*
+The triggers of a keyspace for one event, in firing order. Send
+operators load them once per statement, so triggers created or
+dropped while a statement runs only apply to later statements.
+*/
+type rowTriggers struct {
+	before []*datastore.Trigger
+	after  []*datastore.Trigger
+}
+
+func getTriggers(keyspace datastore.Keyspace, event string) *rowTriggers {
+	var rv *rowTriggers
+	for _, trigger := range datastore.GetTriggerStore().Triggers(keyspace.QualifiedName()) {
+		if trigger.Event != event {
+			continue
+		}
+		if rv == nil {
+			rv = &rowTriggers{}
+		}
+		if trigger.Timing == datastore.TRIGGER_BEFORE {
+			rv.before = append(rv.before, trigger)
+		} else {
+			rv.after = append(rv.after, trigger)
+		}
+	}
+	return rv
+}
+
+func (this *rowTriggers) hasBefore() bool {
+	return this != nil && len(this.before) > 0
+}
+
+func (this *rowTriggers) hasAfter() bool {
+	return this != nil && len(this.after) > 0
+}
+
+/*
+Fire the BEFORE triggers for a document about to be mutated. A trigger
+returning FALSE rejects the row, and one returning an object replaces
+the NEW document for the triggers that follow and for the mutation.
+Returns the replacement document, if any, and whether the row goes
+ahead; errors have been reported if it doesn't.
+*/
+func (this *rowTriggers) fireBefore(key string, newDoc, oldDoc value.Value, context *Context) (value.Value, bool) {
+	if !this.hasBefore() {
+		return nil, true
+	}
+	var replacement value.Value
+	for _, trigger := range this.before {
+		rv, err := fireTrigger(trigger, key, newDoc, oldDoc, context)
+		if err != nil {
+			context.Error(err)
+			return nil, false
+		}
+		if rv == nil {
+			continue
+		}
+		switch rv.Type() {
+		case value.BOOLEAN:
+			if !rv.Truth() {
+				context.Error(errors.NewTriggerRejectedError(trigger.Name, key))
+				return nil, false
+			}
+		case value.OBJECT:
+			if newDoc != nil {
+				newDoc = rv
+				replacement = rv
+			}
+		}
+	}
+	return replacement, true
+}
+
+/*
+Fire the AFTER triggers for a mutated document. The mutation stands
+whatever the triggers return.
+*/
+func (this *rowTriggers) fireAfter(key string, newDoc, oldDoc value.Value, context *Context) {
+	if !this.hasAfter() {
+		return
+	}
+	for _, trigger := range this.after {
+		_, err := fireTrigger(trigger, key, newDoc, oldDoc, context)
+		if err != nil {
+			context.Error(err)
+		}
+	}
+}
+
+func fireTrigger(trigger *datastore.Trigger, key string, newDoc, oldDoc value.Value,
+	context *Context) (value.Value, errors.Error) {
+
+	// statements run by the function see the nesting level through their own copies
+	context = context.Copy()
+	levels := context.IncRecursionCount(1)
+	if levels > _MAX_TRIGGER_NESTING {
+		return nil, errors.NewTriggerExecutionError(trigger.Name, key, fmt.Errorf("%v nested triggers", levels))
+	}
+
+	docs := make(map[string]interface{}, 2)
+	if newDoc != nil {
+		docs[algebra.TRIGGER_NEW] = newDoc
+	}
+	if oldDoc != nil {
+		docs[algebra.TRIGGER_OLD] = oldDoc
+	}
+	item := value.NewValue(docs)
+
+	args := make([]value.Value, len(trigger.Args))
+	for i, arg := range trigger.Args {
+		val, err := arg.Evaluate(item, context)
+		if err != nil {
+			return nil, errors.NewTriggerExecutionError(trigger.Name, key, err)
+		}
+		args[i] = val
+	}
+
+	rv, err := functions.ExecuteFunction(trigger.Function, functions.NONE, args, context)
+	if err != nil {
+		return nil, errors.NewTriggerExecutionError(trigger.Name, key, err)
+	}
+	return rv, nil
+}
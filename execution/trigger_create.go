@@ -0,0 +1,79 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type CreateTrigger struct {
+	base
+	plan *plan.CreateTrigger
+}
+
+func NewCreateTrigger(plan *plan.CreateTrigger, context *Context) *CreateTrigger {
+	rv := &CreateTrigger{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *CreateTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateTrigger(this)
+}
+
+func (this *CreateTrigger) Copy() Operator {
+	rv := &CreateTrigger{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *CreateTrigger) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *CreateTrigger) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		trigger := this.plan.Trigger()
+		this.switchPhase(_SERVTIME)
+		ok, err := datastore.GetTriggerStore().AddTrigger(trigger)
+		this.switchPhase(_EXECTIME)
+		if err != nil {
+			context.Error(errors.NewTriggerStoreError(trigger.Name, trigger.Keyspace, err))
+		} else if !ok && this.plan.FailIfExists() {
+			context.Error(errors.NewDuplicateTriggerError(trigger.Name, trigger.Keyspace))
+		}
+	})
+}
+
+func (this *CreateTrigger) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
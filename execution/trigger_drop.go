@@ -0,0 +1,78 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type DropTrigger struct {
+	base
+	plan *plan.DropTrigger
+}
+
+func NewDropTrigger(plan *plan.DropTrigger, context *Context) *DropTrigger {
+	rv := &DropTrigger{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *DropTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropTrigger(this)
+}
+
+func (this *DropTrigger) Copy() Operator {
+	rv := &DropTrigger{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *DropTrigger) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *DropTrigger) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		this.switchPhase(_SERVTIME)
+		trigger, err := datastore.GetTriggerStore().DropTrigger(this.plan.Keyspace(), this.plan.Name())
+		this.switchPhase(_EXECTIME)
+		if err != nil {
+			context.Error(errors.NewTriggerStoreError(this.plan.Name(), this.plan.Keyspace(), err))
+		} else if trigger == nil && this.plan.FailIfNotExists() {
+			context.Error(errors.NewTriggerNotFoundError(this.plan.Name(), this.plan.Keyspace()))
+		}
+	})
+}
+
+func (this *DropTrigger) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
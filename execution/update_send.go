@@ -14,8 +14,10 @@ import (
 	"math"
 	"time"
 
+	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/util"
 	"github.com/couchbase/query/value"
@@ -35,12 +37,16 @@ type SendUpdate struct {
 	plan     *plan.SendUpdate
 	keyspace datastore.Keyspace
 	limit    int64
+	schema   *expression.JSONSchema
+	triggers *rowTriggers
 }
 
 func NewSendUpdate(plan *plan.SendUpdate, context *Context) *SendUpdate {
 	rv := _SENDUPDATE_OP_POOL.Get().(*SendUpdate)
 	rv.plan = plan
 	rv.limit = -1
+	rv.schema = nil
+	rv.triggers = nil
 
 	newBase(&rv.base, context)
 	rv.execPhase = UPDATE
@@ -56,6 +62,8 @@ func (this *SendUpdate) Copy() Operator {
 	rv := _SENDUPDATE_OP_POOL.Get().(*SendUpdate)
 	rv.plan = this.plan
 	rv.limit = this.limit
+	rv.schema = this.schema
+	rv.triggers = this.triggers
 	this.base.copy(&rv.base)
 	return rv
 }
@@ -83,6 +91,14 @@ func (this *SendUpdate) beforeItems(context *Context, parent value.Value) bool {
 	if this.keyspace == nil {
 		return false
 	}
+	this.triggers = getTriggers(this.keyspace, datastore.TRIGGER_UPDATE)
+
+	if this.plan.Validate() {
+		this.schema = getSchema(this.keyspace, context)
+		if this.schema == nil {
+			return false
+		}
+	}
 
 	if this.plan.Limit() == nil {
 		return true
@@ -129,7 +145,13 @@ func (this *SendUpdate) flushBatch(context *Context) bool {
 		pairs = make([]value.Pair, 0, len(this.batch))
 	}
 
-	for i, item := range this.batch {
+	var rejected map[int]bool
+	var old map[string]value.Value
+	if this.triggers.hasAfter() {
+		old = make(map[string]value.Value, len(this.batch))
+	}
+	i := 0
+	for n, item := range this.batch {
 		uv, ok := item.Field(this.plan.Alias())
 		if !ok {
 			context.Error(errors.NewUpdateAliasMissingError(this.plan.Alias()))
@@ -163,6 +185,18 @@ func (this *SendUpdate) flushBatch(context *Context) bool {
 
 			cav := value.NewAnnotatedValue(cv)
 			cav.CopyAnnotations(av)
+			replacement, ok := this.triggers.fireBefore(key, cav, av, context)
+			if ok && replacement != nil {
+				cav = value.NewAnnotatedValue(replacement)
+				cav.CopyAnnotations(av)
+			}
+			if !ok || !validateDocument(this.schema, key, cav, context) {
+				if rejected == nil {
+					rejected = make(map[int]bool)
+				}
+				rejected[n] = true
+				continue
+			}
 			pairs[i].Value = cav
 
 			if mv := clone.GetAttachment("options"); mv != nil {
@@ -175,13 +209,21 @@ func (this *SendUpdate) flushBatch(context *Context) bool {
 			setMetaExpiration(cav, pairs[i].Options, context.PreserveExpiry())
 
 			item.SetField(this.plan.Alias(), cav)
+			if this.plan.Images() {
+				setReturningImages(item, this.plan.Alias(), av, cav)
+			}
+			if old != nil {
+				old[key] = av
+			}
 
 		default:
 			context.Error(errors.NewInvalidValueError(fmt.Sprintf(
 				"Invalid UPDATE value of type %T.", clone)))
 			return false
 		}
+		i++
 	}
+	pairs = pairs[0:i]
 
 	this.switchPhase(_SERVTIME)
 
@@ -192,6 +234,10 @@ func (this *SendUpdate) flushBatch(context *Context) bool {
 	// Update mutation count with number of updated docs
 	context.AddMutationCount(uint64(len(pairs)))
 
+	for _, pair := range pairs {
+		this.triggers.fireAfter(pair.Name, pair.Value, old[pair.Name], context)
+	}
+
 	mutationOk := true
 	if len(errs) > 0 {
 		context.Errors(errs)
@@ -200,7 +246,10 @@ func (this *SendUpdate) flushBatch(context *Context) bool {
 		}
 	}
 
-	for _, item := range this.batch {
+	for n, item := range this.batch {
+		if rejected[n] {
+			continue
+		}
 		if !this.sendItem(item) {
 			return false
 		}
@@ -240,6 +289,58 @@ func getExpiration(options value.Value) (exptime uint32, present bool) {
 	return
 }
 
+/*
+Compile the schema of the keyspace, for DML statements with the
+VALIDATE option. It is an error for the keyspace not to have one.
+*/
+func getSchema(keyspace datastore.Keyspace, context *Context) *expression.JSONSchema {
+	name := keyspace.QualifiedName()
+	schema, ok := datastore.GetSchemaStore().Schema(name)
+	if !ok {
+		context.Error(errors.NewSchemaNotFoundError(name))
+		return nil
+	}
+
+	rv, err := expression.NewJSONSchema(schema)
+	if err != nil {
+		context.Error(errors.NewEvaluationError(err, "keyspace schema"))
+		return nil
+	}
+	return rv
+}
+
+/*
+Validate a new document against the keyspace schema, if any. A
+document that fails is reported as a per-document error, and counts
+towards the error limit of the request.
+*/
+func validateDocument(schema *expression.JSONSchema, key string, doc value.Value, context *Context) bool {
+	if schema == nil {
+		return true
+	}
+
+	errs := schema.Validate(doc)
+	if len(errs) == 0 {
+		return true
+	}
+
+	context.Error(errors.NewSchemaValidationError(key, errs))
+	return false
+}
+
+/*
+Attach the before and after images of a document for the OLD and
+NEW aliases of RETURNING, unless the keyspace is aliased as either.
+*/
+func setReturningImages(item value.AnnotatedValue, alias string, old, new value.Value) {
+	if old != nil && alias != algebra.RETURNING_OLD {
+		item.SetField(algebra.RETURNING_OLD, old)
+	}
+	if new != nil && alias != algebra.RETURNING_NEW {
+		item.SetField(algebra.RETURNING_NEW, new)
+	}
+}
+
 const _MONTH = uint32(30 * 24 * 60 * 60)
 
 func adjustExpiration(options value.Value) value.Value {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/value"
 )
@@ -22,6 +23,9 @@ type SendUpsert struct {
 	base
 	plan     *plan.SendUpsert
 	keyspace datastore.Keyspace
+	schema   *expression.JSONSchema
+	inserts  *rowTriggers
+	updates  *rowTriggers
 }
 
 func NewSendUpsert(plan *plan.SendUpsert, context *Context) *SendUpsert {
@@ -40,7 +44,7 @@ func (this *SendUpsert) Accept(visitor Visitor) (interface{}, error) {
 }
 
 func (this *SendUpsert) Copy() Operator {
-	rv := &SendUpsert{plan: this.plan}
+	rv := &SendUpsert{plan: this.plan, schema: this.schema, inserts: this.inserts, updates: this.updates}
 	this.base.copy(&rv.base)
 	return rv
 }
@@ -55,7 +59,19 @@ func (this *SendUpsert) RunOnce(context *Context, parent value.Value) {
 
 func (this *SendUpsert) beforeItems(context *Context, parent value.Value) bool {
 	this.keyspace = getKeyspace(this.plan.Keyspace(), this.plan.Term().ExpressionTerm(), context)
-	return this.keyspace != nil
+	if this.keyspace == nil {
+		return false
+	}
+
+	// new documents fire the INSERT triggers, and replaced ones the UPDATE triggers
+	this.inserts = getTriggers(this.keyspace, datastore.TRIGGER_INSERT)
+	this.updates = getTriggers(this.keyspace, datastore.TRIGGER_UPDATE)
+
+	if this.plan.Validate() {
+		this.schema = getSchema(this.keyspace, context)
+		return this.schema != nil
+	}
+	return true
 }
 
 func (this *SendUpsert) processItem(item value.AnnotatedValue, context *Context) bool {
@@ -149,7 +165,45 @@ func (this *SendUpsert) flushBatch(context *Context) bool {
 			continue
 		}
 
-		dpair.Options = adjustExpiration(options)
+		dpair.Value = val
+		dpair.Options = options
+		i++
+	}
+
+	dpairs = dpairs[0:i]
+
+	// the documents being replaced are only read for the UPDATE triggers
+	var olds map[string]value.AnnotatedValue
+	if this.updates != nil && len(dpairs) > 0 {
+		olds, ok = this.fetchOld(dpairs, context)
+		if !ok {
+			return false
+		}
+	}
+
+	i = 0
+	for _, dp := range dpairs {
+		val := dp.Value
+		triggers := this.inserts
+		var old value.Value
+		if o, found := olds[dp.Name]; found {
+			triggers = this.updates
+			old = o
+		}
+		replacement, ok := triggers.fireBefore(dp.Name, val, old, context)
+		if !ok {
+			continue
+		} else if replacement != nil {
+			val = replacement
+		}
+
+		if !validateDocument(this.schema, dp.Name, val, context) {
+			continue
+		}
+
+		dpair := &dpairs[i]
+		dpair.Name = dp.Name
+		dpair.Options = adjustExpiration(dp.Options)
 		expiration, _ := getExpiration(dpair.Options)
 		// UPSERT can preserve expiration, but we can't get old value without read for RETURNING clause.
 		dpair.Value = this.setDocumentKey(dpair.Name, value.NewAnnotatedValue(val), expiration, context)
@@ -179,6 +233,11 @@ func (this *SendUpsert) flushBatch(context *Context) bool {
 
 	// Capture the upserted keys in case there is a RETURNING clause
 	for _, dp := range dpairs {
+		if old, found := olds[dp.Name]; found {
+			this.updates.fireAfter(dp.Name, dp.Value, old, context)
+		} else {
+			this.inserts.fireAfter(dp.Name, dp.Value, nil, context)
+		}
 		dv := value.NewAnnotatedValue(dp.Value)
 		av := value.NewAnnotatedValue(make(map[string]interface{}, 1))
 		av.CopyAnnotations(dv)
@@ -191,6 +250,27 @@ func (this *SendUpsert) flushBatch(context *Context) bool {
 	return mutationOk
 }
 
+func (this *SendUpsert) fetchOld(dpairs []value.Pair, context *Context) (map[string]value.AnnotatedValue, bool) {
+	keys := make([]string, len(dpairs))
+	for i, dp := range dpairs {
+		keys[i] = dp.Name
+	}
+
+	this.switchPhase(_SERVTIME)
+	olds := make(map[string]value.AnnotatedValue, len(keys))
+	errs := this.keyspace.Fetch(keys, olds, context, nil)
+	this.switchPhase(_EXECTIME)
+
+	ok := true
+	for _, err := range errs {
+		context.Error(err)
+		if err.IsFatal() {
+			ok = false
+		}
+	}
+	return olds, ok
+}
+
 func (this *SendUpsert) readonly() bool {
 	return false
 }
@@ -0,0 +1,100 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type AlterUser struct {
+	base
+	plan *plan.AlterUser
+}
+
+func NewAlterUser(plan *plan.AlterUser, context *Context) *AlterUser {
+	rv := &AlterUser{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *AlterUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitAlterUser(this)
+}
+
+func (this *AlterUser) Copy() Operator {
+	rv := &AlterUser{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *AlterUser) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *AlterUser) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		node := this.plan.Node()
+		domain, id := splitUser(node.User())
+		userId := domain + ":" + id
+		if domain != "local" && node.Password() != "" {
+			context.Fatal(errors.NewUserOptionError("user", userId, "only local users have a password"))
+			return
+		}
+
+		userMap, err := getUserMap(context.datastore)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+		user := userMap[userId]
+		if user == nil {
+			context.Fatal(errors.NewUserNotFoundError(userId))
+			return
+		}
+
+		// the roles and groups read are written back unchanged
+		user.Password = node.Password()
+		err = setUserOptions(user, node.With(), context)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+
+		err = context.datastore.PutUserInfo(user)
+		if err != nil {
+			context.Fatal(err)
+		}
+	})
+}
+
+func (this *AlterUser) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
@@ -0,0 +1,170 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type CreateUser struct {
+	base
+	plan *plan.CreateUser
+}
+
+func NewCreateUser(plan *plan.CreateUser, context *Context) *CreateUser {
+	rv := &CreateUser{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *CreateUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateUser(this)
+}
+
+func (this *CreateUser) Copy() Operator {
+	rv := &CreateUser{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *CreateUser) PlanOp() plan.Operator {
+	return this.plan
+}
+
+// Split a user in either plain user_id form or domain:user_id form.
+func splitUser(user string) (string, string) {
+	if i := strings.Index(user, ":"); i >= 0 {
+		return user[:i], user[i+1:]
+	}
+	return "local", user
+}
+
+func stringList(val value.Value) ([]string, bool) {
+	if val.Type() != value.ARRAY {
+		return nil, false
+	}
+	vals := val.Actual().([]interface{})
+	rv := make([]string, len(vals))
+	for i, v := range vals {
+		s, ok := value.NewValue(v).Actual().(string)
+		if !ok {
+			return nil, false
+		}
+		rv[i] = s
+	}
+	return rv, true
+}
+
+// Set the options given in the WITH clause of CREATE USER and ALTER USER.
+func setUserOptions(user *datastore.User, with value.Value, context *Context) errors.Error {
+	if with == nil {
+		return nil
+	}
+
+	name := user.Domain + ":" + user.Id
+	if with.Type() != value.OBJECT {
+		return errors.NewUserOptionError("user", name, "WITH must be an object")
+	}
+	for k, v := range with.Fields() {
+		val := value.NewValue(v)
+		switch k {
+		case "name":
+			if val.Type() != value.STRING {
+				return errors.NewUserOptionError("user", name, "name must be a string")
+			}
+			user.Name = val.Actual().(string)
+		case "groups":
+			groups, ok := stringList(val)
+			if !ok {
+				return errors.NewUserOptionError("user", name, "groups must be an array of strings")
+			}
+			groupMap, err := getGroupMap(context.datastore)
+			if err != nil {
+				return err
+			}
+			for _, g := range groups {
+				if groupMap[g] == nil {
+					return errors.NewGroupNotFoundError(g)
+				}
+			}
+			user.Groups = groups
+		default:
+			return errors.NewUserOptionError("user", name, "unknown option "+k)
+		}
+	}
+	return nil
+}
+
+func (this *CreateUser) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		node := this.plan.Node()
+		domain, id := splitUser(node.User())
+		userId := domain + ":" + id
+		if domain != "local" {
+			context.Fatal(errors.NewUserOptionError("user", userId, "only local users have a password"))
+			return
+		}
+
+		userMap, err := getUserMap(context.datastore)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+		if userMap[userId] != nil {
+			context.Fatal(errors.NewUserExistsError(userId))
+			return
+		}
+
+		user := &datastore.User{Id: id, Domain: domain, Password: node.Password()}
+		err = setUserOptions(user, node.With(), context)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+
+		err = context.datastore.PutUserInfo(user)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+
+		// roles are granted to the user separately
+		if len(user.Groups) == 0 {
+			context.Warning(errors.NewUserWithNoRoles(userId))
+		}
+	})
+}
+
+func (this *CreateUser) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
@@ -0,0 +1,86 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package execution
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
+)
+
+type DropUser struct {
+	base
+	plan *plan.DropUser
+}
+
+func NewDropUser(plan *plan.DropUser, context *Context) *DropUser {
+	rv := &DropUser{
+		plan: plan,
+	}
+
+	newRedirectBase(&rv.base)
+	rv.output = rv
+	return rv
+}
+
+func (this *DropUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropUser(this)
+}
+
+func (this *DropUser) Copy() Operator {
+	rv := &DropUser{plan: this.plan}
+	this.base.copy(&rv.base)
+	return rv
+}
+
+func (this *DropUser) PlanOp() plan.Operator {
+	return this.plan
+}
+
+func (this *DropUser) RunOnce(context *Context, parent value.Value) {
+	this.once.Do(func() {
+		defer context.Recover(&this.base) // Recover from any panic
+		active := this.active()
+		defer this.close(context)
+		this.switchPhase(_EXECTIME)
+		defer this.switchPhase(_NOTIME)
+		defer this.notify() // Notify that I have stopped
+
+		if !active || context.Readonly() {
+			return
+		}
+
+		domain, id := splitUser(this.plan.Node().User())
+		userId := domain + ":" + id
+		userMap, err := getUserMap(context.datastore)
+		if err != nil {
+			context.Fatal(err)
+			return
+		}
+		user := userMap[userId]
+		if user == nil {
+			context.Fatal(errors.NewUserNotFoundError(userId))
+			return
+		}
+
+		err = context.datastore.DeleteUserInfo(user)
+		if err != nil {
+			context.Fatal(err)
+		}
+	})
+}
+
+func (this *DropUser) MarshalJSON() ([]byte, error) {
+	r := this.plan.MarshalBase(func(r map[string]interface{}) {
+		this.marshalTimes(r)
+	})
+	return json.Marshal(r)
+}
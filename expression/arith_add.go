@@ -45,7 +45,7 @@ return a null value. Return the final sum.
 */
 func (this *Add) Evaluate(item value.Value, context Context) (value.Value, error) {
 	null := false
-	sum := value.ZERO_NUMBER
+	sum := ArithNumber(value.ZERO_VALUE, context)
 
 	for _, op := range this.operands {
 		arg, err := op.Evaluate(item, context)
@@ -53,7 +53,7 @@ func (this *Add) Evaluate(item value.Value, context Context) (value.Value, error
 			return nil, err
 		}
 		if !null && arg.Type() == value.NUMBER {
-			sum = sum.Add(value.AsNumberValue(arg))
+			sum = sum.Add(ArithNumber(arg, context))
 		} else if arg.Type() == value.MISSING {
 			return value.MISSING_VALUE, nil
 		} else {
@@ -59,6 +59,10 @@ func (this *Div) Evaluate(item value.Value, context Context) (value.Value, error
 		}
 
 		if first.Type() == value.NUMBER {
+			if UseDecimal(context) {
+				return value.DecimalDiv(ArithNumber(first, context), ArithNumber(second, context)), nil
+			}
+
 			d := first.Actual().(float64) / s
 			return value.NewValue(d), nil
 		}
@@ -53,7 +53,7 @@ func (this *IMod) Evaluate(item value.Value, context Context) (value.Value, erro
 	}
 
 	if first.Type() == value.NUMBER && second.Type() == value.NUMBER {
-		return value.AsNumberValue(first).IMod(value.AsNumberValue(second)), nil
+		return ArithNumber(first, context).IMod(ArithNumber(second, context)), nil
 	} else {
 		return value.NULL_VALUE, nil
 	}
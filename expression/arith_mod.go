@@ -61,6 +61,10 @@ func (this *Mod) Evaluate(item value.Value, context Context) (value.Value, error
 		}
 
 		if first.Type() == value.NUMBER {
+			if UseDecimal(context) {
+				return value.DecimalMod(ArithNumber(first, context), ArithNumber(second, context)), nil
+			}
+
 			m := math.Mod(first.Actual().(float64), s)
 			return value.NewValue(m), nil
 		}
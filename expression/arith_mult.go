@@ -45,7 +45,7 @@ all other types return a null value. Return the final product.
 */
 func (this *Mult) Evaluate(item value.Value, context Context) (value.Value, error) {
 	null := false
-	prod := value.ONE_NUMBER
+	prod := ArithNumber(value.ONE_VALUE, context)
 
 	for _, op := range this.operands {
 		arg, err := op.Evaluate(item, context)
@@ -54,7 +54,7 @@ func (this *Mult) Evaluate(item value.Value, context Context) (value.Value, erro
 		} else if arg.Type() == value.MISSING {
 			return value.MISSING_VALUE, nil
 		} else if !null && arg.Type() == value.NUMBER {
-			prod = prod.Mult(value.AsNumberValue(arg))
+			prod = prod.Mult(ArithNumber(arg, context))
 		} else {
 			null = true
 		}
@@ -48,7 +48,7 @@ func (this *Neg) Evaluate(item value.Value, context Context) (value.Value, error
 	if err != nil {
 		return nil, err
 	} else if arg.Type() == value.NUMBER {
-		return value.AsNumberValue(arg).Neg(), nil
+		return ArithNumber(arg, context).Neg(), nil
 	} else if arg.Type() == value.MISSING {
 		return value.MISSING_VALUE, nil
 	} else {
@@ -56,7 +56,7 @@ func (this *Sub) Evaluate(item value.Value, context Context) (value.Value, error
 	}
 
 	if first.Type() == value.NUMBER && second.Type() == value.NUMBER {
-		return value.AsNumberValue(first).Sub(value.AsNumberValue(second)), nil
+		return ArithNumber(first, context).Sub(ArithNumber(second, context)), nil
 	} else if first.Type() == value.MISSING || second.Type() == value.MISSING {
 		return value.MISSING_VALUE, nil
 	} else {
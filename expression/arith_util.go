@@ -0,0 +1,34 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"github.com/couchbase/query/value"
+)
+
+/*
+Returns true if the request has opted in to exact decimal arithmetic.
+*/
+func UseDecimal(context Context) bool {
+	decimalContext, ok := context.(DecimalContext)
+	return ok && decimalContext.UseDecimal()
+}
+
+/*
+Return the number operand of an arithmetic operation. With decimal
+arithmetic every number is converted to an exact decimal; otherwise
+decimals kept from documents are rounded to float64, so that results
+are the same as they always were.
+*/
+func ArithNumber(arg value.Value, context Context) value.NumberValue {
+	if UseDecimal(context) {
+		return value.ToDecimal(value.AsNumberValue(arg))
+	}
+	return value.ToBinary(value.AsNumberValue(arg))
+}
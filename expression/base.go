@@ -37,6 +37,7 @@ const (
 	EXPR_DEFAULT_LIKE
 	EXPR_UNNEST_ISARRAY
 	EXPR_IN_PAREN
+	EXPR_DERIVED_NGRAMS
 )
 
 /*
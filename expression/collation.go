@@ -0,0 +1,477 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+/*
+A Collation orders strings following the Unicode Collation Algorithm
+(UTS #10). Strings are put in NFKD form and mapped to collation
+elements with three levels of weights: the primary weight orders base
+characters, the secondary weight orders accents, and the tertiary
+weight orders case and compatibility variants.
+
+The weights are not those of the default collation element table
+(DUCET). Rather than carrying that table, primary weights are derived
+from character properties: spaces, punctuation, symbols and digits
+sort before letters, digits of every script sort by numeric value, and
+letters sort by their lowercase code point, which keeps the letters of
+each script together and in alphabetical order. Latin letters that the
+code point order would put after z, such as æ, ß and ł, are placed as
+in DUCET. Other orders differ from DUCET: punctuation and symbols are
+ordered by code point rather than by DUCET's variable weighting,
+scripts are ordered by code point rather than DUCET's script order, and
+letters beyond Latin that DUCET orders differently from their code
+point are not placed. Languages whose alphabet differs from the root
+order are tailored.
+
+A collation is specified as 'locale[-ci][-ai]'. The locale is a
+language tag such as en, sv_SE or de-AT, or root for the untailored
+order; -ci ignores case differences and -ai ignores accents.
+*/
+type Collation struct {
+	spec      string
+	language  string
+	ci        bool
+	ai        bool
+	tailoring *collationTailoring
+}
+
+/*
+Returns the collation for the given specification, or an error if
+the specification is malformed. Unknown languages use the root order.
+*/
+func NewCollation(spec string) (*Collation, error) {
+	parts := strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(parts) == 0 || !collationSubtag(parts[0], 2, 8) {
+		return nil, fmt.Errorf("Invalid collation %s: expected 'locale[-ci][-ai]'", spec)
+	}
+
+	rv := &Collation{spec: spec, language: parts[0]}
+	for i, part := range parts[1:] {
+		switch {
+		case part == "ci" && !rv.ci:
+			rv.ci = true
+		case part == "ai" && !rv.ai:
+			rv.ai = true
+		case !rv.ci && !rv.ai && i < 2 && collationSubtag(part, 2, 4):
+			// region and script subtags do not affect the order
+		default:
+			return nil, fmt.Errorf("Invalid collation %s: unexpected %s", spec, part)
+		}
+	}
+
+	rv.tailoring = _TAILORINGS[rv.language]
+	return rv, nil
+}
+
+func collationSubtag(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *Collation) String() string {
+	return this.spec
+}
+
+/*
+Returns the sort key of s. Sort keys compare bytewise in collation
+order, so that they can be compared, sorted and indexed like any other
+string. Keys are only meaningful relative to keys of the same
+collation.
+*/
+func (this *Collation) Key(s string) string {
+	elements := this.elements(s)
+	key := make([]rune, 0, 6*len(elements)+2)
+
+	for _, e := range elements {
+		if e.primary != 0 {
+			key = appendCollationWeight(key, e.primary)
+		}
+	}
+
+	if !this.ai {
+		key = append(key, _COLLATION_LEVEL_SEPARATOR)
+		for _, e := range elements {
+			if e.secondary != 0 {
+				key = appendCollationWeight(key, e.secondary)
+			}
+		}
+	}
+
+	if !this.ci {
+		key = append(key, _COLLATION_LEVEL_SEPARATOR)
+		for _, e := range elements {
+			if e.tertiary != 0 {
+				key = append(key, rune(e.tertiary))
+			}
+		}
+	}
+
+	return string(key)
+}
+
+/*
+Compares two strings in collation order.
+*/
+func (this *Collation) Compare(first, second string) int {
+	return strings.Compare(this.Key(first), this.Key(second))
+}
+
+/*
+Weights are written as runes, whose UTF-8 encodings compare in code
+point order. Runes are kept below the surrogate range, and above the
+level separator, which terminates each level so that a prefix sorts
+first.
+*/
+const (
+	_COLLATION_LEVEL_SEPARATOR = rune(0x01)
+	_COLLATION_WEIGHT_BASE     = 0x100
+	_COLLATION_WEIGHT_BITS     = 15
+)
+
+func appendCollationWeight(key []rune, w uint32) []rune {
+	return append(key,
+		rune(_COLLATION_WEIGHT_BASE+w>>_COLLATION_WEIGHT_BITS),
+		rune(_COLLATION_WEIGHT_BASE+w&(1<<_COLLATION_WEIGHT_BITS-1)))
+}
+
+/*
+Primary weights are a group followed by a value within the group.
+Letters are spaced out so that letters can be placed after any letter:
+tailorings use the lower half of the gap, and the root order the upper
+half.
+*/
+const (
+	_COLLATION_SPACE = uint32(iota + 1)
+	_COLLATION_PUNCTUATION
+	_COLLATION_SYMBOL
+	_COLLATION_DIGIT
+	_COLLATION_NUMBER
+	_COLLATION_LETTER
+)
+
+const (
+	_COLLATION_GROUP_SHIFT = 24
+	_COLLATION_GAP         = 8
+)
+
+const (
+	_SECONDARY_COMMON = 0x20
+	_SECONDARY_MARK   = 0x100
+)
+
+const (
+	_TERTIARY_LOWER   = 0x10
+	_TERTIARY_COMPAT  = 0x04
+	_TERTIARY_UPPER   = 0x18
+	_TERTIARY_VARIANT = 0x02
+)
+
+type collationElement struct {
+	primary   uint32
+	secondary uint32
+	tertiary  uint32
+}
+
+type collationRune struct {
+	r      rune
+	compat bool
+}
+
+func (this *Collation) elements(s string) []collationElement {
+	runes := collationDecompose(s)
+	rv := make([]collationElement, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		cr := runes[i]
+		if unicode.In(cr.r, unicode.Mn, unicode.Mc, unicode.Me) {
+			rv = append(rv, collationElement{secondary: _SECONDARY_MARK + uint32(cr.r)})
+			continue
+		}
+
+		tertiary := uint32(_TERTIARY_LOWER)
+		if unicode.IsUpper(cr.r) || unicode.IsTitle(cr.r) {
+			tertiary = _TERTIARY_UPPER
+		}
+		if cr.compat {
+			tertiary += _TERTIARY_COMPAT
+		}
+
+		if this.tailoring != nil {
+			if w, n := this.tailoring.match(runes[i:], this.lower); n > 0 {
+				rv = append(rv, collationElement{w.primary, w.secondary, tertiary})
+				i += n - 1
+				continue
+			}
+		}
+
+		lower := this.lower(cr.r)
+		if expansion, ok := _COLLATION_EXPANSIONS[lower]; ok {
+			for _, r := range expansion {
+				primary, _ := rootPrimary(r)
+				rv = append(rv, collationElement{primary, _SECONDARY_COMMON, tertiary + _TERTIARY_VARIANT})
+			}
+			continue
+		}
+		if base, ok := _COLLATION_STROKES[lower]; ok {
+			primary, _ := rootPrimary(base)
+			rv = append(rv, collationElement{primary, _SECONDARY_COMMON, tertiary},
+				collationElement{secondary: _SECONDARY_MARK + _COLLATION_STROKE})
+			continue
+		}
+
+		primary, variant := rootPrimary(lower)
+		if primary == 0 {
+			continue
+		}
+		if variant {
+			tertiary += _TERTIARY_VARIANT
+		}
+		rv = append(rv, collationElement{primary, _SECONDARY_COMMON, tertiary})
+	}
+
+	return rv
+}
+
+func (this *Collation) lower(r rune) rune {
+	if this.language == "tr" || this.language == "az" {
+		return unicode.TurkishCase.ToLower(r)
+	}
+	return unicode.ToLower(r)
+}
+
+/*
+Decomposes s to NFKD, remembering which characters came from a
+compatibility decomposition.
+*/
+func collationDecompose(s string) []collationRune {
+	rv := make([]collationRune, 0, len(s))
+	var buf []rune
+	for _, r := range s {
+		buf = decomposeRune(buf[:0], r, true)
+		compat := false
+		if _, ok := _COMPATIBILITY_DECOMPOSITIONS[r]; ok {
+			compat = true
+		}
+		for _, d := range buf {
+			rv = append(rv, collationRune{d, compat})
+		}
+	}
+
+	for i := 1; i < len(rv); i++ {
+		c := _COMBINING_CLASSES[rv[i].r]
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			p := _COMBINING_CLASSES[rv[j-1].r]
+			if p == 0 || p <= c {
+				break
+			}
+			rv[j-1], rv[j] = rv[j], rv[j-1]
+		}
+	}
+	return rv
+}
+
+/*
+Returns the untailored primary weight of a lowercase base character,
+and whether it is a variant of another character at the primary
+level. Control and format characters are ignorable and weigh 0.
+*/
+func rootPrimary(r rune) (uint32, bool) {
+	var group, value uint32
+	variant := false
+
+	switch {
+	case unicode.IsSpace(r):
+		group, value = _COLLATION_SPACE, uint32(r)
+	case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+		return 0, false
+	case unicode.IsPunct(r):
+		group, value = _COLLATION_PUNCTUATION, uint32(r)
+	case unicode.IsSymbol(r):
+		group, value = _COLLATION_SYMBOL, uint32(r)
+	case unicode.IsDigit(r):
+		// Decimal digits are assigned in contiguous runs from zero
+		zero := r
+		for unicode.IsDigit(zero - 1) {
+			zero--
+		}
+		group, value = _COLLATION_DIGIT, uint32(r-zero)%10
+		variant = r > '9'
+	case unicode.IsNumber(r):
+		group, value = _COLLATION_NUMBER, uint32(r)
+	default:
+		if p, ok := _COLLATION_PLACED[r]; ok {
+			return p, false
+		}
+		group, value = _COLLATION_LETTER, uint32(r)*_COLLATION_GAP
+	}
+
+	return group<<_COLLATION_GROUP_SHIFT | value, variant
+}
+
+/*
+Latin letters that DUCET orders with the letter they derive from: a
+ligature or variant form expands to the letters it stands for, with a
+tertiary difference, a letter with a stroke is the letter with a
+secondary difference, and other letters follow the letter they derive
+from, in the order given.
+*/
+var _COLLATION_EXPANSIONS = map[rune]string{
+	'æ': "ae",
+	'ð': "d",
+	'œ': "oe",
+	'ſ': "s",
+	'ß': "ss",
+}
+
+var _COLLATION_STROKES = map[rune]rune{
+	'đ': 'd',
+	'ħ': 'h',
+	'ł': 'l',
+	'ø': 'o',
+}
+
+// strokes weigh as the combining short stroke overlay
+const _COLLATION_STROKE = 0x0335
+
+var _COLLATION_PLACEMENTS = map[rune]string{
+	'b': "ƀ",
+	'e': "ə",
+	'g': "ǥ",
+	'i': "ıɨ",
+	'n': "ŋ",
+	'q': "ĸ",
+	'r': "ɍ",
+	't': "ŧ",
+	'u': "ʉ",
+	'z': "ƶþ",
+}
+
+var _COLLATION_PLACED map[rune]uint32
+
+/*
+A tailoring assigns weights to lowercase NFD character sequences,
+which may be contractions of several characters.
+*/
+type collationTailoring struct {
+	weights map[string]collationElement
+	longest int
+}
+
+func (this *collationTailoring) match(runes []collationRune, lower func(rune) rune) (collationElement, int) {
+	n := this.longest
+	if n > len(runes) {
+		n = len(runes)
+	}
+
+	buf := make([]rune, n)
+	for i := 0; i < n; i++ {
+		buf[i] = lower(runes[i].r)
+	}
+
+	for ; n > 0; n-- {
+		if w, ok := this.weights[string(buf[:n])]; ok {
+			return w, n
+		}
+	}
+	return collationElement{}, 0
+}
+
+/*
+Tailoring rules follow the ICU syntax: each rule resets to a character
+and places the following characters after it, with < for a primary
+difference, << for a secondary difference, and = for no difference.
+*/
+func newCollationTailoring(rules string) *collationTailoring {
+	rv := &collationTailoring{weights: make(map[string]collationElement)}
+	root := &Collation{language: "root"}
+
+	for _, rule := range strings.Split(rules, "&") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		fields := strings.Fields(strings.NewReplacer("<<", " << ", "<", " < ", "=", " = ").Replace(rule))
+		reset := normalizeString(fields[0], _NFD)
+		prev, ok := rv.weights[reset]
+		if !ok {
+			prev = root.elements(reset)[0]
+		}
+
+		for i := 1; i+1 < len(fields); i += 2 {
+			next := prev
+			switch fields[i] {
+			case "<":
+				next.primary++
+				next.secondary = _SECONDARY_COMMON
+				if next.primary%_COLLATION_GAP >= _COLLATION_GAP/2 {
+					panic("Collation tailoring exceeds gap: " + rule)
+				}
+			case "<<":
+				next.secondary++
+			}
+
+			chars := normalizeString(fields[i+1], _NFD)
+			rv.weights[chars] = next
+			if n := len([]rune(chars)); n > rv.longest {
+				rv.longest = n
+			}
+			prev = next
+		}
+	}
+
+	return rv
+}
+
+var _TAILORINGS map[string]*collationTailoring
+
+func init() {
+	_COLLATION_PLACED = make(map[rune]uint32, 2*len(_COLLATION_PLACEMENTS))
+	for base, letters := range _COLLATION_PLACEMENTS {
+		primary, _ := rootPrimary(base)
+		for i, r := range []rune(letters) {
+			_COLLATION_PLACED[r] = primary + _COLLATION_GAP/2 + uint32(i)
+		}
+	}
+
+	nordic := newCollationTailoring("&z<å<ä<ö &ä<<æ &ö<<ø &y<<ü")
+	norwegian := newCollationTailoring("&z<æ<ø<å &æ<<ä &ø<<ö")
+	turkish := newCollationTailoring("&c<ç &g<ğ &h<ı &i=ı̇ &o<ö &s<ş &u<ü")
+
+	_TAILORINGS = map[string]*collationTailoring{
+		"sv": nordic,
+		"fi": nordic,
+		"da": norwegian,
+		"nb": norwegian,
+		"nn": norwegian,
+		"no": norwegian,
+		"es": newCollationTailoring("&n<ñ"),
+		"tr": turkish,
+		"az": turkish,
+		"cs": newCollationTailoring("&c<č &h<ch &r<ř &s<š &z<ž"),
+	}
+}
@@ -72,3 +72,13 @@ type LikeContext interface {
 	GetLikeRegex(in *Like, s string) *regexp.Regexp
 	CacheLikeRegex(in *Like, s string, re *regexp.Regexp)
 }
+
+type DecimalContext interface {
+	Context
+	UseDecimal() bool
+}
+
+type KeystoreContext interface {
+	Context
+	EncryptionKey(name string) ([]byte, bool)
+}
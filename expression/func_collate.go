@@ -0,0 +1,323 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/value"
+)
+
+///////////////////////////////////////////////////
+//
+// CollationKey
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function COLLATION_KEY(expr, collation),
+which is also written expr COLLATE collation. It returns the sort key
+of a string in the given collation, which compares, sorts and indexes
+in collation order. Values other than strings are returned unchanged.
+The collation is compiled once if it is a constant; a malformed
+collation returns null.
+*/
+type CollationKey struct {
+	BinaryFunctionBase
+	collation *Collation
+}
+
+func NewCollationKey(first, second Expression) Function {
+	rv := &CollationKey{
+		*NewBinaryFunctionBase("collation_key", first, second),
+		nil,
+	}
+
+	if spec := second.Value(); spec != nil && spec.Type() == value.STRING {
+		rv.collation, _ = NewCollation(spec.ToString())
+	}
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *CollationKey) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *CollationKey) Type() value.Type {
+	if this.operands[0].Type() == value.STRING {
+		return value.STRING
+	}
+	return value.JSON
+}
+
+func (this *CollationKey) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if second.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	collation := this.collation
+	if collation == nil {
+		collation, err = NewCollation(second.ToString())
+		if err != nil {
+			return value.NULL_VALUE, nil
+		}
+	}
+
+	if first.Type() != value.STRING {
+		return first, nil
+	}
+	return value.NewValue(collation.Key(first.ToString())), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *CollationKey) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewCollationKey(operands[0], operands[1])
+	}
+}
+
+/*
+Returns the operands of a comparison with the COLLATE clause of one
+operand applied to the other, so that both compare in the same
+collation.
+*/
+func Collated(first, second Expression) (Expression, Expression) {
+	if key, ok := first.(*CollationKey); ok {
+		if _, ok = second.(*CollationKey); !ok {
+			second = NewCollationKey(second, key.Second())
+		}
+	} else if key, ok := second.(*CollationKey); ok {
+		first = NewCollationKey(first, key.Second())
+	}
+
+	return first, second
+}
+
+/*
+Returns the operands of IN and NOT IN with the COLLATE clause of the
+item, or of an element, applied to the item and to every element, so
+that elements match in the same collation. The elements must be known:
+the collection is an array construct or a constant array.
+*/
+func CollatedIn(item, collection Expression) (Expression, Expression, error) {
+	var elems Expressions
+	known := true
+	switch collection := collection.(type) {
+	case *ArrayConstruct:
+		elems = collection.Operands()
+	default:
+		if val := collection.Value(); val != nil && val.Type() == value.ARRAY {
+			for _, v := range val.Actual().([]interface{}) {
+				elems = append(elems, NewConstant(v))
+			}
+		} else {
+			known = false
+		}
+	}
+
+	key, ok := item.(*CollationKey)
+	for _, elem := range elems {
+		if key != nil {
+			break
+		}
+		key, _ = elem.(*CollationKey)
+	}
+	if key == nil {
+		if _, ok = collection.(*CollationKey); ok {
+			return item, collection, fmt.Errorf("COLLATE applies to the elements of IN, not to the array")
+		}
+		return item, collection, nil
+	} else if !known {
+		return item, collection, fmt.Errorf("COLLATE with IN needs the elements listed, or a constant array")
+	}
+
+	if _, ok = item.(*CollationKey); !ok {
+		item = NewCollationKey(item, key.Second())
+	}
+	collated := make(Expressions, len(elems))
+	for i, elem := range elems {
+		if _, ok = elem.(*CollationKey); ok {
+			collated[i] = elem
+		} else {
+			collated[i] = NewCollationKey(elem, key.Second())
+		}
+	}
+	return item, NewArrayConstruct(collated...), nil
+}
+
+/*
+LIKE matches characters, and WITHIN searches nested values, neither of
+which a collation key can stand for.
+*/
+func NotCollated(operator string, operands ...Expression) error {
+	for _, op := range operands {
+		if _, ok := op.(*CollationKey); ok {
+			return fmt.Errorf("COLLATE is not supported with %s", operator)
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////
+//
+// Normalize
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function NORMALIZE(expr [, form ]). It
+returns the string in Unicode normalization form NFC, NFD, NFKC or
+NFKD, NFC by default. An unknown form returns null.
+*/
+type Normalize struct {
+	FunctionBase
+}
+
+func NewNormalize(operands ...Expression) Function {
+	rv := &Normalize{
+		*NewFunctionBase("normalize", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Normalize) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Normalize) Type() value.Type { return value.STRING }
+
+func (this *Normalize) Evaluate(item value.Value, context Context) (value.Value, error) {
+	var s string
+	form := _NFC
+	null := false
+	missing := false
+
+	for i, op := range this.operands {
+		arg, err := op.Evaluate(item, context)
+		if err != nil {
+			return nil, err
+		} else if arg.Type() == value.MISSING {
+			missing = true
+		} else if arg.Type() != value.STRING {
+			null = true
+		} else if !null && !missing {
+			if i == 0 {
+				s = arg.ToString()
+			} else {
+				var ok bool
+				form, ok = newNormalForm(arg.ToString())
+				null = !ok
+			}
+		}
+	}
+
+	if missing {
+		return value.MISSING_VALUE, nil
+	} else if null {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(normalizeString(s, form)), nil
+}
+
+/*
+Minimum input arguments required for the NORMALIZE function
+is 1.
+*/
+func (this *Normalize) MinArgs() int { return 1 }
+
+/*
+Maximum input arguments required for the NORMALIZE function
+is 2.
+*/
+func (this *Normalize) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *Normalize) Constructor() FunctionConstructor {
+	return NewNormalize
+}
+
+///////////////////////////////////////////////////
+//
+// Unaccent
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function UNACCENT(expr). It returns the
+string with accents and other diacritical marks removed, and with
+letters such as ø and æ replaced by their unaccented spelling.
+*/
+type Unaccent struct {
+	UnaryFunctionBase
+}
+
+func NewUnaccent(operand Expression) Function {
+	rv := &Unaccent{
+		*NewUnaryFunctionBase("unaccent", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Unaccent) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Unaccent) Type() value.Type { return value.STRING }
+
+func (this *Unaccent) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if arg.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(unaccentString(arg.ToString())), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Unaccent) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewUnaccent(operands[0])
+	}
+}
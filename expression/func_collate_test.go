@@ -0,0 +1,230 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/couchbase/query/value"
+)
+
+func TestNormalize(t *testing.T) {
+	var tests = []struct {
+		expr     Expression
+		expected string
+	}{
+		{NewNormalize(NewConstant("e\u0301t\u00e9")), `"\u00e9t\u00e9"`},
+		{NewNormalize(NewConstant("\u00e9"), NewConstant("nfd")), `"e\u0301"`},
+		{NewNormalize(NewConstant("\u1e9b\u0323"), NewConstant("NFC")), `"\u1e9b\u0323"`},
+		{NewNormalize(NewConstant("\u1e9b\u0323"), NewConstant("NFKC")), `"\u1e69"`},
+		{NewNormalize(NewConstant("a\u0323\u0302"), NewConstant("NFC")), `"\u1ead"`},
+		{NewNormalize(NewConstant("a\u0302\u0323"), NewConstant("NFC")), `"\u1ead"`},
+		{NewNormalize(NewConstant("\ufb01\u2460"), NewConstant("NFKD")), `"fi1"`},
+		{NewNormalize(NewConstant("\u1100\u1161\u11a8"), NewConstant("NFC")), `"\uac01"`},
+		{NewNormalize(NewConstant("\uac01"), NewConstant("NFD")), `"\u1100\u1161\u11a8"`},
+		{NewNormalize(NewConstant("\u0958"), NewConstant("NFC")), `"\u0915\u093c"`},
+		{NewNormalize(NewConstant("abc"), NewConstant("NFX")), `null`},
+		{NewNormalize(NewConstant(1)), `null`},
+		{NewUnaccent(NewConstant("Crème Brûlée, Łódź, Ærø, Straße")), `"Creme Brulee, Lodz, AEro, Strasse"`},
+		{NewUnaccent(NewConstant(true)), `null`},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+		} else if expected := value.NewValue([]byte(test.expected)); rv.String() != expected.String() {
+			t.Errorf("Test %d: expected %s, got %s", i, test.expected, rv.String())
+		}
+	}
+
+	// composing a decomposition gives the canonical composition
+	for _, r := range []rune{0x00C5, 0x212B, 0x1E69, 0x0344} {
+		if normalizeString(normalizeString(string(r), _NFD), _NFC) != normalizeString(string(r), _NFC) {
+			t.Errorf("%U does not round trip", r)
+		}
+	}
+	if normalizeString("\u212b", _NFC) != "\u00c5" {
+		t.Errorf("Expected angstrom sign to normalize to A with ring")
+	}
+}
+
+func collationSort(t *testing.T, spec string, words []string) []string {
+	collation, err := NewCollation(spec)
+	if err != nil {
+		t.Fatalf("%s: received error %v", spec, err)
+	}
+
+	rv := make([]string, len(words))
+	copy(rv, words)
+	sort.SliceStable(rv, func(i, j int) bool {
+		return collation.Compare(rv[i], rv[j]) < 0
+	})
+	return rv
+}
+
+func TestCollation(t *testing.T) {
+	var tests = []struct {
+		spec     string
+		expected []string
+	}{
+		{"en", []string{" x", "-x", "$x", "10", "2", "a", "A", "à", "À", "ab", "b", "coté", "côte", "Côté", "z"}},
+		{"root", []string{"cote", "coté", "côte", "côté", "ﬁ", "fj", "z"}},
+		{"en", []string{"a", "ae", "æ", "Æ", "af", "b", "d", "ð", "đ", "e", "ə", "f", "i", "ı", "l", "ł", "Ł", "m",
+			"n", "ŋ", "o", "ø", "oe", "œ", "p", "ss", "ß", "ẞ", "st", "t", "ŧ", "u", "z", "þ"}},
+		{"sv_SE", []string{"Andersson", "Zorn", "Åberg", "Ärlig", "Östen"}},
+		{"da", []string{"Zealand", "Ærø", "Øster", "Århus"}},
+		{"es", []string{"nube", "ñandú", "oso"}},
+		{"tr", []string{"çay", "dağ", "hız", "ılık", "İzmir", "ocak", "ödev", "şeker", "uzak", "üzüm"}},
+		{"cs", []string{"cena", "čaj", "hrad", "chata", "ivan"}},
+	}
+
+	for _, test := range tests {
+		shuffled := make([]string, len(test.expected))
+		for i, w := range test.expected {
+			shuffled[len(shuffled)-1-i] = w
+		}
+
+		sorted := collationSort(t, test.spec, shuffled)
+		for i := range sorted {
+			if sorted[i] != test.expected[i] {
+				t.Errorf("%s: expected %q, got %q", test.spec, test.expected, sorted)
+				break
+			}
+		}
+	}
+
+	var compare = []struct {
+		spec     string
+		first    string
+		second   string
+		expected int
+	}{
+		{"en", "resume", "Resume", -1},
+		{"en-ci", "resume", "RESUME", 0},
+		{"en-ci", "resume", "résumé", -1},
+		{"en-ci-ai", "resume", "RÉSUMÉ", 0},
+		{"en-ai", "resume", "résumé", 0},
+		{"en-ai", "resume", "Résumé", -1},
+		{"en", "é", "é", 0},
+		{"en-ci", "Ａ", "a", 0},
+		{"en", "٣", "3", 1},
+		{"en-ci", "٣", "3", 0},
+		{"en", "a\u0000b", "ab", 0},
+		{"tr-ci", "I", "ı", 0},
+		{"tr-ci", "İ", "i", 0},
+		{"en-ci", "I", "i", 0},
+		{"sv-ci-ai", "ø", "ö", 0},
+		{"en-ci", "Straße", "STRASSE", 0},
+		{"en", "Straße", "strasse", 1},
+		{"en-ai", "łódź", "lodz", 0},
+		{"en", "łódź", "lodz", 1},
+		{"en-ai", "ħ", "h", 0},
+		{"da", "æ", "z", 1},
+		{"sv", "æ", "ä", 1},
+	}
+
+	for _, test := range compare {
+		collation, err := NewCollation(test.spec)
+		if err != nil {
+			t.Errorf("%s: received error %v", test.spec, err)
+		} else if rv := collation.Compare(test.first, test.second); rv != test.expected {
+			t.Errorf("%s: expected %q vs %q to be %d, got %d", test.spec, test.first, test.second, test.expected, rv)
+		}
+	}
+
+	for _, spec := range []string{"", "x", "en-ci-ci", "en-xx-ci-us", "en-ci-fr", "e n"} {
+		if _, err := NewCollation(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
+
+func TestCollationKey(t *testing.T) {
+	f := NewCollationKey(NewIdentifier("name"), NewConstant("fr-ci"))
+	if f.(*CollationKey).collation == nil {
+		t.Errorf("Expected constant collation to be precompiled")
+	}
+
+	var tests = []struct {
+		first    interface{}
+		second   interface{}
+		expected int
+	}{
+		{"Ecole", "école", -1},
+		{"ECOLE", "ecole", 0},
+		{"ecole", "ecoles", -1},
+		{"eclair", "École", -1},
+	}
+
+	for _, test := range tests {
+		first, _ := f.Evaluate(value.NewValue(map[string]interface{}{"name": test.first}), nil)
+		second, _ := f.Evaluate(value.NewValue(map[string]interface{}{"name": test.second}), nil)
+		if rv := first.Collate(second); (rv > 0) != (test.expected > 0) || (rv < 0) != (test.expected < 0) {
+			t.Errorf("%v vs %v: expected %d, got %d", test.first, test.second, test.expected, rv)
+		}
+	}
+
+	rv, _ := f.Evaluate(value.NewValue(map[string]interface{}{"name": 7}), nil)
+	if rv.String() != "7" {
+		t.Errorf("Expected number to be unchanged, got %v", rv)
+	}
+
+	rv, _ = NewCollationKey(NewConstant("a"), NewConstant("not a collation")).Evaluate(nil, nil)
+	if rv.Type() != value.NULL {
+		t.Errorf("Expected null, got %v", rv)
+	}
+
+	first, second := Collated(f, NewConstant("ÉCOLE"))
+	if _, ok := second.(*CollationKey); !ok || first != f {
+		t.Errorf("Expected collation to apply to %v", second)
+	}
+	first, second = Collated(NewConstant("a"), NewConstant("b"))
+	if _, ok := first.(*CollationKey); ok {
+		t.Errorf("Expected no collation, got %v", first)
+	}
+}
+
+func TestCollatedIn(t *testing.T) {
+	name := NewIdentifier("name")
+	key := NewCollationKey(name, NewConstant("en-ci"))
+	doc := value.NewValue(map[string]interface{}{"name": "Jose"})
+
+	var tests = []struct {
+		item       Expression
+		collection Expression
+		expected   bool
+	}{
+		{key, NewConstant([]interface{}{"jose", "ana"}), true},
+		{key, NewArrayConstruct(NewConstant("ANA"), NewConstant("JOSE")), true},
+		{name, NewArrayConstruct(NewConstant("ana"), NewCollationKey(NewConstant("JOSE"), NewConstant("en-ci"))), true},
+		{key, NewConstant([]interface{}{"joseph", 7}), false},
+		{name, NewConstant([]interface{}{"jose"}), false},
+	}
+
+	for _, test := range tests {
+		item, collection, err := CollatedIn(test.item, test.collection)
+		if err != nil {
+			t.Errorf("Unexpected error for %v IN %v: %v", test.item, test.collection, err)
+			continue
+		}
+		rv, _ := NewIn(item, collection).Evaluate(doc, nil)
+		if rv.Truth() != test.expected {
+			t.Errorf("%v IN %v: expected %v, got %v", test.item, test.collection, test.expected, rv)
+		}
+	}
+
+	if _, _, err := CollatedIn(key, NewIdentifier("names")); err == nil {
+		t.Errorf("Expected an error for elements that are not known")
+	}
+	if err := NotCollated("LIKE", name, key); err == nil {
+		t.Errorf("Expected an error for LIKE")
+	}
+}
@@ -9,18 +9,28 @@
 package expression
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
 	"hash"
 	"hash/crc32"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/logging"
 	"github.com/couchbase/query/util"
 	"github.com/couchbase/query/value"
 	"golang.org/x/crypto/md4"
+	"golang.org/x/crypto/sha3"
 )
 
 type Hashbytes struct {
@@ -181,3 +191,750 @@ func (this *Hashbytes) MinArgs() int { return 1 }
 func (this *Hashbytes) MaxArgs() int { return 2 }
 
 func (this *Hashbytes) Constructor() FunctionConstructor { return NewHashbytes }
+
+/*
+Hash functions that HMAC accepts, by lowercase name.
+*/
+var _DIGESTS = map[string]func() hash.Hash{
+	"md5":        md5.New,
+	"sha1":       sha1.New,
+	"sha224":     sha256.New224,
+	"sha256":     sha256.New,
+	"sha384":     sha512.New384,
+	"sha512":     sha512.New,
+	"sha512/224": sha512.New512_224,
+	"sha512/256": sha512.New512_256,
+	"sha3-224":   sha3.New224,
+	"sha3-256":   sha3.New256,
+	"sha3-384":   sha3.New384,
+	"sha3-512":   sha3.New512,
+}
+
+var _SHA3_DIGESTS = map[int]func() hash.Hash{
+	224: sha3.New224,
+	256: sha3.New256,
+	384: sha3.New384,
+	512: sha3.New512,
+}
+
+func cryptoEvaluate(operands Expressions, item value.Value, context Context) (
+	[]value.Value, value.Value, error) {
+
+	missing := false
+	null := false
+	args := make([]value.Value, len(operands))
+	for i, op := range operands {
+		arg, err := op.Evaluate(item, context)
+		if err != nil {
+			return nil, nil, err
+		} else if arg.Type() == value.MISSING {
+			missing = true
+		} else if arg.Type() == value.NULL {
+			null = true
+		}
+		args[i] = arg
+	}
+
+	if missing {
+		return nil, value.MISSING_VALUE, nil
+	} else if null {
+		return nil, value.NULL_VALUE, nil
+	}
+	return args, nil, nil
+}
+
+/*
+Returns the bytes that digests, HMAC and ENCRYPT operate on: the
+contents of a binary value, the UTF-8 encoding of a string, and the
+JSON encoding of any other value. Unlike HASHBYTES, a string is not
+quoted, so that digests match those computed outside the server.
+*/
+func cryptoBytes(arg value.Value) ([]byte, error) {
+	switch arg.Type() {
+	case value.BINARY:
+		return arg.Actual().([]byte), nil
+	case value.STRING:
+		return []byte(arg.ToString()), nil
+	}
+	return arg.MarshalJSON()
+}
+
+/*
+Encodes a digest as hex, the default, or base64.
+*/
+func encodeDigest(digest []byte, args []value.Value, pos int) value.Value {
+	encoding := "hex"
+	if pos < len(args) {
+		if args[pos].Type() != value.STRING {
+			return value.NULL_VALUE
+		}
+		encoding = strings.ToLower(args[pos].ToString())
+	}
+
+	switch encoding {
+	case "hex":
+		return value.NewValue(hex.EncodeToString(digest))
+	case "base64":
+		return value.NewValue(base64.StdEncoding.EncodeToString(digest))
+	}
+	return value.NULL_VALUE
+}
+
+func digestEvaluate(operands Expressions, newHash func() hash.Hash, item value.Value, context Context) (
+	value.Value, error) {
+
+	args, rv, err := cryptoEvaluate(operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+	return digest(newHash, args[0], args, 1)
+}
+
+func digest(newHash func() hash.Hash, arg value.Value, args []value.Value, encodingPos int) (value.Value, error) {
+	data, err := cryptoBytes(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	h := newHash()
+	h.Write(data)
+	return encodeDigest(h.Sum(nil), args, encodingPos), nil
+}
+
+///////////////////////////////////////////////////
+//
+// SHA224
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function SHA224(expr [, encoding ]). It
+returns the SHA-224 digest of expr, encoded as 'hex' (the default) or
+'base64'.
+*/
+type SHA224 struct {
+	FunctionBase
+}
+
+func NewSHA224(operands ...Expression) Function {
+	rv := &SHA224{
+		*NewFunctionBase("sha224", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *SHA224) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *SHA224) Type() value.Type { return value.STRING }
+
+func (this *SHA224) Evaluate(item value.Value, context Context) (value.Value, error) {
+	return digestEvaluate(this.operands, sha256.New224, item, context)
+}
+
+func (this *SHA224) MinArgs() int { return 1 }
+
+func (this *SHA224) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *SHA224) Constructor() FunctionConstructor {
+	return NewSHA224
+}
+
+///////////////////////////////////////////////////
+//
+// SHA256
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function SHA256(expr [, encoding ]). It
+returns the SHA-256 digest of expr, encoded as 'hex' (the default) or
+'base64'.
+*/
+type SHA256 struct {
+	FunctionBase
+}
+
+func NewSHA256(operands ...Expression) Function {
+	rv := &SHA256{
+		*NewFunctionBase("sha256", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *SHA256) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *SHA256) Type() value.Type { return value.STRING }
+
+func (this *SHA256) Evaluate(item value.Value, context Context) (value.Value, error) {
+	return digestEvaluate(this.operands, sha256.New, item, context)
+}
+
+func (this *SHA256) MinArgs() int { return 1 }
+
+func (this *SHA256) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *SHA256) Constructor() FunctionConstructor {
+	return NewSHA256
+}
+
+///////////////////////////////////////////////////
+//
+// SHA384
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function SHA384(expr [, encoding ]). It
+returns the SHA-384 digest of expr, encoded as 'hex' (the default) or
+'base64'.
+*/
+type SHA384 struct {
+	FunctionBase
+}
+
+func NewSHA384(operands ...Expression) Function {
+	rv := &SHA384{
+		*NewFunctionBase("sha384", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *SHA384) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *SHA384) Type() value.Type { return value.STRING }
+
+func (this *SHA384) Evaluate(item value.Value, context Context) (value.Value, error) {
+	return digestEvaluate(this.operands, sha512.New384, item, context)
+}
+
+func (this *SHA384) MinArgs() int { return 1 }
+
+func (this *SHA384) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *SHA384) Constructor() FunctionConstructor {
+	return NewSHA384
+}
+
+///////////////////////////////////////////////////
+//
+// SHA512
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function SHA512(expr [, encoding ]). It
+returns the SHA-512 digest of expr, encoded as 'hex' (the default) or
+'base64'.
+*/
+type SHA512 struct {
+	FunctionBase
+}
+
+func NewSHA512(operands ...Expression) Function {
+	rv := &SHA512{
+		*NewFunctionBase("sha512", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *SHA512) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *SHA512) Type() value.Type { return value.STRING }
+
+func (this *SHA512) Evaluate(item value.Value, context Context) (value.Value, error) {
+	return digestEvaluate(this.operands, sha512.New, item, context)
+}
+
+func (this *SHA512) MinArgs() int { return 1 }
+
+func (this *SHA512) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *SHA512) Constructor() FunctionConstructor {
+	return NewSHA512
+}
+
+///////////////////////////////////////////////////
+//
+// SHA3
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function SHA3(expr [, bits [, encoding ]]).
+It returns the SHA3-224, SHA3-256 (the default), SHA3-384 or SHA3-512
+digest of expr, encoded as 'hex' (the default) or 'base64'.
+*/
+type SHA3 struct {
+	FunctionBase
+}
+
+func NewSHA3(operands ...Expression) Function {
+	rv := &SHA3{
+		*NewFunctionBase("sha3", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *SHA3) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *SHA3) Type() value.Type { return value.STRING }
+
+func (this *SHA3) Evaluate(item value.Value, context Context) (value.Value, error) {
+	args, rv, err := cryptoEvaluate(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	newHash := _SHA3_DIGESTS[256]
+	if len(args) > 1 {
+		bits, ok := value.IsIntValue(args[1])
+		if ok {
+			newHash, ok = _SHA3_DIGESTS[int(bits)]
+		}
+		if !ok {
+			return value.NULL_VALUE, nil
+		}
+	}
+
+	return digest(newHash, args[0], args, 2)
+}
+
+func (this *SHA3) MinArgs() int { return 1 }
+
+func (this *SHA3) MaxArgs() int { return 3 }
+
+/*
+Factory method pattern.
+*/
+func (this *SHA3) Constructor() FunctionConstructor {
+	return NewSHA3
+}
+
+///////////////////////////////////////////////////
+//
+// HMAC
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function HMAC(algorithm, key, data
+[, encoding ]). It returns the keyed-hash message authentication code
+of data, encoded as 'hex' (the default) or 'base64'. The algorithm is
+one of md5, sha1, sha224, sha256, sha384, sha512, sha512/224,
+sha512/256, sha3-224, sha3-256, sha3-384 or sha3-512, and the key is a
+string or binary value.
+*/
+type HMAC struct {
+	FunctionBase
+}
+
+func NewHMAC(operands ...Expression) Function {
+	rv := &HMAC{
+		*NewFunctionBase("hmac", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *HMAC) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *HMAC) Type() value.Type { return value.STRING }
+
+func (this *HMAC) Evaluate(item value.Value, context Context) (value.Value, error) {
+	args, rv, err := cryptoEvaluate(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	if args[0].Type() != value.STRING ||
+		(args[1].Type() != value.STRING && args[1].Type() != value.BINARY) {
+		return value.NULL_VALUE, nil
+	}
+
+	algo := strings.Replace(strings.ToLower(args[0].ToString()), "sha-", "sha", 1)
+	newHash, ok := _DIGESTS[algo]
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+
+	key, _ := cryptoBytes(args[1])
+	data, err := cryptoBytes(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(newHash, key)
+	mac.Write(data)
+	return encodeDigest(mac.Sum(nil), args, 3), nil
+}
+
+func (this *HMAC) MinArgs() int { return 3 }
+
+func (this *HMAC) MaxArgs() int { return 4 }
+
+/*
+Factory method pattern.
+*/
+func (this *HMAC) Constructor() FunctionConstructor {
+	return NewHMAC
+}
+
+///////////////////////////////////////////////////
+//
+// HexEncode
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function HEX_ENCODE(expr). It returns the
+lowercase hexadecimal encoding of a binary value or of the UTF-8
+encoding of a string.
+*/
+type HexEncode struct {
+	UnaryFunctionBase
+}
+
+func NewHexEncode(operand Expression) Function {
+	rv := &HexEncode{
+		*NewUnaryFunctionBase("hex_encode", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *HexEncode) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *HexEncode) Type() value.Type { return value.STRING }
+
+func (this *HexEncode) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if arg.Type() != value.BINARY && arg.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	bytes, _ := cryptoBytes(arg)
+	return value.NewValue(hex.EncodeToString(bytes)), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *HexEncode) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewHexEncode(operands[0])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// HexDecode
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function HEX_DECODE(expr). It returns the
+binary value encoded by a hexadecimal string, or null if the string
+is not valid hexadecimal.
+*/
+type HexDecode struct {
+	UnaryFunctionBase
+}
+
+func NewHexDecode(operand Expression) Function {
+	rv := &HexDecode{
+		*NewUnaryFunctionBase("hex_decode", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *HexDecode) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *HexDecode) Type() value.Type { return value.BINARY }
+
+func (this *HexDecode) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if arg.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	bytes, err := hex.DecodeString(arg.ToString())
+	if err != nil {
+		return value.NULL_VALUE, nil
+	}
+	return value.NewBinaryValue(bytes), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *HexDecode) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewHexDecode(operands[0])
+	}
+}
+
+/*
+Returns the AES-GCM cipher for the named key in the server keystore.
+Statements refer to keys only by name, so that key material never
+appears in statement text, plans, logs or completed_requests.
+*/
+func keystoreCipher(name string, context Context) (cipher.AEAD, error) {
+	var key []byte
+	ok := false
+	if keystoreContext, isKeystore := context.(KeystoreContext); isKeystore {
+		key, ok = keystoreContext.EncryptionKey(name)
+	}
+	if !ok {
+		return nil, errors.NewEncryptionKeyNotFoundError(name)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+///////////////////////////////////////////////////
+//
+// Encrypt
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function ENCRYPT(expr, key_name). It
+encrypts expr with AES-GCM, using the named key from the server
+keystore and a random nonce, and returns the base64 encoding of the
+nonce followed by the ciphertext and authentication tag. Strings are
+encrypted as UTF-8, binary values as is, and other values as JSON.
+Since every call returns a different ciphertext, ENCRYPT is never
+evaluated ahead of execution.
+*/
+type Encrypt struct {
+	BinaryFunctionBase
+}
+
+func NewEncrypt(first, second Expression) Function {
+	rv := &Encrypt{
+		*NewBinaryFunctionBase("encrypt", first, second),
+	}
+
+	rv.setVolatile()
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Encrypt) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Encrypt) Type() value.Type { return value.STRING }
+
+func (this *Encrypt) Evaluate(item value.Value, context Context) (value.Value, error) {
+	args, rv, err := cryptoEvaluate(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	} else if args[1].Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	aead, err := keystoreCipher(args[1].ToString(), context)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := cryptoBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return value.NewValue(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (this *Encrypt) Indexable() bool {
+	return false
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Encrypt) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewEncrypt(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// Decrypt
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Crypto function DECRYPT(expr, key_name). It
+decrypts a value produced by ENCRYPT, given as a base64 string or a
+binary value, using the named key from the server keystore. It
+returns a string if the plaintext is valid UTF-8, and a binary value
+otherwise. A value that is not valid base64 returns null, and a value
+that fails authentication is an error. DECRYPT is never evaluated
+ahead of execution, so plaintext does not appear in plans.
+*/
+type Decrypt struct {
+	BinaryFunctionBase
+}
+
+func NewDecrypt(first, second Expression) Function {
+	rv := &Decrypt{
+		*NewBinaryFunctionBase("decrypt", first, second),
+	}
+
+	rv.setVolatile()
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Decrypt) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Decrypt) Type() value.Type { return value.JSON }
+
+func (this *Decrypt) Evaluate(item value.Value, context Context) (value.Value, error) {
+	args, rv, err := cryptoEvaluate(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	} else if args[1].Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	var sealed []byte
+	switch args[0].Type() {
+	case value.BINARY:
+		sealed = args[0].Actual().([]byte)
+	case value.STRING:
+		sealed, err = base64.StdEncoding.DecodeString(args[0].ToString())
+		if err != nil {
+			return value.NULL_VALUE, nil
+		}
+	default:
+		return value.NULL_VALUE, nil
+	}
+
+	name := args[1].ToString()
+	aead, err := keystoreCipher(name, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.NewDecryptionError(name)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.NewDecryptionError(name)
+	}
+
+	if utf8.Valid(plaintext) {
+		return value.NewValue(string(plaintext)), nil
+	}
+	return value.NewBinaryValue(plaintext), nil
+}
+
+func (this *Decrypt) Indexable() bool {
+	return false
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Decrypt) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewDecrypt(operands[0], operands[1])
+	}
+}
@@ -0,0 +1,130 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/value"
+)
+
+func TestDigests(t *testing.T) {
+	var tests = []struct {
+		expr     Expression
+		expected string
+	}{
+		{NewSHA224(NewConstant("abc")), `"23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7"`},
+		{NewSHA256(NewConstant("abc")), `"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"`},
+		{NewSHA256(NewConstant(map[string]interface{}{"a": 1})), `"015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"`},
+		{NewSHA512(NewConstant("abc"), NewConstant("base64")),
+			`"3a81oZNherrMQXNJriBBMRLm+k6JqX6iCp7u5ktV05ohkpkqJ0/BqDa6PCOj/uu9RU1EI2Q86A4qmslPpUyknw=="`},
+		{NewSHA3(NewConstant("abc")), `"3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"`},
+		{NewSHA3(NewConstant("abc"), NewConstant(512)),
+			`"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"`},
+		{NewSHA3(NewConstant("abc"), NewConstant(100)), `null`},
+		{NewSHA256(NewConstant("abc"), NewConstant("base32")), `null`},
+		{NewSHA384(NewConstant(nil)), `null`},
+		{NewHMAC(NewConstant("sha256"), NewConstant("Jefe"), NewConstant("what do ya want for nothing?")),
+			`"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"`},
+		{NewHMAC(NewConstant("SHA3-256"), NewConstant("Jefe"), NewConstant("what do ya want for nothing?")),
+			`"c7d4072e788877ae3596bbb0da73b887c9171f93095b294ae857fbe2645e1ba5"`},
+		{NewHMAC(NewConstant("sha-1"), NewConstant("key"), NewConstant("data"), NewConstant("base64")),
+			`"EEFSxb/coHvGM+69RhmfAlXJ9J0="`},
+		{NewHMAC(NewConstant("sha0"), NewConstant("key"), NewConstant("data")), `null`},
+		{NewHMAC(NewConstant("md5"), NewConstant(1), NewConstant("data")), `null`},
+		{NewHexEncode(NewConstant("Hi!")), `"486921"`},
+		{NewHexEncode(NewHexDecode(NewConstant("00FF7f"))), `"00ff7f"`},
+		{NewHexDecode(NewConstant("0g")), `null`},
+		{NewHexEncode(NewConstant(12)), `null`},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+		} else if expected := value.NewValue([]byte(test.expected)); rv.String() != expected.String() {
+			t.Errorf("Test %d: expected %s, got %s", i, test.expected, rv.String())
+		}
+	}
+
+	rv, _ := NewSHA256(NewConstant("abc"), NewIdentifier("missing")).Evaluate(value.NewValue(map[string]interface{}{}), nil)
+	if rv.Type() != value.MISSING {
+		t.Errorf("Expected missing, got %v", rv)
+	}
+
+	rv, _ = NewHexDecode(NewConstant("00ff")).Evaluate(nil, nil)
+	if rv.Type() != value.BINARY {
+		t.Errorf("Expected binary, got %v", rv.Type())
+	}
+}
+
+type keystoreTestContext struct {
+	Context
+	keys map[string][]byte
+}
+
+func (this *keystoreTestContext) EncryptionKey(name string) ([]byte, bool) {
+	key, ok := this.keys[name]
+	return key, ok
+}
+
+func TestEncryption(t *testing.T) {
+	context := &keystoreTestContext{keys: map[string][]byte{
+		"k128": []byte("0123456789abcdef"),
+		"k256": []byte("0123456789abcdef0123456789abcdef"),
+	}}
+
+	for _, name := range []string{"k128", "k256"} {
+		encrypt := NewEncrypt(NewConstant("secret"), NewConstant(name))
+		if encrypt.Value() != nil {
+			t.Errorf("Expected ENCRYPT not to be constant folded")
+		}
+
+		first, err := encrypt.Evaluate(nil, context)
+		if err != nil {
+			t.Fatalf("%s: received error %v", name, err)
+		}
+		second, _ := encrypt.Evaluate(nil, context)
+		if first.Type() != value.STRING || first.Equals(second).Truth() {
+			t.Errorf("%s: expected distinct ciphertexts, got %v and %v", name, first, second)
+		}
+
+		rv, err := NewDecrypt(NewConstant(first), NewConstant(name)).Evaluate(nil, context)
+		if err != nil || rv.String() != `"secret"` {
+			t.Errorf("%s: expected secret, got %v, %v", name, rv, err)
+		}
+	}
+
+	sealed, _ := NewEncrypt(NewConstant(value.NewBinaryValue([]byte{0xff, 0x00})), NewConstant("k128")).Evaluate(nil, context)
+	rv, _ := NewDecrypt(NewConstant(sealed), NewConstant("k128")).Evaluate(nil, context)
+	if rv.Type() != value.BINARY {
+		t.Errorf("Expected binary plaintext, got %v", rv)
+	}
+
+	_, err := NewDecrypt(NewConstant(sealed), NewConstant("k256")).Evaluate(nil, context)
+	if e, ok := err.(errors.Error); !ok || e.Code() != errors.E_DECRYPTION {
+		t.Errorf("Expected decryption error, got %v", err)
+	}
+
+	_, err = NewEncrypt(NewConstant("secret"), NewConstant("none")).Evaluate(nil, context)
+	if e, ok := err.(errors.Error); !ok || e.Code() != errors.E_ENCRYPTION_KEY_NOT_FOUND {
+		t.Errorf("Expected key not found error, got %v", err)
+	}
+
+	_, err = NewEncrypt(NewConstant("secret"), NewConstant("k128")).Evaluate(nil, nil)
+	if err == nil {
+		t.Errorf("Expected error without a keystore")
+	}
+
+	rv, _ = NewDecrypt(NewConstant("not base64!"), NewConstant("k128")).Evaluate(nil, context)
+	if rv.Type() != value.NULL {
+		t.Errorf("Expected null, got %v", rv)
+	}
+}
@@ -0,0 +1,683 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"math"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Geospatial predicates that can be answered from an index on
+ST_GEOHASH() of one of their geometry operands. GeoDistance is nil
+unless the predicate is a distance predicate.
+*/
+type GeoPredicate interface {
+	Function
+	GeoOperands() (first, second Expression)
+	GeoDistance() Expression
+}
+
+func geoOperand(arg value.Value) (*geometry, bool) {
+	val, ok := geoValue(arg)
+	if !ok {
+		return nil, false
+	}
+	return newGeometry(val)
+}
+
+func geoEvaluate(operands Expressions, item value.Value, context Context) (
+	[]*geometry, value.Value, error) {
+
+	missing := false
+	null := false
+	geoms := make([]*geometry, 0, 2)
+	for _, op := range operands {
+		arg, err := op.Evaluate(item, context)
+		if err != nil {
+			return nil, nil, err
+		} else if arg.Type() == value.MISSING {
+			missing = true
+		} else if geom, ok := geoOperand(arg); ok {
+			geoms = append(geoms, geom)
+		} else {
+			null = true
+		}
+	}
+
+	if missing {
+		return nil, value.MISSING_VALUE, nil
+	} else if null {
+		return nil, value.NULL_VALUE, nil
+	}
+	return geoms, nil, nil
+}
+
+///////////////////////////////////////////////////
+//
+// STArea
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_AREA(geom). It returns
+the spherical area of the polygons of a GeoJSON geometry in square
+meters.
+*/
+type STArea struct {
+	UnaryFunctionBase
+}
+
+func NewSTArea(operand Expression) Function {
+	rv := &STArea{
+		*NewUnaryFunctionBase("st_area", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STArea) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STArea) Type() value.Type { return value.NUMBER }
+
+func (this *STArea) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands, item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	return value.NewValue(geoms[0].area()), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STArea) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTArea(operands[0])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STContains
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_CONTAINS(geom1, geom2).
+It returns true if the second geometry lies entirely within the
+first.
+*/
+type STContains struct {
+	BinaryFunctionBase
+}
+
+func NewSTContains(first, second Expression) Function {
+	rv := &STContains{
+		*NewBinaryFunctionBase("st_contains", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STContains) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STContains) Type() value.Type { return value.BOOLEAN }
+
+func (this *STContains) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands, item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	return value.NewValue(geoms[0].contains(geoms[1])), nil
+}
+
+func (this *STContains) GeoOperands() (first, second Expression) {
+	return this.operands[0], this.operands[1]
+}
+
+func (this *STContains) GeoDistance() Expression { return nil }
+
+/*
+If this expression is in the WHERE clause of a partial index, lists
+the Expressions that are implicitly covered.
+
+For boolean functions, simply list this expression.
+*/
+func (this *STContains) FilterCovers(covers map[string]value.Value) map[string]value.Value {
+	covers[this.String()] = value.TRUE_VALUE
+	return covers
+}
+
+func (this *STContains) FilterExpressionCovers(covers map[Expression]value.Value) map[Expression]value.Value {
+	covers[this] = value.TRUE_VALUE
+	return covers
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STContains) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTContains(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STDistance
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_DISTANCE(geom1, geom2).
+It returns the minimum great circle distance between two geometries
+in meters, or 0 if they intersect.
+*/
+type STDistance struct {
+	BinaryFunctionBase
+}
+
+func NewSTDistance(first, second Expression) Function {
+	rv := &STDistance{
+		*NewBinaryFunctionBase("st_distance", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STDistance) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STDistance) Type() value.Type { return value.NUMBER }
+
+func (this *STDistance) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands, item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	return value.NewValue(geoms[0].distance(geoms[1])), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STDistance) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTDistance(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STDWithin
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_DWITHIN(geom1, geom2,
+meters). It returns true if the geometries are within the given
+distance of each other.
+*/
+type STDWithin struct {
+	TernaryFunctionBase
+}
+
+func NewSTDWithin(first, second, third Expression) Function {
+	rv := &STDWithin{
+		*NewTernaryFunctionBase("st_dwithin", first, second, third),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STDWithin) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STDWithin) Type() value.Type { return value.BOOLEAN }
+
+func (this *STDWithin) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands[:2], item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	dist, err := this.operands[2].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if dist.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if dist.Type() != value.NUMBER {
+		return value.NULL_VALUE, nil
+	}
+
+	d := dist.Actual().(float64)
+	if math.IsNaN(d) || d < 0.0 {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(geoms[0].distance(geoms[1]) <= d), nil
+}
+
+func (this *STDWithin) GeoOperands() (first, second Expression) {
+	return this.operands[0], this.operands[1]
+}
+
+func (this *STDWithin) GeoDistance() Expression { return this.operands[2] }
+
+/*
+If this expression is in the WHERE clause of a partial index, lists
+the Expressions that are implicitly covered.
+
+For boolean functions, simply list this expression.
+*/
+func (this *STDWithin) FilterCovers(covers map[string]value.Value) map[string]value.Value {
+	covers[this.String()] = value.TRUE_VALUE
+	return covers
+}
+
+func (this *STDWithin) FilterExpressionCovers(covers map[Expression]value.Value) map[Expression]value.Value {
+	covers[this] = value.TRUE_VALUE
+	return covers
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STDWithin) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTDWithin(operands[0], operands[1], operands[2])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STGeohash
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_GEOHASH(geom [, precision]).
+It returns the geohash of the smallest cell, of at most precision
+characters (default and maximum 12), that contains the whole
+geometry. Indexes on it can be used by GeoPredicates.
+*/
+type STGeohash struct {
+	FunctionBase
+}
+
+func NewSTGeohash(operands ...Expression) Function {
+	rv := &STGeohash{
+		*NewFunctionBase("st_geohash", operands...),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STGeohash) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STGeohash) Type() value.Type { return value.STRING }
+
+func (this *STGeohash) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands[:1], item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	p := _GEOHASH_MAX_PRECISION
+	if len(this.operands) > 1 {
+		prec, err := this.operands[1].Evaluate(item, context)
+		if err != nil {
+			return nil, err
+		} else if prec.Type() == value.MISSING {
+			return value.MISSING_VALUE, nil
+		} else if prec.Type() != value.NUMBER {
+			return value.NULL_VALUE, nil
+		}
+
+		pf := prec.Actual().(float64)
+		if pf != math.Trunc(pf) || pf < 1.0 || pf > _GEOHASH_MAX_PRECISION {
+			return value.NULL_VALUE, nil
+		}
+		p = int(pf)
+	}
+
+	return value.NewValue(geoms[0].geohash(p)), nil
+}
+
+/*
+The geometry operand.
+*/
+func (this *STGeohash) Geometry() Expression {
+	return this.operands[0]
+}
+
+/*
+The precision, if known at plan time.
+*/
+func (this *STGeohash) Precision() (int, bool) {
+	if len(this.operands) < 2 {
+		return _GEOHASH_MAX_PRECISION, true
+	}
+
+	prec := this.operands[1].Value()
+	if prec == nil || prec.Type() != value.NUMBER {
+		return 0, false
+	}
+
+	pf := prec.Actual().(float64)
+	if pf != math.Trunc(pf) || pf < 1.0 || pf > _GEOHASH_MAX_PRECISION {
+		return 0, false
+	}
+	return int(pf), true
+}
+
+func (this *STGeohash) MinArgs() int { return 1 }
+
+func (this *STGeohash) MaxArgs() int { return 2 }
+
+/*
+Factory method pattern.
+*/
+func (this *STGeohash) Constructor() FunctionConstructor {
+	return NewSTGeohash
+}
+
+///////////////////////////////////////////////////
+//
+// STGeomFromGeoJSON
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_GEOMFROMGEOJSON(expr). It
+accepts a GeoJSON object or its string encoding and returns the
+object if it is a valid geometry, Feature or FeatureCollection, and
+NULL otherwise.
+*/
+type STGeomFromGeoJSON struct {
+	UnaryFunctionBase
+}
+
+func NewSTGeomFromGeoJSON(operand Expression) Function {
+	rv := &STGeomFromGeoJSON{
+		*NewUnaryFunctionBase("st_geomfromgeojson", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STGeomFromGeoJSON) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STGeomFromGeoJSON) Type() value.Type { return value.OBJECT }
+
+func (this *STGeomFromGeoJSON) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	}
+
+	val, ok := geoValue(arg)
+	if ok {
+		_, ok = newGeometry(val)
+	}
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+	return val, nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STGeomFromGeoJSON) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTGeomFromGeoJSON(operands[0])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STIntersects
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_INTERSECTS(geom1, geom2).
+It returns true if the geometries share at least one point.
+*/
+type STIntersects struct {
+	BinaryFunctionBase
+}
+
+func NewSTIntersects(first, second Expression) Function {
+	rv := &STIntersects{
+		*NewBinaryFunctionBase("st_intersects", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STIntersects) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STIntersects) Type() value.Type { return value.BOOLEAN }
+
+func (this *STIntersects) Evaluate(item value.Value, context Context) (value.Value, error) {
+	geoms, rv, err := geoEvaluate(this.operands, item, context)
+	if geoms == nil {
+		return rv, err
+	}
+
+	return value.NewValue(geoms[0].intersects(geoms[1])), nil
+}
+
+func (this *STIntersects) GeoOperands() (first, second Expression) {
+	return this.operands[0], this.operands[1]
+}
+
+func (this *STIntersects) GeoDistance() Expression { return nil }
+
+/*
+If this expression is in the WHERE clause of a partial index, lists
+the Expressions that are implicitly covered.
+
+For boolean functions, simply list this expression.
+*/
+func (this *STIntersects) FilterCovers(covers map[string]value.Value) map[string]value.Value {
+	covers[this.String()] = value.TRUE_VALUE
+	return covers
+}
+
+func (this *STIntersects) FilterExpressionCovers(covers map[Expression]value.Value) map[Expression]value.Value {
+	covers[this] = value.TRUE_VALUE
+	return covers
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STIntersects) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTIntersects(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STIsValid
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_ISVALID(expr). It returns
+true if the argument is a valid GeoJSON object or string encoding,
+false if it is some other object or string, and NULL otherwise.
+*/
+type STIsValid struct {
+	UnaryFunctionBase
+}
+
+func NewSTIsValid(operand Expression) Function {
+	rv := &STIsValid{
+		*NewUnaryFunctionBase("st_isvalid", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STIsValid) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STIsValid) Type() value.Type { return value.BOOLEAN }
+
+func (this *STIsValid) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arg.Type() {
+	case value.MISSING:
+		return value.MISSING_VALUE, nil
+	case value.OBJECT, value.STRING:
+		_, ok := geoOperand(arg)
+		return value.NewValue(ok), nil
+	default:
+		return value.NULL_VALUE, nil
+	}
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STIsValid) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTIsValid(operands[0])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// STPoint
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the geospatial function ST_POINT(lon, lat). It
+returns a GeoJSON Point, or NULL if the coordinates are out of
+range.
+*/
+type STPoint struct {
+	BinaryFunctionBase
+}
+
+func NewSTPoint(first, second Expression) Function {
+	rv := &STPoint{
+		*NewBinaryFunctionBase("st_point", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *STPoint) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *STPoint) Type() value.Type { return value.OBJECT }
+
+func (this *STPoint) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if first.Type() != value.NUMBER || second.Type() != value.NUMBER {
+		return value.NULL_VALUE, nil
+	}
+
+	lon := first.Actual().(float64)
+	lat := second.Actual().(float64)
+	if !(lon >= -180.0 && lon <= 180.0 && lat >= -90.0 && lat <= 90.0) {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{first, second},
+	}), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *STPoint) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSTPoint(operands[0], operands[1])
+	}
+}
@@ -0,0 +1,161 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/query/value"
+)
+
+func geoConstant(s string) Expression {
+	return NewConstant(value.NewValue([]byte(s)))
+}
+
+var (
+	_GEO_SQUARE = geoConstant(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1], [0, 0]]]}`)
+	_GEO_DONUT  = geoConstant(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1], [0, 0]],
+		[[0.25, 0.25], [0.75, 0.25], [0.75, 0.75], [0.25, 0.75], [0.25, 0.25]]]}`)
+	_GEO_CENTER  = geoConstant(`{"type": "Point", "coordinates": [0.5, 0.5]}`)
+	_GEO_FAR     = geoConstant(`{"type": "Point", "coordinates": [2, 2]}`)
+	_GEO_LINE    = geoConstant(`{"type": "LineString", "coordinates": [[0.5, 0.5], [2, 2]]}`)
+	_GEO_EQUATOR = geoConstant(`{"type": "Feature", "properties": {},
+		"geometry": {"type": "LineString", "coordinates": [[-1, 0], [1, 0]]}}`)
+)
+
+func TestGeoPredicates(t *testing.T) {
+	var tests = []struct {
+		expr     Expression
+		expected bool
+	}{
+		{NewSTContains(_GEO_SQUARE, _GEO_CENTER), true},
+		{NewSTContains(_GEO_SQUARE, _GEO_FAR), false},
+		{NewSTContains(_GEO_DONUT, _GEO_CENTER), false},
+		{NewSTContains(_GEO_SQUARE, _GEO_LINE), false},
+		{NewSTContains(_GEO_SQUARE, _GEO_DONUT), true},
+		{NewSTIntersects(_GEO_SQUARE, _GEO_LINE), true},
+		{NewSTIntersects(_GEO_DONUT, _GEO_LINE), true},
+		{NewSTIntersects(_GEO_DONUT, _GEO_CENTER), false},
+		{NewSTIntersects(_GEO_SQUARE, _GEO_FAR), false},
+		{NewSTDWithin(_GEO_SQUARE, _GEO_FAR, NewConstant(160000)), true},
+		{NewSTDWithin(_GEO_SQUARE, _GEO_FAR, NewConstant(150000)), false},
+		{NewSTIsValid(_GEO_DONUT), true},
+		{NewSTIsValid(geoConstant(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0.5]]]}`)), false},
+		{NewSTIsValid(geoConstant(`{"type": "Point", "coordinates": [200, 0]}`)), false},
+		{NewSTIsValid(NewConstant(`{"type": "Point", "coordinates": [10, 20]}`)), true},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+		} else if rv.Type() != value.BOOLEAN || rv.Truth() != test.expected {
+			t.Errorf("Test %d: expected %v, got %v", i, test.expected, rv)
+		}
+	}
+}
+
+func TestGeoMeasures(t *testing.T) {
+	degree := _EARTH_RADIUS * math.Pi / 180.0
+
+	var tests = []struct {
+		expr     Expression
+		expected float64
+	}{
+		{NewSTDistance(NewSTPoint(NewConstant(0), NewConstant(0)), NewSTPoint(NewConstant(0), NewConstant(1))), degree},
+		{NewSTDistance(NewSTPoint(NewConstant(0), NewConstant(1)), _GEO_EQUATOR), degree},
+		{NewSTDistance(NewSTPoint(NewConstant(3), NewConstant(0)), _GEO_EQUATOR), 2 * degree},
+		{NewSTDistance(_GEO_SQUARE, _GEO_LINE), 0},
+		{NewSTArea(_GEO_SQUARE), degree * degree * math.Sin(math.Pi/180.0) * 180.0 / math.Pi},
+		{NewSTArea(_GEO_CENTER), 0},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+			continue
+		}
+
+		actual, ok := rv.Actual().(float64)
+		if !ok || math.Abs(actual-test.expected) > 1e-6*math.Max(1.0, test.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i, test.expected, rv)
+		}
+	}
+}
+
+func TestGeoValues(t *testing.T) {
+	var tests = []struct {
+		expr     Expression
+		expected string
+	}{
+		{NewSTPoint(NewConstant(-5.6), NewConstant(42.6)), `{"coordinates":[-5.6,42.6],"type":"Point"}`},
+		{NewSTPoint(NewConstant(-5.6), NewConstant(92.6)), `null`},
+		{NewSTPoint(NewConstant(-5.6), NewConstant("42.6")), `null`},
+		{NewSTGeohash(NewSTPoint(NewConstant(-5.6), NewConstant(42.6)), NewConstant(5)), `"ezs42"`},
+		{NewSTGeohash(_GEO_SQUARE), `"s00"`},
+		{NewSTGeohash(_GEO_SQUARE, NewConstant(13)), `null`},
+		{NewSTGeomFromGeoJSON(NewConstant(`{"type": "Point", "coordinates": [10, 20]}`)),
+			`{"coordinates":[10,20],"type":"Point"}`},
+		{NewSTGeomFromGeoJSON(NewConstant(`{"type": "Point"}`)), `null`},
+		{NewSTArea(NewConstant(true)), `null`},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+		} else if rv.String() != test.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, test.expected, rv.String())
+		}
+	}
+
+	rv, _ := NewSTDistance(NewConstant(value.MISSING_VALUE), _GEO_SQUARE).Evaluate(nil, nil)
+	if rv.Type() != value.MISSING {
+		t.Errorf("Expected missing, got %v", rv)
+	}
+}
+
+func TestGeoCover(t *testing.T) {
+	center := value.NewValue([]byte(`{"type": "Point", "coordinates": [2.35, 48.85]}`))
+	cells, ok := GeoCover(center, 5000.0, 12)
+	if !ok || len(cells) == 0 || len(cells) > _GEOHASH_MAX_CELLS {
+		t.Fatalf("Unexpected cover %v", cells)
+	}
+
+	covered := func(hash string) bool {
+		for _, cell := range cells {
+			if strings.HasPrefix(hash, cell) || strings.HasPrefix(cell, hash) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, p := range []geoPoint{{2.35, 48.85}, {2.40, 48.88}, {2.30, 48.82}, {2.35, 48.894}} {
+		if hash := geohashEncode(p, 12); !covered(hash) {
+			t.Errorf("Point %v (%s) not covered by %v", p, hash, cells)
+		}
+	}
+
+	if hash := geohashEncode(geoPoint{3.35, 48.85}, 12); covered(hash) {
+		t.Errorf("Point %s unexpectedly covered by %v", hash, cells)
+	}
+
+	cells, ok = GeoCover(center, 1e7, 12)
+	if !ok || len(cells) == 0 || len(cells) > _GEOHASH_MAX_CELLS {
+		t.Errorf("Unexpected cover %v", cells)
+	}
+
+	if _, ok = GeoCover(value.NewValue(true), 0.0, 12); ok {
+		t.Errorf("Expected invalid region")
+	}
+}
@@ -383,3 +383,390 @@ func ensureBuffer(buffer []interface{}, length int) []interface{} {
 }
 
 var _SET_POOL = value.NewSetPool(64, true, false)
+
+///////////////////////////////////////////////////
+//
+// JSONPathExists
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the json function JSON_PATH_EXISTS(expr, path). It
+returns true if the JSONPath selects at least one value. The path is
+compiled once if it is a constant.
+*/
+type JSONPathExists struct {
+	BinaryFunctionBase
+	path *jsonPath
+}
+
+func NewJSONPathExists(first, second Expression) Function {
+	rv := &JSONPathExists{
+		*NewBinaryFunctionBase("json_path_exists", first, second),
+		nil,
+	}
+
+	rv.path = precompileJSONPath(second.Value())
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JSONPathExists) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JSONPathExists) Type() value.Type { return value.BOOLEAN }
+
+func (this *JSONPathExists) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, path, err := jsonPathEvaluate(this.operands, this.path, item, context)
+	if path == nil {
+		return first, err
+	}
+
+	return value.NewValue(len(path.query(first, first, 1)) > 0), nil
+}
+
+/*
+If this expression is in the WHERE clause of a partial index, lists
+the Expressions that are implicitly covered.
+
+For boolean functions, simply list this expression.
+*/
+func (this *JSONPathExists) FilterCovers(covers map[string]value.Value) map[string]value.Value {
+	covers[this.String()] = value.TRUE_VALUE
+	return covers
+}
+
+func (this *JSONPathExists) FilterExpressionCovers(covers map[Expression]value.Value) map[Expression]value.Value {
+	covers[this] = value.TRUE_VALUE
+	return covers
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JSONPathExists) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJSONPathExists(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// JSONPathQuery
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the json function JSON_PATH_QUERY(expr, path). It
+returns the array of values selected by the JSONPath, e.g.
+'$.items[?(@.price > 10)].sku'. The path is compiled once if it is a
+constant.
+*/
+type JSONPathQuery struct {
+	BinaryFunctionBase
+	path *jsonPath
+}
+
+func NewJSONPathQuery(first, second Expression) Function {
+	rv := &JSONPathQuery{
+		*NewBinaryFunctionBase("json_path_query", first, second),
+		nil,
+	}
+
+	rv.path = precompileJSONPath(second.Value())
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JSONPathQuery) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JSONPathQuery) Type() value.Type { return value.ARRAY }
+
+func (this *JSONPathQuery) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, path, err := jsonPathEvaluate(this.operands, this.path, item, context)
+	if path == nil {
+		return first, err
+	}
+
+	vals := path.query(first, first, 0)
+	rv := make([]interface{}, len(vals))
+	for i, val := range vals {
+		rv[i] = val
+	}
+	return value.NewValue(rv), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JSONPathQuery) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJSONPathQuery(operands[0], operands[1])
+	}
+}
+
+/*
+Evaluate the document and path operands. Returns a nil path, and the
+MISSING or NULL result, if the operands are not a value and a string.
+*/
+func jsonPathEvaluate(operands Expressions, path *jsonPath, item value.Value, context Context) (
+	value.Value, *jsonPath, error) {
+
+	first, err := operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil, nil
+	} else if second.Type() != value.STRING {
+		return value.NULL_VALUE, nil, nil
+	}
+
+	if path == nil {
+		path, err = newJSONPath(second.ToString())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return first, path, nil
+}
+
+///////////////////////////////////////////////////
+//
+// JSONPointerGet
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the json function JSON_POINTER_GET(expr, pointer). It
+returns the value referenced by the JSON Pointer (RFC 6901), e.g.
+'/a/b/0', or MISSING if there is none. An invalid pointer is NULL.
+*/
+type JSONPointerGet struct {
+	BinaryFunctionBase
+}
+
+func NewJSONPointerGet(first, second Expression) Function {
+	rv := &JSONPointerGet{
+		*NewBinaryFunctionBase("json_pointer_get", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JSONPointerGet) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JSONPointerGet) Type() value.Type { return value.JSON }
+
+func (this *JSONPointerGet) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if second.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	tokens, ok := jsonPointerTokens(second.ToString())
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+
+	rv, ok := jsonPointerGet(first, tokens)
+	if !ok {
+		return value.MISSING_VALUE, nil
+	}
+	return rv, nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JSONPointerGet) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJSONPointerGet(operands[0], operands[1])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// JSONPointerSet
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the json function JSON_POINTER_SET(expr, pointer,
+value). It returns a copy of expr with the location referenced by the
+JSON Pointer set to value. Following the JSON Patch add operation,
+the parent of the location must exist, and - appends to an array;
+otherwise the result is NULL.
+*/
+type JSONPointerSet struct {
+	TernaryFunctionBase
+}
+
+func NewJSONPointerSet(first, second, third Expression) Function {
+	rv := &JSONPointerSet{
+		*NewTernaryFunctionBase("json_pointer_set", first, second, third),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JSONPointerSet) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JSONPointerSet) Type() value.Type { return value.JSON }
+
+func (this *JSONPointerSet) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	third, err := this.operands[2].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING || third.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if second.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	tokens, ok := jsonPointerTokens(second.ToString())
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+
+	rv, ok := jsonPointerSet(first, tokens, third)
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+	return rv, nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JSONPointerSet) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJSONPointerSet(operands[0], operands[1], operands[2])
+	}
+}
+
+///////////////////////////////////////////////////
+//
+// JSONSchemaValidate
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the json function JSON_SCHEMA_VALIDATE(expr, schema).
+It returns an object with a boolean field valid, and a field errors
+listing the path, keyword and message of each validation failure. The
+schema follows JSON Schema draft 2020-12, and is compiled once if it
+is a constant; an invalid schema is an error.
+*/
+type JSONSchemaValidate struct {
+	BinaryFunctionBase
+	schema *JSONSchema
+}
+
+func NewJSONSchemaValidate(first, second Expression) Function {
+	rv := &JSONSchemaValidate{
+		*NewBinaryFunctionBase("json_schema_validate", first, second),
+		nil,
+	}
+
+	if schema := second.Value(); schema != nil {
+		rv.schema, _ = NewJSONSchema(schema)
+	}
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JSONSchemaValidate) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JSONSchemaValidate) Type() value.Type { return value.OBJECT }
+
+func (this *JSONSchemaValidate) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if second.Type() != value.OBJECT && second.Type() != value.BOOLEAN {
+		return value.NULL_VALUE, nil
+	}
+
+	schema := this.schema
+	if schema == nil {
+		schema, err = NewJSONSchema(second)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	errs := schema.Validate(first)
+	return value.NewValue(map[string]interface{}{
+		"valid":  len(errs) == 0,
+		"errors": errs,
+	}), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JSONSchemaValidate) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJSONSchemaValidate(operands[0], operands[1])
+	}
+}
@@ -0,0 +1,205 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/value"
+)
+
+var _JSON_DOC = NewConstant(value.NewValue([]byte(`{
+	"store": "north",
+	"items": [
+		{"sku": "a1", "price": 5, "tags": ["x"]},
+		{"sku": "b2", "price": 15, "tags": ["x", "y"]},
+		{"sku": "c3", "price": 25, "discount": true}
+	],
+	"a/b": {"m~n": 7},
+	"limit": 20
+}`)))
+
+func TestJSONPathQuery(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected string
+	}{
+		{`$`, ""},
+		{`$.store`, `["north"]`},
+		{`$.items[?(@.price>10)].sku`, `["b2","c3"]`},
+		{`$.items[?(@.price > 10 && !@.discount)].sku`, `["b2"]`},
+		{`$.items[?(@.price < $.limit)].sku`, `["a1","b2"]`},
+		{`$.items[?(@.discount)].sku`, `["c3"]`},
+		{`$.items[?(@.sku == 'a1' || @.sku == "c3")].price`, `[5,25]`},
+		{`$.items[-1].sku`, `["c3"]`},
+		{`$.items[0,2].sku`, `["a1","c3"]`},
+		{`$.items[1:].sku`, `["b2","c3"]`},
+		{`$.items[::-1].sku`, `["c3","b2","a1"]`},
+		{`$.items[*].tags[*]`, `["x","x","y"]`},
+		{`$..sku`, `["a1","b2","c3"]`},
+		{`$['a/b']['m~n']`, `[7]`},
+		{`$.nothing.here`, `[]`},
+	}
+
+	for _, test := range tests {
+		rv, err := NewJSONPathQuery(_JSON_DOC, NewConstant(test.path)).Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("%s: received error %v", test.path, err)
+			continue
+		}
+
+		expected := test.expected
+		if expected == "" {
+			expected = "[" + _JSON_DOC.Value().String() + "]"
+		}
+		if rv.String() != expected {
+			t.Errorf("%s: expected %s, got %s", test.path, expected, rv.String())
+		}
+	}
+
+	for _, path := range []string{`store`, `$.items[`, `$.items[?(@.price >)]`, `$.items[1`} {
+		_, err := NewJSONPathQuery(_JSON_DOC, NewConstant(path)).Evaluate(nil, nil)
+		if err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
+
+func TestJSONPathExists(t *testing.T) {
+	f := NewJSONPathExists(_JSON_DOC, NewConstant(`$.items[?(@.price > 20)]`))
+	if f.(*JSONPathExists).path == nil {
+		t.Errorf("Expected constant path to be precompiled")
+	}
+
+	rv, _ := f.Evaluate(nil, nil)
+	if !rv.Truth() {
+		t.Errorf("Expected match")
+	}
+
+	rv, _ = NewJSONPathExists(_JSON_DOC, NewConstant(`$.items[?(@.price > 30)]`)).Evaluate(nil, nil)
+	if rv.Type() != value.BOOLEAN || rv.Truth() {
+		t.Errorf("Expected no match, got %v", rv)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	var tests = []struct {
+		expr     Expression
+		expected string
+	}{
+		{NewJSONPointerGet(_JSON_DOC, NewConstant("/items/1/sku")), `"b2"`},
+		{NewJSONPointerGet(_JSON_DOC, NewConstant("/a~1b/m~0n")), `7`},
+		{NewJSONPointerGet(_JSON_DOC, NewConstant("/items/01")), ``},
+		{NewJSONPointerGet(_JSON_DOC, NewConstant("/items/3")), ``},
+		{NewJSONPointerGet(_JSON_DOC, NewConstant("items")), `null`},
+		{NewJSONPointerGet(NewConstant([]interface{}{1, 2}), NewConstant("")), `[1,2]`},
+		{NewJSONPointerSet(NewConstant(map[string]interface{}{"a": map[string]interface{}{"b": 1}}),
+			NewConstant("/a/c"), NewConstant(2)), `{"a":{"b":1,"c":2}}`},
+		{NewJSONPointerSet(NewConstant(map[string]interface{}{"a": []interface{}{1, 2}}),
+			NewConstant("/a/-"), NewConstant(3)), `{"a":[1,2,3]}`},
+		{NewJSONPointerSet(NewConstant(map[string]interface{}{"a": []interface{}{1, 2}}),
+			NewConstant("/a/0"), NewConstant("x")), `{"a":["x",2]}`},
+		{NewJSONPointerSet(NewConstant(map[string]interface{}{"a": 1}),
+			NewConstant("/b/c"), NewConstant(2)), `null`},
+	}
+
+	for i, test := range tests {
+		rv, err := test.expr.Evaluate(nil, nil)
+		if err != nil {
+			t.Errorf("Test %d: received error %v", i, err)
+		} else if test.expected == "" {
+			if rv.Type() != value.MISSING {
+				t.Errorf("Test %d: expected missing, got %v", i, rv)
+			}
+		} else if rv.String() != test.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, test.expected, rv.String())
+		}
+	}
+
+	doc := value.NewValue(map[string]interface{}{"a": []interface{}{1, 2}})
+	NewJSONPointerSet(NewConstant(doc), NewConstant("/a/0"), NewConstant(9)).Evaluate(nil, nil)
+	if doc.String() != `{"a":[1,2]}` {
+		t.Errorf("Expected document to be unchanged, got %s", doc.String())
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema := NewConstant(value.NewValue([]byte(`{
+		"type": "object",
+		"required": ["id", "tags"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 5},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true},
+			"kind": {"enum": ["a", "b"]}
+		},
+		"additionalProperties": false,
+		"if": {"required": ["kind"], "properties": {"kind": {"const": "b"}}},
+		"then": {"required": ["name"]},
+		"$defs": {"tag": {"type": "string", "minLength": 1}}
+	}`)))
+
+	var tests = []struct {
+		doc      string
+		keywords []string
+	}{
+		{`{"id": 1, "tags": ["x"]}`, nil},
+		{`{"id": 1.5, "tags": []}`, []string{"type"}},
+		{`{"id": 0, "name": "Bob", "tags": ["x", "x"]}`, []string{"minimum", "pattern", "uniqueItems"}},
+		{`{"id": 2, "tags": [""], "extra": true}`, []string{"additionalProperties", "minLength"}},
+		{`{"id": 2, "tags": [], "kind": "b"}`, []string{"required"}},
+		{`{"id": 2, "kind": "c"}`, []string{"required", "enum"}},
+		{`[]`, []string{"type"}},
+	}
+
+	f := NewJSONSchemaValidate(NewIdentifier("doc"), schema)
+	if f.(*JSONSchemaValidate).schema == nil {
+		t.Errorf("Expected constant schema to be precompiled")
+	}
+
+	for _, test := range tests {
+		item := value.NewValue(map[string]interface{}{"doc": value.NewValue([]byte(test.doc))})
+		rv, err := f.Evaluate(item, nil)
+		if err != nil {
+			t.Errorf("%s: received error %v", test.doc, err)
+			continue
+		}
+
+		valid, _ := rv.Field("valid")
+		errs, _ := rv.Field("errors")
+		if valid.Truth() != (len(test.keywords) == 0) {
+			t.Errorf("%s: unexpected validity %v", test.doc, rv)
+		}
+
+		keywords := make(map[string]bool)
+		for _, e := range errs.Actual().([]interface{}) {
+			keyword, _ := value.NewValue(e).Field("keyword")
+			keywords[keyword.ToString()] = true
+		}
+		for _, keyword := range test.keywords {
+			if !keywords[keyword] {
+				t.Errorf("%s: expected %s error, got %v", test.doc, keyword, errs)
+			}
+		}
+	}
+
+	// schemas built from values may hold values rather than raw JSON
+	nested := NewConstant(map[string]interface{}{"required": []interface{}{value.NewValue("name")}})
+	rv, _ := NewJSONSchemaValidate(NewConstant(map[string]interface{}{}), nested).Evaluate(nil, nil)
+	if valid, _ := rv.Field("valid"); valid.Truth() {
+		t.Errorf("Expected missing required property, got %v", rv)
+	}
+
+	for _, bad := range []string{`{"type": "string", "pattern": "("}`, `{"$ref": "#/nowhere"}`, `{"items": 3}`} {
+		_, err := NewJSONSchemaValidate(NewConstant(1), NewConstant(value.NewValue([]byte(bad)))).Evaluate(nil, nil)
+		if err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
@@ -584,3 +584,63 @@ func (this *DsVersion) Constructor() FunctionConstructor {
 		return NewDsVersion()
 	}
 }
+
+///////////////////////////////////////////////////
+//
+// MergeAction
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the Meta function MERGE_ACTION(). In the RETURNING
+clause of a MERGE, it returns the action that was applied to the
+document: "INSERT", "UPDATE" or "DELETE".
+*/
+type MergeAction struct {
+	NullaryFunctionBase
+}
+
+func NewMergeAction() Function {
+	rv := &MergeAction{
+		*NewNullaryFunctionBase("merge_action"),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *MergeAction) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *MergeAction) Type() value.Type { return value.STRING }
+
+/*
+Return the action attached to the item by MERGE, or NULL if there
+is none.
+*/
+func (this *MergeAction) Evaluate(item value.Value, context Context) (value.Value, error) {
+	av, ok := item.(value.AnnotatedValue)
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+
+	action, ok := av.GetAttachment("merge_action").(string)
+	if !ok {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(action), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *MergeAction) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewMergeAction()
+	}
+}
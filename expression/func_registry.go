@@ -9,6 +9,7 @@
 package expression
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -25,6 +26,19 @@ func GetFunction(name string) (Function, bool) {
 	return rv, ok
 }
 
+/*
+Return the sorted names of all the functions in the registry,
+in lower case. Used by clients for name completion.
+*/
+func FunctionNames() []string {
+	rv := make([]string, 0, len(_FUNCTIONS))
+	for name, _ := range _FUNCTIONS {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
 /*
 The variable _FUNCTIONS represents a map from string to
 Function. Each string returns a pointer to that function.
@@ -100,7 +114,17 @@ var _FUNCTIONS = map[string]Function{
 	"slice":   &Slice{},
 
 	// Crypto
-	"hashbytes": &Hashbytes{},
+	"decrypt":    &Decrypt{},
+	"encrypt":    &Encrypt{},
+	"hashbytes":  &Hashbytes{},
+	"hex_decode": &HexDecode{},
+	"hex_encode": &HexEncode{},
+	"hmac":       &HMAC{},
+	"sha224":     &SHA224{},
+	"sha256":     &SHA256{},
+	"sha3":       &SHA3{},
+	"sha384":     &SHA384{},
+	"sha512":     &SHA512{},
 
 	// Curl
 	"curl": &Curl{},
@@ -145,32 +169,44 @@ var _FUNCTIONS = map[string]Function{
 	"weekday_str":          &WeekdayStr{},
 
 	// String
-	"contains":  &Contains{},
-	"initcap":   &Title{},
-	"length":    &Length{},
-	"lower":     &Lower{},
-	"lpad":      &LPad{},
-	"ltrim":     &LTrim{},
-	"mask":      &Mask{},
-	"position":  &Position0{},
-	"pos":       &Position0{},
-	"position0": &Position0{},
-	"pos0":      &Position0{},
-	"position1": &Position1{},
-	"pos1":      &Position1{},
-	"repeat":    &Repeat{},
-	"replace":   &Replace{},
-	"reverse":   &Reverse{},
-	"rpad":      &RPad{},
-	"rtrim":     &RTrim{},
-	"split":     &Split{},
-	"substr":    &Substr0{},
-	"substr0":   &Substr0{},
-	"substr1":   &Substr1{},
-	"suffixes":  &Suffixes{},
-	"title":     &Title{},
-	"trim":      &Trim{},
-	"upper":     &Upper{},
+	"collation_key": &CollationKey{},
+	"contains":      &Contains{},
+	"initcap":       &Title{},
+	"length":        &Length{},
+	"lower":         &Lower{},
+	"lpad":          &LPad{},
+	"ltrim":         &LTrim{},
+	"mask":          &Mask{},
+	"normalize":     &Normalize{},
+	"position":      &Position0{},
+	"pos":           &Position0{},
+	"position0":     &Position0{},
+	"pos0":          &Position0{},
+	"position1":     &Position1{},
+	"pos1":          &Position1{},
+	"repeat":        &Repeat{},
+	"replace":       &Replace{},
+	"reverse":       &Reverse{},
+	"rpad":          &RPad{},
+	"rtrim":         &RTrim{},
+	"split":         &Split{},
+	"substr":        &Substr0{},
+	"substr0":       &Substr0{},
+	"substr1":       &Substr1{},
+	"suffixes":      &Suffixes{},
+	"title":         &Title{},
+	"trim":          &Trim{},
+	"unaccent":      &Unaccent{},
+	"upper":         &Upper{},
+
+	// Text similarity
+	"damerau_levenshtein": &DamerauLevenshtein{},
+	"jaro_winkler":        &JaroWinkler{},
+	"levenshtein":         &Levenshtein{},
+	"metaphone":           &Metaphone{},
+	"ngrams":              &NGrams{},
+	"soundex":             &Soundex{},
+	"trigram_similarity":  &TrigramSimilarity{},
 
 	// Regular expressions
 	"contains_regex":   &RegexpContains{},
@@ -308,13 +344,29 @@ var _FUNCTIONS = map[string]Function{
 	"object_values":       &ObjectValues{},
 
 	// JSON
-	"decode_json":  &JSONDecode{},
-	"encode_json":  &JSONEncode{},
-	"encoded_size": &EncodedSize{},
-	"json_decode":  &JSONDecode{},
-	"json_encode":  &JSONEncode{},
-	"pairs":        &Pairs{},
-	"poly_length":  &PolyLength{},
+	"decode_json":          &JSONDecode{},
+	"encode_json":          &JSONEncode{},
+	"encoded_size":         &EncodedSize{},
+	"json_decode":          &JSONDecode{},
+	"json_encode":          &JSONEncode{},
+	"json_path_exists":     &JSONPathExists{},
+	"json_path_query":      &JSONPathQuery{},
+	"json_pointer_get":     &JSONPointerGet{},
+	"json_pointer_set":     &JSONPointerSet{},
+	"json_schema_validate": &JSONSchemaValidate{},
+	"pairs":                &Pairs{},
+	"poly_length":          &PolyLength{},
+
+	// Geospatial
+	"st_area":            &STArea{},
+	"st_contains":        &STContains{},
+	"st_distance":        &STDistance{},
+	"st_dwithin":         &STDWithin{},
+	"st_geohash":         &STGeohash{},
+	"st_geomfromgeojson": &STGeomFromGeoJSON{},
+	"st_intersects":      &STIntersects{},
+	"st_isvalid":         &STIsValid{},
+	"st_point":           &STPoint{},
 
 	// Base64
 	"base64":        &Base64Encode{},
@@ -376,6 +428,7 @@ var _FUNCTIONS = map[string]Function{
 	"version":       &Version{},
 	"current_users": &CurrentUsers{},
 	"ds_version":    &DsVersion{},
+	"merge_action":  &MergeAction{},
 
 	// Distributed
 	"node_name": &NodeName{},
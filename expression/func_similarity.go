@@ -0,0 +1,822 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/couchbase/query/value"
+)
+
+///////////////////////////////////////////////////
+//
+// Levenshtein
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function LEVENSHTEIN(expr1, expr2). It
+returns the number of single character insertions, deletions and
+substitutions needed to turn one string into the other.
+*/
+type Levenshtein struct {
+	BinaryFunctionBase
+}
+
+func NewLevenshtein(first, second Expression) Function {
+	rv := &Levenshtein{
+		*NewBinaryFunctionBase("levenshtein", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Levenshtein) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Levenshtein) Type() value.Type { return value.NUMBER }
+
+func (this *Levenshtein) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, second, rv, err := evaluateStrings(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	return value.NewValue(levenshtein([]rune(first), []rune(second))), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Levenshtein) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewLevenshtein(operands[0], operands[1])
+	}
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+///////////////////////////////////////////////////
+//
+// DamerauLevenshtein
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function DAMERAU_LEVENSHTEIN(expr1, expr2).
+It returns the edit distance between two strings when transpositions
+of two characters also count as a single edit.
+*/
+type DamerauLevenshtein struct {
+	BinaryFunctionBase
+}
+
+func NewDamerauLevenshtein(first, second Expression) Function {
+	rv := &DamerauLevenshtein{
+		*NewBinaryFunctionBase("damerau_levenshtein", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *DamerauLevenshtein) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *DamerauLevenshtein) Type() value.Type { return value.NUMBER }
+
+func (this *DamerauLevenshtein) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, second, rv, err := evaluateStrings(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	return value.NewValue(damerauLevenshtein([]rune(first), []rune(second))), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *DamerauLevenshtein) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewDamerauLevenshtein(operands[0], operands[1])
+	}
+}
+
+/*
+The unrestricted distance of Lowrance and Wagner, in which characters
+may be edited again after being transposed.
+*/
+func damerauLevenshtein(a, b []rune) int {
+	inf := len(a) + len(b)
+	d := make([][]int, len(a)+2)
+	for i := range d {
+		d[i] = make([]int, len(b)+2)
+	}
+
+	d[0][0] = inf
+	for i := 0; i <= len(a); i++ {
+		d[i+1][0] = inf
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j+1] = inf
+		d[1][j+1] = j
+	}
+
+	last := make(map[rune]int)
+	for i := 1; i <= len(a); i++ {
+		match := 0
+		for j := 1; j <= len(b); j++ {
+			i1 := last[b[j-1]]
+			j1 := match
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+				match = j
+			}
+			d[i+1][j+1] = minInt(d[i][j]+cost, d[i+1][j]+1, d[i][j+1]+1,
+				d[i1][j1]+(i-i1-1)+1+(j-j1-1))
+		}
+		last[a[i-1]] = i
+	}
+
+	return d[len(a)+1][len(b)+1]
+}
+
+///////////////////////////////////////////////////
+//
+// JaroWinkler
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function JARO_WINKLER(expr1, expr2). It
+returns the Jaro-Winkler similarity of two strings, between 0 for no
+similarity and 1 for equal strings. Strings sharing a prefix of up to
+four characters score higher.
+*/
+type JaroWinkler struct {
+	BinaryFunctionBase
+}
+
+func NewJaroWinkler(first, second Expression) Function {
+	rv := &JaroWinkler{
+		*NewBinaryFunctionBase("jaro_winkler", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *JaroWinkler) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *JaroWinkler) Type() value.Type { return value.NUMBER }
+
+func (this *JaroWinkler) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, second, rv, err := evaluateStrings(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	return value.NewValue(jaroWinkler([]rune(first), []rune(second))), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *JaroWinkler) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewJaroWinkler(operands[0], operands[1])
+	}
+}
+
+const (
+	_JARO_WINKLER_THRESHOLD = 0.7
+	_JARO_WINKLER_SCALE     = 0.1
+	_JARO_WINKLER_PREFIX    = 4
+)
+
+func jaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1.0
+	} else if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+
+	window := int(math.Max(float64(len(a)), float64(len(b))))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	aMatched := make([]bool, len(a))
+	bMatched := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		lo := i - window
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i + window + 1
+		if hi > len(b) {
+			hi = len(b)
+		}
+		for j := lo; j < hi; j++ {
+			if !bMatched[j] && a[i] == b[j] {
+				aMatched[i] = true
+				bMatched[j] = true
+				matches++
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0.0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3.0
+	if jaro <= _JARO_WINKLER_THRESHOLD {
+		return jaro
+	}
+
+	prefix := 0
+	for prefix < _JARO_WINKLER_PREFIX && prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*_JARO_WINKLER_SCALE*(1.0-jaro)
+}
+
+///////////////////////////////////////////////////
+//
+// Soundex
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function SOUNDEX(expr). It returns the
+four character American Soundex code of the string, which is the same
+for names that sound alike in English. Accents are ignored, and other
+characters than letters are skipped.
+*/
+type Soundex struct {
+	UnaryFunctionBase
+}
+
+func NewSoundex(operand Expression) Function {
+	rv := &Soundex{
+		*NewUnaryFunctionBase("soundex", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Soundex) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Soundex) Type() value.Type { return value.STRING }
+
+func (this *Soundex) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if arg.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(soundex(phoneticLetters(arg.ToString()))), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Soundex) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewSoundex(operands[0])
+	}
+}
+
+/*
+Soundex digits of the letters A to Z. Vowels and Y are 0 and separate
+equal digits; H and W are - and do not.
+*/
+const _SOUNDEX_CODES = "0123012-02245501262301-202"
+
+func soundex(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	rv := []byte{s[0]}
+	last := _SOUNDEX_CODES[s[0]-'A']
+	for i := 1; i < len(s) && len(rv) < 4; i++ {
+		code := _SOUNDEX_CODES[s[i]-'A']
+		switch code {
+		case '-':
+			continue
+		case '0':
+		default:
+			if code != last {
+				rv = append(rv, code)
+			}
+		}
+		last = code
+	}
+
+	for len(rv) < 4 {
+		rv = append(rv, '0')
+	}
+	return string(rv)
+}
+
+/*
+Returns the unaccented letters of s, in upper case, without any other
+characters.
+*/
+func phoneticLetters(s string) string {
+	s = strings.ToUpper(unaccentString(s))
+	rv := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 'A' && s[i] <= 'Z' {
+			rv = append(rv, s[i])
+		}
+	}
+	return string(rv)
+}
+
+///////////////////////////////////////////////////
+//
+// Metaphone
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function METAPHONE(expr). It returns the
+Metaphone key of the string, an approximation of its pronunciation in
+English that is more accurate than Soundex. 0 stands for the th sound
+and X for sh. Accents are ignored, and other characters than letters
+are skipped.
+*/
+type Metaphone struct {
+	UnaryFunctionBase
+}
+
+func NewMetaphone(operand Expression) Function {
+	rv := &Metaphone{
+		*NewUnaryFunctionBase("metaphone", operand),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *Metaphone) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *Metaphone) Type() value.Type { return value.STRING }
+
+func (this *Metaphone) Evaluate(item value.Value, context Context) (value.Value, error) {
+	arg, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	} else if arg.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if arg.Type() != value.STRING {
+		return value.NULL_VALUE, nil
+	}
+
+	return value.NewValue(metaphone(phoneticLetters(arg.ToString()))), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *Metaphone) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewMetaphone(operands[0])
+	}
+}
+
+func isVowel(c byte) bool {
+	return c == 'A' || c == 'E' || c == 'I' || c == 'O' || c == 'U'
+}
+
+/*
+The original Metaphone rules of Lawrence Philips.
+*/
+func metaphone(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(s, "AE"), strings.HasPrefix(s, "GN"), strings.HasPrefix(s, "KN"),
+		strings.HasPrefix(s, "PN"), strings.HasPrefix(s, "WR"):
+		s = s[1:]
+	case s[0] == 'X':
+		s = "S" + s[1:]
+	case strings.HasPrefix(s, "WH"):
+		s = "W" + s[2:]
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(s) {
+			return 0
+		}
+		return s[i]
+	}
+
+	rv := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != 'C' && c == at(i-1) {
+			continue
+		}
+
+		next := at(i + 1)
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				rv = append(rv, c)
+			}
+		case 'B':
+			if !(at(i-1) == 'M' && i == len(s)-1) {
+				rv = append(rv, 'B')
+			}
+		case 'C':
+			switch {
+			case next == 'I' && at(i+2) == 'A':
+				rv = append(rv, 'X')
+			case next == 'H':
+				if at(i-1) == 'S' {
+					rv = append(rv, 'K')
+				} else {
+					rv = append(rv, 'X')
+				}
+			case next == 'I' || next == 'E' || next == 'Y':
+				if at(i-1) != 'S' {
+					rv = append(rv, 'S')
+				}
+			default:
+				rv = append(rv, 'K')
+			}
+		case 'D':
+			if next == 'G' && (at(i+2) == 'E' || at(i+2) == 'I' || at(i+2) == 'Y') {
+				rv = append(rv, 'J')
+			} else {
+				rv = append(rv, 'T')
+			}
+		case 'G':
+			switch {
+			case next == 'H' && i+2 < len(s) && !isVowel(at(i+2)):
+			case next == 'N' && (i+2 == len(s) || s[i+2:] == "ED"):
+			case at(i-1) == 'D' && (next == 'E' || next == 'I' || next == 'Y'):
+			case next == 'I' || next == 'E' || next == 'Y':
+				rv = append(rv, 'J')
+			default:
+				rv = append(rv, 'K')
+			}
+		case 'H':
+			prev := at(i - 1)
+			if strings.IndexByte("CSPTG", prev) < 0 && !(isVowel(prev) && !isVowel(next)) {
+				rv = append(rv, 'H')
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				rv = append(rv, 'K')
+			}
+		case 'P':
+			if next == 'H' {
+				rv = append(rv, 'F')
+			} else {
+				rv = append(rv, 'P')
+			}
+		case 'Q':
+			rv = append(rv, 'K')
+		case 'S':
+			if next == 'H' || (next == 'I' && (at(i+2) == 'O' || at(i+2) == 'A')) {
+				rv = append(rv, 'X')
+			} else {
+				rv = append(rv, 'S')
+			}
+		case 'T':
+			switch {
+			case next == 'I' && (at(i+2) == 'O' || at(i+2) == 'A'):
+				rv = append(rv, 'X')
+			case next == 'H':
+				rv = append(rv, '0')
+			case next == 'C' && at(i+2) == 'H':
+			default:
+				rv = append(rv, 'T')
+			}
+		case 'V':
+			rv = append(rv, 'F')
+		case 'W', 'Y':
+			if isVowel(next) {
+				rv = append(rv, c)
+			}
+		case 'X':
+			rv = append(rv, 'K', 'S')
+		case 'Z':
+			rv = append(rv, 'S')
+		default:
+			rv = append(rv, c)
+		}
+	}
+
+	return string(rv)
+}
+
+///////////////////////////////////////////////////
+//
+// NGrams
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function NGRAMS(expr, n). It returns the
+sorted array of distinct n-grams of the words of a string, in lower
+case. Words are runs of letters and digits, and are padded with n-1
+spaces in front and one space behind, so that 3-grams are the
+trigrams used by TRIGRAM_SIMILARITY. An array index on NGRAMS(expr, 3)
+serves TRIGRAM_SIMILARITY(expr, ...) > k.
+*/
+type NGrams struct {
+	BinaryFunctionBase
+}
+
+func NewNGrams(first, second Expression) Function {
+	rv := &NGrams{
+		*NewBinaryFunctionBase("ngrams", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *NGrams) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *NGrams) Type() value.Type { return value.ARRAY }
+
+func (this *NGrams) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, err := this.operands[0].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+	second, err := this.operands[1].Evaluate(item, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.Type() == value.MISSING || second.Type() == value.MISSING {
+		return value.MISSING_VALUE, nil
+	} else if first.Type() != value.STRING || second.Type() != value.NUMBER {
+		return value.NULL_VALUE, nil
+	}
+
+	n := second.Actual().(float64)
+	if n < 1 || n != math.Trunc(n) {
+		return value.NULL_VALUE, nil
+	}
+
+	grams := ngrams(first.ToString(), int(n))
+	rv := make([]interface{}, 0, len(grams))
+	for gram, _ := range grams {
+		rv = append(rv, gram)
+	}
+	sort.Slice(rv, func(i, j int) bool { return rv[i].(string) < rv[j].(string) })
+	return value.NewValue(rv), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *NGrams) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewNGrams(operands[0], operands[1])
+	}
+}
+
+func ngrams(s string, n int) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	rv := make(map[string]bool)
+	for _, word := range words {
+		runes := []rune(word)
+		if n > 1 {
+			runes = []rune(strings.Repeat(" ", n-1) + word + " ")
+		}
+		for i := 0; i+n <= len(runes); i++ {
+			rv[string(runes[i:i+n])] = true
+		}
+	}
+	return rv
+}
+
+///////////////////////////////////////////////////
+//
+// TrigramSimilarity
+//
+///////////////////////////////////////////////////
+
+/*
+This represents the String function TRIGRAM_SIMILARITY(expr1, expr2).
+It returns the number of trigrams the two strings share, divided by
+the number of distinct trigrams of both, between 0 and 1. The
+trigrams of a string are NGRAMS(expr, 3).
+*/
+type TrigramSimilarity struct {
+	BinaryFunctionBase
+}
+
+func NewTrigramSimilarity(first, second Expression) Function {
+	rv := &TrigramSimilarity{
+		*NewBinaryFunctionBase("trigram_similarity", first, second),
+	}
+
+	rv.expr = rv
+	return rv
+}
+
+/*
+Visitor pattern.
+*/
+func (this *TrigramSimilarity) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitFunction(this)
+}
+
+func (this *TrigramSimilarity) Type() value.Type { return value.NUMBER }
+
+func (this *TrigramSimilarity) Evaluate(item value.Value, context Context) (value.Value, error) {
+	first, second, rv, err := evaluateStrings(this.operands, item, context)
+	if rv != nil || err != nil {
+		return rv, err
+	}
+
+	a := ngrams(first, 3)
+	b := ngrams(second, 3)
+	common := 0
+	for gram, _ := range a {
+		if b[gram] {
+			common++
+		}
+	}
+
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return value.ZERO_NUMBER, nil
+	}
+	return value.NewValue(float64(common) / float64(union)), nil
+}
+
+/*
+Factory method pattern.
+*/
+func (this *TrigramSimilarity) Constructor() FunctionConstructor {
+	return func(operands ...Expression) Function {
+		return NewTrigramSimilarity(operands[0], operands[1])
+	}
+}
+
+/*
+Returns a predicate implied by a positive similarity, for index
+selection: when one operand is static, some trigram of the other
+operand is one of its trigrams.
+
+ANY ngram IN NGRAMS(expr, 3) SATISFIES ngram IN NGRAMS(static, 3) END
+*/
+func (this *TrigramSimilarity) NGramPredicate() Expression {
+	expr, static := this.operands[0], this.operands[1]
+	if static.Static() == nil {
+		expr, static = static, expr
+	}
+	if static.Static() == nil || expr.Static() != nil {
+		return nil
+	}
+
+	three := NewConstant(3)
+	var trigrams Expression = NewNGrams(static, three)
+	if static.Value() != nil {
+		rv, err := trigrams.Evaluate(nil, nil)
+		if err != nil || rv.Type() != value.ARRAY {
+			return nil
+		}
+		trigrams = NewConstant(rv)
+	}
+
+	variable := NewIdentifier("ngram")
+	variable.SetBindingVariable(true)
+	return NewAny(Bindings{NewSimpleBinding(variable.Identifier(), NewNGrams(expr, three))},
+		NewIn(variable, trigrams))
+}
+
+/*
+Evaluates two string operands. If either is missing or not a string,
+returns the missing or null result instead.
+*/
+func evaluateStrings(operands Expressions, item value.Value, context Context) (
+	first, second string, rv value.Value, err error) {
+	a, err := operands[0].Evaluate(item, context)
+	if err != nil {
+		return
+	}
+	b, err := operands[1].Evaluate(item, context)
+	if err != nil {
+		return
+	}
+
+	if a.Type() == value.MISSING || b.Type() == value.MISSING {
+		rv = value.MISSING_VALUE
+	} else if a.Type() != value.STRING || b.Type() != value.STRING {
+		rv = value.NULL_VALUE
+	} else {
+		first, second = a.ToString(), b.ToString()
+	}
+	return
+}
@@ -0,0 +1,141 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"math"
+	"testing"
+
+	"github.com/couchbase/query/value"
+)
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		first   string
+		second  string
+		lev     int
+		damerau int
+		jaro    float64
+	}{
+		{"kitten", "sitting", 3, 3, 0.746032},
+		{"MARTHA", "MARHTA", 2, 1, 0.961111},
+		{"DWAYNE", "DUANE", 2, 2, 0.840000},
+		{"DIXON", "DICKSONX", 4, 4, 0.813333},
+		{"ca", "abc", 3, 2, 0.0},
+		{"", "abc", 3, 3, 0.0},
+		{"", "", 0, 0, 1.0},
+		{"Zoë", "Zoe", 1, 1, 0.822222},
+	}
+
+	for _, test := range tests {
+		first, second := NewConstant(test.first), NewConstant(test.second)
+
+		rv, _ := NewLevenshtein(first, second).Evaluate(nil, nil)
+		if rv.Actual() != float64(test.lev) {
+			t.Errorf("levenshtein(%s, %s): expected %d, got %v", test.first, test.second, test.lev, rv)
+		}
+
+		rv, _ = NewDamerauLevenshtein(first, second).Evaluate(nil, nil)
+		if rv.Actual() != float64(test.damerau) {
+			t.Errorf("damerau_levenshtein(%s, %s): expected %d, got %v", test.first, test.second, test.damerau, rv)
+		}
+
+		rv, _ = NewJaroWinkler(first, second).Evaluate(nil, nil)
+		if math.Abs(rv.Actual().(float64)-test.jaro) > 1e-6 {
+			t.Errorf("jaro_winkler(%s, %s): expected %v, got %v", test.first, test.second, test.jaro, rv)
+		}
+	}
+
+	rv, _ := NewLevenshtein(NewConstant("a"), NewConstant(1)).Evaluate(nil, nil)
+	if rv.Type() != value.NULL {
+		t.Errorf("Expected null, got %v", rv)
+	}
+}
+
+func TestPhonetic(t *testing.T) {
+	var tests = []struct {
+		name      string
+		soundex   string
+		metaphone string
+	}{
+		{"Robert", "R163", "RBRT"},
+		{"Rupert", "R163", "RPRT"},
+		{"Tymczak", "T522", "TMKSK"},
+		{"Pfister", "P236", "PFSTR"},
+		{"Ashcraft", "A261", "AXKRFT"},
+		{"Honeyman", "H555", "HNMN"},
+		{"Smith", "S530", "SM0"},
+		{"Thomas", "T520", "0MS"},
+		{"Knuth", "K530", "N0"},
+		{"Wright", "W623", "RT"},
+		{"Michael", "M240", "MXL"},
+		{"Xavier", "X160", "SFR"},
+		{"Müller", "M460", "MLR"},
+		{"O'Brien", "O165", "OBRN"},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		rv, _ := NewSoundex(NewConstant(test.name)).Evaluate(nil, nil)
+		if rv.Actual() != test.soundex {
+			t.Errorf("soundex(%s): expected %s, got %v", test.name, test.soundex, rv)
+		}
+
+		rv, _ = NewMetaphone(NewConstant(test.name)).Evaluate(nil, nil)
+		if rv.Actual() != test.metaphone {
+			t.Errorf("metaphone(%s): expected %s, got %v", test.name, test.metaphone, rv)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	rv, _ := NewNGrams(NewConstant("Word"), NewConstant(3)).Evaluate(nil, nil)
+	if rv.String() != `["  w"," wo","ord","rd ","wor"]` {
+		t.Errorf("Unexpected trigrams %v", rv)
+	}
+
+	rv, _ = NewNGrams(NewConstant("a-b ab"), NewConstant(1)).Evaluate(nil, nil)
+	if rv.String() != `["a","b"]` {
+		t.Errorf("Unexpected unigrams %v", rv)
+	}
+
+	rv, _ = NewNGrams(NewConstant("word"), NewConstant(0.5)).Evaluate(nil, nil)
+	if rv.Type() != value.NULL {
+		t.Errorf("Expected null, got %v", rv)
+	}
+
+	var tests = []struct {
+		first    string
+		second   string
+		expected float64
+	}{
+		{"word", "two words", 4.0 / 11.0},
+		{"Word", "word!", 1.0},
+		{"abc", "xyz", 0.0},
+		{"", "", 0.0},
+	}
+
+	for _, test := range tests {
+		rv, _ = NewTrigramSimilarity(NewConstant(test.first), NewConstant(test.second)).Evaluate(nil, nil)
+		if math.Abs(rv.Actual().(float64)-test.expected) > 1e-9 {
+			t.Errorf("trigram_similarity(%s, %s): expected %v, got %v", test.first, test.second, test.expected, rv)
+		}
+	}
+
+	sim := NewTrigramSimilarity(NewConstant("ab"), NewIdentifier("name")).(*TrigramSimilarity)
+	pred := sim.NGramPredicate()
+	if pred == nil || pred.String() != "any `ngram` in ngrams(`name`, 3) satisfies (`ngram` in [\"  a\",\" ab\",\"ab \"]) end" {
+		t.Errorf("Unexpected implied predicate %v", pred)
+	}
+
+	sim = NewTrigramSimilarity(NewIdentifier("a"), NewIdentifier("b")).(*TrigramSimilarity)
+	if pred = sim.NGramPredicate(); pred != nil {
+		t.Errorf("Expected no implied predicate, got %v", pred)
+	}
+}
@@ -406,6 +406,8 @@ func (this *ToString) Evaluate(item value.Value, context Context) (value.Value,
 			s = strconv.FormatFloat(actual, 'f', -1, 64)
 		case int64:
 			s = strconv.FormatInt(actual, 10)
+		default:
+			s = arg.String()
 		}
 		return value.NewValue(s), nil
 	case value.BINARY:
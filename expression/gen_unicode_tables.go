@@ -0,0 +1,275 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+// +build ignore
+
+/*
+Generates unicode_tables.go from UnicodeData.txt and
+DerivedNormalizationProps.txt of the Unicode character database.
+
+	go run gen_unicode_tables.go [-ucd url-or-directory] [-output file]
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const _UNICODE_VERSION = "14.0.0"
+
+var ucd = flag.String("ucd", "https://www.unicode.org/Public/"+_UNICODE_VERSION+"/ucd/",
+	"URL or directory of the Unicode character database")
+var output = flag.String("output", "unicode_tables.go", "file to write")
+
+const header = `//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+// Code generated by gen_unicode_tables.go from the Unicode %s character database. DO NOT EDIT.
+
+package expression
+
+// The tables hold one level of canonical decomposition, the full
+// compatibility decomposition of characters that have one, the
+// canonical combining class of non-starters, and the characters whose
+// canonical decomposition is excluded from composition. Hangul
+// syllables are decomposed and composed algorithmically and are not
+// listed.
+`
+
+type decomposition struct {
+	compatibility bool
+	runes         []rune
+}
+
+var decompositions = map[rune]decomposition{}
+var combiningClasses = map[rune]uint8{}
+
+func main() {
+	flag.Parse()
+	readUnicodeData()
+	exclusions := readExclusions()
+
+	canonical := map[rune]string{}
+	compatibility := map[rune]string{}
+	for r, d := range decompositions {
+		if !d.compatibility {
+			canonical[r] = string(d.runes)
+		}
+		if full := decompose(r, true); full != decompose(r, false) {
+			compatibility[r] = full
+		}
+	}
+	classes := map[rune]string{}
+	for r, c := range combiningClasses {
+		classes[r] = strconv.Itoa(int(c))
+	}
+	excluded := map[rune]string{}
+	for _, r := range exclusions {
+		excluded[r] = "true"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, header, _UNICODE_VERSION)
+	writeTable(&buf, "_CANONICAL_DECOMPOSITIONS", "string", canonical, true)
+	writeTable(&buf, "_COMPATIBILITY_DECOMPOSITIONS", "string", compatibility, true)
+	writeTable(&buf, "_COMBINING_CLASSES", "uint8", classes, false)
+	writeTable(&buf, "_COMPOSITION_EXCLUSIONS", "bool", excluded, false)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func open(name string) io.ReadCloser {
+	if !strings.HasPrefix(*ucd, "http://") && !strings.HasPrefix(*ucd, "https://") {
+		f, err := os.Open(filepath.Join(*ucd, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return f
+	}
+	resp, err := http.Get(strings.TrimSuffix(*ucd, "/") + "/" + name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Fetching %s: %s", name, resp.Status)
+	}
+	return resp.Body
+}
+
+// calls line with the fields of each line of a database file,
+// leaving out comments
+func readFile(name string, line func(fields []string)) {
+	f := open(name)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := strings.Split(text, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		line(fields)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseRune(s string) rune {
+	r, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return rune(r)
+}
+
+// ranges of characters listed by their first and last code point, such
+// as Hangul syllables and CJK ideographs, have neither decompositions
+// nor combining classes
+func readUnicodeData() {
+	readFile("UnicodeData.txt", func(fields []string) {
+		if len(fields) < 6 {
+			log.Fatalf("Unexpected UnicodeData.txt line %v", fields)
+		}
+		r := parseRune(fields[0])
+		c, err := strconv.ParseUint(fields[3], 10, 8)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if c != 0 {
+			combiningClasses[r] = uint8(c)
+		}
+		if fields[5] == "" {
+			return
+		}
+		var d decomposition
+		for _, s := range strings.Fields(fields[5]) {
+			if strings.HasPrefix(s, "<") {
+				d.compatibility = true
+			} else {
+				d.runes = append(d.runes, parseRune(s))
+			}
+		}
+		decompositions[r] = d
+	})
+}
+
+func readExclusions() []rune {
+	var rv []rune
+	readFile("DerivedNormalizationProps.txt", func(fields []string) {
+		if len(fields) < 2 || fields[1] != "Full_Composition_Exclusion" {
+			return
+		}
+		first, last := fields[0], fields[0]
+		if i := strings.Index(first, ".."); i >= 0 {
+			first, last = first[:i], first[i+2:]
+		}
+		for r := parseRune(first); r <= parseRune(last); r++ {
+			rv = append(rv, r)
+		}
+	})
+	return rv
+}
+
+// the full canonical, or compatibility, decomposition of r in
+// canonical order
+func decompose(r rune, compatibility bool) string {
+	var rv []rune
+	var add func(r rune)
+	add = func(r rune) {
+		if s := r - 0xAC00; s >= 0 && s < 11172 {
+			rv = append(rv, 0x1100+s/588, 0x1161+(s%588)/28)
+			if t := s % 28; t != 0 {
+				rv = append(rv, 0x11A7+t)
+			}
+			return
+		}
+		d, ok := decompositions[r]
+		if !ok || (d.compatibility && !compatibility) {
+			rv = append(rv, r)
+			return
+		}
+		for _, c := range d.runes {
+			add(c)
+		}
+	}
+	add(r)
+
+	for i := 1; i < len(rv); i++ {
+		c := combiningClasses[rv[i]]
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0 && combiningClasses[rv[j-1]] > c; j-- {
+			rv[j-1], rv[j] = rv[j], rv[j-1]
+		}
+	}
+	return string(rv)
+}
+
+func writeTable(w io.Writer, name, typ string, table map[rune]string, quote bool) {
+	keys := make([]int, 0, len(table))
+	for r := range table {
+		keys = append(keys, int(r))
+	}
+	sort.Ints(keys)
+
+	fmt.Fprintf(w, "\nvar %s = map[rune]%s{\n", name, typ)
+	for _, k := range keys {
+		v := table[rune(k)]
+		if quote {
+			v = escape(v)
+		}
+		fmt.Fprintf(w, "0x%04X: %s,\n", k, v)
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r < 0x10000 {
+			fmt.Fprintf(&b, "\\u%04X", r)
+		} else {
+			fmt.Fprintf(&b, "\\U%08X", r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
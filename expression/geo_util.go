@@ -0,0 +1,652 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"math"
+	"strings"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Mean earth radius in meters, as used for all spherical computations.
+*/
+const _EARTH_RADIUS = 6371008.8
+
+const (
+	_GEOHASH_MAX_PRECISION = 12
+	_GEOHASH_MAX_CELLS     = 32
+)
+
+const _GEOHASH_ALPHABET = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+/*
+A position is a longitude, latitude pair in degrees.
+*/
+type geoPoint [2]float64
+
+/*
+Geometries are flattened into their points, their line strings and
+their polygons; the first ring of a polygon is its outer boundary and
+any further rings are holes. Containment and intersection treat edges
+as straight lines in the longitude, latitude plane, while distances
+and areas are computed on the sphere.
+*/
+type geometry struct {
+	points   []geoPoint
+	lines    [][]geoPoint
+	polygons [][][]geoPoint
+}
+
+/*
+Parse a GeoJSON object (geometry, Feature or FeatureCollection).
+Returns false if the object is not valid GeoJSON.
+*/
+func newGeometry(val value.Value) (*geometry, bool) {
+	rv := &geometry{}
+	if !rv.parse(val, 0) || rv.empty() {
+		return nil, false
+	}
+	return rv, true
+}
+
+func (this *geometry) empty() bool {
+	return len(this.points) == 0 && len(this.lines) == 0 && len(this.polygons) == 0
+}
+
+func (this *geometry) parse(val value.Value, depth int) bool {
+	if val.Type() != value.OBJECT || depth > 32 {
+		return false
+	}
+
+	typ, ok := val.Field("type")
+	if !ok || typ.Type() != value.STRING {
+		return false
+	}
+
+	switch typ.ToString() {
+	case "Feature":
+		geom, ok := val.Field("geometry")
+		return ok && this.parse(geom, depth+1)
+	case "FeatureCollection":
+		return this.parseList(val, "features", depth)
+	case "GeometryCollection":
+		return this.parseList(val, "geometries", depth)
+	}
+
+	coords, ok := val.Field("coordinates")
+	if !ok {
+		return false
+	}
+
+	switch typ.ToString() {
+	case "Point":
+		p, ok := geoPosition(coords)
+		if ok {
+			this.points = append(this.points, p)
+		}
+		return ok
+	case "MultiPoint":
+		points, ok := geoPositions(coords, 1)
+		if ok {
+			this.points = append(this.points, points...)
+		}
+		return ok
+	case "LineString":
+		line, ok := geoPositions(coords, 2)
+		if ok {
+			this.lines = append(this.lines, line)
+		}
+		return ok
+	case "MultiLineString":
+		return geoEach(coords, func(elem value.Value) bool {
+			line, ok := geoPositions(elem, 2)
+			if ok {
+				this.lines = append(this.lines, line)
+			}
+			return ok
+		})
+	case "Polygon":
+		poly, ok := geoPolygon(coords)
+		if ok {
+			this.polygons = append(this.polygons, poly)
+		}
+		return ok
+	case "MultiPolygon":
+		return geoEach(coords, func(elem value.Value) bool {
+			poly, ok := geoPolygon(elem)
+			if ok {
+				this.polygons = append(this.polygons, poly)
+			}
+			return ok
+		})
+	}
+
+	return false
+}
+
+func (this *geometry) parseList(val value.Value, field string, depth int) bool {
+	list, ok := val.Field(field)
+	if !ok {
+		return false
+	}
+
+	return geoEach(list, func(elem value.Value) bool {
+		return this.parse(elem, depth+1)
+	})
+}
+
+func geoEach(val value.Value, f func(value.Value) bool) bool {
+	if val.Type() != value.ARRAY {
+		return false
+	}
+
+	for i := 0; ; i++ {
+		elem, ok := val.Index(i)
+		if !ok {
+			return true
+		}
+		if !f(elem) {
+			return false
+		}
+	}
+}
+
+func geoPosition(val value.Value) (geoPoint, bool) {
+	var p geoPoint
+	n := 0
+	ok := geoEach(val, func(elem value.Value) bool {
+		if elem.Type() != value.NUMBER {
+			return false
+		}
+		if n < 2 {
+			p[n] = elem.Actual().(float64)
+		}
+		n++
+		return true
+	})
+
+	// Altitude is allowed and ignored
+	if !ok || n < 2 || n > 3 ||
+		math.IsNaN(p[0]) || p[0] < -180.0 || p[0] > 180.0 ||
+		math.IsNaN(p[1]) || p[1] < -90.0 || p[1] > 90.0 {
+		return p, false
+	}
+	return p, true
+}
+
+func geoPositions(val value.Value, min int) ([]geoPoint, bool) {
+	var points []geoPoint
+	ok := geoEach(val, func(elem value.Value) bool {
+		p, ok := geoPosition(elem)
+		points = append(points, p)
+		return ok
+	})
+	return points, ok && len(points) >= min
+}
+
+func geoPolygon(val value.Value) ([][]geoPoint, bool) {
+	var rings [][]geoPoint
+	ok := geoEach(val, func(elem value.Value) bool {
+		ring, ok := geoPositions(elem, 4)
+		if !ok || ring[0] != ring[len(ring)-1] {
+			return false
+		}
+		rings = append(rings, ring)
+		return true
+	})
+	return rings, ok && len(rings) > 0
+}
+
+/*
+All the vertices of the geometry.
+*/
+func (this *geometry) vertices() []geoPoint {
+	rv := make([]geoPoint, 0, len(this.points))
+	rv = append(rv, this.points...)
+	for _, line := range this.lines {
+		rv = append(rv, line...)
+	}
+	for _, poly := range this.polygons {
+		for _, ring := range poly {
+			rv = append(rv, ring...)
+		}
+	}
+	return rv
+}
+
+/*
+All the edges of the geometry, including polygon boundaries.
+*/
+func (this *geometry) segments() [][2]geoPoint {
+	var rv [][2]geoPoint
+	add := func(line []geoPoint) {
+		for i := 1; i < len(line); i++ {
+			rv = append(rv, [2]geoPoint{line[i-1], line[i]})
+		}
+	}
+
+	for _, line := range this.lines {
+		add(line)
+	}
+	for _, poly := range this.polygons {
+		for _, ring := range poly {
+			add(ring)
+		}
+	}
+	return rv
+}
+
+/*
+Bounding box as minimum and maximum corners.
+*/
+func (this *geometry) bbox() (min, max geoPoint) {
+	min = geoPoint{180.0, 90.0}
+	max = geoPoint{-180.0, -90.0}
+	for _, p := range this.vertices() {
+		for i := 0; i < 2; i++ {
+			min[i] = math.Min(min[i], p[i])
+			max[i] = math.Max(max[i], p[i])
+		}
+	}
+	return
+}
+
+/*
+Whether the point lies on or inside the geometry.
+*/
+func (this *geometry) covers(p geoPoint) bool {
+	for _, q := range this.points {
+		if p == q {
+			return true
+		}
+	}
+
+	for _, s := range this.segments() {
+		if onSegment(p, s[0], s[1]) {
+			return true
+		}
+	}
+
+	for _, poly := range this.polygons {
+		if inRing(p, poly[0]) {
+			inside := true
+			for _, hole := range poly[1:] {
+				if inRing(p, hole) {
+					inside = false
+					break
+				}
+			}
+			if inside {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (this *geometry) intersects(other *geometry) bool {
+	segs := other.segments()
+	for _, s1 := range this.segments() {
+		for _, s2 := range segs {
+			if segmentsIntersect(s1[0], s1[1], s2[0], s2[1]) {
+				return true
+			}
+		}
+	}
+
+	for _, p := range this.vertices() {
+		if other.covers(p) {
+			return true
+		}
+	}
+
+	for _, p := range other.vertices() {
+		if this.covers(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+The other geometry lies entirely on or inside this one: all its
+vertices and edge midpoints are covered, and none of its edges
+crosses one of ours.
+*/
+func (this *geometry) contains(other *geometry) bool {
+	for _, p := range other.vertices() {
+		if !this.covers(p) {
+			return false
+		}
+	}
+
+	segs := this.segments()
+	for _, s := range other.segments() {
+		mid := geoPoint{(s[0][0] + s[1][0]) / 2.0, (s[0][1] + s[1][1]) / 2.0}
+		if !this.covers(mid) {
+			return false
+		}
+		for _, t := range segs {
+			if segmentsCross(s[0], s[1], t[0], t[1]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+/*
+Minimum spherical distance in meters; zero if the geometries
+intersect.
+*/
+func (this *geometry) distance(other *geometry) float64 {
+	if this.intersects(other) {
+		return 0.0
+	}
+
+	rv := math.Inf(1)
+	measure := func(a, b *geometry) {
+		segs := b.segments()
+		for _, p := range a.vertices() {
+			for _, q := range b.points {
+				rv = math.Min(rv, haversine(p, q))
+			}
+			for _, s := range segs {
+				rv = math.Min(rv, crossTrack(p, s[0], s[1]))
+			}
+		}
+	}
+
+	measure(this, other)
+	measure(other, this)
+	return rv * _EARTH_RADIUS
+}
+
+/*
+Spherical area in square meters, holes subtracted.
+*/
+func (this *geometry) area() float64 {
+	rv := 0.0
+	for _, poly := range this.polygons {
+		rv += ringArea(poly[0])
+		for _, hole := range poly[1:] {
+			rv -= ringArea(hole)
+		}
+	}
+	return math.Max(rv, 0.0)
+}
+
+func ringArea(ring []geoPoint) float64 {
+	sum := 0.0
+	for i := 1; i < len(ring); i++ {
+		p1, p2 := ring[i-1], ring[i]
+		sum += radians(p2[0]-p1[0]) *
+			(2.0 + math.Sin(radians(p1[1])) + math.Sin(radians(p2[1])))
+	}
+	return math.Abs(sum * _EARTH_RADIUS * _EARTH_RADIUS / 2.0)
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180.0
+}
+
+/*
+Central angle between two points.
+*/
+func haversine(p, q geoPoint) float64 {
+	lat1, lat2 := radians(p[1]), radians(q[1])
+	dlat := lat2 - lat1
+	dlon := radians(q[0] - p[0])
+	h := math.Sin(dlat/2.0)*math.Sin(dlat/2.0) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlon/2.0)*math.Sin(dlon/2.0)
+	return 2.0 * math.Asin(math.Sqrt(math.Min(h, 1.0)))
+}
+
+func bearing(p, q geoPoint) float64 {
+	lat1, lat2 := radians(p[1]), radians(q[1])
+	dlon := radians(q[0] - p[0])
+	return math.Atan2(math.Sin(dlon)*math.Cos(lat2),
+		math.Cos(lat1)*math.Sin(lat2)-math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon))
+}
+
+/*
+Central angle between a point and the great circle segment a-b.
+*/
+func crossTrack(p, a, b geoPoint) float64 {
+	dap := haversine(a, p)
+	dab := haversine(a, b)
+	if dab == 0.0 || dap == 0.0 {
+		return dap
+	}
+
+	theta := bearing(a, p) - bearing(a, b)
+	if math.Cos(theta) <= 0.0 {
+		return dap
+	}
+
+	dxt := math.Asin(math.Sin(dap) * math.Sin(theta))
+	dat := math.Acos(math.Max(-1.0, math.Min(1.0, math.Cos(dap)/math.Cos(dxt))))
+	if dat > dab {
+		return haversine(b, p)
+	}
+	return math.Abs(dxt)
+}
+
+func orientation(a, b, c geoPoint) int {
+	v := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	switch {
+	case v > 0.0:
+		return 1
+	case v < 0.0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func onSegment(p, a, b geoPoint) bool {
+	return orientation(a, b, p) == 0 &&
+		p[0] >= math.Min(a[0], b[0]) && p[0] <= math.Max(a[0], b[0]) &&
+		p[1] >= math.Min(a[1], b[1]) && p[1] <= math.Max(a[1], b[1])
+}
+
+func segmentsIntersect(a, b, c, d geoPoint) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+
+	return (o1 == 0 && onSegment(c, a, b)) || (o2 == 0 && onSegment(d, a, b)) ||
+		(o3 == 0 && onSegment(a, c, d)) || (o4 == 0 && onSegment(b, c, d))
+}
+
+/*
+Proper crossing: the segments meet at a single point interior to both.
+*/
+func segmentsCross(a, b, c, d geoPoint) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	return o1*o2 < 0 && o3*o4 < 0
+}
+
+/*
+Ray casting; points on the boundary count as inside.
+*/
+func inRing(p geoPoint, ring []geoPoint) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+/*
+Cell dimensions in degrees at a given geohash precision.
+*/
+func geohashCell(precision int) (width, height float64, lonBits, latBits uint) {
+	bits := uint(5 * precision)
+	lonBits = (bits + 1) / 2
+	latBits = bits / 2
+	width = 360.0 / float64(uint64(1)<<lonBits)
+	height = 180.0 / float64(uint64(1)<<latBits)
+	return
+}
+
+func geohashEncode(p geoPoint, precision int) string {
+	lon := [2]float64{-180.0, 180.0}
+	lat := [2]float64{-90.0, 90.0}
+	buf := make([]byte, precision)
+	even := true
+	for i := 0; i < precision; i++ {
+		c := 0
+		for b := 0; b < 5; b++ {
+			interval, v := &lat, p[1]
+			if even {
+				interval, v = &lon, p[0]
+			}
+			mid := (interval[0] + interval[1]) / 2.0
+			c <<= 1
+			if v >= mid {
+				c |= 1
+				interval[0] = mid
+			} else {
+				interval[1] = mid
+			}
+			even = !even
+		}
+		buf[i] = _GEOHASH_ALPHABET[c]
+	}
+	return string(buf)
+}
+
+/*
+The smallest geohash cell, up to the given precision, that contains
+the whole geometry.
+*/
+func (this *geometry) geohash(precision int) string {
+	min, max := this.bbox()
+	low := geohashEncode(min, precision)
+	high := geohashEncode(max, precision)
+	i := 0
+	for i < len(low) && low[i] == high[i] {
+		i++
+	}
+	return low[:i]
+}
+
+/*
+GeoCover returns the geohash cells that together cover the given
+GeoJSON region, grown by distance meters, using the largest precision
+not above the requested one that keeps the number of cells small. It
+is used by the planner to derive index spans on ST_GEOHASH() keys: any
+geometry within the region has a geohash that either starts with one
+of the returned cells or is a prefix of one of them. Returns false if
+the region is not valid GeoJSON.
+*/
+func GeoCover(region value.Value, distance float64, precision int) ([]string, bool) {
+	geom, ok := newGeometry(region)
+	if !ok || math.IsNaN(distance) || distance < 0.0 {
+		return nil, false
+	}
+
+	min, max := geom.bbox()
+	if distance > 0.0 {
+		dlat := distance / _EARTH_RADIUS * 180.0 / math.Pi
+		min[1] = math.Max(min[1]-dlat, -90.0)
+		max[1] = math.Min(max[1]+dlat, 90.0)
+
+		lat := radians(math.Max(math.Abs(min[1]), math.Abs(max[1])))
+		s := math.Sin(math.Min(distance/_EARTH_RADIUS, math.Pi/2.0)) / math.Cos(lat)
+		dlon := 360.0
+		if s < 1.0 {
+			dlon = math.Asin(s) * 180.0 / math.Pi
+		}
+		if min[0]-dlon < -180.0 || max[0]+dlon > 180.0 {
+			min[0], max[0] = -180.0, 180.0
+		} else {
+			min[0] -= dlon
+			max[0] += dlon
+		}
+	}
+
+	if precision > _GEOHASH_MAX_PRECISION {
+		precision = _GEOHASH_MAX_PRECISION
+	}
+
+	for ; precision > 1; precision-- {
+		if geoCells(min, max, precision) <= _GEOHASH_MAX_CELLS {
+			break
+		}
+	}
+
+	if precision < 1 {
+		return nil, false
+	}
+
+	width, height, lonBits, latBits := geohashCell(precision)
+	lon0, lon1 := geoCellIndex(min[0]+180.0, width, lonBits), geoCellIndex(max[0]+180.0, width, lonBits)
+	lat0, lat1 := geoCellIndex(min[1]+90.0, height, latBits), geoCellIndex(max[1]+90.0, height, latBits)
+
+	cells := make([]string, 0, (lon1-lon0+1)*(lat1-lat0+1))
+	for i := lon0; i <= lon1; i++ {
+		for j := lat0; j <= lat1; j++ {
+			center := geoPoint{-180.0 + (float64(i)+0.5)*width, -90.0 + (float64(j)+0.5)*height}
+			cells = append(cells, geohashEncode(center, precision))
+		}
+	}
+
+	return cells, true
+}
+
+func geoCells(min, max geoPoint, precision int) int {
+	width, height, lonBits, latBits := geohashCell(precision)
+	lons := geoCellIndex(max[0]+180.0, width, lonBits) - geoCellIndex(min[0]+180.0, width, lonBits) + 1
+	lats := geoCellIndex(max[1]+90.0, height, latBits) - geoCellIndex(min[1]+90.0, height, latBits) + 1
+	return lons * lats
+}
+
+func geoCellIndex(offset, size float64, bits uint) int {
+	i := int(math.Floor(offset / size))
+	if n := int(uint64(1) << bits); i >= n {
+		i = n - 1
+	}
+	return i
+}
+
+/*
+Accept either a GeoJSON object or its string encoding.
+*/
+func geoValue(val value.Value) (value.Value, bool) {
+	switch val.Type() {
+	case value.OBJECT:
+		return val, true
+	case value.STRING:
+		s := strings.TrimSpace(val.ToString())
+		if !strings.HasPrefix(s, "{") {
+			return nil, false
+		}
+		rv := value.NewValue([]byte(s))
+		return rv, rv.Type() == value.OBJECT
+	default:
+		return nil, false
+	}
+}
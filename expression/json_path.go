@@ -0,0 +1,775 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+A compiled JSONPath: a sequence of segments applied to the root,
+each selecting children (or, for descendant segments, children of
+the node and all its descendants) of every node selected so far.
+*/
+type jsonPath struct {
+	segments []*jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []*jsonPathSelector
+}
+
+const (
+	_PATH_NAME = iota
+	_PATH_WILDCARD
+	_PATH_INDEX
+	_PATH_SLICE
+	_PATH_FILTER
+)
+
+type jsonPathSelector struct {
+	kind   int
+	name   string
+	index  int
+	slice  [3]*int
+	filter jsonPathFilter
+}
+
+/*
+Filter expressions test a candidate node; root is the document the
+path is applied to.
+*/
+type jsonPathFilter interface {
+	test(root, current value.Value) bool
+}
+
+type jsonPathOr struct {
+	first, second jsonPathFilter
+}
+
+func (this *jsonPathOr) test(root, current value.Value) bool {
+	return this.first.test(root, current) || this.second.test(root, current)
+}
+
+type jsonPathAnd struct {
+	first, second jsonPathFilter
+}
+
+func (this *jsonPathAnd) test(root, current value.Value) bool {
+	return this.first.test(root, current) && this.second.test(root, current)
+}
+
+type jsonPathNot struct {
+	operand jsonPathFilter
+}
+
+func (this *jsonPathNot) test(root, current value.Value) bool {
+	return !this.operand.test(root, current)
+}
+
+/*
+Either a literal or a path relative to the current node (@) or the
+root ($). Paths yielding several nodes compare their first one.
+*/
+type jsonPathOperand struct {
+	literal  value.Value
+	path     *jsonPath
+	relative bool
+}
+
+func (this *jsonPathOperand) eval(root, current value.Value) (value.Value, bool) {
+	if this.path == nil {
+		return this.literal, true
+	}
+
+	start := root
+	if this.relative {
+		start = current
+	}
+
+	rv := this.path.query(start, root, 1)
+	if len(rv) == 0 {
+		return nil, false
+	}
+	return rv[0], true
+}
+
+type jsonPathExists struct {
+	operand *jsonPathOperand
+}
+
+func (this *jsonPathExists) test(root, current value.Value) bool {
+	val, ok := this.operand.eval(root, current)
+	return ok && (this.operand.path != nil || val.Truth())
+}
+
+type jsonPathCompare struct {
+	op            string
+	first, second *jsonPathOperand
+}
+
+func (this *jsonPathCompare) test(root, current value.Value) bool {
+	first, ok1 := this.first.eval(root, current)
+	second, ok2 := this.second.eval(root, current)
+
+	switch this.op {
+	case "==":
+		return jsonPathEquals(first, second, ok1, ok2)
+	case "!=":
+		return !jsonPathEquals(first, second, ok1, ok2)
+	}
+
+	if !ok1 || !ok2 || first.Type() != second.Type() ||
+		(first.Type() != value.NUMBER && first.Type() != value.STRING) {
+		return false
+	}
+
+	c := first.Collate(second)
+	switch this.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func jsonPathEquals(first, second value.Value, ok1, ok2 bool) bool {
+	if !ok1 || !ok2 {
+		return ok1 == ok2
+	}
+	return first.Equals(second).Truth()
+}
+
+/*
+Apply the path to a value, stopping after limit results if limit is
+positive.
+*/
+func (this *jsonPath) query(start, root value.Value, limit int) []value.Value {
+	nodes := []value.Value{start}
+	for _, segment := range this.segments {
+		var next []value.Value
+		for _, node := range nodes {
+			if segment.descendant {
+				next = segment.descend(node, root, next)
+			} else {
+				next = segment.apply(node, root, next)
+			}
+		}
+
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+
+	if limit > 0 && len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+	return nodes
+}
+
+func (this *jsonPathSegment) descend(node, root value.Value, rv []value.Value) []value.Value {
+	rv = this.apply(node, root, rv)
+	for _, child := range jsonChildren(node) {
+		rv = this.descend(child, root, rv)
+	}
+	return rv
+}
+
+func (this *jsonPathSegment) apply(node, root value.Value, rv []value.Value) []value.Value {
+	for _, sel := range this.selectors {
+		switch sel.kind {
+		case _PATH_NAME:
+			if node.Type() == value.OBJECT {
+				if child, ok := node.Field(sel.name); ok {
+					rv = append(rv, child)
+				}
+			}
+		case _PATH_WILDCARD:
+			rv = append(rv, jsonChildren(node)...)
+		case _PATH_INDEX:
+			if node.Type() == value.ARRAY {
+				if child, ok := node.Index(sel.index); ok {
+					rv = append(rv, child)
+				}
+			}
+		case _PATH_SLICE:
+			rv = sel.applySlice(node, rv)
+		case _PATH_FILTER:
+			for _, child := range jsonChildren(node) {
+				if sel.filter.test(root, child) {
+					rv = append(rv, child)
+				}
+			}
+		}
+	}
+	return rv
+}
+
+func (this *jsonPathSelector) applySlice(node value.Value, rv []value.Value) []value.Value {
+	if node.Type() != value.ARRAY {
+		return rv
+	}
+
+	array := node.Actual().([]interface{})
+	n := len(array)
+	step := 1
+	if this.slice[2] != nil {
+		step = *this.slice[2]
+	}
+	if step == 0 {
+		return rv
+	}
+
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		if step > 0 {
+			if i < 0 {
+				i = 0
+			} else if i > n {
+				i = n
+			}
+		} else {
+			if i < -1 {
+				i = -1
+			} else if i >= n {
+				i = n - 1
+			}
+		}
+		return i
+	}
+
+	if step > 0 {
+		for i := bound(this.slice[0], 0); i < bound(this.slice[1], n); i += step {
+			rv = append(rv, value.NewValue(array[i]))
+		}
+	} else {
+		for i := bound(this.slice[0], n-1); i > bound(this.slice[1], -1); i += step {
+			rv = append(rv, value.NewValue(array[i]))
+		}
+	}
+	return rv
+}
+
+/*
+Children of an object in name order, or elements of an array.
+*/
+func jsonChildren(node value.Value) []value.Value {
+	switch node.Type() {
+	case value.OBJECT:
+		names := node.FieldNames(nil)
+		rv := make([]value.Value, 0, len(names))
+		for _, name := range names {
+			child, _ := node.Field(name)
+			rv = append(rv, child)
+		}
+		return rv
+	case value.ARRAY:
+		array := node.Actual().([]interface{})
+		rv := make([]value.Value, len(array))
+		for i, child := range array {
+			rv[i] = value.NewValue(child)
+		}
+		return rv
+	default:
+		return nil
+	}
+}
+
+/*
+Compile a JSONPath expression, e.g. $.items[?(@.price > 10)].sku.
+Supports names (.name, ['name']), wildcards, indexes, unions, slices
+([start:end:step]), descendants (..) and filters with comparisons,
+existence tests, !, && and ||.
+*/
+func newJSONPath(s string) (*jsonPath, error) {
+	p := &jsonPathParser{input: s}
+	p.skipSpace()
+	if !p.consume("$") {
+		return nil, p.error("path must start with $")
+	}
+
+	rv, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.error("unexpected character")
+	}
+	return rv, nil
+}
+
+func precompileJSONPath(path value.Value) *jsonPath {
+	if path == nil || path.Type() != value.STRING {
+		return nil
+	}
+
+	rv, _ := newJSONPath(path.ToString())
+	return rv
+}
+
+type jsonPathParser struct {
+	input string
+	pos   int
+}
+
+func (this *jsonPathParser) error(msg string) error {
+	return fmt.Errorf("Invalid JSON path %s at position %d: %s", strconv.Quote(this.input), this.pos, msg)
+}
+
+func (this *jsonPathParser) skipSpace() {
+	for this.pos < len(this.input) && strings.IndexByte(" \t\r\n", this.input[this.pos]) >= 0 {
+		this.pos++
+	}
+}
+
+func (this *jsonPathParser) peek(s string) bool {
+	return strings.HasPrefix(this.input[this.pos:], s)
+}
+
+func (this *jsonPathParser) consume(s string) bool {
+	if this.peek(s) {
+		this.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (this *jsonPathParser) segments() (*jsonPath, error) {
+	rv := &jsonPath{}
+	for {
+		var segment *jsonPathSegment
+		var err error
+
+		switch {
+		case this.consume(".."):
+			if this.peek("[") {
+				segment, err = this.bracket()
+			} else {
+				segment, err = this.dotted()
+			}
+			if segment != nil {
+				segment.descendant = true
+			}
+		case this.consume("."):
+			segment, err = this.dotted()
+		case this.peek("["):
+			segment, err = this.bracket()
+		default:
+			return rv, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		rv.segments = append(rv.segments, segment)
+	}
+}
+
+func (this *jsonPathParser) dotted() (*jsonPathSegment, error) {
+	if this.consume("*") {
+		return &jsonPathSegment{selectors: []*jsonPathSelector{&jsonPathSelector{kind: _PATH_WILDCARD}}}, nil
+	}
+
+	start := this.pos
+	for this.pos < len(this.input) {
+		r, size := utf8.DecodeRuneInString(this.input[this.pos:])
+		if r < utf8.RuneSelf && strings.IndexRune(".[]()=!<>&|,'\"@$*? \t\r\n", r) >= 0 {
+			break
+		}
+		this.pos += size
+	}
+
+	if this.pos == start {
+		return nil, this.error("expected name")
+	}
+
+	name := this.input[start:this.pos]
+	return &jsonPathSegment{selectors: []*jsonPathSelector{&jsonPathSelector{kind: _PATH_NAME, name: name}}}, nil
+}
+
+func (this *jsonPathParser) bracket() (*jsonPathSegment, error) {
+	this.consume("[")
+	rv := &jsonPathSegment{}
+	for {
+		this.skipSpace()
+		sel, err := this.selector()
+		if err != nil {
+			return nil, err
+		}
+		rv.selectors = append(rv.selectors, sel)
+
+		this.skipSpace()
+		if this.consume("]") {
+			return rv, nil
+		} else if !this.consume(",") {
+			return nil, this.error("expected , or ]")
+		}
+	}
+}
+
+func (this *jsonPathParser) selector() (*jsonPathSelector, error) {
+	switch {
+	case this.consume("*"):
+		return &jsonPathSelector{kind: _PATH_WILDCARD}, nil
+	case this.peek("'") || this.peek("\""):
+		name, err := this.str()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathSelector{kind: _PATH_NAME, name: name}, nil
+	case this.consume("?"):
+		this.skipSpace()
+		filter, err := this.or()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathSelector{kind: _PATH_FILTER, filter: filter}, nil
+	}
+
+	var slice [3]*int
+	n := 0
+	for {
+		this.skipSpace()
+		if i, ok := this.integer(); ok {
+			slice[n] = &i
+		}
+
+		this.skipSpace()
+		if n == 2 || !this.consume(":") {
+			break
+		}
+		n++
+	}
+
+	if n == 0 {
+		if slice[0] == nil {
+			return nil, this.error("invalid selector")
+		}
+		return &jsonPathSelector{kind: _PATH_INDEX, index: *slice[0]}, nil
+	}
+	return &jsonPathSelector{kind: _PATH_SLICE, slice: slice}, nil
+}
+
+func (this *jsonPathParser) integer() (int, bool) {
+	start := this.pos
+	if this.pos < len(this.input) && this.input[this.pos] == '-' {
+		this.pos++
+	}
+	for this.pos < len(this.input) && this.input[this.pos] >= '0' && this.input[this.pos] <= '9' {
+		this.pos++
+	}
+
+	i, err := strconv.Atoi(this.input[start:this.pos])
+	if err != nil {
+		this.pos = start
+		return 0, false
+	}
+	return i, true
+}
+
+func (this *jsonPathParser) str() (string, error) {
+	quote := this.input[this.pos]
+	this.pos++
+
+	var buf strings.Builder
+	for this.pos < len(this.input) {
+		c := this.input[this.pos]
+		this.pos++
+		switch {
+		case c == quote:
+			return buf.String(), nil
+		case c != '\\':
+			buf.WriteByte(c)
+		case this.pos >= len(this.input):
+			return "", this.error("unterminated string")
+		default:
+			e := this.input[this.pos]
+			this.pos++
+			switch e {
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'u':
+				if this.pos+4 > len(this.input) {
+					return "", this.error("invalid escape")
+				}
+				r, err := strconv.ParseUint(this.input[this.pos:this.pos+4], 16, 32)
+				if err != nil {
+					return "", this.error("invalid escape")
+				}
+				buf.WriteRune(rune(r))
+				this.pos += 4
+			default:
+				buf.WriteByte(e)
+			}
+		}
+	}
+	return "", this.error("unterminated string")
+}
+
+func (this *jsonPathParser) or() (jsonPathFilter, error) {
+	rv, err := this.and()
+	for err == nil {
+		this.skipSpace()
+		if !this.consume("||") {
+			break
+		}
+
+		var second jsonPathFilter
+		second, err = this.and()
+		rv = &jsonPathOr{rv, second}
+	}
+	return rv, err
+}
+
+func (this *jsonPathParser) and() (jsonPathFilter, error) {
+	rv, err := this.unary()
+	for err == nil {
+		this.skipSpace()
+		if !this.consume("&&") {
+			break
+		}
+
+		var second jsonPathFilter
+		second, err = this.unary()
+		rv = &jsonPathAnd{rv, second}
+	}
+	return rv, err
+}
+
+func (this *jsonPathParser) unary() (jsonPathFilter, error) {
+	this.skipSpace()
+	if this.peek("!") && !this.peek("!=") {
+		this.pos++
+		operand, err := this.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathNot{operand}, nil
+	}
+
+	if this.consume("(") {
+		rv, err := this.or()
+		if err != nil {
+			return nil, err
+		}
+		this.skipSpace()
+		if !this.consume(")") {
+			return nil, this.error("expected )")
+		}
+		return rv, nil
+	}
+
+	first, err := this.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	this.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if this.consume(op) {
+			this.skipSpace()
+			second, err := this.operand()
+			if err != nil {
+				return nil, err
+			}
+			return &jsonPathCompare{op, first, second}, nil
+		}
+	}
+
+	return &jsonPathExists{first}, nil
+}
+
+func (this *jsonPathParser) operand() (*jsonPathOperand, error) {
+	switch {
+	case this.peek("@") || this.peek("$"):
+		relative := this.input[this.pos] == '@'
+		this.pos++
+		path, err := this.segments()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathOperand{path: path, relative: relative}, nil
+	case this.peek("'") || this.peek("\""):
+		s, err := this.str()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathOperand{literal: value.NewValue(s)}, nil
+	case this.consume("true"):
+		return &jsonPathOperand{literal: value.TRUE_VALUE}, nil
+	case this.consume("false"):
+		return &jsonPathOperand{literal: value.FALSE_VALUE}, nil
+	case this.consume("null"):
+		return &jsonPathOperand{literal: value.NULL_VALUE}, nil
+	}
+
+	start := this.pos
+	for this.pos < len(this.input) && strings.IndexByte("+-.0123456789eE", this.input[this.pos]) >= 0 {
+		this.pos++
+	}
+
+	f, err := strconv.ParseFloat(this.input[start:this.pos], 64)
+	if err != nil {
+		this.pos = start
+		return nil, this.error("invalid operand")
+	}
+	return &jsonPathOperand{literal: value.NewValue(f)}, nil
+}
+
+/*
+Split a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+*/
+func jsonPointerTokens(s string) ([]string, bool) {
+	if s == "" {
+		return nil, true
+	} else if s[0] != '/' {
+		return nil, false
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, true
+}
+
+/*
+Array index tokens are decimal digits without leading zeros.
+*/
+func jsonPointerIndex(token string, length int) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+
+	i, err := strconv.Atoi(token)
+	return i, err == nil && i < length
+}
+
+func jsonPointerGet(val value.Value, tokens []string) (value.Value, bool) {
+	for _, token := range tokens {
+		switch val.Type() {
+		case value.OBJECT:
+			child, ok := val.Field(token)
+			if !ok {
+				return nil, false
+			}
+			val = child
+		case value.ARRAY:
+			array := val.Actual().([]interface{})
+			i, ok := jsonPointerIndex(token, len(array))
+			if !ok {
+				return nil, false
+			}
+			val = value.NewValue(array[i])
+		default:
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+/*
+Return a copy of val with the referenced location set to item,
+leaving val unchanged. As with the JSON Patch add operation, the
+parent of the location must exist, and the token - or the array
+length appends to an array.
+*/
+func jsonPointerSet(val value.Value, tokens []string, item value.Value) (value.Value, bool) {
+	if len(tokens) == 0 {
+		return item, true
+	}
+
+	token := tokens[0]
+	switch val.Type() {
+	case value.OBJECT:
+		child, ok := val.Field(token)
+		if !ok {
+			if len(tokens) > 1 {
+				return nil, false
+			}
+			child = value.NULL_VALUE
+		}
+
+		child, ok = jsonPointerSet(child, tokens[1:], item)
+		if !ok {
+			return nil, false
+		}
+
+		fields := val.Fields()
+		object := make(map[string]interface{}, len(fields)+1)
+		for name, field := range fields {
+			object[name] = field
+		}
+		object[token] = child
+		return value.NewValue(object), true
+	case value.ARRAY:
+		array := val.Actual().([]interface{})
+		i, ok := jsonPointerIndex(token, len(array))
+		if !ok {
+			if len(tokens) > 1 || (token != "-" && token != strconv.Itoa(len(array))) {
+				return nil, false
+			}
+			i = len(array)
+		}
+
+		var child value.Value = value.NULL_VALUE
+		if i < len(array) {
+			child = value.NewValue(array[i])
+		}
+
+		child, ok = jsonPointerSet(child, tokens[1:], item)
+		if !ok {
+			return nil, false
+		}
+
+		rv := make([]interface{}, len(array), len(array)+1)
+		copy(rv, array)
+		if i < len(array) {
+			rv[i] = child
+		} else {
+			rv = append(rv, child)
+		}
+		return value.NewValue(rv), true
+	default:
+		return nil, false
+	}
+}
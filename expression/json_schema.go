@@ -0,0 +1,568 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/couchbase/query/value"
+)
+
+/*
+Maximum nesting of $ref resolution, to stop recursive schemas from
+looping on recursive references.
+*/
+const _SCHEMA_MAX_DEPTH = 64
+
+/*
+JSONSchema is a compiled JSON Schema, supporting the validation
+keywords of draft 2020-12 other than dynamic and unevaluated ones:
+type, enum, const, the numeric, string, array and object keywords,
+allOf, anyOf, oneOf, not, if/then/else, and $ref to locations within
+the schema. Format and annotation keywords are ignored.
+*/
+type JSONSchema struct {
+	root    value.Value
+	regexps map[string]*regexp.Regexp
+}
+
+/*
+Compile a schema, which must be an object or a boolean. The compiled
+schema is read-only and may be shared.
+*/
+func NewJSONSchema(schema value.Value) (*JSONSchema, error) {
+	rv := &JSONSchema{
+		root:    schema,
+		regexps: make(map[string]*regexp.Regexp),
+	}
+
+	err := rv.compile(schema, "")
+	if err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+var _SCHEMA_MAPS = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+var _SCHEMA_LISTS = []string{"prefixItems", "allOf", "anyOf", "oneOf"}
+var _SCHEMA_SINGLES = []string{"additionalProperties", "items", "contains", "propertyNames", "not", "if", "then", "else"}
+
+func (this *JSONSchema) compile(schema value.Value, path string) error {
+	switch schema.Type() {
+	case value.BOOLEAN:
+		return nil
+	case value.OBJECT:
+	default:
+		return fmt.Errorf("Invalid JSON schema at %s: a schema must be an object or a boolean", jsonSchemaPath(path))
+	}
+
+	if pattern, ok := schema.Field("pattern"); ok {
+		if err := this.compilePattern(pattern, path+"/pattern"); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := schema.Field("$ref"); ok {
+		if _, ok := this.resolve(ref); !ok {
+			return fmt.Errorf("Invalid JSON schema at %s: unresolvable $ref %v", jsonSchemaPath(path), ref)
+		}
+	}
+
+	for _, keyword := range _SCHEMA_MAPS {
+		sub, ok := schema.Field(keyword)
+		if !ok {
+			continue
+		} else if sub.Type() != value.OBJECT {
+			return fmt.Errorf("Invalid JSON schema at %s: %s must be an object", jsonSchemaPath(path), keyword)
+		}
+
+		for name, child := range sub.Fields() {
+			if keyword == "patternProperties" {
+				if err := this.compilePattern(value.NewValue(name), path+"/"+keyword); err != nil {
+					return err
+				}
+			}
+			if err := this.compile(value.NewValue(child), path+"/"+keyword+"/"+jsonPointerEscape(name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range _SCHEMA_LISTS {
+		sub, ok := schema.Field(keyword)
+		if !ok {
+			continue
+		} else if sub.Type() != value.ARRAY {
+			return fmt.Errorf("Invalid JSON schema at %s: %s must be an array", jsonSchemaPath(path), keyword)
+		}
+
+		for i, child := range sub.Actual().([]interface{}) {
+			if err := this.compile(value.NewValue(child), fmt.Sprintf("%s/%s/%d", path, keyword, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range _SCHEMA_SINGLES {
+		if sub, ok := schema.Field(keyword); ok {
+			if err := this.compile(sub, path+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (this *JSONSchema) compilePattern(pattern value.Value, path string) error {
+	if pattern.Type() != value.STRING {
+		return fmt.Errorf("Invalid JSON schema at %s: pattern must be a string", jsonSchemaPath(path))
+	}
+
+	s := pattern.ToString()
+	if _, ok := this.regexps[s]; ok {
+		return nil
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("Invalid JSON schema at %s: %v", jsonSchemaPath(path), err)
+	}
+	this.regexps[s] = re
+	return nil
+}
+
+/*
+Resolve a reference to a location within the schema, given as a URI
+fragment holding a JSON Pointer.
+*/
+func (this *JSONSchema) resolve(ref value.Value) (value.Value, bool) {
+	if ref.Type() != value.STRING || !strings.HasPrefix(ref.ToString(), "#") {
+		return nil, false
+	}
+
+	tokens, ok := jsonPointerTokens(ref.ToString()[1:])
+	if !ok {
+		return nil, false
+	}
+	return jsonPointerGet(this.root, tokens)
+}
+
+/*
+Validate a document, returning the list of errors found; each is an
+object with the JSON Pointer path of the failing value, the keyword
+and a message. The list is empty if the document is valid.
+*/
+func (this *JSONSchema) Validate(doc value.Value) []interface{} {
+	v := &jsonSchemaValidation{schema: this}
+	v.validate(doc, this.root, "", 0)
+	if v.errors == nil {
+		return []interface{}{}
+	}
+	return v.errors
+}
+
+type jsonSchemaValidation struct {
+	schema *JSONSchema
+	errors []interface{}
+	first  bool
+}
+
+func (this *jsonSchemaValidation) fail(path, keyword, format string, args ...interface{}) {
+	this.errors = append(this.errors, map[string]interface{}{
+		"path":    path,
+		"keyword": keyword,
+		"message": fmt.Sprintf(format, args...),
+	})
+}
+
+func (this *jsonSchemaValidation) done() bool {
+	return this.first && len(this.errors) > 0
+}
+
+/*
+Whether a value is valid against a subschema, without reporting.
+*/
+func (this *jsonSchemaValidation) valid(doc, schema value.Value, path string, depth int) bool {
+	v := &jsonSchemaValidation{schema: this.schema, first: true}
+	v.validate(doc, schema, path, depth)
+	return len(v.errors) == 0
+}
+
+func (this *jsonSchemaValidation) validate(doc, schema value.Value, path string, depth int) {
+	if schema.Type() == value.BOOLEAN {
+		if !schema.Truth() {
+			this.fail(path, "false", "No value is allowed")
+		}
+		return
+	}
+
+	if ref, ok := schema.Field("$ref"); ok {
+		target, _ := this.schema.resolve(ref)
+		if depth >= _SCHEMA_MAX_DEPTH {
+			this.fail(path, "$ref", "Maximum reference depth exceeded")
+			return
+		}
+		this.validate(doc, target, path, depth+1)
+		if this.done() {
+			return
+		}
+	}
+
+	if typ, ok := schema.Field("type"); ok && !jsonSchemaType(doc, typ) {
+		this.fail(path, "type", "Expected %v, got %s", typ, jsonSchemaTypeName(doc))
+		return
+	}
+
+	if enum, ok := schema.Field("enum"); ok && enum.Type() == value.ARRAY {
+		found := false
+		for _, e := range enum.Actual().([]interface{}) {
+			if doc.Equals(value.NewValue(e)).Truth() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			this.fail(path, "enum", "Value is not one of %v", enum)
+		}
+	}
+
+	if c, ok := schema.Field("const"); ok && !doc.Equals(c).Truth() {
+		this.fail(path, "const", "Value is not %v", c)
+	}
+
+	switch doc.Type() {
+	case value.NUMBER:
+		this.validateNumber(doc, schema, path)
+	case value.STRING:
+		this.validateString(doc, schema, path)
+	case value.ARRAY:
+		this.validateArray(doc, schema, path, depth)
+	case value.OBJECT:
+		this.validateObject(doc, schema, path, depth)
+	}
+
+	if this.done() {
+		return
+	}
+
+	this.validateCombinators(doc, schema, path, depth)
+}
+
+func (this *jsonSchemaValidation) validateNumber(doc, schema value.Value, path string) {
+	f := doc.Actual().(float64)
+
+	if min, ok := jsonSchemaNumber(schema, "minimum"); ok && f < min {
+		this.fail(path, "minimum", "%v is less than %v", doc, min)
+	}
+	if max, ok := jsonSchemaNumber(schema, "maximum"); ok && f > max {
+		this.fail(path, "maximum", "%v is greater than %v", doc, max)
+	}
+	if min, ok := jsonSchemaNumber(schema, "exclusiveMinimum"); ok && f <= min {
+		this.fail(path, "exclusiveMinimum", "%v is not greater than %v", doc, min)
+	}
+	if max, ok := jsonSchemaNumber(schema, "exclusiveMaximum"); ok && f >= max {
+		this.fail(path, "exclusiveMaximum", "%v is not less than %v", doc, max)
+	}
+	if m, ok := jsonSchemaNumber(schema, "multipleOf"); ok && m > 0.0 {
+		q := f / m
+		if math.Abs(q-math.Round(q)) > 1e-9*math.Max(1.0, math.Abs(q)) {
+			this.fail(path, "multipleOf", "%v is not a multiple of %v", doc, m)
+		}
+	}
+}
+
+func (this *jsonSchemaValidation) validateString(doc, schema value.Value, path string) {
+	s := doc.ToString()
+	n := float64(utf8.RuneCountInString(s))
+
+	if min, ok := jsonSchemaNumber(schema, "minLength"); ok && n < min {
+		this.fail(path, "minLength", "String is shorter than %v", min)
+	}
+	if max, ok := jsonSchemaNumber(schema, "maxLength"); ok && n > max {
+		this.fail(path, "maxLength", "String is longer than %v", max)
+	}
+	if pattern, ok := schema.Field("pattern"); ok && !this.schema.regexps[pattern.ToString()].MatchString(s) {
+		this.fail(path, "pattern", "String does not match %s", pattern.ToString())
+	}
+}
+
+func (this *jsonSchemaValidation) validateArray(doc, schema value.Value, path string, depth int) {
+	array := doc.Actual().([]interface{})
+	n := float64(len(array))
+
+	if min, ok := jsonSchemaNumber(schema, "minItems"); ok && n < min {
+		this.fail(path, "minItems", "Array has fewer than %v items", min)
+	}
+	if max, ok := jsonSchemaNumber(schema, "maxItems"); ok && n > max {
+		this.fail(path, "maxItems", "Array has more than %v items", max)
+	}
+
+	if unique, ok := schema.Field("uniqueItems"); ok && unique.Truth() {
+	outer:
+		for i := 1; i < len(array); i++ {
+			for j := 0; j < i; j++ {
+				if value.NewValue(array[i]).Equals(value.NewValue(array[j])).Truth() {
+					this.fail(path, "uniqueItems", "Items %d and %d are equal", j, i)
+					break outer
+				}
+			}
+		}
+	}
+
+	// items as an array is the pre 2020-12 form of prefixItems
+	prefix := 0
+	items, hasItems := schema.Field("items")
+	prefixItems, ok := schema.Field("prefixItems")
+	if !ok && hasItems && items.Type() == value.ARRAY {
+		prefixItems, ok = items, true
+		items, hasItems = schema.Field("additionalItems")
+	}
+	if ok {
+		for i, sub := range prefixItems.Actual().([]interface{}) {
+			if i >= len(array) || this.done() {
+				break
+			}
+			this.validate(value.NewValue(array[i]), value.NewValue(sub), fmt.Sprintf("%s/%d", path, i), depth)
+			prefix++
+		}
+	}
+	if hasItems {
+		for i := prefix; i < len(array) && !this.done(); i++ {
+			this.validate(value.NewValue(array[i]), items, fmt.Sprintf("%s/%d", path, i), depth)
+		}
+	}
+
+	if contains, ok := schema.Field("contains"); ok {
+		count := 0
+		for i, elem := range array {
+			if this.valid(value.NewValue(elem), contains, fmt.Sprintf("%s/%d", path, i), depth) {
+				count++
+			}
+		}
+
+		min, ok := jsonSchemaNumber(schema, "minContains")
+		if !ok {
+			min = 1.0
+		}
+		if float64(count) < min {
+			this.fail(path, "contains", "Array contains fewer than %v matching items", min)
+		}
+		if max, ok := jsonSchemaNumber(schema, "maxContains"); ok && float64(count) > max {
+			this.fail(path, "maxContains", "Array contains more than %v matching items", max)
+		}
+	}
+}
+
+func (this *jsonSchemaValidation) validateObject(doc, schema value.Value, path string, depth int) {
+	names := doc.FieldNames(nil)
+	n := float64(len(names))
+
+	if min, ok := jsonSchemaNumber(schema, "minProperties"); ok && n < min {
+		this.fail(path, "minProperties", "Object has fewer than %v properties", min)
+	}
+	if max, ok := jsonSchemaNumber(schema, "maxProperties"); ok && n > max {
+		this.fail(path, "maxProperties", "Object has more than %v properties", max)
+	}
+
+	if required, ok := schema.Field("required"); ok && required.Type() == value.ARRAY {
+		for _, r := range required.Actual().([]interface{}) {
+			if name := value.NewValue(r); name.Type() == value.STRING {
+				if _, ok := doc.Field(name.ToString()); !ok {
+					this.fail(path, "required", "Missing required property %s", name.ToString())
+				}
+			}
+		}
+	}
+
+	if deps, ok := schema.Field("dependentRequired"); ok && deps.Type() == value.OBJECT {
+		for name, list := range deps.Fields() {
+			if _, ok := doc.Field(name); !ok {
+				continue
+			}
+			if list := value.NewValue(list); list.Type() == value.ARRAY {
+				for _, r := range list.Actual().([]interface{}) {
+					if dep := value.NewValue(r); dep.Type() == value.STRING {
+						if _, ok := doc.Field(dep.ToString()); !ok {
+							this.fail(path, "dependentRequired", "Property %s requires property %s",
+								name, dep.ToString())
+						}
+					}
+				}
+			}
+		}
+	}
+
+	properties, _ := schema.Field("properties")
+	patterns, _ := schema.Field("patternProperties")
+	additional, hasAdditional := schema.Field("additionalProperties")
+	propertyNames, hasPropertyNames := schema.Field("propertyNames")
+	dependents, _ := schema.Field("dependentSchemas")
+
+	for _, name := range names {
+		if this.done() {
+			return
+		}
+
+		child, _ := doc.Field(name)
+		childPath := path + "/" + jsonPointerEscape(name)
+
+		if hasPropertyNames && !this.valid(value.NewValue(name), propertyNames, childPath, depth) {
+			this.fail(childPath, "propertyNames", "Invalid property name %s", name)
+		}
+
+		matched := false
+		if properties != nil {
+			if sub, ok := properties.Field(name); ok {
+				matched = true
+				this.validate(child, sub, childPath, depth)
+			}
+		}
+
+		if patterns != nil {
+			for pattern, sub := range patterns.Fields() {
+				if this.schema.regexps[pattern].MatchString(name) {
+					matched = true
+					this.validate(child, value.NewValue(sub), childPath, depth)
+				}
+			}
+		}
+
+		if !matched && hasAdditional {
+			if additional.Type() == value.BOOLEAN && !additional.Truth() {
+				this.fail(childPath, "additionalProperties", "Property %s is not allowed", name)
+			} else {
+				this.validate(child, additional, childPath, depth)
+			}
+		}
+
+		if dependents != nil {
+			if sub, ok := dependents.Field(name); ok {
+				this.validate(doc, sub, path, depth)
+			}
+		}
+	}
+}
+
+func (this *jsonSchemaValidation) validateCombinators(doc, schema value.Value, path string, depth int) {
+	if all, ok := schema.Field("allOf"); ok {
+		for _, sub := range all.Actual().([]interface{}) {
+			if this.done() {
+				return
+			}
+			this.validate(doc, value.NewValue(sub), path, depth)
+		}
+	}
+
+	if anyOf, ok := schema.Field("anyOf"); ok {
+		found := false
+		for _, sub := range anyOf.Actual().([]interface{}) {
+			if this.valid(doc, value.NewValue(sub), path, depth) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			this.fail(path, "anyOf", "Value does not match any of the schemas")
+		}
+	}
+
+	if oneOf, ok := schema.Field("oneOf"); ok {
+		count := 0
+		for _, sub := range oneOf.Actual().([]interface{}) {
+			if this.valid(doc, value.NewValue(sub), path, depth) {
+				count++
+			}
+		}
+		if count != 1 {
+			this.fail(path, "oneOf", "Value matches %d of the schemas instead of exactly one", count)
+		}
+	}
+
+	if not, ok := schema.Field("not"); ok && this.valid(doc, not, path, depth) {
+		this.fail(path, "not", "Value must not match the schema")
+	}
+
+	if cond, ok := schema.Field("if"); ok {
+		branch := "else"
+		if this.valid(doc, cond, path, depth) {
+			branch = "then"
+		}
+		if sub, ok := schema.Field(branch); ok {
+			this.validate(doc, sub, path, depth)
+		}
+	}
+}
+
+func jsonSchemaNumber(schema value.Value, keyword string) (float64, bool) {
+	val, ok := schema.Field(keyword)
+	if !ok || val.Type() != value.NUMBER {
+		return 0.0, false
+	}
+	return val.Actual().(float64), true
+}
+
+func jsonSchemaType(doc, typ value.Value) bool {
+	switch typ.Type() {
+	case value.STRING:
+		name := typ.ToString()
+		if name == "integer" {
+			if doc.Type() != value.NUMBER {
+				return false
+			}
+			f := doc.Actual().(float64)
+			return f == math.Trunc(f)
+		}
+		return name == jsonSchemaTypeName(doc) ||
+			(name == "number" && doc.Type() == value.NUMBER)
+	case value.ARRAY:
+		for _, t := range typ.Actual().([]interface{}) {
+			if jsonSchemaType(doc, value.NewValue(t)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func jsonSchemaTypeName(doc value.Value) string {
+	switch doc.Type() {
+	case value.NULL:
+		return "null"
+	case value.BOOLEAN:
+		return "boolean"
+	case value.NUMBER:
+		return "number"
+	case value.STRING:
+		return "string"
+	case value.ARRAY:
+		return "array"
+	case value.OBJECT:
+		return "object"
+	default:
+		return doc.Type().String()
+	}
+}
+
+func jsonSchemaPath(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return path
+}
+
+func jsonPointerEscape(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
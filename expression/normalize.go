@@ -0,0 +1,233 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package expression
+
+import (
+	"strings"
+	"unicode"
+)
+
+//go:generate go run gen_unicode_tables.go
+
+/*
+Unicode normalization forms, as defined by UAX #15.
+*/
+type normalForm int
+
+const (
+	_NFC normalForm = iota
+	_NFD
+	_NFKC
+	_NFKD
+)
+
+/*
+Returns the normalization form with the given name, ignoring case.
+*/
+func newNormalForm(name string) (normalForm, bool) {
+	switch strings.ToUpper(name) {
+	case "NFC":
+		return _NFC, true
+	case "NFD":
+		return _NFD, true
+	case "NFKC":
+		return _NFKC, true
+	case "NFKD":
+		return _NFKD, true
+	}
+	return _NFC, false
+}
+
+/*
+Hangul syllables are composed of a leading consonant, a vowel and an
+optional trailing consonant, and are decomposed arithmetically.
+*/
+const (
+	_HANGUL_SBASE  = 0xAC00
+	_HANGUL_LBASE  = 0x1100
+	_HANGUL_VBASE  = 0x1161
+	_HANGUL_TBASE  = 0x11A7
+	_HANGUL_LCOUNT = 19
+	_HANGUL_VCOUNT = 21
+	_HANGUL_TCOUNT = 28
+	_HANGUL_NCOUNT = _HANGUL_VCOUNT * _HANGUL_TCOUNT
+	_HANGUL_SCOUNT = _HANGUL_LCOUNT * _HANGUL_NCOUNT
+)
+
+var _COMPOSITIONS map[[2]rune]rune
+
+func init() {
+	_COMPOSITIONS = make(map[[2]rune]rune, len(_CANONICAL_DECOMPOSITIONS))
+	for r, d := range _CANONICAL_DECOMPOSITIONS {
+		pair := []rune(d)
+		if len(pair) == 2 && !_COMPOSITION_EXCLUSIONS[r] {
+			_COMPOSITIONS[[2]rune{pair[0], pair[1]}] = r
+		}
+	}
+}
+
+/*
+Returns s in the given normalization form.
+*/
+func normalizeString(s string, form normalForm) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	runes := decompose(s, form == _NFKC || form == _NFKD)
+	if form == _NFC || form == _NFKC {
+		runes = compose(runes)
+	}
+	return string(runes)
+}
+
+/*
+Fully decomposes s and puts combining marks in canonical order.
+*/
+func decompose(s string, compat bool) []rune {
+	rv := make([]rune, 0, len(s))
+	for _, r := range s {
+		rv = decomposeRune(rv, r, compat)
+	}
+
+	// Canonical ordering: a stable sort of each run of non-starters.
+	for i := 1; i < len(rv); i++ {
+		c := _COMBINING_CLASSES[rv[i]]
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			p := _COMBINING_CLASSES[rv[j-1]]
+			if p == 0 || p <= c {
+				break
+			}
+			rv[j-1], rv[j] = rv[j], rv[j-1]
+		}
+	}
+	return rv
+}
+
+func decomposeRune(rv []rune, r rune, compat bool) []rune {
+	if r < 0x80 {
+		return append(rv, r)
+	}
+
+	if s := r - _HANGUL_SBASE; s >= 0 && s < _HANGUL_SCOUNT {
+		rv = append(rv, _HANGUL_LBASE+s/_HANGUL_NCOUNT, _HANGUL_VBASE+(s%_HANGUL_NCOUNT)/_HANGUL_TCOUNT)
+		if t := s % _HANGUL_TCOUNT; t != 0 {
+			rv = append(rv, _HANGUL_TBASE+t)
+		}
+		return rv
+	}
+
+	if compat {
+		if d, ok := _COMPATIBILITY_DECOMPOSITIONS[r]; ok {
+			for _, c := range d {
+				rv = decomposeRune(rv, c, compat)
+			}
+			return rv
+		}
+	}
+
+	if d, ok := _CANONICAL_DECOMPOSITIONS[r]; ok {
+		for _, c := range d {
+			rv = decomposeRune(rv, c, compat)
+		}
+		return rv
+	}
+
+	return append(rv, r)
+}
+
+/*
+Canonical composition of a decomposed, canonically ordered sequence.
+A character combines with the last starter unless a character of the
+same or higher combining class sits between them.
+*/
+func compose(runes []rune) []rune {
+	if len(runes) == 0 {
+		return runes
+	}
+
+	rv := runes[:1]
+	starter := 0
+	if _COMBINING_CLASSES[runes[0]] != 0 {
+		starter = -1
+	}
+	last := uint8(0)
+
+	for _, r := range runes[1:] {
+		c := _COMBINING_CLASSES[r]
+		if starter >= 0 && (last < c || (last == 0 && len(rv)-1 == starter)) {
+			if composed, ok := composePair(rv[starter], r); ok {
+				rv[starter] = composed
+				continue
+			}
+		}
+
+		if c == 0 {
+			starter = len(rv)
+		}
+		last = c
+		rv = append(rv, r)
+	}
+	return rv
+}
+
+func composePair(first, second rune) (rune, bool) {
+	if l := first - _HANGUL_LBASE; l >= 0 && l < _HANGUL_LCOUNT {
+		if v := second - _HANGUL_VBASE; v >= 0 && v < _HANGUL_VCOUNT {
+			return _HANGUL_SBASE + (l*_HANGUL_VCOUNT+v)*_HANGUL_TCOUNT, true
+		}
+	}
+
+	if s := first - _HANGUL_SBASE; s >= 0 && s < _HANGUL_SCOUNT && s%_HANGUL_TCOUNT == 0 {
+		if t := second - _HANGUL_TBASE; t > 0 && t < _HANGUL_TCOUNT {
+			return first + t, true
+		}
+	}
+
+	r, ok := _COMPOSITIONS[[2]rune{first, second}]
+	return r, ok
+}
+
+/*
+Letters that have no decomposition but are conventionally written
+without their stroke or ligature in unaccented text.
+*/
+var _UNACCENT_LETTERS = map[rune]string{
+	'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe", 'ß': "ss", 'ẞ': "SS",
+	'Ø': "O", 'ø': "o", 'Đ': "D", 'đ': "d", 'Ð': "D", 'ð': "d",
+	'Ł': "L", 'ł': "l", 'Ħ': "H", 'ħ': "h", 'ı': "i", 'Þ': "TH", 'þ': "th",
+}
+
+/*
+Returns s with accents and other combining marks removed.
+*/
+func unaccentString(s string) string {
+	runes := decompose(s, false)
+	rv := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		} else if u, ok := _UNACCENT_LETTERS[r]; ok {
+			rv = append(rv, []rune(u)...)
+		} else {
+			rv = append(rv, r)
+		}
+	}
+	return string(compose(rv))
+}
@@ -11,6 +11,7 @@ package functionsBridge
 // this package solely exists to avoid circular references between parse/n1ql, functions, expression, and functions/javascript
 
 import (
+	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/functions"
@@ -31,3 +32,7 @@ var NewGolangBody func(library, object string) (functions.FunctionBody, errors.E
 var NewJavascriptBody func(library, object string) (functions.FunctionBody, errors.Error) = func(library, object string) (functions.FunctionBody, errors.Error) {
 	return nil, nil
 }
+
+var NewProceduralBody func(block *algebra.ProcBlock, text, queryContext string) (functions.FunctionBody, errors.Error) = func(block *algebra.ProcBlock, text, queryContext string) (functions.FunctionBody, errors.Error) {
+	return nil, nil
+}
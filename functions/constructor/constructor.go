@@ -20,6 +20,7 @@ import (
 	"github.com/couchbase/query/functions/inline"
 	"github.com/couchbase/query/functions/javascript"
 	storage "github.com/couchbase/query/functions/metakv"
+	"github.com/couchbase/query/functions/procedural"
 	"github.com/gorilla/mux"
 )
 
@@ -28,11 +29,13 @@ func Init(mux *mux.Router, threads int) {
 	functionsBridge.NewInlineBody = inline.NewInlineBody
 	functionsBridge.NewGolangBody = golang.NewGolangBody
 	functionsBridge.NewJavascriptBody = javascript.NewJavascriptBody
+	functionsBridge.NewProceduralBody = procedural.NewProceduralBody
 	authorize.Init()
 	storage.Init()
 	golang.Init()
 	inline.Init()
 	javascript.Init(mux, threads)
+	procedural.Init()
 }
 
 func newGlobalFunction(elem []string, namespace string, queryContext string) (functions.FunctionName, errors.Error) {
@@ -10,6 +10,7 @@ package functions
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	atomic "github.com/couchbase/go-couchbase/platform"
@@ -28,6 +29,7 @@ const (
 	INLINE
 	GOLANG
 	JAVASCRIPT
+	PROCEDURAL
 	_SIZER
 )
 
@@ -84,6 +86,10 @@ type LanguageRunner interface {
 type functionCache struct {
 	cache *util.GenCache
 	tag   atomic.AlignedInt64
+
+	// functions whose body is being loaded
+	sync.Mutex
+	loading map[string]int
 }
 
 var Authorize func(privileges *auth.Privileges, credentials *auth.Credentials) errors.Error
@@ -94,6 +100,7 @@ var functions = &functionCache{}
 // init functions cache
 func init() {
 	functions.cache = util.NewGenCache(_LIMIT)
+	functions.loading = make(map[string]int)
 }
 
 func FunctionsNewLanguage(lang Language, runner LanguageRunner) {
@@ -313,7 +320,9 @@ func preLoad(name FunctionName) *FunctionEntry {
 
 	// nope, try to load it
 	entry := &FunctionEntry{FunctionName: name}
+	functions.setLoading(key, 1)
 	entry.FunctionBody, err = name.Load()
+	functions.setLoading(key, -1)
 
 	// if all good, cache
 	if entry.FunctionBody != nil && err == nil {
@@ -326,6 +335,23 @@ func preLoad(name FunctionName) *FunctionEntry {
 	return nil
 }
 
+func (this *functionCache) setLoading(key string, inc int) {
+	this.Lock()
+	if n := this.loading[key] + inc; n > 0 {
+		this.loading[key] = n
+	} else {
+		delete(this.loading, key)
+	}
+	this.Unlock()
+}
+
+func (this *functionCache) isLoading(key string) bool {
+	this.Lock()
+	rv := this.loading[key] > 0
+	this.Unlock()
+	return rv
+}
+
 func checkDelete(name FunctionName, context Context) (*FunctionEntry, errors.Error) {
 	f := preLoad(name)
 	if f != nil {
@@ -341,6 +367,12 @@ func checkDelete(name FunctionName, context Context) (*FunctionEntry, errors.Err
 }
 
 func PreLoad(name FunctionName) bool {
+
+	// loading the body of a function that calls itself resolves the
+	// function again, and finds it in the making
+	if functions.isLoading(name.Key()) {
+		return true
+	}
 	f := preLoad(name)
 	return (f != nil)
 }
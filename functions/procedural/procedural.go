@@ -0,0 +1,441 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package procedural
+
+import (
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/functions"
+	"github.com/couchbase/query/value"
+)
+
+// procedures calling procedures can't go deeper than this
+const _MAX_NESTING = 32
+
+type procedural struct {
+}
+
+type proceduralBody struct {
+	block        *algebra.ProcBlock
+	text         string
+	queryContext string
+	varNames     []string
+}
+
+func Init() {
+	functions.FunctionsNewLanguage(functions.PROCEDURAL, &procedural{})
+}
+
+func (this *procedural) Execute(name functions.FunctionName, body functions.FunctionBody, modifiers functions.Modifier, values []value.Value, context functions.Context) (value.Value, errors.Error) {
+	funcBody, ok := body.(*proceduralBody)
+
+	if !ok {
+		return nil, errors.NewInternalFunctionError(goerrors.New("Wrong language being executed!"), name.Name())
+	}
+
+	levels := context.IncRecursionCount(1)
+	defer context.IncRecursionCount(-1)
+	if levels > _MAX_NESTING {
+		return nil, executionError(name, levels, fmt.Errorf("%v nested procedure calls", levels))
+	}
+
+	vars := make(map[string]interface{}, len(values))
+	if funcBody.varNames == nil {
+		args := make([]interface{}, len(values))
+		for i, _ := range values {
+			args[i] = values[i]
+		}
+		vars["args"] = args
+	} else {
+		if len(values) != len(funcBody.varNames) {
+			return nil, errors.NewArgumentsMismatchError(name.Name())
+		}
+		for i, _ := range values {
+			vars[funcBody.varNames[i]] = values[i]
+		}
+	}
+
+	exec := &execution{
+		vars:    vars,
+		parent:  value.NewValue(vars),
+		context: context,
+		rv:      value.NULL_VALUE,
+	}
+	if timeout := context.GetTimeout(); timeout > 0 {
+		exec.deadline = context.Now().Add(timeout)
+	}
+
+	_, err := exec.runStatement(funcBody.block)
+	if err != nil {
+		return nil, executionError(name, levels, err)
+	}
+	return exec.rv, nil
+}
+
+/*
+Function calls in expressions return errors as plain text, so each
+level wrapping the error of the level below would double its message:
+only the outermost procedure says which procedure failed.
+*/
+func executionError(name functions.FunctionName, levels int, err error) errors.Error {
+	if levels > 1 {
+		return errors.NewInnerFunctionExecutionError("", name.Name(), err)
+	}
+	if e, ok := err.(errors.Error); ok && e.Code() == errors.E_FUNCTION_EXECUTION {
+		return e
+	}
+	return errors.NewFunctionExecutionError("", name.Name(), err)
+}
+
+func NewProceduralBody(block *algebra.ProcBlock, text, queryContext string) (functions.FunctionBody, errors.Error) {
+	return &proceduralBody{block: block, text: text, queryContext: queryContext}, nil
+}
+
+/*
+Check the procedure variables and formalize its expressions: procedure
+parameters, declared variables, loop variables and error variables all
+share the same scope, and are bound as static values, as for inline
+function parameters.
+*/
+func (this *proceduralBody) SetVarNames(vars []string) errors.Error {
+	this.varNames = vars
+
+	checker := &checker{declared: make(map[string]bool, len(vars)+8)}
+	if vars == nil {
+		checker.declare("args")
+	} else {
+		for _, v := range vars {
+			if checker.declared[v] {
+				return errors.NewProcedureDefinitionError(fmt.Sprintf("duplicate parameter %v", v))
+			}
+			checker.declare(v)
+		}
+	}
+	err := checker.check(algebra.ProcStatements{this.block}, 0)
+	if err != nil {
+		return err
+	}
+
+	c := expression.NewConstant("")
+	bindings := make(expression.Bindings, len(checker.names))
+	for i, n := range checker.names {
+		bindings[i] = expression.NewSimpleBinding(n, c)
+		bindings[i].SetStatic(true)
+	}
+
+	f := expression.NewFormalizer("", nil)
+	f.SetWiths(bindings)
+	f.PushBindings(bindings, true)
+	e := this.block.MapExpressions(f)
+	if e != nil {
+		return errors.NewProcedureDefinitionError(e.Error())
+	}
+	return nil
+}
+
+func (this *proceduralBody) Lang() functions.Language {
+	return functions.PROCEDURAL
+}
+
+func (this *proceduralBody) Body(object map[string]interface{}) {
+	object["#language"] = "procedural"
+	object["text"] = this.text
+	if this.queryContext != "" {
+		object["query_context"] = this.queryContext
+	}
+	if this.varNames != nil {
+		vars := make([]value.Value, len(this.varNames))
+		for v, _ := range this.varNames {
+			vars[v] = value.NewValue(this.varNames[v])
+		}
+		object["parameters"] = vars
+	}
+}
+
+func (this *proceduralBody) Indexable() value.Tristate {
+	return value.FALSE
+}
+
+// statements run in the function query context, like external functions
+func (this *proceduralBody) SwitchContext() value.Tristate {
+	return value.NONE
+}
+
+func (this *proceduralBody) IsExternal() bool {
+	return false
+}
+
+/*
+The statements a procedure executes are authorized when they run, but
+subqueries in its expressions are not, so they are authorized with the
+procedure, as for inline functions.
+*/
+func (this *proceduralBody) Privileges() (*auth.Privileges, errors.Error) {
+	lister := &exprLister{}
+	lister.SetMapper(lister)
+	lister.SetMapFunc(func(expr expression.Expression) (expression.Expression, error) {
+		lister.exprs = append(lister.exprs, expr)
+		return expr, nil
+	})
+	err := this.block.MapExpressions(lister)
+	if err != nil {
+		return nil, errors.NewError(err, "")
+	}
+
+	subqueries, err := expression.ListSubqueries(lister.exprs, false)
+	if err != nil {
+		return nil, errors.NewError(err, "")
+	}
+
+	privileges := auth.NewPrivileges()
+	for _, s := range subqueries {
+		sub := s.(*algebra.Subquery)
+		sp, e := sub.Select().Privileges()
+		if e != nil {
+			return nil, e
+		}
+
+		privileges.AddAll(sp)
+	}
+
+	return privileges, nil
+}
+
+type exprLister struct {
+	expression.MapperBase
+	exprs expression.Expressions
+}
+
+type checker struct {
+	declared map[string]bool
+	names    []string
+}
+
+func (this *checker) declare(name string) {
+	if !this.declared[name] {
+		this.declared[name] = true
+		this.names = append(this.names, name)
+	}
+}
+
+// variables have to be declared before they are set, and only once
+func (this *checker) check(stmts algebra.ProcStatements, loops int) errors.Error {
+	for _, s := range stmts {
+		var err errors.Error
+
+		switch s := s.(type) {
+		case *algebra.ProcBlock:
+			err = this.check(s.Statements(), loops)
+			if err == nil && s.Handler() != nil {
+				if s.ErrorVar() != "" {
+					this.declare(s.ErrorVar())
+				}
+				err = this.check(s.Handler(), loops)
+			}
+		case *algebra.ProcDeclare:
+			if this.declared[s.Variable()] {
+				return errors.NewProcedureDefinitionError(fmt.Sprintf("variable %v is already declared", s.Variable()))
+			}
+			this.declare(s.Variable())
+		case *algebra.ProcSet:
+			if !this.declared[s.Variable()] {
+				return errors.NewProcedureDefinitionError(fmt.Sprintf("variable %v is not declared", s.Variable()))
+			}
+		case *algebra.ProcIf:
+			err = this.check(s.Then(), loops)
+			if err == nil {
+				err = this.check(s.Else(), loops)
+			}
+		case *algebra.ProcWhile:
+			err = this.check(s.Body(), loops+1)
+		case *algebra.ProcFor:
+			this.declare(s.Variable())
+			err = this.check(s.Body(), loops+1)
+		case *algebra.ProcLoopControl:
+			if loops == 0 {
+				if s.Continue() {
+					return errors.NewProcedureDefinitionError("CONTINUE outside of a loop")
+				}
+				return errors.NewProcedureDefinitionError("BREAK outside of a loop")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type flow int
+
+const (
+	_NEXT flow = iota
+	_BREAK
+	_CONTINUE
+	_RETURN
+)
+
+type execution struct {
+	vars     map[string]interface{}
+	parent   value.Value
+	context  functions.Context
+	deadline time.Time
+	rv       value.Value
+}
+
+func (this *execution) run(stmts algebra.ProcStatements) (flow, error) {
+	for _, s := range stmts {
+		f, err := this.runStatement(s)
+		if err != nil || f != _NEXT {
+			return f, err
+		}
+	}
+	return _NEXT, nil
+}
+
+func (this *execution) runStatement(stmt algebra.ProcStatement) (flow, error) {
+	switch stmt := stmt.(type) {
+	case *algebra.ProcBlock:
+		f, err := this.run(stmt.Statements())
+
+		// a timeout ends the procedure, whatever the handler does
+		if err != nil && stmt.Handler() != nil && !this.expired() {
+			if stmt.ErrorVar() != "" {
+				this.vars[stmt.ErrorVar()] = errorValue(err)
+			}
+			return this.run(stmt.Handler())
+		}
+		return f, err
+	case *algebra.ProcDeclare:
+		val := value.NULL_VALUE
+		if stmt.Expression() != nil {
+			var err error
+
+			val, err = stmt.Expression().Evaluate(this.parent, this.context)
+			if err != nil {
+				return _NEXT, err
+			}
+		}
+		this.vars[stmt.Variable()] = val
+	case *algebra.ProcSet:
+		val, err := stmt.Expression().Evaluate(this.parent, this.context)
+		if err != nil {
+			return _NEXT, err
+		}
+		this.vars[stmt.Variable()] = val
+	case *algebra.ProcIf:
+		cond, err := stmt.Condition().Evaluate(this.parent, this.context)
+		if err != nil {
+			return _NEXT, err
+		}
+		if cond.Truth() {
+			return this.run(stmt.Then())
+		}
+		return this.run(stmt.Else())
+	case *algebra.ProcWhile:
+		for {
+			err := this.checkTimeout()
+			if err != nil {
+				return _NEXT, err
+			}
+			cond, err := stmt.Condition().Evaluate(this.parent, this.context)
+			if err != nil {
+				return _NEXT, err
+			}
+			if !cond.Truth() {
+				break
+			}
+			f, err := this.run(stmt.Body())
+			if err != nil || f == _RETURN {
+				return f, err
+			} else if f == _BREAK {
+				break
+			}
+		}
+	case *algebra.ProcFor:
+		val, err := stmt.Expression().Evaluate(this.parent, this.context)
+		if err != nil {
+			return _NEXT, err
+		}
+		switch val.Type() {
+		case value.MISSING, value.NULL:
+			return _NEXT, nil
+		case value.ARRAY:
+		default:
+			return _NEXT, fmt.Errorf("FOR %v loops over %v, not an array", stmt.Variable(), val.Type())
+		}
+		for _, e := range val.Actual().([]interface{}) {
+			err = this.checkTimeout()
+			if err != nil {
+				return _NEXT, err
+			}
+			this.vars[stmt.Variable()] = value.NewValue(e)
+			f, err := this.run(stmt.Body())
+			if err != nil || f == _RETURN {
+				return f, err
+			} else if f == _BREAK {
+				break
+			}
+		}
+	case *algebra.ProcLoopControl:
+		if stmt.Continue() {
+			return _CONTINUE, nil
+		}
+		return _BREAK, nil
+	case *algebra.ProcReturn:
+		if stmt.Expression() != nil {
+			val, err := stmt.Expression().Evaluate(this.parent, this.context)
+			if err != nil {
+				return _NEXT, err
+			}
+			this.rv = val
+		}
+		return _RETURN, nil
+	case *algebra.ProcExecute:
+		return _NEXT, this.execute(stmt)
+	}
+	return _NEXT, nil
+}
+
+// statements see the procedure variables as named parameters
+func (this *execution) execute(stmt *algebra.ProcExecute) error {
+	namedArgs := make(map[string]value.Value, len(this.vars))
+	for n, v := range this.vars {
+		namedArgs[n] = value.NewValue(v)
+	}
+	_, _, err := this.context.EvaluateStatement(stmt.Text(), namedArgs, nil, false, this.context.Readonly())
+	return err
+}
+
+func (this *execution) expired() bool {
+	return !this.deadline.IsZero() && time.Now().After(this.deadline)
+}
+
+func (this *execution) checkTimeout() error {
+	if this.expired() {
+		return goerrors.New("procedure timed out")
+	}
+	return nil
+}
+
+// the error as seen by an exception handler
+func errorValue(err error) value.Value {
+	rv := map[string]interface{}{"msg": err.Error()}
+	if e, ok := err.(errors.Error); ok {
+		rv["code"] = e.Code()
+		rv["msg"] = e.Error()
+	}
+	return value.NewValue(rv)
+}
@@ -13,6 +13,7 @@ import (
 	go_errors "errors"
 	"fmt"
 
+	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/expression/parser"
@@ -20,6 +21,7 @@ import (
 	"github.com/couchbase/query/functions/golang"
 	"github.com/couchbase/query/functions/inline"
 	"github.com/couchbase/query/functions/javascript"
+	"github.com/couchbase/query/parser/n1ql"
 )
 
 func MakePath(bytes []byte) ([]string, errors.Error) {
@@ -145,6 +147,44 @@ func MakeBody(name string, bytes []byte) (functions.FunctionBody, errors.Error)
 		}
 		return body, newErr
 
+	case "procedural":
+
+		var _unmarshalled struct {
+			_            string   `json:"#language"`
+			Parameters   []string `json:"parameters"`
+			Text         string   `json:"text"`
+			QueryContext string   `json:"query_context"`
+		}
+		err := json.Unmarshal(bytes, &_unmarshalled)
+		if err != nil {
+			return nil, errors.NewFunctionEncodingError("decode body", name, err)
+		}
+		if _unmarshalled.Text == "" {
+			return nil, errors.NewFunctionEncodingError("decode body", name, go_errors.New("text is missing"))
+		}
+
+		// the body is stored as text, and parsed back as part of a procedure definition
+		parms := "..."
+		if _unmarshalled.Parameters != nil {
+			parms = ""
+			for i, p := range _unmarshalled.Parameters {
+				if i > 0 {
+					parms += ", "
+				}
+				parms += "`" + p + "`"
+			}
+		}
+		stmt, err := n1ql.ParseStatement2("CREATE PROCEDURE `p`("+parms+") "+_unmarshalled.Text,
+			"default", _unmarshalled.QueryContext)
+		if err != nil {
+			return nil, errors.NewFunctionEncodingError("decode body", name, err)
+		}
+		create, ok := stmt.(*algebra.CreateFunction)
+		if !ok || create.Body() == nil {
+			return nil, errors.NewFunctionEncodingError("decode body", name, go_errors.New("invalid procedure"))
+		}
+		return create.Body(), nil
+
 	default:
 		return nil, errors.NewFunctionEncodingError("decode body", "unknown", fmt.Errorf("unknown language %v", language_type.Language))
 	}
@@ -0,0 +1,123 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package metadata
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/couchbase/cbauth/metakv"
+	"github.com/couchbase/indexing/secondary/common"
+	"github.com/couchbase/query/logging"
+)
+
+/*
+A Mirror keeps a metakv directory in step with an in memory copy on
+each query node, so that query metadata survives restarts and is shared
+by the cluster. Writes go to metakv, and every node, the writer
+included, applies the changes as metakv reports them.
+
+Writers also apply their own changes straight away, so that the next
+statement sees them whatever the metakv latency: applying an entry
+must therefore be idempotent. Concurrent writers converge on the last
+value metakv stored, unless they write conditionally, with SetIf, on
+the revision Get returned.
+*/
+type Mirror struct {
+	path  string
+	apply func(key string, value []byte)
+}
+
+// apply is called with a nil value for deleted entries
+func NewMirror(path string, apply func(key string, value []byte)) *Mirror {
+	return &Mirror{path: path, apply: apply}
+}
+
+const _METAKV_RETRIES = 100
+
+/*
+Load the directory, and follow its changes from then on. Both are
+retried: a load that fails is retried in the background, so that a
+node does not wait on metakv to start.
+*/
+func (this *Mirror) Start() {
+	err := this.load(0, nil)
+	go func() {
+		if err != nil {
+			rh := common.NewRetryHelper(_METAKV_RETRIES, time.Second, 2, this.load)
+			err := rh.Run()
+			if err != nil {
+				logging.Errorf("Unable to load %v: %v", this.path, err)
+			}
+		}
+
+		// the observer also replays the directory, which is harmless
+		rh := common.NewRetryHelper(_METAKV_RETRIES, time.Second, 2, this.observe)
+		err := rh.Run()
+		if err != nil {
+			logging.Errorf("Unable to follow changes to %v: %v", this.path, err)
+		}
+	}()
+}
+
+func (this *Mirror) load(r int, err error) error {
+	if r > 0 {
+		logging.Errorf("Unable to load %v (%v), retrying %v", this.path, err, r)
+	}
+	return metakv.IterateChildrenV2(this.path, this.callback)
+}
+
+func (this *Mirror) observe(r int, err error) error {
+	if r > 0 {
+		logging.Errorf("Unable to follow changes to %v (%v), retrying %v", this.path, err, r)
+	}
+	return metakv.RunObserveChildrenV2(this.path, this.callback, make(chan struct{}))
+}
+
+func (this *Mirror) callback(kve metakv.KVEntry) error {
+	if len(kve.Path) <= len(this.path) {
+		return nil
+	}
+	key, err := url.PathUnescape(kve.Path[len(this.path):])
+	if err != nil {
+		logging.Errorf("Ignoring invalid entry %v: %v", kve.Path, err)
+		return nil
+	}
+	this.apply(key, kve.Value)
+	return nil
+}
+
+// keys can be any string: they are escaped to a single path element
+func (this *Mirror) Set(key string, value []byte) error {
+	return metakv.Set(this.path+url.PathEscape(key), value, nil)
+}
+
+// a nil value if there is no such entry
+func (this *Mirror) Get(key string) ([]byte, interface{}, error) {
+	return metakv.Get(this.path + url.PathEscape(key))
+}
+
+// false if the entry has changed since rev was read
+func (this *Mirror) SetIf(key string, value []byte, rev interface{}) (bool, error) {
+	err := metakv.Set(this.path+url.PathEscape(key), value, rev)
+	if err == metakv.ErrRevMismatch {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (this *Mirror) Delete(key string) error {
+	err := metakv.Delete(this.path+url.PathEscape(key), nil)
+
+	// dodgy, but the not found error is not exported in metakv
+	if err != nil && err.Error() == "Not found" {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package n1ql
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var keywordsOnce sync.Once
+var keywords []string
+
+/*
+Keywords returns the sorted list of reserved words recognised by the
+lexer, in upper case. A grammar token is a keyword if its name on its
+own is lexed back into a single token other than an identifier.
+*/
+func Keywords() []string {
+	keywordsOnce.Do(func() {
+		for _, name := range yyToknames {
+			if !isKeywordName(name) {
+				continue
+			}
+
+			var lval yySymType
+			nex := NewLexer(strings.NewReader(name))
+			tok := nex.Lex(&lval)
+			if tok != IDENT && tok != IDENT_ICASE && tok != 0 && nex.Lex(&lval) == 0 {
+				keywords = append(keywords, name)
+			}
+			nex.Stop()
+		}
+		sort.Strings(keywords)
+	})
+	return keywords
+}
+
+func isKeywordName(name string) bool {
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
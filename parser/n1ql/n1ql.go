@@ -151,7 +151,13 @@ func (this *lexer) Lex(lval *yySymType) int {
 		return rv
 	}
 
-	rv := this.nex.Lex(lval)
+	rv := this.nexLex(lval)
+
+	// WITHIN GROUP introduces the ordering of an ordered-set aggregate,
+	// while WITHIN on its own is an operator
+	if rv == WITHIN {
+		return this.peek(lval, GROUP, WITHIN_GROUP, WITHIN)
+	}
 
 	// we are going to treat identifiers specially to resolve
 	// shift reduce conflicts on namespaces
@@ -168,7 +174,7 @@ func (this *lexer) Lex(lval *yySymType) int {
 	// save the current token value and check the next
 	this.hasSaved = true
 	oldLval := *lval
-	this.saved = this.nex.Lex(lval)
+	this.saved = this.nexLex(lval)
 	this.lval = *lval
 	*lval = oldLval
 
@@ -180,6 +186,149 @@ func (this *lexer) Lex(lval *yySymType) int {
 	return NAMESPACE_ID
 }
 
+/*
+Scan the next token, and note where it starts and ends in the statement,
+for rules that need the statement text of their symbols.
+*/
+func (this *lexer) nexLex(lval *yySymType) int {
+	rv := this.nex.Lex(lval)
+	if rv != 0 {
+		lval.tokOffset = this.nex.curOffset
+		lval.tokStart = this.nex.curOffset - len(this.nex.Text())
+	}
+	return rv
+}
+
+/*
+The statement text with the token between start and end, such as a
+password, masked.
+*/
+func (this *lexer) redact(start, end int) string {
+	return this.text[:start] + "\"*****\"" + this.text[end:]
+}
+
+/*
+Mask the password of every PASSWORD "..." in a statement. Unlike the
+masking done by the grammar, this only scans the statement, so that it
+also applies to statements that do not parse, whose errors and logs must
+not show the password either. Where the password cannot be told apart,
+such as after a PASSWORD keyword that is not followed by a string, or
+in input left unscanned, the rest of the statement is masked.
+*/
+func RedactPasswords(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	var spans [][2]int
+	password := -1 // the end of a PASSWORD keyword awaiting its string
+	scanned := 0
+
+	scanTokens(input, func(tok, start, end int) bool {
+		if password < 0 {
+			if tok == PASSWORD {
+				password = end
+			}
+			scanned = end
+			return true
+		}
+		if tok != STR {
+			return false
+		}
+		spans = append(spans, [2]int{start, end})
+		password = -1
+		scanned = end
+		return true
+	})
+
+	rv := input
+	if password < 0 && strings.Contains(strings.ToUpper(input[scanned:]), "PASSWORD") {
+		password = scanned
+	}
+	if password >= 0 {
+		rv = strings.TrimRight(rv[:password], " \t\n") + " \"*****\""
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		rv = rv[:spans[i][0]] + "\"*****\"" + rv[spans[i][1]:]
+	}
+	return rv, password >= 0 || len(spans) > 0
+}
+
+/*
+The statement with its tokens separated by single spaces, whatever the
+white space and comments between them, and without a trailing
+semicolon. Tokens, string literals included, are kept as written.
+Statements the lexer cannot scan are only trimmed.
+*/
+func NormalizeText(input string) string {
+	var tokens []string
+	last := 0
+	scanned := 0
+	blank := true
+	ok := scanTokens(input, func(tok, start, end int) bool {
+		blank = blank && isBlank(input[scanned:start])
+		tokens = append(tokens, input[start:end])
+		last = tok
+		scanned = end
+		return true
+	})
+	if !ok || !blank || !isBlank(input[scanned:]) {
+		return strings.TrimSpace(input)
+	}
+	if last == SEMI {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// only white space and comments are skipped between tokens
+func isBlank(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "--") || strings.HasPrefix(s, "/*")
+}
+
+// call token for each token and its offsets, until it returns false
+// the lexer gives up on input it cannot scan
+// returns whether every token was scanned
+func scanTokens(input string, token func(tok, start, end int) bool) (scanned bool) {
+	lex := newLexer(NewLexer(strings.NewReader(input)))
+	lex.text = input
+	lex.nex.ResetOffset()
+	lex.nex.ReportError(lex.ScannerError)
+	defer func() {
+		if recover() != nil {
+			scanned = false
+		}
+		lex.nex.Stop()
+	}()
+
+	var lval yySymType
+	for {
+		tok := lex.nexLex(&lval)
+		if tok == 0 {
+			return lex.lastScannerError == ""
+		} else if !token(tok, lval.tokStart, lval.tokOffset) {
+			return false
+		}
+	}
+}
+
+/*
+Peek at the next token. If it is next, consume it and return
+found, otherwise save it for the following call and return notFound.
+*/
+func (this *lexer) peek(lval *yySymType, next, found, notFound int) int {
+	oldLval := *lval
+	tok := this.nexLex(lval)
+	if tok == next {
+		*lval = oldLval
+		return found
+	}
+
+	this.hasSaved = true
+	this.saved = tok
+	this.lval = *lval
+	*lval = oldLval
+	return notFound
+}
+
 func (this *lexer) Remainder(offset int) string {
 	return strings.TrimLeft(this.text[offset:], " \t")
 }
@@ -99,6 +99,10 @@ isolationLevel   datastore.IsolationLevel
 functionName     functions.FunctionName
 functionBody     functions.FunctionBody
 
+procBlock        *algebra.ProcBlock
+procStmt         algebra.ProcStatement
+procStmts        algebra.ProcStatements
+
 identifier       *expression.Identifier
 
 optimHintArr     []algebra.OptimHint
@@ -106,6 +110,7 @@ optimHints       *algebra.OptimHints
 
 // token offset into the statement
 tokOffset    int
+tokStart     int
 }
 
 %token _ERROR_  // used by the scanner to flag errors
@@ -317,6 +322,7 @@ tokOffset    int
 %token WINDOW
 %token WITH
 %token WITHIN
+%token WITHIN_GROUP
 %token WORK
 %token XOR
 
@@ -343,6 +349,7 @@ tokOffset    int
 %left           CONCAT
 %left           PLUS MINUS
 %left           STAR DIV MOD
+%left           COLLATE
 
 /* Unary operators */
 %right          COVER
@@ -375,7 +382,7 @@ tokOffset    int
 %type <binding>          binding with_term
 %type <bindings>         bindings with_list
 
-%type <s>                alias as_alias opt_as_alias variable opt_name opt_window_name
+%type <s>                alias as_alias opt_as_alias variable opt_window_name
 
 %type <expr>             case_expr simple_or_searched_case simple_case searched_case opt_else
 %type <whenTerms>        when_thens
@@ -391,7 +398,12 @@ tokOffset    int
 
 %type <functionName>     func_name long_func_name short_func_name
 %type <ss>               parm_list parameter_terms
-%type <functionBody>     func_body
+%type <functionBody>     func_body proc_body
+%type <procBlock>        proc_block
+%type <procStmt>         proc_stmt
+%type <procStmts>        proc_stmts proc_else opt_proc_handler
+%type <s>                opt_proc_error
+%type <expr>             opt_proc_value
 %type <expr>             opt_replace
 
 %type <expr>             paren_expr
@@ -422,7 +434,7 @@ tokOffset    int
 %type <resultTerm>       project
 %type <resultTerms>      projects
 %type <projection>       projection
-%type <order>            order_by opt_order_by
+%type <order>            order_by opt_order_by opt_within_group
 %type <sortTerm>         sort_term
 %type <sortTerms>        sort_terms
 %type <groupTerm>        group_term
@@ -442,7 +454,13 @@ tokOffset    int
 %type <statement>        savepoint set_transaction_isolation
 %type <statement>        collection_stmt create_collection drop_collection flush_collection
 %type <statement>        role_stmt grant_role revoke_role
+%type <statement>        user_stmt create_user alter_user drop_user create_group drop_group
 %type <statement>        function_stmt create_function drop_function execute_function
+%type <statement>        create_procedure drop_procedure
+%type <statement>        materialized_view_stmt create_materialized_view refresh_materialized_view drop_materialized_view
+%type <s>                opt_refresh_mode
+%type <statement>        trigger_stmt create_trigger drop_trigger
+%type <s>                trigger_event
 
 %type <keyspaceRef>      keyspace_ref simple_keyspace_ref
 %type <pairs>            values values_list next_values
@@ -460,7 +478,7 @@ tokOffset    int
 %type <binding>          update_binding
 %type <bindings>         update_dimension
 %type <dimensions>       update_dimensions
-%type <b>                opt_key opt_force
+%type <b>                opt_key opt_force prepare_force opt_validate
 %type <mergeActions>     merge_actions opt_merge_delete_insert
 %type <mergeUpdate>      merge_update
 %type <mergeDelete>      merge_delete
@@ -564,6 +582,8 @@ update_statistics
 |
 role_stmt
 |
+user_stmt
+|
 function_stmt
 |
 transaction_stmt
@@ -592,14 +612,31 @@ EXPLAIN stmt
 }
 ;
 
+/* the name is spelled out, as statements can start with an IDENT */
 prepare:
-PREPARE
+prepare_force stmt
 {
-    yylex.(*lexer).setOffset($<tokOffset>1)
+    $$ = algebra.NewPrepare("", $1, $2, yylex.(*lexer).getText(), yylex.(*lexer).getOffset())
 }
-opt_force opt_name stmt
+|
+prepare_force IDENT from_or_as stmt
+{
+    $$ = algebra.NewPrepare($2, $1, $4, yylex.(*lexer).getText(), yylex.(*lexer).getOffset())
+}
+|
+prepare_force STR from_or_as stmt
 {
-    $$ = algebra.NewPrepare($4, $3, $5, yylex.(*lexer).getText(), yylex.(*lexer).getOffset())
+    $$ = algebra.NewPrepare($2, $1, $4, yylex.(*lexer).getText(), yylex.(*lexer).getOffset())
+}
+;
+
+prepare_force:
+PREPARE opt_force
+{
+    if !$2 {
+        yylex.(*lexer).setOffset($<tokOffset>1)
+    }
+    $$ = $2
 }
 ;
 
@@ -616,23 +653,6 @@ FORCE
 }
 ;
 
-opt_name:
-/* empty */
-{
-    $$ = ""
-}
-|
-IDENT from_or_as
-{
-    $$ = $1
-}
-|
-STR from_or_as
-{
-    $$ = $1
-}
-;
-
 from_or_as:
 FROM
 {
@@ -732,6 +752,10 @@ index_stmt
 scope_stmt
 |
 collection_stmt
+|
+materialized_view_stmt
+|
+trigger_stmt
 ;
 
 role_stmt:
@@ -740,6 +764,32 @@ grant_role
 revoke_role
 ;
 
+user_stmt:
+create_user
+|
+alter_user
+|
+drop_user
+|
+create_group
+|
+drop_group
+;
+
+materialized_view_stmt:
+create_materialized_view
+|
+refresh_materialized_view
+|
+drop_materialized_view
+;
+
+trigger_stmt:
+create_trigger
+|
+drop_trigger
+;
+
 index_stmt:
 create_index
 |
@@ -770,6 +820,10 @@ create_function
 drop_function
 |
 execute_function
+|
+create_procedure
+|
+drop_procedure
 ;
 
 transaction_stmt:
@@ -896,6 +950,10 @@ from_select:
 opt_with from opt_let opt_where opt_group opt_window_clause SELECT opt_optim_hints projection
 {
     $$ = algebra.NewSubselect($1, $2, $3, $4, $5, $6, $9, $8)
+    if $1 == nil {
+        // the subselect starts at FROM
+        $<tokStart>$ = $<tokStart>2
+    }
 }
 ;
 
@@ -903,6 +961,10 @@ select_from:
 opt_with SELECT opt_optim_hints projection opt_from opt_let opt_where opt_group opt_window_clause
 {
     $$ = algebra.NewSubselect($1, $5, $6, $7, $8, $9, $4, $3)
+    if $1 == nil {
+        // the subselect starts at SELECT
+        $<tokStart>$ = $<tokStart>2
+    }
 }
 ;
 
@@ -994,6 +1056,11 @@ IDENT DIV PROBE
     $$ = []string{$1 + "/PROBE"}
 }
 |
+INT
+{
+    $$ = []string{fmt.Sprintf("%d", $1)}
+}
+|
 hint_args IDENT
 {
     $$ = append($1, $2)
@@ -1421,6 +1488,11 @@ NL
 {
     $$ = algebra.NewUse(nil, nil, algebra.USE_NL)
 }
+|
+MERGE
+{
+    $$ = algebra.NewUse(nil, nil, algebra.USE_MERGE)
+}
 ;
 
 opt_primary:
@@ -1846,14 +1918,18 @@ OFFSET expr
  *************************************************/
 
 insert:
-INSERT INTO keyspace_ref opt_values_header values_list opt_returning
+INSERT INTO keyspace_ref opt_values_header values_list opt_validate opt_returning
 {
-    $$ = algebra.NewInsertValues($3, $5, $6)
+    insert := algebra.NewInsertValues($3, $5, $7)
+    insert.SetValidate($6)
+    $$ = insert
 }
 |
-INSERT INTO keyspace_ref LPAREN key_val_options_expr_header RPAREN fullselect opt_returning
+INSERT INTO keyspace_ref LPAREN key_val_options_expr_header RPAREN fullselect opt_validate opt_returning
 {
-    $$ = algebra.NewInsertSelect($3, $5.Key(), $5.Value(), $5.Options(), $7, $8)
+    insert := algebra.NewInsertSelect($3, $5.Key(), $5.Value(), $5.Options(), $7, $9)
+    insert.SetValidate($8)
+    $$ = insert
 }
 ;
 
@@ -1949,6 +2025,18 @@ LPAREN expr COMMA expr COMMA expr RPAREN
 ;
 
 
+opt_validate:
+/* empty */
+{
+    $$ = false
+}
+|
+VALIDATE
+{
+    $$ = true
+}
+;
+
 opt_returning:
 /* empty */
 {
@@ -2030,14 +2118,18 @@ key_expr_header COMMA options_expr_header
  *************************************************/
 
 upsert:
-UPSERT INTO keyspace_ref opt_values_header values_list opt_returning
+UPSERT INTO keyspace_ref opt_values_header values_list opt_validate opt_returning
 {
-    $$ = algebra.NewUpsertValues($3, $5, $6)
+    upsert := algebra.NewUpsertValues($3, $5, $7)
+    upsert.SetValidate($6)
+    $$ = upsert
 }
 |
-UPSERT INTO keyspace_ref LPAREN key_val_options_expr_header RPAREN fullselect opt_returning
+UPSERT INTO keyspace_ref LPAREN key_val_options_expr_header RPAREN fullselect opt_validate opt_returning
 {
-    $$ = algebra.NewUpsertSelect($3, $5.Key(), $5.Value(), $5.Options(), $7, $8)
+    upsert := algebra.NewUpsertSelect($3, $5.Key(), $5.Value(), $5.Options(), $7, $9)
+    upsert.SetValidate($8)
+    $$ = upsert
 }
 ;
 
@@ -2049,9 +2141,11 @@ UPSERT INTO keyspace_ref LPAREN key_val_options_expr_header RPAREN fullselect op
  *************************************************/
 
 delete:
-DELETE FROM keyspace_ref opt_use_del_upd opt_where opt_limit opt_returning
+DELETE opt_optim_hints FROM keyspace_ref opt_use_del_upd opt_where opt_limit opt_returning
 {
-    $$ = algebra.NewDelete($3, $4.Keys(), $4.Indexes(), $5, $6, $7)
+    del := algebra.NewDelete($4, $5.Keys(), $5.Indexes(), $6, $7, $8)
+    del.SetOptimHints($2)
+    $$ = del
 }
 ;
 
@@ -2063,19 +2157,28 @@ DELETE FROM keyspace_ref opt_use_del_upd opt_where opt_limit opt_returning
  *************************************************/
 
 update:
-UPDATE keyspace_ref opt_use_del_upd set unset opt_where opt_limit opt_returning
+UPDATE opt_optim_hints keyspace_ref opt_use_del_upd set unset opt_where opt_limit opt_validate opt_returning
 {
-    $$ = algebra.NewUpdate($2, $3.Keys(), $3.Indexes(), $4, $5, $6, $7, $8)
+    update := algebra.NewUpdate($3, $4.Keys(), $4.Indexes(), $5, $6, $7, $8, $10)
+    update.SetValidate($9)
+    update.SetOptimHints($2)
+    $$ = update
 }
 |
-UPDATE keyspace_ref opt_use_del_upd set opt_where opt_limit opt_returning
+UPDATE opt_optim_hints keyspace_ref opt_use_del_upd set opt_where opt_limit opt_validate opt_returning
 {
-    $$ = algebra.NewUpdate($2, $3.Keys(), $3.Indexes(), $4, nil, $5, $6, $7)
+    update := algebra.NewUpdate($3, $4.Keys(), $4.Indexes(), $5, nil, $6, $7, $9)
+    update.SetValidate($8)
+    update.SetOptimHints($2)
+    $$ = update
 }
 |
-UPDATE keyspace_ref opt_use_del_upd unset opt_where opt_limit opt_returning
+UPDATE opt_optim_hints keyspace_ref opt_use_del_upd unset opt_where opt_limit opt_validate opt_returning
 {
-    $$ = algebra.NewUpdate($2, $3.Keys(), $3.Indexes(), nil, $4, $5, $6, $7)
+    update := algebra.NewUpdate($3, $4.Keys(), $4.Indexes(), nil, $5, $6, $7, $9)
+    update.SetValidate($8)
+    update.SetOptimHints($2)
+    $$ = update
 }
 ;
 
@@ -2244,21 +2347,27 @@ path opt_update_for
  *************************************************/
 
 merge:
-MERGE INTO simple_keyspace_ref opt_use_merge USING simple_from_term ON opt_key expr merge_actions opt_limit opt_returning
+MERGE opt_optim_hints INTO simple_keyspace_ref opt_use_merge USING simple_from_term ON opt_key expr merge_actions opt_limit opt_validate opt_returning
 {
-     switch other := $6.(type) {
+     var merge *algebra.Merge
+     switch other := $7.(type) {
          case *algebra.SubqueryTerm:
               source := algebra.NewMergeSourceSubquery(other)
-              $$ = algebra.NewMerge($3, $4.Indexes(), source, $8, $9, $10, $11, $12)
+              merge = algebra.NewMerge($4, $5.Indexes(), source, $9, $10, $11, $12, $14)
          case *algebra.ExpressionTerm:
               source := algebra.NewMergeSourceExpression(other)
-              $$ = algebra.NewMerge($3, $4.Indexes(), source, $8, $9, $10, $11, $12)
+              merge = algebra.NewMerge($4, $5.Indexes(), source, $9, $10, $11, $12, $14)
          case *algebra.KeyspaceTerm:
               source := algebra.NewMergeSourceFrom(other)
-              $$ = algebra.NewMerge($3, $4.Indexes(), source, $8, $9, $10, $11, $12)
+              merge = algebra.NewMerge($4, $5.Indexes(), source, $9, $10, $11, $12, $14)
          default:
               yylex.Error("MERGE source term is UNKNOWN"+yylex.(*lexer).ErrorContext())
      }
+     if merge != nil {
+         merge.SetValidate($13)
+         merge.SetOptimHints($2)
+         $$ = merge
+     }
 }
 ;
 
@@ -2531,6 +2640,76 @@ REVOKE role_list ON keyspace_scope_list FROM user_list
 }
 ;
 
+/*************************************************
+ *
+ * CREATE USER
+ *
+ *************************************************/
+
+create_user:
+CREATE USER user PASSWORD STR opt_index_with
+{
+    $$ = algebra.NewCreateUser($3, $5, $6, yylex.(*lexer).redact($<tokStart>5, $<tokOffset>5))
+}
+;
+
+/*************************************************
+ *
+ * ALTER USER
+ *
+ *************************************************/
+
+alter_user:
+ALTER USER user PASSWORD STR opt_index_with
+{
+    $$ = algebra.NewAlterUser($3, $5, $6, yylex.(*lexer).redact($<tokStart>5, $<tokOffset>5))
+}
+|
+ALTER USER user index_with
+{
+    $$ = algebra.NewAlterUser($3, "", $4, "")
+}
+;
+
+/*************************************************
+ *
+ * DROP USER
+ *
+ *************************************************/
+
+drop_user:
+DROP USER user
+{
+    $$ = algebra.NewDropUser($3)
+}
+;
+
+/*************************************************
+ *
+ * CREATE GROUP
+ *
+ *************************************************/
+
+create_group:
+CREATE GROUP IDENT opt_index_with
+{
+    $$ = algebra.NewCreateGroup($3, $4)
+}
+;
+
+/*************************************************
+ *
+ * DROP GROUP
+ *
+ *************************************************/
+
+drop_group:
+DROP GROUP IDENT
+{
+    $$ = algebra.NewDropGroup($3)
+}
+;
+
 /*************************************************
  *
  * CREATE SCOPE
@@ -2583,6 +2762,117 @@ DROP COLLECTION named_keyspace_ref opt_if_exists
 }
 ;
 
+/*************************************************
+ *
+ * CREATE MATERIALIZED VIEW
+ *
+ *************************************************/
+
+create_materialized_view:
+CREATE MATERIALIZED VIEW named_keyspace_ref opt_if_not_exists AS select_stmt opt_index_with
+{
+    text := yylex.(*lexer).getText()[$<tokStart>7:]
+    if $8 != nil {
+        text = yylex.(*lexer).getText()[$<tokStart>7:$<tokStart>8]
+    }
+    $$ = algebra.NewCreateMaterializedView($4, $7.(*algebra.Select), strings.TrimRight(text, " \t\r\n;"), $8, $5)
+}
+;
+
+/*************************************************
+ *
+ * REFRESH MATERIALIZED VIEW
+ *
+ *************************************************/
+
+/* REFRESH, FULL and INCREMENTAL are not reserved words */
+refresh_materialized_view:
+IDENT MATERIALIZED VIEW named_keyspace_ref opt_refresh_mode
+{
+    if strings.ToUpper($1) != "REFRESH" {
+        yylex.Error(fmt.Sprintf("syntax error - unexpected %s%s", $1, yylex.(*lexer).ErrorContext()))
+    }
+    $$ = algebra.NewRefreshMaterializedView($4, $5)
+}
+;
+
+opt_refresh_mode:
+/* empty */
+{
+    $$ = ""
+}
+|
+IDENT
+{
+    $$ = strings.ToUpper($1)
+    if $$ != algebra.MV_REFRESH_FULL && $$ != algebra.MV_REFRESH_INCREMENTAL {
+        yylex.Error(fmt.Sprintf("syntax error - unexpected %s%s", $1, yylex.(*lexer).ErrorContext()))
+    }
+}
+;
+
+/*************************************************
+ *
+ * DROP MATERIALIZED VIEW
+ *
+ *************************************************/
+
+drop_materialized_view:
+DROP MATERIALIZED VIEW named_keyspace_ref opt_if_exists
+{
+    $$ = algebra.NewDropMaterializedView($4, $5)
+}
+;
+
+/*************************************************
+ *
+ * CREATE TRIGGER
+ *
+ *************************************************/
+
+/* BEFORE and AFTER are not reserved words */
+create_trigger:
+CREATE TRIGGER IDENT opt_if_not_exists IDENT trigger_event ON named_keyspace_ref
+FOR EACH ROW EXECUTE FUNCTION func_name LPAREN opt_exprs RPAREN
+{
+    timing := strings.ToUpper($5)
+    if timing != datastore.TRIGGER_BEFORE && timing != datastore.TRIGGER_AFTER {
+        yylex.Error(fmt.Sprintf("syntax error - unexpected %s%s", $5, yylex.(*lexer).ErrorContext()))
+    }
+    $$ = algebra.NewCreateTrigger($3, $8, timing, $6, $14, $16, $4)
+}
+;
+
+trigger_event:
+INSERT
+{
+    $$ = datastore.TRIGGER_INSERT
+}
+|
+UPDATE
+{
+    $$ = datastore.TRIGGER_UPDATE
+}
+|
+DELETE
+{
+    $$ = datastore.TRIGGER_DELETE
+}
+;
+
+/*************************************************
+ *
+ * DROP TRIGGER
+ *
+ *************************************************/
+
+drop_trigger:
+DROP TRIGGER IDENT ON named_keyspace_ref opt_if_exists
+{
+    $$ = algebra.NewDropTrigger($3, $5, $6)
+}
+;
+
 /*************************************************
  *
  * FLUSH COLLECTION
@@ -3112,6 +3402,213 @@ EXECUTE FUNCTION func_name LPAREN opt_exprs RPAREN
 {
     $$ = algebra.NewExecuteFunction($3, $5)
 }
+|
+CALL func_name LPAREN opt_exprs RPAREN
+{
+    $$ = algebra.NewExecuteFunction($2, $4)
+}
+;
+
+/*************************************************
+ *
+ * CREATE PROCEDURE
+ *
+ *************************************************/
+
+create_procedure:
+CREATE opt_replace PROCEDURE func_name
+{
+    if $4 != nil {
+        // push function query context
+        yylex.(*lexer).PushQueryContext($4.QueryContext())
+    }
+}
+LPAREN parm_list RPAREN opt_if_not_exists proc_body
+{
+    if $4 != nil {
+        yylex.(*lexer).PopQueryContext()
+    }
+    if $10 != nil {
+        err := $10.SetVarNames($7)
+        if err != nil {
+            yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+        }
+    }
+    if $2.Value().Truth() && !$9 {
+        return yylex.(*lexer).FatalError(
+            fmt.Sprintf("syntax error - OR REPLACE and IF NOT EXISTS are mutually exclusive%s", $2.ErrorContext()))
+    }
+    $$ = algebra.NewCreateFunction($4, $10, $2.Value().Truth(), $9)
+}
+;
+
+proc_body:
+proc_block
+{
+    text := yylex.(*lexer).getText()[$<tokStart>1:$<tokOffset>1]
+    body, err := functionsBridge.NewProceduralBody($1, text, yylex.(*lexer).QueryContext())
+    if err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    } else {
+        $$ = body
+    }
+}
+;
+
+proc_block:
+BEGIN proc_stmts opt_proc_handler END
+{
+    $$ = algebra.NewProcBlock($2, $3, $<s>3)
+    $<tokOffset>$ = $<tokOffset>4
+}
+;
+
+/* EXCEPTION is not a reserved word */
+opt_proc_handler:
+/* empty */
+{
+    $$ = nil
+    $<s>$ = ""
+}
+|
+IDENT WHEN OTHERS opt_proc_error THEN proc_stmts
+{
+    if strings.ToUpper($1) != "EXCEPTION" {
+        yylex.Error(fmt.Sprintf("syntax error - unexpected %s%s", $1, yylex.(*lexer).ErrorContext()))
+    }
+    $$ = $6
+    if $$ == nil {
+        $$ = algebra.ProcStatements{}
+    }
+    $<s>$ = $4
+}
+;
+
+opt_proc_error:
+/* empty */
+{
+    $$ = ""
+}
+|
+AS IDENT
+{
+    $$ = $2
+}
+;
+
+proc_stmts:
+/* empty */
+{
+    $$ = nil
+}
+|
+proc_stmts proc_stmt
+{
+    $$ = append($1, $2)
+}
+;
+
+proc_stmt:
+DECLARE IDENT opt_proc_value SEMI
+{
+    $$ = algebra.NewProcDeclare($2, $3)
+}
+|
+SET IDENT EQ expr SEMI
+{
+    $$ = algebra.NewProcSet($2, $4)
+}
+|
+IF expr THEN proc_stmts proc_else END IF SEMI
+{
+    $$ = algebra.NewProcIf($2, $4, $5)
+}
+|
+WHILE expr DO proc_stmts END WHILE SEMI
+{
+    $$ = algebra.NewProcWhile($2, $4)
+}
+|
+FOR IDENT IN expr DO proc_stmts END FOR SEMI
+{
+    $$ = algebra.NewProcFor($2, $4, $6)
+}
+|
+BREAK SEMI
+{
+    $$ = algebra.NewProcLoopControl(false)
+}
+|
+CONTINUE SEMI
+{
+    $$ = algebra.NewProcLoopControl(true)
+}
+|
+RETURN SEMI
+{
+    $$ = algebra.NewProcReturn(nil)
+}
+|
+RETURN expr SEMI
+{
+    $$ = algebra.NewProcReturn($2)
+}
+|
+proc_block SEMI
+{
+    $$ = $1
+}
+|
+stmt SEMI
+{
+    $$ = algebra.NewProcExecute(yylex.(*lexer).getText()[$<tokStart>1:$<tokStart>2], $1)
+}
+;
+
+opt_proc_value:
+/* empty */
+{
+    $$ = nil
+}
+|
+EQ expr
+{
+    $$ = $2
+}
+;
+
+/* ELSEIF is not a reserved word */
+proc_else:
+/* empty */
+{
+    $$ = nil
+}
+|
+ELSE proc_stmts
+{
+    $$ = $2
+}
+|
+IDENT expr THEN proc_stmts proc_else
+{
+    if strings.ToUpper($1) != "ELSEIF" {
+        yylex.Error(fmt.Sprintf("syntax error - unexpected %s%s", $1, yylex.(*lexer).ErrorContext()))
+    }
+    $$ = algebra.ProcStatements{algebra.NewProcIf($2, $4, $5)}
+}
+;
+
+/*************************************************
+ *
+ * DROP PROCEDURE
+ *
+ *************************************************/
+
+drop_procedure:
+DROP PROCEDURE func_name opt_if_exists
+{
+    $$ = algebra.NewDropFunction($3, $4)
+}
 ;
 
 /*************************************************
@@ -3391,6 +3888,16 @@ expr CONCAT expr
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
+/* Collation */
+expr COLLATE STR
+{
+    if _, err := expression.NewCollation($3); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewCollationKey($1, expression.NewConstant($3))
+    $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
+}
+|
 /* Logical */
 expr AND expr
 {
@@ -3413,126 +3920,172 @@ NOT expr
 /* Comparison */
 expr EQ expr
 {
-    $$ = expression.NewEq($1, $3)
+    $$ = expression.NewEq(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr DEQ expr
 {
-    $$ = expression.NewEq($1, $3)
+    $$ = expression.NewEq(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NE expr
 {
-    $$ = expression.NewNE($1, $3)
+    $$ = expression.NewNE(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr LT expr
 {
-    $$ = expression.NewLT($1, $3)
+    $$ = expression.NewLT(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr GT expr
 {
-    $$ = expression.NewGT($1, $3)
+    $$ = expression.NewGT(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr LE expr
 {
-    $$ = expression.NewLE($1, $3)
+    $$ = expression.NewLE(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr GE expr
 {
-    $$ = expression.NewGE($1, $3)
+    $$ = expression.NewGE(expression.Collated($1, $3))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr BETWEEN b_expr AND b_expr
 {
-    $$ = expression.NewBetween($1, $3, $5)
+    item, low := expression.Collated($1, $3)
+    item, high := expression.Collated(item, $5)
+    low, _ = expression.Collated(low, item)
+    $$ = expression.NewBetween(item, low, high)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT BETWEEN b_expr AND b_expr
 {
-    $$ = expression.NewNotBetween($1, $4, $6)
+    item, low := expression.Collated($1, $4)
+    item, high := expression.Collated(item, $6)
+    low, _ = expression.Collated(low, item)
+    $$ = expression.NewNotBetween(item, low, high)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr LIKE expr ESCAPE expr
 {
+    if err := expression.NotCollated("LIKE", $1, $3); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewLike($1, $3, $5)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr LIKE expr
 {
+    if err := expression.NotCollated("LIKE", $1, $3); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewLike($1, $3, expression.DEFAULT_ESCAPE_EXPR)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT LIKE expr ESCAPE expr
 {
+    if err := expression.NotCollated("LIKE", $1, $4); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewNotLike($1, $4, $6)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT LIKE expr
 {
+    if err := expression.NotCollated("LIKE", $1, $4); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewNotLike($1, $4, expression.DEFAULT_ESCAPE_EXPR)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr IN expr
 {
-    $$ = expression.NewIn($1, $3)
+    item, collection, err := expression.CollatedIn($1, $3)
+    if err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewIn(item, collection)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr IN LPAREN in_expr_list RPAREN
 {
-    $$ = expression.NewIn($1, expression.NewArrayConstruct($4...))
+    item, collection, err := expression.CollatedIn($1, expression.NewArrayConstruct($4...))
+    if err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewIn(item, collection)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT IN expr
 {
-    $$ = expression.NewNotIn($1, $4)
+    item, collection, err := expression.CollatedIn($1, $4)
+    if err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewNotIn(item, collection)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT IN LPAREN in_expr_list RPAREN
 {
-    $$ = expression.NewNotIn($1, expression.NewArrayConstruct($5...))
+    item, collection, err := expression.CollatedIn($1, expression.NewArrayConstruct($5...))
+    if err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewNotIn(item, collection)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr WITHIN expr
 {
+    if err := expression.NotCollated("WITHIN", $1, $3); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewWithin($1, $3)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr WITHIN LPAREN in_expr_list RPAREN
 {
+    if err := expression.NotCollated("WITHIN", append(expression.Expressions{$1}, $4...)...); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewWithin($1, expression.NewArrayConstruct($4...))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT WITHIN expr
 {
+    if err := expression.NotCollated("WITHIN", $1, $4); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewNotWithin($1, $4)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
 |
 expr NOT WITHIN LPAREN in_expr_list RPAREN
 {
+    if err := expression.NotCollated("WITHIN", append(expression.Expressions{$1}, $5...)...); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
     $$ = expression.NewNotWithin($1, expression.NewArrayConstruct($5...))
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
@@ -3755,6 +4308,16 @@ b_expr CONCAT b_expr
     $$ = expression.NewConcat($1, $3)
     $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
 }
+|
+/* Collation */
+b_expr COLLATE STR
+{
+    if _, err := expression.NewCollation($3); err != nil {
+        yylex.Error(err.Error()+yylex.(*lexer).ErrorContext())
+    }
+    $$ = expression.NewCollationKey($1, expression.NewConstant($3))
+    $$.ExprBase().SetErrorContext($1.ExprBase().GetErrorContext())
+}
 ;
 
 /*************************************************
@@ -4083,7 +4646,7 @@ NTH_VALUE LPAREN exprs RPAREN opt_from_first_last opt_nulls_treatment window_fun
     }
 }
 |
-function_name LPAREN opt_exprs RPAREN opt_filter opt_nulls_treatment opt_window_function
+function_name LPAREN opt_exprs RPAREN opt_within_group opt_filter opt_nulls_treatment opt_window_function
 {
     fname := $1.Identifier()
     ectx := $1.ErrorContext()
@@ -4092,15 +4655,17 @@ function_name LPAREN opt_exprs RPAREN opt_filter opt_nulls_treatment opt_window_
     if !ok {
         f, ok = search.GetSearchFunction(fname)
     }
-    if !ok || $7 != nil {
-        f, ok = algebra.GetAggregate(fname, false, ($5 != nil), ($7 != nil))
+    if !ok || $5 != nil || $8 != nil {
+        f, ok = algebra.GetAggregate(fname, false, ($6 != nil), ($8 != nil))
     }
 
     if ok {
-        if ($6 == algebra.AGGREGATE_RESPECTNULLS && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_WINDOW_RESPECTNULLS)) ||
-           ($6 == algebra.AGGREGATE_IGNORENULLS && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_WINDOW_IGNORENULLS)) {
+        if ($7 == algebra.AGGREGATE_RESPECTNULLS && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_WINDOW_RESPECTNULLS)) ||
+           ($7 == algebra.AGGREGATE_IGNORENULLS && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_WINDOW_IGNORENULLS)) {
             yylex.Error(fmt.Sprintf("RESPECT|IGNORE NULLS syntax is not valid for function %s%s.", fname, ectx))
-        } else if ($5 != nil && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_ALLOWS_FILTER)) {
+        } else if ($5 != nil && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_ALLOWS_WITHIN_GROUP)) {
+            yylex.Error(fmt.Sprintf("WITHIN GROUP clause syntax is not valid for function %s%s.", fname, ectx))
+        } else if ($6 != nil && !algebra.AggregateHasProperty(fname, algebra.AGGREGATE_ALLOWS_FILTER)) {
             yylex.Error(fmt.Sprintf("FILTER clause syntax is not valid for function %s%s.", fname, ectx))
         } else if len($3) < f.MinArgs() || len($3) > f.MaxArgs() {
             if f.MinArgs() == f.MaxArgs() {
@@ -4111,7 +4676,8 @@ function_name LPAREN opt_exprs RPAREN opt_filter opt_nulls_treatment opt_window_
         } else {
             $$ = f.Constructor()($3...)
             if a, ok := $$.(algebra.Aggregate); ok {
-                a.SetAggregateModifiers($6, $5, $7)
+                a.SetAggregateModifiers($7, $6, $8)
+                a.SetWithinGroup($5)
             }
             $$.ExprBase().SetErrorContext(yylex.(*lexer).nex.Line()+1,yylex.(*lexer).nex.Column())
         }
@@ -4120,7 +4686,7 @@ function_name LPAREN opt_exprs RPAREN opt_filter opt_nulls_treatment opt_window_
         var err errors.Error
 
         f = nil
-        if $5 == nil && $6 == uint32(0) && $7 == nil {
+        if $5 == nil && $6 == nil && $7 == uint32(0) && $8 == nil {
             name, err = functionsBridge.NewFunctionName([]string{fname}, yylex.(*lexer).Namespace(), yylex.(*lexer).QueryContext())
             if err != nil {
                 return yylex.(*lexer).FatalError(err.Error()+yylex.(*lexer).ErrorContext())
@@ -4173,6 +4739,21 @@ function_name LPAREN STAR RPAREN opt_filter opt_window_function
     }
 }
 |
+// EVERY is a keyword, so the EVERY(expr) aggregate needs its own rule
+EVERY LPAREN expr RPAREN opt_filter opt_window_function
+{
+    $$ = nil
+    agg, ok := algebra.GetAggregate("every", false, ($5 != nil), ($6 != nil))
+    if ok {
+        $$ = agg.Constructor()($3)
+        if a, ok := $$.(algebra.Aggregate); ok {
+            a.SetAggregateModifiers(uint32(0), $5, $6)
+        }
+    } else {
+        yylex.Error(fmt.Sprintf("Invalid aggregate function EVERY%s.", $3.ErrorContext()))
+    }
+}
+|
 long_func_name LPAREN opt_exprs RPAREN
 {
     f := expression.GetUserDefinedFunction($1)
@@ -4581,6 +5162,14 @@ DISTINCT
 }
 ;
 
+opt_within_group:
+/* empty */
+{ $$ = nil }
+|
+WITHIN_GROUP LPAREN order_by RPAREN
+{ $$ = $3 }
+;
+
 opt_filter:
 /* empty */
 { $$ = nil }
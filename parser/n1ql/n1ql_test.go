@@ -0,0 +1,97 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package n1ql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactPasswords(t *testing.T) {
+	cases := map[string]string{
+		`CREATE USER u1 PASSWORD "s3cret" WITH {"name": "U"}`: `CREATE USER u1 PASSWORD "*****" WITH {"name": "U"}`,
+		"ALTER USER u1 PASSWORD 's3cret'":                     `ALTER USER u1 PASSWORD "*****"`,
+
+		// statements that do not parse
+		`CREATE USER u1 PASSWORD "s3cret" WITH {`:         `CREATE USER u1 PASSWORD "*****" WITH {`,
+		"CREATE USER u1 PASSWORD s3cret":                  `CREATE USER u1 PASSWORD "*****"`,
+		`CREATE USER u1 PASSWORD "s3cret`:                 `CREATE USER u1 PASSWORD "*****"`,
+		"CREATE USER u1 PASSWORD":                         `CREATE USER u1 PASSWORD "*****"`,
+		"CREATE USER u1 ` PASSWORD \"s3cret\"":            "CREATE USER u1 ` \"*****\"",
+		"SELECT 1 -- password s3cret":                     `SELECT 1 "*****"`,
+		`EXPLAIN ALTER USER u1 PASSWORD "s3cret" garbage`: `EXPLAIN ALTER USER u1 PASSWORD "*****" garbage`,
+	}
+	for stmt, expected := range cases {
+		actual, ok := RedactPasswords(stmt)
+		if !ok || actual != expected {
+			t.Errorf("Masking %q: expected %q, actual %q", stmt, expected, actual)
+		}
+		if strings.Contains(actual, "s3cret") {
+			t.Errorf("Password shown in %q", actual)
+		}
+	}
+
+	for _, stmt := range []string{`SELECT "PASSWORD" FROM default`, "SELEC 1"} {
+		if actual, ok := RedactPasswords(stmt); ok || actual != stmt {
+			t.Errorf("Unexpected masking of %q: %q", stmt, actual)
+		}
+	}
+}
+
+func TestParsePasswordMasked(t *testing.T) {
+	_, err := ParseStatement(`CREATE USER u1 PASSWORD "s3cret" WITH {"name": }`)
+	if err == nil {
+		t.Fatalf("Expected syntax error")
+	}
+	text, _ := RedactPasswords(`CREATE USER u1 PASSWORD "s3cret" WITH {"name": }`)
+	_, err = ParseStatement(text)
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("Unexpected error for masked statement: %v", err)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	cases := map[string]string{
+		"SELECT *\n  FROM default;":                    "SELECT * FROM default",
+		"SELECT  a  FROM b -- all of them\n WHERE c=1": "SELECT a FROM b WHERE c = 1",
+		`SELECT /*+ INDEX(b  i) */ a FROM b`:           `SELECT /*+ INDEX(b  i) */ a FROM b`,
+		`SELECT "a  b", ` + "`x  y`" + ` FROM b`:       `SELECT "a  b" , ` + "`x  y`" + ` FROM b`,
+		`SELECT "héllo  wörld"  FROM b`:                `SELECT "héllo  wörld" FROM b`,
+		"  SELEC \"1 ":                                 `SELEC "1`,
+	}
+	for stmt, expected := range cases {
+		if actual := NormalizeText(stmt); actual != expected {
+			t.Errorf("Normalizing %q: expected %q, actual %q", stmt, expected, actual)
+		}
+	}
+	if NormalizeText(`SELECT "a  b"`) == NormalizeText(`SELECT "a b"`) {
+		t.Errorf("Expected string literals to be kept apart")
+	}
+}
+
+func TestCollateOperators(t *testing.T) {
+	for _, stmt := range []string{
+		`SELECT 1 FROM b WHERE name COLLATE "en-ci" IN ["jose", "ana"]`,
+		`SELECT 1 FROM b WHERE name NOT IN ("jose" COLLATE "en-ci", "ana")`,
+	} {
+		if _, err := ParseStatement(stmt); err != nil {
+			t.Errorf("Unexpected error parsing %s: %v", stmt, err)
+		}
+	}
+
+	for _, stmt := range []string{
+		`SELECT 1 FROM b WHERE name COLLATE "en-ci" IN names`,
+		`SELECT 1 FROM b WHERE name COLLATE "en-ci" LIKE "jo%"`,
+		`SELECT 1 FROM b WHERE name NOT WITHIN ("jose" COLLATE "en-ci")`,
+	} {
+		if _, err := ParseStatement(stmt); err == nil || !strings.Contains(err.Error(), "COLLATE") {
+			t.Errorf("Expected COLLATE error parsing %s, got %v", stmt, err)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/metadata"
+	"github.com/couchbase/query/parser/n1ql"
+)
+
+const (
+	BASELINE_ACCEPTED = "accepted"
+	BASELINE_REJECTED = "rejected"
+)
+
+/*
+A plan baseline pins the plan of a statement. While the baseline is
+accepted, the planner uses its plan for any statement with the same
+normalized text and query context, instead of optimizing afresh, so
+that new indexes or statistics do not change the plan until the
+baseline is evolved, i.e. captured again.
+
+The plan is held encoded, as the indexes it references may be dropped
+and recreated, and is decoded and verified on use.
+*/
+type Baseline struct {
+	uses               int64 // accessed atomically, keep aligned
+	name               string
+	text               string
+	queryContext       string
+	namespace          string
+	state              string
+	plan               json.RawMessage
+	indexScanKeyspaces map[string]bool
+	captured           time.Time
+
+	sync.RWMutex
+	prepared *Prepared // the verified plan, nil until first use
+}
+
+func NewBaseline(name, text, queryContext, namespace string, op Operator,
+	indexScanKeyspaces map[string]bool) (*Baseline, error) {
+	bytes, err := json.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	return &Baseline{
+		name:               name,
+		text:               NormalizeBaselineText(text),
+		queryContext:       queryContext,
+		namespace:          namespace,
+		state:              BASELINE_ACCEPTED,
+		plan:               bytes,
+		indexScanKeyspaces: indexScanKeyspaces,
+		captured:           time.Now(),
+	}, nil
+}
+
+/*
+Statements differing only in white space and comments between tokens,
+or in a trailing semicolon, share baselines; string literals must match.
+*/
+func NormalizeBaselineText(text string) string {
+	return n1ql.NormalizeText(text)
+}
+
+func (this *Baseline) Name() string {
+	return this.name
+}
+
+func (this *Baseline) Text() string {
+	return this.text
+}
+
+func (this *Baseline) QueryContext() string {
+	return this.queryContext
+}
+
+func (this *Baseline) Namespace() string {
+	return this.namespace
+}
+
+func (this *Baseline) State() string {
+	return this.state
+}
+
+func (this *Baseline) Accepted() bool {
+	return this.state == BASELINE_ACCEPTED
+}
+
+func (this *Baseline) Plan() json.RawMessage {
+	return this.plan
+}
+
+func (this *Baseline) IndexScanKeyspaces() map[string]bool {
+	return this.indexScanKeyspaces
+}
+
+func (this *Baseline) Captured() time.Time {
+	return this.captured
+}
+
+func (this *Baseline) Uses() int64 {
+	return atomic.LoadInt64(&this.uses)
+}
+
+type baselineEntry struct {
+	Name               string          `json:"name"`
+	Text               string          `json:"statement"`
+	QueryContext       string          `json:"query_context,omitempty"`
+	Namespace          string          `json:"namespace"`
+	State              string          `json:"state"`
+	Plan               json.RawMessage `json:"plan"`
+	IndexScanKeyspaces map[string]bool `json:"index_scan_keyspaces,omitempty"`
+	Captured           time.Time       `json:"captured"`
+}
+
+/*
+The stored form of a baseline. Uses are not stored: they are counted
+by each node.
+*/
+func (this *Baseline) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&baselineEntry{
+		Name:               this.name,
+		Text:               this.text,
+		QueryContext:       this.queryContext,
+		Namespace:          this.namespace,
+		State:              this.state,
+		Plan:               this.plan,
+		IndexScanKeyspaces: this.indexScanKeyspaces,
+		Captured:           this.captured,
+	})
+}
+
+func UnmarshalBaseline(bytes []byte) (*Baseline, error) {
+	var entry baselineEntry
+	err := json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &Baseline{
+		name:               entry.Name,
+		text:               entry.Text,
+		queryContext:       entry.QueryContext,
+		namespace:          entry.Namespace,
+		state:              entry.State,
+		plan:               entry.Plan,
+		indexScanKeyspaces: entry.IndexScanKeyspaces,
+		captured:           entry.Captured,
+	}, nil
+}
+
+// same capture, in the same state
+func (this *Baseline) sameAs(other *Baseline) bool {
+	return this.name == other.name && this.text == other.text && this.queryContext == other.queryContext &&
+		this.state == other.state && this.captured.Equal(other.captured)
+}
+
+/*
+Return a copy of the baseline in a different state. The plan and its
+verification are shared, but uses are counted afresh.
+*/
+func (this *Baseline) WithState(state string) *Baseline {
+	this.RLock()
+	prepared := this.prepared
+	this.RUnlock()
+	return &Baseline{
+		name:               this.name,
+		text:               this.text,
+		queryContext:       this.queryContext,
+		namespace:          this.namespace,
+		state:              state,
+		plan:               this.plan,
+		indexScanKeyspaces: this.indexScanKeyspaces,
+		captured:           this.captured,
+		prepared:           prepared,
+	}
+}
+
+/*
+Return the pinned plan, if it is still valid against the current
+indexes and keyspaces.
+*/
+func (this *Baseline) Operator() (Operator, bool) {
+	this.RLock()
+	prepared := this.prepared
+	this.RUnlock()
+
+	// as with cached prepared statements, checking the metadata
+	// versions without a lock is fine: the plan tree does not change
+	if prepared != nil && prepared.MetadataCheck() {
+		atomic.AddInt64(&this.uses, 1)
+		return prepared.Operator, true
+	}
+
+	this.Lock()
+	defer this.Unlock()
+	if this.prepared != nil && this.prepared.Verify() {
+		atomic.AddInt64(&this.uses, 1)
+		return this.prepared.Operator, true
+	}
+
+	// the indexes may have been recreated: decode the plan afresh
+	this.prepared = nil
+	var op_type struct {
+		Operator string `json:"#operator"`
+	}
+	err := json.Unmarshal(this.plan, &op_type)
+	if err != nil {
+		return nil, false
+	}
+	op, err := MakeOperator(op_type.Operator, this.plan)
+	if err != nil {
+		return nil, false
+	}
+	prepared = NewPrepared(op, nil, this.indexScanKeyspaces)
+	if !prepared.Verify() {
+		return nil, false
+	}
+	this.prepared = prepared
+	atomic.AddInt64(&this.uses, 1)
+	return op, true
+}
+
+/*
+BaselineStore holds the plan baselines by name, and is listed through
+system:plan_baselines. At most one baseline can pin the plan of each
+statement text and query context.
+
+PutBaseline returns false when another baseline pins the statement,
+and DropBaseline returns nil when there is no such baseline. Errors
+are failures to store the change.
+*/
+type BaselineStore interface {
+	Baseline(name string) (*Baseline, bool)
+	Match(text, queryContext string) (*Baseline, bool)
+	PutBaseline(baseline *Baseline) (bool, error)
+	DropBaseline(name string) (*Baseline, error)
+	Names() []string
+}
+
+/*
+Until the server sets a store kept in metakv, baselines only live as
+long as the process, which is what standalone tools and tests need.
+*/
+var _BASELINESTORE BaselineStore = newBaselineStore()
+
+func SetBaselineStore(store BaselineStore) {
+	_BASELINESTORE = store
+}
+
+func GetBaselineStore() BaselineStore {
+	return _BASELINESTORE
+}
+
+const _BASELINES_PATH = "/query/plan_baselines/"
+
+type baselineStore struct {
+	sync.RWMutex
+	baselines  map[string]*Baseline
+	statements map[string]string
+	mirror     *metadata.Mirror
+}
+
+func newBaselineStore() *baselineStore {
+	return &baselineStore{
+		baselines:  make(map[string]*Baseline),
+		statements: make(map[string]string),
+	}
+}
+
+/*
+A store shared by the query nodes of the cluster through metakv.
+Plans are matched against the baselines each node holds, so a baseline
+captured on one node takes effect on the others as soon as metakv
+reports it.
+*/
+func NewMetakvBaselineStore() BaselineStore {
+	rv := newBaselineStore()
+	rv.mirror = metadata.NewMirror(_BASELINES_PATH, rv.applyEntry)
+	rv.mirror.Start()
+	return rv
+}
+
+func statementKey(text, queryContext string) string {
+	return queryContext + "\n" + text
+}
+
+func (this *baselineStore) Baseline(name string) (*Baseline, bool) {
+	this.RLock()
+	defer this.RUnlock()
+	rv, ok := this.baselines[name]
+	return rv, ok
+}
+
+func (this *baselineStore) Match(text, queryContext string) (*Baseline, bool) {
+	this.RLock()
+	defer this.RUnlock()
+	if len(this.baselines) == 0 {
+		return nil, false
+	}
+	name, ok := this.statements[statementKey(NormalizeBaselineText(text), queryContext)]
+	if !ok {
+		return nil, false
+	}
+	return this.baselines[name], true
+}
+
+// replaces the baseline of the same name, fails if another baseline pins the statement
+func (this *baselineStore) PutBaseline(baseline *Baseline) (bool, error) {
+	var bytes []byte
+	if this.mirror != nil {
+		var err error
+		bytes, err = json.Marshal(baseline)
+		if err != nil {
+			return false, err
+		}
+	}
+	if !this.put(baseline) {
+		return false, nil
+	}
+	if this.mirror != nil {
+		return true, this.mirror.Set(baseline.name, bytes)
+	}
+	return true, nil
+}
+
+func (this *baselineStore) put(baseline *Baseline) bool {
+	this.Lock()
+	defer this.Unlock()
+	key := statementKey(baseline.text, baseline.queryContext)
+	if name, ok := this.statements[key]; ok && name != baseline.name {
+		return false
+	}
+	if old, ok := this.baselines[baseline.name]; ok {
+		delete(this.statements, statementKey(old.text, old.queryContext))
+	}
+	this.baselines[baseline.name] = baseline
+	this.statements[key] = baseline.name
+	return true
+}
+
+func (this *baselineStore) DropBaseline(name string) (*Baseline, error) {
+	baseline := this.drop(name)
+	if baseline != nil && this.mirror != nil {
+		return baseline, this.mirror.Delete(name)
+	}
+	return baseline, nil
+}
+
+func (this *baselineStore) drop(name string) *Baseline {
+	this.Lock()
+	defer this.Unlock()
+	baseline, ok := this.baselines[name]
+	if !ok {
+		return nil
+	}
+	delete(this.baselines, name)
+	delete(this.statements, statementKey(baseline.text, baseline.queryContext))
+	return baseline
+}
+
+/*
+Apply a change reported by metakv. Our own changes come back too, and
+leave the baseline alone, so that its verified plan and use count are
+kept.
+*/
+func (this *baselineStore) applyEntry(name string, bytes []byte) {
+	if bytes == nil {
+		this.drop(name)
+		return
+	}
+	baseline, err := UnmarshalBaseline(bytes)
+	if err != nil {
+		logging.Errorf("Ignoring plan baseline %v: %v", name, err)
+		return
+	}
+	if old, ok := this.Baseline(name); ok && old.sameAs(baseline) {
+		return
+	}
+
+	// a baseline pinning the same statement under another name has been
+	// dropped elsewhere, and its deletion has not reached us yet
+	this.Lock()
+	defer this.Unlock()
+	key := statementKey(baseline.text, baseline.queryContext)
+	if other, ok := this.statements[key]; ok && other != name {
+		delete(this.baselines, other)
+	}
+	if old, ok := this.baselines[name]; ok {
+		delete(this.statements, statementKey(old.text, old.queryContext))
+	}
+	this.baselines[name] = baseline
+	this.statements[key] = name
+}
+
+func (this *baselineStore) Names() []string {
+	this.RLock()
+	defer this.RUnlock()
+	rv := make([]string, 0, len(this.baselines))
+	for name, _ := range this.baselines {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
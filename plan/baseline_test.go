@@ -0,0 +1,76 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBaselineEncoding(t *testing.T) {
+	baseline, err := NewBaseline("b1", "SELECT *\n  FROM default;", "default:b.s", "default",
+		NewDummyScan(-1, -1, -1, -1), map[string]bool{"default:b.s.c": true})
+	if err != nil {
+		t.Fatalf("Unexpected error creating baseline: %v", err)
+	}
+	baseline = baseline.WithState(BASELINE_REJECTED)
+
+	bytes, err := json.Marshal(baseline)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding baseline: %v", err)
+	}
+	decoded, err := UnmarshalBaseline(bytes)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding baseline: %v", err)
+	}
+	if !decoded.sameAs(baseline) || decoded.Namespace() != "default" || string(decoded.Plan()) != string(baseline.Plan()) ||
+		!decoded.IndexScanKeyspaces()["default:b.s.c"] {
+		t.Errorf("Baseline changed in encoding: %s", bytes)
+	}
+	if decoded.Text() != "SELECT * FROM default" {
+		t.Errorf("Unexpected normalized text %q", decoded.Text())
+	}
+}
+
+func TestBaselineEntries(t *testing.T) {
+	store := newBaselineStore()
+	b1, _ := NewBaseline("b1", "SELECT 1", "", "default", NewDummyScan(-1, -1, -1, -1), nil)
+	if ok, err := store.PutBaseline(b1); !ok || err != nil {
+		t.Fatalf("Unexpected failure storing baseline: %v", err)
+	}
+	b2, _ := NewBaseline("b2", "SELECT 1", "", "default", NewDummyScan(-1, -1, -1, -1), nil)
+	if ok, _ := store.PutBaseline(b2); ok {
+		t.Errorf("Expected a second baseline on the same statement to fail")
+	}
+
+	// our own change coming back from metakv keeps the baseline
+	bytes, _ := json.Marshal(b1)
+	store.applyEntry("b1", bytes)
+	if b, _ := store.Baseline("b1"); b != b1 {
+		t.Errorf("Expected baseline to be unchanged")
+	}
+
+	// another node moved the statement to b2 before b1's deletion reached us
+	bytes, _ = json.Marshal(b2)
+	store.applyEntry("b2", bytes)
+	if b, ok := store.Match("SELECT  1;", ""); !ok || b.Name() != "b2" {
+		t.Errorf("Expected the statement to match b2")
+	}
+	if _, ok := store.Baseline("b1"); ok {
+		t.Errorf("Expected b1 to be superseded")
+	}
+
+	store.applyEntry("b2", nil)
+	if names := store.Names(); len(names) != 0 {
+		t.Errorf("Unexpected baselines %v", names)
+	}
+	if b, err := store.DropBaseline("b2"); b != nil || err != nil {
+		t.Errorf("Unexpected drop of missing baseline: %v, %v", b, err)
+	}
+}
@@ -24,15 +24,17 @@ type SendDelete struct {
 	term     *algebra.KeyspaceRef
 	alias    string
 	limit    expression.Expression
+	images   bool
 }
 
 func NewSendDelete(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef, limit expression.Expression,
-	cost, cardinality float64, size int64, frCost float64) *SendDelete {
+	images bool, cost, cardinality float64, size int64, frCost float64) *SendDelete {
 	rv := &SendDelete{
 		keyspace: keyspace,
 		term:     ksref,
 		alias:    ksref.Alias(),
 		limit:    limit,
+		images:   images,
 	}
 	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
 	return rv
@@ -62,6 +64,10 @@ func (this *SendDelete) Limit() expression.Expression {
 	return this.limit
 }
 
+func (this *SendDelete) Images() bool {
+	return this.images
+}
+
 func (this *SendDelete) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -75,6 +81,10 @@ func (this *SendDelete) MarshalBase(f func(map[string]interface{})) map[string]i
 		r["limit"] = this.limit
 	}
 
+	if this.images {
+		r["images"] = this.images
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -96,6 +106,7 @@ func (this *SendDelete) UnmarshalJSON(body []byte) error {
 		As          string                 `json:"as"`
 		Alias       string                 `json:"alias"`
 		Limit       string                 `json:"limit"`
+		Images      bool                   `json:"images"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 	}
 
@@ -113,6 +124,8 @@ func (this *SendDelete) UnmarshalJSON(body []byte) error {
 		}
 	}
 
+	this.images = _unmarshalled.Images
+
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	if _unmarshalled.Expr != "" {
@@ -19,6 +19,8 @@ type Explain struct {
 	op         Operator
 	text       string
 	optimHints *algebra.OptimHints
+	baseline   *Baseline
+	pinned     bool
 }
 
 func NewExplain(op Operator, text string, optimHints *algebra.OptimHints) *Explain {
@@ -41,6 +43,15 @@ func (this *Explain) Operator() Operator {
 	return this.op
 }
 
+/*
+Record the plan baseline matching the statement, and whether its plan
+was used.
+*/
+func (this *Explain) SetBaseline(baseline *Baseline, pinned bool) {
+	this.baseline = baseline
+	this.pinned = pinned
+}
+
 func (this *Explain) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -63,6 +74,13 @@ func (this *Explain) MarshalBase(f func(map[string]interface{})) map[string]inte
 		if this.optimHints != nil {
 			r["optimizer_hints"] = this.optimHints
 		}
+		if this.baseline != nil {
+			r["baseline"] = map[string]interface{}{
+				"name":  this.baseline.Name(),
+				"state": this.baseline.State(),
+				"used":  this.pinned,
+			}
+		}
 	}
 	return r
 }
@@ -101,7 +119,7 @@ func (this *Explain) UnmarshalJSON(body []byte) error {
 	// see the overall cost/cardinality for the entire plan, there is
 	// no need to put the info anywhere
 
-	// Optimizer hints is printed in explain for informational purpose only
+	// Optimizer hints and baseline are printed in explain for informational purpose only
 
 	this.op, err = MakeOperator(op_type.Operator, _unmarshalled.Op)
 	return err
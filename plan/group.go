@@ -117,6 +117,7 @@ type IntermediateGroup struct {
 	optEstimate
 	keys       expression.Expressions
 	aggregates algebra.Aggregates
+	partitions int
 }
 
 func NewIntermediateGroup(keys expression.Expressions, aggregates algebra.Aggregates,
@@ -145,6 +146,20 @@ func (this *IntermediateGroup) Aggregates() algebra.Aggregates {
 	return this.aggregates
 }
 
+/*
+The number of partitions of the group keys. With more than one, the
+copies of the operator in the enclosing Parallel merge the partial
+groups into shared partitions, and then each copy completes a disjoint
+set of partitions, so that the following FinalGroup can run in parallel.
+*/
+func (this *IntermediateGroup) Partitions() int {
+	return this.partitions
+}
+
+func (this *IntermediateGroup) SetPartitions(partitions int) {
+	this.partitions = partitions
+}
+
 func (this *IntermediateGroup) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -161,6 +176,9 @@ func (this *IntermediateGroup) MarshalBase(f func(map[string]interface{})) map[s
 		s = append(s, expression.NewStringer().Visit(agg))
 	}
 	r["aggregates"] = s
+	if this.partitions > 1 {
+		r["partitions"] = this.partitions
+	}
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -175,6 +193,7 @@ func (this *IntermediateGroup) UnmarshalJSON(body []byte) error {
 		_           string                 `json:"#operator"`
 		Keys        []string               `json:"group_keys"`
 		Aggs        []string               `json:"aggregates"`
+		Partitions  int                    `json:"partitions"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 	}
 
@@ -201,6 +220,7 @@ func (this *IntermediateGroup) UnmarshalJSON(body []byte) error {
 		this.aggregates[i], _ = agg_expr.(algebra.Aggregate)
 	}
 
+	this.partitions = _unmarshalled.Partitions
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	return nil
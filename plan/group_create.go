@@ -0,0 +1,76 @@
+// Copyright 2021-Present Couchbase, Inc.
+//
+// Use of this software is governed by the Business Source License included
+// in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+// in that file, in accordance with the Business Source License, use of this
+// software will be governed by the Apache License, Version 2.0, included in
+// the file licenses/APL2.txt.
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/value"
+)
+
+// Create group
+type CreateGroup struct {
+	ddl
+	node *algebra.CreateGroup
+}
+
+func NewCreateGroup(node *algebra.CreateGroup) *CreateGroup {
+	return &CreateGroup{
+		node: node,
+	}
+}
+
+func (this *CreateGroup) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateGroup(this)
+}
+
+func (this *CreateGroup) New() Operator {
+	return &CreateGroup{}
+}
+
+func (this *CreateGroup) Node() *algebra.CreateGroup {
+	return this.node
+}
+
+func (this *CreateGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *CreateGroup) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "CreateGroup"}
+	r["group"] = this.node.Group()
+	if this.node.With() != nil {
+		r["with"] = this.node.With()
+	}
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *CreateGroup) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_     string          `json:"#operator"`
+		Group string          `json:"group"`
+		With  json.RawMessage `json:"with"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	var with value.Value
+	if len(_unmarshalled.With) > 0 {
+		with = value.NewValue([]byte(_unmarshalled.With))
+	}
+
+	this.node = algebra.NewCreateGroup(_unmarshalled.Group, with)
+	return nil
+}
@@ -27,10 +27,12 @@ type SendInsert struct {
 	value    expression.Expression
 	options  expression.Expression
 	limit    expression.Expression
+	validate bool
+	images   bool
 }
 
 func NewSendInsert(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef,
-	key, value, options, limit expression.Expression, cost, cardinality float64,
+	key, value, options, limit expression.Expression, validate, images bool, cost, cardinality float64,
 	size int64, frCost float64) *SendInsert {
 	rv := &SendInsert{
 		keyspace: keyspace,
@@ -40,6 +42,8 @@ func NewSendInsert(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef,
 		value:    value,
 		options:  options,
 		limit:    limit,
+		validate: validate,
+		images:   images,
 	}
 	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
 	return rv
@@ -81,6 +85,14 @@ func (this *SendInsert) Limit() expression.Expression {
 	return this.limit
 }
 
+func (this *SendInsert) Validate() bool {
+	return this.validate
+}
+
+func (this *SendInsert) Images() bool {
+	return this.images
+}
+
 func (this *SendInsert) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -106,6 +118,14 @@ func (this *SendInsert) MarshalBase(f func(map[string]interface{})) map[string]i
 		r["options"] = this.options.String()
 	}
 
+	if this.validate {
+		r["validate"] = this.validate
+	}
+
+	if this.images {
+		r["images"] = this.images
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -130,6 +150,8 @@ func (this *SendInsert) UnmarshalJSON(body []byte) error {
 		As          string                 `json:"as"`
 		Alias       string                 `json:"alias"`
 		Limit       string                 `json:"limit"`
+		Validate    bool                   `json:"validate"`
+		Images      bool                   `json:"images"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 	}
 
@@ -168,6 +190,9 @@ func (this *SendInsert) UnmarshalJSON(body []byte) error {
 
 	this.alias = _unmarshalled.Alias
 
+	this.validate = _unmarshalled.Validate
+	this.images = _unmarshalled.Images
+
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	if _unmarshalled.Expr != "" {
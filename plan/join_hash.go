@@ -26,6 +26,7 @@ type HashJoin struct {
 	probeExprs   expression.Expressions
 	buildAliases []string
 	filter       expression.Expression
+	partitions   int
 }
 
 func NewHashJoin(join *algebra.AnsiJoin, child Operator, buildExprs, probeExprs expression.Expressions,
@@ -96,6 +97,19 @@ func (this *HashJoin) SetFilter(filter expression.Expression) {
 	this.filter = filter
 }
 
+/*
+The number of partitions of the hash table. With more than one, the
+table is built by as many goroutines, and is shared by all the copies
+of the operator in the enclosing Parallel, which probe it concurrently.
+*/
+func (this *HashJoin) Partitions() int {
+	return this.partitions
+}
+
+func (this *HashJoin) SetPartitions(partitions int) {
+	this.partitions = partitions
+}
+
 func (this *HashJoin) SetCardinality(cardinality float64) {
 	this.cardinality = cardinality
 }
@@ -132,6 +146,10 @@ func (this *HashJoin) MarshalBase(f func(map[string]interface{})) map[string]int
 		r["filter"] = expression.NewStringer().Visit(this.filter)
 	}
 
+	if this.partitions > 1 {
+		r["partitions"] = this.partitions
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -153,6 +171,7 @@ func (this *HashJoin) UnmarshalJSON(body []byte) error {
 		ProbeExprs   []string               `json:"probe_exprs"`
 		BuildAliases []string               `json:"build_aliases"`
 		Filter       string                 `json:"filter"`
+		Partitions   int                    `json:"partitions"`
 		OptEstimate  map[string]interface{} `json:"optimizer_estimates"`
 		Child        json.RawMessage        `json:"~child"`
 	}
@@ -198,6 +217,7 @@ func (this *HashJoin) UnmarshalJSON(body []byte) error {
 		}
 	}
 
+	this.partitions = _unmarshalled.Partitions
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	raw_child := _unmarshalled.Child
@@ -0,0 +1,223 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/expression/parser"
+)
+
+/*
+MergeJoin joins its input (the left-hand side) with its child (the
+right-hand side). Both sides must arrive ordered on their respective
+merge keys, in the same direction.
+*/
+type MergeJoin struct {
+	readonly
+	optEstimate
+	outer        bool
+	onclause     expression.Expression
+	child        Operator
+	leftKey      expression.Expression
+	rightKey     expression.Expression
+	descending   bool
+	rightAliases []string
+	filter       expression.Expression
+}
+
+func NewMergeJoin(join *algebra.AnsiJoin, child Operator, leftKey, rightKey expression.Expression,
+	descending bool, rightAliases []string, filter expression.Expression, cost, cardinality float64,
+	size int64, frCost float64) *MergeJoin {
+	rv := &MergeJoin{
+		outer:        join.Outer(),
+		onclause:     join.Onclause(),
+		child:        child,
+		leftKey:      leftKey,
+		rightKey:     rightKey,
+		descending:   descending,
+		rightAliases: rightAliases,
+		filter:       filter,
+	}
+	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
+	return rv
+}
+
+func (this *MergeJoin) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitMergeJoin(this)
+}
+
+func (this *MergeJoin) New() Operator {
+	return &MergeJoin{}
+}
+
+func (this *MergeJoin) Outer() bool {
+	return this.outer
+}
+
+func (this *MergeJoin) Onclause() expression.Expression {
+	return this.onclause
+}
+
+func (this *MergeJoin) SetOnclause(onclause expression.Expression) {
+	this.onclause = onclause
+}
+
+func (this *MergeJoin) Child() Operator {
+	return this.child
+}
+
+func (this *MergeJoin) LeftKey() expression.Expression {
+	return this.leftKey
+}
+
+func (this *MergeJoin) SetLeftKey(leftKey expression.Expression) {
+	this.leftKey = leftKey
+}
+
+func (this *MergeJoin) RightKey() expression.Expression {
+	return this.rightKey
+}
+
+func (this *MergeJoin) SetRightKey(rightKey expression.Expression) {
+	this.rightKey = rightKey
+}
+
+func (this *MergeJoin) Descending() bool {
+	return this.descending
+}
+
+func (this *MergeJoin) RightAliases() []string {
+	return this.rightAliases
+}
+
+func (this *MergeJoin) Filter() expression.Expression {
+	return this.filter
+}
+
+func (this *MergeJoin) SetFilter(filter expression.Expression) {
+	this.filter = filter
+}
+
+func (this *MergeJoin) SetCardinality(cardinality float64) {
+	this.cardinality = cardinality
+}
+
+func (this *MergeJoin) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *MergeJoin) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "MergeJoin"}
+	if this.onclause != nil {
+		r["on_clause"] = expression.NewStringer().Visit(this.onclause)
+	}
+
+	if this.outer {
+		r["outer"] = this.outer
+	}
+
+	r["left_key"] = expression.NewStringer().Visit(this.leftKey)
+	r["right_key"] = expression.NewStringer().Visit(this.rightKey)
+
+	if this.descending {
+		r["descending"] = this.descending
+	}
+
+	r["right_aliases"] = this.rightAliases
+
+	if this.filter != nil {
+		r["filter"] = expression.NewStringer().Visit(this.filter)
+	}
+
+	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
+		r["optimizer_estimates"] = optEstimate
+	}
+
+	if f != nil {
+		f(r)
+	} else {
+		r["~child"] = this.child
+	}
+	return r
+}
+
+func (this *MergeJoin) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_            string                 `json:"#operator"`
+		Onclause     string                 `json:"on_clause"`
+		Outer        bool                   `json:"outer"`
+		LeftKey      string                 `json:"left_key"`
+		RightKey     string                 `json:"right_key"`
+		Descending   bool                   `json:"descending"`
+		RightAliases []string               `json:"right_aliases"`
+		Filter       string                 `json:"filter"`
+		OptEstimate  map[string]interface{} `json:"optimizer_estimates"`
+		Child        json.RawMessage        `json:"~child"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if _unmarshalled.Onclause != "" {
+		this.onclause, err = parser.Parse(_unmarshalled.Onclause)
+		if err != nil {
+			return err
+		}
+	}
+
+	this.outer = _unmarshalled.Outer
+
+	this.leftKey, err = parser.Parse(_unmarshalled.LeftKey)
+	if err != nil {
+		return err
+	}
+
+	this.rightKey, err = parser.Parse(_unmarshalled.RightKey)
+	if err != nil {
+		return err
+	}
+
+	this.descending = _unmarshalled.Descending
+	this.rightAliases = _unmarshalled.RightAliases
+
+	if _unmarshalled.Filter != "" {
+		this.filter, err = parser.Parse(_unmarshalled.Filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
+
+	raw_child := _unmarshalled.Child
+	var child_type struct {
+		Op_name string `json:"#operator"`
+	}
+
+	err = json.Unmarshal(raw_child, &child_type)
+	if err != nil {
+		return err
+	}
+
+	this.child, err = MakeOperator(child_type.Op_name, raw_child)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (this *MergeJoin) verify(prepared *Prepared) bool {
+	return this.child.verify(prepared)
+}
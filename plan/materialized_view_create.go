@@ -0,0 +1,121 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/couchbase/query/datastore"
+)
+
+// Create materialized view
+type CreateMaterializedView struct {
+	ddl
+	view         *datastore.MaterializedView
+	failIfExists bool
+}
+
+func NewCreateMaterializedView(view *datastore.MaterializedView, failIfExists bool) *CreateMaterializedView {
+	return &CreateMaterializedView{
+		view:         view,
+		failIfExists: failIfExists,
+	}
+}
+
+func (this *CreateMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateMaterializedView(this)
+}
+
+func (this *CreateMaterializedView) New() Operator {
+	return &CreateMaterializedView{}
+}
+
+/*
+The view definition. Each execution creates its own copy.
+*/
+func (this *CreateMaterializedView) View() *datastore.MaterializedView {
+	return this.view
+}
+
+func (this *CreateMaterializedView) FailIfExists() bool {
+	return this.failIfExists
+}
+
+func (this *CreateMaterializedView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *CreateMaterializedView) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "CreateMaterializedView"}
+	r["name"] = this.view.Name
+	r["target"] = this.view.Target
+	r["query"] = this.view.Text
+	if this.view.Incremental() {
+		r["source"] = this.view.Source
+		r["alias"] = this.view.Alias
+		if this.view.Where != "" {
+			r["where"] = this.view.Where
+		}
+		r["keys"] = this.view.Keys
+		r["fields"] = this.view.Fields
+	}
+	if this.view.Interval > 0 {
+		r["refresh_interval"] = this.view.Interval.String()
+	}
+	r["query_rewrite"] = this.view.QueryRewrite
+	r["fail_if_exists"] = this.failIfExists
+
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *CreateMaterializedView) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_            string                        `json:"#operator"`
+		Name         string                        `json:"name"`
+		Target       string                        `json:"target"`
+		Query        string                        `json:"query"`
+		Source       string                        `json:"source"`
+		Alias        string                        `json:"alias"`
+		Where        string                        `json:"where"`
+		Keys         []string                      `json:"keys"`
+		Fields       []datastore.MaterializedField `json:"fields"`
+		Interval     string                        `json:"refresh_interval"`
+		QueryRewrite bool                          `json:"query_rewrite"`
+		FailIfExists bool                          `json:"fail_if_exists"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	this.view = &datastore.MaterializedView{
+		Name:         _unmarshalled.Name,
+		Target:       _unmarshalled.Target,
+		Text:         _unmarshalled.Query,
+		Source:       _unmarshalled.Source,
+		Alias:        _unmarshalled.Alias,
+		Where:        _unmarshalled.Where,
+		Keys:         _unmarshalled.Keys,
+		Fields:       _unmarshalled.Fields,
+		QueryRewrite: _unmarshalled.QueryRewrite,
+	}
+	if _unmarshalled.Interval != "" {
+		this.view.Interval, err = time.ParseDuration(_unmarshalled.Interval)
+		if err != nil {
+			return err
+		}
+	}
+	this.failIfExists = _unmarshalled.FailIfExists
+	return nil
+}
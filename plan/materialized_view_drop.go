@@ -0,0 +1,75 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+)
+
+// Drop materialized view
+type DropMaterializedView struct {
+	ddl
+	name            string
+	failIfNotExists bool
+}
+
+func NewDropMaterializedView(name string, failIfNotExists bool) *DropMaterializedView {
+	return &DropMaterializedView{
+		name:            name,
+		failIfNotExists: failIfNotExists,
+	}
+}
+
+func (this *DropMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropMaterializedView(this)
+}
+
+func (this *DropMaterializedView) New() Operator {
+	return &DropMaterializedView{}
+}
+
+func (this *DropMaterializedView) Name() string {
+	return this.name
+}
+
+func (this *DropMaterializedView) FailIfNotExists() bool {
+	return this.failIfNotExists
+}
+
+func (this *DropMaterializedView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *DropMaterializedView) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "DropMaterializedView"}
+	r["name"] = this.name
+	r["fail_if_not_exists"] = this.failIfNotExists
+
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *DropMaterializedView) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_               string `json:"#operator"`
+		Name            string `json:"name"`
+		FailIfNotExists bool   `json:"fail_if_not_exists"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	this.name = _unmarshalled.Name
+	this.failIfNotExists = _unmarshalled.FailIfNotExists
+	return nil
+}
@@ -0,0 +1,77 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+)
+
+// Refresh materialized view
+type RefreshMaterializedView struct {
+	ddl
+	name string
+	mode string
+}
+
+func NewRefreshMaterializedView(name, mode string) *RefreshMaterializedView {
+	return &RefreshMaterializedView{
+		name: name,
+		mode: mode,
+	}
+}
+
+func (this *RefreshMaterializedView) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitRefreshMaterializedView(this)
+}
+
+func (this *RefreshMaterializedView) New() Operator {
+	return &RefreshMaterializedView{}
+}
+
+func (this *RefreshMaterializedView) Name() string {
+	return this.name
+}
+
+func (this *RefreshMaterializedView) Mode() string {
+	return this.mode
+}
+
+func (this *RefreshMaterializedView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *RefreshMaterializedView) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "RefreshMaterializedView"}
+	r["name"] = this.name
+	if this.mode != "" {
+		r["mode"] = this.mode
+	}
+
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *RefreshMaterializedView) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_    string `json:"#operator"`
+		Name string `json:"name"`
+		Mode string `json:"mode"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	this.name = _unmarshalled.Name
+	this.mode = _unmarshalled.Mode
+	return nil
+}
@@ -26,6 +26,7 @@ type HashNest struct {
 	probeExprs expression.Expressions
 	buildAlias string
 	filter     expression.Expression
+	partitions int
 }
 
 func NewHashNest(nest *algebra.AnsiNest, child Operator, buildExprs, probeExprs expression.Expressions,
@@ -96,6 +97,15 @@ func (this *HashNest) SetFilter(filter expression.Expression) {
 	this.filter = filter
 }
 
+// as for HashJoin
+func (this *HashNest) Partitions() int {
+	return this.partitions
+}
+
+func (this *HashNest) SetPartitions(partitions int) {
+	this.partitions = partitions
+}
+
 func (this *HashNest) SetCardinality(cardinality float64) {
 	this.cardinality = cardinality
 }
@@ -130,6 +140,10 @@ func (this *HashNest) MarshalBase(f func(map[string]interface{})) map[string]int
 		r["filter"] = expression.NewStringer().Visit(this.filter)
 	}
 
+	if this.partitions > 1 {
+		r["partitions"] = this.partitions
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -151,6 +165,7 @@ func (this *HashNest) UnmarshalJSON(body []byte) error {
 		ProbeExprs  []string               `json:"probe_exprs"`
 		BuildAlias  string                 `json:"build_alias"`
 		Filter      string                 `json:"filter"`
+		Partitions  int                    `json:"partitions"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 		Child       json.RawMessage        `json:"~child"`
 	}
@@ -196,6 +211,7 @@ func (this *HashNest) UnmarshalJSON(body []byte) error {
 		}
 	}
 
+	this.partitions = _unmarshalled.Partitions
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	raw_child := _unmarshalled.Child
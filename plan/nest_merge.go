@@ -0,0 +1,221 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/expression/parser"
+)
+
+/*
+MergeNest nests its child (the right-hand side) into its input (the
+left-hand side). Both sides must arrive ordered on their respective
+merge keys, in the same direction.
+*/
+type MergeNest struct {
+	readonly
+	optEstimate
+	outer      bool
+	onclause   expression.Expression
+	child      Operator
+	leftKey    expression.Expression
+	rightKey   expression.Expression
+	descending bool
+	rightAlias string
+	filter     expression.Expression
+}
+
+func NewMergeNest(nest *algebra.AnsiNest, child Operator, leftKey, rightKey expression.Expression,
+	descending bool, rightAlias string, filter expression.Expression, cost, cardinality float64,
+	size int64, frCost float64) *MergeNest {
+	rv := &MergeNest{
+		outer:      nest.Outer(),
+		onclause:   nest.Onclause(),
+		child:      child,
+		leftKey:    leftKey,
+		rightKey:   rightKey,
+		descending: descending,
+		rightAlias: rightAlias,
+		filter:     filter,
+	}
+	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
+	return rv
+}
+
+func (this *MergeNest) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitMergeNest(this)
+}
+
+func (this *MergeNest) New() Operator {
+	return &MergeNest{}
+}
+
+func (this *MergeNest) Outer() bool {
+	return this.outer
+}
+
+func (this *MergeNest) Onclause() expression.Expression {
+	return this.onclause
+}
+
+func (this *MergeNest) SetOnclause(onclause expression.Expression) {
+	this.onclause = onclause
+}
+
+func (this *MergeNest) Child() Operator {
+	return this.child
+}
+
+func (this *MergeNest) LeftKey() expression.Expression {
+	return this.leftKey
+}
+
+func (this *MergeNest) SetLeftKey(leftKey expression.Expression) {
+	this.leftKey = leftKey
+}
+
+func (this *MergeNest) RightKey() expression.Expression {
+	return this.rightKey
+}
+
+func (this *MergeNest) SetRightKey(rightKey expression.Expression) {
+	this.rightKey = rightKey
+}
+
+func (this *MergeNest) Descending() bool {
+	return this.descending
+}
+
+func (this *MergeNest) RightAlias() string {
+	return this.rightAlias
+}
+
+func (this *MergeNest) Filter() expression.Expression {
+	return this.filter
+}
+
+func (this *MergeNest) SetFilter(filter expression.Expression) {
+	this.filter = filter
+}
+
+func (this *MergeNest) SetCardinality(cardinality float64) {
+	this.cardinality = cardinality
+}
+
+func (this *MergeNest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *MergeNest) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "MergeNest"}
+	r["on_clause"] = expression.NewStringer().Visit(this.onclause)
+
+	if this.outer {
+		r["outer"] = this.outer
+	}
+
+	r["left_key"] = expression.NewStringer().Visit(this.leftKey)
+	r["right_key"] = expression.NewStringer().Visit(this.rightKey)
+
+	if this.descending {
+		r["descending"] = this.descending
+	}
+
+	r["right_alias"] = this.rightAlias
+
+	if this.filter != nil {
+		r["filter"] = expression.NewStringer().Visit(this.filter)
+	}
+
+	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
+		r["optimizer_estimates"] = optEstimate
+	}
+
+	if f != nil {
+		f(r)
+	} else {
+		r["~child"] = this.child
+	}
+	return r
+}
+
+func (this *MergeNest) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_           string                 `json:"#operator"`
+		Onclause    string                 `json:"on_clause"`
+		Outer       bool                   `json:"outer"`
+		LeftKey     string                 `json:"left_key"`
+		RightKey    string                 `json:"right_key"`
+		Descending  bool                   `json:"descending"`
+		RightAlias  string                 `json:"right_alias"`
+		Filter      string                 `json:"filter"`
+		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
+		Child       json.RawMessage        `json:"~child"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	if _unmarshalled.Onclause != "" {
+		this.onclause, err = parser.Parse(_unmarshalled.Onclause)
+		if err != nil {
+			return err
+		}
+	}
+
+	this.outer = _unmarshalled.Outer
+
+	this.leftKey, err = parser.Parse(_unmarshalled.LeftKey)
+	if err != nil {
+		return err
+	}
+
+	this.rightKey, err = parser.Parse(_unmarshalled.RightKey)
+	if err != nil {
+		return err
+	}
+
+	this.descending = _unmarshalled.Descending
+	this.rightAlias = _unmarshalled.RightAlias
+
+	if _unmarshalled.Filter != "" {
+		this.filter, err = parser.Parse(_unmarshalled.Filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
+
+	raw_child := _unmarshalled.Child
+	var child_type struct {
+		Op_name string `json:"#operator"`
+	}
+
+	err = json.Unmarshal(raw_child, &child_type)
+	if err != nil {
+		return err
+	}
+
+	this.child, err = MakeOperator(child_type.Op_name, raw_child)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (this *MergeNest) verify(prepared *Prepared) bool {
+	return this.child.verify(prepared)
+}
@@ -67,10 +67,12 @@ var _OPERATORS = map[string]Operator{
 	"IndexJoin":      &IndexJoin{},
 	"NestedLoopJoin": &NLJoin{},
 	"HashJoin":       &HashJoin{},
+	"MergeJoin":      &MergeJoin{},
 	"Nest":           &Nest{},
 	"IndexNest":      &IndexNest{},
 	"NestedLoopNest": &NLNest{},
 	"HashNest":       &HashNest{},
+	"MergeNest":      &MergeNest{},
 	"Unnest":         &Unnest{},
 
 	// Let + Letting
@@ -157,6 +159,13 @@ var _OPERATORS = map[string]Operator{
 	"GrantRole":  &GrantRole{},
 	"RevokeRole": &RevokeRole{},
 
+	// Users and groups
+	"CreateUser":  &CreateUser{},
+	"AlterUser":   &AlterUser{},
+	"DropUser":    &DropUser{},
+	"CreateGroup": &CreateGroup{},
+	"DropGroup":   &DropGroup{},
+
 	// Explain
 	"Explain": &Explain{},
 
@@ -168,6 +177,15 @@ var _OPERATORS = map[string]Operator{
 	"DropFunction":    &DropFunction{},
 	"ExecuteFunction": &ExecuteFunction{},
 
+	// Materialized views
+	"CreateMaterializedView":  &CreateMaterializedView{},
+	"DropMaterializedView":    &DropMaterializedView{},
+	"RefreshMaterializedView": &RefreshMaterializedView{},
+
+	// Triggers
+	"CreateTrigger": &CreateTrigger{},
+	"DropTrigger":   &DropTrigger{},
+
 	// Index Advisor
 	"AdviseIndex": &Advise{},
 	"IndexAdvice": &IndexAdvice{},
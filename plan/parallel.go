@@ -16,10 +16,16 @@ import (
 type Parallel struct {
 	child          Operator
 	maxParallelism int
+	adaptive       bool
 }
 
 func NewParallel(child Operator, maxParallelism int) *Parallel {
-	return &Parallel{child, maxParallelism}
+	return &Parallel{child, maxParallelism, false}
+}
+
+// the number of copies is further reduced at execution time according to the server load
+func NewAdaptiveParallel(child Operator, maxParallelism int) *Parallel {
+	return &Parallel{child, maxParallelism, true}
 }
 
 func (this *Parallel) Accept(visitor Visitor) (interface{}, error) {
@@ -46,6 +52,10 @@ func (this *Parallel) MaxParallelism() int {
 	return this.maxParallelism
 }
 
+func (this *Parallel) Adaptive() bool {
+	return this.adaptive
+}
+
 func (this *Parallel) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -57,6 +67,10 @@ func (this *Parallel) MarshalBase(f func(map[string]interface{})) map[string]int
 		r["maxParallelism"] = this.maxParallelism
 	}
 
+	if this.adaptive {
+		r["adaptive"] = this.adaptive
+	}
+
 	if f != nil {
 		f(r)
 	} else {
@@ -69,6 +83,7 @@ func (this *Parallel) UnmarshalJSON(body []byte) error {
 	var _unmarshalled struct {
 		_              string          `json:"#operator"`
 		MaxParallelism int             `json:"maxParallelism"`
+		Adaptive       bool            `json:"adaptive"`
 		Child          json.RawMessage `json:"~child"`
 	}
 	var child_type struct {
@@ -86,6 +101,7 @@ func (this *Parallel) UnmarshalJSON(body []byte) error {
 	}
 
 	this.maxParallelism = _unmarshalled.MaxParallelism
+	this.adaptive = _unmarshalled.Adaptive
 	this.child, err = MakeOperator(child_type.Operator, _unmarshalled.Child)
 	return err
 }
@@ -0,0 +1,127 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/expression/parser"
+)
+
+// Create trigger
+type CreateTrigger struct {
+	ddl
+	trigger      *datastore.Trigger
+	failIfExists bool
+}
+
+func NewCreateTrigger(trigger *datastore.Trigger, failIfExists bool) *CreateTrigger {
+	return &CreateTrigger{
+		trigger:      trigger,
+		failIfExists: failIfExists,
+	}
+}
+
+func (this *CreateTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateTrigger(this)
+}
+
+func (this *CreateTrigger) New() Operator {
+	return &CreateTrigger{}
+}
+
+func (this *CreateTrigger) Trigger() *datastore.Trigger {
+	return this.trigger
+}
+
+func (this *CreateTrigger) FailIfExists() bool {
+	return this.failIfExists
+}
+
+func (this *CreateTrigger) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *CreateTrigger) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "CreateTrigger"}
+	r["name"] = this.trigger.Name
+	r["keyspace"] = this.trigger.Keyspace
+	r["timing"] = this.trigger.Timing
+	r["event"] = this.trigger.Event
+	identity := make(map[string]interface{})
+	this.trigger.Function.Signature(identity)
+	r["identity"] = identity
+	args := make([]string, len(this.trigger.Args))
+	for i, arg := range this.trigger.Args {
+		args[i] = expression.NewStringer().Visit(arg)
+	}
+	r["args"] = args
+	r["fail_if_exists"] = this.failIfExists
+
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *CreateTrigger) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_            string          `json:"#operator"`
+		Name         string          `json:"name"`
+		Keyspace     string          `json:"keyspace"`
+		Timing       string          `json:"timing"`
+		Event        string          `json:"event"`
+		Identity     json.RawMessage `json:"identity"`
+		Args         []string        `json:"args"`
+		FailIfExists bool            `json:"fail_if_exists"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	this.trigger = &datastore.Trigger{
+		Name:     _unmarshalled.Name,
+		Keyspace: _unmarshalled.Keyspace,
+		Timing:   _unmarshalled.Timing,
+		Event:    _unmarshalled.Event,
+		Args:     make(expression.Expressions, len(_unmarshalled.Args)),
+	}
+	this.trigger.Function, err = makeName(_unmarshalled.Identity)
+	if err != nil {
+		return err
+	}
+	for i, arg := range _unmarshalled.Args {
+		this.trigger.Args[i], err = parser.Parse(arg)
+		if err != nil {
+			return err
+		}
+	}
+	this.failIfExists = _unmarshalled.FailIfExists
+	return nil
+}
+
+/*
+Triggers are stored as the plans that create them.
+*/
+func EncodeTrigger(trigger *datastore.Trigger) ([]byte, error) {
+	return json.Marshal(NewCreateTrigger(trigger, false))
+}
+
+func DecodeTrigger(bytes []byte) (*datastore.Trigger, error) {
+	var rv CreateTrigger
+	err := rv.UnmarshalJSON(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return rv.trigger, nil
+}
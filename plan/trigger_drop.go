@@ -0,0 +1,87 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plan
+
+import (
+	"encoding/json"
+)
+
+// Drop trigger
+type DropTrigger struct {
+	ddl
+	name            string
+	keyspace        string
+	failIfNotExists bool
+}
+
+func NewDropTrigger(name, keyspace string, failIfNotExists bool) *DropTrigger {
+	return &DropTrigger{
+		name:            name,
+		keyspace:        keyspace,
+		failIfNotExists: failIfNotExists,
+	}
+}
+
+func (this *DropTrigger) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitDropTrigger(this)
+}
+
+func (this *DropTrigger) New() Operator {
+	return &DropTrigger{}
+}
+
+func (this *DropTrigger) Name() string {
+	return this.name
+}
+
+/*
+The qualified name of the keyspace the trigger is defined on.
+*/
+func (this *DropTrigger) Keyspace() string {
+	return this.keyspace
+}
+
+func (this *DropTrigger) FailIfNotExists() bool {
+	return this.failIfNotExists
+}
+
+func (this *DropTrigger) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *DropTrigger) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "DropTrigger"}
+	r["name"] = this.name
+	r["keyspace"] = this.keyspace
+	r["fail_if_not_exists"] = this.failIfNotExists
+
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *DropTrigger) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_               string `json:"#operator"`
+		Name            string `json:"name"`
+		Keyspace        string `json:"keyspace"`
+		FailIfNotExists bool   `json:"fail_if_not_exists"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	this.name = _unmarshalled.Name
+	this.keyspace = _unmarshalled.Keyspace
+	this.failIfNotExists = _unmarshalled.FailIfNotExists
+	return nil
+}
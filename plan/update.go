@@ -217,15 +217,20 @@ type SendUpdate struct {
 	term     *algebra.KeyspaceRef
 	alias    string
 	limit    expression.Expression
+	validate bool
+	images   bool
 }
 
 func NewSendUpdate(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef,
-	limit expression.Expression, cost, cardinality float64, size int64, frCost float64) *SendUpdate {
+	limit expression.Expression, validate, images bool, cost, cardinality float64, size int64,
+	frCost float64) *SendUpdate {
 	rv := &SendUpdate{
 		keyspace: keyspace,
 		term:     ksref,
 		alias:    ksref.Alias(),
 		limit:    limit,
+		validate: validate,
+		images:   images,
 	}
 	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
 	return rv
@@ -255,6 +260,14 @@ func (this *SendUpdate) Limit() expression.Expression {
 	return this.limit
 }
 
+func (this *SendUpdate) Validate() bool {
+	return this.validate
+}
+
+func (this *SendUpdate) Images() bool {
+	return this.images
+}
+
 func (this *SendUpdate) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -268,6 +281,14 @@ func (this *SendUpdate) MarshalBase(f func(map[string]interface{})) map[string]i
 		r["limit"] = this.limit
 	}
 
+	if this.validate {
+		r["validate"] = this.validate
+	}
+
+	if this.images {
+		r["images"] = this.images
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -289,6 +310,8 @@ func (this *SendUpdate) UnmarshalJSON(body []byte) error {
 		As          string                 `json:"as"`
 		Alias       string                 `json:"alias"`
 		Limit       string                 `json:"limit"`
+		Validate    bool                   `json:"validate"`
+		Images      bool                   `json:"images"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 	}
 
@@ -306,6 +329,9 @@ func (this *SendUpdate) UnmarshalJSON(body []byte) error {
 		}
 	}
 
+	this.validate = _unmarshalled.Validate
+	this.images = _unmarshalled.Images
+
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	if _unmarshalled.Expr != "" {
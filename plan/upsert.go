@@ -26,10 +26,11 @@ type SendUpsert struct {
 	key      expression.Expression
 	value    expression.Expression
 	options  expression.Expression
+	validate bool
 }
 
 func NewSendUpsert(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef,
-	key, value, options expression.Expression, cost, cardinality float64,
+	key, value, options expression.Expression, validate bool, cost, cardinality float64,
 	size int64, frCost float64) *SendUpsert {
 	rv := &SendUpsert{
 		keyspace: keyspace,
@@ -38,6 +39,7 @@ func NewSendUpsert(keyspace datastore.Keyspace, ksref *algebra.KeyspaceRef,
 		key:      key,
 		value:    value,
 		options:  options,
+		validate: validate,
 	}
 	setOptEstimate(&rv.optEstimate, cost, cardinality, size, frCost)
 	return rv
@@ -75,6 +77,10 @@ func (this *SendUpsert) Options() expression.Expression {
 	return this.options
 }
 
+func (this *SendUpsert) Validate() bool {
+	return this.validate
+}
+
 func (this *SendUpsert) MarshalJSON() ([]byte, error) {
 	return json.Marshal(this.MarshalBase(nil))
 }
@@ -96,6 +102,10 @@ func (this *SendUpsert) MarshalBase(f func(map[string]interface{})) map[string]i
 		r["options"] = this.options.String()
 	}
 
+	if this.validate {
+		r["validate"] = this.validate
+	}
+
 	if optEstimate := marshalOptEstimate(&this.optEstimate); optEstimate != nil {
 		r["optimizer_estimates"] = optEstimate
 	}
@@ -119,6 +129,7 @@ func (this *SendUpsert) UnmarshalJSON(body []byte) error {
 		Expr        string                 `json:"expr"`
 		As          string                 `json:"as"`
 		Alias       string                 `json:"alias"`
+		Validate    bool                   `json:"validate"`
 		OptEstimate map[string]interface{} `json:"optimizer_estimates"`
 	}
 
@@ -150,6 +161,8 @@ func (this *SendUpsert) UnmarshalJSON(body []byte) error {
 
 	this.alias = _unmarshalled.Alias
 
+	this.validate = _unmarshalled.Validate
+
 	unmarshalOptEstimate(&this.optEstimate, _unmarshalled.OptEstimate)
 
 	if _unmarshalled.Expr != "" {
@@ -0,0 +1,76 @@
+// Copyright 2021-Present Couchbase, Inc.
+//
+// Use of this software is governed by the Business Source License included
+// in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+// in that file, in accordance with the Business Source License, use of this
+// software will be governed by the Apache License, Version 2.0, included in
+// the file licenses/APL2.txt.
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/value"
+)
+
+// Alter user
+type AlterUser struct {
+	ddl
+	node *algebra.AlterUser
+}
+
+func NewAlterUser(node *algebra.AlterUser) *AlterUser {
+	return &AlterUser{
+		node: node,
+	}
+}
+
+func (this *AlterUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitAlterUser(this)
+}
+
+func (this *AlterUser) New() Operator {
+	return &AlterUser{}
+}
+
+func (this *AlterUser) Node() *algebra.AlterUser {
+	return this.node
+}
+
+func (this *AlterUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+func (this *AlterUser) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "AlterUser"}
+	r["user"] = this.node.User()
+	if this.node.With() != nil {
+		r["with"] = this.node.With()
+	}
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *AlterUser) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_    string          `json:"#operator"`
+		User string          `json:"user"`
+		With json.RawMessage `json:"with"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	var with value.Value
+	if len(_unmarshalled.With) > 0 {
+		with = value.NewValue([]byte(_unmarshalled.With))
+	}
+
+	this.node = algebra.NewAlterUser(_unmarshalled.User, "", with, "")
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2021-Present Couchbase, Inc.
+//
+// Use of this software is governed by the Business Source License included
+// in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+// in that file, in accordance with the Business Source License, use of this
+// software will be governed by the Apache License, Version 2.0, included in
+// the file licenses/APL2.txt.
+package plan
+
+import (
+	"encoding/json"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/value"
+)
+
+// Create user
+type CreateUser struct {
+	ddl
+	node *algebra.CreateUser
+}
+
+func NewCreateUser(node *algebra.CreateUser) *CreateUser {
+	return &CreateUser{
+		node: node,
+	}
+}
+
+func (this *CreateUser) Accept(visitor Visitor) (interface{}, error) {
+	return visitor.VisitCreateUser(this)
+}
+
+func (this *CreateUser) New() Operator {
+	return &CreateUser{}
+}
+
+func (this *CreateUser) Node() *algebra.CreateUser {
+	return this.node
+}
+
+func (this *CreateUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.MarshalBase(nil))
+}
+
+/*
+The password is never marshalled: statements holding one cannot be
+prepared, so their plans are not shared.
+*/
+func (this *CreateUser) MarshalBase(f func(map[string]interface{})) map[string]interface{} {
+	r := map[string]interface{}{"#operator": "CreateUser"}
+	r["user"] = this.node.User()
+	if this.node.With() != nil {
+		r["with"] = this.node.With()
+	}
+	if f != nil {
+		f(r)
+	}
+	return r
+}
+
+func (this *CreateUser) UnmarshalJSON(body []byte) error {
+	var _unmarshalled struct {
+		_    string          `json:"#operator"`
+		User string          `json:"user"`
+		With json.RawMessage `json:"with"`
+	}
+
+	err := json.Unmarshal(body, &_unmarshalled)
+	if err != nil {
+		return err
+	}
+
+	var with value.Value
+	if len(_unmarshalled.With) > 0 {
+		with = value.NewValue([]byte(_unmarshalled.With))
+	}
+
+	this.node = algebra.NewCreateUser(_unmarshalled.User, "", with, "")
+	return nil
+}
@@ -46,6 +46,8 @@ type Visitor interface {
 	VisitNLNest(op *NLNest) (interface{}, error)
 	VisitHashJoin(op *HashJoin) (interface{}, error)
 	VisitHashNest(op *HashNest) (interface{}, error)
+	VisitMergeJoin(op *MergeJoin) (interface{}, error)
+	VisitMergeNest(op *MergeNest) (interface{}, error)
 
 	// Let + Letting, With
 	VisitLet(op *Let) (interface{}, error)
@@ -133,6 +135,13 @@ type Visitor interface {
 	VisitGrantRole(op *GrantRole) (interface{}, error)
 	VisitRevokeRole(op *RevokeRole) (interface{}, error)
 
+	// Users and groups
+	VisitCreateUser(op *CreateUser) (interface{}, error)
+	VisitAlterUser(op *AlterUser) (interface{}, error)
+	VisitDropUser(op *DropUser) (interface{}, error)
+	VisitCreateGroup(op *CreateGroup) (interface{}, error)
+	VisitDropGroup(op *DropGroup) (interface{}, error)
+
 	// Explain
 	VisitExplain(op *Explain) (interface{}, error)
 
@@ -148,6 +157,15 @@ type Visitor interface {
 	VisitDropFunction(op *DropFunction) (interface{}, error)
 	VisitExecuteFunction(op *ExecuteFunction) (interface{}, error)
 
+	// Materialized views
+	VisitCreateMaterializedView(op *CreateMaterializedView) (interface{}, error)
+	VisitDropMaterializedView(op *DropMaterializedView) (interface{}, error)
+	VisitRefreshMaterializedView(op *RefreshMaterializedView) (interface{}, error)
+
+	// Triggers
+	VisitCreateTrigger(op *CreateTrigger) (interface{}, error)
+	VisitDropTrigger(op *DropTrigger) (interface{}, error)
+
 	// Index Advisor
 	VisitIndexAdvice(op *IndexAdvice) (interface{}, error)
 	VisitAdvise(op *Advise) (interface{}, error)
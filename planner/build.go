@@ -22,21 +22,22 @@ func Build(stmt algebra.Statement, datastore, systemstore datastore.Datastore,
 	namespace string, subquery, stream bool, context *PrepareContext) (
 	plan.Operator, map[string]bool, error) {
 
-	builder := newBuilder(datastore, systemstore, namespace, subquery, context)
-	if context.UseCBO() && context.Optimizer() != nil {
-		builder.useCBO = true
-		checkCostModel(context.FeatureControls())
-	}
+	var op plan.Operator
+	var indexKeyspaces map[string]bool
 
-	o, err := stmt.Accept(builder)
+	if !subquery {
+		_, op, indexKeyspaces = baselinePlan(stmt, context.BaselineText(), namespace, context)
+	}
+	if op == nil {
+		var err error
 
-	if err != nil {
-		return nil, nil, err
+		op, indexKeyspaces, err = build(stmt, datastore, systemstore, namespace, subquery, context)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	op := o.(plan.Operator)
-	_, is_prepared := o.(*plan.Prepared)
-	indexKeyspaces := builder.indexKeyspaceNames
+	_, is_prepared := op.(*plan.Prepared)
 
 	if !subquery && !is_prepared {
 		privs, er := stmt.Privileges()
@@ -65,6 +66,32 @@ func Build(stmt algebra.Statement, datastore, systemstore datastore.Datastore,
 	}
 }
 
+/*
+Build the plan the optimizer chooses for a statement, regardless of
+any plan baseline, and without the operators that wrap the plan of a
+request. This is the plan captured by a baseline.
+*/
+func BuildStatement(stmt algebra.Statement, datastore, systemstore datastore.Datastore,
+	namespace string, context *PrepareContext) (plan.Operator, map[string]bool, error) {
+	return build(stmt, datastore, systemstore, namespace, false, context)
+}
+
+func build(stmt algebra.Statement, datastore, systemstore datastore.Datastore,
+	namespace string, subquery bool, context *PrepareContext) (plan.Operator, map[string]bool, error) {
+
+	builder := newBuilder(datastore, systemstore, namespace, subquery, context)
+	if context.UseCBO() && context.Optimizer() != nil {
+		builder.useCBO = true
+		checkCostModel(context.FeatureControls())
+	}
+
+	o, err := stmt.Accept(builder)
+	if err != nil {
+		return nil, nil, err
+	}
+	return o.(plan.Operator), builder.indexKeyspaceNames, nil
+}
+
 var _MAP_KEYSPACE_CAP = 4
 
 const (
@@ -85,6 +112,7 @@ const (
 	BUILDER_JOIN_ENUM
 	BUILDER_CHK_INDEX_ORDER
 	BUILDER_PLAN_HAS_ORDER
+	BUILDER_VIEW_REWRITE // planning a query rewritten to read a materialized view
 )
 
 type builder struct {
@@ -97,6 +125,7 @@ type builder struct {
 	subquery           bool
 	correlated         bool
 	maxParallelism     int
+	parallelHint       int                   // PARALLEL hint of the query block, 0 if none
 	delayProjection    bool                  // Used to allow ORDER BY non-projected expressions
 	from               algebra.FromTerm      // Used for index selection
 	where              expression.Expression // Used for index selection
@@ -133,6 +162,7 @@ func (this *builder) Copy() *builder {
 		subquery:           this.subquery,
 		correlated:         this.correlated,
 		maxParallelism:     this.maxParallelism,
+		parallelHint:       this.parallelHint,
 		delayProjection:    this.delayProjection,
 		from:               this.from,
 		where:              expression.Copy(this.where),
@@ -358,11 +388,94 @@ func (this *builder) addChildren(ops ...plan.Operator) {
 }
 
 func (this *builder) addParallel(subChildren ...plan.Operator) *plan.Parallel {
-	return plan.NewParallel(plan.NewSequence(subChildren...), this.maxParallelism)
+	return this.newParallel(subChildren)
 }
 
 func (this *builder) addSubchildrenParallel() *plan.Parallel {
-	parallel := plan.NewParallel(plan.NewSequence(this.subChildren...), this.maxParallelism)
+	parallel := this.newParallel(this.subChildren)
 	this.subChildren = make([]plan.Operator, 0, 16)
 	return parallel
 }
+
+// operators whose copies share partitioned state, which are run with the
+// degree of parallelism chosen for them, adapted to the server load
+type partitionedOperator interface {
+	plan.Operator
+	Partitions() int
+	SetPartitions(partitions int)
+}
+
+func (this *builder) newParallel(subChildren []plan.Operator) *plan.Parallel {
+	partitions := 0
+	for _, op := range subChildren {
+		if op, ok := op.(partitionedOperator); ok && op.Partitions() > partitions {
+			partitions = op.Partitions()
+		}
+	}
+	if partitions > 1 {
+		return plan.NewAdaptiveParallel(plan.NewSequence(subChildren...), partitions)
+	}
+	return plan.NewParallel(plan.NewSequence(subChildren...), this.parallelism())
+}
+
+/*
+The maximum parallelism of the query block, capped by its PARALLEL hint.
+0 leaves it to the server.
+*/
+func (this *builder) parallelism() int {
+	if this.parallelHint > 0 && (this.maxParallelism <= 0 || this.maxParallelism > this.parallelHint) {
+		return this.parallelHint
+	}
+	return this.maxParallelism
+}
+
+// one copy for every so many estimated input rows
+const _ROWS_PER_COPY = 10000.0
+
+/*
+The degree of parallelism for an operator, from the estimated number of
+rows it has to process, capped by the maximum parallelism of the statement.
+*/
+func (this *builder) adaptiveParallelism(cardinality float64) int {
+	maxParallelism := this.parallelism()
+	if !this.useCBO || cardinality <= 0.0 || maxParallelism == 1 {
+		return 1
+	}
+	if maxParallelism <= 0 {
+		maxParallelism = plan.GetMaxParallelism()
+	}
+	dop := int(cardinality / _ROWS_PER_COPY)
+	if dop > maxParallelism {
+		dop = maxParallelism
+	}
+	if dop < 1 {
+		dop = 1
+	}
+	return dop
+}
+
+/*
+A hash join or nest that is worth running in parallel joins the parallel
+section of the probe side, with as many partitions in its hash table;
+otherwise it runs on its own, after the probe side.
+*/
+func (this *builder) addHashOp(op partitionedOperator, build plan.Operator) {
+	dop := 1
+	if this.lastOp != nil && build != nil {
+		cardinality := this.lastOp.Cardinality()
+		if build.Cardinality() > cardinality {
+			cardinality = build.Cardinality()
+		}
+		dop = this.adaptiveParallelism(cardinality)
+	}
+	if dop > 1 {
+		op.SetPartitions(dop)
+		this.addSubChildren(op)
+		return
+	}
+
+	if len(this.subChildren) > 0 {
+		this.addChildren(this.addSubchildrenParallel())
+	}
+	this.addChildren(op)
+}
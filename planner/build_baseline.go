@@ -0,0 +1,51 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/plan"
+)
+
+/*
+Only queries and DML can have their plan pinned.
+*/
+func BaselineStatement(stmtType string) bool {
+	switch stmtType {
+	case "SELECT", "INSERT", "UPSERT", "UPDATE", "DELETE", "MERGE":
+		return true
+	}
+	return false
+}
+
+/*
+Return the baseline matching the statement, if any, and its plan if
+the baseline is accepted and the plan is still valid. Plans within
+transactions depend on the keyspaces mutated so far, and are never
+pinned.
+*/
+func baselinePlan(stmt algebra.Statement, text, namespace string, context *PrepareContext) (
+	*plan.Baseline, plan.Operator, map[string]bool) {
+
+	if text == "" || !BaselineStatement(stmt.Type()) || len(context.DeltaKeyspaces()) > 0 {
+		return nil, nil, nil
+	}
+	baseline, ok := plan.GetBaselineStore().Match(text, context.QueryContext())
+	if !ok || baseline.Namespace() != namespace {
+		return nil, nil, nil
+	}
+	if !baseline.Accepted() {
+		return baseline, nil, nil
+	}
+	op, ok := baseline.Operator()
+	if !ok {
+		return baseline, nil, nil
+	}
+	return baseline, op, baseline.IndexScanKeyspaces()
+}
@@ -18,6 +18,7 @@ func (this *builder) VisitDelete(stmt *algebra.Delete) (interface{}, error) {
 	this.cover = stmt
 	this.node = stmt
 	this.where = stmt.Where()
+	this.dmlOptimHints(stmt.OptimHints())
 
 	this.initialIndexAdvisor(stmt)
 
@@ -52,7 +53,8 @@ func (this *builder) VisitDelete(stmt *algebra.Delete) (interface{}, error) {
 		}
 	}
 
-	deleteSubChildren = append(deleteSubChildren, plan.NewSendDelete(keyspace, ksref, stmt.Limit(), cost, cardinality, size, frCost))
+	deleteSubChildren = append(deleteSubChildren, plan.NewSendDelete(keyspace, ksref, stmt.Limit(),
+		stmt.ReturningImages(), cost, cardinality, size, frCost))
 
 	if stmt.Returning() != nil {
 		deleteSubChildren = this.buildDMLProject(stmt.Returning(), deleteSubChildren)
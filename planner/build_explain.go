@@ -14,10 +14,23 @@ import (
 )
 
 func (this *builder) VisitExplain(stmt *algebra.Explain) (interface{}, error) {
-	op, err := stmt.Statement().Accept(this)
-	if err != nil {
-		return nil, err
+	baseline, op, ik := baselinePlan(stmt.Statement(), stmt.Text(), this.namespace, this.context)
+	pinned := op != nil
+	if pinned {
+		for ks, v := range ik {
+			this.indexKeyspaceNames[ks] = v
+		}
+	} else {
+		o, err := stmt.Statement().Accept(this)
+		if err != nil {
+			return nil, err
+		}
+		op = o.(plan.Operator)
 	}
 
-	return plan.NewExplain(op.(plan.Operator), stmt.Text(), stmt.Statement().OptimHints()), nil
+	rv := plan.NewExplain(op, stmt.Text(), stmt.Statement().OptimHints())
+	if baseline != nil {
+		rv.SetBaseline(baseline, pinned)
+	}
+	return rv, nil
 }
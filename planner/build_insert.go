@@ -61,7 +61,7 @@ func (this *builder) VisitInsert(stmt *algebra.Insert) (interface{}, error) {
 	}
 
 	insert := plan.NewSendInsert(keyspace, ksref, stmt.Key(), stmt.Value(), stmt.Options(),
-		nil, cost, cardinality, size, frCost)
+		nil, stmt.Validate(), false, cost, cardinality, size, frCost)
 	subChildren := make([]plan.Operator, 0, 4)
 	subChildren = append(subChildren, insert)
 
@@ -100,13 +100,12 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 		nlCost := OPT_COST_NOT_AVAIL
 
 		// When optimizer hints are specified, in case of CBO when we consider
-		// both hash join and nested-loop join, if index hint error occurs we
-		// remember the index hint error here and reset the flag on baseKeyspace,
-		// since both hash join and nested-loop join build the scan on the inner
-		// side. After we've chosen either hash join or nested-loop join, we then
-		// re-set the necessary hint error flag on baseKeyspace.
-		hjIndexHintError := false
-		nlIndexHintError := false
+		// both hash join and nested-loop join, if index (or index combine) hint
+		// errors occur we remember the hint errors here and reset the flags on
+		// baseKeyspace, since both hash join and nested-loop join build the scan
+		// on the inner side. After we've chosen either hash join or nested-loop
+		// join, we then re-set the necessary hint error flags on baseKeyspace.
+		var hjHintErrors, nlHintErrors uint32
 
 		useFr := false
 		if useCBO && this.hasBuilderFlag(BUILDER_HAS_LIMIT) &&
@@ -114,6 +113,23 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 			useFr = true
 		}
 
+		// merge join is considered first, when USE MERGE hint is specified or when
+		// no sorting is needed (no other join hint specified)
+		if !this.joinEnum() && !right.PreferHash() && !right.PreferNL() {
+			mjoin, err := this.buildMergeJoin(node, filter, selec)
+			if err != nil {
+				return nil, err
+			}
+			if mjoin != nil {
+				return mjoin, nil
+			}
+			this.restoreJoinPlannerState(jps)
+			node.SetOnclause(origOnclause)
+			if right.PreferMerge() {
+				baseKeyspace.SetJoinHintError()
+			}
+		}
+
 		if util.IsFeatureEnabled(this.context.FeatureControls(), util.N1QL_HASH_JOIN) {
 			tryHash := false
 			if useCBO {
@@ -121,8 +137,8 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 				if !this.joinEnum() {
 					tryHash = true
 				}
-			} else if right.PreferHash() {
-				// only consider hash join when USE HASH hint is specified
+			} else if right.PreferHash() || right.AvoidNL() {
+				// only consider hash join when USE HASH or NO_USE_NL hint is specified
 				tryHash = true
 			}
 			if tryHash {
@@ -133,7 +149,7 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 					return nil, err
 				}
 				if hjoin != nil {
-					if useCBO && !right.PreferHash() {
+					if useCBO && !right.PreferHash() && !right.AvoidNL() {
 						if useFr {
 							hjCost = hjoin.FrCost()
 						} else {
@@ -141,10 +157,8 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 						}
 						hjps = this.saveJoinPlannerState()
 						hjOnclause = node.Onclause()
-						if baseKeyspace.HasIndexHintError() {
-							hjIndexHintError = true
-							baseKeyspace.UnsetIndexHintError()
-						}
+						hjHintErrors = baseKeyspace.ScanHintErrors()
+						baseKeyspace.SetScanHintErrors(0)
 					} else {
 						if !this.joinEnum() && !buildRight {
 							this.resetOrder()
@@ -170,10 +184,8 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 			return nil, err
 		}
 
-		if baseKeyspace.HasIndexHintError() {
-			nlIndexHintError = true
-			baseKeyspace.UnsetIndexHintError()
-		}
+		nlHintErrors = baseKeyspace.ScanHintErrors()
+		baseKeyspace.SetScanHintErrors(0)
 
 		if len(scans) > 0 {
 			if useCBO && !right.PreferNL() {
@@ -186,9 +198,7 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 					this.restoreJoinPlannerState(hjps)
 					node.SetOnclause(hjOnclause)
 					right.UnsetUnderNL()
-					if hjIndexHintError {
-						baseKeyspace.SetIndexHintError()
-					}
+					baseKeyspace.SetScanHintErrors(hjHintErrors)
 					if !this.joinEnum() && !buildRight {
 						this.resetOrder()
 					}
@@ -196,7 +206,7 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 				}
 			}
 
-			if right.PreferHash() && !this.joinEnum() {
+			if (right.PreferHash() || right.AvoidNL()) && !this.joinEnum() {
 				baseKeyspace.SetJoinHintError()
 			}
 			if newOnclause != nil {
@@ -211,9 +221,7 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 			if this.joinEnum() {
 				right.UnsetUnderNL()
 			}
-			if nlIndexHintError {
-				baseKeyspace.SetIndexHintError()
-			}
+			baseKeyspace.SetScanHintErrors(nlHintErrors)
 			return plan.NewNLJoin(node, plan.NewSequence(scans...), newFilter, cost, cardinality, size, frCost), nil
 		} else if hjCost > 0.0 {
 			this.restoreJoinPlannerState(hjps)
@@ -222,9 +230,7 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 				baseKeyspace.SetJoinHintError()
 			}
 			right.UnsetUnderNL()
-			if hjIndexHintError {
-				baseKeyspace.SetIndexHintError()
-			}
+			baseKeyspace.SetScanHintErrors(hjHintErrors)
 			if !this.joinEnum() && !buildRight {
 				this.resetOrder()
 			}
@@ -277,8 +283,9 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 			cost, cardinality, size, frCost = getLookupJoinCost(this.lastOp, node.Outer(),
 				newKeyspaceTerm, rightKeyspace)
 		}
-		if nlIndexHintError {
-			baseKeyspace.SetIndexHintError()
+		baseKeyspace.SetScanHintErrors(nlHintErrors)
+		if right.AvoidNL() && !this.joinEnum() {
+			baseKeyspace.SetJoinHintError()
 		}
 		return plan.NewJoinFromAnsi(keyspace, newKeyspaceTerm, node.Outer(), onFilter, cost, cardinality, size, frCost), nil
 	case *algebra.ExpressionTerm, *algebra.SubqueryTerm:
@@ -308,6 +315,12 @@ func (this *builder) buildAnsiJoinOp(node *algebra.AnsiJoin) (op plan.Operator,
 			return nil, err
 		}
 
+		if right.AvoidNL() && !this.joinEnum() {
+			if baseKeyspace, ok := this.baseKeyspaces[right.Alias()]; ok {
+				baseKeyspace.SetJoinHintError()
+			}
+		}
+
 		if newOnclause != nil {
 			node.SetOnclause(newOnclause)
 		}
@@ -361,13 +374,29 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 		hnCost := float64(OPT_COST_NOT_AVAIL)
 
 		// When optimizer hints are specified, in case of CBO when we consider
-		// both hash nest and nested-loop nest, if index hint error occurs we
-		// remember the index hint error here and reset the flag on baseKeyspace,
-		// since both hash nest and nested-loop nest build the scan on the inner
-		// side. After we've chosen either hash nest or nested-loop nest, we then
-		// re-set the necessary hint error flag on baseKeyspace.
-		hjIndexHintError := false
-		nlIndexHintError := false
+		// both hash nest and nested-loop nest, if index (or index combine) hint
+		// errors occur we remember the hint errors here and reset the flags on
+		// baseKeyspace, since both hash nest and nested-loop nest build the scan
+		// on the inner side. After we've chosen either hash nest or nested-loop
+		// nest, we then re-set the necessary hint error flags on baseKeyspace.
+		var hjHintErrors, nlHintErrors uint32
+
+		// merge nest is considered first, when USE MERGE hint is specified or when
+		// no sorting is needed (no other join hint specified)
+		if !this.joinEnum() && !right.PreferHash() && !right.PreferNL() {
+			mnest, err := this.buildMergeNest(node, filter, selec)
+			if err != nil {
+				return nil, err
+			}
+			if mnest != nil {
+				return mnest, nil
+			}
+			this.restoreJoinPlannerState(jps)
+			node.SetOnclause(origOnclause)
+			if right.PreferMerge() {
+				baseKeyspace.SetJoinHintError()
+			}
+		}
 
 		if util.IsFeatureEnabled(this.context.FeatureControls(), util.N1QL_HASH_JOIN) {
 			tryHash := false
@@ -376,8 +405,8 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 				if !this.joinEnum() {
 					tryHash = true
 				}
-			} else if right.PreferHash() {
-				// only consider hash nest when USE HASH hint is specified
+			} else if right.PreferHash() || right.AvoidNL() {
+				// only consider hash nest when USE HASH or NO_USE_NL hint is specified
 				tryHash = true
 			}
 			if tryHash {
@@ -388,14 +417,12 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 					return nil, err
 				}
 				if hnest != nil {
-					if useCBO && !right.PreferHash() {
+					if useCBO && !right.PreferHash() && !right.AvoidNL() {
 						hnCost = hnest.Cost()
 						hjps = this.saveJoinPlannerState()
 						hnOnclause = node.Onclause()
-						if baseKeyspace.HasIndexHintError() {
-							hjIndexHintError = true
-							baseKeyspace.UnsetIndexHintError()
-						}
+						hjHintErrors = baseKeyspace.ScanHintErrors()
+						baseKeyspace.SetScanHintErrors(0)
 					} else {
 						if !this.joinEnum() && !buildRight {
 							this.resetOrder()
@@ -421,26 +448,22 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 			return nil, err
 		}
 
-		if baseKeyspace.HasIndexHintError() {
-			nlIndexHintError = true
-			baseKeyspace.UnsetIndexHintError()
-		}
+		nlHintErrors = baseKeyspace.ScanHintErrors()
+		baseKeyspace.SetScanHintErrors(0)
 
 		if len(scans) > 0 {
 			if useCBO && !right.PreferNL() && (hnCost > 0.0) && (cost > hnCost) {
 				this.restoreJoinPlannerState(hjps)
 				node.SetOnclause(hnOnclause)
 				right.UnsetUnderNL()
-				if hjIndexHintError {
-					baseKeyspace.SetIndexHintError()
-				}
+				baseKeyspace.SetScanHintErrors(hjHintErrors)
 				if !this.joinEnum() && !buildRight {
 					this.resetOrder()
 				}
 				return hnest, nil
 			}
 
-			if right.PreferHash() && !this.joinEnum() {
+			if (right.PreferHash() || right.AvoidNL()) && !this.joinEnum() {
 				baseKeyspace.SetJoinHintError()
 			}
 			if newOnclause != nil {
@@ -455,9 +478,7 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 			if this.joinEnum() {
 				right.UnsetUnderNL()
 			}
-			if nlIndexHintError {
-				baseKeyspace.SetIndexHintError()
-			}
+			baseKeyspace.SetScanHintErrors(nlHintErrors)
 			return plan.NewNLNest(node, plan.NewSequence(scans...), newFilter, cost, cardinality, size, frCost), nil
 		} else if hnCost > 0.0 {
 			this.restoreJoinPlannerState(hjps)
@@ -466,9 +487,7 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 				baseKeyspace.SetJoinHintError()
 			}
 			right.UnsetUnderNL()
-			if hjIndexHintError {
-				baseKeyspace.SetIndexHintError()
-			}
+			baseKeyspace.SetScanHintErrors(hjHintErrors)
 			if !this.joinEnum() && !buildRight {
 				this.resetOrder()
 			}
@@ -521,8 +540,9 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 			cost, cardinality, size, frCost = getLookupNestCost(this.lastOp, node.Outer(),
 				newKeyspaceTerm, rightKeyspace)
 		}
-		if nlIndexHintError {
-			baseKeyspace.SetIndexHintError()
+		baseKeyspace.SetScanHintErrors(nlHintErrors)
+		if right.AvoidNL() && !this.joinEnum() {
+			baseKeyspace.SetJoinHintError()
 		}
 		return plan.NewNestFromAnsi(keyspace, newKeyspaceTerm, node.Outer(), onFilter, cost, cardinality, size, frCost), nil
 	case *algebra.ExpressionTerm, *algebra.SubqueryTerm:
@@ -547,6 +567,12 @@ func (this *builder) buildAnsiNestOp(node *algebra.AnsiNest) (op plan.Operator,
 			return nil, err
 		}
 
+		if right.AvoidNL() && !this.joinEnum() {
+			if baseKeyspace, ok := this.baseKeyspaces[right.Alias()]; ok {
+				baseKeyspace.SetJoinHintError()
+			}
+		}
+
 		if newOnclause != nil {
 			node.SetOnclause(newOnclause)
 		}
@@ -837,6 +863,11 @@ func (this *builder) buildHashJoinOp(right algebra.SimpleFromTerm, outer bool,
 		right = ksterm
 	}
 
+	// NO_USE_HASH hint
+	if right.AvoidHash() {
+		return nil, nil, nil, nil, nil, nil, false, OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, nil
+	}
+
 	switch right := right.(type) {
 	case *algebra.KeyspaceTerm:
 		// if USE HASH and USE KEYS are specified together, make sure the document key
@@ -1040,72 +1071,16 @@ func (this *builder) buildHashJoinOp(right algebra.SimpleFromTerm, outer bool,
 			newOnclause = onclause.Copy()
 		}
 
-		for _, op := range this.coveringScans {
-			coverer := expression.NewCoverer(op.Covers(), op.FilterCovers())
-			if arrayKey := op.ImplicitArrayKey(); arrayKey != nil {
-				newFilter, newOnclause, _, err =
-					this.renameAnyExpression(arrayKey, newFilter, newOnclause, nil)
-				if err != nil {
-					return nil, nil, nil, nil, nil, nil, false,
-						OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-				}
-				anyRenamer := expression.NewAnyRenamer(arrayKey)
-				for i, _ := range rightExprs {
-					rightExprs[i], err = anyRenamer.Map(rightExprs[i])
-					if err != nil {
-						return nil, nil, nil, nil, nil, nil, false,
-							OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-					}
-				}
-			}
-
-			newFilter, newOnclause, _, err = this.coverExpression(coverer, newFilter, newOnclause, nil)
-			if err != nil {
-				return nil, nil, nil, nil, nil, nil, false,
-					OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-			}
-
-			for i, _ := range rightExprs {
-				rightExprs[i], err = coverer.Map(rightExprs[i])
-				if err != nil {
-					return nil, nil, nil, nil, nil, nil, false,
-						OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-				}
-			}
+		newFilter, newOnclause, err = this.coverJoinExprs(this.coveringScans, rightExprs, newFilter, newOnclause)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, false,
+				OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
 		}
 
-		for _, op := range coveringScans {
-			coverer := expression.NewCoverer(op.Covers(), op.FilterCovers())
-			if arrayKey := op.ImplicitArrayKey(); arrayKey != nil {
-				newFilter, newOnclause, _, err =
-					this.renameAnyExpression(arrayKey, newFilter, newOnclause, nil)
-				if err != nil {
-					return nil, nil, nil, nil, nil, nil, false,
-						OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-				}
-				anyRenamer := expression.NewAnyRenamer(arrayKey)
-				for i, _ := range leftExprs {
-					leftExprs[i], err = anyRenamer.Map(leftExprs[i])
-					if err != nil {
-						return nil, nil, nil, nil, nil, nil, false,
-							OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-					}
-				}
-			}
-
-			newFilter, newOnclause, _, err = this.coverExpression(coverer, newFilter, newOnclause, nil)
-			if err != nil {
-				return nil, nil, nil, nil, nil, nil, false,
-					OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-			}
-
-			for i, _ := range leftExprs {
-				leftExprs[i], err = coverer.Map(leftExprs[i])
-				if err != nil {
-					return nil, nil, nil, nil, nil, nil, false,
-						OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
-				}
-			}
+		newFilter, newOnclause, err = this.coverJoinExprs(coveringScans, leftExprs, newFilter, newOnclause)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, false,
+				OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
 		}
 	}
 
@@ -1149,6 +1124,310 @@ func (this *builder) buildHashJoinOp(right algebra.SimpleFromTerm, outer bool,
 	return child, buildExprs, probeExprs, buildAliases, newOnclause, newFilter, buildRight, cost, cardinality, size, frCost, nil
 }
 
+/*
+Merge join (and nest) is used when the left-hand side is already ordered on one side
+of an equality join predicate (i.e. an index order used for ORDER BY survived planning
+of the left-hand side), and the right-hand side can be scanned in the order of the other
+side of the predicate, such that no sorting is needed. With USE MERGE hint, explicit
+sorts are added to either side that is not already ordered.
+*/
+func (this *builder) buildMergeJoin(node *algebra.AnsiJoin, filter expression.Expression, selec float64) (
+	*plan.MergeJoin, error) {
+	child, leftKey, rightKey, descending, newOnclause, newFilter, cost, cardinality, size, frCost, err :=
+		this.buildMergeJoinOp(node.Right(), node.Outer(), node.Onclause(), filter, "join")
+	if err != nil || child == nil {
+		// cannot do merge join
+		return nil, err
+	}
+	if this.useCBO && (cost > 0.0) && (cardinality > 0.0) && (selec > 0.0) && (filter != nil) &&
+		(size > 0) && (frCost > 0.0) {
+		selec = this.adjustForHashFilters(node.Alias(), node.Onclause(), selec)
+		cost, cardinality, size, frCost = getSimpleFilterCost(node.Alias(),
+			cost, cardinality, selec, size, frCost)
+	}
+	if newOnclause != nil {
+		node.SetOnclause(newOnclause)
+	}
+	return plan.NewMergeJoin(node, child, leftKey, rightKey, descending, []string{node.Alias()}, newFilter,
+		cost, cardinality, size, frCost), nil
+}
+
+func (this *builder) buildMergeNest(node *algebra.AnsiNest, filter expression.Expression, selec float64) (
+	*plan.MergeNest, error) {
+	child, leftKey, rightKey, descending, newOnclause, newFilter, cost, cardinality, size, frCost, err :=
+		this.buildMergeJoinOp(node.Right(), node.Outer(), node.Onclause(), nil, "nest")
+	if err != nil || child == nil {
+		// cannot do merge nest
+		return nil, err
+	}
+	if this.useCBO && (cost > 0.0) && (cardinality > 0.0) && (selec > 0.0) && (filter != nil) &&
+		(size > 0) && (frCost > 0.0) {
+		selec = this.adjustForHashFilters(node.Alias(), node.Onclause(), selec)
+		cost, cardinality, size, frCost = getSimpleFilterCost(node.Alias(),
+			cost, cardinality, selec, size, frCost)
+	}
+	if newOnclause != nil {
+		node.SetOnclause(newOnclause)
+	}
+	return plan.NewMergeNest(node, child, leftKey, rightKey, descending, node.Alias(), newFilter,
+		cost, cardinality, size, frCost), nil
+}
+
+func (this *builder) buildMergeJoinOp(right algebra.SimpleFromTerm, outer bool,
+	onclause, filter expression.Expression, op string) (child plan.Operator,
+	leftKey, rightKey expression.Expression, descending bool, newOnclause, newFilter expression.Expression,
+	cost, cardinality float64, size int64, frCost float64, err error) {
+
+	// only keyspace term without USE KEYS can be scanned in order
+	ksterm := algebra.GetKeyspaceTerm(right)
+	if ksterm == nil || ksterm.Keys() != nil {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, nil
+	}
+
+	alias := ksterm.Alias()
+	keyspaceNames := make(map[string]string, 1)
+	keyspaceNames[alias] = ksterm.Keyspace()
+
+	baseKeyspace, _ := this.baseKeyspaces[alias]
+	filters := baseKeyspace.Filters()
+	if len(filters) > 0 {
+		filters.ClearHashFlag()
+	}
+
+	// the left-hand side is ordered on the first ORDER BY term, as long as
+	// the sort direction is known at plan time
+	var leftTerm *algebra.SortTerm
+	if this.order != nil && len(this.order.Terms()) > 0 {
+		leftTerm = this.order.Terms()[0]
+		if desc := leftTerm.DescendingExpr(); desc != nil && desc.Value() == nil {
+			leftTerm = nil
+		}
+	}
+
+	// look for an equality join predicate, preferably one on the left-hand side order
+	var mergeFltr *base.Filter
+	leftOrdered := false
+	for _, fltr := range filters {
+		if !fltr.IsJoin() {
+			continue
+		}
+
+		eqFltr, ok := fltr.FltrExpr().(*expression.Eq)
+		if !ok || !eqFltr.First().Indexable() || !eqFltr.Second().Indexable() {
+			continue
+		}
+
+		// make sure only one side of the equality predicate references
+		// alias (which is right-hand-side of the join)
+		firstRef := expression.HasKeyspaceReferences(eqFltr.First(), keyspaceNames)
+		secondRef := expression.HasKeyspaceReferences(eqFltr.Second(), keyspaceNames)
+
+		var lExpr, rExpr expression.Expression
+		if firstRef && !secondRef {
+			lExpr, rExpr = eqFltr.Second(), eqFltr.First()
+		} else if !firstRef && secondRef {
+			lExpr, rExpr = eqFltr.First(), eqFltr.Second()
+		} else {
+			continue
+		}
+
+		if leftTerm != nil && lExpr.EquivalentTo(leftTerm.Expression()) {
+			mergeFltr, leftKey, rightKey = fltr, lExpr.Copy(), rExpr.Copy()
+			leftOrdered = true
+			break
+		} else if mergeFltr == nil {
+			mergeFltr, leftKey, rightKey = fltr, lExpr.Copy(), rExpr.Copy()
+		}
+	}
+
+	// without USE MERGE hint, only consider merge join when no sort is needed
+	// on the left-hand side
+	if mergeFltr == nil || (!leftOrdered && !ksterm.PreferMerge()) {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, nil
+	}
+
+	var descExpr expression.Expression
+	if leftOrdered {
+		descExpr = leftTerm.DescendingExpr()
+		descending = leftTerm.Descending(nil)
+	}
+
+	useCBO := this.useCBO && this.keyspaceUseCBO(alias) && leftOrdered
+	if useCBO {
+		if mergeFltr.Selec() > 0.0 {
+			mergeFltr.SetHJFlag()
+		} else {
+			useCBO = false
+		}
+	}
+
+	// left hand side is already built
+	if len(this.subChildren) > 0 {
+		this.addChildren(this.addSubchildrenParallel())
+	}
+
+	// build right hand side, in the order of the right-hand side merge key
+
+	coveringScans := this.coveringScans
+	countScan := this.countScan
+	orderScan := this.orderScan
+	lastOp := this.lastOp
+	maxParallelism := this.maxParallelism
+	indexPushDowns := this.storeIndexPushDowns()
+	defer func() {
+		this.countScan = countScan
+		this.orderScan = orderScan
+		this.lastOp = lastOp
+		this.maxParallelism = maxParallelism
+		this.restoreIndexPushDowns(indexPushDowns, true)
+
+		if len(this.coveringScans) > 0 {
+			this.coveringScans = append(coveringScans, this.coveringScans...)
+		} else {
+			this.coveringScans = coveringScans
+		}
+
+		// the left-hand side is sorted on the merge key, query order is lost
+		if child != nil && !leftOrdered {
+			this.resetOrder()
+		}
+	}()
+
+	children := this.children
+	subChildren := this.subChildren
+
+	this.coveringScans = nil
+	this.countScan = nil
+	this.order = algebra.NewOrder(algebra.SortTerms{algebra.NewSortTerm(rightKey, descExpr, nil)})
+	this.orderScan = nil
+	this.limit = nil
+	this.offset = nil
+	this.lastOp = nil
+
+	this.children = make([]plan.Operator, 0, 16)
+	this.subChildren = make([]plan.Operator, 0, 16)
+
+	// similar to hash join, both sides of the merge join are independent of each other,
+	// join filters cannot be used for index selection on the right-hand side
+	ksterm.SetUnderHash()
+	ksterm.SetUnderMerge()
+	defer func() {
+		ksterm.UnsetUnderHash()
+		ksterm.UnsetUnderMerge()
+	}()
+
+	_, err = right.Accept(this)
+	if err != nil {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
+	}
+
+	// if no plan generated, or no ordered scan is available without USE MERGE hint, bail out
+	rightOrdered := this.order != nil
+	if len(this.children) == 0 || (!rightOrdered && !ksterm.PreferMerge()) {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, nil
+	}
+
+	// perform cover transformation of merge keys and onclause
+	if filter != nil {
+		newFilter = filter.Copy()
+	}
+
+	if onclause != nil {
+		newOnclause = onclause.Copy()
+	}
+
+	rightKeys := expression.Expressions{rightKey}
+	newFilter, newOnclause, err = this.coverJoinExprs(this.coveringScans, rightKeys, newFilter, newOnclause)
+	if err != nil {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
+	}
+
+	leftKeys := expression.Expressions{leftKey}
+	newFilter, newOnclause, err = this.coverJoinExprs(coveringScans, leftKeys, newFilter, newOnclause)
+	if err != nil {
+		return nil, nil, nil, false, nil, nil,
+			OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL, err
+	}
+
+	leftKey = leftKeys[0]
+	rightKey = rightKeys[0]
+
+	if len(this.subChildren) > 0 {
+		this.addChildren(this.addSubchildrenParallel())
+	}
+
+	if !rightOrdered {
+		useCBO = false
+		this.addChildren(plan.NewOrder(algebra.NewOrder(algebra.SortTerms{algebra.NewSortTerm(rightKey, descExpr, nil)}),
+			nil, nil, OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL))
+	}
+
+	if !leftOrdered {
+		children = append(children, plan.NewOrder(algebra.NewOrder(algebra.SortTerms{algebra.NewSortTerm(leftKey, nil, nil)}),
+			nil, nil, OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL))
+	}
+
+	if useCBO {
+		// merge join processes the same items as hash join building on the
+		// right-hand side, use hash join costing as an estimate
+		cost, cardinality, size, frCost, _ = getHashJoinCost(lastOp, this.lastOp, leftKeys, rightKeys,
+			true, true, filters, outer, op)
+	} else {
+		cost, cardinality, size, frCost = OPT_COST_NOT_AVAIL, OPT_CARD_NOT_AVAIL, OPT_SIZE_NOT_AVAIL, OPT_COST_NOT_AVAIL
+	}
+
+	child = plan.NewSequence(this.children...)
+	this.children = children
+	this.subChildren = subChildren
+
+	return child, leftKey, rightKey, descending, newOnclause, newFilter, cost, cardinality, size, frCost, nil
+}
+
+/*
+Perform cover transformation of join expressions, filter and onclause
+for the given covering scans.
+*/
+func (this *builder) coverJoinExprs(coveringScans []plan.CoveringOperator, exprs expression.Expressions,
+	filter, onclause expression.Expression) (newFilter, newOnclause expression.Expression, err error) {
+	newFilter = filter
+	newOnclause = onclause
+	for _, op := range coveringScans {
+		coverer := expression.NewCoverer(op.Covers(), op.FilterCovers())
+		if arrayKey := op.ImplicitArrayKey(); arrayKey != nil {
+			newFilter, newOnclause, _, err =
+				this.renameAnyExpression(arrayKey, newFilter, newOnclause, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			anyRenamer := expression.NewAnyRenamer(arrayKey)
+			for i, _ := range exprs {
+				exprs[i], err = anyRenamer.Map(exprs[i])
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+
+		newFilter, newOnclause, _, err = this.coverExpression(coverer, newFilter, newOnclause, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, _ := range exprs {
+			exprs[i], err = coverer.Map(exprs[i])
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return newFilter, newOnclause, nil
+}
+
 func (this *builder) buildAnsiJoinSimpleFromTerm(node algebra.SimpleFromTerm, onclause expression.Expression,
 	outer bool, op string) ([]plan.Operator, expression.Expression, float64, float64, int64, float64, error) {
 
@@ -1307,6 +1586,16 @@ func (this *builder) markPlanFlags(op plan.Operator, term algebra.SimpleFromTerm
 		} else {
 			children = this.children
 		}
+	case *plan.MergeJoin:
+		// right-hand side is always the child
+		if seq, ok := op.Child().(*plan.Sequence); ok {
+			children = seq.Children()
+		}
+	case *plan.MergeNest:
+		// right-hand side is always the child
+		if seq, ok := op.Child().(*plan.Sequence); ok {
+			children = seq.Children()
+		}
 	case *plan.HashNest:
 		if op.BuildAlias() == alias {
 			// expect the child to be a sequence operator
@@ -0,0 +1,259 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/expression"
+)
+
+/*
+Reorder the joins of the FROM clause per the LEADING hint: the hinted
+keyspaces are joined first, in the hinted order, followed by the rest
+in their original order.
+
+Only inner ANSI joins, or comma-separated joins, of keyspaces and
+subqueries are reordered. The conjuncts of the ON clauses are moved
+to the first join where all the keyspaces they reference are
+available; a join with no ON clause conjunct on its right-hand side
+keyspace is not done, as it would be a cartesian product.
+
+This is done before the keyspaces are gathered, such that the rest of
+the planning sees the new join order. Reordering an already reordered
+FROM clause does not change it, as happens when a prepared statement
+is planned again.
+*/
+func (this *builder) leadingJoinOrder(node *algebra.Subselect) {
+	optHints := node.OptimHints()
+	if optHints == nil || node.From() == nil {
+		return
+	}
+
+	var leading *algebra.HintLeading
+	for _, hint := range optHints.Hints() {
+		if hint, ok := hint.(*algebra.HintLeading); ok {
+			if leading != nil {
+				leading.SetError(algebra.DUPLICATED_LEADING_HINT)
+				hint.SetError(algebra.DUPLICATED_LEADING_HINT)
+				continue
+			}
+			leading = hint
+		}
+	}
+	if leading == nil || leading.State() == algebra.HINT_STATE_ERROR {
+		return
+	}
+
+	terms, onclauses, comma, ok := flattenJoins(node.From())
+	if !ok {
+		leading.SetNotFollowed()
+		return
+	}
+
+	pos := make(map[string]int, len(terms))
+	aliases := make(map[string]string, len(terms))
+	for i, term := range terms {
+		pos[term.Alias()] = i
+		aliases[term.Alias()] = term.Alias()
+	}
+
+	order := make([]int, 0, len(terms))
+	used := make(map[int]bool, len(terms))
+	for _, ks := range leading.Keyspaces() {
+		i, ok := pos[ks]
+		if !ok {
+			leading.SetError(algebra.INVALID_KEYSPACE + ks)
+			return
+		}
+		if used[i] {
+			leading.SetError(algebra.DUPLICATED_LEADING_KEYSPACE + ks)
+			return
+		}
+		used[i] = true
+		order = append(order, i)
+	}
+	for i, _ := range terms {
+		if !used[i] {
+			order = append(order, i)
+		}
+	}
+
+	reorder := false
+	for k, i := range order {
+		if k != i {
+			reorder = true
+			break
+		}
+	}
+	if !reorder {
+		leading.SetFollowed()
+		return
+	}
+
+	// the primary term cannot have a join hint, and USE KEYS of the
+	// original primary term does not apply to the right-hand side of a join
+	if terms[order[0]].JoinHint() != algebra.JOIN_HINT_NONE {
+		leading.SetNotFollowed()
+		return
+	}
+	if ksterm := algebra.GetKeyspaceTerm(terms[0]); ksterm != nil && ksterm.Keys() != nil {
+		leading.SetNotFollowed()
+		return
+	}
+
+	var conjuncts expression.Expressions
+	for _, onclause := range onclauses {
+		if and, ok := onclause.(*expression.And); ok {
+			and, _ = expression.FlattenAnd(and)
+			conjuncts = append(conjuncts, and.Operands()...)
+		} else {
+			conjuncts = append(conjuncts, onclause)
+		}
+	}
+
+	refs := make([]map[string]string, len(conjuncts))
+	for i, conjunct := range conjuncts {
+		var err error
+		refs[i], err = expression.CountKeySpaces(conjunct, aliases)
+		if err != nil {
+			leading.SetNotFollowed()
+			return
+		}
+	}
+
+	available := make(map[string]bool, len(terms))
+	available[terms[order[0]].Alias()] = true
+	placed := make([]bool, len(conjuncts))
+
+	var from algebra.FromTerm = terms[order[0]]
+	for _, i := range order[1:] {
+		right := terms[i]
+		available[right.Alias()] = true
+
+		var onclause expression.Expression
+		if !comma {
+			var ops expression.Expressions
+			joined := false
+			for j, conjunct := range conjuncts {
+				if placed[j] || !allAvailable(refs[j], available) {
+					continue
+				}
+				placed[j] = true
+				ops = append(ops, conjunct)
+				if _, ok := refs[j][right.Alias()]; ok {
+					joined = true
+				}
+			}
+			if !joined {
+				leading.SetNotFollowed()
+				return
+			}
+			if len(ops) == 1 {
+				onclause = ops[0]
+			} else {
+				onclause = expression.NewAnd(ops...)
+			}
+		}
+		from = algebra.NewAnsiJoin(from, false, right, onclause)
+	}
+
+	if order[0] != 0 {
+		setJoinOrderProps(terms[order[0]], false, false)
+		setJoinOrderProps(terms[0], true, comma)
+	}
+	node.SetFrom(from)
+	leading.SetFollowed()
+}
+
+/*
+Flatten a left-deep chain of inner joins into its terms, with the
+ON clauses of all but the primary term. All joins are either ANSI
+joins or comma-separated joins.
+*/
+func flattenJoins(from algebra.FromTerm) (terms []algebra.SimpleFromTerm,
+	onclauses expression.Expressions, comma bool, ok bool) {
+
+	first := true
+	for {
+		switch term := from.(type) {
+		case *algebra.AnsiJoin:
+			if term.Outer() || !joinOrderTerm(term.Right()) {
+				return nil, nil, false, false
+			}
+			if first {
+				comma = term.IsCommaJoin()
+				first = false
+			} else if comma != term.IsCommaJoin() {
+				return nil, nil, false, false
+			}
+			terms = append(terms, term.Right())
+			if !comma {
+				onclauses = append(onclauses, term.Onclause())
+			}
+			from = term.Left()
+		case algebra.SimpleFromTerm:
+			if first || !joinOrderTerm(term) {
+				return nil, nil, false, false
+			}
+			terms = append(terms, term)
+
+			// terms and ON clauses are gathered from the last join
+			for i, j := 0, len(terms)-1; i < j; i, j = i+1, j-1 {
+				terms[i], terms[j] = terms[j], terms[i]
+			}
+			for i, j := 0, len(onclauses)-1; i < j; i, j = i+1, j-1 {
+				onclauses[i], onclauses[j] = onclauses[j], onclauses[i]
+			}
+			return terms, onclauses, comma, true
+		default:
+			return nil, nil, false, false
+		}
+	}
+}
+
+/*
+Make a term the primary term, or the right-hand side of a join. A keyspace
+expression term has its join properties on its keyspace term as well.
+*/
+func setJoinOrderProps(term algebra.SimpleFromTerm, join, comma bool) {
+	terms := []algebra.SimpleFromTerm{term}
+	if ksterm := algebra.GetKeyspaceTerm(term); ksterm != nil && ksterm != term {
+		terms = append(terms, ksterm)
+	}
+	for _, term := range terms {
+		if !join {
+			term.UnsetJoinProps()
+			continue
+		}
+		term.SetAnsiJoin()
+		if comma {
+			term.SetCommaJoin()
+		}
+	}
+}
+
+// only keyspaces and subqueries can change place in the join order
+func joinOrderTerm(term algebra.SimpleFromTerm) bool {
+	switch term := term.(type) {
+	case *algebra.KeyspaceTerm, *algebra.SubqueryTerm:
+		return true
+	case *algebra.ExpressionTerm:
+		return term.IsKeyspace()
+	}
+	return false
+}
+
+func allAvailable(refs map[string]string, available map[string]bool) bool {
+	for alias, _ := range refs {
+		if !available[alias] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/parser/n1ql"
+)
+
+func TestLeadingJoinOrder(t *testing.T) {
+	const threeWay = " c.name FROM customer c JOIN purchase p ON c.customerId = p.customerId" +
+		" JOIN product pr ON pr.productId = p.productId AND pr.type = c.type"
+
+	var tests = []struct {
+		stmt    string
+		state   algebra.HintState
+		aliases []string
+	}{
+		// p joins c, and pr joins both
+		{"SELECT /*+ LEADING(p c) */" + threeWay, algebra.HINT_STATE_FOLLOWED, []string{"p", "c", "pr"}},
+		{"SELECT /*+ LEADING(p) */" + threeWay, algebra.HINT_STATE_FOLLOWED, []string{"p", "c", "pr"}},
+		{"SELECT /*+ LEADING(c p) */" + threeWay, algebra.HINT_STATE_FOLLOWED, []string{"c", "p", "pr"}},
+		{"SELECT /*+ LEADING(p pr c) */" + threeWay, algebra.HINT_STATE_FOLLOWED, []string{"p", "pr", "c"}},
+		{"SELECT /*+ LEADING(p c) */ c.name FROM customer c, purchase p WHERE c.customerId = p.customerId",
+			algebra.HINT_STATE_FOLLOWED, []string{"p", "c"}},
+
+		// c and pr are only joined through p
+		{"SELECT /*+ LEADING(pr c) */ c.name FROM customer c JOIN purchase p ON c.customerId = p.customerId" +
+			" JOIN product pr ON pr.productId = p.productId", algebra.HINT_STATE_NOT_FOLLOWED, []string{"c", "p", "pr"}},
+
+		// outer joins are left as they are
+		{"SELECT /*+ LEADING(p c) */ c.name FROM customer c LEFT JOIN purchase p ON c.customerId = p.customerId",
+			algebra.HINT_STATE_NOT_FOLLOWED, nil},
+
+		{"SELECT /*+ LEADING(p x) */" + threeWay, algebra.HINT_STATE_ERROR, []string{"c", "p", "pr"}},
+		{"SELECT /*+ LEADING(p p) */" + threeWay, algebra.HINT_STATE_ERROR, []string{"c", "p", "pr"}},
+		{"SELECT /*+ LEADING(p c) LEADING(c p) */" + threeWay, algebra.HINT_STATE_ERROR, []string{"c", "p", "pr"}},
+	}
+
+	for _, test := range tests {
+		stmt, err := n1ql.ParseStatement(test.stmt)
+		if err != nil {
+			t.Errorf("%s: %v", test.stmt, err)
+			continue
+		}
+		node := stmt.(*algebra.Select).Subresult().(*algebra.Subselect)
+		from := node.From().String()
+		builder := &builder{}
+		builder.leadingJoinOrder(node)
+
+		for _, hint := range node.OptimHints().Hints() {
+			if hint.State() != test.state {
+				t.Errorf("%s: expected hint state %v, got %v", test.stmt, test.state, hint.State())
+			}
+		}
+
+		if test.aliases == nil {
+			if node.From().String() != from {
+				t.Errorf("%s: unexpected FROM clause %v", test.stmt, node.From())
+			}
+			continue
+		}
+
+		terms, _, _, ok := flattenJoins(node.From())
+		if !ok {
+			t.Errorf("%s: unexpected FROM clause %v", test.stmt, node.From())
+			continue
+		}
+		aliases := make([]string, len(terms))
+		for i, term := range terms {
+			aliases[i] = term.Alias()
+		}
+		if !reflect.DeepEqual(aliases, test.aliases) {
+			t.Errorf("%s: expected join order %v, got %v", test.stmt, test.aliases, aliases)
+		}
+	}
+}
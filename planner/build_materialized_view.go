@@ -0,0 +1,38 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/plan"
+)
+
+func (this *builder) VisitCreateMaterializedView(stmt *algebra.CreateMaterializedView) (interface{}, error) {
+	stmt.Keyspace().SetDefaultNamespace(this.namespace)
+	_, err := this.getNameKeyspace(stmt.Keyspace(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err1 := stmt.Definition(this.namespace)
+	if err1 != nil {
+		return nil, err1
+	}
+	return plan.NewCreateMaterializedView(view, stmt.FailIfExists()), nil
+}
+
+func (this *builder) VisitRefreshMaterializedView(stmt *algebra.RefreshMaterializedView) (interface{}, error) {
+	stmt.Keyspace().SetDefaultNamespace(this.namespace)
+	return plan.NewRefreshMaterializedView(stmt.Keyspace().FullName(), stmt.Mode()), nil
+}
+
+func (this *builder) VisitDropMaterializedView(stmt *algebra.DropMaterializedView) (interface{}, error) {
+	stmt.Keyspace().SetDefaultNamespace(this.namespace)
+	return plan.NewDropMaterializedView(stmt.Keyspace().FullName(), stmt.FailIfNotExists()), nil
+}
@@ -20,6 +20,7 @@ func (this *builder) VisitMerge(stmt *algebra.Merge) (interface{}, error) {
 	var path *algebra.Path
 
 	this.node = stmt
+	this.dmlOptimHints(stmt.OptimHints())
 	this.children = make([]plan.Operator, 0, 8)
 	this.subChildren = make([]plan.Operator, 0, 8)
 	source := stmt.Source()
@@ -130,7 +131,7 @@ func (this *builder) VisitMerge(stmt *algebra.Merge) (interface{}, error) {
 		switch join := join.(type) {
 		case *plan.NLJoin:
 			this.addSubChildren(join)
-		case *plan.Join, *plan.HashJoin:
+		case *plan.Join, *plan.HashJoin, *plan.MergeJoin:
 			if len(this.subChildren) > 0 {
 				this.addChildren(this.addSubchildrenParallel())
 			}
@@ -217,7 +218,8 @@ func (this *builder) VisitMerge(stmt *algebra.Merge) (interface{}, error) {
 			cost, cardinality, size, frCost = getUpdateSendCost(stmt.Limit(),
 				cost, cardinality, size, frCost)
 		}
-		ops = append(ops, plan.NewSendUpdate(keyspace, ksref, stmt.Limit(), cost, cardinality, size, frCost))
+		ops = append(ops, plan.NewSendUpdate(keyspace, ksref, stmt.Limit(), stmt.Validate(), stmt.ReturningImages(),
+			cost, cardinality, size, frCost))
 		update = plan.NewSequence(ops...)
 		if this.useCBO && cost > 0.0 {
 			updateCost = cost
@@ -249,7 +251,8 @@ func (this *builder) VisitMerge(stmt *algebra.Merge) (interface{}, error) {
 				cost, cardinality, size, frCost)
 		}
 
-		delete = plan.NewSendDelete(keyspace, ksref, stmt.Limit(), cost, cardinality, size, frCost)
+		delete = plan.NewSendDelete(keyspace, ksref, stmt.Limit(), stmt.ReturningImages(), cost, cardinality,
+			size, frCost)
 		if this.useCBO && cost > 0.0 {
 			deleteCost = cost
 			deleteCard = cardinality
@@ -287,8 +290,8 @@ func (this *builder) VisitMerge(stmt *algebra.Merge) (interface{}, error) {
 				act.Options(), stmt.Limit(), cost, cardinality, size, frCost)
 		}
 
-		insert = plan.NewSendInsert(keyspace, ksref, keyExpr, act.Value(), act.Options(), stmt.Limit(), cost, cardinality, size,
-			frCost)
+		insert = plan.NewSendInsert(keyspace, ksref, keyExpr, act.Value(), act.Options(), stmt.Limit(), stmt.Validate(),
+			stmt.ReturningImages(), cost, cardinality, size, frCost)
 		if this.useCBO && cost > 0.0 {
 			insertCost = cost
 			insertCard = cardinality
@@ -56,9 +56,12 @@ func (this *builder) VisitPrepare(stmt *algebra.Prepare) (interface{}, error) {
 	}
 
 	dks := this.context.DeltaKeyspaces()
+	bt := this.context.BaselineText()
 	this.context.SetDeltaKeyspaces(nil)
+	this.context.SetBaselineText(stmt.Text()[stmt.Offset():])
 	prep, err = BuildPrepared(stmt.Statement(), this.datastore, this.systemstore, this.namespace, false, true, this.context)
 	this.context.SetDeltaKeyspaces(dks)
+	this.context.SetBaselineText(bt)
 
 	if err != nil {
 		return nil, err
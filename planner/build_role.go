@@ -20,3 +20,23 @@ func (this *builder) VisitGrantRole(stmt *algebra.GrantRole) (interface{}, error
 func (this *builder) VisitRevokeRole(stmt *algebra.RevokeRole) (interface{}, error) {
 	return plan.NewRevokeRole(stmt), nil
 }
+
+func (this *builder) VisitCreateUser(stmt *algebra.CreateUser) (interface{}, error) {
+	return plan.NewCreateUser(stmt), nil
+}
+
+func (this *builder) VisitAlterUser(stmt *algebra.AlterUser) (interface{}, error) {
+	return plan.NewAlterUser(stmt), nil
+}
+
+func (this *builder) VisitDropUser(stmt *algebra.DropUser) (interface{}, error) {
+	return plan.NewDropUser(stmt), nil
+}
+
+func (this *builder) VisitCreateGroup(stmt *algebra.CreateGroup) (interface{}, error) {
+	return plan.NewCreateGroup(stmt), nil
+}
+
+func (this *builder) VisitDropGroup(stmt *algebra.DropGroup) (interface{}, error) {
+	return plan.NewDropGroup(stmt), nil
+}
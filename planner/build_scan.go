@@ -53,6 +53,7 @@ func (this *builder) selectScan(keyspace datastore.Keyspace, node *algebra.Keysp
 	if err != nil {
 		return nil, err
 	}
+	this.checkCombineHint(node.Alias(), secondary)
 
 	if !this.joinEnum() && !node.IsAnsiJoinOp() {
 		err = this.markOptimHints(node.Alias())
@@ -236,6 +237,7 @@ func (this *builder) buildPredicateScan(keyspace datastore.Keyspace, node *algeb
 	if err != nil {
 		return
 	}
+	others = avoidIndexes(baseKeyspace, others)
 
 	secondary, primary, err = this.buildSubsetScan(keyspace, node,
 		baseKeyspace, id, others, primaryKey, formalizer, false)
@@ -267,7 +269,12 @@ func (this *builder) buildSubsetScan(keyspace datastore.Keyspace, node *algebra.
 	join := node.IsAnsiJoinOp()
 	hash := node.IsUnderHash()
 	if join {
+		mergeOrder := this.order
 		this.resetPushDowns()
+		// right-hand side of merge join must be scanned in join key order
+		if node.IsUnderMerge() {
+			this.order = mergeOrder
+		}
 	}
 	order := this.order
 
@@ -275,7 +282,8 @@ func (this *builder) buildSubsetScan(keyspace datastore.Keyspace, node *algebra.
 	if join && baseKeyspace.OnclauseOnly() {
 		pred = baseKeyspace.Onclause()
 	}
-	if !this.hasBuilderFlag(BUILDER_CHK_INDEX_ORDER) {
+	if !this.hasBuilderFlag(BUILDER_CHK_INDEX_ORDER) &&
+		this.combineHint(node.Alias()) != algebra.HINT_NO_INDEX_COMBINE {
 		// Prefer OR scan
 		if or, ok := pred.(*expression.Or); ok {
 
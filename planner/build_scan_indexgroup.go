@@ -38,6 +38,9 @@ var _INDEX_AGG_PROPERTIES = map[string]*indexGroupAggProperties{
 	"count_distinct":  &indexGroupAggProperties{3, true, datastore.AGG_COUNT, true, false, false},
 	"countn_distinct": &indexGroupAggProperties{3, true, datastore.AGG_COUNTN, true, false, false},
 	"sum_distinct":    &indexGroupAggProperties{3, true, datastore.AGG_SUM, true, false, false},
+
+	// the exact distinct count from the indexer is as good as the estimate
+	"approx_count_distinct": &indexGroupAggProperties{3, true, datastore.AGG_COUNT, true, false, false},
 }
 
 func checkAndAdd(ids []int, id int) []int {
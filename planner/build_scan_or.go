@@ -25,7 +25,9 @@ func (this *builder) buildOrScan(node *algebra.KeyspaceTerm, baseKeyspace *base.
 	scan plan.SecondaryScan, sargLength int, err error) {
 
 	indexPushDowns := this.storeIndexPushDowns()
-	if this.cover != nil || this.hasOrderOrOffsetOrLimit() {
+	// INDEX_COMBINE hint prefers a union scan over a covering scan or pushdowns
+	if (this.cover != nil || this.hasOrderOrOffsetOrLimit()) &&
+		this.combineHint(node.Alias()) != algebra.HINT_INDEX_COMBINE {
 		coveringScans := this.coveringScans
 		scan, sargLength, err = this.buildTermScan(node, baseKeyspace, id, indexes, primaryKey, formalizer)
 		if err == nil && scan != nil {
@@ -173,7 +173,9 @@ nextagg:
 		default:
 			// Distinct aggregates argument can be any key in the matched leading keys + 0|1
 			// 0 for partition index and 1 for non partition index
-			if agg.Distinct() {
+			// APPROX_COUNT_DISTINCT is pushed down as COUNT(DISTINCT)
+			_, approxDistinct := agg.(*algebra.ApproxCountDistinct)
+			if agg.Distinct() || approxDistinct {
 				if groupMatch {
 					if constOp {
 						continue nextagg
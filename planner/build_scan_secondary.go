@@ -31,10 +31,13 @@ func (this *builder) buildSecondaryScan(indexes, arrayIndexes, flex map[datastor
 		pred, arrayIndexes)
 	defer releaseUnnestPools(unnests, primaryUnnests)
 
-	scan, sargLength, err = this.buildCovering(indexes, unnestIndexes, flex, node,
-		baseKeyspace, subset, id, searchSargables, unnests)
-	if scan != nil || err != nil {
-		return
+	// INDEX_COMBINE hint prefers an intersect scan over a covering scan
+	if len(indexes) <= 1 || this.combineHint(node.Alias()) != algebra.HINT_INDEX_COMBINE {
+		scan, sargLength, err = this.buildCovering(indexes, unnestIndexes, flex, node,
+			baseKeyspace, subset, id, searchSargables, unnests)
+		if scan != nil || err != nil {
+			return
+		}
 	}
 
 	hasDeltaKeyspace := this.context.HasDeltaKeyspace(baseKeyspace.Keyspace())
@@ -78,6 +81,9 @@ func (this *builder) buildCreateSecondaryScan(indexes, flex map[datastore.Index]
 	searchSargables []*indexEntry, hasDeltaKeyspace bool) (scan plan.SecondaryScan, sargLength int, err error) {
 
 	indexes = this.minimalIndexes(indexes, true, pred, node)
+	if len(indexes) > 1 && this.combineHint(node.Alias()) == algebra.HINT_NO_INDEX_COMBINE {
+		indexes = bestIndex(indexes)
+	}
 	// Already done. need only for one index
 	// flex = this.minimalFTSFlexIndexes(flex, true)
 	searchSargables = this.minimalSearchIndexes(flex, searchSargables)
@@ -496,13 +502,50 @@ func (this *builder) minimalIndexes(sargables map[datastore.Index]*indexEntry, s
 		}
 	}
 
-	if useCBO && shortest && len(sargables) > 1 {
+	if useCBO && shortest && len(sargables) > 1 && this.combineHint(alias) != algebra.HINT_INDEX_COMBINE {
 		sargables = this.chooseIntersectScan(sargables, node)
 	}
 
 	return sargables
 }
 
+/*
+Keep only the best of the sargable indexes, for NO_INDEX_COMBINE hint:
+the cheapest one if all are costed, else the one with most sargable keys.
+*/
+func bestIndex(sargables map[datastore.Index]*indexEntry) map[datastore.Index]*indexEntry {
+	useCost := true
+	for _, se := range sargables {
+		if se.cost <= 0.0 {
+			useCost = false
+			break
+		}
+	}
+
+	var best *indexEntry
+	for _, se := range sargables {
+		if best == nil {
+			best = se
+			continue
+		}
+		if useCost {
+			if se.cost < best.cost || (se.cost == best.cost && se.index.Name() < best.index.Name()) {
+				best = se
+			}
+		} else if len(se.sargKeys) > len(best.sargKeys) ||
+			(len(se.sargKeys) == len(best.sargKeys) && se.index.Name() < best.index.Name()) {
+			best = se
+		}
+	}
+
+	for index, _ := range sargables {
+		if index != best.index {
+			delete(sargables, index)
+		}
+	}
+	return sargables
+}
+
 /*
 Is se narrower or equivalent to te.
   true : purge te
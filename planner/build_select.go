@@ -42,6 +42,18 @@ func (this *builder) VisitSelect(stmt *algebra.Select) (interface{}, error) {
 
 	}()
 
+	// fall back to the query as written if the view cannot be used
+	if !this.hasBuilderFlag(BUILDER_VIEW_REWRITE) {
+		if view := this.materializedViewSelect(stmt); view != nil {
+			this.setBuilderFlag(BUILDER_VIEW_REWRITE)
+			op, err := this.VisitSelect(view)
+			this.unsetBuilderFlag(BUILDER_VIEW_REWRITE)
+			if err == nil {
+				return op, nil
+			}
+		}
+	}
+
 	stmtOrder := stmt.Order()
 	stmtOffset, err := newOffsetLimitExpr(stmt.Offset(), true)
 	if err != nil {
@@ -33,6 +33,8 @@ func (this *builder) visitFrom(node *algebra.Subselect, group *algebra.Group,
 		this.maxParallelism = 1
 		this.resetPushDowns()
 	} else if node.From() != nil {
+		this.leadingJoinOrder(node)
+
 		prevFrom := this.from
 		this.from = node.From()
 		defer func() { this.from = prevFrom }()
@@ -133,7 +135,7 @@ func (this *builder) visitFrom(node *algebra.Subselect, group *algebra.Group,
 		var hasOrder bool
 
 		if this.useCBO && !this.indexAdvisor && this.context.Optimizer() != nil &&
-			!hasOrderedHint(node.OptimHints()) &&
+			!hasOrderedHint(node.OptimHints()) && !hasLeadingHint(node.OptimHints()) &&
 			util.IsFeatureEnabled(this.context.FeatureControls(), util.N1QL_JOIN_ENUMERATION) {
 			var limit, offset expression.Expression
 			var order *algebra.Order
@@ -550,7 +552,9 @@ func (this *builder) VisitAnsiJoin(node *algebra.AnsiJoin) (interface{}, error)
 	switch join := join.(type) {
 	case *plan.NLJoin:
 		this.addSubChildren(join)
-	case *plan.Join, *plan.HashJoin:
+	case *plan.HashJoin:
+		this.addHashOp(join, join.Child())
+	case *plan.Join, *plan.MergeJoin:
 		if len(this.subChildren) > 0 {
 			this.addChildren(this.addSubchildrenParallel())
 		}
@@ -673,7 +677,9 @@ func (this *builder) VisitAnsiNest(node *algebra.AnsiNest) (interface{}, error)
 	switch nest := nest.(type) {
 	case *plan.NLNest:
 		this.addSubChildren(nest)
-	case *plan.Nest, *plan.HashNest:
+	case *plan.HashNest:
+		this.addHashOp(nest, nest.Child())
+	case *plan.Nest, *plan.MergeNest:
 		if len(this.subChildren) > 0 {
 			this.addChildren(this.addSubchildrenParallel())
 		}
@@ -34,6 +34,7 @@ func (this *builder) VisitSubselect(node *algebra.Subselect) (interface{}, error
 	prevPushableOnclause := this.pushableOnclause
 	prevBuilderFlags := this.builderFlags
 	prevMaxParallelism := this.maxParallelism
+	prevParallelHint := this.parallelHint
 	prevAliases := this.aliases
 	prevLastOp := this.lastOp
 
@@ -52,6 +53,7 @@ func (this *builder) VisitSubselect(node *algebra.Subselect) (interface{}, error
 		this.pushableOnclause = prevPushableOnclause
 		this.builderFlags = prevBuilderFlags
 		this.maxParallelism = prevMaxParallelism
+		this.parallelHint = prevParallelHint
 		this.lastOp = prevLastOp
 		this.aliases = prevAliases
 		this.restoreIndexPushDowns(indexPushDowns, false)
@@ -69,6 +71,11 @@ func (this *builder) VisitSubselect(node *algebra.Subselect) (interface{}, error
 	this.lastOp = nil
 	this.aliases = nil
 
+	// a PARALLEL hint applies to nested query blocks without one
+	if parallel := parallelHint(node.OptimHints()); parallel > 0 {
+		this.parallelHint = parallel
+	}
+
 	this.projection = node.Projection()
 	this.resetIndexGroupAggs()
 
@@ -411,17 +418,30 @@ func (this *builder) visitGroup(group *algebra.Group, aggs algebra.Aggregates) {
 			size = last.Size()
 			if cost > 0.0 && cardinality > 0.0 && size > 0 {
 				costInitial, cardinalityInitial, costIntermediate, cardinalityIntermediate, costFinal, cardinalityFinal =
-					getGroupCosts(group, aggs, cost, cardinality, size, this.keyspaceNames, this.maxParallelism)
+					getGroupCosts(group, aggs, cost, cardinality, size, this.keyspaceNames, this.parallelism())
 			}
 		}
 		aggv := sortAggregatesSlice(aggs)
 		this.addSubChildren(plan.NewInitialGroup(group.By(), aggv,
 			costInitial, cardinalityInitial, size, costInitial))
 		this.addChildren(this.addSubchildrenParallel())
-		this.addChildren(plan.NewIntermediateGroup(group.By(), aggv,
-			costIntermediate, cardinalityIntermediate, size, costIntermediate))
-		this.addChildren(plan.NewFinalGroup(group.By(), aggv,
-			costFinal, cardinalityFinal, size, costFinal))
+		intermediate := plan.NewIntermediateGroup(group.By(), aggv,
+			costIntermediate, cardinalityIntermediate, size, costIntermediate)
+		final := plan.NewFinalGroup(group.By(), aggv,
+			costFinal, cardinalityFinal, size, costFinal)
+
+		// with enough partial groups, merge and complete them in parallel
+		dop := 1
+		if len(group.By()) > 0 {
+			dop = this.adaptiveParallelism(cardinalityInitial)
+		}
+		if dop > 1 {
+			intermediate.SetPartitions(dop)
+			this.addChildren(plan.NewAdaptiveParallel(plan.NewSequence(intermediate, final), dop))
+		} else {
+			this.addChildren(intermediate)
+			this.addChildren(final)
+		}
 	}
 
 	this.addLetAndPredicate(group.Letting(), group.Having())
@@ -880,6 +900,11 @@ This enables the query to use an index on v.
 func (this *builder) constrainAggregate(cond expression.Expression, aggs algebra.Aggregates) expression.Expression {
 	var first expression.Expression
 	for _, agg := range aggs {
+		// ordered-set aggregates take their input from WITHIN GROUP
+		if len(agg.Operands()) == 0 || agg.WithinGroup() != nil {
+			return cond
+		}
+
 		if first == nil {
 			first = agg.Operands()[0]
 			if first == nil {
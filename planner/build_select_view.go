@@ -0,0 +1,224 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/expression/parser"
+)
+
+/*
+Rewrite a query to read a materialized view that has query_rewrite
+set, if the view holds exactly its groups: the query has to read the
+view source with the same WHERE clause and group keys, and everything
+it projects, filters with HAVING and orders by has to be computable
+from the view fields.
+*/
+func (this *builder) materializedViewSelect(stmt *algebra.Select) *algebra.Select {
+	if stmt.IsCorrelated() {
+		return nil
+	}
+
+	sub, ok := stmt.Subresult().(*algebra.Subselect)
+	if !ok || sub.With() != nil || sub.Let() != nil || sub.Window() != nil {
+		return nil
+	}
+
+	simple, ok := sub.From().(algebra.SimpleFromTerm)
+	if !ok {
+		return nil
+	}
+	from := algebra.GetKeyspaceTerm(simple)
+	if from == nil || from.Path() == nil || from.Keys() != nil || from.Indexes() != nil {
+		return nil
+	}
+
+	var keys expression.Expressions
+	if group := sub.Group(); group != nil {
+		if group.Letting() != nil {
+			return nil
+		}
+		keys = group.By()
+	}
+
+	from.SetDefaultNamespace(this.namespace)
+	source := from.Path().ProtectedString()
+	store := datastore.GetMaterializedViewStore()
+	for _, name := range store.Names() {
+		view, ok := store.View(name)
+		if !ok || !view.QueryRewrite || view.Source != source {
+			continue
+		}
+
+		// views loaded from other nodes may not have been populated yet
+		if _, last := view.Refreshed(); last.IsZero() {
+			continue
+		}
+
+		rv, err := viewSelect(stmt, sub, from.Alias(), keys, view)
+		if err == nil && rv != nil {
+			return rv
+		}
+	}
+	return nil
+}
+
+func viewSelect(stmt *algebra.Select, sub *algebra.Subselect, alias string, keys expression.Expressions,
+	view *datastore.MaterializedView) (*algebra.Select, error) {
+
+	rename := func(s string) (expression.Expression, error) {
+		expr, err := parser.Parse(s)
+		if err != nil || view.Alias == alias {
+			return expr, err
+		}
+		return expression.ReplaceExpr(expr, expression.NewIdentifier(view.Alias), expression.NewIdentifier(alias))
+	}
+
+	// same filter
+	if view.Where == "" {
+		if sub.Where() != nil {
+			return nil, nil
+		}
+	} else {
+		where, err := rename(view.Where)
+		if err != nil {
+			return nil, err
+		}
+		if sub.Where() == nil || !sub.Where().EquivalentTo(where) {
+			return nil, nil
+		}
+	}
+
+	// same groups
+	if len(keys) != len(view.Keys) {
+		return nil, nil
+	}
+	viewKeys := make(expression.Expressions, len(view.Keys))
+	for i, k := range view.Keys {
+		var err error
+
+		viewKeys[i], err = rename(k)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range keys {
+		found := false
+		for _, vk := range viewKeys {
+			if k.EquivalentTo(vk) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+
+	mapper := &viewMapper{alias: alias}
+	mapper.SetMapper(mapper)
+	mapper.SetMapFunc(mapper.mapView)
+	for _, f := range view.Fields {
+		expr, err := rename(f.Expr)
+		if err != nil {
+			return nil, err
+		}
+		mapper.exprs = append(mapper.exprs, expr)
+		mapper.fields = append(mapper.fields, expression.NewField(expression.NewIdentifier(alias),
+			expression.NewFieldName(f.Name, false)))
+	}
+
+	subqueries, err := expression.ListSubqueries(stmt.Expressions(), false)
+	if err != nil || len(subqueries) > 0 {
+		return nil, err
+	}
+
+	// the expressions are copied, as the query is not changed
+	var projection *algebra.Projection
+	proj := sub.Projection()
+	if proj.Raw() {
+		expr, err := mapper.Map(proj.Terms()[0].Expression().Copy())
+		if err != nil {
+			return nil, err
+		}
+		projection = algebra.NewRawProjection(proj.Distinct(), expr, proj.Terms()[0].Alias())
+	} else {
+		terms := make(algebra.ResultTerms, len(proj.Terms()))
+		for i, t := range proj.Terms() {
+			if t.Star() {
+				return nil, nil
+			}
+			expr, err := mapper.Map(t.Expression().Copy())
+			if err != nil {
+				return nil, err
+			}
+			terms[i] = algebra.NewResultTerm(expr, false, t.Alias())
+		}
+		projection = algebra.NewProjection(proj.Distinct(), terms)
+	}
+
+	var where expression.Expression
+	if sub.Group() != nil && sub.Group().Having() != nil {
+		where, err = mapper.Map(sub.Group().Having().Copy())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var order *algebra.Order
+	if stmt.Order() != nil {
+		order = stmt.Order().Copy()
+		err = order.MapExpressions(mapper)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path := algebra.NewPathFromElements(algebra.ParsePath(view.Target))
+	term := algebra.NewKeyspaceTermFromPath(path, alias, nil, nil)
+	rv := algebra.NewSelect(algebra.NewSubselect(nil, term, nil, where, nil, nil, projection, sub.OptimHints()),
+		order, stmt.Offset(), stmt.Limit())
+	err = rv.Formalize()
+	if err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+/*
+Replace the view expressions with the view fields holding them. Any
+aggregate or document reference left means the view cannot be used.
+*/
+type viewMapper struct {
+	expression.MapperBase
+	alias  string
+	exprs  expression.Expressions
+	fields expression.Expressions
+}
+
+func (this *viewMapper) mapView(expr expression.Expression) (expression.Expression, error) {
+	for i, e := range this.exprs {
+		if expr.EquivalentTo(e) {
+			return this.fields[i].Copy(), nil
+		}
+	}
+
+	switch expr := expr.(type) {
+	case algebra.Aggregate:
+		return nil, errors.NewPlanError(nil, "aggregate not in materialized view")
+	case *expression.Identifier:
+		if expr.Identifier() == this.alias {
+			return nil, errors.NewPlanError(nil, "reference not in materialized view")
+		}
+	}
+	return expr, expr.MapChildren(this)
+}
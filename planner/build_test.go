@@ -0,0 +1,51 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/plan"
+)
+
+func TestAdaptiveParallelism(t *testing.T) {
+	var tests = []struct {
+		useCBO         bool
+		maxParallelism int
+		parallelHint   int
+		cardinality    float64
+		expected       int
+	}{
+		// without estimates, run on one copy
+		{false, 8, 0, 1000000, 1},
+		{true, 8, 0, -1, 1},
+
+		// one copy for every _ROWS_PER_COPY rows
+		{true, 8, 0, 100, 1},
+		{true, 8, 0, 3 * _ROWS_PER_COPY, 3},
+
+		// capped by the statement and the PARALLEL hint
+		{true, 8, 0, 100 * _ROWS_PER_COPY, 8},
+		{true, 8, 2, 100 * _ROWS_PER_COPY, 2},
+		{true, 2, 4, 100 * _ROWS_PER_COPY, 2},
+		{true, 1, 0, 100 * _ROWS_PER_COPY, 1},
+		{true, 0, 0, 1000 * _ROWS_PER_COPY, plan.GetMaxParallelism()},
+	}
+
+	for i, test := range tests {
+		builder := &builder{
+			useCBO:         test.useCBO,
+			maxParallelism: test.maxParallelism,
+			parallelHint:   test.parallelHint,
+		}
+		if dop := builder.adaptiveParallelism(test.cardinality); dop != test.expected {
+			t.Errorf("Test %d: expected parallelism %d, got %d", i, test.expected, dop)
+		}
+	}
+}
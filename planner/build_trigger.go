@@ -0,0 +1,49 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/functions"
+	"github.com/couchbase/query/plan"
+)
+
+func (this *builder) VisitCreateTrigger(stmt *algebra.CreateTrigger) (interface{}, error) {
+	stmt.Keyspace().SetDefaultNamespace(this.namespace)
+	keyspace, err := this.getNameKeyspace(stmt.Keyspace(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// as for functions called in expressions, the function must exist
+	if !functions.PreLoad(stmt.Function()) {
+		return nil, errors.NewMissingFunctionError(stmt.Function().Name())
+	}
+
+	trigger := &datastore.Trigger{
+		Name:     stmt.Name(),
+		Keyspace: keyspace.QualifiedName(),
+		Timing:   stmt.Timing(),
+		Event:    stmt.Event(),
+		Function: stmt.Function(),
+		Args:     stmt.Args(),
+	}
+	return plan.NewCreateTrigger(trigger, stmt.FailIfExists()), nil
+}
+
+func (this *builder) VisitDropTrigger(stmt *algebra.DropTrigger) (interface{}, error) {
+	stmt.Keyspace().SetDefaultNamespace(this.namespace)
+	keyspace, err := this.getNameKeyspace(stmt.Keyspace(), false)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewDropTrigger(stmt.Name(), keyspace.QualifiedName(), stmt.FailIfNotExists()), nil
+}
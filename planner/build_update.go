@@ -17,6 +17,7 @@ import (
 func (this *builder) VisitUpdate(stmt *algebra.Update) (interface{}, error) {
 	this.where = stmt.Where()
 	this.node = stmt
+	this.dmlOptimHints(stmt.OptimHints())
 
 	this.initialIndexAdvisor(stmt)
 	ksref := stmt.KeyspaceRef()
@@ -72,7 +73,7 @@ func (this *builder) VisitUpdate(stmt *algebra.Update) (interface{}, error) {
 			cost, cardinality, size, frCost)
 	}
 	updateSubChildren = append(updateSubChildren, plan.NewSendUpdate(keyspace, ksref, stmt.Limit(),
-		cost, cardinality, size, frCost))
+		stmt.Validate(), stmt.ReturningImages(), cost, cardinality, size, frCost))
 
 	if stmt.Returning() != nil {
 		updateSubChildren = this.buildDMLProject(stmt.Returning(), updateSubChildren)
@@ -61,7 +61,7 @@ func (this *builder) VisitUpsert(stmt *algebra.Upsert) (interface{}, error) {
 	}
 
 	upsert := plan.NewSendUpsert(keyspace, ksref, stmt.Key(), stmt.Value(), stmt.Options(),
-		cost, cardinality, size, frCost)
+		stmt.Validate(), cost, cardinality, size, frCost)
 	subChildren := make([]plan.Operator, 0, 4)
 	subChildren = append(subChildren, upsert)
 
@@ -25,6 +25,7 @@ type PrepareContext struct {
 	optimizer       Optimizer
 	deltaKeyspaces  map[string]bool
 	dsContext       datastore.QueryContext
+	baselineText    string
 }
 
 func NewPrepareContext(rv *PrepareContext, requestId, queryContext string,
@@ -93,6 +94,15 @@ func (this *PrepareContext) SetPositionalArgs(pa value.Values) {
 	this.positionalArgs = pa
 }
 
+// the text of the statement being planned, to match plan baselines against
+func (this *PrepareContext) SetBaselineText(text string) {
+	this.baselineText = text
+}
+
+func (this *PrepareContext) BaselineText() string {
+	return this.baselineText
+}
+
 func (this *PrepareContext) DeltaKeyspaces() map[string]bool {
 	return this.deltaKeyspaces
 }
@@ -12,10 +12,11 @@ import (
 	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/plan"
 	base "github.com/couchbase/query/plannerbase"
 )
 
-// derive new OptimHints based on USE INDEX and USE NL/USE HASH specified in the query
+// derive new OptimHints based on USE INDEX and USE NL/USE HASH/USE MERGE specified in the query
 func deriveOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *algebra.OptimHints) *algebra.OptimHints {
 	var newHints []algebra.OptimHint
 
@@ -37,6 +38,8 @@ func deriveOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *a
 				newHint = algebra.NewDerivedHashHint(alias, algebra.HASH_OPTION_PROBE)
 			case algebra.USE_NL:
 				newHint = algebra.NewDerivedNLHint(alias)
+			case algebra.USE_MERGE:
+				newHint = algebra.NewDerivedMergeHint(alias)
 			}
 			if newHint != nil {
 				baseKeyspace.AddJoinHint(newHint)
@@ -88,8 +91,8 @@ func processOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *
 		return
 	}
 
-	// For INDEX/INDEX_FTS/USE_NL/USE_HASH hints, add correpsonding hint in SimpleFromTerm
-	// for ease of further processing.
+	// For INDEX/INDEX_FTS/USE_NL/USE_HASH/USE_MERGE/NO_USE_NL/NO_USE_HASH hints, add correpsonding
+	// hint in SimpleFromTerm for ease of further processing.
 	// Note we don't allow mixing of USE style hints (specified after a keyspace in query text)
 	// with same type of hint specified up front
 	for _, hint := range optimHints.Hints() {
@@ -100,6 +103,7 @@ func processOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *
 		var keyspace string
 		var indexes algebra.IndexRefs
 		var joinHint algebra.JoinHint
+		var noIndex, combine bool
 
 		switch hint := hint.(type) {
 		case *algebra.HintIndex:
@@ -111,6 +115,24 @@ func processOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *
 		case *algebra.HintNL:
 			keyspace = hint.Keyspace()
 			joinHint = algebra.USE_NL
+		case *algebra.HintMerge:
+			keyspace = hint.Keyspace()
+			joinHint = algebra.USE_MERGE
+		case *algebra.HintNoNL:
+			keyspace = hint.Keyspace()
+			joinHint = algebra.NO_USE_NL
+		case *algebra.HintNoHash:
+			keyspace = hint.Keyspace()
+			joinHint = algebra.NO_USE_HASH
+		case *algebra.HintNoIndex:
+			keyspace = hint.Keyspace()
+			noIndex = true
+		case *algebra.HintIndexCombine:
+			keyspace = hint.Keyspace()
+			combine = true
+		case *algebra.HintNoIndexCombine:
+			keyspace = hint.Keyspace()
+			combine = true
 		case *algebra.HintHash:
 			keyspace = hint.Keyspace()
 			switch hint.Option() {
@@ -191,6 +213,176 @@ func processOptimHints(baseKeyspaces map[string]*base.BaseKeyspace, optimHints *
 			}
 			baseKeyspace.AddIndexHint(hint)
 		}
+		if noIndex {
+			if algebra.GetKeyspaceTerm(node) == nil {
+				hint.SetError(algebra.NON_KEYSPACE_NO_INDEX_HINT + keyspace)
+			}
+			baseKeyspace.AddNoIndexHint(hint)
+		}
+		if combine {
+			curHints := baseKeyspace.CombineHints()
+			if algebra.GetKeyspaceTerm(node) == nil {
+				hint.SetError(algebra.NON_KEYSPACE_COMBINE_HINT + keyspace)
+			} else if len(curHints) > 0 {
+				// duplicated (or conflicting) index combine hint
+				hint.SetError(algebra.DUPLICATED_COMBINE_HINT + keyspace)
+				for _, curHint := range curHints {
+					curHint.SetError(algebra.DUPLICATED_COMBINE_HINT + keyspace)
+				}
+			}
+			baseKeyspace.AddCombineHint(hint)
+		}
+	}
+
+	// NO_INDEX hint cannot exclude an index the INDEX hint (or USE INDEX) asks for,
+	// otherwise it is always followed when the keyspace is scanned
+	for keyspace, baseKeyspace := range baseKeyspaces {
+		for _, hint := range baseKeyspace.NoIndexHints() {
+			if hint.State() != algebra.HINT_STATE_UNKNOWN {
+				continue
+			}
+			ksterm := algebra.GetKeyspaceTerm(baseKeyspace.Node())
+			if noIndexConflict(hint.(*algebra.HintNoIndex), ksterm.Indexes()) {
+				hint.SetError(algebra.NO_INDEX_HINT_CONFLICT + keyspace)
+			} else {
+				hint.SetFollowed()
+			}
+		}
+	}
+}
+
+func noIndexConflict(hint *algebra.HintNoIndex, indexes algebra.IndexRefs) bool {
+	for _, idx := range indexes {
+		switch idx.Using() {
+		case datastore.DEFAULT, datastore.GSI:
+			if len(hint.Indexes()) == 0 {
+				return true
+			}
+			for _, noIdx := range hint.Indexes() {
+				if noIdx.Name() == idx.Name() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+/*
+Remove from indexes the ones excluded by NO_INDEX hints on the keyspace.
+NO_INDEX without index names excludes all secondary indexes other than
+search indexes.
+*/
+func avoidIndexes(baseKeyspace *base.BaseKeyspace, indexes []datastore.Index) []datastore.Index {
+	noIndexHints := baseKeyspace.NoIndexHints()
+	if len(noIndexHints) == 0 {
+		return indexes
+	}
+
+	rv := indexes[:0]
+	for _, index := range indexes {
+		if !avoidIndex(noIndexHints, index) {
+			rv = append(rv, index)
+		}
+	}
+	return rv
+}
+
+func avoidIndex(noIndexHints []algebra.OptimHint, index datastore.Index) bool {
+	for _, hint := range noIndexHints {
+		if hint.State() != algebra.HINT_STATE_FOLLOWED {
+			continue
+		}
+		noIndexes := hint.(*algebra.HintNoIndex).Indexes()
+		if len(noIndexes) == 0 {
+			if !index.IsPrimary() && index.Type() != datastore.FTS {
+				return true
+			}
+			continue
+		}
+		for _, noIdx := range noIndexes {
+			if noIdx.Name() == index.Name() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+INDEX_COMBINE or NO_INDEX_COMBINE for the keyspace, HINT_INVALID if
+there is neither, or the hint is in error.
+*/
+func (this *builder) combineHint(alias string) algebra.HintType {
+	baseKeyspace, ok := this.baseKeyspaces[alias]
+	if !ok {
+		return algebra.HINT_INVALID
+	}
+	combineHints := baseKeyspace.CombineHints()
+	if len(combineHints) != 1 || combineHints[0].State() == algebra.HINT_STATE_ERROR {
+		return algebra.HINT_INVALID
+	}
+	return combineHints[0].Type()
+}
+
+// whether the scan built for the keyspace follows its index combine hint
+func (this *builder) checkCombineHint(alias string, op plan.Operator) {
+	hintType := this.combineHint(alias)
+	if hintType == algebra.HINT_INVALID {
+		return
+	}
+	baseKeyspace := this.baseKeyspaces[alias]
+	scan, ok := op.(plan.SecondaryScan)
+	combined := ok && scan.GetIndex() == nil
+	if combined == (hintType == algebra.HINT_INDEX_COMBINE) {
+		baseKeyspace.UnsetCombineHintError()
+	} else {
+		baseKeyspace.SetCombineHintError()
+	}
+}
+
+/*
+The parallelism of the PARALLEL hint of a query block, 0 if none.
+PARALLEL is always followed, unless duplicated.
+*/
+func parallelHint(optHints *algebra.OptimHints) int {
+	if optHints == nil {
+		return 0
+	}
+
+	var parallel *algebra.HintParallel
+	for _, hint := range optHints.Hints() {
+		if hint, ok := hint.(*algebra.HintParallel); ok {
+			if parallel != nil {
+				parallel.SetError(algebra.DUPLICATED_PARALLEL_HINT)
+				hint.SetError(algebra.DUPLICATED_PARALLEL_HINT)
+				continue
+			}
+			parallel = hint
+		}
+	}
+	if parallel == nil || parallel.State() == algebra.HINT_STATE_ERROR {
+		return 0
+	}
+	parallel.SetFollowed()
+	return parallel.Parallelism()
+}
+
+/*
+Hints of UPDATE, DELETE and MERGE. The PARALLEL hint applies to the
+mutation and to the query blocks it contains; the other hints are not
+followed, as the mutated keyspace takes its index from USE INDEX.
+*/
+func (this *builder) dmlOptimHints(optHints *algebra.OptimHints) {
+	if optHints == nil {
+		return
+	}
+
+	if parallel := parallelHint(optHints); parallel > 0 {
+		this.parallelHint = parallel
+	}
+	for _, hint := range optHints.Hints() {
+		hint.SetNotFollowed()
 	}
 }
 
@@ -216,6 +408,18 @@ func hasOrderedHint(optHints *algebra.OptimHints) bool {
 	return false
 }
 
+// join enumeration is not done once the LEADING hint has reordered the joins
+func hasLeadingHint(optHints *algebra.OptimHints) bool {
+	if optHints != nil {
+		for _, hint := range optHints.Hints() {
+			if hint.Type() == algebra.HINT_LEADING && hint.State() == algebra.HINT_STATE_FOLLOWED {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func setDuplicateIndexHintError(hint algebra.OptimHint, keyspace string) {
 	switch hint := hint.(type) {
 	case *algebra.HintIndex:
@@ -247,29 +451,23 @@ func (this *builder) markOptimHints(alias string) (err error) {
 		return errors.NewPlanInternalError("markOptimHintErrors: invalid alias specified: " + alias)
 	}
 
-	indexHintError := baseKeyspace.HasIndexHintError()
-	for _, hint := range baseKeyspace.IndexHints() {
-		switch hint.State() {
-		case algebra.HINT_STATE_ERROR, algebra.HINT_STATE_INVALID, algebra.HINT_STATE_FOLLOWED, algebra.HINT_STATE_NOT_FOLLOWED:
-			// nothing to do
-		case algebra.HINT_STATE_UNKNOWN:
-			if indexHintError {
-				hint.SetNotFollowed()
-			} else {
-				hint.SetFollowed()
-			}
-		default:
-			return errors.NewPlanInternalError("markOptimHints: invalid hint state")
-		}
+	err = markHints(baseKeyspace.IndexHints(), baseKeyspace.HasIndexHintError())
+	if err == nil {
+		err = markHints(baseKeyspace.JoinHints(), baseKeyspace.HasJoinHintError())
+	}
+	if err == nil {
+		err = markHints(baseKeyspace.CombineHints(), baseKeyspace.HasCombineHintError())
 	}
+	return err
+}
 
-	joinHintError := baseKeyspace.HasJoinHintError()
-	for _, hint := range baseKeyspace.JoinHints() {
+func markHints(hints []algebra.OptimHint, hintError bool) error {
+	for _, hint := range hints {
 		switch hint.State() {
 		case algebra.HINT_STATE_ERROR, algebra.HINT_STATE_INVALID, algebra.HINT_STATE_FOLLOWED, algebra.HINT_STATE_NOT_FOLLOWED:
 			// nothing to do
 		case algebra.HINT_STATE_UNKNOWN:
-			if joinHintError {
+			if hintError {
 				hint.SetNotFollowed()
 			} else {
 				hint.SetFollowed()
@@ -278,6 +476,5 @@ func (this *builder) markOptimHints(alias string) (err error) {
 			return errors.NewPlanInternalError("markOptimHints: invalid hint state")
 		}
 	}
-
 	return nil
 }
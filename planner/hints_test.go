@@ -0,0 +1,57 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/algebra"
+)
+
+func TestParallelHint(t *testing.T) {
+	if p := parallelHint(nil); p != 0 {
+		t.Errorf("Expected no parallelism without hints, got %d", p)
+	}
+
+	hint := algebra.NewParallelHint(4)
+	optHints := algebra.NewOptimHints([]algebra.OptimHint{algebra.NewOrderedHint(), hint}, false)
+	if p := parallelHint(optHints); p != 4 || hint.State() != algebra.HINT_STATE_FOLLOWED {
+		t.Errorf("Expected followed parallelism 4, got %d and state %v", p, hint.State())
+	}
+
+	first, second := algebra.NewParallelHint(4), algebra.NewParallelHint(2)
+	optHints = algebra.NewOptimHints([]algebra.OptimHint{first, second}, false)
+	if p := parallelHint(optHints); p != 0 {
+		t.Errorf("Expected no parallelism with duplicated hints, got %d", p)
+	}
+	if first.State() != algebra.HINT_STATE_ERROR || second.State() != algebra.HINT_STATE_ERROR {
+		t.Errorf("Expected duplicated hints in error, got %v and %v", first.State(), second.State())
+	}
+}
+
+func TestDmlOptimHints(t *testing.T) {
+	parallel := algebra.NewParallelHint(3)
+	index := algebra.NewIndexHint("orders", nil)
+	leading := algebra.NewLeadingHint([]string{"orders"})
+	optHints := algebra.NewOptimHints([]algebra.OptimHint{index, parallel, leading}, false)
+
+	builder := &builder{}
+	builder.dmlOptimHints(optHints)
+	if builder.parallelHint != 3 {
+		t.Errorf("Expected parallelism 3, got %d", builder.parallelHint)
+	}
+	if parallel.State() != algebra.HINT_STATE_FOLLOWED {
+		t.Errorf("Expected PARALLEL followed, got %v", parallel.State())
+	}
+	for _, hint := range []algebra.OptimHint{index, leading} {
+		if hint.State() != algebra.HINT_STATE_NOT_FOLLOWED {
+			t.Errorf("Expected %v not followed, got %v", hint, hint.State())
+		}
+	}
+}
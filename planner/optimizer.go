@@ -246,10 +246,7 @@ func (this *builder) buildJoinOp(join *algebra.AnsiJoin, nest *algebra.AnsiNest,
 			if err != nil || hjoin == nil {
 				return nil, nil, nil, nil, err
 			}
-			if len(this.subChildren) > 0 {
-				this.addChildren(this.addSubchildrenParallel())
-			}
-			this.addChildren(hjoin)
+			this.addHashOp(hjoin, hjoin.Child())
 		} else {
 			nljoin, err := this.buildAnsiJoin(join)
 			if err != nil || nljoin == nil {
@@ -276,10 +273,7 @@ func (this *builder) buildJoinOp(join *algebra.AnsiJoin, nest *algebra.AnsiNest,
 			if err != nil || hnest == nil {
 				return nil, nil, nil, nil, err
 			}
-			if len(this.subChildren) > 0 {
-				this.addChildren(this.addSubchildrenParallel())
-			}
-			this.addChildren(hnest)
+			this.addHashOp(hnest, hnest.Child())
 		} else {
 			nlnest, err := this.buildAnsiNest(nest)
 			if err != nil || nlnest == nil {
@@ -220,6 +220,8 @@ func (this *sarg) VisitFunction(pred expression.Function) (interface{}, error) {
 	switch pred := pred.(type) {
 	case *expression.RegexpLike:
 		return this.visitLike(pred)
+	case expression.GeoPredicate:
+		return this.visitGeo(pred)
 	}
 
 	return this.visitDefault(pred)
@@ -0,0 +1,116 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"sort"
+
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/expression"
+	"github.com/couchbase/query/plan"
+	base "github.com/couchbase/query/plannerbase"
+	"github.com/couchbase/query/value"
+)
+
+/*
+A geospatial predicate on an ST_GEOHASH() key. The key of a matching
+geometry is the cell containing it, so it either falls inside one of
+the cells covering the region or is one of their ancestors: each
+cover cell contributes a prefix range, and each proper prefix an
+equality span.
+*/
+func (this *sarg) visitGeo(pred expression.GeoPredicate) (interface{}, error) {
+	if len(this.context.NamedArgs()) > 0 || len(this.context.PositionalArgs()) > 0 {
+		replaced, err := base.ReplaceParameters(pred, this.context.NamedArgs(), this.context.PositionalArgs())
+		if err != nil {
+			return nil, err
+		}
+		if repFunc, ok := replaced.(expression.GeoPredicate); ok {
+			pred = repFunc
+		}
+	}
+
+	if base.SubsetOf(pred, this.key) {
+		if expression.Equivalent(pred, this.key) {
+			return _EXACT_SELF_SPANS, nil
+		}
+		return _SELF_SPANS, nil
+	}
+
+	geohash, ok := this.key.(*expression.STGeohash)
+	if !ok {
+		return this.visitDefault(pred)
+	}
+
+	var region expression.Expression
+	first, second := pred.GeoOperands()
+	if first.EquivalentTo(geohash.Geometry()) {
+		region = second
+	} else if second.EquivalentTo(geohash.Geometry()) {
+		region = first
+	} else {
+		return this.visitDefault(pred)
+	}
+
+	precision, ok := geohash.Precision()
+	regionVal := region.Value()
+	if !ok || regionVal == nil {
+		return _VALUED_SPANS, nil
+	}
+
+	distance := 0.0
+	if dist := pred.GeoDistance(); dist != nil {
+		distVal := dist.Value()
+		if distVal == nil || distVal.Type() != value.NUMBER {
+			return _VALUED_SPANS, nil
+		}
+		distance = distVal.Actual().(float64)
+	}
+
+	cells, ok := expression.GeoCover(regionVal, distance, precision)
+	if !ok {
+		return _VALUED_SPANS, nil
+	}
+
+	selec := this.getSelec(pred)
+	prefixes := make(map[string]bool, len(cells))
+	spans := make(plan.Spans2, 0, 2*len(cells))
+	for _, cell := range cells {
+		if prefixes[cell] {
+			continue
+		}
+		prefixes[cell] = true
+
+		high := []byte(cell)
+		high[len(high)-1]++
+		range2 := plan.NewRange2(expression.NewConstant(cell), expression.NewConstant(string(high)),
+			datastore.LOW, selec, OPT_SELEC_NOT_AVAIL, 0)
+		spans = append(spans, plan.NewSpan2(nil, plan.Ranges2{range2}, false))
+	}
+
+	ancestors := make([]string, 0, len(prefixes))
+	for cell, _ := range prefixes {
+		for i := 0; i < len(cell); i++ {
+			ancestors = append(ancestors, cell[:i])
+		}
+	}
+	sort.Strings(ancestors)
+
+	for i, ancestor := range ancestors {
+		if prefixes[ancestor] || (i > 0 && ancestors[i-1] == ancestor) {
+			continue
+		}
+
+		expr := expression.NewConstant(ancestor)
+		range2 := plan.NewRange2(expr, expr, datastore.BOTH, selec, OPT_SELEC_NOT_AVAIL, 0)
+		spans = append(spans, plan.NewSpan2(nil, plan.Ranges2{range2}, false))
+	}
+
+	return NewTermSpans(spans...), nil
+}
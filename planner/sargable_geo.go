@@ -0,0 +1,24 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package planner
+
+import (
+	"github.com/couchbase/query/expression"
+)
+
+func (this *sargable) visitGeo(pred expression.GeoPredicate) (bool, error) {
+	if geohash, ok := this.key.(*expression.STGeohash); ok {
+		first, second := pred.GeoOperands()
+		if first.EquivalentTo(geohash.Geometry()) || second.EquivalentTo(geohash.Geometry()) {
+			return true, nil
+		}
+	}
+
+	return this.defaultSargable(pred), nil
+}
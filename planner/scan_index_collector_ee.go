@@ -231,6 +231,14 @@ func (this *scanIdxCol) VisitHashNest(op *plan.HashNest) (interface{}, error) {
 	return nil, nil
 }
 
+func (this *scanIdxCol) VisitMergeJoin(op *plan.MergeJoin) (interface{}, error) {
+	return nil, nil
+}
+
+func (this *scanIdxCol) VisitMergeNest(op *plan.MergeNest) (interface{}, error) {
+	return nil, nil
+}
+
 // Let + Letting, With
 func (this *scanIdxCol) VisitLet(op *plan.Let) (interface{}, error) {
 	return nil, nil
@@ -425,6 +433,28 @@ func (this *scanIdxCol) VisitFlushCollection(op *plan.FlushCollection) (interfac
 	return nil, nil
 }
 
+// Materialized views
+func (this *scanIdxCol) VisitCreateMaterializedView(op *plan.CreateMaterializedView) (interface{}, error) {
+	return nil, nil
+}
+
+func (this *scanIdxCol) VisitDropMaterializedView(op *plan.DropMaterializedView) (interface{}, error) {
+	return nil, nil
+}
+
+func (this *scanIdxCol) VisitRefreshMaterializedView(op *plan.RefreshMaterializedView) (interface{}, error) {
+	return nil, nil
+}
+
+// Triggers
+func (this *scanIdxCol) VisitCreateTrigger(op *plan.CreateTrigger) (interface{}, error) {
+	return nil, nil
+}
+
+func (this *scanIdxCol) VisitDropTrigger(op *plan.DropTrigger) (interface{}, error) {
+	return nil, nil
+}
+
 // Roles
 func (this *scanIdxCol) VisitGrantRole(op *plan.GrantRole) (interface{}, error) {
 	return nil, nil
@@ -433,6 +463,23 @@ func (this *scanIdxCol) VisitRevokeRole(op *plan.RevokeRole) (interface{}, error
 	return nil, nil
 }
 
+// Users and groups
+func (this *scanIdxCol) VisitCreateUser(op *plan.CreateUser) (interface{}, error) {
+	return nil, nil
+}
+func (this *scanIdxCol) VisitAlterUser(op *plan.AlterUser) (interface{}, error) {
+	return nil, nil
+}
+func (this *scanIdxCol) VisitDropUser(op *plan.DropUser) (interface{}, error) {
+	return nil, nil
+}
+func (this *scanIdxCol) VisitCreateGroup(op *plan.CreateGroup) (interface{}, error) {
+	return nil, nil
+}
+func (this *scanIdxCol) VisitDropGroup(op *plan.DropGroup) (interface{}, error) {
+	return nil, nil
+}
+
 // Explain
 func (this *scanIdxCol) VisitExplain(op *plan.Explain) (interface{}, error) {
 	return nil, nil
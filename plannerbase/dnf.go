@@ -13,6 +13,7 @@ import (
 
 	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/value"
 )
 
 type DNF struct {
@@ -49,6 +50,24 @@ func (this *DNF) VisitLike(expr *expression.Like) (interface{}, error) {
 	return this.visitLike(expr)
 }
 
+func (this *DNF) VisitLT(expr *expression.LT) (interface{}, error) {
+	err := expr.MapChildren(this)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.visitSimilarity(expr, expr.First(), expr.Second(), false)
+}
+
+func (this *DNF) VisitLE(expr *expression.LE) (interface{}, error) {
+	err := expr.MapChildren(this)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.visitSimilarity(expr, expr.First(), expr.Second(), true)
+}
+
 /*
 Convert to Disjunctive Normal Form.
 
@@ -393,6 +412,37 @@ func (this *DNF) visitLike(expr expression.LikeFunction) (interface{}, error) {
 	return and, nil
 }
 
+/*
+TRIGRAM_SIMILARITY(expr, static) > k, for k >= 0, implies that expr
+and static share a trigram. The implied ANY predicate is added so that
+an array index on NGRAMS(expr, 3) can be used.
+*/
+func (this *DNF) visitSimilarity(expr expression.Function, bound, similarity expression.Expression,
+	inclusive bool) (interface{}, error) {
+
+	sim, ok := similarity.(*expression.TrigramSimilarity)
+	if !ok || expr.HasExprFlag(expression.EXPR_DERIVED_NGRAMS) {
+		return expr, nil
+	}
+
+	val := bound.Value()
+	if val == nil || val.Type() != value.NUMBER {
+		return expr, nil
+	}
+	k := value.AsNumberValue(val).Float64()
+	if k < 0.0 || (inclusive && k <= 0.0) {
+		return expr, nil
+	}
+
+	any := sim.NGramPredicate()
+	if any == nil {
+		return expr, nil
+	}
+
+	expr.SetExprFlag(expression.EXPR_DERIVED_NGRAMS)
+	return expression.NewAnd(expr, any), nil
+}
+
 const _MAX_DNF_COMPLEXITY = 1024
 
 var _EXPRESSIONS_POOL = expression.NewExpressionsPool(_MAX_DNF_COMPLEXITY)
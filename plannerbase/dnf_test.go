@@ -0,0 +1,44 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package plannerbase
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/expression"
+)
+
+func TestSimilarityNGrams(t *testing.T) {
+	name := expression.NewField(expression.NewIdentifier("c"), expression.NewFieldName("name", false))
+	bounds := []interface{}{0.5, 0.0, 1, 0}
+
+	for _, bound := range bounds {
+		sim := expression.NewTrigramSimilarity(name, expression.NewConstant("couchbase"))
+		pred := expression.NewGT(sim, expression.NewConstant(bound))
+		dnf := NewDNF(pred, true, true)
+		res, err := dnf.Map(pred)
+		if err != nil {
+			t.Fatalf("Unexpected error for bound %v: %v", bound, err)
+		}
+		if _, ok := res.(*expression.And); !ok {
+			t.Errorf("Expected an implied ngram predicate for bound %v, got %v", bound, res)
+		}
+	}
+
+	// a negative bound does not imply a shared trigram
+	sim := expression.NewTrigramSimilarity(name, expression.NewConstant("couchbase"))
+	pred := expression.NewGT(sim, expression.NewConstant(-1))
+	res, err := NewDNF(pred, true, true).Map(pred)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := res.(*expression.And); ok {
+		t.Errorf("Expected no implied ngram predicate for a negative bound, got %v", res)
+	}
+}
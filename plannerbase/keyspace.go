@@ -15,17 +15,21 @@ import (
 )
 
 const (
-	KS_PLAN_DONE        = 1 << iota // planning is done for this keyspace
-	KS_ONCLAUSE_ONLY                // use ON-clause only for planning
-	KS_IS_UNNEST                    // unnest alias
-	KS_IN_CORR_SUBQ                 // in correlated subquery
-	KS_HAS_DOC_COUNT                // docCount retrieved for keyspace
-	KS_PRIMARY_TERM                 // primary term
-	KS_OUTER_FILTERS                // OUTER filters have been classified
-	KS_INDEX_HINT_ERROR             // index hint error
-	KS_JOIN_HINT_ERROR              // join hint error
+	KS_PLAN_DONE          = 1 << iota // planning is done for this keyspace
+	KS_ONCLAUSE_ONLY                  // use ON-clause only for planning
+	KS_IS_UNNEST                      // unnest alias
+	KS_IN_CORR_SUBQ                   // in correlated subquery
+	KS_HAS_DOC_COUNT                  // docCount retrieved for keyspace
+	KS_PRIMARY_TERM                   // primary term
+	KS_OUTER_FILTERS                  // OUTER filters have been classified
+	KS_INDEX_HINT_ERROR               // index hint error
+	KS_JOIN_HINT_ERROR                // join hint error
+	KS_COMBINE_HINT_ERROR             // index combine hint error
 )
 
+// errors of the hints on the scan of a keyspace
+const KS_SCAN_HINT_ERRORS = (KS_INDEX_HINT_ERROR | KS_COMBINE_HINT_ERROR)
+
 type BaseKeyspace struct {
 	name          string
 	keyspace      string
@@ -43,6 +47,8 @@ type BaseKeyspace struct {
 	optBit        int32
 	indexHints    []algebra.OptimHint
 	joinHints     []algebra.OptimHint
+	noIndexHints  []algebra.OptimHint
+	combineHints  []algebra.OptimHint
 }
 
 func NewBaseKeyspace(name string, path *algebra.Path, node algebra.SimpleFromTerm,
@@ -205,6 +211,20 @@ func copyBaseKeyspaces(src map[string]*BaseKeyspace, copyFilter bool) map[string
 			}
 			dest[kspace.name].joinHints = joinHints
 		}
+		if len(kspace.noIndexHints) > 0 {
+			noIndexHints := make([]algebra.OptimHint, 0, len(kspace.noIndexHints))
+			for _, hint := range kspace.noIndexHints {
+				noIndexHints = append(noIndexHints, hint)
+			}
+			dest[kspace.name].noIndexHints = noIndexHints
+		}
+		if len(kspace.combineHints) > 0 {
+			combineHints := make([]algebra.OptimHint, 0, len(kspace.combineHints))
+			for _, hint := range kspace.combineHints {
+				combineHints = append(combineHints, hint)
+			}
+			dest[kspace.name].combineHints = combineHints
+		}
 		if copyFilter {
 			if len(kspace.filters) > 0 {
 				dest[kspace.name].filters = kspace.filters.Copy()
@@ -359,6 +379,22 @@ func (this *BaseKeyspace) JoinHints() []algebra.OptimHint {
 	return this.joinHints
 }
 
+func (this *BaseKeyspace) AddNoIndexHint(noIndexHint algebra.OptimHint) {
+	this.noIndexHints = append(this.noIndexHints, noIndexHint)
+}
+
+func (this *BaseKeyspace) NoIndexHints() []algebra.OptimHint {
+	return this.noIndexHints
+}
+
+func (this *BaseKeyspace) AddCombineHint(combineHint algebra.OptimHint) {
+	this.combineHints = append(this.combineHints, combineHint)
+}
+
+func (this *BaseKeyspace) CombineHints() []algebra.OptimHint {
+	return this.combineHints
+}
+
 func (this *BaseKeyspace) HasIndexHintError() bool {
 	return (this.ksFlags & KS_INDEX_HINT_ERROR) != 0
 }
@@ -383,6 +419,28 @@ func (this *BaseKeyspace) UnsetJoinHintError() {
 	this.ksFlags &^= KS_JOIN_HINT_ERROR
 }
 
+func (this *BaseKeyspace) HasCombineHintError() bool {
+	return (this.ksFlags & KS_COMBINE_HINT_ERROR) != 0
+}
+
+func (this *BaseKeyspace) SetCombineHintError() {
+	this.ksFlags |= KS_COMBINE_HINT_ERROR
+}
+
+func (this *BaseKeyspace) UnsetCombineHintError() {
+	this.ksFlags &^= KS_COMBINE_HINT_ERROR
+}
+
+// the index and index combine hint errors of the last scan built for the keyspace,
+// saved and restored when alternative join methods are considered
+func (this *BaseKeyspace) ScanHintErrors() uint32 {
+	return this.ksFlags & KS_SCAN_HINT_ERRORS
+}
+
+func (this *BaseKeyspace) SetScanHintErrors(errs uint32) {
+	this.ksFlags = (this.ksFlags &^ KS_SCAN_HINT_ERRORS) | (errs & KS_SCAN_HINT_ERRORS)
+}
+
 func (this *BaseKeyspace) MarkHashUnavailable() {
 	for _, hint := range this.joinHints {
 		if hint.Type() == algebra.HINT_HASH {
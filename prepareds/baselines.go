@@ -0,0 +1,155 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package prepareds
+
+import (
+	"fmt"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/parser/n1ql"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/planner"
+	"github.com/couchbase/query/rewrite"
+	"github.com/couchbase/query/semantics"
+	"github.com/couchbase/query/util"
+)
+
+/*
+Plan a statement and pin its plan as the named baseline, replacing
+any baseline of the same name. Evolving a baseline is capturing it
+again.
+
+The statement is either given, or is that of a prepared statement:
+the text of a PREPARE is the text of the statement it prepares. As a
+baseline applies to whoever runs the statement, the caller must hold
+the privileges to run it.
+*/
+func CaptureBaseline(name, statement, preparedName, queryContext, namespace string,
+	creds *auth.Credentials) (*plan.Baseline, errors.Error) {
+	if preparedName != "" {
+		prepared, err := GetPreparedWithContext(preparedName, queryContext, nil, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		statement = prepared.Text()
+		queryContext = prepared.QueryContext()
+		namespace = prepared.Namespace()
+	}
+
+	stmt, err := n1ql.ParseStatement2(statement, namespace, queryContext)
+	if err != nil {
+		return nil, errors.NewParseSyntaxError(err, "")
+	}
+	if prepare, ok := stmt.(*algebra.Prepare); ok {
+		statement = prepare.Text()[prepare.Offset():]
+		stmt = prepare.Statement()
+	}
+	if !planner.BaselineStatement(stmt.Type()) {
+		return nil, errors.NewBaselineError(nil, name, fmt.Sprintf("%s statements cannot have a baseline", stmt.Type()))
+	}
+
+	if _, err = stmt.Accept(rewrite.NewRewrite(rewrite.REWRITE_PHASE1)); err != nil {
+		return nil, errors.NewRewriteError(err, "")
+	}
+
+	semChecker := semantics.NewSemChecker(true, stmt.Type(), false)
+	_, err = stmt.Accept(semChecker)
+	if err != nil {
+		return nil, errors.NewSemanticsError(err, "")
+	}
+
+	privs, err1 := stmt.Privileges()
+	if err1 == nil {
+		_, err1 = datastore.GetDatastore().Authorize(privs, creds)
+	}
+	if err1 != nil {
+		return nil, err1
+	}
+
+	requestId, err := util.UUIDV4()
+	if err != nil {
+		return nil, errors.NewPlanError(nil, "request id is nil")
+	}
+
+	var optimizer planner.Optimizer
+	useCBO := util.GetUseCBO()
+	if useCBO {
+		optimizer = getNewOptimizer()
+	}
+
+	// the plan must not depend on args or credentials
+	var prepContext planner.PrepareContext
+	planner.NewPrepareContext(&prepContext, requestId, queryContext, nil, nil,
+		util.GetMaxIndexAPI(), util.GetN1qlFeatureControl(), false, useCBO, optimizer, nil, nil)
+
+	op, ik, err := planner.BuildStatement(stmt, store, systemstore, namespace, &prepContext)
+	if err != nil {
+		return nil, errors.NewPlanError(err, "")
+	}
+
+	baseline, err := plan.NewBaseline(name, statement, queryContext, namespace, op, ik)
+	if err != nil {
+		return nil, errors.NewBaselineError(err, name, "unable to encode plan")
+	}
+	ok, err := plan.GetBaselineStore().PutBaseline(baseline)
+	if err != nil {
+		return nil, errors.NewBaselineError(err, name, "unable to store the baseline")
+	}
+	if !ok {
+		return nil, errors.NewBaselineError(nil, name, "another baseline pins the statement")
+	}
+	return baseline, nil
+}
+
+/*
+Accept or reject a baseline.
+*/
+func SetBaselineState(name, state string) errors.Error {
+	if state != plan.BASELINE_ACCEPTED && state != plan.BASELINE_REJECTED {
+		return errors.NewBaselineError(nil, name, fmt.Sprintf("state must be %s or %s",
+			plan.BASELINE_ACCEPTED, plan.BASELINE_REJECTED))
+	}
+	baselines := plan.GetBaselineStore()
+	baseline, ok := baselines.Baseline(name)
+	if !ok {
+		return errors.NewNoSuchBaselineError(name)
+	}
+	if baseline.State() != state {
+		_, err := baselines.PutBaseline(baseline.WithState(state))
+		if err != nil {
+			return errors.NewBaselineError(err, name, "unable to store the baseline")
+		}
+	}
+	return nil
+}
+
+/*
+Capture a baseline again, with the plan the optimizer chooses now.
+*/
+func EvolveBaseline(name string, creds *auth.Credentials) (*plan.Baseline, errors.Error) {
+	baseline, ok := plan.GetBaselineStore().Baseline(name)
+	if !ok {
+		return nil, errors.NewNoSuchBaselineError(name)
+	}
+	return CaptureBaseline(name, baseline.Text(), "", baseline.QueryContext(), baseline.Namespace(), creds)
+}
+
+func DropBaseline(name string) errors.Error {
+	baseline, err := plan.GetBaselineStore().DropBaseline(name)
+	if err != nil {
+		return errors.NewBaselineError(err, name, "unable to drop the baseline")
+	}
+	if baseline == nil {
+		return errors.NewNoSuchBaselineError(name)
+	}
+	return nil
+}
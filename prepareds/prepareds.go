@@ -756,7 +756,9 @@ func reprepare(prepared *plan.Prepared, deltaKeyspaces map[string]bool, phaseTim
 		prepared.IndexApiVersion(), prepared.FeatureControls(), prepared.UseFts(), prepared.UseCBO(),
 		optimizer, deltaKeyspaces, nil)
 
-	pl, err := planner.BuildPrepared(stmt.(*algebra.Prepare).Statement(), store, systemstore, prepared.Namespace(),
+	prepare := stmt.(*algebra.Prepare)
+	prepContext.SetBaselineText(prepare.Text()[prepare.Offset():])
+	pl, err := planner.BuildPrepared(prepare.Statement(), store, systemstore, prepared.Namespace(),
 		false, true, &prepContext)
 	if phaseTime != nil {
 		*phaseTime += time.Since(prep)
@@ -12,13 +12,16 @@ package couchbase
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 type User struct {
-	Name   string
-	Id     string
-	Domain string
-	Roles  []Role
+	Name     string
+	Id       string
+	Domain   string
+	Roles    []Role
+	Groups   []string
+	Password string `json:"-"`
 }
 
 type Role struct {
@@ -26,6 +29,20 @@ type Role struct {
 	BucketName     string `json:"bucket_name"`
 	ScopeName      string `json:"scope_name"`
 	CollectionName string `json:"collection_name"`
+	Origins        []RoleOrigin
+}
+
+// Where a user got a role from: the user itself, or one of its groups.
+// Roles without origins belong to users that are in no group.
+type RoleOrigin struct {
+	Type string
+	Name string
+}
+
+type Group struct {
+	Id          string
+	Description string
+	Roles       []Role
 }
 
 // Sample:
@@ -101,25 +118,69 @@ func rolesToParamFormat(roles []Role) string {
 	return buffer.String()
 }
 
-func (c *Client) PutUserInfo(u *User) error {
-	params := map[string]interface{}{
-		"name":  u.Name,
-		"roles": rolesToParamFormat(u.Roles),
-	}
-	var target string
+func userTarget(u *User) (string, error) {
 	switch u.Domain {
 	case "external":
-		target = "/settings/rbac/users/" + u.Id
+		return "/settings/rbac/users/" + u.Id, nil
 	case "local":
-		target = "/settings/rbac/users/local/" + u.Id
+		return "/settings/rbac/users/local/" + u.Id, nil
 	default:
-		return fmt.Errorf("Unknown user type: %s", u.Domain)
+		return "", fmt.Errorf("Unknown user type: %s", u.Domain)
+	}
+}
+
+func (c *Client) PutUserInfo(u *User) error {
+	params := map[string]interface{}{
+		"name":   u.Name,
+		"roles":  rolesToParamFormat(u.Roles),
+		"groups": strings.Join(u.Groups, ","),
+	}
+
+	// an existing user keeps its password if none is sent
+	if u.Password != "" {
+		params["password"] = u.Password
+	}
+	target, err := userTarget(u)
+	if err != nil {
+		return err
 	}
 	var ret string // PUT returns an empty string. We ignore it.
-	err := c.parsePutURLResponse(target, params, &ret)
+	err = c.parsePutURLResponse(target, params, &ret)
 	return err
 }
 
+func (c *Client) DeleteUserInfo(u *User) error {
+	target, err := userTarget(u)
+	if err != nil {
+		return err
+	}
+	var ret string
+	return c.parseDeleteURLResponseTerse(target, nil, &ret)
+}
+
+func (c *Client) GetGroupInfoAll() ([]Group, error) {
+	ret := make([]Group, 0, 16)
+	err := c.parseURLResponse("/settings/rbac/groups", &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (c *Client) PutGroupInfo(g *Group) error {
+	params := map[string]interface{}{
+		"description": g.Description,
+		"roles":       rolesToParamFormat(g.Roles),
+	}
+	var ret string
+	return c.parsePutURLResponse("/settings/rbac/groups/"+g.Id, params, &ret)
+}
+
+func (c *Client) DeleteGroupInfo(g *Group) error {
+	var ret string
+	return c.parseDeleteURLResponseTerse("/settings/rbac/groups/"+g.Id, nil, &ret)
+}
+
 func (c *Client) GetRolesAll() ([]RoleDescription, error) {
 	ret := make([]RoleDescription, 0, 32)
 	err := c.parseURLResponse("/settings/rbac/roles", &ret)
@@ -52,6 +52,26 @@ func (this *Rewrite) VisitFlushCollection(stmt *algebra.FlushCollection) (interf
 	return stmt, stmt.MapExpressions(this)
 }
 
+func (this *Rewrite) VisitCreateMaterializedView(stmt *algebra.CreateMaterializedView) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitRefreshMaterializedView(stmt *algebra.RefreshMaterializedView) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitDropMaterializedView(stmt *algebra.DropMaterializedView) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitCreateTrigger(stmt *algebra.CreateTrigger) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitDropTrigger(stmt *algebra.DropTrigger) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
 func (this *Rewrite) VisitCreateFunction(stmt *algebra.CreateFunction) (interface{}, error) {
 	return stmt, stmt.MapExpressions(this)
 }
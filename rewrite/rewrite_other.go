@@ -20,6 +20,26 @@ func (this *Rewrite) VisitRevokeRole(stmt *algebra.RevokeRole) (interface{}, err
 	return stmt, stmt.MapExpressions(this)
 }
 
+func (this *Rewrite) VisitCreateUser(stmt *algebra.CreateUser) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitAlterUser(stmt *algebra.AlterUser) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitDropUser(stmt *algebra.DropUser) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitCreateGroup(stmt *algebra.CreateGroup) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
+func (this *Rewrite) VisitDropGroup(stmt *algebra.DropGroup) (interface{}, error) {
+	return stmt, stmt.MapExpressions(this)
+}
+
 func (this *Rewrite) VisitExplain(stmt *algebra.Explain) (interface{}, error) {
 	return stmt.Statement().Accept(this)
 }
@@ -40,6 +40,7 @@ type TaskEntry struct {
 	StartTime time.Time
 	EndTime   time.Time
 	Delay     time.Duration
+	Interval  time.Duration
 	State     State
 	Results   interface{}
 	Errors    []errors.Error
@@ -108,11 +109,28 @@ func TaskDo(key string, f func(*TaskEntry)) {
 }
 
 // scheduler primitives
+func TaskId(name, class, subClass string) (string, errors.Error) {
+	id, err := util.UUIDV5(class+subClass, name)
+	if err != nil {
+		return "", errors.NewSchedulerError("uuid", err)
+	}
+	return id, nil
+}
+
 func ScheduleTask(name, class, subClass string, delay time.Duration, exec, stop TaskFunc, parms interface{}, context Context) errors.Error {
+	return scheduleTask(name, class, subClass, delay, 0, exec, stop, parms, context)
+}
 
-	id, err := util.UUIDV5(class+subClass, name)
+// repeating tasks run every interval, and stay scheduled until deleted
+func ScheduleRepeatingTask(name, class, subClass string, interval time.Duration, exec, stop TaskFunc, parms interface{}, context Context) errors.Error {
+	return scheduleTask(name, class, subClass, interval, interval, exec, stop, parms, context)
+}
+
+func scheduleTask(name, class, subClass string, delay, interval time.Duration, exec, stop TaskFunc, parms interface{}, context Context) errors.Error {
+
+	id, err := TaskId(name, class, subClass)
 	if err != nil {
-		return errors.NewSchedulerError("uuid", err)
+		return err
 	}
 
 	task := &TaskEntry{
@@ -120,6 +138,7 @@ func ScheduleTask(name, class, subClass string, delay time.Duration, exec, stop
 		Class:      class,
 		SubClass:   subClass,
 		Delay:      delay,
+		Interval:   interval,
 		PostTime:   time.Now(),
 		Exec:       exec,
 		Stop:       stop,
@@ -146,53 +165,71 @@ func ScheduleTask(name, class, subClass string, delay time.Duration, exec, stop
 	}
 
 	// and schedule execution
-	task.timer = time.AfterFunc(delay, func() {
-
-		// first, lock, check and mark as running
-		bailOut := false
-		scheduler.scheduled.Get(task.Id, func(ce interface{}) {
-			if task.State != SCHEDULED {
-				bailOut = true
-				return
-			}
-			task.State = RUNNING
-			task.StartTime = time.Now()
-		})
-		if bailOut {
+	task.timer = time.AfterFunc(delay, func() { runTask(task) })
+
+	return nil
+}
+
+func runTask(task *TaskEntry) {
+
+	// first, lock, check and mark as running
+	bailOut := false
+	scheduler.scheduled.Get(task.Id, func(ce interface{}) {
+		if task.State != SCHEDULED {
+			bailOut = true
 			return
 		}
+		task.State = RUNNING
+		task.StartTime = time.Now()
+	})
+	if bailOut {
+		return
+	}
 
-		// execute
-		res, errs := task.Exec(task.context, task.parameters)
+	// execute
+	res, errs := task.Exec(task.context, task.parameters)
 
-		// mark complete and remove from scheduled
-		scheduler.scheduled.Delete(task.Id, func(ce interface{}) {
-			task.State = COMPLETED
+	// repeating tasks go back to scheduled for their next run
+	repeat := false
+	scheduler.scheduled.Get(task.Id, func(ce interface{}) {
+		if task.Interval > 0 {
+			repeat = true
+			task.State = SCHEDULED
 			task.Results = res
 			task.Errors = errs
 			task.EndTime = time.Now()
+			task.timer = time.AfterFunc(task.Interval, func() { runTask(task) })
+		}
+	})
+	if repeat {
+		return
+	}
 
-			// now that we are done, ditch everything we don't need
-			task.Exec = nil
-			task.Stop = nil
-			task.context = nil
-			task.parameters = nil
-			task.timer = nil
-		})
-		scheduler.completed.Add(task, task.Id, func(ce interface{}) util.Operation {
-
-			// can't happen, but for completeness, ditch any previous run
-			return util.REPLACE
-		})
+	// mark complete and remove from scheduled
+	scheduler.scheduled.Delete(task.Id, func(ce interface{}) {
+		task.State = COMPLETED
+		task.Results = res
+		task.Errors = errs
+		task.EndTime = time.Now()
 
+		// now that we are done, ditch everything we don't need
+		task.Exec = nil
+		task.Stop = nil
+		task.context = nil
+		task.parameters = nil
+		task.timer = nil
 	})
+	scheduler.completed.Add(task, task.Id, func(ce interface{}) util.Operation {
 
-	return nil
+		// can't happen, but for completeness, ditch any previous run
+		return util.REPLACE
+	})
 }
 
 func DeleteTask(id string) errors.Error {
 	var task *TaskEntry
 	bailOut := false
+	stopped := false
 	deleted := false
 
 	_ = scheduler.scheduled.Get(id, func(ce interface{}) {
@@ -200,6 +237,11 @@ func DeleteTask(id string) errors.Error {
 		if task.State == SCHEDULED {
 			task.State = DELETING
 			task.timer.Stop()
+		} else if task.Interval > 0 {
+
+			// let the current run complete, but no further runs
+			task.Interval = 0
+			stopped = true
 		} else {
 			bailOut = true
 		}
@@ -209,6 +251,9 @@ func DeleteTask(id string) errors.Error {
 	if bailOut {
 		return errors.NewTaskRunningError(id)
 	}
+	if stopped {
+		return nil
+	}
 
 	// cleanup and remove
 	if task != nil {
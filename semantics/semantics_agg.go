@@ -9,10 +9,12 @@
 package semantics
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
 	"github.com/couchbase/query/value"
 )
 
@@ -50,6 +52,38 @@ func (this *SemChecker) visitAggregateFunction(agg algebra.Aggregate) (err error
 			"semantics.visit_aggregate_function.filter")
 	}
 
+	// Aggregate syntax has WITHIN GROUP, check it is present and has a single term where required
+	if algebra.AggregateHasProperty(aggName, algebra.AGGREGATE_REQUIRES_WITHIN_GROUP) {
+		if agg.WithinGroup() == nil {
+			return errors.NewSemanticsError(nil, fmt.Sprintf("%s requires WITHIN GROUP clause.", aggName))
+		} else if len(agg.WithinGroup().Terms()) != 1 {
+			return errors.NewSemanticsError(nil, fmt.Sprintf("%s WITHIN GROUP clause must have a single ORDER BY term.",
+				aggName))
+		}
+	}
+
+	// fraction argument must be a constant or parameter that evaluates to a number between 0 and 1
+	if algebra.AggregateHasProperty(agg.Name(), algebra.AGGREGATE_1ST_FRACTION) && len(agg.Operands()) > 0 {
+		if !fractionOperand(agg.Operands()[0]) {
+			return errors.NewSemanticsError(nil, fmt.Sprintf("%s first argument must be a constant number between 0 and 1.",
+				aggName))
+		}
+	}
+
+	if algebra.AggregateHasProperty(agg.Name(), algebra.AGGREGATE_2ND_FRACTION) && len(agg.Operands()) > 1 {
+		if !fractionOperand(agg.Operands()[1]) {
+			return errors.NewSemanticsError(nil, fmt.Sprintf("%s second argument must be a constant number between 0 and 1.",
+				aggName))
+		}
+	}
+
+	// second argument must be a constant or parameter
+	if algebra.AggregateHasProperty(agg.Name(), algebra.AGGREGATE_2ND_CONSTANT) && len(agg.Operands()) > 1 {
+		if op := agg.Operands()[1]; op == nil || op.Static() == nil {
+			return errors.NewSemanticsError(nil, fmt.Sprintf("%s second argument must be a constant.", aggName))
+		}
+	}
+
 	wTerm := agg.WindowTerm()
 	if wTerm == nil {
 		if algebra.AggregateHasProperty(aggName, algebra.AGGREGATE_ALLOWS_REGULAR) {
@@ -187,3 +221,13 @@ func (this *SemChecker) visitAggregateFunction(agg algebra.Aggregate) (err error
 	return nil
 
 }
+
+func fractionOperand(op expression.Expression) bool {
+	ok := (op != nil && op.Static() != nil)
+	if ok {
+		val := op.Value()
+		ok = (val == nil || (val.Type() == value.NUMBER && val.(value.NumberValue).Float64() >= 0.0 &&
+			val.(value.NumberValue).Float64() <= 1.0))
+	}
+	return ok
+}
@@ -26,6 +26,10 @@ func (this *SemChecker) VisitFunction(expr expression.Function) (interface{}, er
 		return expr, this.visitSearchFunction(nexpr)
 	case *expression.Advisor:
 		return expr, this.visitAdvisorFunction(nexpr)
+	case *expression.MergeAction:
+		if !this.hasSemFlag(_SEM_MERGE_RETURNING) {
+			return expr, errors.NewSemanticsError(nil, "MERGE_ACTION() function is allowed in RETURNING clause of MERGE only.")
+		}
 	case *expression.FlattenKeys:
 		if this.stmtType != "CREATE_INDEX" && this.stmtType != "UPDATE_STATISTICS" {
 			return expr, errors.NewFlattenKeys(nexpr.String(), nexpr.ErrorContext())
@@ -88,6 +88,26 @@ func (this *SemChecker) VisitFlushCollection(stmt *algebra.FlushCollection) (int
 	return nil, stmt.MapExpressions(this)
 }
 
+func (this *SemChecker) VisitCreateMaterializedView(stmt *algebra.CreateMaterializedView) (interface{}, error) {
+	return stmt.Query().Accept(this)
+}
+
+func (this *SemChecker) VisitRefreshMaterializedView(stmt *algebra.RefreshMaterializedView) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitDropMaterializedView(stmt *algebra.DropMaterializedView) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitCreateTrigger(stmt *algebra.CreateTrigger) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitDropTrigger(stmt *algebra.DropTrigger) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
 type CheckFlattenKeys struct {
 	expression.MapperBase
 	flattenKeys expression.Expression
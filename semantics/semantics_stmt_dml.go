@@ -11,6 +11,7 @@ package semantics
 import (
 	"github.com/couchbase/query/algebra"
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
 )
 
 func (this *SemChecker) VisitSelect(stmt *algebra.Select) (r interface{}, err error) {
@@ -191,6 +192,23 @@ func (this *SemChecker) VisitMerge(stmt *algebra.Merge) (r interface{}, err erro
 		}
 	}
 
+	if stmt.Returning() != nil {
+		this.setSemFlag(_SEM_MERGE_RETURNING)
+		err = stmt.Returning().MapExpressions(this)
+		this.unsetSemFlag(_SEM_MERGE_RETURNING)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// MERGE_ACTION() only has a value in the RETURNING clause
+	exprs := expression.Expressions{stmt.On(), stmt.Limit()}
+	for _, expr := range append(exprs, stmt.Actions().Expressions()...) {
+		if hasMergeAction(expr) {
+			return nil, errors.NewSemanticsError(nil, "MERGE_ACTION() function is allowed in RETURNING clause of MERGE only.")
+		}
+	}
+
 	if source.SubqueryTerm() != nil {
 		return source.SubqueryTerm().Accept(this)
 	} else if source.ExpressionTerm() != nil {
@@ -230,3 +248,18 @@ func (this *SemChecker) VisitMerge(stmt *algebra.Merge) (r interface{}, err erro
 
 	return nil, stmt.MapExpressions(this)
 }
+
+func hasMergeAction(expr expression.Expression) bool {
+	if expr == nil {
+		return false
+	}
+	if _, ok := expr.(*expression.MergeAction); ok {
+		return true
+	}
+	for _, child := range expr.Children() {
+		if hasMergeAction(child) {
+			return true
+		}
+	}
+	return false
+}
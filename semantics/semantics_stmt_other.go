@@ -22,6 +22,26 @@ func (this *SemChecker) VisitRevokeRole(stmt *algebra.RevokeRole) (interface{},
 	return nil, stmt.MapExpressions(this)
 }
 
+func (this *SemChecker) VisitCreateUser(stmt *algebra.CreateUser) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitAlterUser(stmt *algebra.AlterUser) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitDropUser(stmt *algebra.DropUser) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitCreateGroup(stmt *algebra.CreateGroup) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
+func (this *SemChecker) VisitDropGroup(stmt *algebra.DropGroup) (interface{}, error) {
+	return nil, stmt.MapExpressions(this)
+}
+
 func (this *SemChecker) VisitExplain(stmt *algebra.Explain) (interface{}, error) {
 	saveStmtType := stmt.Type()
 	defer func() { this.stmtType = saveStmtType }()
@@ -48,6 +68,11 @@ func (this *SemChecker) VisitAdvise(stmt *algebra.Advise) (interface{}, error) {
 }
 
 func (this *SemChecker) VisitPrepare(stmt *algebra.Prepare) (interface{}, error) {
+	// prepared statements are cached and shown, and would expose the password
+	if _, ok := algebra.RedactedText(stmt); ok {
+		return nil, errors.NewPreparePasswordError(stmt.Statement().Type())
+	}
+
 	saveStmtType := stmt.Type()
 	defer func() { this.stmtType = saveStmtType }()
 	this.stmtType = stmt.Statement().Type()
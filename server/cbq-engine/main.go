@@ -34,6 +34,7 @@ import (
 	"github.com/couchbase/query/logging"
 	"github.com/couchbase/query/logging/event"
 	log_resolver "github.com/couchbase/query/logging/resolver"
+	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/prepareds"
 	"github.com/couchbase/query/scheduler"
 	server_package "github.com/couchbase/query/server"
@@ -85,6 +86,7 @@ var HTTPS_ADDR = flag.String("https", _DEF_HTTPS, "HTTPS service address")
 var CA_FILE = flag.String("cafile", "", "HTTPS CA certificates")
 var CERT_FILE = flag.String("certfile", "", "HTTPS certificate chain file")
 var KEY_FILE = flag.String("keyfile", "", "HTTPS private key file")
+var KEYSTORE = flag.String("keystore", "", "File of named encryption keys for ENCRYPT and DECRYPT")
 
 var IPv6 = flag.String("ipv6", server_package.TCP_OPT, "Query is IPv6 compliant")
 var IPv4 = flag.String("ipv4", server_package.TCP_REQ, "Query uses IPv4 listeners only")
@@ -242,6 +244,16 @@ func main() {
 	}
 	datastore_package.SetDatastore(datastore)
 
+	if *KEYSTORE != "" {
+		keyStore, err := datastore_package.NewFileKeyStore(*KEYSTORE)
+		if err != nil {
+			logging.Errorf("%v", err.Error())
+			logging.Errorf("Shutting down.")
+			os.Exit(1)
+		}
+		datastore_package.SetKeyStore(keyStore)
+	}
+
 	nullSecurityConfig := &datastore_package.ConnectionSecurityConfig{}
 	datastore.SetConnectionSecurityConfig(nullSecurityConfig)
 
@@ -397,6 +409,12 @@ func main() {
 
 	constructor.Init(endpoint.Mux(), server.Servicers())
 
+	// like functions, query metadata is kept in metakv
+	plan.SetBaselineStore(plan.NewMetakvBaselineStore())
+	datastore_package.SetTriggerStore(datastore_package.NewMetakvTriggerStore(plan.EncodeTrigger, plan.DecodeTrigger))
+	datastore_package.SetMaterializedViewStore(datastore_package.NewMetakvMaterializedViewStore())
+	datastore_package.SetSchemaStore(datastore_package.NewMetakvSchemaStore())
+
 	// topology awareness
 	_ = control.NewManager(*UUID)
 
@@ -55,6 +55,7 @@ const (
 	CLEANUPLOSTATTEMPTS   = "cleanuplostattempts"
 	GCPERCENT             = "gc-percent"
 	REQUESTERRORLIMIT     = "request-error-limit"
+	RESOURCEGROUPS        = "resource-groups"
 )
 
 type Checker func(interface{}) (bool, errors.Error)
@@ -88,6 +89,7 @@ var CHECKERS = map[string]Checker{
 	CLEANUPLOSTATTEMPTS:   checkBool,
 	GCPERCENT:             checkNumber,
 	REQUESTERRORLIMIT:     checkNumber,
+	RESOURCEGROUPS:        checkResourceGroups,
 }
 
 var CHECKERS_MIN = map[string]int{
@@ -175,6 +177,30 @@ func checkCompleted(val interface{}) (bool, errors.Error) {
 	return ok, nil
 }
 
+func checkResourceGroups(val interface{}) (bool, errors.Error) {
+	object, ok := val.(map[string]interface{})
+	if !ok {
+		return ok, errors.NewAdminSettingTypeError(RESOURCEGROUPS, val)
+	}
+
+	for n, v := range object {
+		if n == "" {
+			return false, errors.NewAdminSettingTypeError(RESOURCEGROUPS, object)
+		}
+		switch n[0] {
+		case '-':
+			continue
+		case '+':
+			n = n[1:]
+		}
+		err := ResourceGroupCheck(n, v)
+		if err != nil {
+			return false, err
+		}
+	}
+	return ok, nil
+}
+
 func checkString(val interface{}) (bool, errors.Error) {
 	_, ok := val.(string)
 	return ok, nil
@@ -579,7 +579,7 @@ func LogRequest(request_time, service_time, transactionElapsedTime time.Duration
 			}
 		}
 	}
-	stmt := request.Statement()
+	stmt := request.RedactedStatement()
 	if stmt != "" {
 		re.Statement = stmt
 	}
@@ -31,6 +31,7 @@ import (
 	"github.com/couchbase/query/functions/bridge"
 	functionsMeta "github.com/couchbase/query/functions/metakv"
 	functionsResolver "github.com/couchbase/query/functions/resolver"
+	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/prepareds"
 	"github.com/couchbase/query/scheduler"
 	"github.com/couchbase/query/server"
@@ -48,6 +49,7 @@ const (
 	functionsPrefix       = adminPrefix + "/functions_cache"
 	dictionaryPrefix      = adminPrefix + "/dictionary_cache"
 	tasksPrefix           = adminPrefix + "/tasks_cache"
+	baselinesPrefix       = adminPrefix + "/plan_baselines"
 	indexesPrefix         = adminPrefix + "/indexes"
 	expvarsRoute          = "/debug/vars"
 	prometheusLow         = "/_prometheusMetrics"
@@ -131,6 +133,13 @@ func (this *HttpEndpoint) registerAccountingHandlers() {
 		this.wrapAPI(w, req, doTasks)
 	}
 
+	baselineHandler := func(w http.ResponseWriter, req *http.Request) {
+		this.wrapAPI(w, req, doPlanBaseline)
+	}
+	baselinesHandler := func(w http.ResponseWriter, req *http.Request) {
+		this.wrapAPI(w, req, doPlanBaselines)
+	}
+
 	prometheusLowHandler := func(w http.ResponseWriter, req *http.Request) {
 		this.wrapAPI(w, req, doPrometheusLow)
 	}
@@ -171,6 +180,8 @@ func (this *HttpEndpoint) registerAccountingHandlers() {
 		dictionaryPrefix + "/{name}":                      {handler: dictionaryEntryHandler, methods: []string{"GET", "POST", "DELETE"}},
 		tasksPrefix:                                       {handler: tasksHandler, methods: []string{"GET"}},
 		tasksPrefix + "/{name}":                           {handler: taskHandler, methods: []string{"GET", "POST", "DELETE"}},
+		baselinesPrefix:                                   {handler: baselinesHandler, methods: []string{"GET"}},
+		baselinesPrefix + "/{name}":                       {handler: baselineHandler, methods: []string{"GET", "POST", "DELETE", "PUT"}},
 		transactionsPrefix:                                {handler: transactionsHandler, methods: []string{"GET"}},
 		transactionsPrefix + "/{txid}":                    {handler: transactionHandler, methods: []string{"GET", "POST", "DELETE"}},
 		indexesPrefix + "/prepareds":                      {handler: preparedIndexHandler, methods: []string{"GET"}},
@@ -728,6 +739,140 @@ func doDictionary(endpoint *HttpEndpoint, w http.ResponseWriter, req *http.Reque
 	}
 }
 
+func baselineMap(baseline *plan.Baseline, withPlan bool) map[string]interface{} {
+	rv := map[string]interface{}{
+		"name":      baseline.Name(),
+		"statement": baseline.Text(),
+		"namespace": baseline.Namespace(),
+		"state":     baseline.State(),
+		"captured":  baseline.Captured().String(),
+		"uses":      baseline.Uses(),
+	}
+	if baseline.QueryContext() != "" {
+		rv["queryContext"] = baseline.QueryContext()
+	}
+	if withPlan {
+		rv["plan"] = baseline.Plan()
+	}
+	return rv
+}
+
+func doPlanBaseline(endpoint *HttpEndpoint, w http.ResponseWriter, req *http.Request, af *audit.ApiAuditFields) (interface{}, errors.Error) {
+	vars := mux.Vars(req)
+	name := vars["name"]
+
+	af.EventTypeId = audit.API_ADMIN_PLAN_BASELINES
+	af.Name = name
+
+	if req.Method == "DELETE" {
+		err, _ := endpoint.verifyCredentialsFromRequest("system:plan_baselines", auth.PRIV_CLUSTER_ADMIN, req, af)
+		if err != nil {
+			return nil, err
+		}
+		err = prepareds.DropBaseline(name)
+		if err != nil {
+			return nil, err
+		}
+		return true, nil
+	} else if req.Method == "PUT" {
+		body, err1 := ioutil.ReadAll(req.Body)
+		defer req.Body.Close()
+
+		// http.BasicAuth eats the body, so verify credentials after getting the body.
+		err, _ := endpoint.verifyCredentialsFromRequest("system:plan_baselines", auth.PRIV_CLUSTER_ADMIN, req, af)
+		if err != nil {
+			return nil, err
+		}
+
+		if err1 != nil {
+			return nil, errors.NewAdminBodyError(err1)
+		}
+
+		// capturing a plan also needs the privileges to run the statement
+		creds, err, _ := endpoint.getCredentialsFromRequest(datastore.GetDatastore(), req)
+		if err != nil {
+			return nil, err
+		}
+
+		// the body either sets the state, evolves the baseline, or captures it anew
+		var settings struct {
+			State        string `json:"state"`
+			Evolve       bool   `json:"evolve"`
+			Statement    string `json:"statement"`
+			Prepared     string `json:"prepared"`
+			QueryContext string `json:"queryContext"`
+			Namespace    string `json:"namespace"`
+		}
+		err1 = json.Unmarshal(body, &settings)
+		if err1 != nil {
+			return nil, errors.NewAdminBodyError(err1)
+		}
+
+		var baseline *plan.Baseline
+		switch {
+		case settings.State != "":
+			err = prepareds.SetBaselineState(name, settings.State)
+		case settings.Evolve:
+			baseline, err = prepareds.EvolveBaseline(name, creds)
+		case settings.Statement != "" || settings.Prepared != "":
+			if settings.Namespace == "" {
+				settings.Namespace = "default"
+			}
+			baseline, err = prepareds.CaptureBaseline(name, settings.Statement, settings.Prepared,
+				settings.QueryContext, settings.Namespace, creds)
+		default:
+			return nil, errors.NewAdminBodyError(fmt.Errorf("expecting a state, evolve, a statement or a prepared name"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if baseline == nil {
+			baseline, _ = plan.GetBaselineStore().Baseline(name)
+		}
+		return baselineMap(baseline, false), nil
+	} else if req.Method == "GET" || req.Method == "POST" {
+		err, _ := endpoint.verifyCredentialsFromRequest("system:plan_baselines", auth.PRIV_SYSTEM_READ, req, af)
+		if err != nil {
+			return nil, err
+		}
+		baseline, ok := plan.GetBaselineStore().Baseline(name)
+		if !ok {
+			return nil, errors.NewNoSuchBaselineError(name)
+		}
+		return baselineMap(baseline, req.Method == "POST"), nil
+	} else {
+		return nil, errors.NewServiceErrorHttpMethod(req.Method)
+	}
+}
+
+func doPlanBaselines(endpoint *HttpEndpoint, w http.ResponseWriter, req *http.Request, af *audit.ApiAuditFields) (interface{}, errors.Error) {
+	af.EventTypeId = audit.API_ADMIN_PLAN_BASELINES
+	switch req.Method {
+	case "GET":
+		err, _ := endpoint.verifyCredentialsFromRequest("system:plan_baselines", auth.PRIV_SYSTEM_READ, req, af)
+		if err != nil {
+			return nil, err
+		}
+
+		baselines := plan.GetBaselineStore()
+		names := baselines.Names()
+		data := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+
+			// dropped since the names were read
+			baseline, ok := baselines.Baseline(name)
+			if !ok {
+				continue
+			}
+			data = append(data, baselineMap(baseline, false))
+		}
+		return data, nil
+
+	default:
+		return nil, errors.NewServiceErrorHttpMethod(req.Method)
+	}
+}
+
 func doTask(endpoint *HttpEndpoint, w http.ResponseWriter, req *http.Request, af *audit.ApiAuditFields) (interface{}, errors.Error) {
 	vars := mux.Vars(req)
 	name := vars["name"]
@@ -1376,8 +1521,8 @@ func activeRequestWorkHorse(endpoint *HttpEndpoint, requestId string, profiling
 		if cId != "" {
 			reqMap["clientContextID"] = cId
 		}
-		if request.Statement() != "" {
-			reqMap["statement"] = request.Statement()
+		if request.RedactedStatement() != "" {
+			reqMap["statement"] = request.RedactedStatement()
 		}
 		if request.Type() != "" {
 			reqMap["statementType"] = request.Type()
@@ -1516,8 +1661,8 @@ func doActiveRequests(endpoint *HttpEndpoint, w http.ResponseWriter, req *http.R
 		if cId != "" {
 			requests[i]["clientContextID"] = cId
 		}
-		if request.Statement() != "" {
-			requests[i]["statement"] = request.Statement()
+		if request.RedactedStatement() != "" {
+			requests[i]["statement"] = request.RedactedStatement()
 		}
 		if request.Type() != "" {
 			requests[i]["statementType"] = request.Type()
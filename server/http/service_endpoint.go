@@ -25,6 +25,7 @@ import (
 	ntls "github.com/couchbase/goutils/tls"
 	"github.com/couchbase/query/accounting"
 	"github.com/couchbase/query/audit"
+	"github.com/couchbase/query/auth"
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/distributed"
 	"github.com/couchbase/query/errors"
@@ -118,11 +119,23 @@ func NewServiceEndpoint(srv *server.Server, staticPath string, metrics bool,
 	server.SetActives(rv.actives)
 	server.SetOptions(rv.options)
 
+	// views created on other nodes, or before a restart, are refreshed as the query service
+	execution.SetMaterializedViewContext(rv.internalContext)
+
 	rv.registerHandlers(staticPath)
 	_ENDPOINT = rv
 	return rv
 }
 
+func (this *HttpEndpoint) internalContext() *execution.Context {
+	creds := auth.NewCredentials()
+	u, p, err := cbauth.GetHTTPServiceAuth(distributed.RemoteAccess().WhoAmI())
+	if err == nil {
+		creds.Users[u] = p
+	}
+	return this.server.NewInternalContext(creds, zeroScanVectorSource, execution.NewInternalOutput())
+}
+
 func (this *HttpEndpoint) Mux() *mux.Router {
 	return this.mux
 }
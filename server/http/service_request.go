@@ -645,6 +645,14 @@ func handlePreserveExpiry(rv *httpRequest, httpArgs httpRequestArgs, parm string
 	return err
 }
 
+func handleUseDecimal(rv *httpRequest, httpArgs httpRequestArgs, parm string, val interface{}) errors.Error {
+	useDecimal, err := httpArgs.getTristateVal(parm, val)
+	if err == nil {
+		rv.SetUseDecimal(useDecimal == value.TRUE)
+	}
+	return err
+}
+
 func handleErrorLimit(rv *httpRequest, httpArgs httpRequestArgs, parm string, val interface{}) errors.Error {
 	limit, err := httpArgs.getIntVal(parm, val)
 	if err == nil {
@@ -786,6 +794,7 @@ const ( // Request argument names
 	NUMATRS            = "numatrs"
 	PRESERVE_EXPIRY    = "preserve_expiry"
 	ERROR_LIMIT        = "error_limit"
+	USE_DECIMAL        = "use_decimal"
 )
 
 type argHandler struct {
@@ -840,6 +849,7 @@ var _PARAMETERS = map[string]*argHandler{
 	NUMATRS:         {handleNumAtrs, false},
 	PRESERVE_EXPIRY: {handlePreserveExpiry, false},
 	ERROR_LIMIT:     {handleErrorLimit, false},
+	USE_DECIMAL:     {handleUseDecimal, false},
 }
 
 // common storage for the httpArgs implementations
@@ -10,6 +10,7 @@ package server
 
 import (
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
 	"github.com/couchbase/query/execution"
+	"github.com/couchbase/query/parser/n1ql"
 	"github.com/couchbase/query/plan"
 	"github.com/couchbase/query/timestamp"
 	"github.com/couchbase/query/util"
@@ -58,6 +60,7 @@ type Request interface {
 	SetClientID(id string)
 	Statement() string
 	SetStatement(statement string)
+	RedactedStatement() string
 	Prepared() *plan.Prepared
 	SetPrepared(prepared *plan.Prepared)
 	Type() string
@@ -139,6 +142,8 @@ type Request interface {
 	SetNumAtrs(n int)
 	PreserveExpiry() bool
 	SetPreserveExpiry(a bool)
+	UseDecimal() bool
+	SetUseDecimal(a bool)
 	ExecutionContext() *execution.Context
 	SetExecutionContext(ctx *execution.Context)
 	SetExecTime(time time.Time)
@@ -269,6 +274,7 @@ type BaseRequest struct {
 	id                   requestIDImpl
 	client_id            clientContextIDImpl
 	statement            string
+	redacted             string
 	prepared             *plan.Prepared
 	reqType              string
 	isPrepare            bool
@@ -331,6 +337,7 @@ type BaseRequest struct {
 	atrCollection        string
 	numAtrs              int
 	preserveExpiry       bool
+	useDecimal           bool
 	executionContext     *execution.Context
 	resultCount          int64
 	resultSize           int64
@@ -422,6 +429,22 @@ func (this *BaseRequest) Statement() string {
 
 func (this *BaseRequest) SetStatement(statement string) {
 	this.statement = statement
+	this.redacted = ""
+
+	// requests are shown as soon as they are registered, so mask passwords here
+	if strings.Contains(strings.ToUpper(statement), "PASSWORD") {
+		if text, ok := n1ql.RedactPasswords(statement); ok {
+			this.redacted = text
+		}
+	}
+}
+
+// The statement as shown in system keyspaces, logs and audit records
+func (this *BaseRequest) RedactedStatement() string {
+	if this.redacted != "" {
+		return this.redacted
+	}
+	return this.statement
 }
 
 func (this *BaseRequest) Prepared() *plan.Prepared {
@@ -1066,6 +1089,14 @@ func (this *BaseRequest) PreserveExpiry() bool {
 	return this.preserveExpiry
 }
 
+func (this *BaseRequest) SetUseDecimal(a bool) {
+	this.useDecimal = a
+}
+
+func (this *BaseRequest) UseDecimal() bool {
+	return this.useDecimal
+}
+
 func (this *BaseRequest) SetExecutionContext(ctx *execution.Context) {
 	this.executionContext = ctx
 }
@@ -1179,7 +1210,7 @@ func (this *BaseRequest) EventStatement() string {
 	if prep != nil {
 		return prep.Text()
 	}
-	return this.Statement()
+	return this.RedactedStatement()
 }
 
 // For audit.Auditable interface.
@@ -0,0 +1,503 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package server
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/datastore"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/logging"
+	"github.com/couchbase/query/parser/n1ql"
+	"github.com/couchbase/query/prepareds"
+	"github.com/couchbase/query/util"
+)
+
+/*
+Resource groups keep one workload from starving another. Each group has
+its own run queue, with its own depth and a share of the servicers, and
+caps the memory quota and sets the default timeout of its requests.
+
+A request belongs to the highest priority group whose criteria it meets:
+every criterion that a group specifies (users, client_context_id prefix,
+statement types) must match. Requests that belong to no group use the
+server wide queues.
+
+The servicers of the groups are taken from the servicers setting, the
+rest run the server wide queue. Groups take their share in priority
+order, so that when the shares add up to more than the servicers, the
+lower priority groups are left with a single servicer.
+
+Groups are managed through the resource-groups admin setting and the
+system:resource_groups keyspace.
+*/
+type ResourceGroup struct {
+	name           string
+	users          []string
+	clientPrefix   string
+	statementTypes []string
+	queueSize      int
+	servicerShare  int
+	memoryQuota    uint64
+	timeout        time.Duration
+	priority       int
+	queue          runQueue
+}
+
+const (
+	RG_USERS           = "users"
+	RG_CLIENT_PREFIX   = "client_context_id"
+	RG_STATEMENT_TYPES = "statement_types"
+	RG_QUEUE_SIZE      = "queue_size"
+	RG_SERVICER_SHARE  = "servicer_share"
+	RG_MEMORY_QUOTA    = "memory_quota"
+	RG_TIMEOUT         = "timeout"
+	RG_PRIORITY        = "priority"
+
+	// read only, reported by system:resource_groups
+	RG_NAME            = "name"
+	RG_SERVICERS       = "servicers"
+	RG_ACTIVE_REQUESTS = "active_requests"
+	RG_QUEUED_REQUESTS = "queued_requests"
+)
+
+const _RG_DEF_QUEUE_SIZE = 1024
+const _RG_DEF_SERVICER_SHARE = 10
+
+type resourceGroupList struct {
+	sync.RWMutex
+	servicers int
+	unbound   *runQueue
+	groups    map[string]*ResourceGroup
+	ordered   []*ResourceGroup
+}
+
+var resourceGroups = &resourceGroupList{
+	servicers: SERVICERS_MULTIPLIER * util.NumCPU(),
+	groups:    make(map[string]*ResourceGroup),
+}
+
+func newResourceGroup(name string, def interface{}) (*ResourceGroup, errors.Error) {
+	object, ok := def.(map[string]interface{})
+	if !ok || name == "" {
+		return nil, errors.NewAdminSettingTypeError(RESOURCEGROUPS, def)
+	}
+
+	rv := &ResourceGroup{
+		name:          name,
+		queueSize:     _RG_DEF_QUEUE_SIZE,
+		servicerShare: _RG_DEF_SERVICER_SHARE,
+	}
+	setting := RESOURCEGROUPS + "." + name + "."
+	for n, v := range object {
+		switch n {
+		case RG_USERS:
+			users, ok := getStrings(v)
+			if !ok {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.users = users
+		case RG_CLIENT_PREFIX:
+			prefix, ok := v.(string)
+			if !ok {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.clientPrefix = prefix
+		case RG_STATEMENT_TYPES:
+			types, ok := getStrings(v)
+			if !ok {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			for i, _ := range types {
+				types[i] = strings.ToUpper(types[i])
+			}
+			rv.statementTypes = types
+		case RG_QUEUE_SIZE:
+			size := getNumber(v)
+			if size < 1 {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.queueSize = int(size)
+		case RG_SERVICER_SHARE:
+			share := getNumber(v)
+			if share < 1 || share > 100 {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.servicerShare = int(share)
+		case RG_MEMORY_QUOTA:
+			quota := getNumber(v)
+			if quota < 0 {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.memoryQuota = uint64(quota)
+		case RG_TIMEOUT:
+			ok, _ := checkDuration(v)
+			if !ok {
+				return nil, errors.NewAdminSettingTypeError(setting+n, v)
+			}
+			rv.timeout = getDuration(v)
+		case RG_PRIORITY:
+			priority, ok := v.(int64)
+			if !ok {
+				f, isFloat := v.(float64)
+				if !isFloat || f != float64(int64(f)) {
+					return nil, errors.NewAdminSettingTypeError(setting+n, v)
+				}
+				priority = int64(f)
+			}
+			rv.priority = int(priority)
+
+		// read only fields are ignored, so that documents read from
+		// system:resource_groups can be written back
+		case RG_NAME, RG_SERVICERS, RG_ACTIVE_REQUESTS, RG_QUEUED_REQUESTS:
+		default:
+			return nil, errors.NewAdminUnknownSettingError(setting + n)
+		}
+	}
+
+	// a group that matches everything would replace the server wide queues
+	if len(rv.users) == 0 && rv.clientPrefix == "" && len(rv.statementTypes) == 0 {
+		return nil, errors.NewAdminSettingTypeError(setting+RG_USERS, nil)
+	}
+	return rv, nil
+}
+
+func getStrings(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		rv := make([]string, len(v))
+		for i, s := range v {
+			str, ok := s.(string)
+			if !ok {
+				return nil, false
+			}
+			rv[i] = str
+		}
+		return rv, true
+	}
+	return nil, false
+}
+
+func (this *ResourceGroup) Name() string {
+	return this.name
+}
+
+func (this *ResourceGroup) Priority() int {
+	return this.priority
+}
+
+// the servicers a group takes out of those left, keeping one for the server wide queue
+func (this *ResourceGroup) setServicers(servicers, left int) int {
+	servicers = servicers * this.servicerShare / 100
+	if servicers >= left {
+		servicers = left - 1
+	}
+	if servicers < 1 {
+		servicers = 1
+	}
+	this.queue.servicers = servicers
+	return servicers
+}
+
+func (this *ResourceGroup) matches(request Request, users []string, statementType string) bool {
+	if this.clientPrefix != "" &&
+		(request.ClientID() == nil || !strings.HasPrefix(request.ClientID().String(), this.clientPrefix)) {
+		return false
+	}
+	if len(this.statementTypes) > 0 {
+		found := false
+		for _, t := range this.statementTypes {
+			if t == statementType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(this.users) > 0 {
+		found := false
+		for _, u := range this.users {
+			for _, user := range users {
+				if userMatches(u, user) {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// a group user matches an authenticated user with or without its domain
+func userMatches(u, user string) bool {
+	if u == user {
+		return true
+	}
+	i := strings.IndexByte(user, ':')
+	return i >= 0 && user[i+1:] == u
+}
+
+// apply the group memory quota and default timeout to a request
+func (this *ResourceGroup) admit(request Request) {
+	if this.memoryQuota > 0 && (request.MemoryQuota() == 0 || request.MemoryQuota() > this.memoryQuota) {
+		request.SetMemoryQuota(this.memoryQuota)
+	}
+	if this.timeout > 0 && request.Timeout() <= 0 {
+		request.SetTimeout(this.timeout)
+	}
+}
+
+func (this *ResourceGroup) description(stats bool) map[string]interface{} {
+	rv := map[string]interface{}{
+		RG_QUEUE_SIZE:     this.queueSize,
+		RG_SERVICER_SHARE: this.servicerShare,
+		RG_PRIORITY:       this.priority,
+	}
+	if len(this.users) > 0 {
+		rv[RG_USERS] = stringsDescription(this.users)
+	}
+	if this.clientPrefix != "" {
+		rv[RG_CLIENT_PREFIX] = this.clientPrefix
+	}
+	if len(this.statementTypes) > 0 {
+		rv[RG_STATEMENT_TYPES] = stringsDescription(this.statementTypes)
+	}
+	if this.memoryQuota > 0 {
+		rv[RG_MEMORY_QUOTA] = this.memoryQuota
+	}
+	if this.timeout > 0 {
+		rv[RG_TIMEOUT] = this.timeout.String()
+	}
+	if stats {
+		rv[RG_NAME] = this.name
+		rv[RG_SERVICERS] = this.queue.servicers
+		rv[RG_ACTIVE_REQUESTS] = this.queue.activeRequests()
+		rv[RG_QUEUED_REQUESTS] = this.queue.queuedRequests()
+	}
+	return rv
+}
+
+// lists are described as JSON arrays, so that they can be read as values
+func stringsDescription(s []string) []interface{} {
+	rv := make([]interface{}, len(s))
+	for i, v := range s {
+		rv[i] = v
+	}
+	return rv
+}
+
+/*
+The statement type of a request, from its prepared statement, or from
+parsing its text, which is only needed when a group selects statement
+types. EXECUTE takes the type of the prepared statement it executes.
+*/
+func requestStatementType(request Request, namespace string) string {
+	prepared := request.Prepared()
+	if prepared == nil {
+		if request.Namespace() != "" {
+			namespace = request.Namespace()
+		}
+		stmt, err := n1ql.ParseStatement2(request.Statement(), namespace, request.QueryContext())
+		if err != nil {
+			return ""
+		}
+		exec, ok := stmt.(*algebra.Execute)
+		if !ok {
+			return stmt.Type()
+		}
+		prepared, _ = prepareds.GetPreparedWithContext(exec.Prepared(), request.QueryContext(), nil, 0, nil)
+		if prepared == nil {
+			return ""
+		}
+	}
+	return prepared.Type()
+}
+
+/*
+The users of a request whose credentials check out. Users named in the
+credentials that do not authenticate do not place a request in a group.
+Authenticated users are qualified by their domain, e.g. local:analyst.
+*/
+func requestUsers(request Request) []string {
+	creds := request.Credentials()
+	ds := datastore.GetDatastore()
+	if creds == nil || ds == nil {
+		return nil
+	}
+	users, err := ds.Authorize(nil, creds)
+	if err != nil {
+		return nil
+	}
+	return users
+}
+
+func matchResourceGroup(request Request, namespace string) *ResourceGroup {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+
+	if len(resourceGroups.ordered) == 0 {
+		return nil
+	}
+
+	var users []string
+	var statementType string
+	usersDone, typeDone := false, false
+	for _, group := range resourceGroups.ordered {
+		if len(group.users) > 0 && !usersDone {
+			users = requestUsers(request)
+			usersDone = true
+		}
+		if len(group.statementTypes) > 0 && !typeDone {
+			statementType = requestStatementType(request, namespace)
+			typeDone = true
+		}
+		if group.matches(request, users, statementType) {
+			return group
+		}
+	}
+	return nil
+}
+
+// divide the servicers between the groups and the server wide queue when they change
+func setResourceGroupServicers(servicers int, unbound *runQueue) {
+	resourceGroups.Lock()
+	defer resourceGroups.Unlock()
+	resourceGroups.servicers = servicers
+	resourceGroups.unbound = unbound
+	resourceGroups.divideServicers()
+}
+
+func (this *resourceGroupList) divideServicers() {
+	left := this.servicers
+	for _, group := range this.ordered {
+		left -= group.setServicers(this.servicers, left)
+	}
+	if left < 1 {
+		left = 1
+	}
+	if this.unbound != nil {
+		this.unbound.servicers = left
+	}
+}
+
+func (this *resourceGroupList) reorder() {
+	this.ordered = this.ordered[:0]
+	for _, group := range this.groups {
+		this.ordered = append(this.ordered, group)
+	}
+	sort.Slice(this.ordered, func(i, j int) bool {
+		if this.ordered[i].priority != this.ordered[j].priority {
+			return this.ordered[i].priority > this.ordered[j].priority
+		}
+		return this.ordered[i].name < this.ordered[j].name
+	})
+}
+
+func ResourceGroupCheck(name string, def interface{}) errors.Error {
+	_, err := newResourceGroup(name, def)
+	return err
+}
+
+/*
+Add or replace a group. Requests already admitted to a replaced group
+complete on its queue, which is retired once drained.
+*/
+func ResourceGroupSet(name string, def interface{}) errors.Error {
+	group, err := newResourceGroup(name, def)
+	if err != nil {
+		return err
+	}
+
+	resourceGroups.Lock()
+	defer resourceGroups.Unlock()
+	newRunQueue(&group.queue, group.queueSize, false)
+	old, ok := resourceGroups.groups[name]
+	if ok {
+		old.queue.retire()
+	}
+	resourceGroups.groups[name] = group
+	resourceGroups.reorder()
+	resourceGroups.divideServicers()
+	logging.Infof("Resource group %v set", name)
+	return nil
+}
+
+func ResourceGroupDelete(name string) bool {
+	resourceGroups.Lock()
+	defer resourceGroups.Unlock()
+	group, ok := resourceGroups.groups[name]
+	if ok {
+		group.queue.retire()
+		delete(resourceGroups.groups, name)
+		resourceGroups.reorder()
+		resourceGroups.divideServicers()
+		logging.Infof("Resource group %v dropped", name)
+	}
+	return ok
+}
+
+func ResourceGroupGet(name string) (map[string]interface{}, bool) {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+	group, ok := resourceGroups.groups[name]
+	if !ok {
+		return nil, false
+	}
+	return group.description(true), true
+}
+
+func ResourceGroupsNames() []string {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+	rv := make([]string, 0, len(resourceGroups.groups))
+	for name, _ := range resourceGroups.groups {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func ResourceGroupsCount() int {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+	return len(resourceGroups.groups)
+}
+
+// the resource-groups admin setting
+func ResourceGroupsGet() map[string]interface{} {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+	rv := make(map[string]interface{}, len(resourceGroups.groups))
+	for name, group := range resourceGroups.groups {
+		rv[name] = group.description(false)
+	}
+	return rv
+}
+
+func resourceGroupsLoad() (load, active, queued int) {
+	resourceGroups.RLock()
+	defer resourceGroups.RUnlock()
+	for _, group := range resourceGroups.ordered {
+		load += group.queue.load(0)
+		active += group.queue.activeRequests()
+		queued += group.queue.queuedRequests()
+	}
+	return
+}
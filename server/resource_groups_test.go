@@ -0,0 +1,169 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/query/auth"
+	"github.com/couchbase/query/plan"
+	"github.com/couchbase/query/prepareds"
+)
+
+type rgRequest struct {
+	Request
+	statement string
+}
+
+func (this *rgRequest) Statement() string         { return this.statement }
+func (this *rgRequest) Prepared() *plan.Prepared  { return nil }
+func (this *rgRequest) Namespace() string         { return "" }
+func (this *rgRequest) QueryContext() string      { return "" }
+func (this *rgRequest) ClientID() ClientContextID { return nil }
+func (this *rgRequest) Credentials() *auth.Credentials {
+	return &auth.Credentials{Users: map[string]string{"analyst": "x"}}
+}
+
+type rgLimitsRequest struct {
+	rgRequest
+	memoryQuota uint64
+	timeout     time.Duration
+}
+
+func (this *rgLimitsRequest) MemoryQuota() uint64              { return this.memoryQuota }
+func (this *rgLimitsRequest) SetMemoryQuota(q uint64)          { this.memoryQuota = q }
+func (this *rgLimitsRequest) Timeout() time.Duration           { return this.timeout }
+func (this *rgLimitsRequest) SetTimeout(timeout time.Duration) { this.timeout = timeout }
+
+func TestResourceGroupStatementType(t *testing.T) {
+	prepareds.PreparedsInit(1024)
+	cases := map[string]string{
+		"SELECT 1":                                         "SELECT",
+		"/* report */ SELECT 1":                            "SELECT",
+		"-- report\nUPDATE default SET a = 1":              "UPDATE",
+		"(SELECT 1) UNION (SELECT 2)":                      "SELECT",
+		"WITH a AS (SELECT 1) SELECT * FROM a":             "SELECT",
+		"DELETE FROM default WHERE a = 1":                  "DELETE",
+		"EXECUTE no_such_prepared":                         "",
+		"this isn't a statement":                           "",
+		"EXPLAIN SELECT /*+ PARALLEL(2) */ * FROM default": "EXPLAIN",
+	}
+	for stmt, expected := range cases {
+		actual := requestStatementType(&rgRequest{statement: stmt}, "default")
+		if actual != expected {
+			t.Errorf("Statement type of %q: expected %q, actual %q", stmt, expected, actual)
+		}
+	}
+}
+
+func TestResourceGroupUsers(t *testing.T) {
+	if !userMatches("analyst", "local:analyst") || !userMatches("analyst", "analyst") ||
+		userMatches("analyst", "local:analyst2") || userMatches("lyst", "local:analyst") {
+		t.Errorf("Unexpected match of group users")
+	}
+
+	// users that are named in the credentials but did not authenticate do not match
+	err := ResourceGroupSet("rg_users", map[string]interface{}{"users": []interface{}{"analyst"}})
+	if err != nil {
+		t.Fatalf("Unexpected error setting group: %v", err)
+	}
+	defer ResourceGroupDelete("rg_users")
+	if group := matchResourceGroup(&rgRequest{statement: "SELECT 1"}, "default"); group != nil {
+		t.Errorf("Unexpected match of unauthenticated user to group %v", group.Name())
+	}
+}
+
+func TestResourceGroupServicers(t *testing.T) {
+	var unbound runQueue
+	setResourceGroupServicers(20, &unbound)
+	defer setResourceGroupServicers(20, nil)
+
+	groups := map[string]map[string]interface{}{
+		"rg_high": {"statement_types": "SELECT", "servicer_share": 50.0, "priority": 10.0},
+		"rg_mid":  {"statement_types": "UPDATE", "servicer_share": 40.0, "priority": 5.0},
+		"rg_low":  {"statement_types": "DELETE", "servicer_share": 30.0},
+	}
+	for name, def := range groups {
+		if err := ResourceGroupSet(name, def); err != nil {
+			t.Fatalf("Unexpected error setting group %v: %v", name, err)
+		}
+		defer ResourceGroupDelete(name)
+	}
+
+	// groups take their share in priority order, the last one is squeezed to a single servicer
+	expected := map[string]int{"rg_high": 10, "rg_mid": 8, "rg_low": 1}
+	for name, servicers := range expected {
+		group, _ := ResourceGroupGet(name)
+		if group[RG_SERVICERS] != servicers {
+			t.Errorf("Servicers of %v: expected %v, actual %v", name, servicers, group[RG_SERVICERS])
+		}
+	}
+	if unbound.servicers != 1 {
+		t.Errorf("Servicers of the server wide queue: expected 1, actual %v", unbound.servicers)
+	}
+
+	ResourceGroupDelete("rg_mid")
+	if unbound.servicers != 4 {
+		t.Errorf("Servicers of the server wide queue: expected 4, actual %v", unbound.servicers)
+	}
+
+	if group := matchResourceGroup(&rgRequest{statement: "/* c */ (SELECT 1) UNION ALL (SELECT 2)"}, "default"); group == nil || group.Name() != "rg_high" {
+		t.Errorf("Expected a match of group rg_high, actual %v", group)
+	}
+	if group := matchResourceGroup(&rgRequest{statement: "UPDATE default SET a = 1"}, "default"); group != nil {
+		t.Errorf("Unexpected match of group %v", group.Name())
+	}
+}
+
+func TestResourceGroupLimits(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"users": 7.0},
+		{"users": []interface{}{"analyst"}, "servicer_share": 0.0},
+		{"users": []interface{}{"analyst"}, "queue_size": 0.0},
+		{"users": []interface{}{"analyst"}, "memory_quota": -1.0},
+		{"users": []interface{}{"analyst"}, "priority": 1.5},
+		{"users": []interface{}{"analyst"}, "timeout": "soon"},
+		{"users": []interface{}{"analyst"}, "no_such_setting": 1.0},
+	}
+	for _, def := range invalid {
+		if err := ResourceGroupCheck("rg_invalid", def); err == nil {
+			t.Errorf("Expected error for group %v", def)
+		}
+	}
+
+	group, err := newResourceGroup("rg_limits", map[string]interface{}{
+		"statement_types": []interface{}{"select"}, "memory_quota": 100.0, "timeout": "5s",
+		"name": "rg_limits", "servicers": 3.0,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the group quota caps the request quota, and its timeout applies to requests without one
+	var tests = []struct {
+		memoryQuota uint64
+		timeout     time.Duration
+		expectedMem uint64
+		expectedTo  time.Duration
+	}{
+		{0, 0, 100, 5 * time.Second},
+		{50, time.Second, 50, time.Second},
+		{200, 10 * time.Second, 100, 10 * time.Second},
+	}
+	for _, test := range tests {
+		request := &rgLimitsRequest{memoryQuota: test.memoryQuota, timeout: test.timeout}
+		group.admit(request)
+		if request.memoryQuota != test.expectedMem || request.timeout != test.expectedTo {
+			t.Errorf("Admitting %v, %v: expected %v, %v, actual %v, %v", test.memoryQuota, test.timeout,
+				test.expectedMem, test.expectedTo, request.memoryQuota, request.timeout)
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	atomic "github.com/couchbase/go-couchbase/platform"
 	"github.com/couchbase/query/accounting"
 	"github.com/couchbase/query/algebra"
+	"github.com/couchbase/query/auth"
 	"github.com/couchbase/query/clustering"
 	"github.com/couchbase/query/datastore"
 	"github.com/couchbase/query/errors"
@@ -38,6 +39,7 @@ import (
 	"github.com/couchbase/query/rewrite"
 	"github.com/couchbase/query/semantics"
 	queryMetakv "github.com/couchbase/query/server/settings/couchbase"
+	"github.com/couchbase/query/timestamp"
 	"github.com/couchbase/query/transactions"
 	"github.com/couchbase/query/util"
 	"github.com/couchbase/query/value"
@@ -112,6 +114,7 @@ type runQueue struct {
 	tail      int32
 	queue     []waitEntry
 	mutex     sync.RWMutex
+	retired   uint32
 }
 
 type txRunQueues struct {
@@ -135,6 +138,7 @@ type Server struct {
 	requestSize    atomic.AlignedInt64
 
 	sync.RWMutex
+	servicers         int
 	unboundQueue      runQueue
 	plusQueue         runQueue
 	transactionQueues txRunQueues
@@ -463,16 +467,20 @@ func (this *Server) SetRequestSizeCap(requestSize int) {
 }
 
 func (this *Server) Servicers() int {
-	return this.unboundQueue.servicers
+	this.RLock()
+	defer this.RUnlock()
+	return this.servicers
 }
 
+// the servicers of the resource groups are taken out of those of the server wide queue
 func (this *Server) SetServicers(servicers int) {
 	this.Lock()
 	if servicers <= 0 {
 		servicers = SERVICERS_MULTIPLIER * util.NumCPU()
 	}
-	this.unboundQueue.servicers = servicers
+	this.servicers = servicers
 	this.Unlock()
+	setResourceGroupServicers(servicers, &this.unboundQueue)
 }
 
 func (this *Server) PlusServicers() int {
@@ -585,20 +593,34 @@ func (this *Server) SetRequestErrorLimit(limit int) error {
 }
 
 func (this *Server) ServiceRequest(request Request) bool {
+	group := matchResourceGroup(request, this.namespace)
+	if group != nil {
+		group.admit(request)
+	}
 	if !this.setupRequestContext(request) {
 		request.Failed(this)
 		return true // so that StatusServiceUnavailable will not return
 	}
 
+	if group != nil {
+		return this.handleRequest(request, &group.queue)
+	}
 	return this.handleRequest(request, &this.unboundQueue)
 }
 
 func (this *Server) PlusServiceRequest(request Request) bool {
+	group := matchResourceGroup(request, this.namespace)
+	if group != nil {
+		group.admit(request)
+	}
 	if !this.setupRequestContext(request) {
 		request.Failed(this)
 		return true // so that StatusServiceUnavailable will not return
 	}
 
+	if group != nil {
+		return this.handlePlusRequest(request, &group.queue, &this.transactionQueues)
+	}
 	return this.handlePlusRequest(request, &this.plusQueue, &this.transactionQueues)
 }
 
@@ -623,6 +645,8 @@ func (this *Server) setupRequestContext(request Request) bool {
 	context.SetDurability(request.DurabilityLevel(), request.DurabilityTimeout())
 	context.SetScanConsistency(request.ScanConsistency(), request.OriginalScanConsistency())
 	context.SetPreserveExpiry(request.PreserveExpiry())
+	context.SetUseDecimal(request.UseDecimal())
+	context.SetServerLoad(this.Load())
 
 	if request.TxId() != "" {
 		err := context.SetTransactionInfo(request.TxId(), request.TxStmtNum())
@@ -655,6 +679,21 @@ func (this *Server) setupRequestContext(request Request) bool {
 	return true
 }
 
+/*
+A context for statements the server runs on its own account, rather
+than for a request, such as the scheduled refreshes of materialized
+views created on other nodes. Scan vectors and output are up to the
+caller.
+*/
+func (this *Server) NewInternalContext(credentials *auth.Credentials, scanVectorSource timestamp.ScanVectorSource,
+	output execution.Output) *execution.Context {
+	id, _ := util.UUIDV4()
+	return execution.NewContext(id, this.datastore, this.systemstore, this.namespace, this.readonly,
+		this.MaxParallelism(), this.ScanCap(), this.PipelineCap(), this.PipelineBatch(), nil, nil, credentials,
+		datastore.UNBOUNDED, scanVectorSource, output, nil, this.MaxIndexAPI(), util.GetN1qlFeatureControl(), "",
+		false, util.GetUseCBO(), getNewOptimizer(), datastore.DEF_KVTIMEOUT, this.Timeout())
+}
+
 func (this *Server) handleRequest(request Request, queue *runQueue) bool {
 	if !queue.enqueue(request) {
 		return false
@@ -896,6 +935,11 @@ func (this *runQueue) checkWaiters() {
 		time.Sleep(100 * time.Millisecond)
 		runCnt := atomic.LoadInt32(&this.runCnt)
 		queueCnt := atomic.LoadInt32(&this.queueCnt)
+
+		// a retired queue takes no new requests: stop once drained
+		if runCnt == 0 && queueCnt == 0 && atomic.LoadUint32(&this.retired) != 0 {
+			return
+		}
 		for {
 
 			// no left behind requests
@@ -921,6 +965,10 @@ func (this *runQueue) checkWaiters() {
 	}
 }
 
+func (this *runQueue) retire() {
+	atomic.StoreUint32(&this.retired, 1)
+}
+
 func (this *runQueue) load(txqueueCnt int) int {
 	return 100 * (this.activeRequests() + txqueueCnt) / this.servicers
 }
@@ -934,7 +982,8 @@ func (this *runQueue) queuedRequests() int {
 }
 
 func (this *Server) Load() int {
-	return this.plusQueue.load(this.txQueueCount()) + this.unboundQueue.load(0)
+	load, _, _ := resourceGroupsLoad()
+	return this.plusQueue.load(this.txQueueCount()) + this.unboundQueue.load(0) + load
 }
 
 func (this *Server) txQueueCount() int {
@@ -942,11 +991,13 @@ func (this *Server) txQueueCount() int {
 }
 
 func (this *Server) ActiveRequests() int {
-	return this.plusQueue.activeRequests() + this.unboundQueue.activeRequests()
+	_, active, _ := resourceGroupsLoad()
+	return this.plusQueue.activeRequests() + this.unboundQueue.activeRequests() + active
 }
 
 func (this *Server) QueuedRequests() int {
-	return this.unboundQueue.queuedRequests() + this.plusQueue.queuedRequests() + this.txQueueCount()
+	_, _, queued := resourceGroupsLoad()
+	return this.unboundQueue.queuedRequests() + this.plusQueue.queuedRequests() + this.txQueueCount() + queued
 }
 
 func (this *Server) serviceRequest(request Request) {
@@ -956,7 +1007,7 @@ func (this *Server) serviceRequest(request Request) {
 			buf := make([]byte, 1<<16)
 			n := runtime.Stack(buf, false)
 			s := string(buf[0:n])
-			stmt := "<ud>" + request.Statement() + "</ud>"
+			stmt := "<ud>" + request.RedactedStatement() + "</ud>"
 			qc := "<ud>" + request.QueryContext() + "</ud>"
 			logging.Severef("panic: %v ", err)
 			logging.Severef("request text: %v", stmt)
@@ -1104,6 +1155,27 @@ func (this *Server) serviceRequest(request Request) {
 	request.Execute(this, context, request.Type(), prepared.Signature(), request.Type() == "START_TRANSACTION")
 }
 
+/*
+Parse the request statement. Syntax errors quote the statement, so a
+statement holding a password that does not parse is reported on with
+the password masked.
+*/
+func parseStatement(request Request, namespace string) (algebra.Statement, error) {
+	stmt, err := n1ql.ParseStatement2(request.Statement(), namespace, request.QueryContext())
+	if err == nil {
+		return stmt, nil
+	}
+	text := request.RedactedStatement()
+	if text == request.Statement() {
+		return nil, err
+	}
+	_, err = n1ql.ParseStatement2(text, namespace, request.QueryContext())
+	if err == nil {
+		err = fmt.Errorf("syntax error in statement holding a password")
+	}
+	return nil, err
+}
+
 func (this *Server) getPrepared(request Request, context *execution.Context) (*plan.Prepared, errors.Error) {
 	var autoPrepare bool
 	var name string
@@ -1145,7 +1217,7 @@ func (this *Server) getPrepared(request Request, context *execution.Context) (*p
 
 	if prepared == nil {
 		parse := time.Now()
-		stmt, err := n1ql.ParseStatement2(request.Statement(), context.Namespace(), request.QueryContext())
+		stmt, err := parseStatement(request, context.Namespace())
 		request.Output().AddPhaseTime(execution.PARSE, time.Since(parse))
 		if err != nil {
 			return nil, errors.NewParseSyntaxError(err, "")
@@ -1201,6 +1273,7 @@ func (this *Server) getPrepared(request Request, context *execution.Context) (*p
 		planner.NewPrepareContext(&prepContext, request.Id().String(), request.QueryContext(), namedArgs,
 			positionalArgs, request.IndexApiVersion(), request.FeatureControls(), request.UseFts(),
 			request.UseCBO(), context.Optimizer(), context.DeltaKeyspaces(), dsContext)
+		prepContext.SetBaselineText(request.Statement())
 		if stmt, ok := stmt.(*algebra.Advise); ok {
 			stmt.SetContext(context)
 		}
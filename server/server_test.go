@@ -0,0 +1,58 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+type parseRequest struct {
+	Request
+	base BaseRequest
+}
+
+func (this *parseRequest) Statement() string             { return this.base.Statement() }
+func (this *parseRequest) SetStatement(statement string) { this.base.SetStatement(statement) }
+func (this *parseRequest) RedactedStatement() string     { return this.base.RedactedStatement() }
+func (this *parseRequest) QueryContext() string          { return "" }
+
+func TestParseStatementPassword(t *testing.T) {
+	for _, stmt := range []string{
+		`CREATE USER u1 PASSWORD "s3cret" WITH {"name": }`,
+		`CREATE USER u1 PASSWORD "s3cret`,
+		"ALTER USER u1 PASSWORD s3cret",
+	} {
+		request := &parseRequest{}
+		request.SetStatement(stmt)
+		_, err := parseStatement(request, "default")
+		if err == nil {
+			t.Errorf("Expected syntax error for %q", stmt)
+			continue
+		}
+		if strings.Contains(err.Error(), "s3cret") || strings.Contains(request.RedactedStatement(), "s3cret") {
+			t.Errorf("Password shown for %q: %q, %v", stmt, request.RedactedStatement(), err)
+		}
+	}
+
+	// the statement is masked as soon as it is set, and executed as written
+	request := &parseRequest{}
+	request.SetStatement(`CREATE USER u1 PASSWORD "s3cret"`)
+	if request.RedactedStatement() != `CREATE USER u1 PASSWORD "*****"` {
+		t.Errorf("Unexpected redacted statement: %q", request.RedactedStatement())
+	}
+	if _, err := parseStatement(request, "default"); err != nil || request.Statement() != `CREATE USER u1 PASSWORD "s3cret"` {
+		t.Errorf("Unexpected parse of valid statement: %q, %v", request.Statement(), err)
+	}
+
+	request.SetStatement("SELECT 1")
+	if request.RedactedStatement() != "SELECT 1" {
+		t.Errorf("Unexpected redacted statement: %q", request.RedactedStatement())
+	}
+}
@@ -115,7 +115,8 @@ var _SETTERS = map[string]Setter{
 		RequestsSetLimit(int(value), CMP_OP_DEL)
 		return nil
 	},
-	CMPOBJECT: setCompleted,
+	CMPOBJECT:      setCompleted,
+	RESOURCEGROUPS: setResourceGroups,
 	PRPLIMIT: func(s *Server, o interface{}) errors.Error {
 		value := getNumber(o)
 		prepareds.PreparedsSetLimit(int(value))
@@ -283,6 +284,25 @@ func setCompleted(s *Server, o interface{}) errors.Error {
 	return nil
 }
 
+func setResourceGroups(s *Server, o interface{}) errors.Error {
+	object := o.(map[string]interface{})
+	for n, v := range object {
+		switch n[0] {
+		case '-':
+			ResourceGroupDelete(n[1:])
+		case '+':
+			n = n[1:]
+			fallthrough
+		default:
+			res := ResourceGroupSet(n, v)
+			if res != nil {
+				return res
+			}
+		}
+	}
+	return nil
+}
+
 var reportAllInitially = true
 
 func ProcessSettings(settings map[string]interface{}, srvr *Server) (err errors.Error) {
@@ -445,6 +465,7 @@ func FillSettings(settings map[string]interface{}, srvr *Server) map[string]inte
 	settings[CLEANUPLOSTATTEMPTS] = tranSettings.CleanupLostAttempts()
 	settings[GCPERCENT] = srvr.GCPercent()
 	settings[REQUESTERRORLIMIT] = srvr.RequestErrorLimit()
+	settings[RESOURCEGROUPS] = ResourceGroupsGet()
 	return settings
 }
 
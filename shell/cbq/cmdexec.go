@@ -15,6 +15,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/couchbase/godbc/n1ql"
@@ -124,6 +125,10 @@ func command_query(line string, w io.Writer, liner *liner.State) (errors.ErrorCo
 				return 0, ""
 			}
 
+			if command.TIMING {
+				defer printElapsed(w, time.Now())
+			}
+
 			retry := true
 			for {
 				err_code, err_str := ExecN1QLStmt(line, command.DbN1ql, w)
@@ -189,6 +194,11 @@ func dispatch_command(line string, w io.Writer, interactive bool, liner *liner.S
 		return 0, ""
 	}
 
+	// Skip everything in a \IF block whose condition does not hold.
+	if command.Skip(line) {
+		return 0, ""
+	}
+
 	if strings.HasPrefix(line, "\\\\") {
 		// handles aliases
 		errCode, errStr := command_alias(line, w, interactive, liner)
@@ -224,13 +234,21 @@ func ExecN1QLStmt(line string, dBn1ql n1ql.N1qlDB, w io.Writer) (errors.ErrorCod
 
 	if rows != nil {
 		// We have output. That is what we want.
+		// Keep a copy for the status and result of the statement.
+		rec := &responseRecorder{}
+		body := io.TeeReader(rows, rec)
 
 		var werr error
-		if command.TERSE {
-			werr = terseOutput(w, rows)
+		if planMode != "" {
+			werr = planOutput(w, body, planMode)
+		} else if command.FORMAT != command.FORMAT_JSON {
+			werr = formattedOutput(w, body, command.FORMAT)
+		} else if command.TERSE {
+			werr = terseOutput(w, body)
 		} else {
-			_, werr = io.Copy(w, rows)
+			_, werr = io.Copy(w, body)
 		}
+		rec.record(err)
 
 		// For any captured write error
 		if werr != nil {
@@ -242,6 +260,7 @@ func ExecN1QLStmt(line string, dBn1ql n1ql.N1qlDB, w io.Writer) (errors.ErrorCod
 		return 0, ""
 	}
 
+	recordFailure()
 	if err != nil {
 		return errors.E_SHELL_DRIVER_QUERY_METHOD, err.Error()
 	}
@@ -250,6 +269,12 @@ func ExecN1QLStmt(line string, dBn1ql n1ql.N1qlDB, w io.Writer) (errors.ErrorCod
 	return 0, ""
 }
 
+// Report the client observed time taken by a statement.
+func printElapsed(w io.Writer, start time.Time) {
+	elapsed := time.Since(start).Round(time.Microsecond)
+	io.WriteString(w, command.NewMessage(command.TIMEMSG, elapsed.String())+"\n")
+}
+
 //Function to remove extra space in between words in a string.
 func trimSpaceInStr(inputStr string) (outputStr string) {
 	whiteSpace := false
@@ -340,6 +365,38 @@ func ExecShellCmd(line string, liner *liner.State) (errors.ErrorCode, string) {
 
 	} // ends main if loop for
 
+	// \EXPLAIN and \PROFILE statements are run here, with the
+	// response rendered as a plan tree.
+	if command.PLAN_MODE != "" {
+		errCode, errStr := execPlanStmt(liner)
+		if errCode != 0 {
+			return errCode, errStr
+		}
+	}
+
+	// \SET -var statements are run here, and their result is
+	// assigned to the user defined parameter.
+	if command.CAPTURE_STMT != "" {
+		errCode, errStr := execCapture()
+		if errCode != 0 {
+			return errCode, errStr
+		}
+	}
+
+	// \IMPORT and \EXPORT use the shell's connection.
+	if command.IMPORT_OPTIONS != nil {
+		errCode, errStr := execImport()
+		if errCode != 0 {
+			return errCode, errStr
+		}
+	}
+	if command.EXPORT_OPTIONS != nil {
+		errCode, errStr := execExport()
+		if errCode != 0 {
+			return errCode, errStr
+		}
+	}
+
 	return 0, ""
 }
 
@@ -361,6 +418,10 @@ func readAndExec(liner *liner.State) (errors.ErrorCode, string) {
 	// Create a new reader for the file
 	newFileReader := bufio.NewReader(inputFile)
 
+	// \IF blocks have to be closed in the file that opens them.
+	depth := command.ConditionalDepth()
+	defer command.ResetConditionals(depth)
+
 	// Final input command string to be executed
 	final_input := " "
 
@@ -433,6 +494,11 @@ func readAndExec(liner *liner.State) (errors.ErrorCode, string) {
 			continue
 		}
 
+		if command.Skip(strings.TrimSuffix(final_input, ";")) {
+			final_input = " "
+			continue
+		}
+
 		// Print the query along with printing the results, only if -q isnt specified.
 		if !command.QUIET {
 			io.WriteString(command.W, final_input+"\n")
@@ -477,6 +543,10 @@ func readAndExec(liner *liner.State) (errors.ErrorCode, string) {
 		io.WriteString(command.W, "\n\n")
 		final_input = " "
 	}
+
+	if command.ConditionalDepth() > depth {
+		return errors.E_SHELL_UNTERMINATED_CONDITIONAL, ""
+	}
 	return 0, ""
 }
 
@@ -491,14 +561,42 @@ var (
 	_NL       = []byte("\n")
 )
 
-func terseOutput(w io.Writer, rows io.ReadCloser) error {
+func isPretty() bool {
 	pretty := *prettyFlag
 	if v, ok := command.QueryParam["pretty"]; ok {
 		if v, ec, _ := v.Top(); ec == 0 {
 			pretty = v.Truth()
 		}
 	}
+	return pretty
+}
+
+// status, errors, warnings and the result or mutation count from the response
+func statusSummary(v map[string]interface{}) map[string]interface{} {
 	status := make(map[string]interface{})
+	if val, ok := v["status"]; ok {
+		status["status"] = val
+	}
+	if val, ok := v["errors"]; ok {
+		status["errors"] = val
+	}
+	if val, ok := v["warnings"]; ok {
+		status["warnings"] = val
+	}
+	if im, ok := v["metrics"]; ok {
+		if m, ok := im.(map[string]interface{}); ok {
+			if cnt, ok := m["mutationCount"]; ok {
+				status["mutationCount"] = cnt
+			} else if cnt, ok := m["resultCount"]; ok {
+				status["resultCount"] = cnt
+			}
+		}
+	}
+	return status
+}
+
+func terseOutput(w io.Writer, rows io.Reader) error {
+	pretty := isPretty()
 
 	buf := make([]byte, _INITIAL_BUFFER_SIZE)
 	i := 0
@@ -596,25 +694,7 @@ status:
 		return err
 	}
 
-	if val, ok := v["status"]; ok {
-		status["status"] = val
-	}
-	if val, ok := v["errors"]; ok {
-		status["errors"] = val
-	}
-	if val, ok := v["warnings"]; ok {
-		status["warnings"] = val
-	}
-	if im, ok := v["metrics"]; ok {
-		if m, ok := im.(map[string]interface{}); ok {
-			if cnt, ok := m["mutationCount"]; ok {
-				status["mutationCount"] = cnt
-			} else if cnt, ok := m["resultCount"]; ok {
-				status["resultCount"] = cnt
-			}
-		}
-	}
-
+	status := statusSummary(v)
 	if len(status) > 1 || status["status"] != "success" {
 		if res {
 			w.Write(_ELEM_SEP)
@@ -14,6 +14,7 @@ import (
 	"sort"
 
 	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/value"
 )
 
 const (
@@ -34,6 +35,14 @@ const (
 	SOURCE_CMD              = "SOURCE"
 	REDIRECT_CMD            = "REDIRECT"
 	REFRESH_CLUSTER_MAP_CMD = "REFRESH_CLUSTER_MAP"
+	EXPLAIN_CMD             = "EXPLAIN"
+	PROFILE_CMD             = "PROFILE"
+	TIMING_CMD              = "TIMING"
+	IF_CMD                  = "IF"
+	ELSE_CMD                = "ELSE"
+	ENDIF_CMD               = "ENDIF"
+	IMPORT_CMD              = "IMPORT"
+	EXPORT_CMD              = "EXPORT"
 )
 
 const (
@@ -76,6 +85,26 @@ var (
 	FILE_APPEND_MODE = false
 	//Terse output
 	TERSE = false
+	//Output format for query results
+	FORMAT = FORMAT_JSON
+	//Statement to run for \EXPLAIN or \PROFILE
+	PLAN_STMT = ""
+	//EXPLAIN_CMD or PROFILE_CMD when there is a statement to run
+	PLAN_MODE = ""
+	//Report the client observed time taken by each statement
+	TIMING = false
+	//Status of the last statement, for \IF
+	LAST_STATUS = ""
+	//Result of the last statement, for \IF
+	LAST_RESULT value.Value = value.NULL_VALUE
+	//User defined parameter to set to the result of CAPTURE_STMT
+	CAPTURE_VAR = ""
+	//Statement to run for \SET -var
+	CAPTURE_STMT = ""
+	//Set when there is an \IMPORT to run
+	IMPORT_OPTIONS *ImportOptions = nil
+	//Set when there is an \EXPORT to run
+	EXPORT_OPTIONS *ExportOptions = nil
 )
 
 /* Value to store sorted list of keys for shell commands */
@@ -131,6 +160,16 @@ var COMMAND_LIST = map[string]ShellCommand{
 	/* Scripting Management */
 	"\\source":   &Source{},
 	"\\redirect": &Redirect{},
+	"\\if":       &If{},
+	"\\else":     &Else{},
+	"\\endif":    &Endif{},
+	"\\import":   &Import{},
+	"\\export":   &Export{},
+
+	/* Statement Analysis */
+	"\\explain": &Explain{},
+	"\\profile": &Profile{},
+	"\\timing":  &Timing{},
 
 	"\\refresh_cluster_map": &Refresh_cluster_map{},
 }
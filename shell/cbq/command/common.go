@@ -39,6 +39,60 @@ type Credentials []Credential
 
 var DbN1ql n1ql.N1qlDB
 
+/*
+   Output formats for query results. FORMAT_JSON writes the response
+   from the query service as is, the rest are rendered by the shell
+   from the results array.
+*/
+const (
+	FORMAT_JSON  = "json"
+	FORMAT_JSONL = "jsonl"
+	FORMAT_TABLE = "table"
+	FORMAT_CSV   = "csv"
+	FORMAT_TSV   = "tsv"
+)
+
+/*
+   Validate the input value for the -format query parameter and
+   return the format name.
+*/
+func ToFormat(v value.Value) (string, errors.ErrorCode, string) {
+	if v.Type() != value.STRING {
+		return "", errors.E_SHELL_INVALID_FORMAT, ValToStr(v)
+	}
+	format := strings.ToLower(strings.TrimSpace(v.Actual().(string)))
+	switch format {
+	case FORMAT_JSON, FORMAT_JSONL, FORMAT_TABLE, FORMAT_CSV, FORMAT_TSV:
+		return format, 0, ""
+	}
+	return "", errors.E_SHELL_INVALID_FORMAT, format
+}
+
+/*
+   The format is handled by the shell and is never passed on to the
+   query service. Set or unset the query parameter accordingly.
+*/
+func setQueryParam(name string, val string) (errors.ErrorCode, string) {
+	if name != "format" {
+		n1ql.SetQueryParams(name, val)
+		return 0, ""
+	}
+	format, err_code, err_str := ToFormat(StrToVal(val))
+	if err_code != 0 {
+		return err_code, err_str
+	}
+	FORMAT = format
+	return 0, ""
+}
+
+func unsetQueryParam(name string) {
+	if name != "format" {
+		n1ql.UnsetQueryParams(name)
+		return
+	}
+	FORMAT = FORMAT_JSON
+}
+
 func init() {
 
 	/* Populate the Predefined user variable map with default
@@ -293,6 +347,23 @@ func PushValue_Helper(set bool, param map[string]*Stack, vble, value_ip string)
 
 }
 
+/* Set the top value of a user defined parameter, creating it if needed. */
+func SetUserDefValue(vble string, v value.Value) {
+	if st, ok := UserDefSV[vble]; ok {
+		st.SetTop(v)
+		return
+	}
+	UserDefSV[vble] = Stack_Helper()
+	UserDefSV[vble].Push(v)
+}
+
+/* Set the user defined parameters $1 to $n to the input script arguments. */
+func SetScriptArgs(args []string) {
+	for i, arg := range args {
+		SetUserDefValue(strconv.Itoa(i+1), StrToVal(arg))
+	}
+}
+
 /* Helper function to pop or unset a value in a stack. */
 func PopValue_Helper(unset bool, param map[string]*Stack, vble string) (err_code errors.ErrorCode, err_str string) {
 	err_code = 0
@@ -428,6 +499,15 @@ func PushOrSet(args []string, pushvalue bool) (errors.ErrorCode, string) {
 
 		args_str := strings.Join(args[1:], " ")
 
+		if vble == "format" {
+			// Validate before pushing, so that an invalid
+			// format does not end up on the stack.
+			_, err_code, err_str := ToFormat(StrToVal(args_str))
+			if err_code != 0 {
+				return err_code, err_str
+			}
+		}
+
 		err_code, err_str := PushValue_Helper(pushvalue, QueryParam, vble, args_str)
 
 		if err_code != 0 {
@@ -475,7 +555,10 @@ func PushOrSet(args []string, pushvalue bool) (errors.ErrorCode, string) {
 				val = ValToStr(v)
 			}
 
-			n1ql.SetQueryParams(vble, val)
+			err_code, err_str = setQueryParam(vble, val)
+			if err_code != 0 {
+				return err_code, err_str
+			}
 
 		}
 
@@ -619,6 +702,30 @@ func printDesc(cmdname string) (errors.ErrorCode, string) {
 	case REFRESH_CLUSTER_MAP_CMD:
 		return PrintStr(W, DREFRESH_CLUSTERMAP)
 
+	case EXPLAIN_CMD:
+		return PrintStr(W, DEXPLAIN)
+
+	case PROFILE_CMD:
+		return PrintStr(W, DPROFILE)
+
+	case TIMING_CMD:
+		return PrintStr(W, DTIMING)
+
+	case IF_CMD:
+		return PrintStr(W, DIF)
+
+	case ELSE_CMD:
+		return PrintStr(W, DELSE)
+
+	case ENDIF_CMD:
+		return PrintStr(W, DENDIF)
+
+	case IMPORT_CMD:
+		return PrintStr(W, DIMPORT)
+
+	case EXPORT_CMD:
+		return PrintStr(W, DEXPORT)
+
 	default:
 		return PrintStr(W, DDEFAULT)
 
@@ -103,6 +103,16 @@ func HandleError(err errors.ErrorCode, msg string) errors.Error {
 		return errors.NewShellErrorNoSuchAlias(msg)
 	case errors.E_SHELL_BATCH_MODE:
 		return errors.NewShellErrorBatchMode("")
+	case errors.E_SHELL_INVALID_FORMAT:
+		return errors.NewShellErrorInvalidFormat(msg)
+	case errors.E_SHELL_UNMATCHED_CONDITIONAL:
+		return errors.NewShellErrorUnmatchedConditional(msg)
+	case errors.E_SHELL_UNTERMINATED_CONDITIONAL:
+		return errors.NewShellErrorUnterminatedConditional(msg)
+	case errors.E_SHELL_NOT_SCALAR:
+		return errors.NewShellErrorNotScalar(msg)
+	case errors.E_SHELL_IMPORT:
+		return errors.NewShellErrorImport(msg)
 
 	//Generic Errors
 	case errors.E_SHELL_OPERATION_TIMEOUT:
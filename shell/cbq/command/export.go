@@ -0,0 +1,111 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/query/errors"
+)
+
+type ExportOptions struct {
+	File string
+	// FORMAT_JSONL or FORMAT_CSV
+	Format    string
+	Statement string
+	// Rows per file, 0 to write all the rows to File
+	Split int
+}
+
+/* Export Command */
+type Export struct {
+	ShellCommand
+}
+
+func (this *Export) Name() string {
+	return "EXPORT"
+}
+
+func (this *Export) CommandCompletion() bool {
+	return false
+}
+
+func (this *Export) MinArgs() int {
+	return TWO_ARGS
+}
+
+func (this *Export) MaxArgs() int {
+	return MAX_ARGS
+}
+
+func (this *Export) ExecCommand(args []string) (errors.ErrorCode, string) {
+	/* \EXPORT filename [FORMAT format] [SPLIT n] statement
+	   The statement is run by the main package, which owns
+	   the connection.
+	*/
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+
+	} else if len(args) < this.MinArgs() {
+		return errors.E_SHELL_TOO_FEW_ARGS, ""
+	}
+
+	opts := &ExportOptions{
+		File:   args[0],
+		Format: FORMAT_JSONL,
+	}
+	if strings.EqualFold(filepath.Ext(opts.File), ".csv") {
+		opts.Format = FORMAT_CSV
+	}
+
+	i := 1
+	for ; i+1 < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "format":
+			opts.Format = strings.ToLower(args[i+1])
+			if opts.Format != FORMAT_JSONL && opts.Format != FORMAT_CSV {
+				return errors.E_SHELL_INVALID_FORMAT, " Supported formats for \\EXPORT are jsonl and csv."
+			}
+			continue
+		case "split":
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Invalid SPLIT " + args[i+1]
+			}
+			opts.Split = n
+			continue
+		}
+		break
+	}
+	opts.Statement = strings.Join(args[i:], " ")
+	if opts.Statement == "" {
+		return errors.E_SHELL_TOO_FEW_ARGS, ""
+	}
+
+	EXPORT_OPTIONS = opts
+	return 0, ""
+}
+
+func (this *Export) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HEXPORT)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
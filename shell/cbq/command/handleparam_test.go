@@ -242,3 +242,32 @@ func TestUnset(t *testing.T) {
 		t.Error(HandleError(errCode, errStr))
 	}
 }
+
+func TestPush_format(t *testing.T) {
+	pushval(strings.Split("-format table", " "), true, t)
+	if FORMAT != FORMAT_TABLE {
+		t.Errorf("Expected format %v, got %v", FORMAT_TABLE, FORMAT)
+	}
+
+	pushval(strings.Split("-format \"CSV\"", " "), false, t)
+	if FORMAT != FORMAT_CSV {
+		t.Errorf("Expected format %v, got %v", FORMAT_CSV, FORMAT)
+	}
+
+	errCode, _ := PushOrSet(strings.Split("-format xml", " "), true)
+	if errCode == 0 || FORMAT != FORMAT_CSV {
+		t.Errorf("Expected invalid format to be rejected")
+	}
+
+	pop := Pop{}
+	pop.ExecCommand(strings.Split("-format", " "))
+	if FORMAT != FORMAT_TABLE {
+		t.Errorf("Expected format %v after pop, got %v", FORMAT_TABLE, FORMAT)
+	}
+
+	unset := Unset{}
+	unset.ExecCommand(strings.Split("-format", " "))
+	if FORMAT != FORMAT_JSON {
+		t.Errorf("Expected format %v after unset, got %v", FORMAT_JSON, FORMAT)
+	}
+}
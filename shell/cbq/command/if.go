@@ -0,0 +1,302 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"io"
+	"strings"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/value"
+)
+
+/*
+   \IF, \ELSE and \ENDIF. While the condition of an enclosing
+   block does not hold, every command and statement other than
+   these three is skipped. Blocks nest; a \IF inside a skipped
+   block is not evaluated, it only has to be matched.
+*/
+
+type conditional struct {
+	// the condition of the current branch holds
+	active bool
+	// inside a block that is being skipped
+	skipped bool
+	inElse  bool
+}
+
+var conditionals []conditional
+
+// Operators in the order they are matched.
+var _CONDITION_OPS = []string{"==", "!=", "<>", "<=", ">=", "=", "<", ">"}
+
+/* Returns true if the input line is to be skipped. */
+func Skip(line string) bool {
+	if len(conditionals) == 0 {
+		return false
+	}
+	top := conditionals[len(conditionals)-1]
+	if top.active && !top.skipped {
+		return false
+	}
+	switch strings.ToLower(strings.Fields(line + " ")[0]) {
+	case "\\if", "\\else", "\\endif":
+		return false
+	}
+	return true
+}
+
+/* Number of open \IF blocks. */
+func ConditionalDepth() int {
+	return len(conditionals)
+}
+
+/* Discard the \IF blocks opened after depth. */
+func ResetConditionals(depth int) {
+	if depth < len(conditionals) {
+		conditionals = conditionals[:depth]
+	}
+}
+
+/* If Command */
+type If struct {
+	ShellCommand
+}
+
+func (this *If) Name() string {
+	return "IF"
+}
+
+func (this *If) CommandCompletion() bool {
+	return false
+}
+
+func (this *If) MinArgs() int {
+	return ONE_ARG
+}
+
+func (this *If) MaxArgs() int {
+	return MAX_ARGS
+}
+
+func (this *If) ExecCommand(args []string) (errors.ErrorCode, string) {
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+
+	} else if len(args) < this.MinArgs() {
+		return errors.E_SHELL_TOO_FEW_ARGS, ""
+	}
+
+	if n := len(conditionals); n > 0 {
+		top := conditionals[n-1]
+		if top.skipped || !top.active {
+			conditionals = append(conditionals, conditional{skipped: true})
+			return 0, ""
+		}
+	}
+
+	holds, err_code, err_str := evalCondition(strings.Join(args, " "))
+	if err_code != 0 {
+		return err_code, err_str
+	}
+	conditionals = append(conditionals, conditional{active: holds})
+	return 0, ""
+}
+
+func (this *If) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HIF)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
+
+/* Else Command */
+type Else struct {
+	ShellCommand
+}
+
+func (this *Else) Name() string {
+	return "ELSE"
+}
+
+func (this *Else) CommandCompletion() bool {
+	return false
+}
+
+func (this *Else) MinArgs() int {
+	return ZERO_ARGS
+}
+
+func (this *Else) MaxArgs() int {
+	return ZERO_ARGS
+}
+
+func (this *Else) ExecCommand(args []string) (errors.ErrorCode, string) {
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+	}
+
+	n := len(conditionals)
+	if n == 0 || conditionals[n-1].inElse {
+		return errors.E_SHELL_UNMATCHED_CONDITIONAL, ""
+	}
+	conditionals[n-1].active = !conditionals[n-1].active
+	conditionals[n-1].inElse = true
+	return 0, ""
+}
+
+func (this *Else) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HELSE)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
+
+/* Endif Command */
+type Endif struct {
+	ShellCommand
+}
+
+func (this *Endif) Name() string {
+	return "ENDIF"
+}
+
+func (this *Endif) CommandCompletion() bool {
+	return false
+}
+
+func (this *Endif) MinArgs() int {
+	return ZERO_ARGS
+}
+
+func (this *Endif) MaxArgs() int {
+	return ZERO_ARGS
+}
+
+func (this *Endif) ExecCommand(args []string) (errors.ErrorCode, string) {
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+	}
+
+	if len(conditionals) == 0 {
+		return errors.E_SHELL_UNMATCHED_CONDITIONAL, ""
+	}
+	conditionals = conditionals[:len(conditionals)-1]
+	return 0, ""
+}
+
+func (this *Endif) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HENDIF)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
+
+/* Evaluate the condition of a \IF : [NOT] operand [operator operand] */
+func evalCondition(cond string) (bool, errors.ErrorCode, string) {
+	cond = strings.TrimSpace(cond)
+	negate := false
+	if len(cond) > 4 && strings.EqualFold(cond[:4], "not ") {
+		negate = true
+		cond = strings.TrimSpace(cond[4:])
+	}
+
+	pos, op := findOperator(cond)
+	if pos < 0 {
+		v, err_code, err_str := conditionOperand(cond)
+		if err_code != 0 {
+			return false, err_code, err_str
+		}
+		return v.Truth() != negate, 0, ""
+	}
+
+	lhs, err_code, err_str := conditionOperand(cond[:pos])
+	if err_code != 0 {
+		return false, err_code, err_str
+	}
+	rhs, err_code, err_str := conditionOperand(cond[pos+len(op):])
+	if err_code != 0 {
+		return false, err_code, err_str
+	}
+
+	var holds bool
+	c := lhs.Collate(rhs)
+	switch op {
+	case "=", "==":
+		holds = c == 0
+	case "!=", "<>":
+		holds = c != 0
+	case "<":
+		holds = c < 0
+	case "<=":
+		holds = c <= 0
+	case ">":
+		holds = c > 0
+	case ">=":
+		holds = c >= 0
+	}
+	return holds != negate, 0, ""
+}
+
+// Position of the first comparison operator outside of quotes.
+func findOperator(cond string) (int, string) {
+	quoted := false
+	for i := 0; i < len(cond); i++ {
+		switch {
+		case cond[i] == '\\':
+			i++
+		case cond[i] == '"':
+			quoted = !quoted
+		case !quoted:
+			for _, op := range _CONDITION_OPS {
+				if strings.HasPrefix(cond[i:], op) {
+					return i, op
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+func conditionOperand(operand string) (value.Value, errors.ErrorCode, string) {
+	operand = strings.TrimSpace(operand)
+	switch strings.ToLower(operand) {
+	case "":
+		return nil, errors.E_SHELL_INVALID_INPUT_ARGUMENTS, ""
+	case "status":
+		return value.NewValue(LAST_STATUS), 0, ""
+	case "result":
+		return LAST_RESULT, 0, ""
+	}
+	return Resolve(operand)
+}
@@ -0,0 +1,110 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"testing"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/value"
+)
+
+/*
+   Test the \IF, \ELSE and \ENDIF commands and \SET -var.
+*/
+
+func TestIfCondition(t *testing.T) {
+	LAST_STATUS = "success"
+	LAST_RESULT = value.NewValue(3)
+	SetUserDefValue("name", value.NewValue("beer"))
+
+	tests := []struct {
+		cond  string
+		holds bool
+	}{
+		{"status = success", true},
+		{"STATUS != \"success\"", false},
+		{"result > 2", true},
+		{"result>=4", false},
+		{"NOT result < 3", true},
+		{"result", true},
+		{"$name = beer", true},
+		{"$name <> \"beer\"", false},
+		{"0", false},
+	}
+	for _, test := range tests {
+		holds, errCode, errStr := evalCondition(test.cond)
+		if errCode != 0 {
+			t.Errorf("%v : %v", test.cond, HandleError(errCode, errStr))
+		} else if holds != test.holds {
+			t.Errorf("%v : expected %v", test.cond, test.holds)
+		}
+	}
+
+	if _, errCode, _ := evalCondition("result = "); errCode != errors.E_SHELL_INVALID_INPUT_ARGUMENTS {
+		t.Errorf("Expected error for missing operand, got %v", errCode)
+	}
+}
+
+func TestIfBlocks(t *testing.T) {
+	ifCmd, elseCmd, endifCmd := COMMAND_LIST["\\if"], COMMAND_LIST["\\else"], COMMAND_LIST["\\endif"]
+	LAST_RESULT = value.NewValue(0)
+
+	ifCmd.ExecCommand([]string{"result"})
+	if !Skip("select 1") || Skip("\\ELSE") {
+		t.Errorf("Expected statements to be skipped in a false \\IF")
+	}
+
+	// nested \IF in a skipped block is not evaluated
+	errCode, _ := ifCmd.ExecCommand([]string{"=", "1"})
+	if errCode != 0 || ConditionalDepth() != 2 {
+		t.Errorf("Expected nested \\IF to be skipped")
+	}
+	elseCmd.ExecCommand([]string{})
+	if !Skip("select 1") {
+		t.Errorf("Expected \\ELSE of a skipped \\IF to be skipped")
+	}
+	endifCmd.ExecCommand([]string{})
+
+	elseCmd.ExecCommand([]string{})
+	if Skip("select 1") {
+		t.Errorf("Expected \\ELSE of a false \\IF to run")
+	}
+	if errCode, _ = elseCmd.ExecCommand([]string{}); errCode != errors.E_SHELL_UNMATCHED_CONDITIONAL {
+		t.Errorf("Expected error for second \\ELSE, got %v", errCode)
+	}
+	endifCmd.ExecCommand([]string{})
+
+	if errCode, _ = endifCmd.ExecCommand([]string{}); errCode != errors.E_SHELL_UNMATCHED_CONDITIONAL {
+		t.Errorf("Expected error for unmatched \\ENDIF, got %v", errCode)
+	}
+}
+
+func TestSetVar(t *testing.T) {
+	set := COMMAND_LIST["\\set"]
+
+	errCode, errStr := set.ExecCommand([]string{"-var", "cnt", ":=", "(SELECT", "RAW", "COUNT(*)", "FROM", "b)"})
+	if errCode != 0 {
+		t.Error(HandleError(errCode, errStr))
+	} else if CAPTURE_VAR != "cnt" || CAPTURE_STMT != "SELECT RAW COUNT(*) FROM b" {
+		t.Errorf("Unexpected \\SET -var : %v %v", CAPTURE_VAR, CAPTURE_STMT)
+	}
+
+	errCode, _ = set.ExecCommand([]string{"-var", "x:=", "(SELECT", "1)", "UNION", "(SELECT", "2)"})
+	if errCode != 0 || CAPTURE_VAR != "x" || CAPTURE_STMT != "(SELECT 1) UNION (SELECT 2)" {
+		t.Errorf("Unexpected \\SET -var : %v %v", CAPTURE_VAR, CAPTURE_STMT)
+	}
+	CAPTURE_VAR = ""
+	CAPTURE_STMT = ""
+
+	errCode, _ = set.ExecCommand([]string{"-var", "cnt", "SELECT", "1"})
+	if errCode != errors.E_SHELL_INVALID_INPUT_ARGUMENTS {
+		t.Errorf("Expected error for \\SET -var without :=, got %v", errCode)
+	}
+}
@@ -0,0 +1,145 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/query/errors"
+)
+
+const (
+	// Documents upserted per statement by \IMPORT
+	DEFAULT_IMPORT_BATCH = 100
+	// Key of imported documents if no KEY is given
+	DEFAULT_IMPORT_KEY = "UUID()"
+)
+
+type ImportOptions struct {
+	File     string
+	Keyspace string
+	// N1QL expression evaluated on each document
+	Key string
+	// FORMAT_JSON (an array), FORMAT_JSONL or FORMAT_CSV. If empty, it
+	// is determined from the contents of the file.
+	Format string
+	Batch  int
+}
+
+/* Import Command */
+type Import struct {
+	ShellCommand
+}
+
+func (this *Import) Name() string {
+	return "IMPORT"
+}
+
+func (this *Import) CommandCompletion() bool {
+	return false
+}
+
+func (this *Import) MinArgs() int {
+	return 3
+}
+
+func (this *Import) MaxArgs() int {
+	return MAX_ARGS
+}
+
+func (this *Import) ExecCommand(args []string) (errors.ErrorCode, string) {
+	/* \IMPORT filename INTO keyspace [KEY expression] [FORMAT format] [BATCH n]
+	   The documents are upserted by the main package, which
+	   owns the connection.
+	*/
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+
+	} else if len(args) < this.MinArgs() {
+		return errors.E_SHELL_TOO_FEW_ARGS, ""
+	}
+
+	if !strings.EqualFold(args[1], "into") {
+		return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Expected INTO keyspace."
+	}
+
+	opts := &ImportOptions{
+		File:     args[0],
+		Keyspace: args[2],
+		Key:      DEFAULT_IMPORT_KEY,
+		Batch:    DEFAULT_IMPORT_BATCH,
+	}
+	if strings.EqualFold(filepath.Ext(opts.File), ".csv") {
+		opts.Format = FORMAT_CSV
+	}
+
+	for i := 3; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		if i+1 >= len(args) {
+			return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Missing value for " + args[i]
+		}
+		switch option {
+		case "key":
+			// the expression extends up to the next option
+			j := i + 1
+			for j < len(args) && !isImportOption(args[j]) {
+				j++
+			}
+			opts.Key = strings.Join(args[i+1:j], " ")
+			i = j - 1
+		case "format":
+			i++
+			opts.Format = strings.ToLower(args[i])
+			if opts.Format != FORMAT_JSON && opts.Format != FORMAT_JSONL && opts.Format != FORMAT_CSV {
+				return errors.E_SHELL_INVALID_FORMAT, " Supported formats for \\IMPORT are json, jsonl and csv."
+			}
+		case "batch":
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n <= 0 {
+				return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Invalid BATCH " + args[i]
+			}
+			opts.Batch = n
+		default:
+			return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Unknown option " + args[i]
+		}
+	}
+	if opts.Key == "" {
+		return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " Missing value for KEY"
+	}
+
+	IMPORT_OPTIONS = opts
+	return 0, ""
+}
+
+func isImportOption(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "format", "batch":
+		return true
+	}
+	return false
+}
+
+func (this *Import) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HIMPORT)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
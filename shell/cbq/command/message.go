@@ -34,6 +34,7 @@ const (
 	USCRIPT     = " Single command mode. Execute input command and exit shell. \n\t For example : -script \"select * from system:keyspaces\""
 	UPRETTY     = " Pretty print the output."
 	UTERSE      = " Terse statement output."
+	UFORMAT     = " Output format for query results. \n\t\t Default : json \n\t\t Possible values : json,jsonl,table,csv,tsv"
 	UEXIT       = " Exit shell after first error encountered."
 	UINPUT      = " File to load commands from. \n\t For example : -file temp.txt"
 	UOUTPUT     = " File to output commands and their results. \n\t For example : -output temp.txt"
@@ -111,6 +112,14 @@ const (
 	HREDIRECT           = "\\REDIRECT OFF | [TEE] filename \n"
 	HSOURCE             = "\\SOURCE filename\n"
 	HREFRESH_CLUSTERMAP = "\\REFRESH_CLUSTER_MAP\n"
+	HEXPLAIN            = "\\EXPLAIN statement\n"
+	HPROFILE            = "\\PROFILE statement\n"
+	HTIMING             = "\\TIMING [ ON | OFF ]\n"
+	HIF                 = "\\IF [ NOT ] operand [ operator operand ]\n"
+	HELSE               = "\\ELSE\n"
+	HENDIF              = "\\ENDIF\n"
+	HIMPORT             = "\\IMPORT filename INTO keyspace [ KEY expression ] [ FORMAT json | jsonl | csv ] [ BATCH n ]\n"
+	HEXPORT             = "\\EXPORT filename [ FORMAT jsonl | csv ] [ SPLIT n ] statement\n"
 
 	//Messages to print description of shell commands. D-> Description
 	DALIAS = " Create an alias (name) for input value. value can be shell command, " +
@@ -153,7 +162,10 @@ const (
 
 	DSET = "Set the value of the given parameter to the input value. parameter is a prefixed name " +
 		"(-creds, -$rate, $user, histfile).\nIf no arguments are given, list all the existing parameters.\n" +
-		"\tExample : \n\t        \\SET -$r 9.5 ;\n\t        \\SET $Val -$r ;\n"
+		"\tExample : \n\t        \\SET -$r 9.5 ;\n\t        \\SET $Val -$r ;\n" +
+		"\t        \\SET -format table ;\n" +
+		"Use -var name := (statement) to set the user defined parameter $name to the result of the statement.\n" +
+		"\tExample : \n\t        \\SET -var cnt := (SELECT RAW COUNT(*) FROM `beer-sample`) ;\n"
 
 	DSOURCE = "Load input file into shell.\n\tExample : \n\t \\SOURCE temp1.txt ;\n"
 
@@ -170,6 +182,47 @@ const (
 		"To return to STDOUT, execute \\REDIRECT OFF .\n" +
 		"\tExample : \n\t\t \\REDIRECT temp1.txt ;\n\t\t select * from `beer-sample`;\n\t\t \\REDIRECT OFF;"
 
+	DEXPLAIN = "Display the plan for the input statement as a tree of operators.\n" +
+		"\tExample : \n\t        \\EXPLAIN select * from `beer-sample` where abv > 5;\n"
+
+	DPROFILE = "Run the input statement with timings profiling and display the plan as a tree of operators\n" +
+		"with the documents in and out and the time spent in each. The most expensive operators are highlighted.\n" +
+		"\tExample : \n\t        \\PROFILE select * from `beer-sample` where abv > 5;\n"
+
+	DTIMING = "Report the time taken by each statement as observed by the shell. " +
+		"If no arguments are given, toggle the setting.\n" +
+		"\tExample : \n\t        \\TIMING ON;\n\t        \\TIMING;\n"
+
+	DIF = "Run the following commands and statements up to the matching \\ELSE or \\ENDIF only if the condition holds.\n" +
+		"An operand is a parameter, a value, STATUS (the status of the previous statement) or RESULT\n" +
+		"(its result). The operators are =, !=, <, <=, > and >=. A single operand is tested for truth.\n" +
+		"\tExample : \n\t        \\IF STATUS = success;\n\t        \\IF RESULT > 0;\n\t        \\IF NOT $1;\n"
+
+	DELSE = "Run the following commands and statements up to the matching \\ENDIF only if the condition of the \\IF does not hold.\n" +
+		"\tExample : \n\t        \\ELSE;\n"
+
+	DENDIF = "End a \\IF block.\n" +
+		"\tExample : \n\t        \\ENDIF;\n"
+
+	DIMPORT = "Load the documents in the input file into the keyspace. The file is a JSON array, JSON documents one per\n" +
+		"line or CSV with a header row. The key of each document is the result of the expression evaluated on\n" +
+		"the document, UUID() by default. The documents are upserted BATCH (default 100) at a time.\n" +
+		"\tExample : \n\t        \\IMPORT beers.json INTO `beer-sample` KEY name || \"-\" || brewery_id;\n" +
+		"\t        \\IMPORT beers.csv INTO `beer-sample` KEY TO_STRING(id) BATCH 500;\n"
+
+	DEXPORT = "Write the results of the statement to the file as JSON documents one per line or as CSV. The CSV columns\n" +
+		"are the fields of the first result. With SPLIT n, a new file is started every n rows.\n" +
+		"\tExample : \n\t        \\EXPORT beers.jsonl SELECT b.* FROM `beer-sample` b WHERE type = \"beer\";\n" +
+		"\t        \\EXPORT beers.csv SPLIT 10000 SELECT name, abv FROM `beer-sample`;\n"
+
+	IMPORTMSG    = " Imported %d of %d documents into %s.\n"
+	IMPORTERRMSG = " Error importing documents %d to %d : %s\n"
+	IMPORTKEYMSG = " Error importing document %d : %s\n"
+	EXPORTMSG    = " Exported %d rows to %d file(s).\n"
+
+	TIMINGMSG = " Timing is "
+	TIMEMSG   = " Time"
+
 	DDEFAULT            = "Fix : Does not exist.\n"
 	DREFRESH_CLUSTERMAP = "Refresh the list of query APIs to reflect input service url as cluster. " +
 		"\tExample : \n\t\t \\REFRESH_CLUSTER_MAP;"
@@ -112,7 +112,7 @@ func (this *Pop) ExecCommand(args []string) (errors.ErrorCode, string) {
 
 			if ok {
 				if QueryParam[vble].Len() == 0 {
-					n1ql.UnsetQueryParams(vble)
+					unsetQueryParam(vble)
 				} else {
 					err_code, err_str := setNewParamPop(vble, st_val)
 					if err_code != 0 {
@@ -121,7 +121,7 @@ func (this *Pop) ExecCommand(args []string) (errors.ErrorCode, string) {
 				}
 
 			} else {
-				n1ql.UnsetQueryParams(vble)
+				unsetQueryParam(vble)
 			}
 
 		} else if strings.HasPrefix(args[0], "$") {
@@ -264,7 +264,7 @@ func Popparam_Helper(param map[string]*Stack, isrestp bool, isnamep bool) (error
 			if isnamep == true {
 				name = "$" + name
 			}
-			n1ql.UnsetQueryParams(name)
+			unsetQueryParam(name)
 		}
 
 		if err_code != 0 {
@@ -313,8 +313,7 @@ func setNewParamPop(name string, paramst *Stack) (errors.ErrorCode, string) {
 		}
 		nval = string(ac)
 	}
-	n1ql.SetQueryParams(name, nval)
-	return 0, ""
+	return setQueryParam(name, nval)
 }
 
 func handleStrings(nval string) string {
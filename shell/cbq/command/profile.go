@@ -0,0 +1,69 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"io"
+	"strings"
+
+	"github.com/couchbase/query/errors"
+)
+
+/* Profile Command */
+type Profile struct {
+	ShellCommand
+}
+
+func (this *Profile) Name() string {
+	return "PROFILE"
+}
+
+func (this *Profile) CommandCompletion() bool {
+	return false
+}
+
+func (this *Profile) MinArgs() int {
+	return ONE_ARG
+}
+
+func (this *Profile) MaxArgs() int {
+	return MAX_ARGS
+}
+
+func (this *Profile) ExecCommand(args []string) (errors.ErrorCode, string) {
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+
+	} else if len(args) < this.MinArgs() {
+		return errors.E_SHELL_TOO_FEW_ARGS, ""
+	} else {
+		/* The statement is run by the main package, which
+		   owns the connection, and the response is rendered
+		   as a plan tree.
+		*/
+		PLAN_MODE = PROFILE_CMD
+		PLAN_STMT = strings.Join(args, " ")
+	}
+	return 0, ""
+}
+
+func (this *Profile) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HPROFILE)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
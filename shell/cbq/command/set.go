@@ -104,6 +104,14 @@ func (this *Set) ExecCommand(args []string) (errors.ErrorCode, string) {
 			return errors.E_SHELL_TOO_FEW_ARGS, ""
 		}
 
+	} else if strings.ToLower(args[0]) == "-var" {
+		//Set a user defined parameter to the result of a statement.
+		//The statement is run in package main.
+		err_code, err_str := setCapture(strings.Join(args[1:], " "))
+		if err_code != 0 {
+			return err_code, err_str
+		}
+
 	} else {
 		//Check what kind of parameter needs to be set.
 		err_code, err_str := PushOrSet(args, true)
@@ -129,6 +137,51 @@ func (this *Set) PrintHelp(desc bool) (errors.ErrorCode, string) {
 	return 0, ""
 }
 
+/* Parse name := (statement) for \SET -var */
+func setCapture(arg string) (errors.ErrorCode, string) {
+	i := strings.Index(arg, ":=")
+	if i < 0 {
+		return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, ""
+	}
+	name := strings.TrimPrefix(strings.TrimSpace(arg[:i]), "$")
+	stmt := strings.TrimSpace(arg[i+2:])
+	if strings.HasPrefix(stmt, "(") && closingParen(stmt) == len(stmt)-1 {
+		stmt = strings.TrimSpace(stmt[1 : len(stmt)-1])
+	}
+	if name == "" || strings.ContainsAny(name, " \t") || stmt == "" {
+		return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, ""
+	}
+	CAPTURE_VAR = name
+	CAPTURE_STMT = stmt
+	return 0, ""
+}
+
+// Position of the parenthesis closing the one at the start of s.
+func closingParen(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 func printSET(name, value string) (werr error) {
 	valuestr := NewMessage(PNAME, name) + "\n" + NewMessage(PVAL, value)
 	_, werr = io.WriteString(W, valuestr)
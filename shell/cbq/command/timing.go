@@ -0,0 +1,83 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"io"
+	"strings"
+
+	"github.com/couchbase/query/errors"
+)
+
+/* Timing Command */
+type Timing struct {
+	ShellCommand
+}
+
+func (this *Timing) Name() string {
+	return "TIMING"
+}
+
+func (this *Timing) CommandCompletion() bool {
+	return false
+}
+
+func (this *Timing) MinArgs() int {
+	return ZERO_ARGS
+}
+
+func (this *Timing) MaxArgs() int {
+	return ONE_ARG
+}
+
+func (this *Timing) ExecCommand(args []string) (errors.ErrorCode, string) {
+	/* Turn reporting of the client observed statement time
+	   on or off. With no arguments toggle the current setting.
+	*/
+	if len(args) > this.MaxArgs() {
+		return errors.E_SHELL_TOO_MANY_ARGS, ""
+
+	} else if len(args) == 0 {
+		TIMING = !TIMING
+	} else {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			TIMING = true
+		case "off":
+			TIMING = false
+		default:
+			return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, ""
+		}
+	}
+
+	state := "off"
+	if TIMING {
+		state = "on"
+	}
+	_, werr := io.WriteString(W, TIMINGMSG+state+".\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
+
+func (this *Timing) PrintHelp(desc bool) (errors.ErrorCode, string) {
+	_, werr := io.WriteString(W, HTIMING)
+	if desc {
+		err_code, err_str := printDesc(this.Name())
+		if err_code != 0 {
+			return err_code, err_str
+		}
+	}
+	_, werr = io.WriteString(W, "\n")
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
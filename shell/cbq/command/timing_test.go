@@ -0,0 +1,70 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/couchbase/query/errors"
+)
+
+/*
+   Test the \TIMING, \EXPLAIN and \PROFILE commands.
+*/
+
+func TestTiming(t *testing.T) {
+	timing := COMMAND_LIST["\\timing"]
+
+	var b bytes.Buffer
+	writetmp := bufio.NewWriter(&b)
+	SetWriter(writetmp)
+
+	TIMING = false
+	errCode, errStr := timing.ExecCommand([]string{"ON"})
+	writetmp.Flush()
+	if errCode != 0 {
+		t.Error(HandleError(errCode, errStr))
+	} else if !TIMING || b.String() != " Timing is on.\n" {
+		t.Errorf("Unexpected timing state %v : %q", TIMING, b.String())
+	}
+
+	errCode, errStr = timing.ExecCommand([]string{})
+	if errCode != 0 {
+		t.Error(HandleError(errCode, errStr))
+	} else if TIMING {
+		t.Errorf("Expected \\TIMING to toggle timing off")
+	}
+
+	errCode, _ = timing.ExecCommand([]string{"sometimes"})
+	if errCode != errors.E_SHELL_INVALID_INPUT_ARGUMENTS {
+		t.Errorf("Expected invalid argument error, got %v", errCode)
+	}
+}
+
+func TestExplainProfile(t *testing.T) {
+	for _, name := range []string{"\\explain", "\\profile"} {
+		cmd := COMMAND_LIST[name]
+
+		errCode, _ := cmd.ExecCommand([]string{})
+		if errCode != errors.E_SHELL_TOO_FEW_ARGS {
+			t.Errorf("Min args for %v command has changed.", name)
+		}
+
+		errCode, errStr := cmd.ExecCommand([]string{"select", "*", "from", "beer"})
+		if errCode != 0 {
+			t.Error(HandleError(errCode, errStr))
+		} else if PLAN_MODE != cmd.Name() || PLAN_STMT != "select * from beer" {
+			t.Errorf("Unexpected statement for %v : %v %v", name, PLAN_MODE, PLAN_STMT)
+		}
+		PLAN_MODE = ""
+		PLAN_STMT = ""
+	}
+}
@@ -0,0 +1,84 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/couchbase/query/errors"
+)
+
+/*
+   Test the argument parsing of the \IMPORT and \EXPORT commands.
+*/
+
+func TestImportArgs(t *testing.T) {
+	imp := COMMAND_LIST["\\import"]
+
+	errCode, errStr := imp.ExecCommand([]string{"beers.csv", "into", "`beer-sample`", "KEY", "name", "||", "\"-\"", "||", "id", "batch", "50"})
+	if errCode != 0 {
+		t.Fatal(HandleError(errCode, errStr))
+	}
+	expected := &ImportOptions{File: "beers.csv", Keyspace: "`beer-sample`", Key: "name || \"-\" || id", Format: FORMAT_CSV, Batch: 50}
+	if !reflect.DeepEqual(IMPORT_OPTIONS, expected) {
+		t.Errorf("Expected %v, got %v", expected, IMPORT_OPTIONS)
+	}
+	IMPORT_OPTIONS = nil
+
+	errCode, _ = imp.ExecCommand([]string{"beers.json", "INTO", "b", "FORMAT", "jsonl"})
+	if errCode != 0 || IMPORT_OPTIONS.Key != DEFAULT_IMPORT_KEY || IMPORT_OPTIONS.Format != FORMAT_JSONL {
+		t.Errorf("Unexpected options %v", IMPORT_OPTIONS)
+	}
+	IMPORT_OPTIONS = nil
+
+	for _, args := range [][]string{
+		{"beers.json", "from", "b"},
+		{"beers.json", "into", "b", "batch", "0"},
+		{"beers.json", "into", "b", "key"},
+		{"beers.json", "into", "b", "limit", "5"},
+	} {
+		if errCode, _ = imp.ExecCommand(args); errCode != errors.E_SHELL_INVALID_INPUT_ARGUMENTS {
+			t.Errorf("%v : expected invalid argument error, got %v", args, errCode)
+		}
+	}
+	if errCode, _ = imp.ExecCommand([]string{"b.json", "into", "b", "format", "xml"}); errCode != errors.E_SHELL_INVALID_FORMAT {
+		t.Errorf("Expected invalid format error, got %v", errCode)
+	}
+	if IMPORT_OPTIONS != nil {
+		t.Errorf("Invalid \\IMPORT should not be run")
+	}
+}
+
+func TestExportArgs(t *testing.T) {
+	exp := COMMAND_LIST["\\export"]
+
+	errCode, errStr := exp.ExecCommand([]string{"beers.csv", "split", "1000", "select", "name", "from", "b"})
+	if errCode != 0 {
+		t.Fatal(HandleError(errCode, errStr))
+	}
+	expected := &ExportOptions{File: "beers.csv", Format: FORMAT_CSV, Statement: "select name from b", Split: 1000}
+	if !reflect.DeepEqual(EXPORT_OPTIONS, expected) {
+		t.Errorf("Expected %v, got %v", expected, EXPORT_OPTIONS)
+	}
+	EXPORT_OPTIONS = nil
+
+	errCode, _ = exp.ExecCommand([]string{"beers.out", "select", "1"})
+	if errCode != 0 || EXPORT_OPTIONS.Format != FORMAT_JSONL || EXPORT_OPTIONS.Split != 0 {
+		t.Errorf("Unexpected options %v", EXPORT_OPTIONS)
+	}
+	EXPORT_OPTIONS = nil
+
+	if errCode, _ = exp.ExecCommand([]string{"beers.out", "format", "csv"}); errCode != errors.E_SHELL_TOO_FEW_ARGS {
+		t.Errorf("Expected error for missing statement, got %v", errCode)
+	}
+	if errCode, _ = exp.ExecCommand([]string{"beers.out", "format", "table", "select", "1"}); errCode != errors.E_SHELL_INVALID_FORMAT {
+		t.Errorf("Expected invalid format error, got %v", errCode)
+	}
+}
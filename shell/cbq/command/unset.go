@@ -72,7 +72,7 @@ func (this *Unset) ExecCommand(args []string) (errors.ErrorCode, string) {
 			if err_code != 0 {
 				return err_code, err_str
 			}
-			n1ql.UnsetQueryParams(vble)
+			unsetQueryParam(vble)
 
 		} else if strings.HasPrefix(args[0], "$") {
 			// For User defined session variables
@@ -0,0 +1,361 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/couchbase/godbc/n1ql"
+	"github.com/couchbase/query/expression"
+	parser "github.com/couchbase/query/parser/n1ql"
+	"github.com/couchbase/query/shell/cbq/command"
+)
+
+/*
+   Tab completion for the interactive shell. The word before the
+   cursor is completed with shell command names, N1QL keywords,
+   function names, keyspace paths from system:keyspaces and field
+   names inferred from the keyspaces in the FROM clause. Keyspaces
+   and fields are fetched on first use and cached until the shell
+   connects to a different endpoint.
+*/
+
+// Keywords after which a keyspace path is expected.
+var _KEYSPACE_CONTEXT = map[string]bool{
+	"FROM":     true,
+	"JOIN":     true,
+	"NEST":     true,
+	"INTO":     true,
+	"UPDATE":   true,
+	"KEYSPACE": true,
+	"INFER":    true,
+}
+
+const _IDENT = "[A-Za-z_$][A-Za-z0-9_$]*"
+const _QUOTED_IDENT = "`[^`]*`"
+const _PATH_ELEM = "(?:" + _QUOTED_IDENT + "|" + _IDENT + ")"
+
+// Keyspace references and their optional aliases in a statement.
+var _KEYSPACE_REF = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|NEST|INTO|UPDATE)\s+` +
+	"((?:" + _IDENT + ":)?" + _PATH_ELEM + `(?:\.` + _PATH_ELEM + ")*)" +
+	`(?:\s+(?:AS\s+)?(` + _PATH_ELEM + "))?")
+
+var _PLAIN_IDENT = regexp.MustCompile("^" + _IDENT + "$")
+
+const _INFER_OPTIONS = `{"sample_size": 100, "num_sample_values": 0, "similarity_metric": 0}`
+
+// Depth of nested field paths offered for completion.
+const _MAX_FIELD_DEPTH = 3
+
+type completer struct {
+	// previous lines of a multi-line statement
+	pending func() string
+	query   func(stmt string) ([]json.RawMessage, error)
+
+	// cached per connection
+	conn      n1ql.N1qlDB
+	keyspaces []string
+	fetched   bool
+	fields    map[string][]string
+}
+
+func newCompleter(pending func() string) *completer {
+	return &completer{
+		pending: pending,
+		query:   queryResults,
+		fields:  make(map[string][]string),
+	}
+}
+
+func queryResults(stmt string) ([]json.RawMessage, error) {
+	if DISCONNECT || noQueryService || command.DbN1ql == nil {
+		return nil, fmt.Errorf("Not connected to a query service")
+	}
+	rows, err := command.DbN1ql.QueryRaw(stmt)
+	if rows != nil {
+		defer rows.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Results []json.RawMessage `json:"results"`
+	}
+	err = json.NewDecoder(rows).Decode(&res)
+	return res.Results, err
+}
+
+// Drop everything fetched from the server if the connection has changed.
+func (this *completer) checkConnection() {
+	if this.conn != command.DbN1ql {
+		this.conn = command.DbN1ql
+		this.keyspaces = nil
+		this.fetched = false
+		this.fields = make(map[string][]string)
+	}
+}
+
+func (this *completer) complete(line string, pos int) (head string, completions []string, tail string) {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	start := wordStart(runes, pos)
+	head = string(runes[:start])
+	tail = string(runes[pos:])
+	word := string(runes[start:pos])
+
+	stmt := head
+	if this.pending != nil {
+		stmt = this.pending() + " " + head
+	}
+
+	if strings.HasPrefix(word, "\\") {
+		if strings.TrimSpace(stmt) == "" {
+			completions = matchWords(word, commandNames())
+		}
+		return
+	}
+
+	this.checkConnection()
+
+	prev := ""
+	if f := strings.Fields(stmt); len(f) > 0 {
+		prev = strings.ToUpper(f[len(f)-1])
+	}
+	if _KEYSPACE_CONTEXT[prev] || strings.Contains(word, ":") {
+		completions = matchNames(word, this.keyspacePaths(strings.Contains(word, ":")))
+		return
+	}
+	if word == "" {
+		return
+	}
+
+	refs := keyspaceRefs(stmt + " " + word + tail)
+	if dot := strings.Index(word, "."); dot > 0 {
+		qualifier := word[:dot]
+		for _, ref := range refs {
+			if qualifier != ref.alias && qualifier != ref.path {
+				continue
+			}
+			for _, f := range this.keyspaceFields(ref.path) {
+				completions = append(completions, qualifier+"."+f)
+			}
+		}
+		if len(completions) > 0 {
+			completions = matchNames(word, completions)
+		} else {
+			completions = matchNames(word, this.keyspacePaths(false))
+		}
+		return
+	}
+
+	completions = matchWords(word, parser.Keywords())
+	for _, f := range matchWords(word, expression.FunctionNames()) {
+		completions = append(completions, f+"(")
+	}
+	var fields []string
+	for _, ref := range refs {
+		for _, f := range this.keyspaceFields(ref.path) {
+			if !isNested(f) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	completions = append(completions, matchNames(word, fields)...)
+	return
+}
+
+// Start of the word ending at pos. Quoted identifiers may contain any character.
+func wordStart(line []rune, pos int) int {
+	quoted := false
+	for _, r := range line[:pos] {
+		if r == '`' {
+			quoted = !quoted
+		}
+	}
+	i := pos
+	for i > 0 {
+		r := line[i-1]
+		if r == '`' {
+			quoted = !quoted
+		} else if !quoted && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_$.:\\", r) {
+			break
+		}
+		i--
+	}
+	return i
+}
+
+// Whether a field path has more than one element.
+func isNested(field string) bool {
+	quoted := false
+	for _, r := range field {
+		if r == '`' {
+			quoted = !quoted
+		} else if r == '.' && !quoted {
+			return true
+		}
+	}
+	return false
+}
+
+func commandNames() []string {
+	rv := make([]string, 0, len(command.COMMAND_LIST))
+	for name, _ := range command.COMMAND_LIST {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// Case insensitive match, returned in upper case if the word is in upper case.
+func matchWords(word string, list []string) []string {
+	lower := strings.ToLower(word)
+	upper := word != lower
+	var rv []string
+	for _, l := range list {
+		if strings.HasPrefix(strings.ToLower(l), lower) {
+			if upper {
+				rv = append(rv, strings.ToUpper(l))
+			} else {
+				rv = append(rv, strings.ToLower(l))
+			}
+		}
+	}
+	return rv
+}
+
+// Case sensitive match on identifiers, ignoring back quotes.
+func matchNames(word string, list []string) []string {
+	word = strings.Replace(word, "`", "", -1)
+	var rv []string
+	seen := make(map[string]bool, len(list))
+	for _, l := range list {
+		if !seen[l] && strings.HasPrefix(strings.Replace(l, "`", "", -1), word) {
+			seen[l] = true
+			rv = append(rv, l)
+		}
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func quoteIdent(name string) string {
+	if _PLAIN_IDENT.MatchString(name) {
+		return name
+	}
+	return "`" + name + "`"
+}
+
+// Keyspace paths, optionally prefixed with the namespace.
+func (this *completer) keyspacePaths(namespace bool) []string {
+	if !this.fetched {
+		this.fetched = true
+		rows, _ := this.query("SELECT RAW [namespace, `bucket`, `scope`, name] FROM system:keyspaces")
+		for _, row := range rows {
+			var elems []interface{}
+			if json.Unmarshal(row, &elems) != nil || len(elems) != 4 {
+				continue
+			}
+			var path []string
+			for _, e := range elems {
+				if s, ok := e.(string); ok {
+					path = append(path, quoteIdent(s))
+				}
+			}
+			if len(path) == 4 || len(path) == 2 {
+				this.keyspaces = append(this.keyspaces, path[0]+":"+strings.Join(path[1:], "."))
+			}
+		}
+	}
+
+	if namespace {
+		return this.keyspaces
+	}
+	rv := make([]string, len(this.keyspaces))
+	for i, ks := range this.keyspaces {
+		rv[i] = ks[strings.Index(ks, ":")+1:]
+	}
+	return rv
+}
+
+type keyspaceRef struct {
+	path  string
+	alias string
+}
+
+func keyspaceRefs(stmt string) []keyspaceRef {
+	var rv []keyspaceRef
+	keywords := parser.Keywords()
+	for _, m := range _KEYSPACE_REF.FindAllStringSubmatch(stmt, -1) {
+		ref := keyspaceRef{path: m[1], alias: m[2]}
+		if i := sort.SearchStrings(keywords, strings.ToUpper(ref.alias)); i < len(keywords) && keywords[i] == strings.ToUpper(ref.alias) {
+			ref.alias = ""
+		}
+		if ref.alias == "" {
+			// unaliased keyspaces are referred to by their last path element
+			elems := strings.Split(ref.path, ".")
+			ref.alias = elems[len(elems)-1]
+			if i := strings.Index(ref.alias, ":"); i >= 0 {
+				ref.alias = ref.alias[i+1:]
+			}
+		}
+		rv = append(rv, ref)
+	}
+	return rv
+}
+
+// Field paths of a keyspace, from INFER.
+func (this *completer) keyspaceFields(path string) []string {
+	if fields, ok := this.fields[path]; ok {
+		return fields
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	rows, _ := this.query("INFER " + path + " WITH " + _INFER_OPTIONS)
+	for _, row := range rows {
+		var flavors []struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if json.Unmarshal(row, &flavors) != nil {
+			continue
+		}
+		for _, flavor := range flavors {
+			addFields("", flavor.Properties, 1, seen, &fields)
+		}
+	}
+	sort.Strings(fields)
+	this.fields[path] = fields
+	return fields
+}
+
+func addFields(prefix string, props map[string]json.RawMessage, depth int, seen map[string]bool, fields *[]string) {
+	for name, prop := range props {
+		field := prefix + quoteIdent(name)
+		if !seen[field] {
+			seen[field] = true
+			*fields = append(*fields, field)
+		}
+		if depth < _MAX_FIELD_DEPTH {
+			var nested struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			if json.Unmarshal(prop, &nested) == nil && len(nested.Properties) > 0 {
+				addFields(field+".", nested.Properties, depth+1, seen, fields)
+			}
+		}
+	}
+}
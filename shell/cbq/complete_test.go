@@ -0,0 +1,92 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testCompleter(queries *int) *completer {
+	c := newCompleter(nil)
+	c.query = func(stmt string) ([]json.RawMessage, error) {
+		*queries++
+		if strings.HasPrefix(stmt, "SELECT RAW") {
+			return []json.RawMessage{
+				json.RawMessage(`["default", null, null, "beer"]`),
+				json.RawMessage(`["default", "travel-sample", "inventory", "airline"]`),
+			}, nil
+		}
+		return []json.RawMessage{json.RawMessage(`[
+			{"properties": {"name": {"type": "string"}, "address": {"properties": {"city": {"type": "string"}}}}},
+			{"properties": {"name": {"type": "string"}, "nickname": {"type": "string"}}}
+		]`)}, nil
+	}
+	return c
+}
+
+func completeTest(t *testing.T, c *completer, line string, head string, expected []string) {
+	h, completions, _ := c.complete(line, len([]rune(line)))
+	if h != head {
+		t.Errorf("%v: expected head %q, got %q", line, head, h)
+	}
+	if !reflect.DeepEqual(completions, expected) {
+		t.Errorf("%v: expected %v, got %v", line, expected, completions)
+	}
+}
+
+func TestCompleteCommands(t *testing.T) {
+	var queries int
+	c := testCompleter(&queries)
+	completeTest(t, c, "\\disc", "", []string{"\\disconnect"})
+	completeTest(t, c, "\\SOU", "", []string{"\\SOURCE"})
+	completeTest(t, c, "select \\sou", "select ", nil)
+	if queries != 0 {
+		t.Errorf("Expected no queries, got %v", queries)
+	}
+}
+
+func TestCompleteKeywords(t *testing.T) {
+	var queries int
+	c := testCompleter(&queries)
+	completeTest(t, c, "SELE", "", []string{"SELECT"})
+	completeTest(t, c, "select array_le", "select ", []string{"array_length("})
+}
+
+func TestCompleteKeyspaces(t *testing.T) {
+	var queries int
+	c := testCompleter(&queries)
+	completeTest(t, c, "SELECT * FROM ", "SELECT * FROM ", []string{"`travel-sample`.inventory.airline", "beer"})
+	completeTest(t, c, "SELECT * FROM trav", "SELECT * FROM ", []string{"`travel-sample`.inventory.airline"})
+	completeTest(t, c, "SELECT * FROM default:b", "SELECT * FROM ", []string{"default:beer"})
+	if queries != 1 {
+		t.Errorf("Expected keyspaces to be cached, got %v queries", queries)
+	}
+}
+
+func TestCompleteFields(t *testing.T) {
+	var queries int
+	c := testCompleter(&queries)
+	// the keyspace may follow the cursor
+	h, completions, tail := c.complete("SELECT b.addr FROM beer b", len("SELECT b.addr"))
+	if h != "SELECT " || tail != " FROM beer b" ||
+		!reflect.DeepEqual(completions, []string{"b.address", "b.address.city"}) {
+		t.Errorf("Unexpected completion %q %v %q", h, completions, tail)
+	}
+
+	h, completions, _ = c.complete("SELECT nick FROM beer", len("SELECT nick"))
+	if !reflect.DeepEqual(completions, []string{"nickname"}) {
+		t.Errorf("Unexpected completion %q %v", h, completions)
+	}
+	if queries != 1 {
+		t.Errorf("Expected fields to be cached, got %v queries", queries)
+	}
+}
@@ -0,0 +1,372 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/couchbase/query/shell/cbq/command"
+	"github.com/mattn/go-runewidth"
+)
+
+/*
+   Client side rendering of query results for the jsonl, table, csv
+   and tsv output formats. The response from the query service is
+   decoded as a stream; each element of the results array is handed
+   to a resultWriter, and the remaining response fields are used for
+   the status summary once the results have been written.
+*/
+
+// Maximum display width of a single value in table output.
+const _MAX_CELL_WIDTH = 40
+const _ELLIPSIS = "..."
+
+// Column name used for results that are not objects, e.g. SELECT RAW.
+const _VALUE_COLUMN = "$1"
+
+const _TABLE_NULL = "NULL"
+
+var _CELL_ESCAPER = strings.NewReplacer("\n", "\\n", "\r", "\\r", "\t", "\\t")
+
+type resultWriter interface {
+	addRow(row json.RawMessage) error
+	done() error
+}
+
+func formattedOutput(w io.Writer, rows io.Reader, format string) error {
+	var rw resultWriter
+
+	switch format {
+	case command.FORMAT_JSONL:
+		rw = &jsonlWriter{w: w}
+	case command.FORMAT_TABLE:
+		rw = &tableWriter{w: w}
+	case command.FORMAT_CSV:
+		rw = &csvWriter{w: w, comma: ','}
+	case command.FORMAT_TSV:
+		rw = &csvWriter{w: w, comma: '\t'}
+	default:
+		_, err := io.Copy(w, rows)
+		return err
+	}
+
+	dec := json.NewDecoder(rows)
+	meta := make(map[string]interface{})
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		if key != "results" {
+			var v interface{}
+			if err = dec.Decode(&v); err != nil {
+				return err
+			}
+			meta[key] = v
+			continue
+		}
+
+		if err = expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var row json.RawMessage
+			if err = dec.Decode(&row); err != nil {
+				return err
+			}
+			if err = rw.addRow(row); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	if err := rw.done(); err != nil {
+		return err
+	}
+
+	status := statusSummary(meta)
+	if tw, ok := rw.(*tableWriter); ok && status["status"] == "success" {
+		if err := tw.footer(status); err != nil {
+			return err
+		}
+	}
+	_, errs := status["errors"]
+	_, warnings := status["warnings"]
+	if errs || warnings || status["status"] != "success" {
+		return writeStatus(w, status)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("Unexpected token %v in query response, expected %v", tok, delim)
+	}
+	return nil
+}
+
+func writeStatus(w io.Writer, status map[string]interface{}) error {
+	var b []byte
+	var err error
+	if isPretty() {
+		b, err = json.MarshalIndent(status, "", _INDENT)
+	} else {
+		b, err = json.Marshal(status)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, _NL...))
+	return err
+}
+
+// jsonl : one compact JSON document per result.
+type jsonlWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (this *jsonlWriter) addRow(row json.RawMessage) error {
+	this.buf.Reset()
+	if err := json.Compact(&this.buf, row); err != nil {
+		return err
+	}
+	this.buf.Write(_NL)
+	_, err := this.w.Write(this.buf.Bytes())
+	return err
+}
+
+func (this *jsonlWriter) done() error {
+	return nil
+}
+
+// Results are flattened on their top-level fields; nested values are
+// written as compact JSON. The columns are the union of the fields of
+// all the results, in the order they are first seen, so all the rows
+// need to be read before anything is written.
+type flattened struct {
+	columns []string
+	index   map[string]int
+	rows    [][]json.RawMessage
+}
+
+func (this *flattened) addRow(row json.RawMessage) error {
+	if this.index == nil {
+		this.index = make(map[string]int)
+	}
+
+	var cells []json.RawMessage
+	set := func(name string, val json.RawMessage) {
+		pos, ok := this.index[name]
+		if !ok {
+			pos = len(this.columns)
+			this.index[name] = pos
+			this.columns = append(this.columns, name)
+		}
+		for len(cells) <= pos {
+			cells = append(cells, nil)
+		}
+		cells[pos] = val
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(row))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		set(_VALUE_COLUMN, row)
+		this.rows = append(this.rows, cells)
+		return nil
+	}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		var val json.RawMessage
+		if err = dec.Decode(&val); err != nil {
+			return err
+		}
+		set(tok.(string), val)
+	}
+	this.rows = append(this.rows, cells)
+	return nil
+}
+
+// Text of a value for display. Missing values are returned as "".
+func cellText(val json.RawMessage, null string) string {
+	if len(val) == 0 {
+		return ""
+	}
+	switch val[0] {
+	case '"':
+		var s string
+		if json.Unmarshal(val, &s) == nil {
+			return s
+		}
+	case 'n':
+		return null
+	case '{', '[':
+		var buf bytes.Buffer
+		if json.Compact(&buf, val) == nil {
+			return buf.String()
+		}
+	}
+	return string(val)
+}
+
+func isNumber(val json.RawMessage) bool {
+	return len(val) > 0 && (val[0] == '-' || (val[0] >= '0' && val[0] <= '9'))
+}
+
+// csv, tsv : header row followed by one record per result.
+type csvWriter struct {
+	flattened
+	w     io.Writer
+	comma rune
+}
+
+func (this *csvWriter) done() error {
+	if len(this.columns) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(this.w)
+	cw.Comma = this.comma
+	cw.Write(this.columns)
+	record := make([]string, len(this.columns))
+	for _, row := range this.rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = cellText(row[i], "")
+			}
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// table : column aligned, long values truncated.
+type tableWriter struct {
+	flattened
+	w io.Writer
+}
+
+func (this *tableWriter) done() error {
+	if len(this.columns) == 0 {
+		return nil
+	}
+
+	cells := make([][]string, len(this.rows))
+	widths := make([]int, len(this.columns))
+	for i, name := range this.columns {
+		widths[i] = runewidth.StringWidth(truncateCell(name))
+	}
+	for r, row := range this.rows {
+		cells[r] = make([]string, len(this.columns))
+		for i := range this.columns {
+			if i < len(row) {
+				cells[r][i] = truncateCell(cellText(row[i], _TABLE_NULL))
+			}
+			if cw := runewidth.StringWidth(cells[r][i]); cw > widths[i] {
+				widths[i] = cw
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writeLine := func(vals []string, right func(int) bool) {
+		var line strings.Builder
+		for i, v := range vals {
+			if i > 0 {
+				line.WriteString(" |")
+			}
+			line.WriteByte(' ')
+			pad := strings.Repeat(" ", widths[i]-runewidth.StringWidth(v))
+			if right(i) {
+				line.WriteString(pad + v)
+			} else {
+				line.WriteString(v + pad)
+			}
+		}
+		buf.WriteString(strings.TrimRight(line.String(), " "))
+		buf.Write(_NL)
+	}
+
+	header := make([]string, len(this.columns))
+	for i, name := range this.columns {
+		header[i] = truncateCell(name)
+	}
+	writeLine(header, func(int) bool { return false })
+	for i, width := range widths {
+		if i > 0 {
+			buf.WriteByte('+')
+		}
+		buf.WriteString(strings.Repeat("-", width+2))
+	}
+	buf.Write(_NL)
+	for r, row := range this.rows {
+		writeLine(cells[r], func(i int) bool {
+			return i < len(row) && isNumber(row[i])
+		})
+	}
+
+	_, err := this.w.Write(buf.Bytes())
+	return err
+}
+
+func (this *tableWriter) footer(status map[string]interface{}) error {
+	var msg string
+	if cnt, ok := status["mutationCount"]; ok {
+		msg = fmt.Sprintf("(%v mutations)\n", cnt)
+	} else if len(this.rows) == 1 {
+		msg = "(1 row)\n"
+	} else {
+		msg = fmt.Sprintf("(%d rows)\n", len(this.rows))
+	}
+	_, err := io.WriteString(this.w, msg)
+	return err
+}
+
+// Escape control characters and truncate to _MAX_CELL_WIDTH columns.
+func truncateCell(s string) string {
+	s = _CELL_ESCAPER.Replace(s)
+	if runewidth.StringWidth(s) <= _MAX_CELL_WIDTH {
+		return s
+	}
+	limit := _MAX_CELL_WIDTH - len(_ELLIPSIS)
+	width := 0
+	for i, r := range s {
+		rw := runewidth.RuneWidth(r)
+		if width+rw > limit {
+			return s[:i] + _ELLIPSIS
+		}
+		width += rw
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/query/shell/cbq/command"
+)
+
+const _TEST_RESPONSE = `{
+"requestID": "d2f1d5a3",
+"signature": {"*":"*"},
+"results": [
+{"name": "alice", "age": 30, "tags": ["a", "b"]},
+{"name": "bob, jr.", "city": null},
+{"name": "carol\tc", "age": 7.5}
+],
+"status": "success",
+"metrics": {"elapsedTime": "1ms", "resultCount": 3}
+}`
+
+func formatTest(t *testing.T, format string, response string, expected string) {
+	var b bytes.Buffer
+	err := formattedOutput(&b, strings.NewReader(response), format)
+	if err != nil {
+		t.Fatalf("%v: unexpected error %v", format, err)
+	}
+	if b.String() != expected {
+		t.Errorf("%v: expected\n%s\ngot\n%s", format, expected, b.String())
+	}
+}
+
+func TestFormatJSONL(t *testing.T) {
+	formatTest(t, command.FORMAT_JSONL, _TEST_RESPONSE,
+		`{"name":"alice","age":30,"tags":["a","b"]}`+"\n"+
+			`{"name":"bob, jr.","city":null}`+"\n"+
+			`{"name":"carol\tc","age":7.5}`+"\n")
+}
+
+func TestFormatCSV(t *testing.T) {
+	formatTest(t, command.FORMAT_CSV, _TEST_RESPONSE,
+		"name,age,tags,city\n"+
+			"alice,30,\"[\"\"a\"\",\"\"b\"\"]\",\n"+
+			"\"bob, jr.\",,,\n"+
+			"carol\tc,7.5,,\n")
+}
+
+func TestFormatTSV(t *testing.T) {
+	formatTest(t, command.FORMAT_TSV, _TEST_RESPONSE,
+		"name\tage\ttags\tcity\n"+
+			"alice\t30\t\"[\"\"a\"\",\"\"b\"\"]\"\t\n"+
+			"bob, jr.\t\t\t\n"+
+			"\"carol\tc\"\t7.5\t\t\n")
+}
+
+func TestFormatTable(t *testing.T) {
+	formatTest(t, command.FORMAT_TABLE, _TEST_RESPONSE,
+		" name     | age | tags      | city\n"+
+			"----------+-----+-----------+------\n"+
+			" alice    |  30 | [\"a\",\"b\"] |\n"+
+			" bob, jr. |     |           | NULL\n"+
+			" carol\\tc | 7.5 |           |\n"+
+			"(3 rows)\n")
+
+	long := strings.Repeat("x", 50)
+	formatTest(t, command.FORMAT_TABLE,
+		`{"results": ["`+long+`"], "status": "success", "metrics": {"resultCount": 1}}`,
+		" $1\n"+
+			"------------------------------------------\n"+
+			" "+long[:37]+"...\n"+
+			"(1 row)\n")
+}
+
+func TestFormatErrors(t *testing.T) {
+	*prettyFlag = false
+	defer func() { *prettyFlag = true }()
+
+	formatTest(t, command.FORMAT_TABLE,
+		`{"errors": [{"code": 3000, "msg": "syntax error"}], "status": "fatal"}`,
+		`{"errors":[{"code":3000,"msg":"syntax error"}],"status":"fatal"}`+"\n")
+	formatTest(t, command.FORMAT_CSV,
+		`{"results": [], "status": "success", "metrics": {"mutationCount": 2}}`,
+		"")
+}
@@ -193,6 +193,11 @@ func HandleInteractiveMode(prompt string) {
 	inputLine := []string{}
 	fullPrompt := prompt + QRY_PROMPT1
 
+	// complete words in the context of the statement entered so far
+	liner.SetWordCompleter(newCompleter(func() string {
+		return strings.Join(inputLine, " ")
+	}).complete)
+
 	handleScriptFlag(&liner)
 	handleIPModeFlag(&liner)
 
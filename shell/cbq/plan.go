@@ -0,0 +1,328 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/couchbase/godbc/n1ql"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/shell/cbq/command"
+	"github.com/couchbase/query/shell/liner"
+	"github.com/couchbase/query/value"
+)
+
+/*
+   \EXPLAIN and \PROFILE render the operator tree of a statement
+   instead of the plan JSON. \PROFILE runs the statement with
+   profile=timings and annotates each operator with the documents
+   in and out and the time spent in it, highlighting the operators
+   that took the longest.
+*/
+
+// Set while a \EXPLAIN or \PROFILE statement is being run.
+var planMode string
+
+// Number of operators highlighted in profile output.
+const _HIGHLIGHT_COUNT = 3
+
+// Longest expression shown for an operator, e.g. a filter condition.
+const _MAX_DETAIL_WIDTH = 60
+
+func execPlanStmt(liner *liner.State) (errors.ErrorCode, string) {
+	stmt := command.PLAN_STMT
+	planMode = command.PLAN_MODE
+	command.PLAN_STMT = ""
+	command.PLAN_MODE = ""
+	defer func() { planMode = "" }()
+
+	if planMode == command.PROFILE_CMD {
+		n1ql.SetQueryParams("profile", "timings")
+		defer restoreQueryParam("profile")
+	} else {
+		stmt = "EXPLAIN " + stmt
+	}
+	return command_query(stmt, command.W, liner)
+}
+
+// Reset a query parameter to its value in the session.
+func restoreQueryParam(name string) {
+	st, ok := command.QueryParam[name]
+	if !ok {
+		n1ql.UnsetQueryParams(name)
+		return
+	}
+	v, err_code, _ := st.Top()
+	if err_code != 0 {
+		n1ql.UnsetQueryParams(name)
+	} else if v.Type() == value.STRING {
+		n1ql.SetQueryParams(name, v.Actual().(string))
+	} else {
+		n1ql.SetQueryParams(name, command.ValToStr(v))
+	}
+}
+
+func planOutput(w io.Writer, rows io.Reader, mode string) error {
+	var resp map[string]interface{}
+	dec := json.NewDecoder(rows)
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return err
+	}
+
+	var err error
+	if mode == command.PROFILE_CMD {
+		err = writeProfile(w, resp)
+	} else {
+		err = writeExplain(w, resp)
+	}
+	if err != nil {
+		return err
+	}
+
+	status := statusSummary(resp)
+	_, errs := status["errors"]
+	_, warnings := status["warnings"]
+	if errs || warnings || status["status"] != "success" {
+		return writeStatus(w, status)
+	}
+	return nil
+}
+
+func writeExplain(w io.Writer, resp map[string]interface{}) error {
+	results, _ := resp["results"].([]interface{})
+	for _, r := range results {
+		res, _ := r.(map[string]interface{})
+		op, ok := res["plan"].(map[string]interface{})
+		if !ok {
+			b, err := json.MarshalIndent(r, "", _INDENT)
+			if err != nil {
+				return err
+			}
+			if _, err = w.Write(append(b, _NL...)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writePlanTree(w, newPlanNode(op), nil, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeProfile(w io.Writer, resp map[string]interface{}) error {
+	var summary []string
+	if metrics, ok := resp["metrics"].(map[string]interface{}); ok {
+		for _, m := range []string{"elapsedTime", "executionTime", "resultCount", "mutationCount"} {
+			if v, ok := metrics[m]; ok {
+				summary = append(summary, fmt.Sprintf("%s: %v", m, v))
+			}
+		}
+	}
+	if len(summary) > 0 {
+		if _, err := io.WriteString(w, strings.Join(summary, ", ")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	profile, _ := resp["profile"].(map[string]interface{})
+	if phases, ok := profile["phaseTimes"].(map[string]interface{}); ok && len(phases) > 0 {
+		type phase struct {
+			name string
+			time time.Duration
+		}
+		list := make([]phase, 0, len(phases))
+		for name, t := range phases {
+			d, _ := time.ParseDuration(fmt.Sprint(t))
+			list = append(list, phase{name, d})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].time > list[j].time || (list[i].time == list[j].time && list[i].name < list[j].name)
+		})
+		times := make([]string, len(list))
+		for i, p := range list {
+			times[i] = p.name + " " + p.time.String()
+		}
+		if _, err := io.WriteString(w, "phaseTimes: "+strings.Join(times, ", ")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	op, ok := profile["executionTimings"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if _, err := w.Write(_NL); err != nil {
+		return err
+	}
+
+	root := newPlanNode(op)
+	var timed []*planNode
+	var total time.Duration
+	root.walk(func(n *planNode) {
+		if n.time > 0 {
+			timed = append(timed, n)
+			total += n.time
+		}
+	})
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].time > timed[j].time })
+	highlight := make(map[*planNode]bool, _HIGHLIGHT_COUNT)
+	for i := 0; i < len(timed) && i < _HIGHLIGHT_COUNT; i++ {
+		highlight[timed[i]] = true
+	}
+	return writePlanTree(w, root, highlight, total)
+}
+
+type planNode struct {
+	operator string
+	details  []string
+	stats    []string
+	time     time.Duration
+	children []*planNode
+}
+
+func newPlanNode(op map[string]interface{}) *planNode {
+	node := &planNode{operator: fmt.Sprint(op["#operator"])}
+
+	if ks := keyspacePath(op); ks != "" {
+		node.details = append(node.details, ks)
+	}
+	for _, k := range []string{"index", "as", "alias", "condition", "on_clause", "filter"} {
+		if v, ok := op[k].(string); ok && v != "" {
+			node.details = append(node.details, k+": "+truncateDetail(v))
+		}
+	}
+	if est, ok := op["optimizer_estimates"].(map[string]interface{}); ok {
+		for _, k := range []string{"cost", "cardinality"} {
+			if v, ok := est[k]; ok {
+				node.details = append(node.details, k+": "+fmt.Sprint(v))
+			}
+		}
+	}
+
+	if stats, ok := op["#stats"].(map[string]interface{}); ok {
+		for _, k := range []string{"#itemsIn", "#itemsOut"} {
+			if v, ok := stats[k]; ok {
+				node.stats = append(node.stats, strings.TrimPrefix(k, "#")+": "+fmt.Sprint(v))
+			}
+		}
+		// time spent waiting on other operators (kernTime) is not included
+		for _, k := range []string{"execTime", "servTime"} {
+			if d, err := time.ParseDuration(fmt.Sprint(stats[k])); err == nil {
+				node.time += d
+			}
+		}
+		if node.time > 0 {
+			node.stats = append(node.stats, "time: "+node.time.String())
+		}
+	}
+
+	// children are any operators nested in the operator's fields
+	keys := make([]string, 0, len(op))
+	for k, _ := range op {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := op[k].(type) {
+		case map[string]interface{}:
+			if _, ok := v["#operator"]; ok {
+				node.children = append(node.children, newPlanNode(v))
+			}
+		case []interface{}:
+			for _, e := range v {
+				if c, ok := e.(map[string]interface{}); ok {
+					if _, ok := c["#operator"]; ok {
+						node.children = append(node.children, newPlanNode(c))
+					}
+				}
+			}
+		}
+	}
+	return node
+}
+
+func keyspacePath(op map[string]interface{}) string {
+	ks, _ := op["keyspace"].(string)
+	if ks == "" {
+		return ""
+	}
+	if bucket, _ := op["bucket"].(string); bucket != "" {
+		scope, _ := op["scope"].(string)
+		return bucket + "." + scope + "." + ks
+	}
+	return ks
+}
+
+func truncateDetail(s string) string {
+	r := []rune(s)
+	if len(r) <= _MAX_DETAIL_WIDTH {
+		return s
+	}
+	return string(r[:_MAX_DETAIL_WIDTH-len(_ELLIPSIS)]) + _ELLIPSIS
+}
+
+func (this *planNode) walk(f func(*planNode)) {
+	f(this)
+	for _, c := range this.children {
+		c.walk(f)
+	}
+}
+
+func writePlanTree(w io.Writer, root *planNode, highlight map[*planNode]bool, total time.Duration) error {
+	var b strings.Builder
+	var write func(n *planNode, indent string, branch string)
+	write = func(n *planNode, indent string, branch string) {
+		b.WriteString(indent + branch)
+
+		hl := highlight[n]
+		if hl {
+			b.WriteString(command.GetfgRed())
+		}
+		b.WriteString(n.operator)
+		if len(n.details) > 0 {
+			b.WriteString(" [" + strings.Join(n.details, ", ") + "]")
+		}
+		if len(n.stats) > 0 {
+			stats := n.stats
+			if total > 0 && n.time > 0 {
+				stats = append(stats[:len(stats):len(stats)],
+					fmt.Sprintf("%.1f%%", float64(n.time)*100/float64(total)))
+			}
+			b.WriteString(" (" + strings.Join(stats, ", ") + ")")
+		}
+		if hl {
+			b.WriteString(" *" + command.Getreset())
+		}
+		b.WriteString("\n")
+
+		switch branch {
+		case "+- ":
+			indent += "|  "
+		case "`- ":
+			indent += "   "
+		}
+		for i, c := range n.children {
+			if i == len(n.children)-1 {
+				write(c, indent, "`- ")
+			} else {
+				write(c, indent, "+- ")
+			}
+		}
+	}
+	write(root, "", "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,80 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/query/shell/cbq/command"
+)
+
+func planTest(t *testing.T, mode string, response string, expected string) {
+	reset, fgRed := command.Getreset(), command.GetfgRed()
+	command.SetDispVal("", "")
+	defer command.SetDispVal(reset, fgRed)
+
+	var b bytes.Buffer
+	err := planOutput(&b, strings.NewReader(response), mode)
+	if err != nil {
+		t.Fatalf("%v: unexpected error %v", mode, err)
+	}
+	if b.String() != expected {
+		t.Errorf("%v: expected\n%s\ngot\n%s", mode, expected, b.String())
+	}
+}
+
+func TestExplainTree(t *testing.T) {
+	planTest(t, command.EXPLAIN_CMD, `{
+"results": [{"plan": {"#operator": "Sequence", "~children": [
+	{"#operator": "PrimaryScan3", "index": "#primary", "keyspace": "beer", "namespace": "default"},
+	{"#operator": "Parallel", "~child": {"#operator": "Sequence", "~children": [
+		{"#operator": "Fetch", "keyspace": "beer", "namespace": "default"},
+		{"#operator": "Filter", "condition": "(5 < (beer.abv))", "optimizer_estimates": {"cost": 12.5, "cardinality": 3}},
+		{"#operator": "InitialProject", "result_terms": [{"expr": "self", "star": true}]}]}}]},
+	"text": "SELECT * FROM beer WHERE abv > 5"}],
+"status": "success"
+}`, "Sequence\n"+
+		"+- PrimaryScan3 [beer, index: #primary]\n"+
+		"`- Parallel\n"+
+		"   `- Sequence\n"+
+		"      +- Fetch [beer]\n"+
+		"      +- Filter [condition: (5 < (beer.abv)), cost: 12.5, cardinality: 3]\n"+
+		"      `- InitialProject\n")
+}
+
+func TestProfileTree(t *testing.T) {
+	planTest(t, command.PROFILE_CMD, `{
+"results": [{"a": 1}],
+"status": "success",
+"metrics": {"elapsedTime": "12ms", "executionTime": "11ms", "resultCount": 1},
+"profile": {
+	"phaseTimes": {"fetch": "4ms", "authorize": "1ms", "primaryScan": "6ms"},
+	"executionTimings": {"#operator": "Sequence", "#stats": {"#phaseSwitches": 1, "execTime": "1ms"}, "~children": [
+		{"#operator": "PrimaryScan3", "keyspace": "beer", "#stats": {"#itemsOut": 10, "execTime": "2ms", "servTime": "4ms", "kernTime": "5ms"}},
+		{"#operator": "Fetch", "keyspace": "beer", "#stats": {"#itemsIn": 10, "#itemsOut": 10, "servTime": "2ms"}},
+		{"#operator": "Filter", "#stats": {"#itemsIn": 10, "#itemsOut": 1}}]}}
+}`, "elapsedTime: 12ms, executionTime: 11ms, resultCount: 1\n"+
+		"phaseTimes: primaryScan 6ms, fetch 4ms, authorize 1ms\n"+
+		"\n"+
+		"Sequence (time: 1ms, 11.1%) *\n"+
+		"+- PrimaryScan3 [beer] (itemsOut: 10, time: 6ms, 66.7%) *\n"+
+		"+- Fetch [beer] (itemsIn: 10, itemsOut: 10, time: 2ms, 22.2%) *\n"+
+		"`- Filter (itemsIn: 10, itemsOut: 1)\n")
+}
+
+func TestPlanErrors(t *testing.T) {
+	*prettyFlag = false
+	defer func() { *prettyFlag = true }()
+
+	planTest(t, command.EXPLAIN_CMD,
+		`{"errors": [{"code": 3000, "msg": "syntax error"}], "status": "fatal"}`,
+		`{"errors":[{"code":3000,"msg":"syntax error"}],"status":"fatal"}`+"\n")
+}
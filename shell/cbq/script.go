@@ -0,0 +1,153 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/shell/cbq/command"
+	"github.com/couchbase/query/value"
+)
+
+/*
+   Support for scripts : arguments given after -- on the command
+   line, the status and result of the previous statement for \IF,
+   and \SET -var, which sets a user defined parameter to the result
+   of a statement.
+*/
+
+// Responses larger than this are not kept for RESULT.
+const _MAX_RECORDED_RESPONSE = 1024 * 1024
+
+// Split the command line at --. What follows are the script arguments.
+func splitScriptArgs(args []string) (flags []string, script []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// Keeps a copy of a query response as it is written out.
+type responseRecorder struct {
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (this *responseRecorder) Write(b []byte) (int, error) {
+	if !this.overflow {
+		if this.buf.Len()+len(b) > _MAX_RECORDED_RESPONSE {
+			this.overflow = true
+			this.buf = bytes.Buffer{}
+		} else {
+			this.buf.Write(b)
+		}
+	}
+	return len(b), nil
+}
+
+// Set the status and result of the last statement from the response,
+// and return the number of results, or -1 if they were not kept.
+func (this *responseRecorder) record(err error) int {
+	command.LAST_STATUS = "success"
+	if err != nil {
+		command.LAST_STATUS = "errors"
+	}
+	command.LAST_RESULT = value.MISSING_VALUE
+
+	var resp struct {
+		Status  string            `json:"status"`
+		Results []json.RawMessage `json:"results"`
+	}
+	if this.overflow || json.Unmarshal(this.buf.Bytes(), &resp) != nil {
+		return -1
+	}
+	if resp.Status != "" {
+		command.LAST_STATUS = resp.Status
+	}
+	command.LAST_RESULT = resultValue(resp.Results)
+	return len(resp.Results)
+}
+
+// No results is NULL. A single result is returned as is, unless it is
+// an object with a single field, in which case the field's value is
+// returned. Several results are returned as an array.
+func resultValue(results []json.RawMessage) value.Value {
+	switch len(results) {
+	case 0:
+		return value.NULL_VALUE
+	case 1:
+		v := value.NewValue([]byte(results[0]))
+		if v.Type() == value.OBJECT {
+			if fields := v.Fields(); len(fields) == 1 {
+				for _, f := range fields {
+					return value.NewValue(f)
+				}
+			}
+		}
+		return v
+	}
+	b, err := json.Marshal(results)
+	if err != nil {
+		return value.MISSING_VALUE
+	}
+	return value.NewValue(b)
+}
+
+// The statement could not be run at all.
+func recordFailure() {
+	command.LAST_STATUS = "errors"
+	command.LAST_RESULT = value.MISSING_VALUE
+}
+
+// Run the statement for \SET -var and set the user defined parameter to its result.
+func execCapture() (errors.ErrorCode, string) {
+	name := command.CAPTURE_VAR
+	stmt := command.CAPTURE_STMT
+	command.CAPTURE_VAR = ""
+	command.CAPTURE_STMT = ""
+
+	if errCode, errStr := openConnection(); errCode != 0 {
+		return errCode, errStr
+	}
+
+	rows, err := command.DbN1ql.QueryRaw(stmt + QRY_EOL)
+	if rows == nil {
+		recordFailure()
+		if err != nil {
+			return errors.E_SHELL_DRIVER_QUERY_METHOD, err.Error()
+		}
+		return errors.E_SHELL_NOT_SCALAR, ""
+	}
+	defer rows.Close()
+
+	rec := &responseRecorder{}
+	if _, cerr := io.Copy(rec, rows); cerr != nil {
+		recordFailure()
+		return errors.E_SHELL_DRIVER_QUERY_METHOD, cerr.Error()
+	}
+	n := rec.record(err)
+	if err != nil {
+		return errors.E_SHELL_DRIVER_QUERY_METHOD, err.Error()
+	}
+	if n != 1 {
+		if n < 0 {
+			return errors.E_SHELL_NOT_SCALAR, ""
+		}
+		return errors.E_SHELL_NOT_SCALAR, fmt.Sprintf("Results : %d", n)
+	}
+
+	command.SetUserDefValue(name, command.LAST_RESULT)
+	return 0, ""
+}
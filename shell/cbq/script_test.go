@@ -0,0 +1,113 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/shell/cbq/command"
+	"github.com/couchbase/query/shell/liner"
+)
+
+func TestSplitScriptArgs(t *testing.T) {
+	flags, args := splitScriptArgs([]string{"-f", "script.n1ql", "--", "a", "--", "5"})
+	if !reflect.DeepEqual(flags, []string{"-f", "script.n1ql"}) || !reflect.DeepEqual(args, []string{"a", "--", "5"}) {
+		t.Errorf("Unexpected split %v %v", flags, args)
+	}
+
+	flags, args = splitScriptArgs([]string{"-f", "script.n1ql"})
+	if len(flags) != 2 || args != nil {
+		t.Errorf("Unexpected split %v %v", flags, args)
+	}
+}
+
+func TestRecordResponse(t *testing.T) {
+	tests := []struct {
+		response string
+		status   string
+		result   string
+		count    int
+	}{
+		{`{"results": [{"$1": 42}], "status": "success"}`, "success", "42", 1},
+		{`{"results": [{"a": 1, "b": 2}], "status": "success"}`, "success", `{"a":1,"b":2}`, 1},
+		{`{"results": [1, 2], "status": "success"}`, "success", "[1,2]", 2},
+		{`{"results": [], "status": "success"}`, "success", "null", 0},
+		{`{"errors": [{"code": 3000}], "status": "fatal"}`, "fatal", "null", 0},
+	}
+	for _, test := range tests {
+		rec := &responseRecorder{}
+		rec.Write([]byte(test.response))
+		n := rec.record(nil)
+		if n != test.count || command.LAST_STATUS != test.status || command.ValToStr(command.LAST_RESULT) != test.result {
+			t.Errorf("%v : got %v %v %v", test.response, n, command.LAST_STATUS, command.LAST_RESULT)
+		}
+	}
+}
+
+func runScript(t *testing.T, script string) (errors.ErrorCode, string) {
+	f, err := ioutil.TempFile("", "cbq_script")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(script)
+	f.Close()
+
+	liner := liner.NewLiner(false)
+	defer liner.Close()
+
+	var b bytes.Buffer
+	command.SetWriter(&b)
+	defer command.SetWriter(os.Stdout)
+	command.QUIET = true
+	defer func() { command.QUIET = false }()
+
+	command.FILE_INPUT = f.Name()
+	return readAndExec(liner)
+}
+
+func TestConditionalScript(t *testing.T) {
+	command.SetScriptArgs([]string{"prod", "5"})
+	command.LAST_STATUS = "success"
+
+	errCode, errStr := runScript(t, `\SET $env none;
+\IF $1 = prod;
+	\IF $2 > 10;
+		\SET $env big;
+	\ELSE;
+		\SET $env small;
+	\ENDIF;
+\ELSE;
+	\SET $env test;
+\ENDIF;
+\IF NOT STATUS = success;
+	\SET $env failed;
+\ENDIF;
+`)
+	if errCode != 0 {
+		t.Fatal(command.HandleError(errCode, errStr))
+	}
+	v, _, _ := command.UserDefSV["env"].Top()
+	if command.ValToStr(v) != `"small"` {
+		t.Errorf("Expected $env to be small, got %v", v)
+	}
+
+	errCode, _ = runScript(t, "\\IF $1 = prod;\n\\SET $env big;\n")
+	if errCode != errors.E_SHELL_UNTERMINATED_CONDITIONAL {
+		t.Errorf("Expected unterminated \\IF error, got %v", errCode)
+	}
+	if command.ConditionalDepth() != 0 {
+		t.Errorf("Unterminated \\IF was not discarded")
+	}
+}
@@ -230,6 +230,15 @@ var prettyFlag = flag.Bool("pretty", true, command.UPRETTY)
 
 var terseFlag = flag.Bool("terse", false, command.UTERSE)
 
+/*
+   Option        : -format
+   Args          : json | jsonl | table | csv | tsv
+   Default value : json
+   Output format for query results
+*/
+
+var formatFlag = flag.String("format", command.FORMAT_JSON, command.UFORMAT)
+
 /*
    Option        : -exit-on-error
    Default value : false
@@ -451,7 +460,10 @@ var (
 
 func main() {
 
-	flag.Parse()
+	// Arguments after -- are passed to the script as $1 ... $n
+	flags, scriptArgs := splitScriptArgs(os.Args[1:])
+	flag.CommandLine.Parse(flags)
+	command.SetScriptArgs(scriptArgs)
 
 	// Initialize Global buffer to store queries for batch mode.
 	stringBuffer.Write([]byte(""))
@@ -473,6 +485,15 @@ func main() {
 		n1ql.SetQueryParams("signature", "false")
 	}
 
+	if *formatFlag != command.FORMAT_JSON {
+		err_code, err_str = command.PushOrSet([]string{"-format", *formatFlag}, true)
+		if err_code != 0 {
+			s_err := command.HandleError(err_code, err_str)
+			command.PrintError(s_err)
+			os.Exit(1)
+		}
+	}
+
 	if outputFlag != "" {
 		// Redirect all output to the given file.
 		// This is handled in the HandleInteractiveMode() method
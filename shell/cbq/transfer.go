@@ -0,0 +1,508 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/couchbase/godbc/n1ql"
+	"github.com/couchbase/query/errors"
+	"github.com/couchbase/query/expression"
+	parser "github.com/couchbase/query/parser/n1ql"
+	"github.com/couchbase/query/shell/cbq/command"
+	"github.com/couchbase/query/value"
+)
+
+/*
+   \IMPORT reads documents from a file and upserts them into a
+   keyspace in batches, through the shell's connection. \EXPORT
+   streams the results of a statement to one or more files.
+*/
+
+// Open a connection to the query service if there is none.
+func openConnection() (errors.ErrorCode, string) {
+	if noQueryService {
+		return errors.E_SHELL_NO_CONNECTION, ""
+	}
+	if command.DbN1ql == nil {
+		var err error
+		command.DbN1ql, err = n1ql.OpenExtended(serverFlag)
+		if err != nil {
+			return errors.E_SHELL_DRIVER_OPEN, err.Error()
+		}
+	}
+	return 0, ""
+}
+
+func execImport() (errors.ErrorCode, string) {
+	opts := command.IMPORT_OPTIONS
+	command.IMPORT_OPTIONS = nil
+
+	key, err := parser.ParseExpression(opts.Key)
+	if err != nil {
+		return errors.E_SHELL_INVALID_INPUT_ARGUMENTS, " KEY " + err.Error()
+	}
+
+	f, err := os.Open(opts.File)
+	if err != nil {
+		return errors.E_SHELL_OPEN_FILE, err.Error()
+	}
+	defer f.Close()
+
+	if errCode, errStr := openConnection(); errCode != 0 {
+		return errCode, errStr
+	}
+
+	imp := newImporter(opts, key, command.W, upsertBatch)
+	if err = readDocuments(bufio.NewReader(f), opts.Format, imp.add); err != nil {
+		imp.flush()
+		imp.summary()
+		return errors.E_SHELL_READ_FILE, err.Error()
+	}
+	imp.flush()
+	imp.summary()
+	if imp.failed > 0 {
+		return errors.E_SHELL_IMPORT, fmt.Sprintf("Failed : %d", imp.failed)
+	}
+	return 0, ""
+}
+
+// Run the statement and return the number of mutations and the errors, if any.
+func upsertBatch(stmt string) (int, string) {
+	rows, err := command.DbN1ql.QueryRaw(stmt + QRY_EOL)
+	if rows == nil {
+		if err != nil {
+			return 0, err.Error()
+		}
+		return 0, ""
+	}
+	defer rows.Close()
+
+	var resp struct {
+		Errors  json.RawMessage `json:"errors"`
+		Metrics struct {
+			MutationCount int `json:"mutationCount"`
+		} `json:"metrics"`
+	}
+	if derr := json.NewDecoder(rows).Decode(&resp); derr != nil {
+		return 0, derr.Error()
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Metrics.MutationCount, string(resp.Errors)
+	}
+	if err != nil {
+		return resp.Metrics.MutationCount, err.Error()
+	}
+	return resp.Metrics.MutationCount, ""
+}
+
+type importer struct {
+	opts    *command.ImportOptions
+	key     expression.Expression
+	context expression.Context
+	w       io.Writer
+	upsert  func(stmt string) (int, string)
+
+	// VALUES of the current batch, and the numbers of its first and last documents
+	batch []string
+	first int
+	last  int
+
+	read     int
+	imported int
+	failed   int
+}
+
+func newImporter(opts *command.ImportOptions, key expression.Expression, w io.Writer,
+	upsert func(stmt string) (int, string)) *importer {
+	return &importer{
+		opts:    opts,
+		key:     key,
+		context: expression.NewIndexContext(),
+		w:       w,
+		upsert:  upsert,
+		batch:   make([]string, 0, opts.Batch),
+	}
+}
+
+func (this *importer) add(doc json.RawMessage) error {
+	this.read++
+	if len(this.batch) == 0 {
+		this.first = this.read
+	}
+
+	key, msg := this.docKey(doc)
+	if msg != "" {
+		this.failed++
+		_, err := fmt.Fprintf(this.w, command.IMPORTKEYMSG, this.read, msg)
+		return err
+	}
+	k, _ := json.Marshal(key)
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, doc); err != nil {
+		return err
+	}
+	this.batch = append(this.batch, "("+string(k)+", "+buf.String()+")")
+	this.last = this.read
+	if len(this.batch) >= this.opts.Batch {
+		this.flush()
+	}
+	return nil
+}
+
+func (this *importer) docKey(doc json.RawMessage) (string, string) {
+	v, err := this.key.Evaluate(value.NewValue([]byte(doc)), this.context)
+	if err != nil {
+		return "", err.Error()
+	}
+	switch v.Type() {
+	case value.STRING:
+		return v.Actual().(string), ""
+	case value.NUMBER:
+		return v.String(), ""
+	case value.MISSING:
+		return "", "KEY " + this.opts.Key + " is missing"
+	}
+	return "", "KEY " + this.opts.Key + " is not a string : " + v.String()
+}
+
+func (this *importer) flush() {
+	if len(this.batch) == 0 {
+		return
+	}
+	stmt := "UPSERT INTO " + this.opts.Keyspace + " VALUES " + strings.Join(this.batch, ", ")
+	count, msg := this.upsert(stmt)
+	this.imported += count
+	if msg != "" || count != len(this.batch) {
+		this.failed += len(this.batch) - count
+		fmt.Fprintf(this.w, command.IMPORTERRMSG, this.first, this.last, msg)
+	}
+	this.batch = this.batch[:0]
+}
+
+func (this *importer) summary() {
+	fmt.Fprintf(this.w, command.IMPORTMSG, this.imported, this.read, this.opts.Keyspace)
+}
+
+// Read the documents in a JSON array, JSON lines or CSV file. If the
+// format is not given, JSON is assumed, as an array if the file starts
+// with [.
+func readDocuments(r *bufio.Reader, format string, add func(doc json.RawMessage) error) error {
+	if format == command.FORMAT_CSV {
+		return readCSV(r, add)
+	}
+
+	dec := json.NewDecoder(r)
+	if format == "" {
+		for {
+			b, err := r.Peek(1)
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if b[0] != ' ' && b[0] != '\t' && b[0] != '\r' && b[0] != '\n' {
+				if b[0] == '[' {
+					format = command.FORMAT_JSON
+				}
+				break
+			}
+			r.ReadByte()
+		}
+	}
+
+	if format == command.FORMAT_JSON {
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+	}
+	for dec.More() {
+		var doc json.RawMessage
+		if err := dec.Decode(&doc); err != nil {
+			return err
+		}
+		if err := add(doc); err != nil {
+			return err
+		}
+	}
+	if format == command.FORMAT_JSON {
+		return expectDelim(dec, ']')
+	}
+	return nil
+}
+
+// Each record is imported as an object with the fields named in the
+// header. Numbers and booleans are imported as such, everything else
+// as strings.
+func readCSV(r io.Reader, add func(doc json.RawMessage) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	names := make([][]byte, len(header))
+	for i, h := range header {
+		names[i], _ = json.Marshal(h)
+	}
+
+	var buf bytes.Buffer
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		buf.Reset()
+		buf.WriteByte('{')
+		for i, field := range record {
+			if i >= len(names) {
+				break
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(names[i])
+			buf.WriteByte(':')
+			buf.Write(csvValue(field))
+		}
+		buf.WriteByte('}')
+		if err = add(json.RawMessage(buf.Bytes())); err != nil {
+			return err
+		}
+	}
+}
+
+func csvValue(field string) []byte {
+	b := []byte(field)
+	if field == "true" || field == "false" {
+		return b
+	}
+	if len(b) > 0 && (b[0] == '-' || (b[0] >= '0' && b[0] <= '9')) && json.Valid(b) {
+		return b
+	}
+	b, _ = json.Marshal(field)
+	return b
+}
+
+func execExport() (errors.ErrorCode, string) {
+	opts := command.EXPORT_OPTIONS
+	command.EXPORT_OPTIONS = nil
+
+	if errCode, errStr := openConnection(); errCode != 0 {
+		return errCode, errStr
+	}
+
+	rows, err := command.DbN1ql.QueryRaw(opts.Statement + QRY_EOL)
+	if rows == nil {
+		if err != nil {
+			return errors.E_SHELL_DRIVER_QUERY_METHOD, err.Error()
+		}
+		return 0, ""
+	}
+	defer rows.Close()
+
+	exp := &exporter{opts: opts}
+	errCode, errStr := exp.export(rows)
+	if errCode == 0 && err != nil {
+		errCode, errStr = errors.E_SHELL_DRIVER_QUERY_METHOD, err.Error()
+	}
+	if errCode != 0 {
+		return errCode, errStr
+	}
+	_, werr := fmt.Fprintf(command.W, command.EXPORTMSG, exp.rows, exp.files)
+	if werr != nil {
+		return errors.E_SHELL_WRITER_OUTPUT, werr.Error()
+	}
+	return 0, ""
+}
+
+type exporter struct {
+	opts *command.ExportOptions
+
+	file   *os.File
+	w      *bufio.Writer
+	csv    *csv.Writer
+	record []string
+
+	// columns of CSV output, from the first result
+	columns []string
+
+	rows   int
+	inFile int
+	files  int
+}
+
+// Write the results in the response to the files.
+func (this *exporter) export(rows io.Reader) (errors.ErrorCode, string) {
+	defer this.close()
+
+	if err := this.next(); err != nil {
+		return errors.E_SHELL_OPEN_FILE, err.Error()
+	}
+
+	dec := json.NewDecoder(rows)
+	if err := expectDelim(dec, '{'); err != nil {
+		return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+	}
+	var respErrors json.RawMessage
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+		}
+		switch tok {
+		case "results":
+			if err = expectDelim(dec, '['); err != nil {
+				return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+			}
+			for dec.More() {
+				var row json.RawMessage
+				if err = dec.Decode(&row); err != nil {
+					return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+				}
+				if err = this.add(row); err != nil {
+					return errors.E_SHELL_WRITE_FILE, err.Error()
+				}
+			}
+			if err = expectDelim(dec, ']'); err != nil {
+				return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+			}
+		case "errors":
+			err = dec.Decode(&respErrors)
+		default:
+			var v json.RawMessage
+			err = dec.Decode(&v)
+		}
+		if err != nil {
+			return errors.E_SHELL_JSON_UNMARSHAL, err.Error()
+		}
+	}
+
+	if err := this.close(); err != nil {
+		return errors.E_SHELL_WRITE_FILE, err.Error()
+	}
+	if len(respErrors) > 0 {
+		return errors.E_SHELL_DRIVER_QUERY_METHOD, string(respErrors)
+	}
+	return 0, ""
+}
+
+func (this *exporter) add(row json.RawMessage) error {
+	if this.opts.Split > 0 && this.inFile == this.opts.Split {
+		if err := this.next(); err != nil {
+			return err
+		}
+	}
+
+	if this.opts.Format == command.FORMAT_CSV {
+		if err := this.addCSV(row); err != nil {
+			return err
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, row); err != nil {
+			return err
+		}
+		buf.Write(_NL)
+		if _, err := this.w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	this.rows++
+	this.inFile++
+	return nil
+}
+
+func (this *exporter) addCSV(row json.RawMessage) error {
+	var fields flattened
+	if err := fields.addRow(row); err != nil {
+		return err
+	}
+	if this.columns == nil {
+		this.columns = fields.columns
+		this.record = make([]string, len(this.columns))
+		if err := this.csv.Write(this.columns); err != nil {
+			return err
+		}
+	}
+
+	cells := fields.rows[0]
+	for i, name := range this.columns {
+		this.record[i] = ""
+		if pos, ok := fields.index[name]; ok && pos < len(cells) {
+			this.record[i] = cellText(cells[pos], "")
+		}
+	}
+	return this.csv.Write(this.record)
+}
+
+// Start the next file.
+func (this *exporter) next() error {
+	if err := this.close(); err != nil {
+		return err
+	}
+
+	this.files++
+	name := this.opts.File
+	if this.opts.Split > 0 {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), this.files, ext)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	this.file = f
+	this.w = bufio.NewWriter(f)
+	this.inFile = 0
+
+	if this.opts.Format == command.FORMAT_CSV {
+		this.csv = csv.NewWriter(this.w)
+		if this.columns != nil {
+			return this.csv.Write(this.columns)
+		}
+	}
+	return nil
+}
+
+func (this *exporter) close() error {
+	if this.file == nil {
+		return nil
+	}
+	f := this.file
+	this.file = nil
+
+	if this.csv != nil {
+		this.csv.Flush()
+		if err := this.csv.Error(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := this.w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,143 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	parser "github.com/couchbase/query/parser/n1ql"
+	"github.com/couchbase/query/shell/cbq/command"
+)
+
+func readAll(t *testing.T, input string, format string) []string {
+	var docs []string
+	err := readDocuments(bufio.NewReader(strings.NewReader(input)), format, func(doc json.RawMessage) error {
+		var buf bytes.Buffer
+		json.Compact(&buf, doc)
+		docs = append(docs, buf.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("%q : unexpected error %v", input, err)
+	}
+	return docs
+}
+
+func TestReadDocuments(t *testing.T) {
+	expected := []string{`{"id":1,"name":"a"}`, `{"id":2,"name":"b"}`}
+
+	docs := readAll(t, " \n[{\"id\": 1, \"name\": \"a\"},\n {\"id\": 2, \"name\": \"b\"}]\n", "")
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("JSON array : got %v", docs)
+	}
+
+	docs = readAll(t, "{\"id\": 1, \"name\": \"a\"}\n{\"id\": 2, \"name\": \"b\"}\n", "")
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("JSON lines : got %v", docs)
+	}
+
+	docs = readAll(t, "id,name,zip,active\n1,a,01234,true\n-2.5,\"b, c\",,false\n", command.FORMAT_CSV)
+	expected = []string{`{"id":1,"name":"a","zip":"01234","active":true}`, `{"id":-2.5,"name":"b, c","zip":"","active":false}`}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("CSV : got %v", docs)
+	}
+}
+
+func TestImporter(t *testing.T) {
+	key, err := parser.ParseExpression("name || \"-\" || TO_STRING(id)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stmts []string
+	upsert := func(stmt string) (int, string) {
+		stmts = append(stmts, stmt)
+		if len(stmts) == 2 {
+			return 0, `[{"code":12009}]`
+		}
+		return strings.Count(stmt, "), (") + 1, ""
+	}
+
+	var b bytes.Buffer
+	opts := &command.ImportOptions{Keyspace: "ks", Key: "name", Batch: 2}
+	imp := newImporter(opts, key, &b, upsert)
+	docs := "[{\"id\": 1, \"name\": \"a\"}, {\"id\": 2, \"name\": \"b\"}, {\"id\": 3, \"name\": \"c\"}, {\"id\": 4}, {\"id\": 5, \"name\": \"e\"}]"
+	err = readDocuments(bufio.NewReader(strings.NewReader(docs)), "", imp.add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp.flush()
+	imp.summary()
+
+	expected := []string{
+		`UPSERT INTO ks VALUES ("a-1", {"id":1,"name":"a"}), ("b-2", {"id":2,"name":"b"})`,
+		`UPSERT INTO ks VALUES ("c-3", {"id":3,"name":"c"}), ("e-5", {"id":5,"name":"e"})`,
+	}
+	if !reflect.DeepEqual(stmts, expected) {
+		t.Errorf("Unexpected statements %v", stmts)
+	}
+	if imp.imported != 2 || imp.failed != 3 {
+		t.Errorf("Expected 2 imported and 3 failed, got %v %v", imp.imported, imp.failed)
+	}
+	out := b.String()
+	if !strings.Contains(out, " Error importing document 4 : KEY name is missing\n") ||
+		!strings.Contains(out, " Error importing documents 3 to 5 : [{\"code\":12009}]\n") ||
+		!strings.HasSuffix(out, " Imported 2 of 5 documents into ks.\n") {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cbq_export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	response := `{"requestID": "1", "results": [{"a": 1, "b": "x"}, {"b": "y", "c": [1, 2]}, {"a": 3}], "status": "success"}`
+
+	exp := &exporter{opts: &command.ExportOptions{File: filepath.Join(dir, "out.csv"), Format: command.FORMAT_CSV, Split: 2}}
+	errCode, errStr := exp.export(strings.NewReader(response))
+	if errCode != 0 {
+		t.Fatal(command.HandleError(errCode, errStr))
+	}
+	if exp.rows != 3 || exp.files != 2 {
+		t.Errorf("Expected 3 rows in 2 files, got %v %v", exp.rows, exp.files)
+	}
+	for name, expected := range map[string]string{"out_1.csv": "a,b\n1,x\n,y\n", "out_2.csv": "a,b\n3,\n"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(b) != expected {
+			t.Errorf("%v : expected %q, got %q %v", name, expected, b, err)
+		}
+	}
+
+	exp = &exporter{opts: &command.ExportOptions{File: filepath.Join(dir, "out.jsonl"), Format: command.FORMAT_JSONL}}
+	errCode, errStr = exp.export(strings.NewReader(response))
+	if errCode != 0 {
+		t.Fatal(command.HandleError(errCode, errStr))
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "out.jsonl"))
+	if string(b) != "{\"a\":1,\"b\":\"x\"}\n{\"b\":\"y\",\"c\":[1,2]}\n{\"a\":3}\n" {
+		t.Errorf("Unexpected JSON lines %q", b)
+	}
+
+	exp = &exporter{opts: &command.ExportOptions{File: filepath.Join(dir, "err.jsonl"), Format: command.FORMAT_JSONL}}
+	errCode, _ = exp.export(strings.NewReader(`{"errors": [{"code": 3000}], "status": "fatal"}`))
+	if errCode == 0 {
+		t.Errorf("Expected error from response errors")
+	}
+}
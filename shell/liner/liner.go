@@ -90,3 +90,16 @@ func (s *State) SetMultiLineMode(mlmode bool) {
 		s.vi.SetMultiLineMode(mlmode)
 	}
 }
+
+// Completion of the word before the cursor. head and tail are the parts of the line that
+// the completion does not replace.
+type WordCompleter func(line string, pos int) (head string, completions []string, tail string)
+
+func (s *State) SetWordCompleter(f WordCompleter) {
+	if !s.viMode {
+		s.orig.SetTabCompletionStyle(pliner.TabPrints)
+		s.orig.SetWordCompleter(pliner.WordCompleter(f))
+	} else {
+		s.vi.SetWordCompleter(pliner.WordCompleter(f))
+	}
+}
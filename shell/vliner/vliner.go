@@ -91,6 +91,15 @@ type State struct {
 	cy              int
 	promptLines     int
 	displayStartPos int
+	completer       pliner.WordCompleter
+}
+
+// state of a tab completion in progress; candidates are cycled through on repeated tabs
+type completion struct {
+	list  []string
+	next  int
+	start int
+	end   int
 }
 
 type digraph struct {
@@ -699,6 +708,10 @@ func (s *State) SetMultiLineMode(mlmode bool) {
 	s.multiLine = mlmode
 }
 
+func (s *State) SetWordCompleter(f pliner.WordCompleter) {
+	s.completer = f
+}
+
 // these persist across invocations in contrast to shell-vi-mode equivalents in order to help with repeated statement invocations
 var fact, fr rune
 var curHist int = -1
@@ -893,7 +906,7 @@ mainLoop:
 				if 0 == len(s.history) {
 					break
 				}
-				search, res = s.inputText("/", []rune(""), []rune(""), true)
+				search, res = s.inputText("/", []rune(""), []rune(""), true, false)
 				if -1 == res {
 					return "", errors.New("vliner: failed to input text")
 				} else if 0 == res || 0 == len(search) {
@@ -914,7 +927,7 @@ mainLoop:
 				if 0 == len(s.history) {
 					break
 				}
-				search, res = s.inputText("?", []rune(""), []rune(""), true)
+				search, res = s.inputText("?", []rune(""), []rune(""), true, false)
 				if -1 == res {
 					return "", errors.New("vliner: failed to input text")
 				} else if 0 == res || 0 == len(search) {
@@ -1170,7 +1183,7 @@ mainLoop:
 				if !s.replayActive {
 					prefix := line[:pos]
 					suffix := line[pos:]
-					input, done = s.inputText(prompt, prefix, suffix, true)
+					input, done = s.inputText(prompt, prefix, suffix, true, true)
 				}
 				if len(input) > 0 {
 					for ; 0 < repeat; repeat-- {
@@ -1198,7 +1211,7 @@ mainLoop:
 				if !s.replayActive {
 					prefix := line[:pos]
 					suffix := line[pos:]
-					input, done = s.inputText(prompt, prefix, suffix, false)
+					input, done = s.inputText(prompt, prefix, suffix, false, false)
 				}
 
 				if len(input) > 0 {
@@ -1696,7 +1709,7 @@ func (s *State) render(pos int, prompt string, input []rune, suffix []rune) {
 	s.showCursor()
 }
 
-func (s *State) inputText(prompt string, prefix []rune, suffix []rune, fixedSuffix bool) ([]rune, int) {
+func (s *State) inputText(prompt string, prefix []rune, suffix []rune, fixedSuffix bool, complete bool) ([]rune, int) {
 	input := make([]rune, 0, 1024)
 	input = input[:0]
 	combo := make([]rune, 0, 1024)
@@ -1704,6 +1717,7 @@ func (s *State) inputText(prompt string, prefix []rune, suffix []rune, fixedSuff
 
 	pos := 0
 	i := 0
+	var comp completion
 
 	done := -1
 	for done = -1; -1 == done; {
@@ -1723,8 +1737,13 @@ func (s *State) inputText(prompt string, prefix []rune, suffix []rune, fixedSuff
 		if nil != err {
 			return nil, -1
 		}
+		if _ASCII_TAB != r {
+			comp = completion{}
+		}
 		if _REPLAY_END == r {
 			continue
+		} else if _ASCII_TAB == r && complete && nil != s.completer {
+			input, pos = s.complete(prefix, input, pos, suffix, &comp)
 		} else if s.controlChars[ccVINTR] == r { // Ctrl+C typically
 			input = input[:0]
 			if s.interruptAborts {
@@ -1889,6 +1908,58 @@ func (s *State) inputText(prompt string, prefix []rune, suffix []rune, fixedSuff
 	return input, done
 }
 
+// Replace the word before the cursor with the next completion candidate.  The first tab extends the word to the longest
+// common prefix of the candidates; subsequent tabs cycle through them.  Only text entered in this input session can be
+// replaced.
+func (s *State) complete(prefix []rune, input []rune, pos int, suffix []rune, comp *completion) ([]rune, int) {
+	var ins []rune
+	if nil == comp.list {
+		line := make([]rune, 0, len(prefix)+len(input)+len(suffix))
+		line = append(append(append(line, prefix...), input...), suffix...)
+		head, list, _ := s.completer(string(line), len(prefix)+pos)
+		start := utf8.RuneCountInString(head) - len(prefix)
+		if 0 == len(list) || 0 > start || pos < start {
+			return input, pos
+		}
+		comp.start = start
+		comp.end = pos
+		if 1 == len(list) {
+			ins = []rune(list[0])
+		} else {
+			comp.list = list
+			ins = []rune(commonPrefix(list))
+			if len(ins) <= pos-start {
+				ins = []rune(list[0])
+				comp.next = 1
+			}
+		}
+	} else {
+		ins = []rune(comp.list[comp.next])
+		comp.next = (comp.next + 1) % len(comp.list)
+	}
+
+	tail := append([]rune(nil), input[comp.end:]...)
+	input = insertRunes(input[:comp.start], comp.start, ins)
+	input = append(input, tail...)
+	comp.end = comp.start + len(ins)
+	return input, comp.end
+}
+
+func commonPrefix(list []string) string {
+	common := []rune(list[0])
+	for _, l := range list[1:] {
+		i := 0
+		for _, r := range l {
+			if i >= len(common) || common[i] != r {
+				break
+			}
+			i++
+		}
+		common = common[:i]
+	}
+	return string(common)
+}
+
 // we display control characters as composites so we need to deal with them explicitly
 func decode(r rune) []rune {
 	if _ASCII_NUL <= r && ' ' > r {
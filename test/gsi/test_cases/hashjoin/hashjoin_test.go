@@ -73,6 +73,9 @@ func TestHashJoin(t *testing.T) {
 	// test ANSI OUTER JOIN to ANSI INNER JOIN transformation
 	runMatch("case_hashjoin_oj2ij.json", false, true, qc, t)
 
+	// test MERGE JOIN and MERGE NEST on the same queries
+	runMatch("case_mergejoin.json", false, true, qc, t)
+
 	fmt.Println("Dropping indexes")
 	runStmt(qc, "DROP INDEX customer.cust_lastName_firstName_customerId")
 	runStmt(qc, "DROP INDEX customer.cust_customerId_lastName_firstName")
@@ -44,6 +44,9 @@ func TestHints(t *testing.T) {
 	// hints with errors
 	runMatch("case_hints_errors.json", false, true, qc, t)
 
+	// join order, index exclusion and parallelism hints
+	runMatch("case_hints_joins.json", false, true, qc, t)
+
 	fmt.Println("Dropping indexes")
 	runStmt(qc, "DROP INDEX customer.cust_lastName_firstName_customerId")
 	runStmt(qc, "DROP INDEX customer.cust_customerId_lastName_firstName")
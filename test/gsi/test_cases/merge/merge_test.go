@@ -38,6 +38,9 @@ func TestMerge(t *testing.T) {
 	// test simple MERGE
 	runMatch("case_merge_simple.json", false, false, qc, t)
 
+	// test RETURNING of OLD/NEW images and MERGE_ACTION()
+	runMatch("case_merge_returning.json", false, false, qc, t)
+
 	// test MERGE with index hints
 	runMatch("case_merge_indexhint.json", false, true, qc, t)
 
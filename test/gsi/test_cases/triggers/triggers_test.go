@@ -0,0 +1,40 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package triggers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Triggers fired by INSERT, UPSERT, UPDATE, DELETE and MERGE
+func TestTriggers(t *testing.T) {
+	if strings.ToLower(os.Getenv("GSI_TEST")) != "true" {
+		return
+	}
+
+	qc := start_cs()
+
+	runStmt(qc, "CREATE PRIMARY INDEX ON shellTest")
+	runStmt(qc, "CREATE FUNCTION trg_tag(d, tag) { OBJECT_PUT(d, \"tag\", tag) }")
+	runStmt(qc, "CREATE FUNCTION trg_check(d) { d.qty >= 0 }")
+
+	fmt.Println("Running trigger test cases")
+	runMatch("case_triggers.json", false, false, qc, t)
+
+	runStmt(qc, "DROP TRIGGER t_insert ON shellTest IF EXISTS")
+	runStmt(qc, "DROP TRIGGER t_update ON shellTest IF EXISTS")
+	runStmt(qc, "DROP TRIGGER t_check ON shellTest IF EXISTS")
+	runStmt(qc, "DROP FUNCTION trg_tag")
+	runStmt(qc, "DROP FUNCTION trg_check")
+	runStmt(qc, "DELETE FROM shellTest")
+	runStmt(qc, "DROP PRIMARY INDEX ON shellTest")
+}
@@ -11,6 +11,7 @@ package util
 import (
 	"fmt"
 	"math"
+	"sync"
 )
 
 // an implementation of hash table loosely based on google's densehash
@@ -104,6 +105,17 @@ func NewHashTable(purpose int) *HashTable {
 func (this *HashTable) Put(hashVal, inputVal interface{}, marshal func(interface{}) ([]byte, error),
 	equal func(val1, val2 interface{}) bool, size uint64) error {
 
+	hashKey, err := this.getHashKey(hashVal, marshal)
+	if err != nil {
+		return err
+	}
+
+	return this.putHashed(hashKey, hashVal, inputVal, equal, size)
+}
+
+func (this *HashTable) putHashed(hashKey uint64, hashVal, inputVal interface{},
+	equal func(val1, val2 interface{}) bool, size uint64) error {
+
 	this.mode = HASH_TABLE_PUT
 
 	if this.loadFactor() >= HTLoadThreshold {
@@ -113,14 +125,9 @@ func (this *HashTable) Put(hashVal, inputVal interface{}, marshal func(interface
 		}
 	}
 
-	hashKey, err := this.getHashKey(hashVal, marshal)
-	if err != nil {
-		return err
-	}
-
 	hashEntry := newHashEntry(hashKey, hashVal, inputVal)
 
-	err = this.putEntry(hashEntry, equal)
+	err := this.putEntry(hashEntry, equal)
 	if err == nil {
 		this.size += size
 	}
@@ -176,27 +183,62 @@ func (this *HashTable) Get(hashVal interface{}, marshal func(interface{}) ([]byt
 		return nil, err
 	}
 
+	idx, err := this.find(hashKey, hashVal, equal)
+	if idx < 0 || err != nil {
+		return nil, err
+	}
+	e := this.entries[idx]
+	if len(e.inputVals) > 1 {
+		this.bucket = idx
+		this.vector = 1
+	}
+	return e.inputVals[0], nil
+}
+
+// given a hash value (hashVal), get all the values associated with it
+// Lookup does not change the state of the hash table, and can be used
+// by multiple goroutines at once after the insertion phase
+func (this *HashTable) Lookup(hashVal interface{}, marshal func(interface{}) ([]byte, error),
+	equal func(val1, val2 interface{}) bool) ([]interface{}, error) {
+
+	hashKey, err := this.getHashKey(hashVal, marshal)
+	if err != nil {
+		return nil, err
+	}
+	return this.lookupHashed(hashKey, hashVal, equal)
+}
+
+func (this *HashTable) lookupHashed(hashKey uint64, hashVal interface{},
+	equal func(val1, val2 interface{}) bool) ([]interface{}, error) {
+
+	idx, err := this.find(hashKey, hashVal, equal)
+	if idx < 0 || err != nil {
+		return nil, err
+	}
+	return this.entries[idx].inputVals, nil
+}
+
+// the position of the entry for a hash value, or -1 if there is none
+func (this *HashTable) find(hashKey uint64, hashVal interface{},
+	equal func(val1, val2 interface{}) bool) (int, error) {
+
 	size_minus_one := uint64(len(this.entries) - 1)
 	idx := int(hashKey & size_minus_one)
 	for i := 0; i < len(this.entries); i++ {
 		e := this.entries[idx]
 		if e != nil {
 			if e.hashKey == hashKey && equal(e.hashVal, hashVal) {
-				if len(e.inputVals) > 1 {
-					this.bucket = idx
-					this.vector = 1
-				}
-				return e.inputVals[0], nil
+				return idx, nil
 			} else {
 				idx = int(uint64(idx+i+1) & size_minus_one)
 			}
 		} else {
-			return nil, nil
+			return -1, nil
 		}
 	}
 
 	// should have either found the entry or stopped looking (finding nil)
-	return nil, fmt.Errorf("HashTable.Get: unexpected traversal of hash table")
+	return -1, fmt.Errorf("HashTable.Get: unexpected traversal of hash table")
 }
 
 // after initial Get() call, return any additional values associated with the same hash value
@@ -222,6 +264,10 @@ func (this *HashTable) GetNext() (interface{}, error) {
 }
 
 func (this *HashTable) getHashKey(hashVal interface{}, marshal func(interface{}) ([]byte, error)) (uint64, error) {
+	return getHashKey(hashVal, marshal)
+}
+
+func getHashKey(hashVal interface{}, marshal func(interface{}) ([]byte, error)) (uint64, error) {
 	bytes, err := marshal(hashVal)
 	if err != nil {
 		return 0, err
@@ -317,6 +363,85 @@ func (this *HashTable) Drop() {
 	this.size = 0
 }
 
+// a hash table split in partitions by hash code, so that it can be built
+// by several goroutines at once, each only locking the partition it is
+// inserting into, and probed by several goroutines at once once built
+type PartitionedHashTable struct {
+	partitions []*HashTable
+	locks      []sync.Mutex
+}
+
+func NewPartitionedHashTable(purpose int, partitions int) *PartitionedHashTable {
+	if partitions < 1 {
+		partitions = 1
+	}
+	rv := &PartitionedHashTable{
+		partitions: make([]*HashTable, partitions),
+		locks:      make([]sync.Mutex, partitions),
+	}
+	for i := range rv.partitions {
+		rv.partitions[i] = NewHashTable(purpose)
+	}
+	return rv
+}
+
+// the low bits of the hash code choose the slot within a partition,
+// so use the high bits to choose the partition
+func (this *PartitionedHashTable) partition(hashKey uint64) int {
+	return int((hashKey >> 32) % uint64(len(this.partitions)))
+}
+
+func (this *PartitionedHashTable) Put(hashVal, inputVal interface{}, marshal func(interface{}) ([]byte, error),
+	equal func(val1, val2 interface{}) bool, size uint64) error {
+
+	hashKey, err := getHashKey(hashVal, marshal)
+	if err != nil {
+		return err
+	}
+
+	p := this.partition(hashKey)
+	this.locks[p].Lock()
+	err = this.partitions[p].putHashed(hashKey, hashVal, inputVal, equal, size)
+	this.locks[p].Unlock()
+	return err
+}
+
+func (this *PartitionedHashTable) Lookup(hashVal interface{}, marshal func(interface{}) ([]byte, error),
+	equal func(val1, val2 interface{}) bool) ([]interface{}, error) {
+
+	hashKey, err := getHashKey(hashVal, marshal)
+	if err != nil {
+		return nil, err
+	}
+	return this.partitions[this.partition(hashKey)].lookupHashed(hashKey, hashVal, equal)
+}
+
+func (this *PartitionedHashTable) Partitions() int {
+	return len(this.partitions)
+}
+
+func (this *PartitionedHashTable) Count() int {
+	count := 0
+	for _, p := range this.partitions {
+		count += p.Count()
+	}
+	return count
+}
+
+func (this *PartitionedHashTable) Size() uint64 {
+	size := uint64(0)
+	for _, p := range this.partitions {
+		size += p.Size()
+	}
+	return size
+}
+
+func (this *PartitionedHashTable) Drop() {
+	for _, p := range this.partitions {
+		p.Drop()
+	}
+}
+
 const (
 	NUMBER_NOT_AVAIL = -1.0
 	_ERR_MARGIN      = 0.0000000000001
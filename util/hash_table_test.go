@@ -11,6 +11,7 @@ package util
 import (
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -160,3 +161,69 @@ func TestHashTable(t *testing.T) {
 	// drop the hash table
 	htab.Drop()
 }
+
+func TestPartitionedHashTable(t *testing.T) {
+
+	// create a hash table
+	htab := NewPartitionedHashTable(HASH_TABLE_FOR_HASH_JOIN, 4)
+
+	// insert values from several goroutines at once
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < 4096; i += 8 {
+				dup := 1
+				if (i & 0xff) == 0 {
+					dup = 5
+				}
+				for j := 0; j < dup; j++ {
+					e := htab.Put(i, fmt.Sprintf("payload i = %d j = %d", i, j), getBytesInt, equalInt, 1)
+					if e != nil {
+						t.Errorf("PUT of int value failed, i = %d j = %d", i, j)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// 4080 single values and 16 values with 5 duplicates each
+	if htab.Count() != 4160 {
+		t.Errorf("Incorrect number of entries, expect 4160, get %d", htab.Count())
+	}
+	if htab.Size() != 4160 {
+		t.Errorf("Incorrect size, expect 4160, get %d", htab.Size())
+	}
+
+	// probe from several goroutines at once
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w - 8; i < 4104; i += 8 {
+				dup := 1
+				if i < 0 || i >= 4096 {
+					dup = 0
+				} else if (i & 0xff) == 0 {
+					dup = 5
+				}
+				vals, e := htab.Lookup(i, getBytesInt, equalInt)
+				if e != nil {
+					t.Errorf("LOOKUP of int value failed, i = %d", i)
+				}
+				if len(vals) != dup {
+					t.Errorf("Unexpected number of results for int value %d, expect %d get %d", i, dup, len(vals))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// drop the hash table
+	htab.Drop()
+	if htab.Count() != 0 {
+		t.Errorf("Incorrect number of entries after Drop(), expect 0, get %d", htab.Count())
+	}
+}
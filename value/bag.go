@@ -24,6 +24,7 @@ type Bag struct {
 	nulls    *BagEntry
 	booleans map[bool]*BagEntry
 	floats   map[float64]*BagEntry
+	decimals map[string]*BagEntry
 	ints     map[int64]*BagEntry
 	strings  map[string]*BagEntry
 	arrays   map[string]*BagEntry
@@ -42,6 +43,7 @@ func NewBag(objectCap int) *Bag {
 	return &Bag{
 		booleans: make(map[bool]*BagEntry, 2),
 		floats:   make(map[float64]*BagEntry, mapCap),
+		decimals: make(map[string]*BagEntry, _MAP_CAP),
 		ints:     make(map[int64]*BagEntry, mapCap),
 		strings:  make(map[string]*BagEntry, mapCap),
 		arrays:   make(map[string]*BagEntry, _MAP_CAP),
@@ -94,6 +96,9 @@ func (this *Bag) Put(key, item Value) {
 		entry.Count++
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -124,6 +129,15 @@ func (this *Bag) Put(key, item Value) {
 				this.ints[akey] = entry
 			}
 
+			entry.Count++
+		case decimalValue:
+			akey := num.String()
+			entry := this.decimals[akey]
+			if entry == nil {
+				entry = &BagEntry{Value: item}
+				this.decimals[akey] = entry
+			}
+
 			entry.Count++
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
@@ -183,6 +197,9 @@ func (this *Bag) Entry(key Value) *BagEntry {
 		return this.booleans[key.Actual().(bool)]
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -193,6 +210,8 @@ func (this *Bag) Entry(key Value) *BagEntry {
 			}
 		case intValue:
 			return this.ints[int64(num)]
+		case decimalValue:
+			return this.decimals[num.String()]
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -211,8 +230,8 @@ func (this *Bag) Entry(key Value) *BagEntry {
 }
 
 func (this *Bag) DistinctLen() int {
-	rv := len(this.booleans) + len(this.floats) + len(this.ints) + len(this.strings) +
-		len(this.arrays) + len(this.objects) + len(this.binaries)
+	rv := len(this.booleans) + len(this.floats) + len(this.decimals) + len(this.ints) +
+		len(this.strings) + len(this.arrays) + len(this.objects) + len(this.binaries)
 
 	if this.nills != nil {
 		rv++
@@ -252,6 +271,10 @@ func (this *Bag) Entries() []*BagEntry {
 		rv = append(rv, av)
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av)
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av)
 	}
@@ -290,6 +313,11 @@ func (this *Bag) Clear() {
 		delete(this.floats, k)
 	}
 
+	for k, _ := range this.decimals {
+		this.decimals[k] = nil
+		delete(this.decimals, k)
+	}
+
 	for k, _ := range this.ints {
 		this.ints[k] = nil
 		delete(this.ints, k)
@@ -0,0 +1,729 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package value
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	json "github.com/couchbase/go_json"
+	"github.com/couchbase/query/util"
+)
+
+/*
+decimalValue is an exact, arbitrary precision decimal number, with the
+value unscaled * 10^-scale. It is used for JSON numbers that neither
+an int64 nor a float64 can hold exactly, and for the results of
+arithmetic in requests that use decimal arithmetic. Trailing zeros
+are always stripped from the fraction, so that equal decimals have
+the same representation.
+*/
+type decimalValue struct {
+	unscaled *big.Int
+	scale    int
+}
+
+/*
+Significant digits that a float64 always holds exactly.
+*/
+const _FLOAT_DIGITS = 15
+
+/*
+Digits of the fraction kept by decimal division.
+*/
+const _DECIMAL_DIV_SCALE = 34
+
+/*
+Numbers with larger exponents than this are not held as decimals,
+so that a short JSON number cannot claim unbounded memory.
+*/
+const _DECIMAL_MAX_EXP = 1024
+
+var _BIG_TEN = big.NewInt(10)
+
+/*
+Strip the trailing zeros of the fraction. The decimal takes ownership
+of unscaled, which must not be shared.
+*/
+func newDecimal(unscaled *big.Int, scale int) decimalValue {
+	if unscaled.Sign() == 0 {
+		return decimalValue{unscaled: unscaled, scale: 0}
+	}
+
+	if scale > 0 {
+		q, r := new(big.Int), new(big.Int)
+		for scale > 0 {
+			q.QuoRem(unscaled, _BIG_TEN, r)
+			if r.Sign() != 0 {
+				break
+			}
+			unscaled, q = q, unscaled
+			scale--
+		}
+	}
+	return decimalValue{unscaled: unscaled, scale: scale}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(_BIG_TEN, big.NewInt(int64(n)), nil)
+}
+
+/*
+Parse the text of a JSON number. Returns false if it is not a valid
+number, or its exponent is out of range.
+*/
+func parseDecimal(s string) (decimalValue, bool) {
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil || e > _DECIMAL_MAX_EXP || e < -_DECIMAL_MAX_EXP {
+			return decimalValue{}, false
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	scale := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+
+	unscaled, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return decimalValue{}, false
+	}
+
+	scale -= exp
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return newDecimal(unscaled, scale), true
+}
+
+/*
+Bring the text of a JSON number into the Value type system. Numbers
+that an int64 or a float64 holds exactly become integer or float
+values, as always; the others are kept exactly as decimals.
+*/
+func newNumberValue(s string) Value {
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return intValue(i)
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && significantDigits(s) <= _FLOAT_DIGITS {
+		return NewValue(f)
+	}
+
+	d, ok := parseDecimal(s)
+	if !ok {
+		return NewValue(f)
+	}
+
+	if err == nil && !math.IsInf(f, 0) && floatDecimal(f).cmp(d) == 0 {
+		return NewValue(f)
+	}
+	return d
+}
+
+func significantDigits(s string) int {
+	n := 0
+	leading := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == 'e' || c == 'E' {
+			break
+		} else if c < '0' || c > '9' || (leading && c == '0') {
+			continue
+		}
+		leading = false
+		n++
+	}
+	return n
+}
+
+/*
+The decimal with the shortest representation that rounds to f, so
+that a float such as 0.1 converts to exactly 0.1. f must be finite.
+*/
+func floatDecimal(f float64) decimalValue {
+	d, _ := parseDecimal(strconv.FormatFloat(f, 'e', -1, 64))
+	return d
+}
+
+/*
+Convert an integer, float or decimal to a decimal. Returns false for
+NaN and infinite floats.
+*/
+func toDecimal(n Value) (decimalValue, bool) {
+	switch n := n.unwrap().(type) {
+	case decimalValue:
+		return n, true
+	case intValue:
+		return decimalValue{unscaled: big.NewInt(int64(n))}, true
+	case floatValue:
+		f := float64(n)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return decimalValue{}, false
+		}
+		return floatDecimal(f), true
+	}
+	return decimalValue{}, false
+}
+
+/*
+Return the number as an exact decimal, for decimal arithmetic. NaN
+and infinite floats are returned as they are.
+*/
+func ToDecimal(n NumberValue) NumberValue {
+	if d, ok := toDecimal(n); ok {
+		return d
+	}
+	return n
+}
+
+/*
+Return the number as an integer or float, for binary floating point
+arithmetic. Decimals are rounded to the nearest float64.
+*/
+func ToBinary(n NumberValue) NumberValue {
+	if d, ok := n.unwrap().(decimalValue); ok {
+		return NewValue(d.Float64()).(NumberValue)
+	}
+	return n
+}
+
+/*
+Exact decimal division, rounded half away from zero to 34 digits of
+fraction. Returns NULL for division by zero.
+*/
+func DecimalDiv(a, b NumberValue) Value {
+	x, ok := toDecimal(a)
+	if !ok {
+		return NewValue(a.Float64() / b.Float64())
+	}
+
+	y, ok := toDecimal(b)
+	if !ok {
+		return NewValue(a.Float64() / b.Float64())
+	} else if y.unscaled.Sign() == 0 {
+		return NULL_VALUE
+	}
+
+	scale := _DECIMAL_DIV_SCALE
+	if x.scale > scale {
+		scale = x.scale
+	}
+
+	num := new(big.Int).Mul(x.unscaled, pow10(scale+y.scale-x.scale))
+	q, r := new(big.Int).QuoRem(num, y.unscaled, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(new(big.Int).Abs(y.unscaled)) >= 0 {
+		if num.Sign() == y.unscaled.Sign() {
+			q.Add(q, big.NewInt(1))
+		} else {
+			q.Sub(q, big.NewInt(1))
+		}
+	}
+	return newDecimal(q, scale)
+}
+
+/*
+Exact decimal remainder, with the sign of the dividend. Returns NULL
+for division by zero.
+*/
+func DecimalMod(a, b NumberValue) Value {
+	x, ok := toDecimal(a)
+	if !ok {
+		return NewValue(math.Mod(a.Float64(), b.Float64()))
+	}
+
+	y, ok := toDecimal(b)
+	if !ok {
+		return NewValue(math.Mod(a.Float64(), b.Float64()))
+	} else if y.unscaled.Sign() == 0 {
+		return NULL_VALUE
+	}
+
+	xu, yu, scale := align(x, y)
+	return newDecimal(new(big.Int).Rem(xu, yu), scale)
+}
+
+/*
+Return the unscaled values of x and y at their common scale.
+*/
+func align(x, y decimalValue) (*big.Int, *big.Int, int) {
+	switch {
+	case x.scale < y.scale:
+		return new(big.Int).Mul(x.unscaled, pow10(y.scale-x.scale)), y.unscaled, y.scale
+	case x.scale > y.scale:
+		return x.unscaled, new(big.Int).Mul(y.unscaled, pow10(x.scale-y.scale)), x.scale
+	default:
+		return x.unscaled, y.unscaled, x.scale
+	}
+}
+
+func (this decimalValue) cmp(other decimalValue) int {
+	x, y, _ := align(this, other)
+	return x.Cmp(y)
+}
+
+/*
+The integer part, truncated towards zero.
+*/
+func (this decimalValue) trunc() *big.Int {
+	if this.scale == 0 {
+		return this.unscaled
+	}
+	return new(big.Int).Quo(this.unscaled, pow10(this.scale))
+}
+
+/*
+Return the integer or float value equal to the receiver, if there is
+one, so that sets and bags file equal numbers under the same key.
+*/
+func (this decimalValue) setKey() Value {
+	if this.scale == 0 && this.unscaled.IsInt64() {
+		return intValue(this.unscaled.Int64())
+	}
+
+	f := this.Float64()
+	if !math.IsInf(f, 0) && floatDecimal(f).cmp(this) == 0 {
+		return floatValue(f)
+	}
+	return this
+}
+
+func (this decimalValue) String() string {
+	s := this.unscaled.String()
+	if this.scale == 0 {
+		return s
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	if len(s) <= this.scale {
+		s = strings.Repeat("0", this.scale-len(s)+1) + s
+	}
+	s = s[:len(s)-this.scale] + "." + s[len(s)-this.scale:]
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+func (this decimalValue) ToString() string {
+	return this.String()
+}
+
+func (this decimalValue) MarshalJSON() ([]byte, error) {
+	return []byte(this.String()), nil
+}
+
+func (this decimalValue) WriteJSON(w io.Writer, prefix, indent string, fast bool) error {
+	_, err := w.Write([]byte(this.String()))
+	return err
+}
+
+/*
+Type NUMBER
+*/
+func (this decimalValue) Type() Type {
+	return NUMBER
+}
+
+/*
+The nearest float64, like every other number.
+*/
+func (this decimalValue) Actual() interface{} {
+	return this.Float64()
+}
+
+/*
+Return int64 if the receiver is one, otherwise the exact digits as a
+json.Number, so that writing and indexing the value do not round it.
+*/
+func (this decimalValue) ActualForIndex() interface{} {
+	if this.scale == 0 && this.unscaled.IsInt64() {
+		return this.unscaled.Int64()
+	}
+	return json.Number(this.String())
+}
+
+func (this decimalValue) Equals(other Value) Value {
+	other = other.unwrap()
+	switch other := other.(type) {
+	case missingValue:
+		return other
+	case *nullValue:
+		return other
+	case decimalValue, intValue, floatValue:
+		if this.Collate(other) == 0 {
+			return TRUE_VALUE
+		}
+	}
+
+	return FALSE_VALUE
+}
+
+func (this decimalValue) EquivalentTo(other Value) bool {
+	other = other.unwrap()
+	switch other.(type) {
+	case decimalValue, intValue, floatValue:
+		return this.Collate(other) == 0
+	default:
+		return false
+	}
+}
+
+func (this decimalValue) Collate(other Value) int {
+	other = other.unwrap()
+	switch other := other.(type) {
+	case decimalValue:
+		return this.cmp(other)
+	case intValue:
+		o, _ := toDecimal(other)
+		return this.cmp(o)
+	case floatValue:
+		o, ok := toDecimal(other)
+		if !ok {
+			// NaN and infinities
+			return collateFloat(this.Float64(), float64(other))
+		}
+		return this.cmp(o)
+	default:
+		return int(NUMBER - other.Type())
+	}
+}
+
+func (this decimalValue) Compare(other Value) Value {
+	other = other.unwrap()
+	switch other := other.(type) {
+	case missingValue:
+		return other
+	case *nullValue:
+		return other
+	default:
+		return intValue(this.Collate(other))
+	}
+}
+
+/*
+Returns true in the event the receiver is not 0.
+*/
+func (this decimalValue) Truth() bool {
+	return this.unscaled.Sign() != 0
+}
+
+/*
+Return receiver
+*/
+func (this decimalValue) Copy() Value {
+	return this
+}
+
+/*
+Return receiver
+*/
+func (this decimalValue) CopyForUpdate() Value {
+	return this
+}
+
+/*
+Calls missingField.
+*/
+func (this decimalValue) Field(field string) (Value, bool) {
+	return missingField(field), false
+}
+
+/*
+Not valid for NUMBER.
+*/
+func (this decimalValue) SetField(field string, val interface{}) error {
+	return Unsettable(field)
+}
+
+/*
+Not valid for NUMBER.
+*/
+func (this decimalValue) UnsetField(field string) error {
+	return Unsettable(field)
+}
+
+/*
+Calls missingIndex.
+*/
+func (this decimalValue) Index(index int) (Value, bool) {
+	return missingIndex(index), false
+}
+
+/*
+Not valid for NUMBER.
+*/
+func (this decimalValue) SetIndex(index int, val interface{}) error {
+	return Unsettable(strconv.Itoa(index))
+}
+
+/*
+Returns NULL_VALUE
+*/
+func (this decimalValue) Slice(start, end int) (Value, bool) {
+	return NULL_VALUE, false
+}
+
+/*
+Returns NULL_VALUE
+*/
+func (this decimalValue) SliceTail(start int) (Value, bool) {
+	return NULL_VALUE, false
+}
+
+/*
+Returns the input buffer as is.
+*/
+func (this decimalValue) Descendants(buffer []interface{}) []interface{} {
+	return buffer
+}
+
+/*
+As number has no fields, return nil.
+*/
+func (this decimalValue) Fields() map[string]interface{} {
+	return nil
+}
+
+func (this decimalValue) FieldNames(buffer []string) []string {
+	return nil
+}
+
+/*
+Returns the input buffer as is.
+*/
+func (this decimalValue) DescendantPairs(buffer []util.IPair) []util.IPair {
+	return buffer
+}
+
+/*
+The next float64 above the receiver. The float nearest to a decimal
+is never more than half way to the next one, so this is always greater.
+*/
+func (this decimalValue) Successor() Value {
+	return floatValue(this.Float64()).Successor()
+}
+
+func (this decimalValue) Track() {
+}
+
+func (this decimalValue) Recycle() {
+}
+
+func (this decimalValue) Tokens(set *Set, options Value) *Set {
+	set.Add(this)
+	return set
+}
+
+func (this decimalValue) ContainsToken(token, options Value) bool {
+	return this.EquivalentTo(token)
+}
+
+func (this decimalValue) ContainsMatchingToken(matcher MatchFunc, options Value) bool {
+	return matcher(this.Float64())
+}
+
+func (this decimalValue) Size() uint64 {
+	return uint64(16 + len(this.unscaled.Bits())*8)
+}
+
+func (this decimalValue) unwrap() Value {
+	return this
+}
+
+/*
+NumberValue methods. Decimals are exact, so they take precedence over
+integers and floats; only NaN and infinities are left to float64.
+*/
+
+func (this decimalValue) Add(n NumberValue) NumberValue {
+	d, ok := toDecimal(n)
+	if !ok {
+		return floatValue(this.Float64() + n.Float64())
+	}
+
+	x, y, scale := align(this, d)
+	return newDecimal(new(big.Int).Add(x, y), scale)
+}
+
+func (this decimalValue) IDiv(n NumberValue) Value {
+	d, ok := toDecimal(n)
+	if !ok {
+		return NULL_VALUE
+	}
+
+	y := d.trunc()
+	if y.Sign() == 0 {
+		return NULL_VALUE
+	}
+	return newDecimal(new(big.Int).Quo(this.trunc(), y), 0).setKey()
+}
+
+func (this decimalValue) IMod(n NumberValue) Value {
+	d, ok := toDecimal(n)
+	if !ok {
+		return NULL_VALUE
+	}
+
+	y := d.trunc()
+	if y.Sign() == 0 {
+		return NULL_VALUE
+	}
+	return newDecimal(new(big.Int).Rem(this.trunc(), y), 0).setKey()
+}
+
+func (this decimalValue) Mult(n NumberValue) NumberValue {
+	d, ok := toDecimal(n)
+	if !ok {
+		return floatValue(this.Float64() * n.Float64())
+	}
+
+	return newDecimal(new(big.Int).Mul(this.unscaled, d.unscaled), this.scale+d.scale)
+}
+
+func (this decimalValue) Neg() NumberValue {
+	return decimalValue{unscaled: new(big.Int).Neg(this.unscaled), scale: this.scale}
+}
+
+func (this decimalValue) Sub(n NumberValue) NumberValue {
+	d, ok := toDecimal(n)
+	if !ok {
+		return floatValue(this.Float64() - n.Float64())
+	}
+
+	x, y, scale := align(this, d)
+	return newDecimal(new(big.Int).Sub(x, y), scale)
+}
+
+/*
+Truncated towards zero, and saturated at the int64 limits.
+*/
+func (this decimalValue) Int64() int64 {
+	i := this.trunc()
+	switch {
+	case i.IsInt64():
+		return i.Int64()
+	case i.Sign() < 0:
+		return math.MinInt64
+	default:
+		return math.MaxInt64
+	}
+}
+
+func (this decimalValue) Float64() float64 {
+	f, _ := strconv.ParseFloat(this.String(), 64)
+	return f
+}
+
+/*
+Container values are unmarshaled with int64 and float64 numbers.
+hasLongNumber finds out cheaply whether a value holds a number that
+neither holds exactly, which is an integer beyond the int64 range or
+any other number with more significant digits than a float64 always
+holds, and unmarshalNumbers keeps such numbers exact.
+*/
+func hasLongNumber(raw []byte) bool {
+	inString := false
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		} else if c == '-' || (c >= '0' && c <= '9') {
+			start := i
+			for i < len(raw) && isNumberByte(raw[i]) {
+				i++
+			}
+			if isLongNumber(raw[start:i]) {
+				return true
+			}
+			i--
+		}
+	}
+	return false
+}
+
+func isNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+func isLongNumber(num []byte) bool {
+	digits := 0
+	for _, c := range num {
+		if c == 'e' || c == 'E' {
+			break
+		} else if c >= '0' && c <= '9' && (digits > 0 || c != '0') {
+			digits++
+		}
+	}
+	if digits <= _FLOAT_DIGITS {
+		return false
+	} else if bytes.ContainsAny(num, ".eE") {
+		return true
+	}
+	_, err := strconv.ParseInt(string(num), 10, 64)
+	return err != nil
+}
+
+func unmarshalNumbers(raw []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var rv interface{}
+	err := decoder.Decode(&rv)
+	if err != nil {
+		return nil, err
+	}
+	return exactNumbers(rv), nil
+}
+
+func exactNumbers(val interface{}) interface{} {
+	switch val := val.(type) {
+	case json.Number:
+		n := newNumberValue(string(val))
+		if _, ok := n.(decimalValue); ok {
+			return n
+		}
+		return n.Actual()
+	case []interface{}:
+		for i, v := range val {
+			val[i] = exactNumbers(v)
+		}
+	case map[string]interface{}:
+		for k, v := range val {
+			val[k] = exactNumbers(v)
+		}
+	}
+	return val
+}
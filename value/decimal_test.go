@@ -0,0 +1,168 @@
+//  Copyright 2021-Present Couchbase, Inc.
+//
+//  Use of this software is governed by the Business Source License included
+//  in the file licenses/BSL-Couchbase.txt.  As of the Change Date specified
+//  in that file, in accordance with the Business Source License, use of this
+//  software will be governed by the Apache License, Version 2.0, included in
+//  the file licenses/APL2.txt.
+
+package value
+
+import (
+	"testing"
+
+	json "github.com/couchbase/go_json"
+)
+
+func TestDecimalParsing(t *testing.T) {
+
+	var tests = []struct {
+		input    string
+		expected string
+		decimal  bool
+	}{
+		{`42`, `42`, false},
+		{`0.1`, `0.1`, false},
+		{`0.10000000000000000`, `0.1`, false},
+		{`1e3`, `1000`, false},
+		{`9007199254740993`, `9007199254740993`, false},
+		{`12345678901234567890`, `12345678901234567890`, true},
+		{`-12345678901234567890.5`, `-12345678901234567890.5`, true},
+		{`3.14159265358979323846264`, `3.14159265358979323846264`, true},
+		{`0.0000000000000000000123456789012345678901`, `0.0000000000000000000123456789012345678901`, true},
+		{`1.2345678901234567890E+5`, `123456.7890123456789`, true},
+	}
+
+	for _, test := range tests {
+		val := NewValue([]byte(test.input))
+		if val.Type() != NUMBER {
+			t.Errorf("Expected %s to be a number, got %v", test.input, val.Type())
+			continue
+		}
+
+		if s := val.String(); s != test.expected {
+			t.Errorf("Expected %s to marshal as %s, got %s", test.input, test.expected, s)
+		}
+
+		if _, ok := val.(decimalValue); ok != test.decimal {
+			t.Errorf("Expected %s to be decimal %v, got %T", test.input, test.decimal, val)
+		}
+	}
+}
+
+func TestDecimalDocument(t *testing.T) {
+	doc := NewValue([]byte(`{"id": 12345678901234567890, "amounts": [0.1, 1.00000000000000000001]}`))
+
+	id, _ := doc.Field("id")
+	if id.String() != "12345678901234567890" {
+		t.Errorf("Expected exact id, got %s", id.String())
+	}
+
+	bytes, _ := doc.MarshalJSON()
+	expected := `{"amounts":[0.1,1.00000000000000000001],"id":12345678901234567890}`
+	if string(bytes) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(bytes))
+	}
+
+	actual, _ := json.Marshal(id.ActualForIndex())
+	if string(actual) != "12345678901234567890" {
+		t.Errorf("Expected exact index key, got %s", string(actual))
+	}
+}
+
+func TestDecimalCollation(t *testing.T) {
+	big := NewValue(json.Number("12345678901234567890"))
+	bigger := NewValue(json.Number("12345678901234567891"))
+	tenth := NewValue(json.Number("0.100000000000000000001"))
+
+	var tests = []struct {
+		first    Value
+		second   Value
+		expected int
+	}{
+		{big, bigger, -1},
+		{bigger, big, 1},
+		{big, NewValue(json.Number("12345678901234567890.0")), 0},
+		{big, NewValue(float64(12345678901234567890)), 1},
+		{tenth, NewValue(0.1), 1},
+		{NewValue(0.1), tenth, -1},
+		{tenth, NewValue(int64(1)), -1},
+		{NewValue(int64(1)), tenth, 1},
+		{tenth, NewValue("0.1"), -1},
+	}
+
+	for _, test := range tests {
+		if c := test.first.Collate(test.second); c != test.expected {
+			t.Errorf("Expected %v collate %v to be %d, got %d", test.first, test.second, test.expected, c)
+		}
+	}
+
+	if !big.Equals(NewValue(json.Number("1.2345678901234567890e19"))).Truth() {
+		t.Errorf("Expected equal decimals")
+	}
+
+	set := NewSet(8, true, false)
+	set.Add(big)
+	set.Add(bigger)
+	set.Add(NewValue(json.Number("1.2345678901234567891e19")))
+	set.Add(NewValue(json.Number("0.5000000000000000000")))
+	set.Add(NewValue(0.5))
+	if set.Len() != 3 {
+		t.Errorf("Expected 3 distinct numbers, got %d", set.Len())
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tenth := ToDecimal(AsNumberValue(NewValue(0.1)))
+	fifth := ToDecimal(AsNumberValue(NewValue(0.2)))
+	big := AsNumberValue(NewValue(json.Number("12345678901234567890")))
+
+	var tests = []struct {
+		result   Value
+		expected string
+	}{
+		{tenth.Add(fifth), "0.3"},
+		{fifth.Sub(tenth), "0.1"},
+		{tenth.Mult(fifth), "0.02"},
+		{tenth.Neg(), "-0.1"},
+		{big.Add(ONE_NUMBER), "12345678901234567891"},
+		{ONE_NUMBER.Sub(big), "-12345678901234567889"},
+		{NewValue(0.5).(NumberValue).Mult(big), "6172839450617283945"},
+		{big.IDiv(intValue(7)), "1763668414462081127"},
+		{big.IMod(intValue(7)), "1"},
+		{DecimalDiv(ONE_NUMBER, intValue(3)), "0.3333333333333333333333333333333333"},
+		{DecimalDiv(intValue(2), intValue(3)), "0.6666666666666666666666666666666667"},
+		{DecimalDiv(intValue(-2), intValue(3)), "-0.6666666666666666666666666666666667"},
+		{DecimalDiv(ONE_NUMBER, intValue(4)), "0.25"},
+		{DecimalDiv(ONE_NUMBER, ZERO_NUMBER), "null"},
+		{DecimalMod(ToDecimal(AsNumberValue(NewValue(-7.5))), intValue(2)), "-1.5"},
+		{ToBinary(big), "12345678901234567000"},
+	}
+
+	for i, test := range tests {
+		if s := test.result.String(); s != test.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, test.expected, s)
+		}
+	}
+}
+
+func TestLongNumbers(t *testing.T) {
+	var tests = []struct {
+		input string
+		long  bool
+	}{
+		{`{"a": 1, "b": [0.5, -3e10, true]}`, false},
+		{`{"id": 1234567890123456789, "ts": -9223372036854775808}`, false},
+		{`{"phone": "12345678901234567890", "c": 1}`, false},
+		{`{"s": "\"12345678901234567890", "c": 0.000000000000000001}`, false},
+		{`{"id": 12345678901234567890}`, true},
+		{`[1.2345678901234567]`, true},
+		{`[1234567890123456.7e-3]`, true},
+	}
+
+	for _, test := range tests {
+		if long := hasLongNumber([]byte(test.input)); long != test.long {
+			t.Errorf("Expected long numbers in %s to be %v", test.input, test.long)
+		}
+	}
+}
@@ -89,6 +89,10 @@ func (this floatValue) Equals(other Value) Value {
 		if float64(this) == float64(other) {
 			return TRUE_VALUE
 		}
+	case decimalValue:
+		if other.Collate(this) == 0 {
+			return TRUE_VALUE
+		}
 	}
 
 	return FALSE_VALUE
@@ -101,6 +105,8 @@ func (this floatValue) EquivalentTo(other Value) bool {
 		return this == other
 	case intValue:
 		return float64(this) == float64(other)
+	case decimalValue:
+		return other.Collate(this) == 0
 	default:
 		return false
 	}
@@ -117,6 +123,8 @@ func (this floatValue) Collate(other Value) int {
 		t := float64(this)
 		o := float64(other)
 		return collateFloat(t, o)
+	case decimalValue:
+		return -other.Collate(this)
 	default:
 		return int(NUMBER - other.Type())
 	}
@@ -339,11 +347,19 @@ NumberValue methods.
 */
 
 func (this floatValue) Add(n NumberValue) NumberValue {
+	if d, ok := n.(decimalValue); ok {
+		return d.Add(this)
+	}
 	return floatValue(float64(this) + n.Actual().(float64))
 }
 
 func (this floatValue) IDiv(n NumberValue) Value {
 	switch n := n.(type) {
+	case decimalValue:
+		if d, ok := toDecimal(this); ok {
+			return d.IDiv(n)
+		}
+		return NULL_VALUE
 	case intValue:
 		if n == 0 {
 			return NULL_VALUE
@@ -362,6 +378,11 @@ func (this floatValue) IDiv(n NumberValue) Value {
 
 func (this floatValue) IMod(n NumberValue) Value {
 	switch n := n.(type) {
+	case decimalValue:
+		if d, ok := toDecimal(this); ok {
+			return d.IMod(n)
+		}
+		return NULL_VALUE
 	case intValue:
 		if n == 0 {
 			return NULL_VALUE
@@ -379,6 +400,9 @@ func (this floatValue) IMod(n NumberValue) Value {
 }
 
 func (this floatValue) Mult(n NumberValue) NumberValue {
+	if d, ok := n.(decimalValue); ok {
+		return d.Mult(this)
+	}
 	return floatValue(float64(this) * n.Actual().(float64))
 }
 
@@ -387,6 +411,9 @@ func (this floatValue) Neg() NumberValue {
 }
 
 func (this floatValue) Sub(n NumberValue) NumberValue {
+	if d, ok := n.(decimalValue); ok {
+		return d.Neg().Add(this)
+	}
 	return floatValue(float64(this) - n.Actual().(float64))
 }
 
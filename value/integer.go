@@ -83,6 +83,10 @@ func (this intValue) Equals(other Value) Value {
 		if float64(this) == float64(other) {
 			return TRUE_VALUE
 		}
+	case decimalValue:
+		if other.Collate(this) == 0 {
+			return TRUE_VALUE
+		}
 	}
 
 	return FALSE_VALUE
@@ -95,6 +99,8 @@ func (this intValue) EquivalentTo(other Value) bool {
 		return this == other
 	case floatValue:
 		return float64(this) == float64(other)
+	case decimalValue:
+		return other.Collate(this) == 0
 	default:
 		return false
 	}
@@ -112,7 +118,7 @@ func (this intValue) Collate(other Value) int {
 		default:
 			return 0
 		}
-	case floatValue:
+	case floatValue, decimalValue:
 		return -other.Collate(this)
 	default:
 		return int(NUMBER - other.Type())
@@ -281,6 +287,8 @@ func (this intValue) Add(n NumberValue) NumberValue {
 		if !overFlow {
 			return rv
 		}
+	case decimalValue:
+		return n.Add(this)
 	}
 
 	return floatValue(float64(this) + n.Actual().(float64))
@@ -291,6 +299,9 @@ func (this intValue) IDiv(n NumberValue) Value {
 	switch n := n.(type) {
 	case intValue:
 		n1 = n
+	case decimalValue:
+		d, _ := toDecimal(this)
+		return d.IDiv(n)
 	default:
 		n1 = intValue(n.Actual().(float64))
 	}
@@ -307,6 +318,9 @@ func (this intValue) IMod(n NumberValue) Value {
 	switch n := n.(type) {
 	case intValue:
 		n1 = n
+	case decimalValue:
+		d, _ := toDecimal(this)
+		return d.IMod(n)
 	default:
 		n1 = intValue(n.Actual().(float64))
 	}
@@ -328,6 +342,8 @@ func (this intValue) Mult(n NumberValue) NumberValue {
 		if this == 0 || rv/this == n {
 			return rv
 		}
+	case decimalValue:
+		return n.Mult(this)
 	}
 
 	return floatValue(float64(this) * n.Actual().(float64))
@@ -347,6 +363,8 @@ func (this intValue) Sub(n NumberValue) NumberValue {
 		if n > math.MinInt64 {
 			return this.Add(-n)
 		}
+	case decimalValue:
+		return n.Neg().Add(this)
 	}
 
 	return floatValue(float64(this) - n.Actual().(float64))
@@ -70,6 +70,7 @@ type MultiSet struct {
 	nulls     *valueCnt
 	booleans  map[bool]*valueCnt
 	floats    map[float64]*valueCnt
+	decimals  map[string]*valueCnt
 	ints      map[int64]*valueCnt
 	strings   map[string]*valueCnt
 	arrays    map[string]*valueCnt
@@ -85,6 +86,7 @@ func NewMultiSet(objectCap int, collect, numeric bool) *MultiSet {
 
 	rv := &MultiSet{
 		floats:    make(map[float64]*valueCnt, mapCap),
+		decimals:  make(map[string]*valueCnt, _MAP_CAP),
 		ints:      make(map[int64]*valueCnt, mapCap),
 		numeric:   numeric,
 		collect:   collect,
@@ -140,6 +142,9 @@ func (this *MultiSet) Put(key, item Value, cnt int64) {
 		}
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -165,6 +170,13 @@ func (this *MultiSet) Put(key, item Value, cnt int64) {
 			} else {
 				this.ints[int64(num)] = vc
 			}
+		case decimalValue:
+			vc := addValueCnt(this.decimals[num.String()], mapItem, cnt)
+			if vc == nil {
+				delete(this.decimals, num.String())
+			} else {
+				this.decimals[num.String()] = vc
+			}
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -228,6 +240,9 @@ func (this *MultiSet) Has(key Value) bool {
 		_, ok = this.booleans[key.Actual().(bool)]
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -238,6 +253,8 @@ func (this *MultiSet) Has(key Value) bool {
 			}
 		case intValue:
 			_, ok = this.ints[int64(num)]
+		case decimalValue:
+			_, ok = this.decimals[num.String()]
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -279,6 +296,9 @@ func (this *MultiSet) Count(key Value) int64 {
 		vc, ok = this.booleans[key.Actual().(bool)]
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -289,6 +309,8 @@ func (this *MultiSet) Count(key Value) int64 {
 			}
 		case intValue:
 			vc, ok = this.ints[int64(num)]
+		case decimalValue:
+			vc, ok = this.decimals[num.String()]
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -312,8 +334,8 @@ func (this *MultiSet) Count(key Value) int64 {
 }
 
 func (this *MultiSet) Len() int {
-	rv := len(this.booleans) + len(this.floats) + len(this.ints) + len(this.strings) +
-		len(this.arrays) + len(this.objects) + len(this.binaries)
+	rv := len(this.booleans) + len(this.floats) + len(this.decimals) + len(this.ints) +
+		len(this.strings) + len(this.arrays) + len(this.objects) + len(this.binaries)
 
 	if this.nills != nil {
 		rv++
@@ -359,6 +381,10 @@ func (this *MultiSet) Values() []Value {
 		rv = append(rv, av.getValue())
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av.getValue())
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av.getValue())
 	}
@@ -405,6 +431,10 @@ func (this *MultiSet) Actuals() []interface{} {
 		rv = append(rv, av.getValue().Actual())
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av.getValue().Actual())
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av.getValue().Actual())
 	}
@@ -459,6 +489,10 @@ func (this *MultiSet) Items() []interface{} {
 		rv = append(rv, av.getValue())
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av.getValue())
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av.getValue())
 	}
@@ -494,6 +528,11 @@ func (this *MultiSet) Clear() {
 		delete(this.floats, k)
 	}
 
+	for k, _ := range this.decimals {
+		this.decimals[k] = nil
+		delete(this.decimals, k)
+	}
+
 	for k, _ := range this.ints {
 		this.ints[k] = nil
 		delete(this.ints, k)
@@ -543,6 +582,7 @@ func (this *MultiSet) Copy() *MultiSet {
 	rv.numeric = this.numeric
 
 	rv.floats = make(map[float64]*valueCnt, 2*(1+len(this.floats)))
+	rv.decimals = make(map[string]*valueCnt, 2*(1+len(this.decimals)))
 	rv.ints = make(map[int64]*valueCnt, 2*(1+len(this.ints)))
 
 	if !rv.numeric {
@@ -577,6 +617,10 @@ func (this *MultiSet) Copy() *MultiSet {
 		rv.floats[k] = v.copy()
 	}
 
+	for k, v := range this.decimals {
+		rv.decimals[k] = v.copy()
+	}
+
 	for k, v := range this.ints {
 		rv.ints[k] = v.copy()
 	}
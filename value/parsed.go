@@ -75,6 +75,12 @@ func NewParsedValueWithOptions(bytes []byte, isValidated, useState bool) Value {
 			return binaryValue(bytes)
 		}
 
+		// keep numbers that a float64 cannot hold exactly, as integers if they fit
+		if parsedType == NUMBER {
+			if s := strings.TrimSpace(string(bytes)); significantDigits(s) > _FLOAT_DIGITS {
+				return newNumberValue(s)
+			}
+		}
 		return NewValue(p)
 	case BINARY:
 		return binaryValue(bytes)
@@ -590,7 +596,13 @@ func (this *parsedValue) unwrap() Value {
 		if this.parsedType == BINARY {
 			this.parsed = binaryValue(this.raw)
 		} else {
-			p, err := json.SimpleUnmarshal(this.raw)
+			var p interface{}
+			var err error
+			if hasLongNumber(this.raw) {
+				p, err = unmarshalNumbers(this.raw)
+			} else {
+				p, err = json.SimpleUnmarshal(this.raw)
+			}
 			if err != nil {
 				this.parsedType = BINARY
 				this.parsed = binaryValue(this.raw)
@@ -24,6 +24,7 @@ type Set struct {
 	nulls     Value
 	booleans  map[bool]Value
 	floats    map[float64]Value
+	decimals  map[string]Value
 	ints      map[int64]Value
 	strings   map[string]Value
 	arrays    map[string]Value
@@ -41,6 +42,7 @@ func NewSet(objectCap int, collect, numeric bool) *Set {
 
 	rv := &Set{
 		floats:    make(map[float64]Value, mapCap),
+		decimals:  make(map[string]Value, _MAP_CAP),
 		ints:      make(map[int64]Value, mapCap),
 		numeric:   numeric,
 		collect:   collect,
@@ -91,6 +93,9 @@ func (this *Set) Put(key, item Value) {
 		this.booleans[key.Actual().(bool)] = mapItem
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -101,6 +106,8 @@ func (this *Set) Put(key, item Value) {
 			}
 		case intValue:
 			this.ints[int64(num)] = mapItem
+		case decimalValue:
+			this.decimals[num.String()] = mapItem
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -138,6 +145,9 @@ func (this *Set) Remove(key Value) {
 		delete(this.booleans, key.Actual().(bool))
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -148,6 +158,8 @@ func (this *Set) Remove(key Value) {
 			}
 		case intValue:
 			delete(this.ints, int64(num))
+		case decimalValue:
+			delete(this.decimals, num.String())
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -185,6 +197,9 @@ func (this *Set) Has(key Value) bool {
 		_, ok = this.booleans[key.Actual().(bool)]
 	case NUMBER:
 		num := key.unwrap()
+		if d, ok := num.(decimalValue); ok {
+			num = d.setKey()
+		}
 		switch num := num.(type) {
 		case floatValue:
 			f := float64(num)
@@ -195,6 +210,8 @@ func (this *Set) Has(key Value) bool {
 			}
 		case intValue:
 			_, ok = this.ints[int64(num)]
+		case decimalValue:
+			_, ok = this.decimals[num.String()]
 		default:
 			panic(fmt.Sprintf("Unsupported value type %T.", key))
 		}
@@ -215,8 +232,8 @@ func (this *Set) Has(key Value) bool {
 }
 
 func (this *Set) Len() int {
-	rv := len(this.booleans) + len(this.floats) + len(this.ints) + len(this.strings) +
-		len(this.arrays) + len(this.objects) + len(this.binaries)
+	rv := len(this.booleans) + len(this.floats) + len(this.decimals) + len(this.ints) +
+		len(this.strings) + len(this.arrays) + len(this.objects) + len(this.binaries)
 
 	if this.nills {
 		rv++
@@ -262,6 +279,10 @@ func (this *Set) Values() []Value {
 		rv = append(rv, av)
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av)
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av)
 	}
@@ -308,6 +329,10 @@ func (this *Set) Actuals() []interface{} {
 		rv = append(rv, av.Actual())
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av.Actual())
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av.Actual())
 	}
@@ -362,6 +387,10 @@ func (this *Set) Items() []interface{} {
 		rv = append(rv, av)
 	}
 
+	for _, av := range this.decimals {
+		rv = append(rv, av)
+	}
+
 	for _, av := range this.ints {
 		rv = append(rv, av)
 	}
@@ -397,6 +426,11 @@ func (this *Set) Clear() {
 		delete(this.floats, k)
 	}
 
+	for k, _ := range this.decimals {
+		this.decimals[k] = nil
+		delete(this.decimals, k)
+	}
+
 	for k, _ := range this.ints {
 		this.ints[k] = nil
 		delete(this.ints, k)
@@ -442,6 +476,7 @@ func (this *Set) Copy() *Set {
 	rv.numeric = this.numeric
 
 	rv.floats = make(map[float64]Value, 2*(1+len(this.floats)))
+	rv.decimals = make(map[string]Value, 2*(1+len(this.decimals)))
 	rv.ints = make(map[int64]Value, 2*(1+len(this.ints)))
 
 	if !rv.numeric {
@@ -476,6 +511,10 @@ func (this *Set) Copy() *Set {
 		rv.floats[k] = v
 	}
 
+	for k, v := range this.decimals {
+		rv.decimals[k] = v
+	}
+
 	for k, v := range this.ints {
 		rv.ints[k] = v
 	}
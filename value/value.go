@@ -413,6 +413,8 @@ func NewValue(val interface{}) Value {
 		return objectValue(val)
 	case *parsedValue:
 		return val
+	case json.Number:
+		return newNumberValue(string(val))
 	case int:
 		return intValue(val)
 	case Values: